@@ -0,0 +1,714 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend is a USB stack used by a Context in place of libusb, e.g. a
+// pure Go usbfs implementation, a USB/IP client or a mock for tests.
+// Backends are registered with RegisterBackend and selected with
+// ContextOptions.Backend, or passed directly to NewContextWithBackend.
+//
+// The methods of a Backend and of the objects it returns may be called
+// concurrently. Errors should be reported using the Error and
+// TransferStatus values where applicable, since gousb and its users
+// check for some of them, e.g. ErrorNoDevice or ErrorNotSupported.
+//
+// A Backend may implement BackendEvents, BackendHotplug, BackendInfo,
+// BackendSysDevice and BackendTransferLimit to provide additional
+// functionality. Handles may
+// implement BackendAttach.
+type Backend interface {
+	// Devices returns the devices currently attached. The same device
+	// should be returned as the same, comparable BackendDevice value on
+	// every call. Each returned device is released with Release.
+	Devices() ([]BackendDevice, error)
+	// Close releases the resources of the backend. It's called by
+	// Context.Close, after all devices were closed.
+	Close() error
+}
+
+// BackendDevice is an attached device returned by Backend.Devices.
+type BackendDevice interface {
+	// Desc returns the descriptor of the device.
+	Desc() (*DeviceDesc, error)
+	// Open opens the device for communication.
+	Open() (BackendHandle, error)
+	// Release is called when gousb no longer needs the device returned by
+	// Backend.Devices. An open device stays valid until it's closed.
+	Release()
+}
+
+// BackendHandle is an open device, see BackendDevice.Open.
+type BackendHandle interface {
+	// Close closes the device.
+	Close()
+	// Reset performs a USB port reset of the device.
+	Reset() error
+	// Control performs a synchronous control transfer. The direction of
+	// the transfer is determined by rType, data holds the data stage.
+	Control(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error)
+	// ActiveConfig returns the number of the active configuration, or 0
+	// if the device is unconfigured.
+	ActiveConfig() (int, error)
+	// SetConfig activates a configuration.
+	SetConfig(cfg int) error
+	// StringDescriptor returns the string descriptor with the given index
+	// in the first language supported by the device.
+	StringDescriptor(index int) (string, error)
+	// SetAutoDetach enables automatic detachment of kernel drivers from
+	// the interfaces claimed by ClaimInterface.
+	SetAutoDetach(autodetach bool) error
+	// DetachKernelDriver detaches the kernel driver of an interface.
+	DetachKernelDriver(intf int) error
+	// ClaimInterface claims an interface for exclusive use.
+	ClaimInterface(intf int) error
+	// ReleaseInterface releases an interface claimed by ClaimInterface.
+	ReleaseInterface(intf int)
+	// SetAlternate activates an alternate setting of a claimed interface.
+	SetAlternate(intf, alt int) error
+	// NewTransfer prepares an asynchronous transfer on the endpoint, using
+	// buf as the transfer buffer. When a submitted transfer finishes, the
+	// backend sends a value on done. done is buffered and only used by a
+	// single transfer.
+	NewTransfer(ep EndpointDesc, opts BackendTransferOptions, buf []byte, done chan<- struct{}) (BackendTransfer, error)
+}
+
+// BackendTransferOptions are the parameters of a transfer created with
+// BackendHandle.NewTransfer.
+type BackendTransferOptions struct {
+	// ShortNotOK makes transfers that return less data than requested
+	// fail, see InEndpoint.SetShortNotOK.
+	ShortNotOK bool
+	// AddZeroPacket terminates transfers that are a multiple of the
+	// endpoint packet size with a zero length packet, see
+	// OutEndpoint.SetZeroPacket.
+	AddZeroPacket bool
+	// IsoPackets is the number of packets of an isochronous transfer.
+	// Backends that don't implement BackendIsoTransfer return an error
+	// for isochronous transfers.
+	IsoPackets int
+}
+
+// BackendTransfer is an asynchronous transfer, see
+// BackendHandle.NewTransfer. A transfer can be submitted again after it
+// finished.
+type BackendTransfer interface {
+	// Submit starts the transfer.
+	Submit() error
+	// Cancel cancels a submitted transfer. The transfer still reports its
+	// completion on the done channel. Cancel returns ErrorNotFound if the
+	// transfer is not in flight.
+	Cancel() error
+	// Result returns the number of bytes transferred and the status of
+	// the finished transfer.
+	Result() (int, TransferStatus)
+	// Free releases the transfer, it's not used afterwards.
+	Free()
+}
+
+// BackendIsoTransfer is implemented by transfers of backends that support
+// isochronous endpoints.
+type BackendIsoTransfer interface {
+	BackendTransfer
+	// SetIsoPacketLength sets the length of all packets of the transfer.
+	SetIsoPacketLength(length int)
+	// IsoPackets returns the results of the individual packets and the
+	// status of the whole transfer. Packet i starts in the transfer buffer
+	// at the sum of the Lengths of the preceding packets.
+	IsoPackets() ([]BackendIsoPacket, TransferStatus)
+}
+
+// BackendIsoPacket is the result of a single packet of an isochronous
+// transfer.
+type BackendIsoPacket struct {
+	// Length is the requested length of the packet.
+	Length int
+	// Actual is the number of bytes transferred.
+	Actual int
+	Status TransferStatus
+}
+
+// BackendEvents is implemented by backends that need a goroutine for
+// event processing, like libusb.
+type BackendEvents interface {
+	// HandleEvents processes events until done is closed, waiting at most
+	// tick for new events between the checks of done.
+	HandleEvents(tick time.Duration, done <-chan struct{})
+	// InterruptEvents wakes up HandleEvents, see Context.InterruptEvents.
+	InterruptEvents()
+}
+
+// BackendHotplug is implemented by backends with native hotplug
+// notifications. For other backends, Context.RegisterHotplug polls
+// Backend.Devices.
+type BackendHotplug interface {
+	// RegisterHotplug calls fn for every attached or detached device
+	// until the returned function is called.
+	RegisterHotplug(fn func(BackendDevice, HotplugEventType)) (func(), error)
+}
+
+// BackendInfo is implemented by backends that report their version and
+// capabilities, see Context.LibusbVersion and Context.HasCapability.
+type BackendInfo interface {
+	Version() LibusbVersion
+	HasCapability(Capability) bool
+	SetDebug(level int)
+}
+
+// BackendSysDevice is implemented by backends that can open devices from
+// system handles, see Context.OpenSysDevice.
+type BackendSysDevice interface {
+	WrapSysDevice(fd uintptr) (BackendDevice, BackendHandle, error)
+}
+
+// BackendTransferLimit is implemented by backends that limit the size of
+// a single bulk or interrupt transfer. Endpoints split larger reads and
+// writes, see InEndpoint.SetMaxTransferSize.
+type BackendTransferLimit interface {
+	// MaxTransferSize returns the size of the largest transfer in bytes,
+	// or 0 if it's not limited.
+	MaxTransferSize() int
+}
+
+// BackendAttach is implemented by backend handles that can reattach
+// kernel drivers, see DetachKernelDriver.
+type BackendAttach interface {
+	// AttachKernelDriver reattaches the kernel driver of an interface
+	// detached with BackendHandle.DetachKernelDriver.
+	AttachKernelDriver(intf int) error
+}
+
+// BackendFactory creates a Backend for a new Context.
+type BackendFactory func(ContextOptions) (Backend, error)
+
+var backendRegistry = struct {
+	sync.Mutex
+	m map[string]BackendFactory
+}{m: make(map[string]BackendFactory)}
+
+// RegisterBackend makes a backend available under the given name, to be
+// selected with ContextOptions.Backend. It's meant to be called from the
+// init function of the package implementing the backend. RegisterBackend
+// panics if the name is empty, already registered or is the name of the
+// built-in backend, "libusb", or "webusb" in WebAssembly builds.
+func RegisterBackend(name string, factory BackendFactory) {
+	backendRegistry.Lock()
+	defer backendRegistry.Unlock()
+	if name == "" || name == defaultBackendName {
+		panic(fmt.Sprintf("gousb: RegisterBackend with reserved name %q", name))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("gousb: RegisterBackend(%q) with a nil factory", name))
+	}
+	if _, ok := backendRegistry.m[name]; ok {
+		panic(fmt.Sprintf("gousb: RegisterBackend called twice for backend %q", name))
+	}
+	backendRegistry.m[name] = factory
+}
+
+// Backends returns the sorted names of the available backends, including
+// the built-in one.
+func Backends() []string {
+	backendRegistry.Lock()
+	defer backendRegistry.Unlock()
+	ret := []string{defaultBackendName}
+	for name := range backendRegistry.m {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// backendImpl returns the implementation of the backend selected by opts.
+func backendImpl(opts ContextOptions) (libusbIntf, error) {
+	if opts.Backend == "" || opts.Backend == defaultBackendName {
+		return newDefaultImpl(), nil
+	}
+	backendRegistry.Lock()
+	factory, ok := backendRegistry.m[opts.Backend]
+	backendRegistry.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q, available backends: %v", opts.Backend, Backends())
+	}
+	b, err := factory(opts)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: %v", opts.Backend, err)
+	}
+	return newBackendAdapter(b), nil
+}
+
+// NewContextWithBackend returns a new Context using b as the USB stack.
+// opts.Backend is ignored. The Context closes b when it's closed.
+func NewContextWithBackend(b Backend, opts ContextOptions) (*Context, error) {
+	return newContextWithImplAndOptions(newBackendAdapter(b), opts)
+}
+
+// backendAdapter implements libusbIntf on top of a Backend. The objects
+// of the backend are represented by unique pointers of the libusb types.
+// The pointer of a device is kept while the device is referenced, like
+// a libusb device, and forgotten once it's dereferenced and closed.
+type backendAdapter struct {
+	b Backend
+
+	mu      sync.Mutex
+	devices map[*libusbDevice]*backendDevice
+	ptrs    map[BackendDevice]*libusbDevice
+	handles map[*libusbDevHandle]backendHandle
+	ts      map[*libusbTransfer]*backendTransfer
+}
+
+type backendDevice struct {
+	d BackendDevice
+	// refs counts the references returned by getDevices that were not
+	// dereferenced yet, and the open handles of the device.
+	refs int
+}
+
+type backendHandle struct {
+	h   BackendHandle
+	dev *libusbDevice
+}
+
+type backendTransfer struct {
+	t   BackendTransfer
+	buf []byte
+}
+
+func newBackendAdapter(b Backend) *backendAdapter {
+	return &backendAdapter{
+		b:       b,
+		devices: make(map[*libusbDevice]*backendDevice),
+		ptrs:    make(map[BackendDevice]*libusbDevice),
+		handles: make(map[*libusbDevHandle]backendHandle),
+		ts:      make(map[*libusbTransfer]*backendTransfer),
+	}
+}
+
+// ref returns the pointer representing d, allocating one if needed, and
+// adds a reference to it. a.mu must be held.
+func (a *backendAdapter) ref(d BackendDevice) *libusbDevice {
+	p, ok := a.ptrs[d]
+	if !ok {
+		p = newDevicePointer()
+		a.ptrs[d] = p
+		a.devices[p] = &backendDevice{d: d}
+	}
+	a.devices[p].refs++
+	return p
+}
+
+// unref drops a reference to the device pointer p, forgetting the pointer
+// when it's no longer referenced. a.mu must be held.
+func (a *backendAdapter) unref(p *libusbDevice) {
+	bd, ok := a.devices[p]
+	if !ok {
+		return
+	}
+	if bd.refs--; bd.refs <= 0 {
+		delete(a.devices, p)
+		delete(a.ptrs, bd.d)
+	}
+}
+
+func (a *backendAdapter) device(d *libusbDevice) (BackendDevice, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bd, ok := a.devices[d]
+	if !ok {
+		return nil, fmt.Errorf("invalid USB device %p", d)
+	}
+	return bd.d, nil
+}
+
+// handle returns the backend handle represented by h, or ErrorNotFound if
+// h is unknown, e.g. because it was closed.
+func (a *backendAdapter) handle(h *libusbDevHandle) (BackendHandle, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bh, ok := a.handles[h]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+	return bh.h, nil
+}
+
+// transfer returns the backend transfer represented by t, or
+// ErrorNotFound if t is unknown, e.g. because it was freed.
+func (a *backendAdapter) transfer(t *libusbTransfer) (*backendTransfer, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bt, ok := a.ts[t]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+	return bt, nil
+}
+
+func (a *backendAdapter) init(ContextOptions) (*libusbContext, error) {
+	return newContextPointer(), nil
+}
+
+func (a *backendAdapter) handleEvents(_ *libusbContext, tick time.Duration, done <-chan struct{}) {
+	if e, ok := a.b.(BackendEvents); ok {
+		e.HandleEvents(tick, done)
+		return
+	}
+	<-done
+}
+
+func (a *backendAdapter) interruptEvents(*libusbContext) {
+	if e, ok := a.b.(BackendEvents); ok {
+		e.InterruptEvents()
+	}
+}
+
+func (a *backendAdapter) getDevices(*libusbContext) ([]*libusbDevice, error) {
+	list, err := a.b.Devices()
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ret := make([]*libusbDevice, len(list))
+	for i, d := range list {
+		ret[i] = a.ref(d)
+	}
+	return ret, nil
+}
+
+func (a *backendAdapter) exit(*libusbContext) error {
+	return a.b.Close()
+}
+
+func (a *backendAdapter) setDebug(_ *libusbContext, lvl int) {
+	if i, ok := a.b.(BackendInfo); ok {
+		i.SetDebug(lvl)
+	}
+}
+
+func (a *backendAdapter) getVersion() LibusbVersion {
+	if i, ok := a.b.(BackendInfo); ok {
+		return i.Version()
+	}
+	return LibusbVersion{}
+}
+
+func (a *backendAdapter) hasCapability(c Capability) bool {
+	if i, ok := a.b.(BackendInfo); ok {
+		return i.HasCapability(c)
+	}
+	return false
+}
+
+func (a *backendAdapter) registerHotplug(_ *libusbContext, fn func(*libusbDevice, HotplugEventType)) (func(), error) {
+	h, ok := a.b.(BackendHotplug)
+	if !ok {
+		return nil, ErrorNotSupported
+	}
+	return h.RegisterHotplug(func(d BackendDevice, typ HotplugEventType) {
+		// The pointer is only valid during the callback, like the device
+		// of a libusb hotplug event.
+		a.mu.Lock()
+		p := a.ref(d)
+		a.mu.Unlock()
+		fn(p, typ)
+		a.mu.Lock()
+		a.unref(p)
+		a.mu.Unlock()
+	})
+}
+
+func (a *backendAdapter) dereference(d *libusbDevice) {
+	a.mu.Lock()
+	bd, ok := a.devices[d]
+	if ok {
+		a.unref(d)
+	}
+	a.mu.Unlock()
+	if ok {
+		bd.d.Release()
+	}
+}
+
+func (a *backendAdapter) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
+	bd, err := a.device(d)
+	if err != nil {
+		return nil, err
+	}
+	return bd.Desc()
+}
+
+func (a *backendAdapter) open(d *libusbDevice) (*libusbDevHandle, error) {
+	bd, err := a.device(d)
+	if err != nil {
+		return nil, err
+	}
+	bh, err := bd.Open()
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if dev, ok := a.devices[d]; ok {
+		dev.refs++
+	}
+	h := newDevHandlePointer()
+	a.handles[h] = backendHandle{h: bh, dev: d}
+	return h, nil
+}
+
+func (a *backendAdapter) wrapSysDevice(_ *libusbContext, fd uintptr) (*libusbDevHandle, error) {
+	s, ok := a.b.(BackendSysDevice)
+	if !ok {
+		return nil, ErrorNotSupported
+	}
+	bd, bh, err := s.WrapSysDevice(fd)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h := newDevHandlePointer()
+	a.handles[h] = backendHandle{h: bh, dev: a.ref(bd)}
+	return h, nil
+}
+
+func (a *backendAdapter) getDevice(h *libusbDevHandle) *libusbDevice {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.handles[h].dev
+}
+
+func (a *backendAdapter) close(h *libusbDevHandle) {
+	a.mu.Lock()
+	bh, ok := a.handles[h]
+	delete(a.handles, h)
+	if ok {
+		a.unref(bh.dev)
+	}
+	a.mu.Unlock()
+	if ok {
+		bh.h.Close()
+	}
+}
+
+func (a *backendAdapter) reset(h *libusbDevHandle) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.Reset()
+}
+
+func (a *backendAdapter) control(h *libusbDevHandle, timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	bh, err := a.handle(h)
+	if err != nil {
+		return 0, err
+	}
+	return bh.Control(timeout, rType, request, val, idx, data)
+}
+
+func (a *backendAdapter) getConfig(h *libusbDevHandle) (uint8, error) {
+	bh, err := a.handle(h)
+	if err != nil {
+		return 0, err
+	}
+	cfg, err := bh.ActiveConfig()
+	return uint8(cfg), err
+}
+
+func (a *backendAdapter) setConfig(h *libusbDevHandle, cfg uint8) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.SetConfig(int(cfg))
+}
+
+func (a *backendAdapter) getStringDesc(h *libusbDevHandle, index int) (string, error) {
+	bh, err := a.handle(h)
+	if err != nil {
+		return "", err
+	}
+	return bh.StringDescriptor(index)
+}
+
+func (a *backendAdapter) setAutoDetach(h *libusbDevHandle, val int) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.SetAutoDetach(val != 0)
+}
+
+func (a *backendAdapter) detachKernelDriver(h *libusbDevHandle, num uint8) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.DetachKernelDriver(int(num))
+}
+
+func (a *backendAdapter) attachKernelDriver(h *libusbDevHandle, num uint8) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	at, ok := bh.(BackendAttach)
+	if !ok {
+		return ErrorNotSupported
+	}
+	return at.AttachKernelDriver(int(num))
+}
+
+func (a *backendAdapter) devMemAlloc(*libusbDevHandle, int) ([]byte, error) {
+	return nil, ErrorNotSupported
+}
+
+func (a *backendAdapter) devMemFree(*libusbDevHandle, []byte) error {
+	return ErrorNotSupported
+}
+
+func (a *backendAdapter) claim(h *libusbDevHandle, num uint8) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.ClaimInterface(int(num))
+}
+
+func (a *backendAdapter) release(h *libusbDevHandle, num uint8) {
+	if bh, err := a.handle(h); err == nil {
+		bh.ReleaseInterface(int(num))
+	}
+}
+
+func (a *backendAdapter) setAlt(h *libusbDevHandle, num, alt uint8) error {
+	bh, err := a.handle(h)
+	if err != nil {
+		return err
+	}
+	return bh.SetAlternate(int(num), int(alt))
+}
+
+func (a *backendAdapter) alloc(h *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
+	return a.allocWithBuffer(h, ep, flags, isoPackets, make([]byte, bufLen), done)
+}
+
+func (a *backendAdapter) allocWithBuffer(h *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, buf []byte, done chan struct{}) (*libusbTransfer, error) {
+	bh, err := a.handle(h)
+	if err != nil {
+		return nil, err
+	}
+	bt, err := bh.NewTransfer(*ep, BackendTransferOptions{
+		ShortNotOK:    flags&transferShortNotOK != 0,
+		AddZeroPacket: flags&transferAddZeroPacket != 0,
+		IsoPackets:    isoPackets,
+	}, buf, done)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := bt.(BackendIsoTransfer); isoPackets > 0 && !ok {
+		bt.Free()
+		return nil, ErrorNotSupported
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t := newFakeTransferPointer()
+	a.ts[t] = &backendTransfer{t: bt, buf: buf}
+	return t, nil
+}
+
+func (a *backendAdapter) cancel(t *libusbTransfer) error {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return err
+	}
+	return bt.t.Cancel()
+}
+
+func (a *backendAdapter) submit(t *libusbTransfer) error {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return err
+	}
+	return bt.t.Submit()
+}
+
+func (a *backendAdapter) buffer(t *libusbTransfer) []byte {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return nil
+	}
+	return bt.buf
+}
+
+func (a *backendAdapter) data(t *libusbTransfer) (int, TransferStatus) {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return 0, TransferError
+	}
+	return bt.t.Result()
+}
+
+func (a *backendAdapter) isoPackets(t *libusbTransfer) ([]isoPacketResult, TransferStatus) {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return nil, TransferError
+	}
+	it, ok := bt.t.(BackendIsoTransfer)
+	if !ok {
+		return nil, TransferError
+	}
+	pkts, status := it.IsoPackets()
+	ret := make([]isoPacketResult, len(pkts))
+	for i, p := range pkts {
+		ret[i] = isoPacketResult{length: p.Length, actual: p.Actual, status: p.Status}
+	}
+	return ret, status
+}
+
+func (a *backendAdapter) free(t *libusbTransfer) {
+	a.mu.Lock()
+	bt := a.ts[t]
+	delete(a.ts, t)
+	a.mu.Unlock()
+	if bt != nil {
+		bt.t.Free()
+	}
+}
+
+func (a *backendAdapter) maxTransferSize() int {
+	if l, ok := a.b.(BackendTransferLimit); ok {
+		return l.MaxTransferSize()
+	}
+	return 0
+}
+
+func (a *backendAdapter) setIsoPacketLengths(t *libusbTransfer, length uint32) {
+	bt, err := a.transfer(t)
+	if err != nil {
+		return
+	}
+	if it, ok := bt.t.(BackendIsoTransfer); ok {
+		it.SetIsoPacketLength(int(length))
+	}
+}
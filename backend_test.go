@@ -0,0 +1,205 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loopbackBackend is a Backend with a single device that echoes the data
+// written to its bulk OUT endpoint on its bulk IN endpoint.
+type loopbackBackend struct {
+	mu       sync.Mutex
+	data     [][]byte
+	released int
+	closed   bool
+}
+
+func (b *loopbackBackend) Devices() ([]BackendDevice, error) {
+	return []BackendDevice{b}, nil
+}
+func (b *loopbackBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+func (b *loopbackBackend) MaxTransferSize() int       { return 4096 }
+func (b *loopbackBackend) Desc() (*DeviceDesc, error) { return fakeDevices[0].devDesc, nil }
+func (b *loopbackBackend) Open() (BackendHandle, error) {
+	return loopbackHandle{b}, nil
+}
+func (b *loopbackBackend) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.released++
+}
+
+type loopbackHandle struct{ b *loopbackBackend }
+
+func (loopbackHandle) Close()       {}
+func (loopbackHandle) Reset() error { return nil }
+func (loopbackHandle) Control(_ time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if rType&ControlIn == 0 || request != 0x01 {
+		return 0, ErrorPipe
+	}
+	return copy(data, []byte{byte(val), byte(idx)}), nil
+}
+func (loopbackHandle) ActiveConfig() (int, error)           { return 1, nil }
+func (loopbackHandle) SetConfig(int) error                  { return nil }
+func (loopbackHandle) StringDescriptor(int) (string, error) { return "loopback", nil }
+func (loopbackHandle) SetAutoDetach(bool) error             { return nil }
+func (loopbackHandle) DetachKernelDriver(int) error         { return nil }
+func (loopbackHandle) ClaimInterface(int) error             { return nil }
+func (loopbackHandle) ReleaseInterface(int)                 {}
+func (loopbackHandle) SetAlternate(int, int) error          { return nil }
+func (h loopbackHandle) NewTransfer(ep EndpointDesc, _ BackendTransferOptions, buf []byte, done chan<- struct{}) (BackendTransfer, error) {
+	return &loopbackTransfer{b: h.b, ep: ep, buf: buf, done: done}, nil
+}
+
+type loopbackTransfer struct {
+	b    *loopbackBackend
+	ep   EndpointDesc
+	buf  []byte
+	done chan<- struct{}
+	n    int
+	st   TransferStatus
+}
+
+func (t *loopbackTransfer) Submit() error {
+	t.b.mu.Lock()
+	switch {
+	case t.ep.Direction == EndpointDirectionOut:
+		t.b.data = append(t.b.data, append([]byte(nil), t.buf...))
+		t.n, t.st = len(t.buf), TransferCompleted
+	case len(t.b.data) == 0:
+		t.n, t.st = 0, TransferStall
+	default:
+		t.n, t.st = copy(t.buf, t.b.data[0]), TransferCompleted
+		t.b.data = t.b.data[1:]
+	}
+	t.b.mu.Unlock()
+	t.done <- struct{}{}
+	return nil
+}
+func (t *loopbackTransfer) Cancel() error                 { return ErrorNotFound }
+func (t *loopbackTransfer) Result() (int, TransferStatus) { return t.n, t.st }
+func (t *loopbackTransfer) Free()                         {}
+
+func init() {
+	RegisterBackend("loopback-test", func(ContextOptions) (Backend, error) {
+		return &loopbackBackend{}, nil
+	})
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+	want := []string{defaultBackendName, "loopback-test"}
+	sort.Strings(want)
+	if got := Backends(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Backends(): got %v, want %v", got, want)
+	}
+	if _, err := NewContextWithOptions(ContextOptions{Backend: "nonexistent"}); err == nil {
+		t.Error("NewContextWithOptions() with an unknown backend: got nil error, want an error")
+	}
+
+	ctx, err := NewContextWithOptions(ContextOptions{Backend: "loopback-test"})
+	if err != nil {
+		t.Fatalf("NewContextWithOptions(): %v", err)
+	}
+	b := ctx.libusb.(*backendAdapter).b.(*loopbackBackend)
+	if devs, err := ctx.OpenDevices(func(*DeviceDesc) bool { return false }); err != nil || len(devs) != 0 {
+		t.Errorf("OpenDevices(none): got (%v, %v), want (nil, nil)", devs, err)
+	}
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	buf := make([]byte, 2)
+	if n, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0x12, 0x34, buf); err != nil || n != 2 || buf[0] != 0x12 || buf[1] != 0x34 {
+		t.Errorf("%s.Control(): got (% x, %v), want (12 34, nil)", dev, buf[:n], err)
+	}
+	if _, err := dev.Control(ControlOut|ControlVendor|ControlDevice, 0x01, 0, 0, nil); err != ErrorPipe {
+		t.Errorf("%s.Control() OUT: got error %v, want %v", dev, err, ErrorPipe)
+	}
+
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	if got, want := in.MaxTransferSize(), 4096; got != want {
+		t.Errorf("%s.MaxTransferSize(): got %d, want %d", in, got, want)
+	}
+	if n, err := out.Write([]byte("hello")); err != nil || n != 5 {
+		t.Errorf("%s.Write(): got (%d, %v), want (5, nil)", out, n, err)
+	}
+	rbuf := make([]byte, 512)
+	if n, err := in.Read(rbuf); err != nil || string(rbuf[:n]) != "hello" {
+		t.Errorf("%s.Read(): got (%q, %v), want (\"hello\", nil)", in, rbuf[:n], err)
+	}
+	if _, err := in.Read(rbuf); err != TransferStall {
+		t.Errorf("%s.Read() with no data: got error %v, want %v", in, err, TransferStall)
+	}
+	done()
+	h := dev.handle
+	dev.Close()
+
+	a := ctx.libusb.(*backendAdapter)
+	if _, err := a.control(h, 0, ControlIn|ControlVendor|ControlDevice, 0x01, 0, 0, buf); err != ErrorNotFound {
+		t.Errorf("control() on a closed handle: got error %v, want %v", err, ErrorNotFound)
+	}
+	a.mu.Lock()
+	if len(a.devices) != 0 || len(a.ptrs) != 0 || len(a.handles) != 0 {
+		t.Errorf("backend objects after the device was closed: got %d devices, %d pointers and %d handles, want none", len(a.devices), len(a.ptrs), len(a.handles))
+	}
+	a.mu.Unlock()
+
+	if err := ctx.Close(); err != nil {
+		t.Errorf("Context.Close(): %v", err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		t.Error("backend was not closed by Context.Close()")
+	}
+	if b.released == 0 {
+		t.Error("the enumerated device was never released")
+	}
+}
+
+func TestRegisterBackendPanics(t *testing.T) {
+	for _, name := range []string{"", defaultBackendName, "loopback-test"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterBackend(%q): did not panic", name)
+				}
+			}()
+			RegisterBackend(name, func(ContextOptions) (Backend, error) { return nil, nil })
+		}()
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench measures the throughput and latency of USB endpoints,
+// typically against test firmware that loops OUT data back to an IN
+// endpoint, like the Cypress FX2 or FX3 loopback examples.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Reader is implemented by *gousb.InEndpoint.
+type Reader interface {
+	ReadContext(ctx context.Context, buf []byte) (int, error)
+}
+
+// Writer is implemented by *gousb.OutEndpoint.
+type Writer interface {
+	WriteContext(ctx context.Context, buf []byte) (int, error)
+}
+
+// Result holds the measurements of a benchmark.
+type Result struct {
+	// Bytes is the number of bytes transferred in each direction.
+	Bytes int64
+	// Elapsed is the total duration of the benchmark.
+	Elapsed time.Duration
+	// Latencies holds the duration of each iteration, in order.
+	Latencies []time.Duration
+}
+
+// Throughput returns the throughput in megabytes (10^6 bytes) per second.
+func (r Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / 1e6 / r.Elapsed.Seconds()
+}
+
+// Percentile returns the latency below which p percent of the iterations
+// completed, e.g. Percentile(99) for the 99th percentile.
+func (r Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	l := append([]time.Duration(nil), r.Latencies...)
+	sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+	i := int(p/100*float64(len(l)) + 0.5)
+	if i > 0 {
+		i--
+	}
+	if i >= len(l) {
+		i = len(l) - 1
+	}
+	return l[i]
+}
+
+// String returns a summary of the result.
+func (r Result) String() string {
+	return fmt.Sprintf("%d bytes in %s, %.2f MB/s, latency p50 %s, p90 %s, p99 %s, max %s",
+		r.Bytes, r.Elapsed, r.Throughput(), r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Percentile(100))
+}
+
+// pattern returns n bytes of test data for iteration i.
+func pattern(n, i int) []byte {
+	b := make([]byte, n)
+	for j := range b {
+		b[j] = byte(i + j)
+	}
+	return b
+}
+
+// Loopback writes count buffers of size bytes to out and reads each one
+// back from in, checking that the data matches. The latency of an
+// iteration is the round trip time of a buffer.
+func Loopback(ctx context.Context, out Writer, in Reader, size, count int) (Result, error) {
+	var r Result
+	got := make([]byte, size)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		want := pattern(size, i)
+		t := time.Now()
+		if n, err := out.WriteContext(ctx, want); err != nil {
+			return r, fmt.Errorf("write %d: wrote %d of %d bytes: %v", i, n, size, err)
+		}
+		for n := 0; n < size; {
+			m, err := in.ReadContext(ctx, got[n:])
+			if err != nil {
+				return r, fmt.Errorf("read %d: read %d of %d bytes: %v", i, n, size, err)
+			}
+			n += m
+		}
+		r.Latencies = append(r.Latencies, time.Since(t))
+		r.Bytes += int64(size)
+		if !bytes.Equal(got, want) {
+			return r, fmt.Errorf("read %d: data doesn't match the written data", i)
+		}
+	}
+	r.Elapsed = time.Since(start)
+	return r, nil
+}
+
+// Read reads count buffers of size bytes from in, measuring the latency
+// of each read.
+func Read(ctx context.Context, in Reader, size, count int) (Result, error) {
+	var r Result
+	buf := make([]byte, size)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		t := time.Now()
+		n, err := in.ReadContext(ctx, buf)
+		r.Bytes += int64(n)
+		if err != nil {
+			return r, fmt.Errorf("read %d: %v", i, err)
+		}
+		r.Latencies = append(r.Latencies, time.Since(t))
+	}
+	r.Elapsed = time.Since(start)
+	return r, nil
+}
+
+// Write writes count buffers of size bytes to out, measuring the latency
+// of each write.
+func Write(ctx context.Context, out Writer, size, count int) (Result, error) {
+	var r Result
+	buf := pattern(size, 0)
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		t := time.Now()
+		n, err := out.WriteContext(ctx, buf)
+		r.Bytes += int64(n)
+		if err != nil {
+			return r, fmt.Errorf("write %d: %v", i, err)
+		}
+		r.Latencies = append(r.Latencies, time.Since(t))
+	}
+	r.Elapsed = time.Since(start)
+	return r, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// loopback is an in-memory device that returns the written data.
+type loopback struct {
+	data    []byte
+	corrupt bool
+}
+
+func (l *loopback) WriteContext(_ context.Context, buf []byte) (int, error) {
+	l.data = append(l.data, buf...)
+	return len(buf), nil
+}
+
+// ReadContext returns at most 64 bytes at a time, like a full speed
+// bulk endpoint.
+func (l *loopback) ReadContext(_ context.Context, buf []byte) (int, error) {
+	if len(l.data) == 0 {
+		return 0, errors.New("no data")
+	}
+	if len(buf) > 64 {
+		buf = buf[:64]
+	}
+	n := copy(buf, l.data)
+	if l.corrupt {
+		buf[0]++
+	}
+	l.data = l.data[n:]
+	return n, nil
+}
+
+func TestLoopback(t *testing.T) {
+	l := &loopback{}
+	r, err := Loopback(context.Background(), l, l, 200, 10)
+	if err != nil {
+		t.Fatalf("Loopback(): %v", err)
+	}
+	if r.Bytes != 2000 || len(r.Latencies) != 10 {
+		t.Errorf("Loopback(): got %d bytes in %d iterations, want 2000 bytes in 10", r.Bytes, len(r.Latencies))
+	}
+	if r.Throughput() <= 0 {
+		t.Errorf("Throughput(): got %f, want > 0", r.Throughput())
+	}
+
+	l.corrupt = true
+	if _, err := Loopback(context.Background(), l, l, 200, 1); err == nil {
+		t.Errorf("Loopback() with corrupted data: got nil error, want non-nil")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	l := &loopback{}
+	if r, err := Write(context.Background(), l, 64, 4); err != nil || r.Bytes != 256 {
+		t.Fatalf("Write(): got %d bytes, %v, want 256 bytes, nil", r.Bytes, err)
+	}
+	if r, err := Read(context.Background(), l, 64, 4); err != nil || r.Bytes != 256 {
+		t.Fatalf("Read(): got %d bytes, %v, want 256 bytes, nil", r.Bytes, err)
+	}
+	if _, err := Read(context.Background(), l, 64, 1); err == nil {
+		t.Errorf("Read() with no data: got nil error, want non-nil")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var r Result
+	for i := 100; i > 0; i-- {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 50 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	} {
+		if got := r.Percentile(tc.p); got != tc.want {
+			t.Errorf("Percentile(%v): got %s, want %s", tc.p, got, tc.want)
+		}
+	}
+	if got := (Result{}).Percentile(50); got != 0 {
+		t.Errorf("Percentile(50) of an empty result: got %s, want 0", got)
+	}
+}
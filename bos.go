@@ -0,0 +1,333 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	descTypeBOS              = uint8(DescriptorTypeBOS)
+	descTypeDeviceCapability = uint8(DescriptorTypeDeviceCapability)
+	bosHeaderSize            = 5
+)
+
+// CapabilityType identifies a device capability in the BOS descriptor.
+type CapabilityType uint8
+
+// Device capability types, as defined in the USB 3.x spec.
+const (
+	CapabilityWirelessUSB      CapabilityType = 0x01
+	CapabilityUSB20Extension   CapabilityType = 0x02
+	CapabilitySuperSpeed       CapabilityType = 0x03
+	CapabilityContainerID      CapabilityType = 0x04
+	CapabilityPlatform         CapabilityType = 0x05
+	CapabilitySuperSpeedPlus   CapabilityType = 0x0a
+	CapabilityBillboard        CapabilityType = 0x0d
+	CapabilityConfigurationSum CapabilityType = 0x0f
+)
+
+var capabilityTypeDescription = map[CapabilityType]string{
+	CapabilityWirelessUSB:      "Wireless USB",
+	CapabilityUSB20Extension:   "USB 2.0 Extension",
+	CapabilitySuperSpeed:       "SuperSpeed USB",
+	CapabilityContainerID:      "Container ID",
+	CapabilityPlatform:         "Platform",
+	CapabilitySuperSpeedPlus:   "SuperSpeedPlus USB",
+	CapabilityBillboard:        "Billboard",
+	CapabilityConfigurationSum: "Configuration Summary",
+}
+
+func (c CapabilityType) String() string {
+	if s, ok := capabilityTypeDescription[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("capability 0x%02x", uint8(c))
+}
+
+// DeviceCapability is a single device capability descriptor of the BOS.
+type DeviceCapability struct {
+	// Type is the bDevCapabilityType of the descriptor.
+	Type CapabilityType
+	// Data holds the capability-dependent fields that follow
+	// bDevCapabilityType.
+	Data []byte
+}
+
+// USB20Extension is the USB 2.0 Extension capability of a device.
+type USB20Extension struct {
+	// LPM is true if the device supports USB 2.0 link power management.
+	LPM bool
+	// BESL is true if the device supports the BESL and alternate HIRD
+	// definitions of LPM.
+	BESL bool
+}
+
+// SuperSpeedCapability is the SuperSpeed USB capability of a device.
+type SuperSpeedCapability struct {
+	// LTM is true if the device can generate latency tolerance messages.
+	LTM bool
+	// SpeedsSupported is a bitmap of the speeds supported by the device,
+	// bit 0 for low speed up to bit 3 for SuperSpeed.
+	SpeedsSupported uint16
+	// FunctionalitySupport is the lowest speed at which all the
+	// functionality of the device is available.
+	FunctionalitySupport Speed
+	// U1ExitLatency is the worst-case latency to transition from U1 to U0.
+	U1ExitLatency time.Duration
+	// U2ExitLatency is the worst-case latency to transition from U2 to U0.
+	U2ExitLatency time.Duration
+}
+
+// SublinkSpeed is a sublink speed attribute of the SuperSpeedPlus USB
+// capability, a speed the lanes of the link support.
+type SublinkSpeed struct {
+	// ID is the sublink speed attribute ID. The receive and transmit
+	// attributes of an asymmetric speed share their ID.
+	ID int
+	// LaneRate is the signaling rate of a single lane in bits per second.
+	LaneRate int64
+	// Asymmetric is false if the attribute applies to both directions,
+	// otherwise Transmit tells which direction it applies to.
+	Asymmetric bool
+	Transmit   bool
+	// SuperSpeedPlus is true if the link protocol is SuperSpeedPlus,
+	// false for SuperSpeed.
+	SuperSpeedPlus bool
+}
+
+// Gen returns the USB 3.2 generation of the lane rate, 1 for 5 Gb/s and
+// 2 for 10 Gb/s, or 0 for other rates.
+func (s SublinkSpeed) Gen() int {
+	switch s.LaneRate {
+	case 5000000000:
+		return 1
+	case 10000000000:
+		return 2
+	}
+	return 0
+}
+
+// SuperSpeedPlusCapability is the SuperSpeedPlus USB capability of
+// a device, which lists the lane speeds it supports.
+type SuperSpeedPlusCapability struct {
+	// SublinkSpeeds are the sublink speed attributes, in descriptor order.
+	SublinkSpeeds []SublinkSpeed
+	// MinSpeedID is the ID of the lowest sublink speed at which all the
+	// functionality of the device is available.
+	MinSpeedID int
+	// MinRxLanes and MinTxLanes are the lowest receive and transmit lane
+	// counts at which all the functionality of the device is available.
+	MinRxLanes, MinTxLanes int
+}
+
+// MaxGen returns the highest USB 3.2 generation of the sublink speeds,
+// see SublinkSpeed.Gen.
+func (c *SuperSpeedPlusCapability) MaxGen() int {
+	max := 0
+	for _, s := range c.SublinkSpeeds {
+		if g := s.Gen(); g > max {
+			max = g
+		}
+	}
+	return max
+}
+
+// MaxLaneRate returns the highest lane rate of the sublink speeds in bits
+// per second. A device that negotiated a link slower than that, i.e. with
+// a Speed.BitRate below it, is attached through a slower port, hub or
+// cable.
+func (c *SuperSpeedPlusCapability) MaxLaneRate() int64 {
+	var max int64
+	for _, s := range c.SublinkSpeeds {
+		if s.LaneRate > max {
+			max = s.LaneRate
+		}
+	}
+	return max
+}
+
+// parseSuperSpeedPlus parses the data of a SuperSpeedPlus USB capability,
+// following bDevCapabilityType.
+func parseSuperSpeedPlus(d []byte) (*SuperSpeedPlusCapability, bool) {
+	if len(d) < 9 {
+		return nil, false
+	}
+	le := binary.LittleEndian
+	// The attribute count is encoded minus one.
+	n := int(le.Uint32(d[1:])&0x1f) + 1
+	if len(d) < 9+4*n {
+		return nil, false
+	}
+	fs := le.Uint16(d[5:])
+	ret := &SuperSpeedPlusCapability{
+		MinSpeedID: int(fs & 0x0f),
+		MinRxLanes: int(fs >> 8 & 0x0f),
+		MinTxLanes: int(fs >> 12 & 0x0f),
+	}
+	for i := 0; i < n; i++ {
+		a := le.Uint32(d[9+4*i:])
+		rate := int64(a >> 16)
+		for e := a >> 4 & 0x03; e > 0; e-- {
+			rate *= 1000
+		}
+		ret.SublinkSpeeds = append(ret.SublinkSpeeds, SublinkSpeed{
+			ID:             int(a & 0x0f),
+			LaneRate:       rate,
+			Asymmetric:     a&(1<<6) != 0,
+			Transmit:       a&(1<<7) != 0,
+			SuperSpeedPlus: a>>14&0x03 == 1,
+		})
+	}
+	return ret, true
+}
+
+// BOSDesc is the Binary device Object Store descriptor of a device,
+// which lists device capabilities not covered by the device descriptor.
+type BOSDesc struct {
+	// Capabilities lists all device capabilities, in descriptor order.
+	Capabilities []DeviceCapability
+	// USB20Extension is the parsed USB 2.0 Extension capability, or nil
+	// if the device doesn't report one.
+	USB20Extension *USB20Extension
+	// SuperSpeed is the parsed SuperSpeed USB capability, or nil if
+	// the device doesn't report one.
+	SuperSpeed *SuperSpeedCapability
+	// SuperSpeedPlus is the parsed SuperSpeedPlus USB capability, or nil
+	// if the device doesn't report one.
+	SuperSpeedPlus *SuperSpeedPlusCapability
+	// ContainerID is the 16 byte UUID of the Container ID capability,
+	// which is the same for all functions of a physical device, or nil if
+	// the device doesn't report one.
+	ContainerID []byte
+}
+
+// ParseBOS parses a raw BOS descriptor, including the device capability
+// descriptors that follow its header.
+func ParseBOS(b []byte) (*BOSDesc, error) {
+	if len(b) < bosHeaderSize || b[1] != descTypeBOS {
+		return nil, fmt.Errorf("invalid BOS descriptor header % x", b)
+	}
+	total := int(b[2]) | int(b[3])<<8
+	if total > len(b) {
+		return nil, fmt.Errorf("BOS descriptor is %d bytes long, want %d", len(b), total)
+	}
+	ret := &BOSDesc{}
+	for off := int(b[0]); off < total; {
+		l := int(b[off])
+		if l < 3 || off+l > total {
+			return nil, fmt.Errorf("invalid device capability descriptor length %d at offset %d of BOS", l, off)
+		}
+		d := b[off : off+l]
+		off += l
+		if d[1] != descTypeDeviceCapability {
+			continue
+		}
+		c := DeviceCapability{Type: CapabilityType(d[2]), Data: append([]byte(nil), d[3:]...)}
+		ret.Capabilities = append(ret.Capabilities, c)
+		switch {
+		case c.Type == CapabilityUSB20Extension && len(c.Data) >= 4:
+			ret.USB20Extension = &USB20Extension{
+				LPM:  c.Data[0]&0x02 != 0,
+				BESL: c.Data[0]&0x04 != 0,
+			}
+		case c.Type == CapabilitySuperSpeed && len(c.Data) >= 7:
+			ret.SuperSpeed = &SuperSpeedCapability{
+				LTM:                  c.Data[0]&0x02 != 0,
+				SpeedsSupported:      uint16(c.Data[1]) | uint16(c.Data[2])<<8,
+				FunctionalitySupport: Speed(c.Data[3] + 1),
+				U1ExitLatency:        time.Duration(c.Data[4]) * time.Microsecond,
+				U2ExitLatency:        time.Duration(uint16(c.Data[5])|uint16(c.Data[6])<<8) * time.Microsecond,
+			}
+		case c.Type == CapabilitySuperSpeedPlus:
+			if ssp, ok := parseSuperSpeedPlus(c.Data); ok {
+				ret.SuperSpeedPlus = ssp
+			}
+		case c.Type == CapabilityContainerID && len(c.Data) >= 17:
+			// The UUID follows a reserved byte.
+			ret.ContainerID = c.Data[1:17]
+		}
+	}
+	if n := int(b[4]); n != len(ret.Capabilities) {
+		return nil, fmt.Errorf("BOS descriptor lists %d device capabilities, but has %d", n, len(ret.Capabilities))
+	}
+	return ret, nil
+}
+
+// BOS reads the BOS descriptor of the device. Devices that conform to
+// USB 2.0 or older usually don't have one and fail the request.
+func (d *Device) BOS() (*BOSDesc, error) {
+	s := StandardRequest(EndpointDirectionIn, requestGetDescriptor).WithValue(uint16(descTypeBOS) << 8)
+	hdr := make([]byte, bosHeaderSize)
+	n, err := d.ControlSetup(s, hdr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the BOS descriptor of %s: %v", d, err)
+	}
+	if n < bosHeaderSize {
+		return nil, fmt.Errorf("BOS descriptor of %s: got %d bytes, want %d", d, n, bosHeaderSize)
+	}
+	buf := make([]byte, int(hdr[2])|int(hdr[3])<<8)
+	if n, err = d.ControlSetup(s, buf); err != nil {
+		return nil, fmt.Errorf("failed to read the BOS descriptor of %s: %v", d, err)
+	}
+	return ParseBOS(buf[:n])
+}
+
+// LPMCapabilities describes the link power management support of a device.
+type LPMCapabilities struct {
+	// USB2 is true if the device supports USB 2.0 LPM (L1).
+	USB2 bool
+	// U1U2 is true if the device supports the SuperSpeed U1 and U2 link
+	// states.
+	U1U2 bool
+	// U1ExitLatency and U2ExitLatency are the worst-case exit latencies of
+	// the U1 and U2 states.
+	U1ExitLatency, U2ExitLatency time.Duration
+}
+
+// LPMCapabilities returns the link power management capabilities reported
+// in the BOS descriptor of the device.
+func (d *Device) LPMCapabilities() (LPMCapabilities, error) {
+	bos, err := d.BOS()
+	if err != nil {
+		return LPMCapabilities{}, err
+	}
+	var ret LPMCapabilities
+	if bos.USB20Extension != nil {
+		ret.USB2 = bos.USB20Extension.LPM
+	}
+	if ss := bos.SuperSpeed; ss != nil {
+		ret.U1U2 = true
+		ret.U1ExitLatency = ss.U1ExitLatency
+		ret.U2ExitLatency = ss.U2ExitLatency
+	}
+	return ret, nil
+}
+
+// SetU1Enabled allows or forbids the device to initiate transitions to
+// the U1 link state. Disabling U1 and U2 trades power for latency, which
+// avoids throughput glitches of some SuperSpeed devices. The host
+// controller may still initiate the transitions.
+func (d *Device) SetU1Enabled(enable bool) error {
+	return d.setFeature(enable, ControlRecipientDevice, 0, FeatureU1Enable)
+}
+
+// SetU2Enabled allows or forbids the device to initiate transitions to
+// the U2 link state. See SetU1Enabled.
+func (d *Device) SetU2Enabled(enable bool) error {
+	return d.setFeature(enable, ControlRecipientDevice, 0, FeatureU2Enable)
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testBOS is the BOS descriptor of a typical USB 3.0 mass storage device.
+var testBOS = []byte{
+	0x05, 0x0f, 0x16, 0x00, 0x02, // BOS header, 22 bytes, 2 capabilities
+	0x07, 0x10, 0x02, 0x02, 0x00, 0x00, 0x00, // USB 2.0 Extension, LPM
+	0x0a, 0x10, 0x03, 0x00, 0x0e, 0x00, 0x01, 0x0a, 0xff, 0x07, // SuperSpeed USB
+}
+
+func TestParseBOS(t *testing.T) {
+	bos, err := ParseBOS(testBOS)
+	if err != nil {
+		t.Fatalf("ParseBOS(): %v", err)
+	}
+	if got := len(bos.Capabilities); got != 2 {
+		t.Fatalf("ParseBOS(): got %d capabilities, want 2", got)
+	}
+	if got, want := bos.Capabilities[1].Type, CapabilitySuperSpeed; got != want {
+		t.Errorf("ParseBOS(): capability 1 is %s, want %s", got, want)
+	}
+	if ext := bos.USB20Extension; ext == nil || !ext.LPM || ext.BESL {
+		t.Errorf("ParseBOS(): USB 2.0 extension %+v, want LPM without BESL", ext)
+	}
+	want := SuperSpeedCapability{
+		SpeedsSupported:      0x0e,
+		FunctionalitySupport: SpeedFull,
+		U1ExitLatency:        10 * time.Microsecond,
+		U2ExitLatency:        2047 * time.Microsecond,
+	}
+	if ss := bos.SuperSpeed; ss == nil || *ss != want {
+		t.Errorf("ParseBOS(): SuperSpeed capability %+v, want %+v", ss, want)
+	}
+
+	for _, b := range [][]byte{
+		nil,
+		testBOS[:10],
+		append([]byte{0x05, 0x0f, 0x0a, 0x00, 0x01}, 0x02, 0x10, 0x02, 0x00, 0x00),
+		[]byte{0x05, 0x0f, 0x05, 0x00, 0x01},
+	} {
+		if _, err := ParseBOS(b); err == nil {
+			t.Errorf("ParseBOS(% x): got nil error, want non-nil", b)
+		}
+	}
+}
+
+func TestParseBOSContainerID(t *testing.T) {
+	b := []byte{
+		0x05, 0x0f, 0x19, 0x00, 0x01, // BOS header, 25 bytes, 1 capability
+		0x14, 0x10, 0x04, 0x00, // Container ID, reserved byte
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+	}
+	bos, err := ParseBOS(b)
+	if err != nil {
+		t.Fatalf("ParseBOS(): %v", err)
+	}
+	if got, want := string(bos.ContainerID), string(b[9:]); got != want {
+		t.Errorf("ParseBOS(): got container ID % x, want % x", got, want)
+	}
+	if bos, err := ParseBOS(testBOS); err != nil {
+		t.Errorf("ParseBOS(testBOS): %v", err)
+	} else if bos.ContainerID != nil {
+		t.Errorf("ParseBOS(testBOS): got container ID % x, want none", bos.ContainerID)
+	}
+}
+
+func TestParseBOSSuperSpeedPlus(t *testing.T) {
+	b := []byte{
+		0x05, 0x0f, 0x21, 0x00, 0x01, // BOS header, 33 bytes, 1 capability
+		0x1c, 0x10, 0x0a, 0x00, // SuperSpeedPlus USB, reserved byte
+		0x23, 0x00, 0x00, 0x00, // 4 sublink speed attributes, 2 speed IDs
+		0x00, 0x11, 0x00, 0x00, // minimum speed ID 0, 1 lane each way
+		0x30, 0x00, 0x05, 0x00, // ID 0, RX, 5 Gb/s SuperSpeed
+		0xb0, 0x00, 0x05, 0x00, // ID 0, TX, 5 Gb/s SuperSpeed
+		0x31, 0x40, 0x0a, 0x00, // ID 1, RX, 10 Gb/s SuperSpeedPlus
+		0xb1, 0x40, 0x0a, 0x00, // ID 1, TX, 10 Gb/s SuperSpeedPlus
+	}
+	bos, err := ParseBOS(b)
+	if err != nil {
+		t.Fatalf("ParseBOS(): %v", err)
+	}
+	ssp := bos.SuperSpeedPlus
+	if ssp == nil {
+		t.Fatal("ParseBOS(): no SuperSpeedPlus capability")
+	}
+	want := SuperSpeedPlusCapability{
+		SublinkSpeeds: []SublinkSpeed{
+			{ID: 0, LaneRate: 5000000000},
+			{ID: 0, LaneRate: 5000000000, Transmit: true},
+			{ID: 1, LaneRate: 10000000000, SuperSpeedPlus: true},
+			{ID: 1, LaneRate: 10000000000, Transmit: true, SuperSpeedPlus: true},
+		},
+		MinRxLanes: 1,
+		MinTxLanes: 1,
+	}
+	if !reflect.DeepEqual(*ssp, want) {
+		t.Errorf("ParseBOS(): SuperSpeedPlus capability %+v, want %+v", *ssp, want)
+	}
+	if got, want := ssp.MaxGen(), 2; got != want {
+		t.Errorf("MaxGen(): got %d, want %d", got, want)
+	}
+	if got, want := ssp.MaxLaneRate(), SpeedSuperPlus.BitRate(); got != want {
+		t.Errorf("MaxLaneRate(): got %d, want %d", got, want)
+	}
+
+	// A capability too short for its attribute count is skipped.
+	b[2], b[5] = 0x1d, 0x18
+	if bos, err := ParseBOS(b[:0x1d]); err != nil {
+		t.Errorf("ParseBOS(truncated): %v", err)
+	} else if bos.SuperSpeedPlus != nil {
+		t.Errorf("ParseBOS(truncated): got SuperSpeedPlus capability %+v, want none", bos.SuperSpeedPlus)
+	}
+}
+
+func TestLPM(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	features := map[Feature]bool{}
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		switch request {
+		case requestGetDescriptor:
+			return copy(data, testBOS), nil
+		case requestSetFeature:
+			features[Feature(val)] = true
+		case requestClearFeature:
+			features[Feature(val)] = false
+		}
+		return 0, nil
+	}
+	caps, err := dev.LPMCapabilities()
+	if err != nil {
+		t.Fatalf("LPMCapabilities(): %v", err)
+	}
+	if want := (LPMCapabilities{USB2: true, U1U2: true, U1ExitLatency: 10 * time.Microsecond, U2ExitLatency: 2047 * time.Microsecond}); caps != want {
+		t.Errorf("LPMCapabilities(): got %+v, want %+v", caps, want)
+	}
+	if err := dev.SetU1Enabled(true); err != nil {
+		t.Fatalf("SetU1Enabled(true): %v", err)
+	}
+	if err := dev.SetU2Enabled(false); err != nil {
+		t.Fatalf("SetU2Enabled(false): %v", err)
+	}
+	if !features[FeatureU1Enable] || features[FeatureU2Enable] {
+		t.Errorf("features after SetU1Enabled(true), SetU2Enabled(false): got %v", features)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// linktypeUSBLinuxMmapped is the pcap link type of usbmon captures with
+	// the 64-byte packet header, as produced by libpcap on Linux.
+	linktypeUSBLinuxMmapped = 220
+	// usbmonHeaderLen is the length of the usbmon packet header.
+	usbmonHeaderLen = 64
+	// captureSnapLen is the maximum length of a captured packet, including
+	// the usbmon header. Longer payloads are truncated.
+	captureSnapLen = 256 * 1024
+)
+
+// Linux errno values used as usbmon URB status codes. They are defined
+// here rather than taken from syscall, since the capture format uses Linux
+// values regardless of the platform.
+const (
+	usbmonENOENT      = 2
+	usbmonEIO         = 5
+	usbmonENODEV      = 19
+	usbmonEPIPE       = 32
+	usbmonEPROTO      = 71
+	usbmonEOVERFLOW   = 75
+	usbmonEINPROGRESS = 115
+)
+
+// usbmon transfer types, from linux/usb.h.
+var usbmonTransferType = map[TransferType]uint8{
+	TransferTypeIsochronous: 0,
+	TransferTypeInterrupt:   1,
+	TransferTypeControl:     2,
+	TransferTypeBulk:        3,
+}
+
+// capturer writes transfers to a pcap stream, see Context.SetCapture.
+type capturer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	ids uint64
+}
+
+// capture writes the transfer as a pair of usbmon submission and
+// completion events.
+func (p *capturer) capture(r traceRecord) {
+	id := atomic.AddUint64(&p.ids, 1)
+	ep := uint8(r.ep)
+	if r.dir == EndpointDirectionIn {
+		ep |= endpointDirectionMask
+	}
+
+	submit := usbmonEvent{
+		id:     id,
+		typ:    'S',
+		tt:     usbmonTransferType[r.tt],
+		ep:     ep,
+		bus:    r.bus,
+		addr:   r.addr,
+		ts:     r.start,
+		status: -usbmonEINPROGRESS,
+		length: r.length,
+		setup:  r.setupPacket,
+		data:   r.outData,
+	}
+	complete := usbmonEvent{
+		id:     id,
+		typ:    'C',
+		tt:     submit.tt,
+		ep:     ep,
+		bus:    r.bus,
+		addr:   r.addr,
+		ts:     r.start.Add(r.dur),
+		status: usbmonStatus(r.err),
+		length: len(r.data),
+	}
+	if r.dir == EndpointDirectionIn {
+		complete.data = r.data
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.w.Write(submit.marshal())
+	p.w.Write(complete.marshal())
+}
+
+// usbmonStatus returns the URB status reported by usbmon for a transfer
+// that finished with err.
+func usbmonStatus(err error) int32 {
+	if err == nil {
+		return 0
+	}
+	var ts TransferStatus
+	if errors.As(err, &ts) {
+		switch ts {
+		case TransferCompleted:
+			return 0
+		case TransferTimedOut, TransferCancelled:
+			return -usbmonENOENT
+		case TransferStall:
+			return -usbmonEPIPE
+		case TransferNoDevice:
+			return -usbmonENODEV
+		case TransferOverflow:
+			return -usbmonEOVERFLOW
+		}
+		return -usbmonEPROTO
+	}
+	var e Error
+	if errors.As(err, &e) {
+		switch e {
+		case ErrorTimeout:
+			return -usbmonENOENT
+		case ErrorPipe:
+			return -usbmonEPIPE
+		case ErrorNoDevice:
+			return -usbmonENODEV
+		case ErrorOverflow:
+			return -usbmonEOVERFLOW
+		}
+	}
+	return -usbmonEIO
+}
+
+// usbmonEvent is a single usbmon event, a submission or a completion of
+// a transfer.
+type usbmonEvent struct {
+	id        uint64
+	typ       byte
+	tt        uint8
+	ep        uint8
+	bus, addr int
+	ts        time.Time
+	status    int32
+	// length is the URB length, i.e. the requested length of a submission
+	// or the actual length of a completion.
+	length int
+	setup  []byte
+	data   []byte
+}
+
+// marshal returns the pcap record of the event, using the 64-byte usbmon
+// header in little endian byte order.
+func (e usbmonEvent) marshal() []byte {
+	data := e.data
+	if max := captureSnapLen - usbmonHeaderLen; len(data) > max {
+		data = data[:max]
+	}
+	capLen := usbmonHeaderLen + len(data)
+	b := make([]byte, 16+capLen)
+	le := binary.LittleEndian
+
+	// pcap record header.
+	le.PutUint32(b[0:], uint32(e.ts.Unix()))
+	le.PutUint32(b[4:], uint32(e.ts.Nanosecond()/1000))
+	le.PutUint32(b[8:], uint32(capLen))
+	le.PutUint32(b[12:], uint32(capLen))
+
+	h := b[16:]
+	le.PutUint64(h[0:], e.id)
+	h[8] = e.typ
+	h[9] = e.tt
+	h[10] = e.ep
+	h[11] = uint8(e.addr)
+	le.PutUint16(h[12:], uint16(e.bus))
+	h[14] = '-' // no setup packet
+	if e.setup != nil {
+		h[14] = 0
+		copy(h[40:48], e.setup)
+	}
+	h[15] = 0 // data present
+	if len(data) == 0 {
+		h[15] = '>'
+		if e.ep&endpointDirectionMask != 0 {
+			h[15] = '<'
+		}
+	}
+	le.PutUint64(h[16:], uint64(e.ts.Unix()))
+	le.PutUint32(h[24:], uint32(e.ts.Nanosecond()/1000))
+	le.PutUint32(h[28:], uint32(e.status))
+	le.PutUint32(h[32:], uint32(e.length))
+	le.PutUint32(h[36:], uint32(len(data)))
+	// interval, start_frame, xfer_flags and ndesc are left as 0.
+	copy(h[usbmonHeaderLen:], data)
+	return b
+}
+
+// pcapHeader returns the pcap global header for usbmon captures.
+func pcapHeader() []byte {
+	b := make([]byte, 24)
+	le := binary.LittleEndian
+	le.PutUint32(b[0:], 0xa1b2c3d4) // microsecond timestamps
+	le.PutUint16(b[4:], 2)
+	le.PutUint16(b[6:], 4)
+	le.PutUint32(b[16:], captureSnapLen)
+	le.PutUint32(b[20:], linktypeUSBLinuxMmapped)
+	return b
+}
+
+// SetCapture enables recording of all control, bulk and interrupt
+// transfers of the devices opened through the Context into w, as a pcap
+// stream using the usbmon (LINKTYPE_USB_LINUX_MMAPPED) link type. The
+// capture can be opened in Wireshark and compared with kernel usbmon
+// captures. Each transfer is recorded as a submission and a completion
+// event. A nil w disables the capture. Captures can be replayed with
+// NewReplayContext.
+//
+// SetCapture writes the pcap file header to w and returns an error if
+// that fails. Errors writing subsequent packets are ignored. As with
+// SetTrace, transfers done through ReadStream and WriteStream are not
+// recorded.
+func (c *Context) SetCapture(w io.Writer) error {
+	if w == nil {
+		c.capture.Store((*capturer)(nil))
+		return nil
+	}
+	if _, err := w.Write(pcapHeader()); err != nil {
+		return err
+	}
+	c.capture.Store(&capturer{w: w})
+	return nil
+}
+
+// getCapture returns the active capture, or nil if capturing is disabled.
+func (c *Context) getCapture() *capturer {
+	p, _ := c.capture.Load().(*capturer)
+	return p
+}
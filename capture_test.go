@@ -0,0 +1,166 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// pcapRecord is a decoded usbmon packet from a capture.
+type pcapRecord struct {
+	id        uint64
+	typ       byte
+	tt, ep    uint8
+	addr      uint8
+	bus       uint16
+	flagSetup byte
+	flagData  byte
+	status    int32
+	length    uint32
+	setup     []byte
+	data      []byte
+}
+
+func parseCapture(t *testing.T, b []byte) []pcapRecord {
+	t.Helper()
+	le := binary.LittleEndian
+	if len(b) < 24 {
+		t.Fatalf("capture too short: %d bytes", len(b))
+	}
+	if got := le.Uint32(b[0:]); got != 0xa1b2c3d4 {
+		t.Errorf("pcap magic: got %#x, want 0xa1b2c3d4", got)
+	}
+	if got := le.Uint32(b[20:]); got != linktypeUSBLinuxMmapped {
+		t.Errorf("pcap link type: got %d, want %d", got, linktypeUSBLinuxMmapped)
+	}
+	b = b[24:]
+	var ret []pcapRecord
+	for len(b) > 0 {
+		if len(b) < 16+usbmonHeaderLen {
+			t.Fatalf("truncated pcap record: %d bytes", len(b))
+		}
+		capLen := int(le.Uint32(b[8:]))
+		h := b[16 : 16+capLen]
+		b = b[16+capLen:]
+		r := pcapRecord{
+			id:        le.Uint64(h[0:]),
+			typ:       h[8],
+			tt:        h[9],
+			ep:        h[10],
+			addr:      h[11],
+			bus:       le.Uint16(h[12:]),
+			flagSetup: h[14],
+			flagData:  h[15],
+			status:    int32(le.Uint32(h[28:])),
+			length:    le.Uint32(h[32:]),
+			data:      h[usbmonHeaderLen:],
+		}
+		if got := int(le.Uint32(h[36:])); got != len(r.data) {
+			t.Errorf("record %d: len_cap %d, want %d", len(ret), got, len(r.data))
+		}
+		if r.flagSetup == 0 {
+			r.setup = h[40:48]
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+func TestCapture(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	var out bytes.Buffer
+	if err := ctx.SetCapture(&out); err != nil {
+		t.Fatalf("SetCapture(): %v", err)
+	}
+
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData([]byte("hello"))
+		ft.setStatus(TransferCompleted)
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	if _, err := ep.Read(make([]byte, 512)); err != nil {
+		t.Errorf("%s.Read(): %v", ep, err)
+	}
+	// fakeLibusb doesn't implement control transfers, the error is captured.
+	dev.Control(ControlOut|ControlVendor|ControlDevice, 0x42, 0x1234, 0x0001, []byte{1, 2, 3})
+
+	got := parseCapture(t, out.Bytes())
+	setup := []byte{0x40, 0x42, 0x34, 0x12, 0x01, 0x00, 0x03, 0x00}
+	want := []pcapRecord{
+		{id: 1, typ: 'S', tt: 3, ep: 0x82, addr: 1, bus: 1, flagSetup: '-', flagData: '<', status: -usbmonEINPROGRESS, length: 512},
+		{id: 1, typ: 'C', tt: 3, ep: 0x82, addr: 1, bus: 1, flagSetup: '-', flagData: 0, length: 5, data: []byte("hello")},
+		{id: 2, typ: 'S', tt: 2, ep: 0x00, addr: 1, bus: 1, flagSetup: 0, flagData: 0, status: -usbmonEINPROGRESS, length: 3, setup: setup, data: []byte{1, 2, 3}},
+		{id: 2, typ: 'C', tt: 2, ep: 0x00, addr: 1, bus: 1, flagSetup: '-', flagData: '>', status: -usbmonEIO, length: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("capture: got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.id != w.id || g.typ != w.typ || g.tt != w.tt || g.ep != w.ep || g.addr != w.addr || g.bus != w.bus || g.flagSetup != w.flagSetup || g.flagData != w.flagData || g.status != w.status || g.length != w.length || !bytes.Equal(g.setup, w.setup) || !bytes.Equal(g.data, w.data) {
+			t.Errorf("record %d:\ngot  %+v\nwant %+v", i, g, w)
+		}
+	}
+
+	out.Reset()
+	ctx.SetCapture(nil)
+	dev.Control(ControlIn|ControlVendor|ControlDevice, 0x42, 0x1234, 0, make([]byte, 8))
+	if out.Len() != 0 {
+		t.Errorf("capture after SetCapture(nil): got %d bytes, want none", out.Len())
+	}
+}
+
+func TestUsbmonStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int32
+	}{
+		{nil, 0},
+		{TransferCompleted, 0},
+		{TransferStall, -usbmonEPIPE},
+		{TransferTimedOut, -usbmonENOENT},
+		{TransferNoDevice, -usbmonENODEV},
+		{ErrorPipe, -usbmonEPIPE},
+		{ErrorIO, -usbmonEIO},
+	} {
+		if got := usbmonStatus(tc.err); got != tc.want {
+			t.Errorf("usbmonStatus(%v): got %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdcnet implements the host side of the USB CDC Ethernet
+// Control Model (ECM) and Network Control Model (NCM), used by USB
+// Ethernet adapters, phones sharing their connection and embedded
+// Linux gadgets.
+//
+// A Device sends and receives Ethernet frames, which makes it suitable
+// as the link layer of a userspace network stack.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x0525, 0xa4a2)
+//	cfg, _ := dev.Config(1)
+//	d, err := cdcnet.Open(dev, cfg, 0)
+//	if err != nil { ... }
+//	defer d.Close()
+//	fmt.Println(d.HardwareAddr)
+//	frame, err := d.ReadPacket()
+package cdcnet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/google/gousb"
+)
+
+// Interface subclass codes of ECM and NCM control interfaces.
+const (
+	SubClassECM = gousb.SubClassCDCECM
+	SubClassNCM = gousb.SubClassCDCNCM
+)
+
+// CDC class-specific descriptors and requests.
+const (
+	descTypeCSInterface = 0x24
+
+	subtypeHeader   = 0x00
+	subtypeUnion    = 0x06
+	subtypeEthernet = 0x0f
+	subtypeNCM      = 0x1a
+
+	requestSetEthernetPacketFilter = 0x43
+	requestGetNTBParameters        = 0x80
+	requestSetNTBFormat            = 0x84
+)
+
+// PacketFilter selects the frames the device passes to the host, see
+// Device.SetPacketFilter.
+type PacketFilter uint16
+
+// Packet filter bits.
+const (
+	FilterPromiscuous  PacketFilter = 0x01
+	FilterAllMulticast PacketFilter = 0x02
+	FilterDirected     PacketFilter = 0x04
+	FilterBroadcast    PacketFilter = 0x08
+	FilterMulticast    PacketFilter = 0x10
+
+	// DefaultFilter is the filter set by Open.
+	DefaultFilter = FilterDirected | FilterBroadcast | FilterAllMulticast
+)
+
+// Controller is the subset of *gousb.Device used to talk to an ECM or NCM
+// control interface.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// StringReader is the subset of *gousb.Device used to read the MAC address.
+type StringReader interface {
+	GetStringDescriptor(descIndex int) (string, error)
+}
+
+// IsECM reports whether the interface setting is an ECM control interface.
+func IsECM(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassComm && s.SubClass == SubClassECM
+}
+
+// IsNCM reports whether the interface setting is an NCM control interface.
+func IsNCM(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassComm && s.SubClass == SubClassNCM
+}
+
+// EthernetDesc is the Ethernet networking functional descriptor.
+type EthernetDesc struct {
+	// MACAddressIndex is the index of the string descriptor holding the
+	// MAC address as 12 hex digits.
+	MACAddressIndex int
+	// Statistics is the raw bmEthernetStatistics field.
+	Statistics uint32
+	// MaxSegmentSize is the maximum length of an Ethernet frame, without
+	// the CRC, usually 1514.
+	MaxSegmentSize int
+	// MulticastFilters is the number of multicast filters, the top bit
+	// is set if the filters are imperfect.
+	MulticastFilters uint16
+	// PowerFilters is the number of wake-up pattern filters.
+	PowerFilters int
+}
+
+// NCMDesc is the NCM functional descriptor.
+type NCMDesc struct {
+	// Version is the NCM version of the function.
+	Version gousb.BCD
+	// Capabilities is the raw bmNetworkCapabilities field.
+	Capabilities uint8
+}
+
+// FunctionalDescs are the functional descriptors of an ECM or NCM control
+// interface. Packages importing cdcnet get them decoded in the ClassDesc
+// field of the interface setting.
+type FunctionalDescs struct {
+	// CDCVersion is the version from the header functional descriptor.
+	CDCVersion gousb.BCD
+	// ControlInterface and DataInterfaces come from the union functional
+	// descriptor.
+	ControlInterface int
+	DataInterfaces   []int
+	// Ethernet is the Ethernet networking functional descriptor.
+	Ethernet EthernetDesc
+	// NCM is the NCM functional descriptor, nil for ECM interfaces.
+	NCM *NCMDesc
+}
+
+func init() {
+	parse := func(s gousb.InterfaceSetting) (interface{}, error) {
+		return ParseFunctionalDescs(s.Extra)
+	}
+	gousb.RegisterClassDescriptorParser(gousb.ClassComm, SubClassECM, parse)
+	gousb.RegisterClassDescriptorParser(gousb.ClassComm, SubClassNCM, parse)
+}
+
+// ParseFunctionalDescs decodes the functional descriptors of an ECM or
+// NCM control interface from the Extra field of its interface setting.
+// The union and Ethernet networking descriptors are required.
+func ParseFunctionalDescs(extra []byte) (*FunctionalDescs, error) {
+	fd := &FunctionalDescs{}
+	var union, eth bool
+	le := binary.LittleEndian
+	for off := 0; off+2 <= len(extra); {
+		l := int(extra[off])
+		if l < 2 || off+l > len(extra) {
+			return nil, fmt.Errorf("invalid descriptor length %d at offset %d", l, off)
+		}
+		d := extra[off : off+l]
+		off += l
+		if d[1] != descTypeCSInterface || l < 3 {
+			continue
+		}
+		switch d[2] {
+		case subtypeHeader:
+			if l < 5 {
+				return nil, fmt.Errorf("header functional descriptor too short: % x", d)
+			}
+			fd.CDCVersion = gousb.BCD(le.Uint16(d[3:]))
+		case subtypeUnion:
+			if l < 5 {
+				return nil, fmt.Errorf("union functional descriptor too short: % x", d)
+			}
+			fd.ControlInterface = int(d[3])
+			for _, n := range d[4:] {
+				fd.DataInterfaces = append(fd.DataInterfaces, int(n))
+			}
+			union = true
+		case subtypeEthernet:
+			if l < 13 {
+				return nil, fmt.Errorf("Ethernet networking functional descriptor too short: % x", d)
+			}
+			fd.Ethernet = EthernetDesc{
+				MACAddressIndex:  int(d[3]),
+				Statistics:       le.Uint32(d[4:]),
+				MaxSegmentSize:   int(le.Uint16(d[8:])),
+				MulticastFilters: le.Uint16(d[10:]),
+				PowerFilters:     int(d[12]),
+			}
+			eth = true
+		case subtypeNCM:
+			if l < 6 {
+				return nil, fmt.Errorf("NCM functional descriptor too short: % x", d)
+			}
+			fd.NCM = &NCMDesc{
+				Version:      gousb.BCD(le.Uint16(d[3:])),
+				Capabilities: d[5],
+			}
+		}
+	}
+	if !union {
+		return nil, fmt.Errorf("no union functional descriptor")
+	}
+	if !eth {
+		return nil, fmt.Errorf("no Ethernet networking functional descriptor")
+	}
+	return fd, nil
+}
+
+// parseMAC decodes a MAC address written as 12 hex digits.
+func parseMAC(s string) (net.HardwareAddr, error) {
+	if len(s) != 12 {
+		return nil, fmt.Errorf("MAC address %q is not 12 hex digits", s)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("MAC address %q is not 12 hex digits", s)
+	}
+	return net.HardwareAddr(b), nil
+}
+
+// MACAddress reads the MAC address of the function from the string
+// descriptor referenced by its Ethernet networking functional descriptor.
+func MACAddress(r StringReader, fd *FunctionalDescs) (net.HardwareAddr, error) {
+	if fd.Ethernet.MACAddressIndex == 0 {
+		return nil, fmt.Errorf("the function has no MAC address string")
+	}
+	s, err := r.GetStringDescriptor(fd.Ethernet.MACAddressIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the MAC address string: %v", err)
+	}
+	return parseMAC(s)
+}
+
+// ReadNTBParams reads the NTB parameters of the NCM control interface intf.
+func ReadNTBParams(c Controller, intf int) (NTBParams, error) {
+	buf := make([]byte, ntbParamsSize)
+	n, err := c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlInterface, requestGetNTBParameters, 0, uint16(intf), buf)
+	if err != nil {
+		return NTBParams{}, fmt.Errorf("failed to read the NTB parameters: %v", err)
+	}
+	return parseNTBParams(buf[:n])
+}
+
+// Device is an ECM or NCM function of a device.
+type Device struct {
+	// Func holds the functional descriptors of the control interface.
+	Func *FunctionalDescs
+	// HardwareAddr is the MAC address of the function.
+	HardwareAddr net.HardwareAddr
+	// NTB holds the NTB parameters of an NCM function.
+	NTB NTBParams
+
+	c    Controller
+	intf uint16
+	in   gousb.MessageReader
+	out  io.Writer
+	done func()
+
+	rmu     sync.Mutex
+	pending [][]byte
+
+	wmu sync.Mutex
+	seq uint16
+}
+
+// New returns a Device for the function with the functional descriptors
+// fd, which exchanges Ethernet frames through in and out, the bulk
+// endpoints of its data interface. intf is the number of the control
+// interface, the target of the class requests sent through c. The NTB
+// parameters of an NCM function are read with ReadNTBParams.
+//
+// Writes to out need to be terminated with a short or zero-length packet,
+// see gousb.OutEndpoint.SetZeroPacket.
+func New(c Controller, intf int, fd *FunctionalDescs, in gousb.MessageReader, out io.Writer) (*Device, error) {
+	d := &Device{Func: fd, c: c, intf: uint16(intf), in: in, out: out}
+	if fd.NCM != nil {
+		p, err := ReadNTBParams(c, intf)
+		if err != nil {
+			return nil, err
+		}
+		if p.FormatsSupported&NTBFormat16 == 0 {
+			return nil, fmt.Errorf("the NCM function doesn't support NTBs with 16 bit offsets")
+		}
+		d.NTB = p
+	}
+	return d, nil
+}
+
+// Open claims the ECM or NCM control interface ctrl of the configuration
+// cfg and its data interface, and returns a Device for the function.
+// Open selects NTBs with 16 bit offsets for NCM functions, reads the MAC
+// address and sets DefaultFilter. The interfaces are released by Close.
+func Open(dev *gousb.Device, cfg *gousb.Config, ctrl int) (_ *Device, err error) {
+	var setting *gousb.InterfaceSetting
+	var data *gousb.InterfaceDesc
+	for i, intf := range cfg.Desc.Interfaces {
+		if intf.Number == ctrl && len(intf.AltSettings) > 0 {
+			setting = &cfg.Desc.Interfaces[i].AltSettings[0]
+		}
+	}
+	if setting == nil || !(IsECM(*setting) || IsNCM(*setting)) {
+		return nil, fmt.Errorf("interface %d of %s is not an ECM or NCM control interface", ctrl, cfg)
+	}
+	fd, ok := setting.ClassDesc.(*FunctionalDescs)
+	if !ok {
+		if fd, err = ParseFunctionalDescs(setting.Extra); err != nil {
+			return nil, fmt.Errorf("interface %d of %s: %v", ctrl, cfg, err)
+		}
+	}
+	if len(fd.DataInterfaces) == 0 {
+		return nil, fmt.Errorf("interface %d of %s has no data interface", ctrl, cfg)
+	}
+	for i, intf := range cfg.Desc.Interfaces {
+		if intf.Number == fd.DataInterfaces[0] {
+			data = &cfg.Desc.Interfaces[i]
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("data interface %d of %s not found", fd.DataInterfaces[0], cfg)
+	}
+	// The data interface has no endpoints in its default setting, the
+	// function is enabled by selecting the one with the bulk endpoints.
+	var inEP, outEP gousb.EndpointDesc
+	alt := -1
+	for _, s := range data.AltSettings {
+		i, iok := s.BulkIn()
+		o, ook := s.BulkOut()
+		if iok && ook {
+			alt, inEP, outEP = s.Alternate, i, o
+			break
+		}
+	}
+	if alt < 0 {
+		return nil, fmt.Errorf("data interface %d of %s has no setting with bulk endpoints", data.Number, cfg)
+	}
+
+	ci, err := cfg.Interface(ctrl, 0)
+	if err != nil {
+		return nil, err
+	}
+	var di *gousb.Interface
+	defer func() {
+		if err != nil {
+			if di != nil {
+				di.Close()
+			}
+			ci.Close()
+		}
+	}()
+	d, err := New(dev, ctrl, fd, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if fd.NCM != nil && d.NTB.FormatsSupported&NTBFormat32 != 0 {
+		// The format can only be changed while the data interface is
+		// in its default setting.
+		if _, err := dev.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, requestSetNTBFormat, 0, uint16(ctrl), nil); err != nil {
+			return nil, fmt.Errorf("failed to select NTBs with 16 bit offsets: %v", err)
+		}
+	}
+	if di, err = cfg.Interface(data.Number, alt); err != nil {
+		return nil, err
+	}
+	in, err := di.InEndpoint(inEP.Number)
+	if err != nil {
+		return nil, err
+	}
+	out, err := di.OutEndpoint(outEP.Number)
+	if err != nil {
+		return nil, err
+	}
+	out.SetZeroPacket(true)
+	d.in, d.out = in, out
+	if d.HardwareAddr, err = MACAddress(dev, fd); err != nil {
+		return nil, err
+	}
+	// Some devices don't implement the request and pass all frames
+	// anyway, a failure isn't fatal.
+	d.SetPacketFilter(DefaultFilter)
+	d.done = func() {
+		di.Close()
+		ci.Close()
+	}
+	return d, nil
+}
+
+// SetPacketFilter selects the frames the device passes to the host.
+func (d *Device) SetPacketFilter(f PacketFilter) error {
+	if _, err := d.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, requestSetEthernetPacketFilter, uint16(f), d.intf, nil); err != nil {
+		return fmt.Errorf("failed to set the packet filter to %#x: %v", uint16(f), err)
+	}
+	return nil
+}
+
+// MTU returns the maximum length of the payload of an Ethernet frame.
+func (d *Device) MTU() int {
+	if d.Func.Ethernet.MaxSegmentSize > 14 {
+		return d.Func.Ethernet.MaxSegmentSize - 14
+	}
+	return 1500
+}
+
+// ReadPacket returns the next Ethernet frame received from the device,
+// without the CRC. For NCM functions, the frames of a received NTB are
+// returned by consecutive calls.
+func (d *Device) ReadPacket() ([]byte, error) {
+	d.rmu.Lock()
+	defer d.rmu.Unlock()
+	for len(d.pending) == 0 {
+		msg, err := d.in.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) == 0 {
+			continue
+		}
+		if d.Func.NCM == nil {
+			return msg, nil
+		}
+		if d.pending, err = DecodeNTB16(msg); err != nil {
+			return nil, err
+		}
+	}
+	p := d.pending[0]
+	d.pending = d.pending[1:]
+	return p, nil
+}
+
+// WritePacket sends the Ethernet frame p, without the CRC, to the device.
+// For NCM functions, the frame is sent in an NTB by itself.
+func (d *Device) WritePacket(p []byte) error {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	if d.Func.NCM != nil {
+		ntb, err := EncodeNTB16(d.seq, [][]byte{p}, d.NTB)
+		if err != nil {
+			return err
+		}
+		d.seq++
+		p = ntb
+	}
+	n, err := d.out.Write(p)
+	if err != nil {
+		return err
+	}
+	if n < len(p) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// Close releases the interfaces claimed by Open. Transfers in progress
+// are cancelled.
+func (d *Device) Close() error {
+	if d.done != nil {
+		d.done()
+		d.done = nil
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdcnet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/internal/usbtest"
+)
+
+// ncmFuncDescs are the functional descriptors of an NCM control
+// interface 0 with data interface 1 and the MAC address in string 5.
+var ncmFuncDescs = []byte{
+	0x05, 0x24, 0x00, 0x10, 0x01, // header, CDC 1.10
+	0x05, 0x24, 0x06, 0x00, 0x01, // union, control 0, data 1
+	0x0d, 0x24, 0x0f, 0x05, 0x00, 0x00, 0x00, 0x00, 0xea, 0x05, 0x00, 0x00, 0x00, // Ethernet, MAC string 5, segment size 1514
+	0x06, 0x24, 0x1a, 0x00, 0x01, 0x00, // NCM 1.0
+}
+
+func TestParseFunctionalDescs(t *testing.T) {
+	got, err := ParseFunctionalDescs(ncmFuncDescs)
+	if err != nil {
+		t.Fatalf("ParseFunctionalDescs(): %v", err)
+	}
+	want := &FunctionalDescs{
+		CDCVersion:       0x0110,
+		ControlInterface: 0,
+		DataInterfaces:   []int{1},
+		Ethernet:         EthernetDesc{MACAddressIndex: 5, MaxSegmentSize: 1514},
+		NCM:              &NCMDesc{Version: 0x0100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFunctionalDescs(): got %+v, want %+v", got, want)
+	}
+
+	for _, tc := range []struct {
+		desc  string
+		extra []byte
+	}{
+		{"no union", ncmFuncDescs[10:]},
+		{"no Ethernet", ncmFuncDescs[:10]},
+		{"truncated", ncmFuncDescs[:len(ncmFuncDescs)-1]},
+	} {
+		if _, err := ParseFunctionalDescs(tc.extra); err == nil {
+			t.Errorf("ParseFunctionalDescs(%s): got nil error, want non-nil", tc.desc)
+		}
+	}
+}
+
+func TestClassDesc(t *testing.T) {
+	cfg := []byte{0x09, 0x02, 0x00, 0x00, 0x02, 0x01, 0x00, 0x80, 0x32}
+	cfg = append(cfg, 0x09, 0x04, 0x00, 0x00, 0x00, 0x02, 0x0d, 0x00, 0x00)
+	cfg = append(cfg, ncmFuncDescs...)
+	cfg = append(cfg, 0x09, 0x04, 0x01, 0x00, 0x00, 0x0a, 0x00, 0x01, 0x00)
+	cfg[2] = byte(len(cfg))
+	desc, err := gousb.ParseConfigDescriptor(cfg, gousb.SpeedHigh)
+	if err != nil {
+		t.Fatalf("ParseConfigDescriptor(): %v", err)
+	}
+	s := desc.Interfaces[0].AltSettings[0]
+	if !IsNCM(s) || IsECM(s) {
+		t.Errorf("IsNCM(), IsECM(): got %v, %v, want true, false", IsNCM(s), IsECM(s))
+	}
+	fd, ok := s.ClassDesc.(*FunctionalDescs)
+	if !ok {
+		t.Fatalf("ClassDesc: got %T (%v), want *FunctionalDescs", s.ClassDesc, s.ClassDesc)
+	}
+	if fd.NCM == nil || !reflect.DeepEqual(fd.DataInterfaces, []int{1}) {
+		t.Errorf("ClassDesc: got %+v, want the NCM function with data interface 1", fd)
+	}
+}
+
+type fakeStrings map[int]string
+
+func (f fakeStrings) GetStringDescriptor(idx int) (string, error) {
+	s, ok := f[idx]
+	if !ok {
+		return "", errors.New("no such string")
+	}
+	return s, nil
+}
+
+func TestMACAddress(t *testing.T) {
+	fd := &FunctionalDescs{Ethernet: EthernetDesc{MACAddressIndex: 5}}
+	got, err := MACAddress(fakeStrings{5: "02005E10A0FF"}, fd)
+	if err != nil {
+		t.Fatalf("MACAddress(): %v", err)
+	}
+	if want := (net.HardwareAddr{0x02, 0x00, 0x5e, 0x10, 0xa0, 0xff}); !bytes.Equal(got, want) {
+		t.Errorf("MACAddress(): got %s, want %s", got, want)
+	}
+	for _, s := range []string{"02005E10A0", "02005E10A0FG"} {
+		if _, err := MACAddress(fakeStrings{5: s}, fd); err == nil {
+			t.Errorf("MACAddress(%q): got nil error, want non-nil", s)
+		}
+	}
+	if _, err := MACAddress(fakeStrings{}, &FunctionalDescs{}); err == nil {
+		t.Error("MACAddress() without a MAC string: got nil error, want non-nil")
+	}
+}
+
+// fakeNCM answers the NCM class requests.
+type fakeNCM struct {
+	filter uint16
+}
+
+func (f *fakeNCM) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	switch request {
+	case requestGetNTBParameters:
+		return copy(data, []byte{
+			0x1c, 0x00, 0x01, 0x00, 0x00, 0x40, 0x00, 0x00,
+			0x04, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00,
+			0x00, 0x20, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00,
+		}), nil
+	case requestSetEthernetPacketFilter:
+		f.filter = val
+		return 0, nil
+	}
+	return 0, errors.New("unsupported request")
+}
+
+func TestDeviceECM(t *testing.T) {
+	fd := &FunctionalDescs{Ethernet: EthernetDesc{MaxSegmentSize: 1514}}
+	in := &usbtest.Messages{[]byte("frame 1"), {}, []byte("frame 2")}
+	var out bytes.Buffer
+	d, err := New(&fakeNCM{}, 0, fd, in, &out)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if got := d.MTU(); got != 1500 {
+		t.Errorf("MTU(): got %d, want 1500", got)
+	}
+	for _, want := range []string{"frame 1", "frame 2"} {
+		p, err := d.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket(): %v", err)
+		}
+		if string(p) != want {
+			t.Errorf("ReadPacket(): got %q, want %q", p, want)
+		}
+	}
+	if _, err := d.ReadPacket(); err != io.EOF {
+		t.Errorf("ReadPacket() at the end: got %v, want io.EOF", err)
+	}
+	if err := d.WritePacket([]byte("sent")); err != nil {
+		t.Fatalf("WritePacket(): %v", err)
+	}
+	if got := out.String(); got != "sent" {
+		t.Errorf("written data: got %q, want %q", got, "sent")
+	}
+}
+
+func TestDeviceNCM(t *testing.T) {
+	fd, err := ParseFunctionalDescs(ncmFuncDescs)
+	if err != nil {
+		t.Fatalf("ParseFunctionalDescs(): %v", err)
+	}
+	ntb, err := EncodeNTB16(0, [][]byte{[]byte("frame 1"), []byte("frame 2")}, NTBParams{})
+	if err != nil {
+		t.Fatalf("EncodeNTB16(): %v", err)
+	}
+	c := &fakeNCM{}
+	in := &usbtest.Messages{ntb}
+	var out bytes.Buffer
+	d, err := New(c, 0, fd, in, &out)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if d.NTB.OutMaxSize != 0x2000 {
+		t.Errorf("NTB.OutMaxSize: got %#x, want 0x2000", d.NTB.OutMaxSize)
+	}
+	if err := d.SetPacketFilter(DefaultFilter); err != nil {
+		t.Fatalf("SetPacketFilter(): %v", err)
+	}
+	if c.filter != uint16(DefaultFilter) {
+		t.Errorf("packet filter: got %#x, want %#x", c.filter, uint16(DefaultFilter))
+	}
+	for _, want := range []string{"frame 1", "frame 2"} {
+		p, err := d.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket(): %v", err)
+		}
+		if string(p) != want {
+			t.Errorf("ReadPacket(): got %q, want %q", p, want)
+		}
+	}
+
+	for i, frame := range []string{"sent 1", "sent 2"} {
+		out.Reset()
+		if err := d.WritePacket([]byte(frame)); err != nil {
+			t.Fatalf("WritePacket(): %v", err)
+		}
+		got, err := DecodeNTB16(out.Bytes())
+		if err != nil {
+			t.Fatalf("DecodeNTB16(written NTB): %v", err)
+		}
+		if len(got) != 1 || string(got[0]) != frame {
+			t.Errorf("written NTB datagrams: got %q, want [%q]", got, frame)
+		}
+		if seq := int(out.Bytes()[6]); seq != i {
+			t.Errorf("written NTB sequence: got %d, want %d", seq, i)
+		}
+	}
+}
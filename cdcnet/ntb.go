@@ -0,0 +1,189 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdcnet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// NCM transfer blocks (NTBs) with 16 bit offsets. The NTB header (NTH16)
+// is followed by one or more NTB datagram pointer tables (NDP16), which
+// point at the Ethernet frames in the block.
+const (
+	nth16Signature = 0x484d434e // "NCMH"
+	ndp16Signature = 0x304d434e // "NCM0", without CRCs
+	nth16Size      = 12
+	ndp16HdrSize   = 8
+	ndp16EntrySize = 4
+
+	ntbParamsSize = 28
+)
+
+// NTB formats, the bits of NTBParams.FormatsSupported.
+const (
+	NTBFormat16 = 0x01
+	NTBFormat32 = 0x02
+)
+
+// NTBParams are the NTB parameters of an NCM function, returned by the
+// GET_NTB_PARAMETERS request.
+type NTBParams struct {
+	// FormatsSupported is a bitmask of NTBFormat16 and NTBFormat32.
+	FormatsSupported uint16
+	// InMaxSize is the maximum length of an NTB sent by the device.
+	InMaxSize int
+	// InDivisor, InPayloadRemainder and InAlignment describe the
+	// alignment of datagrams and NDPs in NTBs sent by the device.
+	InDivisor          int
+	InPayloadRemainder int
+	InAlignment        int
+	// OutMaxSize is the maximum length of an NTB sent to the device.
+	OutMaxSize int
+	// Datagrams in NTBs sent to the device start at an offset equal to
+	// OutPayloadRemainder modulo OutDivisor. NDPs start at a multiple of
+	// OutAlignment.
+	OutDivisor          int
+	OutPayloadRemainder int
+	OutAlignment        int
+	// OutMaxDatagrams is the maximum number of datagrams in an NTB sent
+	// to the device, or 0 if there's no limit.
+	OutMaxDatagrams int
+}
+
+// parseNTBParams decodes the NTB parameter structure b.
+func parseNTBParams(b []byte) (NTBParams, error) {
+	if len(b) < ntbParamsSize {
+		return NTBParams{}, fmt.Errorf("NTB parameters too short: % x", b)
+	}
+	le := binary.LittleEndian
+	return NTBParams{
+		FormatsSupported:    le.Uint16(b[2:]),
+		InMaxSize:           int(le.Uint32(b[4:])),
+		InDivisor:           int(le.Uint16(b[8:])),
+		InPayloadRemainder:  int(le.Uint16(b[10:])),
+		InAlignment:         int(le.Uint16(b[12:])),
+		OutMaxSize:          int(le.Uint32(b[16:])),
+		OutDivisor:          int(le.Uint16(b[20:])),
+		OutPayloadRemainder: int(le.Uint16(b[22:])),
+		OutAlignment:        int(le.Uint16(b[24:])),
+		OutMaxDatagrams:     int(le.Uint16(b[26:])),
+	}, nil
+}
+
+// alignUp returns the smallest offset >= off that's equal to rem modulo
+// div. A div below 4 is treated as 4, the minimum allowed by the spec.
+func alignUp(off, div, rem int) int {
+	if div < 4 {
+		div = 4
+	}
+	rem %= div
+	if d := (rem - off%div + div) % div; d > 0 {
+		off += d
+	}
+	return off
+}
+
+// EncodeNTB16 packs the Ethernet frames datagrams into a single NTB with
+// 16 bit offsets and sequence number seq, following the alignment
+// requirements and limits of p for NTBs sent to the device.
+func EncodeNTB16(seq uint16, datagrams [][]byte, p NTBParams) ([]byte, error) {
+	if len(datagrams) == 0 {
+		return nil, fmt.Errorf("NTB without datagrams")
+	}
+	if p.OutMaxDatagrams > 0 && len(datagrams) > p.OutMaxDatagrams {
+		return nil, fmt.Errorf("%d datagrams exceed the limit of %d datagrams per NTB", len(datagrams), p.OutMaxDatagrams)
+	}
+	// The NDP directly follows the header, with a zero entry
+	// terminating the list of datagrams.
+	ndpOff := alignUp(nth16Size, p.OutAlignment, 0)
+	ndpLen := ndp16HdrSize + ndp16EntrySize*(len(datagrams)+1)
+	offs := make([]int, len(datagrams))
+	end := ndpOff + ndpLen
+	for i, d := range datagrams {
+		offs[i] = alignUp(end, p.OutDivisor, p.OutPayloadRemainder)
+		end = offs[i] + len(d)
+	}
+	max := 0xffff
+	if p.OutMaxSize > 0 && p.OutMaxSize < max {
+		max = p.OutMaxSize
+	}
+	if end > max {
+		return nil, fmt.Errorf("NTB of %d bytes exceeds the maximum NTB size %d", end, max)
+	}
+
+	le := binary.LittleEndian
+	b := make([]byte, end)
+	le.PutUint32(b[0:], nth16Signature)
+	le.PutUint16(b[4:], nth16Size)
+	le.PutUint16(b[6:], seq)
+	le.PutUint16(b[8:], uint16(end))
+	le.PutUint16(b[10:], uint16(ndpOff))
+	ndp := b[ndpOff:]
+	le.PutUint32(ndp[0:], ndp16Signature)
+	le.PutUint16(ndp[4:], uint16(ndpLen))
+	le.PutUint16(ndp[6:], 0)
+	for i, d := range datagrams {
+		e := ndp[ndp16HdrSize+ndp16EntrySize*i:]
+		le.PutUint16(e[0:], uint16(offs[i]))
+		le.PutUint16(e[2:], uint16(len(d)))
+		copy(b[offs[i]:], d)
+	}
+	return b, nil
+}
+
+// DecodeNTB16 returns the datagrams of the NTB with 16 bit offsets b.
+// The returned slices point into b.
+func DecodeNTB16(b []byte) ([][]byte, error) {
+	le := binary.LittleEndian
+	if len(b) < nth16Size {
+		return nil, fmt.Errorf("NTB too short: % x", b)
+	}
+	if sig := le.Uint32(b[0:]); sig != nth16Signature {
+		return nil, fmt.Errorf("invalid NTH16 signature %#08x", sig)
+	}
+	if l := int(le.Uint16(b[8:])); l != 0 && l < len(b) {
+		b = b[:l]
+	}
+	var datagrams [][]byte
+	// seen guards against NDPs pointing at each other.
+	seen := make(map[int]bool)
+	for off := int(le.Uint16(b[10:])); off != 0; {
+		if seen[off] || off%4 != 0 || off+ndp16HdrSize > len(b) {
+			return nil, fmt.Errorf("invalid NDP16 offset %d in NTB of %d bytes", off, len(b))
+		}
+		seen[off] = true
+		ndp := b[off:]
+		if sig := le.Uint32(ndp[0:]); sig != ndp16Signature {
+			return nil, fmt.Errorf("unsupported NDP16 signature %#08x at offset %d", sig, off)
+		}
+		l := int(le.Uint16(ndp[4:]))
+		if l < ndp16HdrSize+ndp16EntrySize || off+l > len(b) {
+			return nil, fmt.Errorf("invalid NDP16 length %d at offset %d", l, off)
+		}
+		for e := ndp[ndp16HdrSize:l]; len(e) >= ndp16EntrySize; e = e[ndp16EntrySize:] {
+			doff, dlen := int(le.Uint16(e[0:])), int(le.Uint16(e[2:]))
+			if doff == 0 || dlen == 0 {
+				break
+			}
+			if doff+dlen > len(b) {
+				return nil, fmt.Errorf("datagram at offset %d with length %d exceeds the NTB of %d bytes", doff, dlen, len(b))
+			}
+			datagrams = append(datagrams, b[doff:doff+dlen])
+		}
+		off = int(le.Uint16(ndp[6:]))
+	}
+	return datagrams, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdcnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestNTB16RoundTrip(t *testing.T) {
+	p := NTBParams{OutDivisor: 4, OutPayloadRemainder: 2, OutAlignment: 4}
+	frames := [][]byte{[]byte("first frame"), []byte("second")}
+	b, err := EncodeNTB16(7, frames, p)
+	if err != nil {
+		t.Fatalf("EncodeNTB16(): %v", err)
+	}
+	le := binary.LittleEndian
+	if got := le.Uint16(b[6:]); got != 7 {
+		t.Errorf("NTB sequence: got %d, want 7", got)
+	}
+	if got := int(le.Uint16(b[8:])); got != len(b) {
+		t.Errorf("NTB block length: got %d, want %d", got, len(b))
+	}
+	ndp := b[le.Uint16(b[10:]):]
+	for i := range frames {
+		if off := le.Uint16(ndp[ndp16HdrSize+ndp16EntrySize*i:]); off%4 != 2 {
+			t.Errorf("datagram %d at offset %d, want 2 modulo 4", i, off)
+		}
+	}
+	got, err := DecodeNTB16(b)
+	if err != nil {
+		t.Fatalf("DecodeNTB16(): %v", err)
+	}
+	if len(got) != len(frames) {
+		t.Fatalf("DecodeNTB16(): got %d datagrams, want %d", len(got), len(frames))
+	}
+	for i := range frames {
+		if !bytes.Equal(got[i], frames[i]) {
+			t.Errorf("datagram %d: got %q, want %q", i, got[i], frames[i])
+		}
+	}
+}
+
+func TestEncodeNTB16Limits(t *testing.T) {
+	frame := make([]byte, 100)
+	if _, err := EncodeNTB16(0, [][]byte{frame}, NTBParams{OutMaxSize: 100}); err == nil {
+		t.Error("EncodeNTB16() exceeding OutMaxSize: got nil error, want non-nil")
+	}
+	if _, err := EncodeNTB16(0, [][]byte{frame, frame}, NTBParams{OutMaxDatagrams: 1}); err == nil {
+		t.Error("EncodeNTB16() exceeding OutMaxDatagrams: got nil error, want non-nil")
+	}
+	if _, err := EncodeNTB16(0, nil, NTBParams{}); err == nil {
+		t.Error("EncodeNTB16() without datagrams: got nil error, want non-nil")
+	}
+}
+
+func TestDecodeNTB16Invalid(t *testing.T) {
+	valid, err := EncodeNTB16(0, [][]byte{[]byte("frame")}, NTBParams{})
+	if err != nil {
+		t.Fatalf("EncodeNTB16(): %v", err)
+	}
+	for _, tc := range []struct {
+		desc   string
+		modify func(b []byte) []byte
+	}{
+		{"short", func(b []byte) []byte { return b[:8] }},
+		{"bad NTH signature", func(b []byte) []byte { b[0] = 'x'; return b }},
+		{"NDP out of range", func(b []byte) []byte { binary.LittleEndian.PutUint16(b[10:], 0x1000); return b }},
+		{"bad NDP signature", func(b []byte) []byte { b[12] = 'x'; return b }},
+		{"NDP loop", func(b []byte) []byte { binary.LittleEndian.PutUint16(b[18:], 12); return b }},
+		{"datagram out of range", func(b []byte) []byte { binary.LittleEndian.PutUint16(b[22:], 0x1000); return b }},
+	} {
+		b := tc.modify(append([]byte(nil), valid...))
+		if _, err := DecodeNTB16(b); err == nil {
+			t.Errorf("DecodeNTB16(%s): got nil error, want non-nil", tc.desc)
+		}
+	}
+}
+
+func TestParseNTBParams(t *testing.T) {
+	b := []byte{
+		0x1c, 0x00, 0x01, 0x00, // wLength, bmNtbFormatsSupported
+		0x00, 0x40, 0x00, 0x00, // dwNtbInMaxSize
+		0x04, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, // in divisor, remainder, alignment, reserved
+		0x00, 0x20, 0x00, 0x00, // dwNtbOutMaxSize
+		0x04, 0x00, 0x02, 0x00, 0x04, 0x00, 0x10, 0x00, // out divisor, remainder, alignment, max datagrams
+	}
+	got, err := parseNTBParams(b)
+	if err != nil {
+		t.Fatalf("parseNTBParams(): %v", err)
+	}
+	want := NTBParams{
+		FormatsSupported: NTBFormat16,
+		InMaxSize:        0x4000, InDivisor: 4, InAlignment: 4,
+		OutMaxSize: 0x2000, OutDivisor: 4, OutPayloadRemainder: 2, OutAlignment: 4, OutMaxDatagrams: 16,
+	}
+	if got != want {
+		t.Errorf("parseNTBParams(): got %+v, want %+v", got, want)
+	}
+	if _, err := parseNTBParams(b[:20]); err == nil {
+		t.Error("parseNTBParams(short): got nil error, want non-nil")
+	}
+}
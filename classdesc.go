@@ -0,0 +1,73 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ClassDescriptorParser decodes the class-specific descriptors of an
+// interface alternate setting, usually from s.Extra and the Extra fields of
+// its endpoints, into a typed structure defined by the class package.
+type ClassDescriptorParser func(s InterfaceSetting) (interface{}, error)
+
+type classKey struct {
+	class, subClass Class
+}
+
+var classParsers = struct {
+	sync.RWMutex
+	m map[classKey]ClassDescriptorParser
+}{m: make(map[classKey]ClassDescriptorParser)}
+
+// RegisterClassDescriptorParser registers the parser of the class-specific
+// descriptors of interfaces with the given class and subclass. It's meant
+// to be called from the init function of a class package, e.g. a HID
+// package registering ClassHID with subclasses 0 and SubClassHIDBoot.
+//
+// Descriptors decoded by libusb, by ParseConfigDescriptor and by
+// InterfaceSetting.UnmarshalJSON have the result stored in
+// InterfaceSetting.ClassDesc. RegisterClassDescriptorParser panics if
+// the class and subclass already have a parser.
+func RegisterClassDescriptorParser(class, subClass Class, p ClassDescriptorParser) {
+	classParsers.Lock()
+	defer classParsers.Unlock()
+	k := classKey{class, subClass}
+	if p == nil {
+		panic(fmt.Sprintf("gousb: RegisterClassDescriptorParser(%s, %d) with a nil parser", class, uint8(subClass)))
+	}
+	if _, ok := classParsers.m[k]; ok {
+		panic(fmt.Sprintf("gousb: RegisterClassDescriptorParser called twice for class %s, subclass %d", class, uint8(subClass)))
+	}
+	classParsers.m[k] = p
+}
+
+// parseClassDesc sets s.ClassDesc using the parser registered for the
+// class and subclass of s, if any.
+func parseClassDesc(s *InterfaceSetting) {
+	classParsers.RLock()
+	p := classParsers.m[classKey{s.Class, s.SubClass}]
+	classParsers.RUnlock()
+	if p == nil {
+		return
+	}
+	v, err := p(*s)
+	if err != nil {
+		s.ClassDesc = fmt.Errorf("failed to parse the %s class descriptors of %s: %v", s.Class, s, err)
+		return
+	}
+	s.ClassDesc = v
+}
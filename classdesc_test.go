@@ -0,0 +1,95 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testClassDesc is the decoded class-specific descriptor of the test
+// parser.
+type testClassDesc struct {
+	Type  uint8
+	Value uint8
+}
+
+func init() {
+	RegisterClassDescriptorParser(ClassVendorSpec, 0x42, func(s InterfaceSetting) (interface{}, error) {
+		if len(s.Extra) < 3 {
+			return nil, errors.New("no class-specific descriptor")
+		}
+		return &testClassDesc{Type: s.Extra[1], Value: s.Extra[2]}, nil
+	})
+}
+
+func TestClassDescriptorParser(t *testing.T) {
+	raw := []byte{
+		0x09, 0x02, 0x1e, 0x00, 0x03, 0x01, 0x00, 0x80, 0x32,
+		0x09, 0x04, 0x00, 0x00, 0x00, 0xff, 0x42, 0x00, 0x00, // interface 0, parsed
+		0x03, 0x24, 0x07, // class-specific descriptor
+		0x09, 0x04, 0x01, 0x00, 0x00, 0xff, 0x42, 0x00, 0x00, // interface 1, parser fails
+	}
+	raw[2] = byte(len(raw))
+	c, err := ParseConfigDescriptor(raw, SpeedHigh)
+	if err != nil {
+		t.Fatalf("ParseConfigDescriptor(): %v", err)
+	}
+	alt := c.Interfaces[0].AltSettings[0]
+	if want := (&testClassDesc{Type: 0x24, Value: 0x07}); !reflect.DeepEqual(alt.ClassDesc, want) {
+		t.Errorf("%s: got ClassDesc %#v, want %#v", alt, alt.ClassDesc, want)
+	}
+	if alt := c.Interfaces[1].AltSettings[0]; !isError(alt.ClassDesc) {
+		t.Errorf("%s: got ClassDesc %#v, want an error", alt, alt.ClassDesc)
+	}
+
+	b, err := json.Marshal(alt)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %v", err)
+	}
+	var got InterfaceSetting
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", b, err)
+	}
+	if !reflect.DeepEqual(got.ClassDesc, alt.ClassDesc) {
+		t.Errorf("json.Unmarshal(%s): got ClassDesc %#v, want %#v", b, got.ClassDesc, alt.ClassDesc)
+	}
+}
+
+func isError(v interface{}) bool {
+	_, ok := v.(error)
+	return ok
+}
+
+func TestRegisterClassDescriptorParserPanics(t *testing.T) {
+	for _, tc := range []struct {
+		subClass Class
+		p        ClassDescriptorParser
+	}{
+		{0x42, func(InterfaceSetting) (interface{}, error) { return nil, nil }},
+		{0x43, nil},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterClassDescriptorParser(%s, %d): did not panic", ClassVendorSpec, uint8(tc.subClass))
+				}
+			}()
+			RegisterClassDescriptorParser(ClassVendorSpec, tc.subClass, tc.p)
+		}()
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gousb-bench measures the throughput and latency of the endpoints of
+// a USB device, e.g. a development board running loopback firmware.
+//
+// Usage:
+//
+//	gousb-bench -d vid:pid [-mode loopback|read|write] [-out 1] [-in 2] [-size 16384] [-n 1000]
+//
+// In loopback mode, every buffer written to the OUT endpoint is expected
+// to be returned on the IN endpoint and is verified.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/bench"
+)
+
+var (
+	devFlag = flag.String("d", "", "Vendor and product ID of the device, as vid:pid in hex.")
+	mode    = flag.String("mode", "loopback", "Benchmark to run: loopback, read or write.")
+	cfgNum  = flag.Int("cfg", 1, "Configuration number.")
+	intfNum = flag.Int("intf", 0, "Interface number.")
+	altNum  = flag.Int("alt", 0, "Alternate setting number.")
+	outEp   = flag.Int("out", 1, "OUT endpoint number.")
+	inEp    = flag.Int("in", 1, "IN endpoint number.")
+	size    = flag.Int("size", 16384, "Size of a single transfer in bytes.")
+	count   = flag.Int("n", 1000, "Number of transfers.")
+	timeout = flag.Duration("timeout", time.Minute, "Timeout of the whole benchmark.")
+)
+
+func parseVIDPID(s string) (gousb.ID, gousb.ID, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("-d %q: want vid:pid, e.g. 04b4:1004", s)
+	}
+	vid, err := strconv.ParseUint(parts[0], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("-d %q: invalid vendor ID: %v", s, err)
+	}
+	pid, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("-d %q: invalid product ID: %v", s, err)
+	}
+	return gousb.ID(vid), gousb.ID(pid), nil
+}
+
+func main() {
+	flag.Parse()
+	vid, pid, err := parseVIDPID(*devFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(vid, pid)
+	if dev == nil {
+		log.Fatalf("open %s:%s: device not found (%v)", vid, pid, err)
+	}
+	defer dev.Close()
+	if err := dev.SetAutoDetach(true); err != nil && !errors.Is(err, gousb.ErrorNotSupported) {
+		log.Fatalf("%s.SetAutoDetach(true): %v", dev, err)
+	}
+	cfg, err := dev.Config(*cfgNum)
+	if err != nil {
+		log.Fatalf("%s.Config(%d): %v", dev, *cfgNum, err)
+	}
+	defer cfg.Close()
+	intf, err := cfg.Interface(*intfNum, *altNum)
+	if err != nil {
+		log.Fatalf("%s.Interface(%d, %d): %v", cfg, *intfNum, *altNum, err)
+	}
+	defer intf.Close()
+
+	var out *gousb.OutEndpoint
+	var in *gousb.InEndpoint
+	if *mode != "read" {
+		if out, err = intf.OutEndpoint(*outEp); err != nil {
+			log.Fatalf("%s.OutEndpoint(%d): %v", intf, *outEp, err)
+		}
+	}
+	if *mode != "write" {
+		if in, err = intf.InEndpoint(*inEp); err != nil {
+			log.Fatalf("%s.InEndpoint(%d): %v", intf, *inEp, err)
+		}
+	}
+
+	bctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	var r bench.Result
+	switch *mode {
+	case "loopback":
+		r, err = bench.Loopback(bctx, out, in, *size, *count)
+	case "read":
+		r, err = bench.Read(bctx, in, *size, *count)
+	case "write":
+		r, err = bench.Write(bctx, out, *size, *count)
+	default:
+		log.Fatalf("-mode %q: want loopback, read or write", *mode)
+	}
+	if err != nil {
+		log.Fatalf("%s benchmark: %v", *mode, err)
+	}
+	fmt.Println(r)
+}
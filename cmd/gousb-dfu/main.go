@@ -0,0 +1,488 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gousb-dfu downloads and uploads firmware to and from USB devices using
+// the Device Firmware Upgrade protocol, similarly to dfu-util(1).
+//
+// Usage:
+//
+//	gousb-dfu -l [-d vid:[pid]] [-p path]
+//	gousb-dfu [-d vid:[pid]] [-p path] [-c cfg] [-i intf] [-a alt] [-t size] [-s address[:leave][:length]] [-R] -D file | -U file | -e
+//
+// -D writes the firmware in the file to the device, -U reads the firmware
+// of the device into the file and -e detaches a device in runtime mode
+// into DFU mode. Devices in runtime mode are detached automatically before
+// -D and -U. The alternate setting given with -a is either a number or
+// the name of the setting.
+//
+// For DfuSe devices, -s gives the memory address to start at, otherwise
+// the start of the memory region of the alternate setting is used. With
+// ":leave", the device runs the firmware at the address after -D. The
+// length limits the amount of data read by -U.
+//
+// A DFU suffix at the end of a file written with -D is verified and
+// stripped.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/dfu"
+)
+
+var (
+	list      = flag.Bool("l", false, "List the DFU interfaces of the attached devices and exit.")
+	devFlag   = flag.String("d", "", "Use the device with the given vendor and product ID, as vid:[pid] in hex.")
+	pathFlag  = flag.String("p", "", "Use the device at the given port path, e.g. 1-2.4.")
+	cfgFlag   = flag.Int("c", 0, "Use the configuration with the given number, 0 for any.")
+	intfFlag  = flag.Int("i", -1, "Use the interface with the given number, -1 for any.")
+	altFlag   = flag.String("a", "", "Use the alternate setting with the given number or name.")
+	dnFlag    = flag.String("D", "", "Write the firmware in the file to the device.")
+	upFlag    = flag.String("U", "", "Read the firmware of the device into the file.")
+	detach    = flag.Bool("e", false, "Detach a device in runtime mode into DFU mode.")
+	dfuseFlag = flag.String("s", "", "DfuSe start address, as address[:leave][:length].")
+	xferSize  = flag.Int("t", 0, "Transfer size, 0 to use the size of the DFU functional descriptor.")
+	reset     = flag.Bool("R", false, "Reset the device when done.")
+	wait      = flag.Duration("wait", 10*time.Second, "How long to wait for a detached device to reappear in DFU mode.")
+	debug     = flag.Int("debug", 0, "libusb debug level (0..3).")
+)
+
+// filter selects the device to use. Negative IDs and an empty path match
+// any value.
+type filter struct {
+	vid, pid int
+	path     string
+}
+
+func (f filter) match(desc *gousb.DeviceDesc) bool {
+	return (f.vid < 0 || gousb.ID(f.vid) == desc.Vendor) &&
+		(f.pid < 0 || gousb.ID(f.pid) == desc.Product) &&
+		(f.path == "" || portPath(desc) == f.path)
+}
+
+func parseNum(s string, base, bits int) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	n, err := strconv.ParseUint(s, base, bits)
+	return int(n), err
+}
+
+func parseFilter(dev, path string) (filter, error) {
+	f := filter{vid: -1, pid: -1, path: path}
+	if dev == "" {
+		return f, nil
+	}
+	parts := strings.SplitN(dev, ":", 2)
+	if len(parts) != 2 {
+		return f, fmt.Errorf("-d %q: want vid:[pid], e.g. 0483:df11 or 0483:", dev)
+	}
+	var err error
+	if f.vid, err = parseNum(parts[0], 16, 16); err != nil {
+		return f, fmt.Errorf("-d %q: invalid vendor ID: %v", dev, err)
+	}
+	if f.pid, err = parseNum(parts[1], 16, 16); err != nil {
+		return f, fmt.Errorf("-d %q: invalid product ID: %v", dev, err)
+	}
+	return f, nil
+}
+
+// dfuseOpts are the options given with -s.
+type dfuseOpts struct {
+	addr    uint32
+	hasAddr bool
+	leave   bool
+	length  int
+}
+
+func parseDfuse(s string) (dfuseOpts, error) {
+	o := dfuseOpts{length: -1}
+	if s == "" {
+		return o, nil
+	}
+	parts := strings.Split(s, ":")
+	if parts[0] != "" {
+		a, err := strconv.ParseUint(parts[0], 0, 32)
+		if err != nil {
+			return o, fmt.Errorf("-s %q: invalid address: %v", s, err)
+		}
+		o.addr, o.hasAddr = uint32(a), true
+	}
+	for _, p := range parts[1:] {
+		if p == "leave" {
+			o.leave = true
+			continue
+		}
+		n, err := strconv.ParseUint(p, 0, 31)
+		if err != nil {
+			return o, fmt.Errorf("-s %q: want address[:leave][:length], got modifier %q", s, p)
+		}
+		o.length = int(n)
+	}
+	return o, nil
+}
+
+func portPath(desc *gousb.DeviceDesc) string {
+	if len(desc.Path) == 0 {
+		return strconv.Itoa(desc.Bus)
+	}
+	ports := make([]string, len(desc.Path))
+	for i, p := range desc.Path {
+		ports[i] = strconv.Itoa(p)
+	}
+	return fmt.Sprintf("%d-%s", desc.Bus, strings.Join(ports, "."))
+}
+
+// target is a DFU interface alternate setting of a device.
+type target struct {
+	cfg     int // configuration number
+	cfgIdx  int // index of the configuration descriptor
+	intf    int
+	alt     int
+	runtime bool
+	name    string
+}
+
+// targets returns the DFU interfaces of the device matching -c and -i. If
+// dev is not nil, the names of the alternate settings are read from it.
+func targets(desc *gousb.DeviceDesc, dev *gousb.Device) []target {
+	var cfgs []int
+	for n := range desc.Configs {
+		cfgs = append(cfgs, n)
+	}
+	sort.Ints(cfgs)
+	var ts []target
+	for idx, c := range cfgs {
+		if *cfgFlag != 0 && c != *cfgFlag {
+			continue
+		}
+		for _, intf := range desc.Configs[c].Interfaces {
+			if *intfFlag >= 0 && intf.Number != *intfFlag {
+				continue
+			}
+			for _, alt := range intf.AltSettings {
+				if !dfu.IsDFU(alt) {
+					continue
+				}
+				t := target{cfg: c, cfgIdx: idx, intf: intf.Number, alt: alt.Alternate, runtime: alt.Protocol == dfu.ProtocolRuntime}
+				if dev != nil {
+					t.name, _ = dev.InterfaceDescription(c, intf.Number, alt.Alternate)
+				}
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// selectTarget returns the target matching -a, or the first target.
+func selectTarget(ts []target) (target, error) {
+	for _, t := range ts {
+		if *altFlag == "" || *altFlag == t.name || *altFlag == strconv.Itoa(t.alt) {
+			return t, nil
+		}
+	}
+	if *altFlag != "" {
+		return target{}, fmt.Errorf("no DFU alternate setting %q", *altFlag)
+	}
+	return target{}, fmt.Errorf("no DFU interface")
+}
+
+func listTargets(ctx *gousb.Context, f filter) {
+	devs, err := ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return f.match(desc) && len(targets(desc, nil)) > 0
+	})
+	if err != nil && len(devs) == 0 {
+		log.Fatalf("list: %v", err)
+	}
+	for _, dev := range devs {
+		defer dev.Close()
+		serial, _ := dev.SerialNumber()
+		for _, t := range targets(dev.Desc, dev) {
+			mode := "DFU"
+			if t.runtime {
+				mode = "Runtime"
+			}
+			fmt.Printf("Found %s: [%s:%s] ver=%04x, devnum=%d, cfg=%d, intf=%d, path=%q, alt=%d, name=%q, serial=%q\n",
+				mode, dev.Desc.Vendor, dev.Desc.Product, uint16(dev.Desc.Device), dev.Desc.Address, t.cfg, t.intf, portPath(dev.Desc), t.alt, t.name, serial)
+		}
+	}
+}
+
+// openDevice opens the single DFU capable device matched by f.
+func openDevice(ctx *gousb.Context, f filter) (*gousb.Device, error) {
+	devs, err := ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return f.match(desc) && len(targets(desc, nil)) > 0
+	})
+	if len(devs) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no DFU capable device found")
+	}
+	if len(devs) > 1 {
+		for _, d := range devs {
+			d.Close()
+		}
+		return nil, fmt.Errorf("found %d DFU capable devices, select one with -d or -p", len(devs))
+	}
+	return devs[0], nil
+}
+
+// session is an opened DFU interface.
+type session struct {
+	dev  *gousb.Device
+	t    target
+	d    *dfu.Device
+	done func()
+}
+
+func open(dev *gousb.Device) (*session, error) {
+	t, err := selectTarget(targets(dev.Desc, dev))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", dev, err)
+	}
+	fd, err := dfu.ReadFunctionalDesc(dev, t.cfgIdx, t.intf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", dev, err)
+	}
+	if *xferSize > 0 {
+		fd.TransferSize = *xferSize
+	}
+	dev.SetAutoDetach(true)
+	cfg, err := dev.Config(t.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s.Config(%d): %v", dev, t.cfg, err)
+	}
+	intf, err := cfg.Interface(t.intf, t.alt)
+	if err != nil {
+		cfg.Close()
+		return nil, fmt.Errorf("%s.Interface(%d, %d): %v", cfg, t.intf, t.alt, err)
+	}
+	done := func() {
+		intf.Close()
+		cfg.Close()
+	}
+	return &session{dev: dev, t: t, d: dfu.New(dev, t.intf, fd), done: done}, nil
+}
+
+// detachDevice switches the device in runtime mode to DFU mode. If
+// reopen is set, it waits for the device to reappear in DFU mode and
+// returns it.
+func detachDevice(ctx *gousb.Context, s *session, reopen bool) (*gousb.Device, error) {
+	path := portPath(s.dev.Desc)
+	if err := s.d.Detach(); err != nil {
+		return nil, err
+	}
+	s.done()
+	if !s.d.Func.WillDetach {
+		s.dev.Reset()
+	}
+	s.dev.Close()
+	if !reopen {
+		return nil, nil
+	}
+	fmt.Printf("Waiting for the device at %s to reappear in DFU mode\n", path)
+	wctx, cancel := context.WithTimeout(context.Background(), *wait)
+	defer cancel()
+	return ctx.WaitForDevice(wctx, func(desc *gousb.DeviceDesc) bool {
+		if portPath(desc) != path {
+			return false
+		}
+		for _, t := range targets(desc, nil) {
+			if !t.runtime {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// stripSuffix removes a valid DFU suffix from the firmware and checks that
+// it was built for the device.
+func stripSuffix(fw []byte, desc *gousb.DeviceDesc) ([]byte, error) {
+	const suffixLen = 16
+	if len(fw) < suffixLen {
+		return fw, nil
+	}
+	s := fw[len(fw)-suffixLen:]
+	if string(s[8:11]) != "UFD" || s[11] < suffixLen || int(s[11]) > len(fw) {
+		return fw, nil
+	}
+	if ^crc32.ChecksumIEEE(fw[:len(fw)-4]) != binary.LittleEndian.Uint32(s[12:]) {
+		return nil, fmt.Errorf("the DFU suffix has an invalid CRC")
+	}
+	pid, vid := binary.LittleEndian.Uint16(s[2:]), binary.LittleEndian.Uint16(s[4:])
+	if (vid != 0xffff && gousb.ID(vid) != desc.Vendor) || (pid != 0xffff && gousb.ID(pid) != desc.Product) {
+		return nil, fmt.Errorf("the firmware is for device %04x:%04x, not %s:%s", vid, pid, desc.Vendor, desc.Product)
+	}
+	fw = fw[:len(fw)-int(s[11])]
+	if bytes.HasPrefix(fw, []byte("DfuSe")) {
+		return nil, fmt.Errorf("DfuSe files are not supported, use a raw binary with -s")
+	}
+	return fw, nil
+}
+
+func main() {
+	flag.Parse()
+	f, err := parseFilter(*devFlag, *pathFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts, err := parseDfuse(*dfuseFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	ctx.Debug(*debug)
+
+	if *list {
+		listTargets(ctx, f)
+		return
+	}
+	ops := 0
+	for _, set := range []bool{*dnFlag != "", *upFlag != "", *detach} {
+		if set {
+			ops++
+		}
+	}
+	if ops != 1 {
+		log.Fatal("exactly one of -l, -D, -U and -e is required")
+	}
+
+	dev, err := openDevice(ctx, f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := open(dev)
+	if err != nil {
+		dev.Close()
+		log.Fatal(err)
+	}
+	if s.t.runtime {
+		fmt.Printf("Detaching %s into DFU mode\n", dev)
+		if dev, err = detachDevice(ctx, s, !*detach); err != nil {
+			log.Fatalf("detach: %v", err)
+		}
+		if *detach {
+			return
+		}
+		if s, err = open(dev); err != nil {
+			dev.Close()
+			log.Fatal(err)
+		}
+	} else if *detach {
+		msg := fmt.Sprintf("%s is already in DFU mode", dev)
+		s.done()
+		dev.Close()
+		log.Fatal(msg)
+	}
+	err = run(s, opts)
+	s.done()
+	if *reset {
+		s.dev.Reset()
+	}
+	s.dev.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run performs the download or upload on the opened DFU interface.
+func run(s *session, opts dfuseOpts) error {
+	d := s.d
+	fmt.Printf("Using %s, interface %d, alt %d %q, transfer size %d\n", s.dev, s.t.intf, s.t.alt, s.t.name, d.Func.TransferSize)
+	if err := d.Idle(); err != nil {
+		return err
+	}
+	var layout *dfu.MemoryLayout
+	if d.Func.DfuSe() {
+		var err error
+		if layout, err = dfu.ParseMemoryLayout(s.t.name); err != nil {
+			log.Printf("Warning: %v", err)
+		} else if !opts.hasAddr {
+			opts.addr, opts.hasAddr = layout.Sectors[0].Addr, true
+		}
+		if !opts.hasAddr {
+			return fmt.Errorf("the device uses DfuSe, give the address with -s")
+		}
+	}
+
+	if *upFlag != "" {
+		out, err := os.Create(*upFlag)
+		if err != nil {
+			return err
+		}
+		var n int
+		if d.Func.DfuSe() {
+			length := opts.length
+			if length < 0 && layout != nil {
+				last := layout.Sectors[len(layout.Sectors)-1]
+				length = int(uint64(last.Addr) + uint64(last.Size) - uint64(opts.addr))
+			}
+			n, err = d.DfuseUpload(out, opts.addr, length)
+		} else {
+			n, err = d.Upload(out)
+		}
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("upload: %v", err)
+		}
+		fmt.Printf("Uploaded %d bytes to %s\n", n, *upFlag)
+		return nil
+	}
+
+	fw, err := ioutil.ReadFile(*dnFlag)
+	if err != nil {
+		return err
+	}
+	if fw, err = stripSuffix(fw, s.dev.Desc); err != nil {
+		return fmt.Errorf("%s: %v", *dnFlag, err)
+	}
+	if d.Func.DfuSe() {
+		if err := d.DfuseDownload(layout, opts.addr, fw); err != nil {
+			return fmt.Errorf("download: %v", err)
+		}
+		fmt.Printf("Downloaded %d bytes to 0x%08x\n", len(fw), opts.addr)
+		if opts.leave {
+			return d.Leave(opts.addr)
+		}
+		return nil
+	}
+	if err := d.Download(fw); err != nil {
+		return fmt.Errorf("download: %v", err)
+	}
+	fmt.Printf("Downloaded %d bytes\n", len(fw))
+	if !d.Func.ManifestationTolerant && !*reset {
+		fmt.Println("The device needs a reset to run the new firmware, e.g. with -R")
+	}
+	return nil
+}
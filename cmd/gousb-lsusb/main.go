@@ -0,0 +1,200 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gousb-lsusb lists attached USB devices, similarly to lsusb(8).
+//
+// Usage:
+//
+//	gousb-lsusb [-v] [-d vid:[pid]] [-s [bus:][addr]]
+//	gousb-lsusb -t
+//
+// With -v, the full descriptor tree of every device is printed, including
+// the string descriptors of the devices that the current user can open.
+// With -t, the bus topology is printed instead, showing which hub port
+// every device is attached to.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/usbid"
+)
+
+var (
+	verbose = flag.Bool("v", false, "Print the full descriptor tree of the devices.")
+	devFlag = flag.String("d", "", "Show only devices with the given vendor and product ID, as vid:[pid] in hex.")
+	busFlag = flag.String("s", "", "Show only devices on the given bus and/or address, as [bus:][addr] in decimal.")
+	topo    = flag.Bool("t", false, "Print the bus topology as a tree.")
+	debug   = flag.Int("debug", 0, "libusb debug level (0..3).")
+)
+
+// filter selects the devices to list. Negative fields match any value.
+type filter struct {
+	vid, pid  int
+	bus, addr int
+}
+
+func (f filter) match(desc *gousb.DeviceDesc) bool {
+	return (f.vid < 0 || gousb.ID(f.vid) == desc.Vendor) &&
+		(f.pid < 0 || gousb.ID(f.pid) == desc.Product) &&
+		(f.bus < 0 || f.bus == desc.Bus) &&
+		(f.addr < 0 || f.addr == desc.Address)
+}
+
+func parseNum(s string, base, bits int) (int, error) {
+	if s == "" {
+		return -1, nil
+	}
+	n, err := strconv.ParseUint(s, base, bits)
+	return int(n), err
+}
+
+func parseFilter(dev, bus string) (filter, error) {
+	f := filter{-1, -1, -1, -1}
+	var err error
+	if dev != "" {
+		parts := strings.SplitN(dev, ":", 2)
+		if len(parts) != 2 {
+			return f, fmt.Errorf("-d %q: want vid:[pid], e.g. 1d6b:0002 or 1d6b:", dev)
+		}
+		if f.vid, err = parseNum(parts[0], 16, 16); err != nil {
+			return f, fmt.Errorf("-d %q: invalid vendor ID: %v", dev, err)
+		}
+		if f.pid, err = parseNum(parts[1], 16, 16); err != nil {
+			return f, fmt.Errorf("-d %q: invalid product ID: %v", dev, err)
+		}
+	}
+	if bus != "" {
+		addr := bus
+		if i := strings.Index(bus, ":"); i >= 0 {
+			if f.bus, err = parseNum(bus[:i], 10, 8); err != nil {
+				return f, fmt.Errorf("-s %q: invalid bus number: %v", bus, err)
+			}
+			addr = bus[i+1:]
+		}
+		if f.addr, err = parseNum(addr, 10, 8); err != nil {
+			return f, fmt.Errorf("-s %q: invalid device address: %v", bus, err)
+		}
+	}
+	return f, nil
+}
+
+func main() {
+	flag.Parse()
+	f, err := parseFilter(*devFlag, *busFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	ctx.Debug(*debug)
+
+	if *topo {
+		roots, err := ctx.DeviceTree()
+		if err != nil && len(roots) == 0 {
+			log.Fatalf("list: %v", err)
+		}
+		if err != nil {
+			log.Printf("warning: list: %v", err)
+		}
+		printTopology(os.Stdout, roots)
+		return
+	}
+
+	// Collect the descriptors first, devices are only opened in verbose
+	// mode to read the string descriptors.
+	var descs []*gousb.DeviceDesc
+	_, err = ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		if f.match(desc) {
+			descs = append(descs, desc)
+		}
+		return false
+	})
+	if err != nil {
+		// The devices that could be read are still listed.
+		log.Printf("warning: list: %v", err)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+		if descs[i].Bus != descs[j].Bus {
+			return descs[i].Bus < descs[j].Bus
+		}
+		return descs[i].Address < descs[j].Address
+	})
+
+	for _, desc := range descs {
+		fmt.Printf("Bus %03d Device %03d: ID %s:%s %s\n", desc.Bus, desc.Address, desc.Vendor, desc.Product, usbid.Describe(desc))
+		if *verbose {
+			if err := printTree(os.Stdout, ctx, desc); err != nil {
+				log.Printf("warning: dump %s: %v", desc, err)
+			}
+		}
+	}
+	if len(descs) == 0 && (*devFlag != "" || *busFlag != "") {
+		os.Exit(1)
+	}
+}
+
+// printTopology prints the bus trees, one device per line, indented by
+// their depth below the root hub.
+func printTopology(w io.Writer, roots []*gousb.DeviceNode) {
+	for _, r := range roots {
+		r.Walk(func(n *gousb.DeviceNode, depth int) {
+			var line string
+			if depth == 0 {
+				line = fmt.Sprintf("/:  Bus %03d", n.Bus)
+			} else {
+				line = fmt.Sprintf("%s|__ Port %d", strings.Repeat("    ", depth), n.Port())
+			}
+			if n.Desc != nil {
+				line += fmt.Sprintf(": Dev %03d, ID %s:%s %s, %s", n.Desc.Address, n.Desc.Vendor, n.Desc.Product, usbid.Describe(n.Desc), n.Desc.Speed)
+			}
+			fmt.Fprintln(w, line)
+		})
+	}
+}
+
+// openDesc opens the device with the given descriptor, or returns nil if
+// it can't be opened, e.g. due to insufficient permissions.
+func openDesc(ctx *gousb.Context, desc *gousb.DeviceDesc) *gousb.Device {
+	devs, _ := ctx.OpenDevices(func(d *gousb.DeviceDesc) bool {
+		return d.Bus == desc.Bus && d.Address == desc.Address
+	})
+	if len(devs) == 0 {
+		return nil
+	}
+	for _, d := range devs[1:] {
+		d.Close()
+	}
+	return devs[0]
+}
+
+// printTree prints the descriptor tree of the device. String descriptors
+// are included if the device can be opened.
+func printTree(w io.Writer, ctx *gousb.Context, desc *gousb.DeviceDesc) error {
+	fmt.Fprintf(w, "  Class: %s\n", usbid.Classify(desc))
+	if dev := openDesc(ctx, desc); dev != nil {
+		defer dev.Close()
+		return dev.Dump(w)
+	}
+	return desc.Dump(w)
+}
@@ -16,8 +16,10 @@
 package gousb
 
 import (
+	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // ConfigDesc contains the information about a USB device configuration,
@@ -31,15 +33,23 @@ type ConfigDesc struct {
 	// RemoteWakeup is true if the device supports remote wakeup, i.e.
 	// an external signal that will wake up a suspended USB device. An example
 	// might be a keyboard that can wake up through a keypress after
-	// the host put it in suspend mode. Note that gousb does not support
-	// device power management, RemoteWakeup only refers to the reported device
-	// capability.
+	// the host put it in suspend mode. RemoteWakeup only refers to
+	// the reported device capability, see Device.Suspend for suspending
+	// the device.
 	RemoteWakeup bool
 	// MaxPower is the maximum current the device draws from the USB bus
 	// in this configuration.
 	MaxPower Milliamperes
+	// MaxPowerRaw is the raw bMaxPower field of the configuration descriptor.
+	// It is expressed in units of 2mA for devices operating at high speed
+	// or below and in units of 8mA for SuperSpeed devices.
+	MaxPowerRaw int
 	// Interfaces has a list of USB interfaces available in this configuration.
 	Interfaces []InterfaceDesc
+	// Associations has the interface association descriptors of the
+	// configuration, which group interfaces into functions. It's only
+	// filled by ParseConfigDescriptor, see Config.Functions.
+	Associations []InterfaceAssociation
 
 	iConfiguration int // index of a string descriptor describing this configuration
 }
@@ -49,6 +59,32 @@ func (c ConfigDesc) String() string {
 	return fmt.Sprintf("Configuration %d", c.Number)
 }
 
+// configDesc has the fields of ConfigDesc, without its methods.
+type configDesc ConfigDesc
+
+// MarshalJSON implements json.Marshaler. Along with the exported fields,
+// the encoded descriptor includes the index of the configuration string
+// descriptor.
+func (c ConfigDesc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		configDesc
+		IConfiguration int `json:"iConfiguration"`
+	}{configDesc(c), c.iConfiguration})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *ConfigDesc) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*configDesc
+		IConfiguration int `json:"iConfiguration"`
+	}{configDesc: (*configDesc)(c)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	c.iConfiguration = v.IConfiguration
+	return nil
+}
+
 func (c ConfigDesc) intfDesc(num, alt int) (*InterfaceSetting, error) {
 	// In an ideal world, interfaces in the descriptor would be numbered
 	// contiguously starting from 0, as required by the specification. In the
@@ -83,7 +119,16 @@ type Config struct {
 
 	// Claimed interfaces
 	mu      sync.Mutex
-	claimed map[int]bool
+	claimed map[int]*Interface
+	// claiming has the interfaces being claimed by Interface, reserved
+	// while it waits between the retries of a busy claim.
+	claiming map[int]bool
+	// claims tracks the calls to Interface in progress, cancelClaims
+	// waits for them.
+	claims sync.WaitGroup
+	// cancel is closed by cancelClaims, to abort the claims waiting
+	// between retries and refuse new ones.
+	cancel chan struct{}
 }
 
 // Close releases the underlying device, allowing the caller to switch the device to a different configuration.
@@ -93,16 +138,20 @@ func (c *Config) Close() error {
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if len(c.claimed) > 0 {
+	if len(c.claimed) > 0 || len(c.claiming) > 0 {
 		var ifs []int
 		for k := range c.claimed {
 			ifs = append(ifs, k)
 		}
+		for k := range c.claiming {
+			ifs = append(ifs, k)
+		}
 		return fmt.Errorf("failed to release %s, interfaces %v are still open", c, ifs)
 	}
 	c.dev.mu.Lock()
 	defer c.dev.mu.Unlock()
 	c.dev.claimed = nil
+	c.dev.ctx.log(LogOpConfigClose, nil, LogField{"device", c.dev.String()}, LogField{"config", c.Desc.Number})
 	c.dev = nil
 	return nil
 }
@@ -112,10 +161,41 @@ func (c *Config) String() string {
 	return fmt.Sprintf("%s,config=%d", c.dev.String(), c.Desc.Number)
 }
 
+// maxBusyBackoff is the longest wait between the retries of RetryBusy.
+const maxBusyBackoff = time.Second
+
+// InterfaceOption modifies how Config.Interface claims an interface.
+type InterfaceOption func(*interfaceOptions)
+
+type interfaceOptions struct {
+	detach  bool
+	retries int
+	backoff time.Duration
+}
+
+// DetachKernelDriver makes Config.Interface detach the kernel driver bound
+// to the interface before claiming it, and reattach it when the Interface
+// is closed. Unlike Device.SetAutoDetach, other interfaces of the device
+// keep their drivers. Detaching fails with an *UnsupportedError on
+// platforms without CapabilityDetachKernelDriver.
+func DetachKernelDriver() InterfaceOption {
+	return func(o *interfaceOptions) { o.detach = true }
+}
+
+// RetryBusy makes Config.Interface retry a claim that fails with
+// ErrorBusy up to retries times, e.g. when udev or a kernel driver still
+// holds the interface right after the device was plugged in. The first
+// retry waits for backoff, every following one twice as long as the
+// previous one, up to maxBusyBackoff. Other calls on the Config are not
+// blocked while Interface waits.
+func RetryBusy(retries int, backoff time.Duration) InterfaceOption {
+	return func(o *interfaceOptions) { o.retries, o.backoff = retries, backoff }
+}
+
 // Interface claims and returns an interface on a USB device.
 // num specifies the number of an interface to claim, and alt specifies the
 // alternate setting number for that interface.
-func (c *Config) Interface(num, alt int) (*Interface, error) {
+func (c *Config) Interface(num, alt int, opts ...InterfaceOption) (*Interface, error) {
 	if c.dev == nil {
 		return nil, fmt.Errorf("Interface(%d, %d) called on %s after Close", num, alt, c)
 	}
@@ -125,14 +205,51 @@ func (c *Config) Interface(num, alt int) (*Interface, error) {
 		return nil, fmt.Errorf("descriptor of interface (%d, %d) in %s: %v", num, alt, c, err)
 	}
 
+	if c.dev.claimedElsewhere(num) {
+		return nil, fmt.Errorf("interface %d on %s is claimed through another handle of the device", num, c)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.claimed[num] {
+	if c.cancelled() {
+		return nil, fmt.Errorf("Interface(%d, %d) called on %s after Close", num, alt, c)
+	}
+	if c.claimed[num] != nil || c.claiming[num] {
 		return nil, fmt.Errorf("interface %d on %s is already claimed", num, c)
 	}
+	c.claims.Add(1)
+	defer c.claims.Done()
+
+	var o interfaceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.detach && !c.dev.ctx.HasCapability(CapabilityDetachKernelDriver) {
+		return nil, newUnsupportedError("detaching kernel drivers", CapabilityDetachKernelDriver)
+	}
+	// reattach gives the interface back to its kernel driver, if it was
+	// detached by claim.
+	reattach := func() {
+		if o.detach {
+			c.dev.ctx.libusb.attachKernelDriver(c.dev.handle, uint8(num))
+		}
+	}
 
 	// Claim the interface
-	if err := c.dev.ctx.libusb.claim(c.dev.handle, uint8(num)); err != nil {
+	if c.claiming == nil {
+		c.claiming = make(map[int]bool)
+	}
+	c.claiming[num] = true
+	err = c.claim(num, o)
+	delete(c.claiming, num)
+	if err != nil {
+		c.dev.ctx.log(LogOpClaim, err, LogField{"device", c.dev.String()}, LogField{"config", c.Desc.Number}, LogField{"interface", num}, LogField{"alt", alt})
+		reattach()
+		if err == ErrorBusy {
+			if drv := c.dev.Desc.kernelDriver(c.Desc.Number, num); drv != "" {
+				return nil, fmt.Errorf("failed to claim interface %d on %s: %v, the interface is bound to kernel driver %s, detach it with the DetachKernelDriver option or Device.SetAutoDetach(true)", num, c, err, drv)
+			}
+		}
 		return nil, fmt.Errorf("failed to claim interface %d on %s: %v", num, c, err)
 	}
 
@@ -140,13 +257,92 @@ func (c *Config) Interface(num, alt int) (*Interface, error) {
 	if len(c.Desc.Interfaces[num].AltSettings) > 1 {
 		if err := c.dev.ctx.libusb.setAlt(c.dev.handle, uint8(num), uint8(alt)); err != nil {
 			c.dev.ctx.libusb.release(c.dev.handle, uint8(num))
+			reattach()
 			return nil, fmt.Errorf("failed to set alternate config %d on interface %d of %s: %v", alt, num, c, err)
 		}
 	}
 
-	c.claimed[num] = true
-	return &Interface{
-		Setting: *altInfo,
-		config:  c,
-	}, nil
+	intf := &Interface{
+		Setting:  *altInfo,
+		config:   c,
+		reattach: o.detach,
+	}
+	c.claimed[num] = intf
+	c.dev.ctx.log(LogOpClaim, nil, LogField{"device", c.dev.String()}, LogField{"config", c.Desc.Number}, LogField{"interface", num}, LogField{"alt", alt})
+	return intf, nil
+}
+
+// claim claims interface num, detaching its kernel driver and retrying
+// as requested by o. c.mu must be held, it's released while waiting
+// between the retries. num must be reserved in c.claiming meanwhile, so
+// that it's not claimed twice and the Config is not closed.
+func (c *Config) claim(num int, o interfaceOptions) error {
+	backoff := o.backoff
+	for i := 0; ; i++ {
+		if o.detach {
+			// A kernel driver may bind to the interface again
+			// between the retries, detach it every time.
+			if err := c.dev.ctx.libusb.detachKernelDriver(c.dev.handle, uint8(num)); err != nil {
+				return fmt.Errorf("failed to detach kernel driver: %v", err)
+			}
+		}
+		err := c.dev.ctx.libusb.claim(c.dev.handle, uint8(num))
+		if err != ErrorBusy || i >= o.retries {
+			return err
+		}
+		c.mu.Unlock()
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-c.cancel:
+			t.Stop()
+		}
+		c.mu.Lock()
+		if c.cancelled() {
+			return fmt.Errorf("claim of interface %d on %s cancelled by Context.Close", num, c)
+		}
+		if c.claimed[num] != nil {
+			return fmt.Errorf("interface %d on %s is already claimed", num, c)
+		}
+		if backoff *= 2; backoff > maxBusyBackoff {
+			backoff = maxBusyBackoff
+		}
+	}
+}
+
+// cancelled reports whether cancelClaims was called. c.mu must be held.
+func (c *Config) cancelled() bool {
+	select {
+	case <-c.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancelClaims aborts the calls to Interface waiting between the retries
+// of a busy claim, makes further calls fail and waits for the calls in
+// progress to return, so that the device handle is no longer used by
+// them.
+func (c *Config) cancelClaims() {
+	c.mu.Lock()
+	if !c.cancelled() {
+		close(c.cancel)
+	}
+	c.mu.Unlock()
+	c.claims.Wait()
+}
+
+// release closes all interfaces claimed in the config and the config itself.
+func (c *Config) release() error {
+	c.mu.Lock()
+	intfs := make([]*Interface, 0, len(c.claimed))
+	for _, intf := range c.claimed {
+		intfs = append(intfs, intf)
+	}
+	c.mu.Unlock()
+	for _, intf := range intfs {
+		intf.Close()
+	}
+	return c.Close()
 }
@@ -0,0 +1,212 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Conn is a net.Conn transferring data over a pair of IN and OUT bulk
+// or interrupt endpoints, so that protocol stacks written for network
+// connections can be used with USB devices. Each Read and Write is a single
+// transfer, see InEndpoint.Read and OutEndpoint.Write.
+type Conn struct {
+	in      *InEndpoint
+	out     *OutEndpoint
+	onClose func()
+
+	mu     sync.Mutex
+	closed bool
+	// readDL and writeDL are the deadlines set on the Conn, used to tell
+	// a read or write cancelled by an expired deadline from one cancelled
+	// by Close.
+	readDL, writeDL time.Time
+	// readTimer and writeTimer cancel the read or write in flight when
+	// its deadline, possibly set after it started, expires.
+	readTimer, writeTimer *time.Timer
+}
+
+var _ net.Conn = (*Conn)(nil)
+
+// NewConn returns a Conn reading from in and writing to out. onClose, if
+// not nil, is called by Close, e.g. to release the interface of
+// the endpoints.
+func NewConn(in *InEndpoint, out *OutEndpoint, onClose func()) *Conn {
+	return &Conn{in: in, out: out, onClose: onClose}
+}
+
+// errConnClosed is returned by the operations on a closed Conn.
+var errConnClosed = errors.New("use of closed USB connection")
+
+// connTimeoutError is returned by the operations of a Conn that exceeded
+// their deadline. Like the errors of network connections, it implements
+// net.Error.
+type connTimeoutError struct{}
+
+func (connTimeoutError) Error() string   { return "i/o timeout" }
+func (connTimeoutError) Timeout() bool   { return true }
+func (connTimeoutError) Temporary() bool { return true }
+
+// connAddr is the net.Addr of an endpoint of a Conn.
+type connAddr string
+
+func (a connAddr) Network() string { return "usb" }
+func (a connAddr) String() string  { return string(a) }
+
+func endpointAddr(e *endpoint) net.Addr {
+	if e.dev == nil {
+		return connAddr("ep=" + e.Desc.Address.String())
+	}
+	return connAddr(e.dev.String() + ",ep=" + e.Desc.Address.String())
+}
+
+// opError wraps err, returned by the operation op on endpoint e, in
+// a *net.OpError, translating expired deadlines and Close.
+func (c *Conn) opError(op string, e *endpoint, dl time.Time, err error) error {
+	if err == nil {
+		return nil
+	}
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	switch {
+	case closed:
+		err = errConnClosed
+	case err == TransferTimedOut, err == TransferCancelled && !dl.IsZero() && !time.Now().Before(dl):
+		err = connTimeoutError{}
+	}
+	return &net.OpError{Op: op, Net: "usb", Source: c.LocalAddr(), Addr: endpointAddr(e), Err: err}
+}
+
+func (c *Conn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// Read reads data from the IN endpoint.
+func (c *Conn) Read(b []byte) (int, error) {
+	if c.isClosed() {
+		return 0, c.opError("read", c.in.endpoint, time.Time{}, errConnClosed)
+	}
+	n, err := c.in.Read(b)
+	c.mu.Lock()
+	dl := c.readDL
+	c.mu.Unlock()
+	return n, c.opError("read", c.in.endpoint, dl, err)
+}
+
+// Write writes data to the OUT endpoint.
+func (c *Conn) Write(b []byte) (int, error) {
+	if c.isClosed() {
+		return 0, c.opError("write", c.out.endpoint, time.Time{}, errConnClosed)
+	}
+	n, err := c.out.Write(b)
+	c.mu.Lock()
+	dl := c.writeDL
+	c.mu.Unlock()
+	return n, c.opError("write", c.out.endpoint, dl, err)
+}
+
+// Close cancels the reads and writes in flight, which return an error, and
+// calls the onClose function passed to NewConn.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return &net.OpError{Op: "close", Net: "usb", Source: c.LocalAddr(), Addr: c.RemoteAddr(), Err: errConnClosed}
+	}
+	c.closed = true
+	stopTimer(&c.readTimer)
+	stopTimer(&c.writeTimer)
+	c.mu.Unlock()
+	c.in.CancelAll()
+	c.out.CancelAll()
+	if c.onClose != nil {
+		c.onClose()
+	}
+	return nil
+}
+
+// LocalAddr returns the address of the IN endpoint, which receives
+// the data on the host.
+func (c *Conn) LocalAddr() net.Addr {
+	return endpointAddr(c.in.endpoint)
+}
+
+// RemoteAddr returns the address of the OUT endpoint, which receives
+// the data on the device.
+func (c *Conn) RemoteAddr() net.Addr {
+	return endpointAddr(c.out.endpoint)
+}
+
+// SetDeadline sets both the read and the write deadline.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	c.SetWriteDeadline(t)
+	return nil
+}
+
+// SetReadDeadline sets the deadline of reads, see
+// InEndpoint.SetReadDeadline. The read in flight, if any, is also bound by
+// the new deadline: it's cancelled when the deadline expires, possibly
+// right away, and fails with a timeout error.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.in.SetReadDeadline(t)
+	c.setDeadline(&c.readDL, &c.readTimer, t, c.in.CancelAll)
+	return nil
+}
+
+// SetWriteDeadline sets the deadline of writes, see
+// OutEndpoint.SetWriteDeadline. The write in flight, if any, is also bound
+// by the new deadline: it's cancelled when the deadline expires, possibly
+// right away, and fails with a timeout error.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.out.SetWriteDeadline(t)
+	c.setDeadline(&c.writeDL, &c.writeTimer, t, c.out.CancelAll)
+	return nil
+}
+
+// setDeadline stores the deadline t in dl and replaces the timer that
+// calls cancel when the deadline expires.
+func (c *Conn) setDeadline(dl *time.Time, timer **time.Timer, t time.Time, cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*dl = t
+	stopTimer(timer)
+	if t.IsZero() || c.closed {
+		return
+	}
+	*timer = time.AfterFunc(time.Until(t), func() {
+		c.mu.Lock()
+		// The deadline might have been changed after the timer fired.
+		expired := dl.Equal(t)
+		c.mu.Unlock()
+		if expired {
+			cancel()
+		}
+	})
+}
+
+// stopTimer stops the timer, if any, and clears it.
+func stopTimer(timer **time.Timer) {
+	if *timer != nil {
+		(*timer).Stop()
+		*timer = nil
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConn(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	closed := 0
+	c := NewConn(in, out, func() {
+		closed++
+		done()
+	})
+
+	if got, want := c.RemoteAddr().String(), dev.String()+",ep=0x01"; got != want {
+		t.Errorf("RemoteAddr(): got %q, want %q", got, want)
+	}
+	if got, want := c.LocalAddr().Network(), "usb"; got != want {
+		t.Errorf("LocalAddr().Network(): got %q, want %q", got, want)
+	}
+
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setLength(3)
+		ft.setStatus(TransferCompleted)
+		ft = lib.waitForSubmitted(nil)
+		ft.setData([]byte{4, 5})
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := c.Write([]byte{1, 2, 3}); n != 3 || err != nil {
+		t.Errorf("Write(): got %d, %v, want 3, nil", n, err)
+	}
+	buf := make([]byte, 512)
+	if n, err := c.Read(buf); n != 2 || err != nil {
+		t.Errorf("Read(): got %d, %v, want 2, nil", n, err)
+	}
+
+	// An expired deadline fails with a timeout.
+	go lib.waitForSubmitted(nil)
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = c.Read(buf)
+	if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("Read() after the deadline: got error %v, want a net.Error with Timeout() true", err)
+	}
+
+	// Setting a deadline in the past interrupts a pending read.
+	c.SetReadDeadline(time.Time{})
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Read(buf)
+		errc <- err
+	}()
+	lib.waitForSubmitted(nil)
+	c.SetReadDeadline(time.Now().Add(-time.Second))
+	if ne, ok := (<-errc).(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("Read() interrupted by SetReadDeadline: got error %v, want a net.Error with Timeout() true", ne)
+	}
+
+	// A deadline set during a pending read interrupts it when it expires.
+	c.SetReadDeadline(time.Time{})
+	go func() {
+		_, err := c.Read(buf)
+		errc <- err
+	}()
+	lib.waitForSubmitted(nil)
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if ne, ok := (<-errc).(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("Read() with a deadline set while pending: got error %v, want a net.Error with Timeout() true", ne)
+	}
+
+	// A deadline replaced before it expires doesn't interrupt the read.
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	c.SetReadDeadline(time.Time{})
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		time.Sleep(50 * time.Millisecond)
+		ft.setData([]byte{6})
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := c.Read(buf); n != 1 || err != nil {
+		t.Errorf("Read() after the deadline was cleared: got %d, %v, want 1, nil", n, err)
+	}
+
+	// Close interrupts a pending read.
+	c.SetReadDeadline(time.Time{})
+	go func() {
+		_, err := c.Read(buf)
+		errc <- err
+	}()
+	lib.waitForSubmitted(nil)
+	if err := c.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if err := <-errc; err == nil {
+		t.Error("Read() interrupted by Close: got nil error, want non-nil")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Errorf("Read() interrupted by Close: got a timeout error %v, want a closed connection error", err)
+	}
+	if _, err := c.Write([]byte{1}); err == nil {
+		t.Error("Write() after Close: got nil error, want non-nil")
+	}
+	if err := c.Close(); err == nil {
+		t.Error("second Close(): got nil error, want non-nil")
+	}
+	if closed != 1 {
+		t.Errorf("onClose called %d times, want 1", closed)
+	}
+}
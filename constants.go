@@ -15,8 +15,6 @@
 
 package gousb
 
-// #include <libusb.h>
-import "C"
 import "strconv"
 
 // Class represents a USB-IF (Implementers Forum) class or subclass code.
@@ -42,6 +40,8 @@ const (
 	ClassAudioVideo         Class = 0x10
 	ClassBillboard          Class = 0x11
 	ClassUSBTypeCBridge     Class = 0x12
+	ClassBulkDisplay        Class = 0x13
+	ClassI3C                Class = 0x3c
 	ClassDiagnosticDevice   Class = 0xdc
 	ClassWireless           Class = 0xe0
 	ClassMiscellaneous      Class = 0xef
@@ -67,6 +67,8 @@ var classDescription = map[Class]string{
 	ClassAudioVideo:         "audio/video",
 	ClassBillboard:          "billboard",
 	ClassUSBTypeCBridge:     "USB type-C bridge",
+	ClassBulkDisplay:        "bulk display",
+	ClassI3C:                "I3C",
 	ClassDiagnosticDevice:   "diagnostic device",
 	ClassWireless:           "wireless",
 	ClassMiscellaneous:      "miscellaneous",
@@ -81,6 +83,62 @@ func (c Class) String() string {
 	return strconv.Itoa(int(c))
 }
 
+// Common subclass codes, qualified by the class they belong to, see
+// https://www.usb.org/defined-class-codes and the class specifications.
+const (
+	SubClassAudioControl   Class = 0x01 // ClassAudio
+	SubClassAudioStreaming Class = 0x02 // ClassAudio
+	SubClassMIDIStreaming  Class = 0x03 // ClassAudio
+
+	SubClassCDCDirectLine Class = 0x01 // ClassComm
+	SubClassCDCACM        Class = 0x02 // ClassComm, abstract control model
+	SubClassCDCECM        Class = 0x06 // ClassComm, Ethernet networking
+	SubClassCDCNCM        Class = 0x0d // ClassComm, network control model
+	SubClassCDCMBIM       Class = 0x0e // ClassComm, mobile broadband
+
+	SubClassHIDBoot Class = 0x01 // ClassHID, boot interface
+
+	SubClassSCSI Class = 0x06 // ClassMassStorage, SCSI transparent command set
+
+	SubClassVideoControl   Class = 0x01 // ClassVideo
+	SubClassVideoStreaming Class = 0x02 // ClassVideo
+
+	SubClassRadioFrequency Class = 0x01 // ClassWireless
+
+	SubClassMiscCommon Class = 0x02 // ClassMiscellaneous, used with ProtocolIAD
+
+	SubClassDFU             Class = 0x01 // ClassApplication, device firmware upgrade
+	SubClassIrDABridge      Class = 0x02 // ClassApplication
+	SubClassTestMeasurement Class = 0x03 // ClassApplication, USBTMC
+)
+
+// Common protocol codes, qualified by the class and subclass they belong
+// to.
+const (
+	ProtocolCDCAT Protocol = 0x01 // SubClassCDCACM, AT commands (V.250)
+
+	ProtocolHIDKeyboard Protocol = 0x01 // SubClassHIDBoot
+	ProtocolHIDMouse    Protocol = 0x02 // SubClassHIDBoot
+
+	ProtocolBulkOnly Protocol = 0x50 // ClassMassStorage, bulk-only transport
+	ProtocolUAS      Protocol = 0x62 // ClassMassStorage, USB attached SCSI
+
+	ProtocolHubFullSpeed  Protocol = 0x00 // ClassHub
+	ProtocolHubSingleTT   Protocol = 0x01 // ClassHub, high speed with a single TT
+	ProtocolHubMultiTT    Protocol = 0x02 // ClassHub, high speed with multiple TTs
+	ProtocolHubSuperSpeed Protocol = 0x03 // ClassHub
+
+	ProtocolBluetooth Protocol = 0x01 // SubClassRadioFrequency
+
+	ProtocolIAD Protocol = 0x01 // SubClassMiscCommon, interface association descriptor
+
+	ProtocolDFURuntime Protocol = 0x01 // SubClassDFU, runtime mode
+	ProtocolDFUMode    Protocol = 0x02 // SubClassDFU, DFU mode
+
+	ProtocolUSBTMC Protocol = 0x00 // SubClassTestMeasurement
+	ProtocolUSB488 Protocol = 0x01 // SubClassTestMeasurement, USB488 subclass
+)
+
 // Protocol is the interface class protocol, qualified by the values
 // of interface class and subclass.
 type Protocol uint8
@@ -94,31 +152,56 @@ type DescriptorType uint8
 
 // Descriptor types defined by the USB spec.
 const (
-	DescriptorTypeDevice    DescriptorType = C.LIBUSB_DT_DEVICE
-	DescriptorTypeConfig    DescriptorType = C.LIBUSB_DT_CONFIG
-	DescriptorTypeString    DescriptorType = C.LIBUSB_DT_STRING
-	DescriptorTypeInterface DescriptorType = C.LIBUSB_DT_INTERFACE
-	DescriptorTypeEndpoint  DescriptorType = C.LIBUSB_DT_ENDPOINT
-	DescriptorTypeHID       DescriptorType = C.LIBUSB_DT_HID
-	DescriptorTypeReport    DescriptorType = C.LIBUSB_DT_REPORT
-	DescriptorTypePhysical  DescriptorType = C.LIBUSB_DT_PHYSICAL
-	DescriptorTypeHub       DescriptorType = C.LIBUSB_DT_HUB
+	DescriptorTypeDevice                  DescriptorType = 0x01
+	DescriptorTypeConfig                  DescriptorType = 0x02
+	DescriptorTypeString                  DescriptorType = 0x03
+	DescriptorTypeInterface               DescriptorType = 0x04
+	DescriptorTypeEndpoint                DescriptorType = 0x05
+	DescriptorTypeDeviceQualifier         DescriptorType = 0x06
+	DescriptorTypeOtherSpeedConfig        DescriptorType = 0x07
+	DescriptorTypeInterfacePower          DescriptorType = 0x08
+	DescriptorTypeOTG                     DescriptorType = 0x09
+	DescriptorTypeDebug                   DescriptorType = 0x0a
+	DescriptorTypeInterfaceAssociation    DescriptorType = 0x0b
+	DescriptorTypeBOS                     DescriptorType = 0x0f
+	DescriptorTypeDeviceCapability        DescriptorType = 0x10
+	DescriptorTypeHID                     DescriptorType = 0x21
+	DescriptorTypeReport                  DescriptorType = 0x22
+	DescriptorTypePhysical                DescriptorType = 0x23
+	DescriptorTypeHub                     DescriptorType = 0x29
+	DescriptorTypeSuperSpeedHub           DescriptorType = 0x2a
+	DescriptorTypeSSEndpointCompanion     DescriptorType = 0x30
+	DescriptorTypeSSPIsoEndpointCompanion DescriptorType = 0x31
 )
 
 var descriptorTypeDescription = map[DescriptorType]string{
-	DescriptorTypeDevice:    "device",
-	DescriptorTypeConfig:    "configuration",
-	DescriptorTypeString:    "string",
-	DescriptorTypeInterface: "interface",
-	DescriptorTypeEndpoint:  "endpoint",
-	DescriptorTypeHID:       "HID",
-	DescriptorTypeReport:    "HID report",
-	DescriptorTypePhysical:  "physical",
-	DescriptorTypeHub:       "hub",
+	DescriptorTypeDevice:                  "device",
+	DescriptorTypeConfig:                  "configuration",
+	DescriptorTypeString:                  "string",
+	DescriptorTypeInterface:               "interface",
+	DescriptorTypeEndpoint:                "endpoint",
+	DescriptorTypeDeviceQualifier:         "device qualifier",
+	DescriptorTypeOtherSpeedConfig:        "other speed configuration",
+	DescriptorTypeInterfacePower:          "interface power",
+	DescriptorTypeOTG:                     "OTG",
+	DescriptorTypeDebug:                   "debug",
+	DescriptorTypeInterfaceAssociation:    "interface association",
+	DescriptorTypeBOS:                     "BOS",
+	DescriptorTypeDeviceCapability:        "device capability",
+	DescriptorTypeHID:                     "HID",
+	DescriptorTypeReport:                  "HID report",
+	DescriptorTypePhysical:                "physical",
+	DescriptorTypeHub:                     "hub",
+	DescriptorTypeSuperSpeedHub:           "SuperSpeed hub",
+	DescriptorTypeSSEndpointCompanion:     "SuperSpeed endpoint companion",
+	DescriptorTypeSSPIsoEndpointCompanion: "SuperSpeedPlus isochronous endpoint companion",
 }
 
 func (dt DescriptorType) String() string {
-	return descriptorTypeDescription[dt]
+	if s, ok := descriptorTypeDescription[dt]; ok {
+		return s
+	}
+	return "descriptor type " + strconv.Itoa(int(dt))
 }
 
 // EndpointDirection defines the direction of data flow - IN (device to host)
@@ -148,10 +231,10 @@ type TransferType uint8
 
 // Transfer types defined by the USB spec.
 const (
-	TransferTypeControl     TransferType = C.LIBUSB_TRANSFER_TYPE_CONTROL
-	TransferTypeIsochronous TransferType = C.LIBUSB_TRANSFER_TYPE_ISOCHRONOUS
-	TransferTypeBulk        TransferType = C.LIBUSB_TRANSFER_TYPE_BULK
-	TransferTypeInterrupt   TransferType = C.LIBUSB_TRANSFER_TYPE_INTERRUPT
+	TransferTypeControl     TransferType = 0
+	TransferTypeIsochronous TransferType = 1
+	TransferTypeBulk        TransferType = 2
+	TransferTypeInterrupt   TransferType = 3
 	transferTypeMask                     = 0x03
 )
 
@@ -167,15 +250,34 @@ func (tt TransferType) String() string {
 	return transferTypeDescription[tt]
 }
 
+// transferFlags is a set of libusb transfer flags applied to the transfers
+// submitted on an endpoint.
+type transferFlags uint8
+
+// Transfer flags supported by gousb. LIBUSB_TRANSFER_FREE_BUFFER and
+// LIBUSB_TRANSFER_FREE_TRANSFER are omitted, transfer memory is always
+// managed by gousb.
+const (
+	transferShortNotOK    transferFlags = 1 << 0
+	transferAddZeroPacket transferFlags = 1 << 3
+)
+
+func (f transferFlags) set(flag transferFlags, v bool) transferFlags {
+	if v {
+		return f | flag
+	}
+	return f &^ flag
+}
+
 // IsoSyncType defines the isochronous transfer synchronization type.
 type IsoSyncType uint8
 
 // Synchronization types defined by the USB spec.
 const (
-	IsoSyncTypeNone     IsoSyncType = C.LIBUSB_ISO_SYNC_TYPE_NONE << 2
-	IsoSyncTypeAsync    IsoSyncType = C.LIBUSB_ISO_SYNC_TYPE_ASYNC << 2
-	IsoSyncTypeAdaptive IsoSyncType = C.LIBUSB_ISO_SYNC_TYPE_ADAPTIVE << 2
-	IsoSyncTypeSync     IsoSyncType = C.LIBUSB_ISO_SYNC_TYPE_SYNC << 2
+	IsoSyncTypeNone     IsoSyncType = 0 << 2
+	IsoSyncTypeAsync    IsoSyncType = 1 << 2
+	IsoSyncTypeAdaptive IsoSyncType = 2 << 2
+	IsoSyncTypeSync     IsoSyncType = 3 << 2
 	isoSyncTypeMask                 = 0x0C
 )
 
@@ -227,19 +329,19 @@ func (ut UsageType) String() string {
 // specify the type and destination of the control request, e.g.
 // `dev.Control(ControlOut|ControlVendor|ControlDevice, ...)`.
 const (
-	ControlIn  = C.LIBUSB_ENDPOINT_IN
-	ControlOut = C.LIBUSB_ENDPOINT_OUT
+	ControlIn  = 0x80
+	ControlOut = 0x00
 
 	// "Standard" is explicitly omitted, as functionality of standard requests
 	// is exposed through higher level operations of gousb.
-	ControlClass  = C.LIBUSB_REQUEST_TYPE_CLASS
-	ControlVendor = C.LIBUSB_REQUEST_TYPE_VENDOR
+	ControlClass  = 0x20
+	ControlVendor = 0x40
 	// "Reserved" is explicitly omitted, should not be used.
 
-	ControlDevice    = C.LIBUSB_RECIPIENT_DEVICE
-	ControlInterface = C.LIBUSB_RECIPIENT_INTERFACE
-	ControlEndpoint  = C.LIBUSB_RECIPIENT_ENDPOINT
-	ControlOther     = C.LIBUSB_RECIPIENT_OTHER
+	ControlDevice    = 0x00
+	ControlInterface = 0x01
+	ControlEndpoint  = 0x02
+	ControlOther     = 0x03
 )
 
 // Speed identifies the speed of the device.
@@ -247,19 +349,35 @@ type Speed int
 
 // Device speeds as defined in the USB spec.
 const (
-	SpeedUnknown Speed = C.LIBUSB_SPEED_UNKNOWN
-	SpeedLow     Speed = C.LIBUSB_SPEED_LOW
-	SpeedFull    Speed = C.LIBUSB_SPEED_FULL
-	SpeedHigh    Speed = C.LIBUSB_SPEED_HIGH
-	SpeedSuper   Speed = C.LIBUSB_SPEED_SUPER
+	SpeedUnknown Speed = 0
+	SpeedLow     Speed = 1
+	SpeedFull    Speed = 2
+	SpeedHigh    Speed = 3
+	SpeedSuper   Speed = 4
+	// SpeedSuperPlus is the 10 Gb/s SuperSpeedPlus (USB 3.2 Gen 2x1) and
+	// SpeedSuperPlusX2 the 20 Gb/s dual-lane speed (Gen 2x2). The latter
+	// is reported by libusb >= 1.0.27 only.
+	SpeedSuperPlus   Speed = 5
+	SpeedSuperPlusX2 Speed = 6
 )
 
 var deviceSpeedDescription = map[Speed]string{
-	SpeedUnknown: "unknown",
-	SpeedLow:     "low",
-	SpeedFull:    "full",
-	SpeedHigh:    "high",
-	SpeedSuper:   "super",
+	SpeedUnknown:     "unknown",
+	SpeedLow:         "low",
+	SpeedFull:        "full",
+	SpeedHigh:        "high",
+	SpeedSuper:       "super",
+	SpeedSuperPlus:   "super plus",
+	SpeedSuperPlusX2: "super plus x2",
+}
+
+var deviceSpeedBitRate = map[Speed]int64{
+	SpeedLow:         1500000,
+	SpeedFull:        12000000,
+	SpeedHigh:        480000000,
+	SpeedSuper:       5000000000,
+	SpeedSuperPlus:   10000000000,
+	SpeedSuperPlusX2: 20000000000,
 }
 
 // String returns a human-readable name of the device speed.
@@ -267,6 +385,63 @@ func (s Speed) String() string {
 	return deviceSpeedDescription[s]
 }
 
+// BitRate returns the signaling rate of the speed in bits per second,
+// summed over the lanes of multi-lane speeds, or 0 if unknown.
+func (s Speed) BitRate() int64 {
+	return deviceSpeedBitRate[s]
+}
+
+// Capability identifies an optional feature of the libusb library.
+type Capability uint32
+
+// Capabilities that can be queried with Context.HasCapability.
+const (
+	// CapabilityHasCapability is supported by every libusb version.
+	CapabilityHasCapability Capability = 0x0000
+	// CapabilityHotplug indicates native hotplug support.
+	CapabilityHotplug Capability = 0x0001
+	// CapabilityHIDAccess indicates that HID devices can be accessed
+	// without detaching the kernel driver.
+	CapabilityHIDAccess Capability = 0x0100
+	// CapabilityDetachKernelDriver indicates that kernel drivers can be
+	// detached from interfaces, see Device.SetAutoDetach.
+	CapabilityDetachKernelDriver Capability = 0x0101
+)
+
+var capabilityDescription = map[Capability]string{
+	CapabilityHasCapability:      "capability query",
+	CapabilityHotplug:            "hotplug",
+	CapabilityHIDAccess:          "HID access",
+	CapabilityDetachKernelDriver: "detach kernel driver",
+}
+
+// String returns a human-readable name of the capability.
+func (c Capability) String() string {
+	if d, ok := capabilityDescription[c]; ok {
+		return d
+	}
+	return strconv.Itoa(int(c))
+}
+
+// HotplugEventType identifies the kind of a hotplug event.
+type HotplugEventType uint8
+
+// Hotplug event types.
+const (
+	HotplugEventDeviceArrived HotplugEventType = 0x01
+	HotplugEventDeviceLeft    HotplugEventType = 0x02
+)
+
+var hotplugEventTypeDescription = map[HotplugEventType]string{
+	HotplugEventDeviceArrived: "device arrived",
+	HotplugEventDeviceLeft:    "device left",
+}
+
+// String returns a human-readable name of the hotplug event type.
+func (t HotplugEventType) String() string {
+	return hotplugEventTypeDescription[t]
+}
+
 const (
 	selfPoweredMask  = 0x40
 	remoteWakeupMask = 0x20
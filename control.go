@@ -0,0 +1,599 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ControlType is the type of a control request, bits 5..6 of bmRequestType.
+type ControlType uint8
+
+// Control request types as defined in the USB spec.
+const (
+	ControlTypeStandard ControlType = 0
+	ControlTypeClass    ControlType = ControlClass
+	ControlTypeVendor   ControlType = ControlVendor
+)
+
+var controlTypeDescription = map[ControlType]string{
+	ControlTypeStandard: "standard",
+	ControlTypeClass:    "class",
+	ControlTypeVendor:   "vendor",
+}
+
+func (t ControlType) String() string {
+	if s, ok := controlTypeDescription[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("reserved type 0x%02x", uint8(t))
+}
+
+// ControlRecipient is the recipient of a control request, bits 0..4
+// of bmRequestType.
+type ControlRecipient uint8
+
+// Control request recipients as defined in the USB spec.
+const (
+	ControlRecipientDevice    ControlRecipient = ControlDevice
+	ControlRecipientInterface ControlRecipient = ControlInterface
+	ControlRecipientEndpoint  ControlRecipient = ControlEndpoint
+	ControlRecipientOther     ControlRecipient = ControlOther
+)
+
+var controlRecipientDescription = map[ControlRecipient]string{
+	ControlRecipientDevice:    "device",
+	ControlRecipientInterface: "interface",
+	ControlRecipientEndpoint:  "endpoint",
+	ControlRecipientOther:     "other",
+}
+
+func (r ControlRecipient) String() string {
+	if s, ok := controlRecipientDescription[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("reserved recipient 0x%02x", uint8(r))
+}
+
+const (
+	controlTypeMask      = 0x60
+	controlRecipientMask = 0x1f
+)
+
+// SetupPacket describes a control request, i.e. the SETUP packet of
+// a control transfer, without the wLength field, which is given by the
+// length of the data buffer. It can be used with Device.ControlSetup
+// instead of packing the bmRequestType byte by hand, e.g.:
+//
+//	dev.ControlSetup(gousb.VendorRequest(gousb.EndpointDirectionIn, 0x01).WithValue(0x1234), buf)
+type SetupPacket struct {
+	// Direction is the direction of the data stage of the request.
+	Direction EndpointDirection
+	// Type is the type of the request.
+	Type ControlType
+	// Recipient is the recipient of the request.
+	Recipient ControlRecipient
+	// Request is the bRequest field.
+	Request uint8
+	// Value is the wValue field.
+	Value uint16
+	// Index is the wIndex field, usually an interface number or endpoint
+	// address, depending on the Recipient.
+	Index uint16
+}
+
+// NewSetupPacket returns a SetupPacket for the given bmRequestType
+// and bRequest.
+func NewSetupPacket(rType, request uint8) SetupPacket {
+	return SetupPacket{
+		Direction: rType&ControlIn != 0,
+		Type:      ControlType(rType & controlTypeMask),
+		Recipient: ControlRecipient(rType & controlRecipientMask),
+		Request:   request,
+	}
+}
+
+// StandardRequest returns a SetupPacket for a standard request addressed
+// to the device.
+func StandardRequest(dir EndpointDirection, request uint8) SetupPacket {
+	return SetupPacket{Direction: dir, Type: ControlTypeStandard, Recipient: ControlRecipientDevice, Request: request}
+}
+
+// ClassRequest returns a SetupPacket for a class-specific request
+// addressed to the device.
+func ClassRequest(dir EndpointDirection, request uint8) SetupPacket {
+	return SetupPacket{Direction: dir, Type: ControlTypeClass, Recipient: ControlRecipientDevice, Request: request}
+}
+
+// VendorRequest returns a SetupPacket for a vendor-specific request
+// addressed to the device.
+func VendorRequest(dir EndpointDirection, request uint8) SetupPacket {
+	return SetupPacket{Direction: dir, Type: ControlTypeVendor, Recipient: ControlRecipientDevice, Request: request}
+}
+
+// WithValue returns a copy of s with the wValue field set to val.
+func (s SetupPacket) WithValue(val uint16) SetupPacket {
+	s.Value = val
+	return s
+}
+
+// WithIndex returns a copy of s with the wIndex field set to idx.
+func (s SetupPacket) WithIndex(idx uint16) SetupPacket {
+	s.Index = idx
+	return s
+}
+
+// ToInterface returns a copy of s addressed to the interface num.
+func (s SetupPacket) ToInterface(num int) SetupPacket {
+	s.Recipient = ControlRecipientInterface
+	s.Index = uint16(num)
+	return s
+}
+
+// ToEndpoint returns a copy of s addressed to the endpoint addr.
+func (s SetupPacket) ToEndpoint(addr EndpointAddress) SetupPacket {
+	s.Recipient = ControlRecipientEndpoint
+	s.Index = uint16(addr)
+	return s
+}
+
+// RequestType returns the bmRequestType field of the request.
+func (s SetupPacket) RequestType() uint8 {
+	rType := uint8(s.Type)&controlTypeMask | uint8(s.Recipient)&controlRecipientMask
+	if s.Direction == EndpointDirectionIn {
+		rType |= ControlIn
+	}
+	return rType
+}
+
+// String returns a human-readable description of the request.
+func (s SetupPacket) String() string {
+	return fmt.Sprintf("%s %s request 0x%02x to %s, value 0x%04x, index 0x%04x", s.Direction, s.Type, s.Request, s.Recipient, s.Value, s.Index)
+}
+
+// ControlSetup sends the control request described by s to the device,
+// like Control. For IN requests, data is filled with the response,
+// for OUT requests it holds the data sent to the device.
+func (d *Device) ControlSetup(s SetupPacket, data []byte) (int, error) {
+	return d.Control(s.RequestType(), s.Request, s.Value, s.Index, data)
+}
+
+// ControlIn sends a device-to-host control request and returns the
+// response, at most length bytes. The direction bit of rType is set
+// automatically, so rType only needs the type and recipient, e.g.
+// ControlVendor|ControlDevice.
+func (d *Device) ControlIn(rType, request uint8, val, idx uint16, length int) ([]byte, error) {
+	if length < 0 || length > 0xffff {
+		return nil, fmt.Errorf("ControlIn(): length %d is out of range 0..65535", length)
+	}
+	buf := make([]byte, length)
+	n, err := d.Control(rType|ControlIn, request, val, idx, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// ControlOut sends a host-to-device control request with data, which may
+// be empty, and returns the number of bytes sent. rType holds the type and
+// recipient of the request, setting its direction bit is an error.
+func (d *Device) ControlOut(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if rType&ControlIn != 0 {
+		return 0, fmt.Errorf("ControlOut() called with bmRequestType 0x%02x, which has the IN direction bit set", rType)
+	}
+	if len(data) > 0xffff {
+		return 0, fmt.Errorf("ControlOut(): data length %d exceeds the maximum of 65535 bytes", len(data))
+	}
+	return d.Control(rType, request, val, idx, data)
+}
+
+// Request is the bRequest code of a standard request. Use it with
+// StandardRequest or Control as uint8(r).
+type Request uint8
+
+// Standard request codes, as defined in the USB spec.
+const (
+	RequestGetStatus        Request = 0x00
+	RequestClearFeature     Request = 0x01
+	RequestSetFeature       Request = 0x03
+	RequestSetAddress       Request = 0x05
+	RequestGetDescriptor    Request = 0x06
+	RequestSetDescriptor    Request = 0x07
+	RequestGetConfiguration Request = 0x08
+	RequestSetConfiguration Request = 0x09
+	RequestGetInterface     Request = 0x0a
+	RequestSetInterface     Request = 0x0b
+	RequestSynchFrame       Request = 0x0c
+	RequestSetSEL           Request = 0x30
+	RequestSetIsochDelay    Request = 0x31
+)
+
+var requestDescription = map[Request]string{
+	RequestGetStatus:        "GET_STATUS",
+	RequestClearFeature:     "CLEAR_FEATURE",
+	RequestSetFeature:       "SET_FEATURE",
+	RequestSetAddress:       "SET_ADDRESS",
+	RequestGetDescriptor:    "GET_DESCRIPTOR",
+	RequestSetDescriptor:    "SET_DESCRIPTOR",
+	RequestGetConfiguration: "GET_CONFIGURATION",
+	RequestSetConfiguration: "SET_CONFIGURATION",
+	RequestGetInterface:     "GET_INTERFACE",
+	RequestSetInterface:     "SET_INTERFACE",
+	RequestSynchFrame:       "SYNCH_FRAME",
+	RequestSetSEL:           "SET_SEL",
+	RequestSetIsochDelay:    "SET_ISOCH_DELAY",
+}
+
+func (r Request) String() string {
+	if s, ok := requestDescription[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("request 0x%02x", uint8(r))
+}
+
+// The standard request codes used internally, as bRequest bytes.
+const (
+	requestGetStatus     = uint8(RequestGetStatus)
+	requestClearFeature  = uint8(RequestClearFeature)
+	requestSetFeature    = uint8(RequestSetFeature)
+	requestGetDescriptor = uint8(RequestGetDescriptor)
+	requestGetInterface  = uint8(RequestGetInterface)
+	requestSynchFrame    = uint8(RequestSynchFrame)
+)
+
+// Feature is a feature selector of the standard SET_FEATURE and
+// CLEAR_FEATURE requests.
+type Feature uint16
+
+// Standard feature selectors as defined in the USB spec.
+const (
+	// FeatureEndpointHalt is the halt (stall) state of an endpoint.
+	FeatureEndpointHalt Feature = 0
+	// FeatureDeviceRemoteWakeup enables the device to wake up the host.
+	FeatureDeviceRemoteWakeup Feature = 1
+	// FeatureTestMode puts a high-speed device in a test mode.
+	FeatureTestMode Feature = 2
+	// FeatureU1Enable allows a SuperSpeed device to initiate U1 link
+	// power management transitions.
+	FeatureU1Enable Feature = 48
+	// FeatureU2Enable allows a SuperSpeed device to initiate U2 link
+	// power management transitions.
+	FeatureU2Enable Feature = 49
+	// FeatureLTMEnable enables latency tolerance messages of a SuperSpeed
+	// device.
+	FeatureLTMEnable Feature = 50
+)
+
+var featureDescription = map[Feature]string{
+	FeatureEndpointHalt:       "endpoint halt",
+	FeatureDeviceRemoteWakeup: "device remote wakeup",
+	FeatureTestMode:           "test mode",
+	FeatureU1Enable:           "U1 enable",
+	FeatureU2Enable:           "U2 enable",
+	FeatureLTMEnable:          "LTM enable",
+}
+
+func (f Feature) String() string {
+	if s, ok := featureDescription[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("feature %d", uint16(f))
+}
+
+// Bits of the device status returned by Device.GetStatus.
+const (
+	StatusSelfPowered  = 1 << 0
+	StatusRemoteWakeup = 1 << 1
+	// The bits below are defined for SuperSpeed devices only.
+	StatusU1Enable  = 1 << 2
+	StatusU2Enable  = 1 << 3
+	StatusLTMEnable = 1 << 4
+)
+
+// DeviceStatus is the runtime status of a device, as returned by
+// Device.Status. Unlike ConfigDesc.SelfPowered and ConfigDesc.RemoteWakeup,
+// which report the capabilities of the configuration, it reflects the
+// current state of the device.
+type DeviceStatus struct {
+	// SelfPowered is true if the device is currently powered externally.
+	SelfPowered bool
+	// RemoteWakeup is true if remote wakeup is currently enabled, see
+	// FeatureDeviceRemoteWakeup.
+	RemoteWakeup bool
+	// U1Enabled, U2Enabled and LTMEnabled report the state of the
+	// SuperSpeed link power management features.
+	U1Enabled  bool
+	U2Enabled  bool
+	LTMEnabled bool
+}
+
+// StatusHalt is the bit of the endpoint status returned by GetStatus
+// of an endpoint, set if the endpoint is halted.
+const StatusHalt = 1 << 0
+
+// getStatus sends a standard GET_STATUS request to the recipient.
+func (d *Device) getStatus(rcpt ControlRecipient, idx uint16) (uint16, error) {
+	s := StandardRequest(EndpointDirectionIn, requestGetStatus).WithIndex(idx)
+	s.Recipient = rcpt
+	buf := make([]byte, 2)
+	n, err := d.ControlSetup(s, buf)
+	if err != nil {
+		return 0, err
+	}
+	if n != 2 {
+		return 0, fmt.Errorf("GET_STATUS for %s %d of %s: got %d bytes, want 2", rcpt, idx, d, n)
+	}
+	return uint16(buf[0]) | uint16(buf[1])<<8, nil
+}
+
+// setFeature sends a standard SET_FEATURE or CLEAR_FEATURE request
+// to the recipient.
+func (d *Device) setFeature(set bool, rcpt ControlRecipient, idx uint16, f Feature) error {
+	req := requestClearFeature
+	if set {
+		req = requestSetFeature
+	}
+	s := StandardRequest(EndpointDirectionOut, req).WithValue(uint16(f)).WithIndex(idx)
+	s.Recipient = rcpt
+	_, err := d.ControlSetup(s, nil)
+	return err
+}
+
+// GetStatus returns the status of the device, a combination of the
+// StatusSelfPowered and StatusRemoteWakeup bits.
+func (d *Device) GetStatus() (uint16, error) {
+	return d.getStatus(ControlRecipientDevice, 0)
+}
+
+// Status returns the runtime status of the device, decoded from
+// GetStatus.
+func (d *Device) Status() (DeviceStatus, error) {
+	st, err := d.GetStatus()
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	return DeviceStatus{
+		SelfPowered:  st&StatusSelfPowered != 0,
+		RemoteWakeup: st&StatusRemoteWakeup != 0,
+		U1Enabled:    st&StatusU1Enable != 0,
+		U2Enabled:    st&StatusU2Enable != 0,
+		LTMEnabled:   st&StatusLTMEnable != 0,
+	}, nil
+}
+
+// SetFeature enables the device feature f, e.g. FeatureDeviceRemoteWakeup.
+func (d *Device) SetFeature(f Feature) error {
+	return d.setFeature(true, ControlRecipientDevice, 0, f)
+}
+
+// ClearFeature disables the device feature f.
+func (d *Device) ClearFeature(f Feature) error {
+	return d.setFeature(false, ControlRecipientDevice, 0, f)
+}
+
+// SetRemoteWakeup allows or forbids the device to wake up the host, e.g.
+// a keyboard waking the host from sleep on a keypress. Only devices whose
+// configuration has ConfigDesc.RemoteWakeup set support it, others
+// usually stall the request. Device.Status reports the current setting.
+func (d *Device) SetRemoteWakeup(enable bool) error {
+	return d.setFeature(enable, ControlRecipientDevice, 0, FeatureDeviceRemoteWakeup)
+}
+
+// GetStatus returns the status of the interface. All bits are reserved
+// in the USB 2.0 spec, USB 3.x defines function remote wakeup bits.
+func (i *Interface) GetStatus() (uint16, error) {
+	if i.config == nil {
+		return 0, fmt.Errorf("GetStatus() called on %s after Close", i)
+	}
+	return i.config.dev.getStatus(ControlRecipientInterface, uint16(i.Setting.Number))
+}
+
+// ActiveAlt returns the alternate setting of the interface that the device
+// reports as active, using a standard GET_INTERFACE request. It mirrors
+// Device.ActiveConfigNum and can be compared with Setting.Alternate.
+func (i *Interface) ActiveAlt() (int, error) {
+	if i.config == nil {
+		return 0, fmt.Errorf("ActiveAlt() called on %s after Close", i)
+	}
+	s := StandardRequest(EndpointDirectionIn, requestGetInterface).WithIndex(uint16(i.Setting.Number))
+	s.Recipient = ControlRecipientInterface
+	buf := make([]byte, 1)
+	n, err := i.config.dev.ControlSetup(s, buf)
+	if err != nil {
+		return 0, err
+	}
+	if n != 1 {
+		return 0, fmt.Errorf("GET_INTERFACE for %s: got %d bytes, want 1", i, n)
+	}
+	return int(buf[0]), nil
+}
+
+// GetStatus returns the status of the endpoint, StatusHalt is set if the
+// endpoint is halted.
+func (e *endpoint) GetStatus() (uint16, error) {
+	return e.dev.getStatus(ControlRecipientEndpoint, uint16(e.Desc.Address))
+}
+
+// IsHalted reports whether the endpoint is halted on the device side,
+// i.e. whether a STALL is pending. Recovery logic can use it to decide
+// between clearing the halt with ClearFeature(FeatureEndpointHalt) and
+// resetting the device.
+func (e *endpoint) IsHalted() (bool, error) {
+	st, err := e.GetStatus()
+	if err != nil {
+		return false, err
+	}
+	return st&StatusHalt != 0, nil
+}
+
+// SetFeature enables the endpoint feature f. Setting FeatureEndpointHalt
+// stalls the endpoint.
+func (e *endpoint) SetFeature(f Feature) error {
+	return e.dev.setFeature(true, ControlRecipientEndpoint, uint16(e.Desc.Address), f)
+}
+
+// ClearFeature disables the endpoint feature f. Clearing
+// FeatureEndpointHalt resumes a stalled endpoint on the device side.
+func (e *endpoint) ClearFeature(f Feature) error {
+	return e.dev.setFeature(false, ControlRecipientEndpoint, uint16(e.Desc.Address), f)
+}
+
+// SynchFrame sends a standard SYNCH_FRAME request to the isochronous
+// endpoint and returns the frame number of the start of its
+// synchronization pattern. Some audio devices expect it when switching
+// alternate settings.
+func (e *endpoint) SynchFrame() (int, error) {
+	if e.Desc.TransferType != TransferTypeIsochronous {
+		return 0, fmt.Errorf("SynchFrame() called on %s, which is not an isochronous endpoint", e)
+	}
+	s := StandardRequest(EndpointDirectionIn, requestSynchFrame).WithIndex(uint16(e.Desc.Address))
+	s.Recipient = ControlRecipientEndpoint
+	buf := make([]byte, 2)
+	n, err := e.dev.ControlSetup(s, buf)
+	if err != nil {
+		return 0, err
+	}
+	if n != 2 {
+		return 0, fmt.Errorf("SYNCH_FRAME for %s: got %d bytes, want 2", e, n)
+	}
+	return int(buf[0]) | int(buf[1])<<8, nil
+}
+
+// controlSetupSize is the size of the SETUP packet, which precedes the data
+// in the buffer of an asynchronous control transfer.
+const controlSetupSize = 8
+
+// ControlTransfer is a control request submitted asynchronously with
+// Device.SubmitControl. The request is in flight until Wait returns,
+// which needs to be called exactly once, also after Cancel.
+type ControlTransfer struct {
+	dev   *Device
+	setup SetupPacket
+	data  []byte
+	start time.Time
+	// cancelled is closed by Cancel.
+	cancelled  chan struct{}
+	cancelOnce sync.Once
+
+	mu sync.Mutex
+	// t is the transfer in flight, nil once Wait was called.
+	t *usbTransfer
+}
+
+// SubmitControl submits the control request described by s to the device
+// and returns without waiting for the response. For OUT requests, data is
+// sent to the device. For IN requests, the response is copied to data when
+// Wait returns. data must not be modified until then.
+// Unlike Control, the request is not subject to Device.ControlTimeout,
+// use a context with a deadline in Wait to limit its duration.
+func (d *Device) SubmitControl(s SetupPacket, data []byte) (*ControlTransfer, error) {
+	if d.handle == nil {
+		return nil, fmt.Errorf("SubmitControl() called on %s after Close", d)
+	}
+	if len(data) > 0xffff {
+		return nil, fmt.Errorf("SubmitControl(%s): data length %d exceeds the maximum of 65535 bytes", s, len(data))
+	}
+	ep := &EndpointDesc{
+		Address:       0,
+		TransferType:  TransferTypeControl,
+		MaxPacketSize: d.Desc.MaxControlPacketSize,
+	}
+	t, err := newUSBTransfer(d.ctx, d.handle, ep, 0, controlSetupSize+len(data))
+	if err != nil {
+		return nil, err
+	}
+	buf := t.data()
+	buf[0] = s.RequestType()
+	buf[1] = s.Request
+	binary.LittleEndian.PutUint16(buf[2:], s.Value)
+	binary.LittleEndian.PutUint16(buf[4:], s.Index)
+	binary.LittleEndian.PutUint16(buf[6:], uint16(len(data)))
+	if s.Direction == EndpointDirectionOut {
+		copy(buf[controlSetupSize:], data)
+	}
+	ct := &ControlTransfer{dev: d, setup: s, data: data, t: t, start: time.Now(), cancelled: make(chan struct{})}
+	if err := t.submit(); err != nil {
+		t.free()
+		return nil, err
+	}
+	return ct, nil
+}
+
+// String returns a human-readable description of the request.
+func (c *ControlTransfer) String() string {
+	return fmt.Sprintf("%s: %s", c.dev, c.setup)
+}
+
+// Wait waits for the request to complete and returns the number of bytes
+// transferred in the data stage. If ctx is done before the request
+// completes, the request is cancelled.
+func (c *ControlTransfer) Wait(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	t := c.t
+	c.t = nil
+	c.mu.Unlock()
+	if t == nil {
+		return 0, fmt.Errorf("Wait() called on %s that was already waited for", c)
+	}
+	// Cancel can't cancel the transfer directly, as it would block on
+	// the transfer lock held by wait. It cancels the context of wait.
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		select {
+		case <-c.cancelled:
+			stop()
+		case <-ctx.Done():
+		}
+	}()
+	n, err := t.wait(ctx)
+	c.dev.disconnect.check(err)
+	if c.setup.Direction == EndpointDirectionIn {
+		n = copy(c.data, t.data()[controlSetupSize:controlSetupSize+n])
+	}
+	t.free()
+	c.dev.ctx.metrics.endpoint(c.dev, 0).record(c.setup.Direction, n, err, time.Since(c.start))
+	return n, err
+}
+
+// Cancel aborts the request. The request is cancelled asynchronously,
+// Wait still needs to be called and returns TransferCancelled if the
+// request was aborted before it completed. Cancel may be called from
+// another goroutine while Wait is blocked.
+func (c *ControlTransfer) Cancel() error {
+	c.cancelOnce.Do(func() { close(c.cancelled) })
+	return nil
+}
+
+// ControlContext sends the control request described by s to the device,
+// like ControlSetup, but the request is cancelled when ctx is done.
+// If Device.ControlTimeout is set, it also limits the duration of
+// the request.
+func (d *Device) ControlContext(ctx context.Context, s SetupPacket, data []byte) (int, error) {
+	if d.ControlTimeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, d.ControlTimeout)
+		defer cancel()
+	}
+	c, err := d.SubmitControl(s, data)
+	if err != nil {
+		return 0, err
+	}
+	return c.Wait(ctx)
+}
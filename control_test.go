@@ -0,0 +1,398 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetupPacket(t *testing.T) {
+	for _, tc := range []struct {
+		s         SetupPacket
+		wantRType uint8
+		wantStr   string
+	}{
+		{
+			s:         VendorRequest(EndpointDirectionIn, 0x42).WithValue(0x1234),
+			wantRType: ControlIn | ControlVendor | ControlDevice,
+			wantStr:   "IN vendor request 0x42 to device, value 0x1234, index 0x0000",
+		},
+		{
+			s:         ClassRequest(EndpointDirectionOut, 0x09).ToInterface(2),
+			wantRType: ControlOut | ControlClass | ControlInterface,
+			wantStr:   "OUT class request 0x09 to interface, value 0x0000, index 0x0002",
+		},
+		{
+			s:         StandardRequest(EndpointDirectionOut, 0x01).ToEndpoint(0x82),
+			wantRType: ControlOut | ControlEndpoint,
+			wantStr:   "OUT standard request 0x01 to endpoint, value 0x0000, index 0x0082",
+		},
+	} {
+		if got := tc.s.RequestType(); got != tc.wantRType {
+			t.Errorf("%s: RequestType(): got 0x%02x, want 0x%02x", tc.wantStr, got, tc.wantRType)
+		}
+		if got := tc.s.String(); got != tc.wantStr {
+			t.Errorf("String(): got %q, want %q", got, tc.wantStr)
+		}
+		if got := NewSetupPacket(tc.wantRType, tc.s.Request).WithValue(tc.s.Value).WithIndex(tc.s.Index); got != tc.s {
+			t.Errorf("NewSetupPacket(0x%02x, 0x%02x): got %+v, want %+v", tc.wantRType, tc.s.Request, got, tc.s)
+		}
+	}
+}
+
+func TestRequestStrings(t *testing.T) {
+	for _, tc := range []struct {
+		got, want string
+	}{
+		{RequestGetDescriptor.String(), "GET_DESCRIPTOR"},
+		{RequestSetIsochDelay.String(), "SET_ISOCH_DELAY"},
+		{Request(0x42).String(), "request 0x42"},
+		{DescriptorTypeBOS.String(), "BOS"},
+		{DescriptorTypeInterfaceAssociation.String(), "interface association"},
+		{DescriptorType(0x42).String(), "descriptor type 66"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("String(): got %q, want %q", tc.got, tc.want)
+		}
+	}
+}
+
+func TestControlSetup(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	type req struct {
+		rType, request uint8
+		val, idx       uint16
+	}
+	var got req
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		got = req{rType, request, val, idx}
+		return copy(data, []byte{1, 2}), nil
+	}
+	s := VendorRequest(EndpointDirectionIn, 0x42).WithValue(0x1234).ToInterface(1)
+	n, err := dev.ControlSetup(s, make([]byte, 8))
+	if err != nil || n != 2 {
+		t.Errorf("ControlSetup(%s): got %d, %v, want 2, nil", s, n, err)
+	}
+	want := req{ControlIn | ControlVendor | ControlInterface, 0x42, 0x1234, 1}
+	if got != want {
+		t.Errorf("ControlSetup(%s): got request %+v, want %+v", s, got, want)
+	}
+}
+
+func TestControlInOut(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	var gotType uint8
+	var gotData []byte
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		gotType = rType
+		if rType&ControlIn == 0 {
+			gotData = append([]byte(nil), data...)
+			return len(data), nil
+		}
+		return copy(data, []byte{1, 2, 3}), nil
+	}
+	b, err := dev.ControlIn(ControlVendor|ControlDevice, 0x42, 0, 0, 8)
+	if err != nil || string(b) != "\x01\x02\x03" {
+		t.Errorf("ControlIn(): got % x, %v, want 01 02 03, nil", b, err)
+	}
+	if want := uint8(ControlIn | ControlVendor | ControlDevice); gotType != want {
+		t.Errorf("ControlIn(): got bmRequestType 0x%02x, want 0x%02x", gotType, want)
+	}
+	if _, err := dev.ControlIn(ControlVendor|ControlDevice, 0x42, 0, 0, 0x10000); err == nil {
+		t.Error("ControlIn() with length 65536: got nil error, want non-nil")
+	}
+
+	n, err := dev.ControlOut(ControlVendor|ControlInterface, 0x43, 0, 1, []byte{4, 5})
+	if err != nil || n != 2 || string(gotData) != "\x04\x05" {
+		t.Errorf("ControlOut(): got %d, %v, data % x, want 2, nil, data 04 05", n, err, gotData)
+	}
+	if want := uint8(ControlVendor | ControlInterface); gotType != want {
+		t.Errorf("ControlOut(): got bmRequestType 0x%02x, want 0x%02x", gotType, want)
+	}
+	if _, err := dev.ControlOut(ControlIn|ControlVendor|ControlDevice, 0x43, 0, 0, nil); err == nil {
+		t.Error("ControlOut() with the IN bit set: got nil error, want non-nil")
+	}
+}
+
+func TestControlWithTimeout(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	lib.controlFn = func(_, _ uint8, _, _ uint16, data []byte) (int, error) {
+		return len(data), nil
+	}
+	timeout := func() time.Duration {
+		lib.mu.Lock()
+		defer lib.mu.Unlock()
+		return lib.controlTimeout
+	}
+
+	dev.ControlTimeout = time.Second
+	if _, err := dev.ControlWithTimeout(time.Minute, ControlVendor|ControlDevice, 0x42, 0, 0, nil); err != nil {
+		t.Fatalf("ControlWithTimeout(1m): %v", err)
+	}
+	if got, want := timeout(), time.Minute; got != want {
+		t.Errorf("ControlWithTimeout(1m): got timeout %v, want %v", got, want)
+	}
+	if _, err := dev.Control(ControlVendor|ControlDevice, 0x42, 0, 0, nil); err != nil {
+		t.Fatalf("Control(): %v", err)
+	}
+	if got, want := timeout(), time.Second; got != want {
+		t.Errorf("Control() with ControlTimeout %v: got timeout %v, want %v", dev.ControlTimeout, got, want)
+	}
+}
+
+func TestStandardRequests(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("DefaultInterface(): %v", err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("InEndpoint(2): %v", err)
+	}
+
+	// The fake device keeps the remote wakeup and halt features.
+	features := map[uint16]uint16{}
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		key := uint16(rType&controlRecipientMask)<<8 | idx
+		switch request {
+		case requestGetStatus:
+			data[0], data[1] = uint8(features[key]), uint8(features[key]>>8)
+			return 2, nil
+		case requestSetFeature:
+			features[key] |= 1 << val
+		case requestClearFeature:
+			features[key] &^= 1 << val
+		}
+		return 0, nil
+	}
+
+	if err := dev.SetFeature(FeatureDeviceRemoteWakeup); err != nil {
+		t.Fatalf("SetFeature(%s): %v", FeatureDeviceRemoteWakeup, err)
+	}
+	if st, err := dev.GetStatus(); err != nil || st != StatusRemoteWakeup {
+		t.Errorf("GetStatus(): got 0x%04x, %v, want 0x%04x, nil", st, err, StatusRemoteWakeup)
+	}
+	features[0] |= StatusSelfPowered | StatusU1Enable
+	if st, err := dev.Status(); err != nil || st != (DeviceStatus{SelfPowered: true, RemoteWakeup: true, U1Enabled: true}) {
+		t.Errorf("Status(): got %+v, %v, want self powered, remote wakeup and U1 enabled", st, err)
+	}
+	features[0] &^= StatusSelfPowered | StatusU1Enable
+	if err := dev.SetRemoteWakeup(false); err != nil {
+		t.Fatalf("SetRemoteWakeup(false): %v", err)
+	}
+	if st, err := dev.Status(); err != nil || st.RemoteWakeup {
+		t.Errorf("Status() after SetRemoteWakeup(false): got %+v, %v, want remote wakeup disabled", st, err)
+	}
+	if err := dev.SetRemoteWakeup(true); err != nil {
+		t.Fatalf("SetRemoteWakeup(true): %v", err)
+	}
+	if err := dev.ClearFeature(FeatureDeviceRemoteWakeup); err != nil {
+		t.Fatalf("ClearFeature(%s): %v", FeatureDeviceRemoteWakeup, err)
+	}
+	if st, err := dev.GetStatus(); err != nil || st != 0 {
+		t.Errorf("GetStatus() after ClearFeature: got 0x%04x, %v, want 0, nil", st, err)
+	}
+
+	if halted, err := ep.IsHalted(); err != nil || halted {
+		t.Errorf("%s.IsHalted(): got %v, %v, want false, nil", ep, halted, err)
+	}
+	if err := ep.SetFeature(FeatureEndpointHalt); err != nil {
+		t.Fatalf("%s.SetFeature(%s): %v", ep, FeatureEndpointHalt, err)
+	}
+	if halted, err := ep.IsHalted(); err != nil || !halted {
+		t.Errorf("%s.IsHalted() after SetFeature(%s): got %v, %v, want true, nil", ep, FeatureEndpointHalt, halted, err)
+	}
+	if st, err := ep.GetStatus(); err != nil || st != StatusHalt {
+		t.Errorf("%s.GetStatus(): got 0x%04x, %v, want 0x%04x, nil", ep, st, err, StatusHalt)
+	}
+	if st, err := dev.GetStatus(); err != nil || st != 0 {
+		t.Errorf("GetStatus() after endpoint SetFeature: got 0x%04x, %v, want 0, nil", st, err)
+	}
+	if st, err := intf.GetStatus(); err != nil || st != 0 {
+		t.Errorf("%s.GetStatus(): got 0x%04x, %v, want 0, nil", intf, st, err)
+	}
+}
+
+func TestSubmitControl(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	s := VendorRequest(EndpointDirectionIn, 0x42).WithValue(0x1234).WithIndex(0x0201)
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		wantSetup := []byte{0xc0, 0x42, 0x34, 0x12, 0x01, 0x02, 0x04, 0x00}
+		if !bytes.Equal(ft.buf[:controlSetupSize], wantSetup) {
+			t.Errorf("setup packet: got %x, want %x", ft.buf[:controlSetupSize], wantSetup)
+		}
+		copy(ft.buf[controlSetupSize:], []byte{1, 2, 3})
+		ft.setLength(3)
+		ft.setStatus(TransferCompleted)
+	}()
+	buf := make([]byte, 4)
+	c, err := dev.SubmitControl(s, buf)
+	if err != nil {
+		t.Fatalf("SubmitControl(%s): %v", s, err)
+	}
+	if n, err := c.Wait(context.Background()); err != nil || n != 3 {
+		t.Fatalf("Wait(): got %d, %v, want 3, nil", n, err)
+	}
+	if want := []byte{1, 2, 3, 0}; !bytes.Equal(buf, want) {
+		t.Errorf("Wait(): got data %v, want %v", buf, want)
+	}
+	if _, err := c.Wait(context.Background()); err == nil {
+		t.Errorf("second Wait(): got nil error, want non-nil")
+	}
+
+	// Cancel from another goroutine aborts a request blocked in Wait.
+	submitted := make(chan *ControlTransfer, 1)
+	go func() {
+		lib.waitForSubmitted(nil)
+		c := <-submitted
+		time.Sleep(10 * time.Millisecond)
+		c.Cancel()
+	}()
+	c, err = dev.SubmitControl(s, buf)
+	if err != nil {
+		t.Fatalf("SubmitControl(%s): %v", s, err)
+	}
+	submitted <- c
+	if _, err := c.Wait(context.Background()); err != TransferCancelled {
+		t.Errorf("Wait() with a concurrent Cancel: got error %v, want %v", err, TransferCancelled)
+	}
+	if err := c.Cancel(); err != nil {
+		t.Errorf("Cancel() after Wait: %v", err)
+	}
+
+	// A slow request is cancelled with the context.
+	go lib.waitForSubmitted(nil)
+	cctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := dev.ControlContext(cctx, s, buf); err != TransferCancelled {
+		t.Errorf("ControlContext() with expired context: got error %v, want %v", err, TransferCancelled)
+	}
+}
+
+func TestSynchFrame(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(8888:0002): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+	intf, err := cfg.Interface(1, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(1, 0): %v", cfg, err)
+	}
+	defer intf.Close()
+	ep, err := intf.InEndpoint(6)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(6): %v", intf, err)
+	}
+
+	type req struct {
+		rType, request uint8
+		val, idx       uint16
+	}
+	var got req
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		got = req{rType, request, val, idx}
+		return copy(data, []byte{0x34, 0x02}), nil
+	}
+	frame, err := ep.SynchFrame()
+	if err != nil || frame != 0x234 {
+		t.Errorf("%s.SynchFrame(): got %d, %v, want %d, nil", ep, frame, err, 0x234)
+	}
+	if want := (req{ControlIn | ControlEndpoint, requestSynchFrame, 0, 0x86}); got != want {
+		t.Errorf("%s.SynchFrame(): got request %+v, want %+v", ep, got, want)
+	}
+	if alt, err := intf.ActiveAlt(); err != nil || alt != 0x34 {
+		t.Errorf("%s.ActiveAlt(): got %d, %v, want %d, nil", intf, alt, err, 0x34)
+	}
+	if want := (req{ControlIn | ControlInterface, requestGetInterface, 0, 1}); got != want {
+		t.Errorf("%s.ActiveAlt(): got request %+v, want %+v", intf, got, want)
+	}
+
+	bulkDev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer bulkDev.Close()
+	bulkIntf, done, err := bulkDev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("DefaultInterface(): %v", err)
+	}
+	defer done()
+	bulk, err := bulkIntf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", bulkIntf, err)
+	}
+	if _, err := bulk.SynchFrame(); err == nil {
+		t.Errorf("%s.SynchFrame(): got nil error, want non-nil", bulk)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "fmt"
+
+// Raw descriptor types and sizes used by ParseConfigDescriptor.
+const (
+	rawDescTypeConfig        = 0x02
+	rawDescTypeInterface     = 0x04
+	rawDescTypeEndpoint      = 0x05
+	rawDescTypeIAD           = 0x0b
+	rawDescTypeSSEPCompanion = 0x30
+
+	rawConfigDescSize    = 9
+	rawInterfaceDescSize = 9
+	rawEndpointDescSize  = 7
+	rawSSEPCompanionSize = 6
+	rawIADSize           = 8
+)
+
+// ParseConfigDescriptor decodes a raw configuration descriptor, including
+// the interface and endpoint descriptors that follow it, e.g. one captured
+// with usbmon, extracted from a firmware image or copied from a bug report.
+//
+// Some fields are encoded differently depending on the speed of the device,
+// speed is the speed at which the descriptor was retrieved. With
+// SpeedUnknown, the descriptor is decoded as if it came from a full speed
+// device. Interface association descriptors are decoded into Associations.
+// Like with libusb, the other descriptors are kept in the Extra field of
+// the interface or endpoint they follow, descriptors that precede the first
+// interface are skipped. Registered class descriptor parsers are applied,
+// see RegisterClassDescriptorParser.
+func ParseConfigDescriptor(b []byte, speed Speed) (ConfigDesc, error) {
+	if len(b) < rawConfigDescSize || b[1] != rawDescTypeConfig {
+		return ConfigDesc{}, fmt.Errorf("invalid configuration descriptor header % x", b)
+	}
+	total := int(b[2]) | int(b[3])<<8
+	if total > len(b) {
+		return ConfigDesc{}, fmt.Errorf("configuration descriptor is %d bytes long, want %d", len(b), total)
+	}
+	dev := &DeviceDesc{Spec: Version(2, 0), Speed: speed}
+	if speed >= SpeedSuper {
+		dev.Spec = Version(3, 0)
+	}
+	c := ConfigDesc{
+		Number:         int(b[5]),
+		SelfPowered:    b[7]&selfPoweredMask != 0,
+		RemoteWakeup:   b[7]&remoteWakeupMask != 0,
+		MaxPower:       maxPower(b[8], speed),
+		MaxPowerRaw:    int(b[8]),
+		iConfiguration: int(b[6]),
+	}
+
+	var (
+		alt *InterfaceSetting
+		ep  *EndpointDesc
+		// index of the interface number in c.Interfaces
+		intfIdx = make(map[int]int)
+		// a map of interface numbers to a set of alternate settings numbers
+		hasIntf = make(map[int]map[int]bool)
+	)
+	// flush adds the pending alternate setting to its interface.
+	flush := func() {
+		if alt == nil {
+			return
+		}
+		if ep != nil {
+			alt.Endpoints[ep.Address] = *ep
+			ep = nil
+		}
+		parseClassDesc(alt)
+		idx, ok := intfIdx[alt.Number]
+		if !ok {
+			idx = len(c.Interfaces)
+			intfIdx[alt.Number] = idx
+			c.Interfaces = append(c.Interfaces, InterfaceDesc{Number: alt.Number})
+		}
+		c.Interfaces[idx].AltSettings = append(c.Interfaces[idx].AltSettings, *alt)
+		alt = nil
+	}
+	skip := false
+	for off := int(b[0]); off < total; {
+		l := int(b[off])
+		if l < 2 || off+l > total {
+			return ConfigDesc{}, fmt.Errorf("invalid descriptor length %d at offset %d of the configuration descriptor", l, off)
+		}
+		d := b[off : off+l]
+		off += l
+		if d[1] != rawDescTypeInterface && d[1] != rawDescTypeEndpoint && !skip {
+			switch {
+			case ep != nil:
+				ep.Extra = append(ep.Extra, d...)
+			case alt != nil:
+				alt.Extra = append(alt.Extra, d...)
+			}
+		}
+		switch d[1] {
+		case rawDescTypeInterface:
+			if l < rawInterfaceDescSize {
+				return ConfigDesc{}, fmt.Errorf("interface descriptor too short: % x", d)
+			}
+			flush()
+			num, altNum := int(d[2]), int(d[3])
+			// Like libusb, use only the first of duplicated alternate settings.
+			skip = hasIntf[num][altNum]
+			if skip {
+				continue
+			}
+			if hasIntf[num] == nil {
+				hasIntf[num] = make(map[int]bool)
+			}
+			hasIntf[num][altNum] = true
+			alt = &InterfaceSetting{
+				Number:     num,
+				Alternate:  altNum,
+				Class:      Class(d[5]),
+				SubClass:   Class(d[6]),
+				Protocol:   Protocol(d[7]),
+				Endpoints:  make(map[EndpointAddress]EndpointDesc),
+				iInterface: int(d[8]),
+			}
+		case rawDescTypeEndpoint:
+			if skip {
+				continue
+			}
+			if alt == nil {
+				return ConfigDesc{}, fmt.Errorf("endpoint descriptor outside of an interface: % x", d)
+			}
+			if l < rawEndpointDescSize {
+				return ConfigDesc{}, fmt.Errorf("endpoint descriptor too short: % x", d)
+			}
+			if ep != nil {
+				alt.Endpoints[ep.Address] = *ep
+			}
+			e := parseEndpointDesc(d, dev)
+			ep = &e
+		case rawDescTypeIAD:
+			if l < rawIADSize {
+				return ConfigDesc{}, fmt.Errorf("interface association descriptor too short: % x", d)
+			}
+			c.Associations = append(c.Associations, InterfaceAssociation{
+				FirstInterface: int(d[2]),
+				InterfaceCount: int(d[3]),
+				Class:          Class(d[4]),
+				SubClass:       Class(d[5]),
+				Protocol:       Protocol(d[6]),
+				iFunction:      int(d[7]),
+			})
+		case rawDescTypeSSEPCompanion:
+			if skip || ep == nil || l < rawSSEPCompanionSize {
+				continue
+			}
+			ep.setSSCompanion(d[2], d[3], uint16(d[4])|uint16(d[5])<<8)
+		}
+	}
+	flush()
+	return c, nil
+}
+
+// parseEndpointDesc decodes a raw endpoint descriptor of the device dev.
+func parseEndpointDesc(d []byte, dev *DeviceDesc) EndpointDesc {
+	addr, attrs := d[2], d[3]
+	wMaxPacketSize := uint16(d[4]) | uint16(d[5])<<8
+	ei := EndpointDesc{
+		Address:       EndpointAddress(addr),
+		Number:        int(addr & endpointNumMask),
+		Direction:     EndpointDirection(addr&endpointDirectionMask != 0),
+		TransferType:  TransferType(attrs & transferTypeMask),
+		MaxPacketSize: int(wMaxPacketSize),
+		Interval:      int(d[6]),
+	}
+	if ei.TransferType == TransferTypeIsochronous || ei.TransferType == TransferTypeInterrupt {
+		ei.MaxPacketSize = periodicMaxPacketSize(wMaxPacketSize)
+	}
+	if ei.TransferType == TransferTypeIsochronous {
+		ei.IsoSyncType = IsoSyncType(attrs & isoSyncTypeMask)
+		switch (attrs & usageTypeMask) >> 4 {
+		case 0:
+			ei.UsageType = IsoUsageTypeData
+		case 1:
+			ei.UsageType = IsoUsageTypeFeedback
+		case 2:
+			ei.UsageType = IsoUsageTypeImplicit
+		}
+	}
+	ei.PollInterval = pollInterval(d[6], ei.TransferType, dev)
+	return ei
+}
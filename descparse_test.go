@@ -0,0 +1,106 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConfigDescriptor(t *testing.T) {
+	raw := []byte{
+		0x09, 0x02, 0x39, 0x00, 0x02, 0x01, 0x04, 0xa0, 0x32, // config 1, 2 interfaces, remote wakeup, 100mA
+		0x09, 0x04, 0x00, 0x00, 0x02, 0xff, 0x01, 0x02, 0x05, // interface 0 alt 0, vendor specific
+		0x07, 0x05, 0x81, 0x02, 0x00, 0x02, 0x00, // EP 0x81 bulk IN, 512 bytes
+		0x07, 0x05, 0x02, 0x02, 0x00, 0x02, 0x00, // EP 0x02 bulk OUT, 512 bytes
+		0x09, 0x04, 0x01, 0x00, 0x01, 0x03, 0x00, 0x00, 0x00, // interface 1 alt 0, HID
+		0x09, 0x21, 0x11, 0x01, 0x00, 0x01, 0x22, 0x20, 0x00, // HID class descriptor, kept in Extra
+		0x07, 0x05, 0x83, 0x03, 0x08, 0x00, 0x04, // EP 0x83 interrupt IN, 8 bytes, bInterval 4
+	}
+	got, err := ParseConfigDescriptor(raw, SpeedHigh)
+	if err != nil {
+		t.Fatalf("ParseConfigDescriptor(): %v", err)
+	}
+	want := ConfigDesc{
+		Number:         1,
+		RemoteWakeup:   true,
+		MaxPower:       100,
+		MaxPowerRaw:    0x32,
+		iConfiguration: 4,
+		Interfaces: []InterfaceDesc{{
+			Number: 0,
+			AltSettings: []InterfaceSetting{{
+				Number:   0,
+				Class:    ClassVendorSpec,
+				SubClass: 1,
+				Protocol: 2,
+				Endpoints: map[EndpointAddress]EndpointDesc{
+					0x81: {Address: 0x81, Number: 1, Direction: EndpointDirectionIn, TransferType: TransferTypeBulk, MaxPacketSize: 512},
+					0x02: {Address: 0x02, Number: 2, Direction: EndpointDirectionOut, TransferType: TransferTypeBulk, MaxPacketSize: 512},
+				},
+				iInterface: 5,
+			}},
+		}, {
+			Number: 1,
+			AltSettings: []InterfaceSetting{{
+				Number: 1,
+				Class:  ClassHID,
+				Endpoints: map[EndpointAddress]EndpointDesc{
+					0x83: {Address: 0x83, Number: 3, Direction: EndpointDirectionIn, TransferType: TransferTypeInterrupt, MaxPacketSize: 8, Interval: 4, PollInterval: time.Millisecond},
+				},
+				Extra: []byte{0x09, 0x21, 0x11, 0x01, 0x00, 0x01, 0x22, 0x20, 0x00},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseConfigDescriptor():\ngot  %#v\nwant %#v", got, want)
+	}
+
+	for _, b := range [][]byte{
+		nil,
+		raw[:20],
+		{0x09, 0x02, 0x10, 0x00, 0x01, 0x01, 0x00, 0x80, 0x32, 0x07, 0x05, 0x81, 0x02, 0x00, 0x02, 0x00},
+		{0x09, 0x02, 0x0b, 0x00, 0x01, 0x01, 0x00, 0x80, 0x32, 0x00, 0x04},
+	} {
+		if _, err := ParseConfigDescriptor(b, SpeedHigh); err == nil {
+			t.Errorf("ParseConfigDescriptor(% x): got nil error, want non-nil", b)
+		}
+	}
+}
+
+func TestParseConfigDescriptorSuperSpeed(t *testing.T) {
+	raw := []byte{
+		0x09, 0x02, 0x1f, 0x00, 0x01, 0x01, 0x00, 0x80, 0x70, // config 1, 896mA at SuperSpeed
+		0x09, 0x04, 0x00, 0x00, 0x01, 0x08, 0x06, 0x50, 0x00, // mass storage
+		0x07, 0x05, 0x81, 0x02, 0x00, 0x04, 0x00, // EP 0x81 bulk IN, 1024 bytes
+		0x06, 0x30, 0x0f, 0x00, 0x00, 0x00, // companion, burst of 16
+	}
+	got, err := ParseConfigDescriptor(raw, SpeedSuper)
+	if err != nil {
+		t.Fatalf("ParseConfigDescriptor(): %v", err)
+	}
+	if got.MaxPower != 896 {
+		t.Errorf("MaxPower: got %dmA, want 896mA", got.MaxPower)
+	}
+	ep := got.Interfaces[0].AltSettings[0].Endpoints[0x81]
+	if ep.MaxBurst != 16 || ep.MaxPacketSize != 1024 {
+		t.Errorf("endpoint 0x81: got MaxBurst %d, MaxPacketSize %d, want 16, 1024", ep.MaxBurst, ep.MaxPacketSize)
+	}
+	if want := raw[len(raw)-6:]; !bytes.Equal(ep.Extra, want) {
+		t.Errorf("endpoint 0x81: got Extra % x, want % x", ep.Extra, want)
+	}
+}
@@ -16,10 +16,14 @@
 package gousb
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
 	"time"
+	"unsafe"
 )
 
 // DeviceDesc is a representation of a USB device descriptor.
@@ -29,6 +33,7 @@ type DeviceDesc struct {
 	Address int   // The address of the device on the bus
 	Speed   Speed // The negotiated operating speed for the device
 	Port    int   // The usb port on which the device was detected
+	Path    []int // Port numbers from the root hub to the device, the last one is Port
 
 	// Version information
 	Spec   BCD // USB Specification Release Number
@@ -57,6 +62,36 @@ func (d *DeviceDesc) String() string {
 	return fmt.Sprintf("%d.%d: %s:%s (available configs: %v)", d.Bus, d.Address, d.Vendor, d.Product, d.sortedConfigIds())
 }
 
+// deviceDesc has the fields of DeviceDesc, without its methods.
+type deviceDesc DeviceDesc
+
+// MarshalJSON implements json.Marshaler. Along with the exported fields,
+// the encoded descriptor includes the indices of the device string
+// descriptors, so that it can be fully restored by UnmarshalJSON.
+func (d DeviceDesc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		deviceDesc
+		IManufacturer int `json:"iManufacturer"`
+		IProduct      int `json:"iProduct"`
+		ISerialNumber int `json:"iSerialNumber"`
+	}{deviceDesc(d), d.iManufacturer, d.iProduct, d.iSerialNumber})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *DeviceDesc) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*deviceDesc
+		IManufacturer int `json:"iManufacturer"`
+		IProduct      int `json:"iProduct"`
+		ISerialNumber int `json:"iSerialNumber"`
+	}{deviceDesc: (*deviceDesc)(d)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	d.iManufacturer, d.iProduct, d.iSerialNumber = v.IManufacturer, v.IProduct, v.ISerialNumber
+	return nil
+}
+
 func (d *DeviceDesc) sortedConfigIds() []int {
 	var cfgs []int
 	for c := range d.Configs {
@@ -94,6 +129,23 @@ type Device struct {
 
 	// Handle AutoDetach in this library
 	autodetach bool
+
+	// disconnect is set when the device is found to be gone.
+	disconnect disconnectState
+
+	// autosuspendDelay is the autosuspend delay of the device saved by
+	// Suspend, to be restored by Resume. Protected by mu.
+	autosuspendDelay string
+
+	// String descriptors read so far, indexed by the descriptor index.
+	// The cache is cleared on Reset.
+	strMu    sync.Mutex
+	strCache map[int]string
+
+	// Buffers allocated through AllocTransferBuffer, indexed by the
+	// address of the first byte.
+	bufMu        sync.Mutex
+	transferBufs map[uintptr][]byte
 }
 
 // String represents a human readable representation of the device.
@@ -101,17 +153,40 @@ func (d *Device) String() string {
 	return fmt.Sprintf("vid=%s,pid=%s,bus=%d,addr=%d", d.Desc.Vendor, d.Desc.Product, d.Desc.Bus, d.Desc.Address)
 }
 
+// ErrDeviceReenumerated is returned by Device.Reset if the device
+// re-enumerated during the reset, e.g. because its descriptors changed.
+// The Device is no longer usable, it must be closed and the device needs
+// to be opened again.
+var ErrDeviceReenumerated = errors.New("device re-enumerated after reset, it must be closed and opened again")
+
 // Reset performs a USB port reset to reinitialize a device.
+//
+// Reset can be called while the device has an active config with claimed
+// interfaces, e.g. to recover from an error. If the device comes back in
+// the same state, the config and the interfaces are restored and remain
+// usable. If the device re-enumerates instead, Reset releases the claimed
+// interfaces and the config and returns ErrDeviceReenumerated, the Device
+// must then be closed and opened again.
 func (d *Device) Reset() error {
 	if d.handle == nil {
 		return fmt.Errorf("Reset() called on %s after Close", d)
 	}
+	err := d.ctx.libusb.reset(d.handle)
+	d.strMu.Lock()
+	d.strCache = nil
+	d.strMu.Unlock()
+	if err != ErrorNotFound && err != ErrorNoDevice {
+		return err
+	}
 	d.mu.Lock()
-	defer d.mu.Unlock()
-	if d.claimed != nil {
-		return fmt.Errorf("can't reset device %s while it has an active configuration %s", d, d.claimed)
+	cfg := d.claimed
+	d.mu.Unlock()
+	if cfg != nil {
+		if err := cfg.release(); err != nil {
+			return fmt.Errorf("failed to release %s after the device re-enumerated: %v", cfg, err)
+		}
 	}
-	return d.ctx.libusb.reset(d.handle)
+	return ErrDeviceReenumerated
 }
 
 // ActiveConfigNum returns the config id of the active configuration.
@@ -140,14 +215,24 @@ func (d *Device) Config(cfgNum int) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("device %s: %v", d, err)
 	}
+	for _, other := range d.otherConfigs() {
+		if other.Desc.Number != cfgNum {
+			return nil, fmt.Errorf("can't set config %d of %s, config %d is in use through another handle of the device", cfgNum, d, other.Desc.Number)
+		}
+	}
 	cfg := &Config{
 		Desc:    *desc,
 		dev:     d,
-		claimed: make(map[int]bool),
+		claimed: make(map[int]*Interface),
+		cancel:  make(chan struct{}),
 	}
 
 	if d.autodetach {
 		for _, iface := range cfg.Desc.Interfaces {
+			if d.claimedElsewhere(iface.Number) {
+				// Held by another handle, there's no kernel driver.
+				continue
+			}
 			if err := d.ctx.libusb.detachKernelDriver(d.handle, uint8(iface.Number)); err != nil {
 				return nil, fmt.Errorf("Can't detach kernel driver of the device %s and interface %d: %v", d, iface.Number, err)
 			}
@@ -164,11 +249,13 @@ func (d *Device) Config(cfgNum int) (*Config, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.claimed = cfg
+	d.ctx.log(LogOpConfig, nil, LogField{"device", d.String()}, LogField{"config", cfgNum})
 	return cfg, nil
 }
 
 // DefaultInterface opens interface #0 with alternate setting #0 of the currently active
-// config. It's intended as a shortcut for devices that have the simplest
+// config, or of the first config if the device is not configured yet.
+// It's intended as a shortcut for devices that have the simplest
 // interface of a single config, interface and alternate setting.
 // The done func should be called to release the claimed interface and config.
 func (d *Device) DefaultInterface() (intf *Interface, done func(), err error) {
@@ -176,6 +263,10 @@ func (d *Device) DefaultInterface() (intf *Interface, done func(), err error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get active config number of device %s: %v", d, err)
 	}
+	if ids := d.Desc.sortedConfigIds(); cfgNum == 0 && len(ids) > 0 {
+		// Config value 0 means the device is in the address state.
+		cfgNum = ids[0]
+	}
 	cfg, err := d.Config(cfgNum)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to claim config %d of device %s: %v", cfgNum, d, err)
@@ -186,7 +277,7 @@ func (d *Device) DefaultInterface() (intf *Interface, done func(), err error) {
 		return nil, nil, fmt.Errorf("failed to select interface #%d alternate setting %d of config %d of device %s: %v", 0, 0, cfgNum, d, err)
 	}
 	return i, func() {
-		intf.Close()
+		i.Close()
 		cfg.Close()
 	}, nil
 }
@@ -196,7 +287,59 @@ func (d *Device) Control(rType, request uint8, val, idx uint16, data []byte) (in
 	if d.handle == nil {
 		return 0, fmt.Errorf("Control() called on %s after Close", d)
 	}
-	return d.ctx.libusb.control(d.handle, d.ControlTimeout, rType, request, val, idx, data)
+	return d.control(d.ControlTimeout, rType, request, val, idx, data)
+}
+
+// ControlWithTimeout sends a control request to the device like Control,
+// but uses timeout instead of ControlTimeout, e.g. for a flash erase
+// command that takes much longer than the other requests. A zero timeout
+// means no timeout.
+func (d *Device) ControlWithTimeout(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if d.handle == nil {
+		return 0, fmt.Errorf("ControlWithTimeout() called on %s after Close", d)
+	}
+	return d.control(timeout, rType, request, val, idx, data)
+}
+
+func (d *Device) control(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	start := time.Now()
+	n, err := d.ctx.libusb.control(d.handle, timeout, rType, request, val, idx, data)
+	d.disconnect.check(err)
+	dir := EndpointDirectionOut
+	if rType&ControlIn != 0 {
+		dir = EndpointDirectionIn
+	}
+	d.ctx.metrics.endpoint(d, 0).record(dir, n, err, time.Since(start))
+	if !d.ctx.tracing() {
+		return n, err
+	}
+	var payload, outData []byte
+	if n > 0 {
+		payload = data[:n]
+	}
+	if dir == EndpointDirectionOut {
+		outData = data
+	}
+	setup := make([]byte, 8)
+	setup[0], setup[1] = rType, request
+	binary.LittleEndian.PutUint16(setup[2:], val)
+	binary.LittleEndian.PutUint16(setup[4:], idx)
+	binary.LittleEndian.PutUint16(setup[6:], uint16(len(data)))
+	d.ctx.trace(traceRecord{
+		bus:         d.Desc.Bus,
+		addr:        d.Desc.Address,
+		dir:         dir,
+		tt:          TransferTypeControl,
+		setup:       fmt.Sprintf("bmRequestType=0x%02x bRequest=0x%02x wValue=0x%04x wIndex=0x%04x", rType, request, val, idx),
+		setupPacket: setup,
+		length:      len(data),
+		outData:     outData,
+		data:        payload,
+		err:         err,
+		start:       start,
+		dur:         time.Since(start),
+	})
+	return n, err
 }
 
 // Close closes the device.
@@ -209,15 +352,155 @@ func (d *Device) Close() error {
 	if d.claimed != nil {
 		return fmt.Errorf("can't release the device %s, it has an open config %d", d, d.claimed.Desc.Number)
 	}
+	d.freeTransferBuffers()
 	d.ctx.closeDev(d)
+	d.ctx.log(LogOpClose, nil, LogField{"device", d.String()})
 	d.handle = nil
 	return nil
 }
 
+// OpenAgain opens another handle to the physical device of d. The handles
+// are independent Devices, each is closed separately and closing one
+// doesn't affect the other, which lets a component monitor an interrupt
+// endpoint while another component with its own lifecycle claims
+// a different interface.
+//
+// The handles share the state of the physical device:
+//   - an interface can be claimed through one handle at a time, claiming
+//     it through another handle fails until it's released;
+//   - Config through one handle fails for a configuration other than
+//     the one claimed through another handle, as setting it would reset
+//     the interfaces of the other handle;
+//   - Reset resets the device for all handles.
+func (d *Device) OpenAgain() (*Device, error) {
+	if d.handle == nil {
+		return nil, fmt.Errorf("OpenAgain() called on %s after Close", d)
+	}
+	return d.ctx.openDevice(d.ctx.libusb.getDevice(d.handle), d.Desc)
+}
+
+// otherConfigs returns the configs claimed through the other handles of
+// the physical device of d.
+func (d *Device) otherConfigs() []*Config {
+	c := d.ctx
+	c.mu.Lock()
+	var others []*Device
+	for o := range c.devices {
+		if o != d && o.Desc.Bus == d.Desc.Bus && o.Desc.Address == d.Desc.Address {
+			others = append(others, o)
+		}
+	}
+	c.mu.Unlock()
+	var ret []*Config
+	for _, o := range others {
+		o.mu.Lock()
+		if o.claimed != nil {
+			ret = append(ret, o.claimed)
+		}
+		o.mu.Unlock()
+	}
+	return ret
+}
+
+// claimedElsewhere reports whether interface num is claimed through
+// another handle of the physical device of d.
+func (d *Device) claimedElsewhere(num int) bool {
+	for _, cfg := range d.otherConfigs() {
+		cfg.mu.Lock()
+		claimed := cfg.claimed[num] != nil
+		cfg.mu.Unlock()
+		if claimed {
+			return true
+		}
+	}
+	return false
+}
+
+// AllocTransferBuffer allocates a buffer of n bytes for transfers to and
+// from the device's endpoints. The memory is allocated by the host USB
+// stack, using memory directly accessible by the USB controller where
+// the platform supports it (currently Linux only). A Read or Write on
+// an endpoint of this device that uses such a buffer transfers the data
+// in place, avoiding copies between the Go buffer, the transfer buffer
+// and the kernel memory.
+// If the platform doesn't support it, AllocTransferBuffer returns
+// ErrorNotSupported and a regular Go buffer should be used instead.
+// The buffer needs to be released with FreeTransferBuffer when no longer
+// needed, all remaining buffers are released when the device is closed.
+func (d *Device) AllocTransferBuffer(n int) ([]byte, error) {
+	if d.handle == nil {
+		return nil, fmt.Errorf("AllocTransferBuffer(%d) called on %s after Close", n, d)
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("AllocTransferBuffer(%d): buffer size must be positive", n)
+	}
+	buf, err := d.ctx.libusb.devMemAlloc(d.handle, n)
+	if err != nil {
+		return nil, err
+	}
+	d.bufMu.Lock()
+	defer d.bufMu.Unlock()
+	if d.transferBufs == nil {
+		d.transferBufs = make(map[uintptr][]byte)
+	}
+	d.transferBufs[bufAddr(buf)] = buf
+	return buf, nil
+}
+
+// FreeTransferBuffer releases a buffer obtained from AllocTransferBuffer.
+// The buffer must not be used after it's released.
+func (d *Device) FreeTransferBuffer(buf []byte) error {
+	if d.handle == nil {
+		return fmt.Errorf("FreeTransferBuffer() called on %s after Close", d)
+	}
+	d.bufMu.Lock()
+	defer d.bufMu.Unlock()
+	orig, ok := d.transferBufs[bufAddr(buf)]
+	if !ok || len(buf) == 0 {
+		return fmt.Errorf("buffer passed to FreeTransferBuffer was not allocated by %s.AllocTransferBuffer", d)
+	}
+	delete(d.transferBufs, bufAddr(buf))
+	return d.ctx.libusb.devMemFree(d.handle, orig)
+}
+
+func (d *Device) freeTransferBuffers() {
+	d.bufMu.Lock()
+	defer d.bufMu.Unlock()
+	for addr, buf := range d.transferBufs {
+		d.ctx.libusb.devMemFree(d.handle, buf)
+		delete(d.transferBufs, addr)
+	}
+}
+
+// isTransferBuffer returns true if buf is a part of a buffer obtained from
+// AllocTransferBuffer.
+func (d *Device) isTransferBuffer(buf []byte) bool {
+	if len(buf) == 0 {
+		return false
+	}
+	d.bufMu.Lock()
+	defer d.bufMu.Unlock()
+	start, end := bufAddr(buf), bufAddr(buf)+uintptr(len(buf))
+	for addr, b := range d.transferBufs {
+		if start >= addr && end <= addr+uintptr(len(b)) {
+			return true
+		}
+	}
+	return false
+}
+
+func bufAddr(buf []byte) uintptr {
+	if len(buf) == 0 {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(&buf[0]))
+}
+
 // GetStringDescriptor returns a device string descriptor with the given index
 // number. The first supported language is always used and the returned
 // descriptor string is converted to ASCII (non-ASCII characters are replaced
-// with "?").
+// with "?"). Descriptors are cached after the first successful read, until
+// the device is Reset.
 func (d *Device) GetStringDescriptor(descIndex int) (string, error) {
 	if d.handle == nil {
 		return "", fmt.Errorf("GetStringDescriptor(%d) called on %s after Close", descIndex, d)
@@ -226,22 +509,41 @@ func (d *Device) GetStringDescriptor(descIndex int) (string, error) {
 	if descIndex == 0 {
 		return "", nil
 	}
-	return d.ctx.libusb.getStringDesc(d.handle, descIndex)
+	d.strMu.Lock()
+	defer d.strMu.Unlock()
+	if s, ok := d.strCache[descIndex]; ok {
+		return s, nil
+	}
+	s, err := d.ctx.libusb.getStringDesc(d.handle, descIndex)
+	if err != nil {
+		return "", err
+	}
+	if d.strCache == nil {
+		d.strCache = make(map[int]string)
+	}
+	d.strCache[descIndex] = s
+	return s, nil
 }
 
-// Manufacturer returns the device's manufacturer name.
+// Manufacturer returns the device's manufacturer name, using the string descriptor
+// index from the device descriptor. If the device doesn't provide one,
+// Manufacturer returns an empty string and a nil error.
 // GetStringDescriptor's string conversion rules apply.
 func (d *Device) Manufacturer() (string, error) {
 	return d.GetStringDescriptor(d.Desc.iManufacturer)
 }
 
-// Product returns the device's product name.
+// Product returns the device's product name, using the string descriptor
+// index from the device descriptor. If the device doesn't provide one,
+// Product returns an empty string and a nil error.
 // GetStringDescriptor's string conversion rules apply.
 func (d *Device) Product() (string, error) {
 	return d.GetStringDescriptor(d.Desc.iProduct)
 }
 
-// SerialNumber returns the device's serial number.
+// SerialNumber returns the device's serial number, using the string descriptor
+// index from the device descriptor. If the device doesn't provide one,
+// SerialNumber returns an empty string and a nil error.
 // GetStringDescriptor's string conversion rules apply.
 func (d *Device) SerialNumber() (string, error) {
 	return d.GetStringDescriptor(d.Desc.iSerialNumber)
@@ -276,14 +578,28 @@ func (d *Device) InterfaceDescription(cfgNum, intfNum, altNum int) (string, erro
 // When autodetach is enabled gousb will automatically detach the kernel driver
 // on the interface and reattach it when releasing the interface.
 // Automatic kernel driver detachment is disabled on newly opened device handles by default.
+// To detach only the drivers of the claimed interfaces, use the
+// DetachKernelDriver option of Config.Interface instead.
+//
+// Enabling it on platforms where libusb can't detach kernel drivers, e.g.
+// macOS, Windows, OpenBSD or NetBSD, fails with an *UnsupportedError, see
+// also Context.HasCapability. Disabling it always succeeds on these
+// platforms.
 func (d *Device) SetAutoDetach(autodetach bool) error {
 	if d.handle == nil {
 		return fmt.Errorf("SetAutoDetach(%v) called on %s after Close", autodetach, d)
 	}
-	d.autodetach = autodetach
 	var autodetachInt int
 	if autodetach {
 		autodetachInt = 1
 	}
-	return d.ctx.libusb.setAutoDetach(d.handle, autodetachInt)
+	err := d.ctx.libusb.setAutoDetach(d.handle, autodetachInt)
+	switch {
+	case err == ErrorNotSupported && autodetach:
+		return newUnsupportedError("detaching kernel drivers", CapabilityDetachKernelDriver)
+	case err != nil && err != ErrorNotSupported:
+		return err
+	}
+	d.autodetach = autodetach
+	return nil
 }
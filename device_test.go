@@ -15,9 +15,12 @@
 package gousb
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestClaimAndRelease(t *testing.T) {
@@ -147,8 +150,9 @@ func TestClaimAndRelease(t *testing.T) {
 		t.Fatalf("%s.Close(): got nil, want non nil, because the Config was not released.", dev)
 	}
 
-	if err := dev.Reset(); err == nil {
-		t.Fatalf("%s.Reset(): got nil, want non nil, because Device is still has an active Config.", dev)
+	// The active config is restored after the reset.
+	if err := dev.Reset(); err != nil {
+		t.Fatalf("%s.Reset() with an active config: got error %v, want nil", dev, err)
 	}
 
 	if err := cfg.Close(); err != nil {
@@ -228,6 +232,145 @@ func TestAutoDetachFailure(t *testing.T) {
 	}
 }
 
+func TestInterfaceDetachKernelDriver(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x8888, 0x0002): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+
+	detached := func() map[uint8]bool {
+		lib.mu.Lock()
+		defer lib.mu.Unlock()
+		ret := make(map[uint8]bool)
+		for k, v := range lib.detached {
+			ret[k] = v
+		}
+		return ret
+	}
+	intf, err := cfg.Interface(1, 0, DetachKernelDriver())
+	if err != nil {
+		t.Fatalf("%s.Interface(1, 0, DetachKernelDriver()): %v", cfg, err)
+	}
+	if got, want := detached(), map[uint8]bool{1: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("detached interfaces after Interface(1, 0, DetachKernelDriver()): got %v, want %v", got, want)
+	}
+	other, err := cfg.Interface(0, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(0, 0): %v", cfg, err)
+	}
+	other.Close()
+	intf.Close()
+	if got := detached(); len(got) != 0 {
+		t.Errorf("detached interfaces after Close: got %v, want none", got)
+	}
+
+	lib.claimErr = ErrorBusy
+	if _, err := cfg.Interface(1, 0, DetachKernelDriver()); err == nil {
+		t.Fatalf("%s.Interface(1, 0, DetachKernelDriver()) with a failing claim: got nil error, want non-nil", cfg)
+	}
+	if got := detached(); len(got) != 0 {
+		t.Errorf("detached interfaces after a failed claim: got %v, want none", got)
+	}
+}
+
+func TestInterfaceRetryBusy(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+
+	for _, tc := range []struct {
+		busy, retries int
+		wantErr       bool
+		wantCalls     int
+	}{
+		{busy: 1, retries: 0, wantErr: true, wantCalls: 1},
+		{busy: 2, retries: 3, wantCalls: 3},
+		{busy: 4, retries: 3, wantErr: true, wantCalls: 4},
+	} {
+		lib.mu.Lock()
+		lib.busyClaims, lib.claimCalls = tc.busy, 0
+		lib.mu.Unlock()
+		intf, err := cfg.Interface(0, 0, RetryBusy(tc.retries, time.Millisecond))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Interface(0, 0, RetryBusy(%d)) with %d busy claims: got error %v, want error: %v", tc.retries, tc.busy, err, tc.wantErr)
+		}
+		if err == nil {
+			intf.Close()
+		}
+		lib.mu.Lock()
+		calls := lib.claimCalls
+		lib.busyClaims = 0
+		lib.mu.Unlock()
+		if calls != tc.wantCalls {
+			t.Errorf("Interface(0, 0, RetryBusy(%d)) with %d busy claims: got %d claim attempts, want %d", tc.retries, tc.busy, calls, tc.wantCalls)
+		}
+	}
+}
+
+func TestSetAutoDetachUnsupported(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	lib.noDetach = true
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	if ctx.HasCapability(CapabilityDetachKernelDriver) {
+		t.Errorf("HasCapability(%s): got true, want false", CapabilityDetachKernelDriver)
+	}
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+
+	err = dev.SetAutoDetach(true)
+	ue, ok := err.(*UnsupportedError)
+	if !ok {
+		t.Fatalf("%s.SetAutoDetach(true): got error %v, want an *UnsupportedError", dev, err)
+	}
+	if ue.Capability != CapabilityDetachKernelDriver {
+		t.Errorf("UnsupportedError.Capability: got %s, want %s", ue.Capability, CapabilityDetachKernelDriver)
+	}
+	if !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("errors.Is(%v, ErrorNotSupported): got false, want true", err)
+	}
+	if dev.autodetach {
+		t.Errorf("%s.autodetach: got true after a failed SetAutoDetach(true), want false", dev)
+	}
+
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+	if _, err := cfg.Interface(0, 0, DetachKernelDriver()); !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("%s.Interface(0, 0, DetachKernelDriver()): got error %v, want an *UnsupportedError", cfg, err)
+	}
+	if err := dev.SetAutoDetach(false); err != nil {
+		t.Errorf("%s.SetAutoDetach(false): %v, want nil", dev, err)
+	}
+}
+
 func TestActiveConfigNumFailure(t *testing.T) {
 	dev, err := createFakeDevice(0x8888, 0x0002)
 	if err != nil {
@@ -268,3 +411,407 @@ func createFakeDevice(vid ID, pid ID) (*Device, error) {
 	}
 	return dev, nil
 }
+
+func TestTransferBuffer(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	buf, err := dev.AllocTransferBuffer(512)
+	if err != nil {
+		t.Fatalf("%s.AllocTransferBuffer(512): %v", dev, err)
+	}
+	if got, want := len(buf), 512; got != want {
+		t.Errorf("%s.AllocTransferBuffer(512): got %d bytes, want %d", dev, got, want)
+	}
+	if !dev.isTransferBuffer(buf[10:20]) {
+		t.Errorf("%s.isTransferBuffer(buf[10:20]): got false, want true", dev)
+	}
+	if dev.isTransferBuffer(make([]byte, 512)) {
+		t.Errorf("%s.isTransferBuffer(<Go buffer>): got true, want false", dev)
+	}
+
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		if &ft.buf[0] != &buf[0] {
+			ft.setStatus(TransferError)
+			return
+		}
+		ft.setData([]byte{1, 2, 3})
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := ep.Read(buf); err != nil {
+		t.Errorf("%s.Read(<transfer buffer>): got error %v, want nil (buffer was not used directly by the transfer?)", ep, err)
+	} else if n != 3 || !reflect.DeepEqual(buf[:n], []byte{1, 2, 3}) {
+		t.Errorf("%s.Read(<transfer buffer>): got %v, want [1 2 3]", ep, buf[:n])
+	}
+
+	if err := dev.FreeTransferBuffer(make([]byte, 10)); err == nil {
+		t.Errorf("%s.FreeTransferBuffer(<Go buffer>): got nil, want an error", dev)
+	}
+	if err := dev.FreeTransferBuffer(buf); err != nil {
+		t.Errorf("%s.FreeTransferBuffer(): %v", dev, err)
+	}
+	if dev.isTransferBuffer(buf) {
+		t.Errorf("%s.isTransferBuffer(<released buffer>): got true, want false", dev)
+	}
+}
+
+func TestDeviceDescJSON(t *testing.T) {
+	for _, d := range fakeDevices {
+		b, err := json.Marshal(d.devDesc)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s): %v", d.devDesc, err)
+		}
+		var got DeviceDesc
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", b, err)
+		}
+		if !reflect.DeepEqual(&got, d.devDesc) {
+			t.Errorf("JSON round trip of %s:\ngot  %+v\nwant %+v", d.devDesc, &got, d.devDesc)
+		}
+	}
+
+	b, err := json.Marshal(fakeDevices[0].devDesc)
+	if err != nil {
+		t.Fatalf("json.Marshal(%s): %v", fakeDevices[0].devDesc, err)
+	}
+	for _, want := range []string{`"Vendor":"9999"`, `"Spec":"2.00"`, `"0x82":{`, `"iManufacturer":`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("json.Marshal(%s): %s does not contain %s", fakeDevices[0].devDesc, b, want)
+		}
+	}
+}
+
+func TestDeviceFingerprint(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	for _, tc := range []struct {
+		vid, pid ID
+		want     string
+	}{
+		{0x8888, 0x0002, "8888:0002:serial=01234567"},
+		{0x9999, 0x0001, "9999:0001@1-1"},
+	} {
+		dev, err := ctx.OpenDeviceWithVIDPID(tc.vid, tc.pid)
+		if err != nil {
+			t.Fatalf("OpenDeviceWithVIDPID(%s, %s): %v", tc.vid, tc.pid, err)
+		}
+		got, err := dev.Fingerprint()
+		if err != nil {
+			t.Errorf("%s.Fingerprint(): %v", dev, err)
+		} else if got != tc.want {
+			t.Errorf("%s.Fingerprint(): got %q, want %q", dev, got, tc.want)
+		}
+		dev.Close()
+	}
+
+	desc := &DeviceDesc{Bus: 3, Path: []int{2, 4, 1}}
+	if got, want := desc.portPath(), "3-2.4.1"; got != want {
+		t.Errorf("portPath(): got %q, want %q", got, want)
+	}
+}
+
+func TestResetReenumerated(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close: %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	intf, _, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+
+	lib.resetErr = ErrorNotFound
+	if err := dev.Reset(); err != ErrDeviceReenumerated {
+		t.Fatalf("%s.Reset(): got error %v, want %v", dev, err, ErrDeviceReenumerated)
+	}
+	if _, err := intf.InEndpoint(2); err == nil {
+		t.Errorf("%s.InEndpoint(2) after re-enumeration: got nil error, want non-nil", intf)
+	}
+	// The interface and the config were released, the device can be closed.
+	if err := dev.Close(); err != nil {
+		t.Errorf("%s.Close() after re-enumeration: %v", dev, err)
+	}
+}
+
+func TestDeviceDisconnected(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	gone := dev.Done()
+	for _, st := range []TransferStatus{TransferError, TransferNoDevice} {
+		go func(st TransferStatus) {
+			lib.waitForSubmitted(nil).setStatus(st)
+		}(st)
+		if _, err := ep.Read(make([]byte, 512)); err != st {
+			t.Fatalf("%s.Read(): got error %v, want %v", ep, err, st)
+		}
+		if st == TransferNoDevice {
+			break
+		}
+		if dev.Disconnected() {
+			t.Errorf("%s.Disconnected() after %v: got true, want false", dev, st)
+		}
+	}
+	select {
+	case <-gone:
+	default:
+		t.Fatalf("%s.Done() channel not closed after a transfer failed with %v", dev, TransferNoDevice)
+	}
+	if !dev.Disconnected() {
+		t.Errorf("%s.Disconnected(): got false, want true", dev)
+	}
+}
+
+func TestDefaultInterfaceUnconfigured(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	lib.unconfigured = true
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface() on an unconfigured device: %v", dev, err)
+	}
+	if got := intf.config.Desc.Number; got != 1 {
+		t.Errorf("%s.DefaultInterface(): got config %d, want 1", dev, got)
+	}
+	done()
+}
+
+func TestMissingStringDescriptors(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+	for name, get := range map[string]func() (string, error){
+		"Manufacturer": dev.Manufacturer,
+		"Product":      dev.Product,
+		"SerialNumber": dev.SerialNumber,
+	} {
+		if s, err := get(); err != nil || s != "" {
+			t.Errorf("%s.%s(): got %q, %v, want empty string and nil error", dev, name, s, err)
+		}
+	}
+}
+
+func TestStringDescriptorCache(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x8888, 0x0002): %v", err)
+	}
+	defer dev.Close()
+	reads := func() int {
+		lib.mu.Lock()
+		defer lib.mu.Unlock()
+		return lib.stringReads
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := dev.Manufacturer(); err != nil {
+			t.Fatalf("%s.Manufacturer(): %v", dev, err)
+		}
+	}
+	if got := reads(); got != 1 {
+		t.Errorf("string descriptor reads after 3 calls to Manufacturer(): got %d, want 1", got)
+	}
+	if err := dev.Reset(); err != nil {
+		t.Fatalf("%s.Reset(): %v", dev, err)
+	}
+	if _, err := dev.Manufacturer(); err != nil {
+		t.Fatalf("%s.Manufacturer() after Reset: %v", dev, err)
+	}
+	if got := reads(); got != 2 {
+		t.Errorf("string descriptor reads after Reset: got %d, want 2", got)
+	}
+}
+
+func TestOpenAgain(t *testing.T) {
+	t.Parallel()
+	c := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := c.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	dev, err := c.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x8888, 0x0002): %v", err)
+	}
+	defer dev.Close()
+	dev2, err := dev.OpenAgain()
+	if err != nil {
+		t.Fatalf("%s.OpenAgain(): %v", dev, err)
+	}
+
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+	intf, err := cfg.Interface(1, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(1, 0): %v", cfg, err)
+	}
+	defer intf.Close()
+
+	cfg2, err := dev2.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1) through the second handle: %v", dev2, err)
+	}
+	if _, err := cfg2.Interface(1, 0); err == nil {
+		t.Errorf("%s.Interface(1, 0): got nil error, want non-nil, because interface 1 is claimed through the first handle", cfg2)
+	}
+	intf2, err := cfg2.Interface(0, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(0, 0): %v", cfg2, err)
+	}
+	if _, err := cfg.Interface(0, 0); err == nil {
+		t.Errorf("%s.Interface(0, 0): got nil error, want non-nil, because interface 0 is claimed through the second handle", cfg)
+	}
+
+	// Closing the second handle doesn't affect the first one.
+	intf2.Close()
+	if err := cfg2.Close(); err != nil {
+		t.Errorf("%s.Close(): %v", cfg2, err)
+	}
+	if err := dev2.Close(); err != nil {
+		t.Errorf("%s.Close(): %v", dev2, err)
+	}
+	if _, err := dev.Manufacturer(); err != nil {
+		t.Errorf("%s.Manufacturer() after closing the second handle: %v", dev, err)
+	}
+	intf0, err := cfg.Interface(0, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(0, 0) after the second handle released it: %v", cfg, err)
+	}
+	intf0.Close()
+}
+
+func TestInterfaceRetryBusyUnlocked(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+
+	lib.mu.Lock()
+	lib.busyClaims = 1
+	lib.mu.Unlock()
+	type result struct {
+		intf *Interface
+		err  error
+	}
+	claimed := make(chan result)
+	go func() {
+		intf, err := cfg.Interface(0, 0, RetryBusy(1, time.Second))
+		claimed <- result{intf, err}
+	}()
+
+	// Wait for the busy claim, the retry follows a second later.
+	for {
+		lib.mu.Lock()
+		calls := lib.claimCalls
+		lib.mu.Unlock()
+		if calls > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := cfg.Interface(0, 0); err == nil {
+			t.Errorf("Interface(0, 0) while another claim of the interface waits: got nil error, want already claimed")
+		}
+		if err := cfg.Close(); err == nil {
+			t.Errorf("%s.Close() while a claim waits: got nil error, want interfaces still open", cfg)
+		}
+	}()
+	select {
+	case <-done:
+	case r := <-claimed:
+		t.Fatalf("%s was locked until Interface(0, 0, RetryBusy(1, time.Second)) returned %v, %v", cfg, r.intf, r.err)
+	}
+	r := <-claimed
+	if r.err != nil {
+		t.Fatalf("Interface(0, 0, RetryBusy(1, time.Second)): %v", r.err)
+	}
+	r.intf.Close()
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DeviceRef is a reference to an enumerated device that is not open.
+// It holds the device descriptor and can be opened later, e.g. after
+// the user picked one of the devices on the bus. A DeviceRef needs to
+// be released after use, whether it was opened or not.
+type DeviceRef struct {
+	// Desc is the descriptor of the device.
+	Desc *DeviceDesc
+
+	ctx *Context
+	mu  sync.Mutex
+	dev *libusbDevice
+}
+
+// String returns a human-readable description of the referenced device.
+func (r *DeviceRef) String() string {
+	return fmt.Sprintf("vid=%s,pid=%s,bus=%d,addr=%d", r.Desc.Vendor, r.Desc.Product, r.Desc.Bus, r.Desc.Address)
+}
+
+// ListDevices calls filter with each enumerated device and returns
+// references to the devices for which filter returned true, without
+// opening them. A nil filter matches all devices. Every returned
+// DeviceRef must be released.
+// If there are any errors enumerating the devices, the final one is
+// returned along with the references to the matching devices.
+func (c *Context) ListDevices(filter func(desc *DeviceDesc) bool) ([]*DeviceRef, error) {
+	if c.ctx == nil {
+		return nil, errors.New("ListDevices called on a closed or uninitialized Context")
+	}
+	list, err := c.libusb.getDevices(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reterr error
+	var ret []*DeviceRef
+	for _, dev := range list {
+		desc, err := c.libusb.getDeviceDesc(dev)
+		if err != nil {
+			c.libusb.dereference(dev)
+			reterr = err
+			continue
+		}
+		if filter != nil && !filter(desc) {
+			c.libusb.dereference(dev)
+			continue
+		}
+		r := &DeviceRef{Desc: desc, ctx: c, dev: dev}
+		c.mu.Lock()
+		c.refs[r] = true
+		c.mu.Unlock()
+		ret = append(ret, r)
+	}
+	return ret, reterr
+}
+
+// Open opens the referenced device. The DeviceRef remains valid and
+// still needs to be released. Open fails if the device was disconnected
+// after enumeration.
+func (r *DeviceRef) Open() (*Device, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dev == nil {
+		return nil, fmt.Errorf("Open() called on %s after Release", r)
+	}
+	return r.ctx.openDevice(r.dev, r.Desc)
+}
+
+// Release releases the reference to the device. Devices opened through
+// the DeviceRef are not affected. Releasing a DeviceRef more than once
+// has no effect.
+func (r *DeviceRef) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dev == nil {
+		return
+	}
+	r.ctx.libusb.dereference(r.dev)
+	r.dev = nil
+	r.ctx.mu.Lock()
+	delete(r.ctx.refs, r)
+	r.ctx.mu.Unlock()
+}
+
+// releaseRefs releases the device references still held by the Context.
+func (c *Context) releaseRefs() {
+	c.mu.Lock()
+	var refs []*DeviceRef
+	for r := range c.refs {
+		refs = append(refs, r)
+	}
+	c.mu.Unlock()
+	for _, r := range refs {
+		r.Release()
+	}
+}
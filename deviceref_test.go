@@ -0,0 +1,77 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "testing"
+
+func TestListDevices(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	all, err := ctx.ListDevices(nil)
+	if err != nil {
+		t.Fatalf("ListDevices(nil): %v", err)
+	}
+	if got, want := len(all), len(fakeDevices); got != want {
+		t.Errorf("ListDevices(nil): got %d devices, want %d", got, want)
+	}
+	for _, r := range all {
+		r.Release()
+	}
+
+	refs, err := ctx.ListDevices(func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x8888
+	})
+	if err != nil {
+		t.Fatalf("ListDevices(8888:*): %v", err)
+	}
+	if len(refs) != 1 {
+		t.Fatalf("ListDevices(8888:*): got %d devices, want 1", len(refs))
+	}
+	ctx.mu.Lock()
+	if n := len(ctx.devices); n != 0 {
+		t.Errorf("ListDevices(8888:*): %d devices open, want none", n)
+	}
+	ctx.mu.Unlock()
+
+	r := refs[0]
+	dev, err := r.Open()
+	if err != nil {
+		t.Fatalf("%s.Open(): %v", r, err)
+	}
+	if dev.Desc != r.Desc {
+		t.Errorf("%s.Open(): got device %s, want the referenced device", r, dev)
+	}
+	// Releasing the reference doesn't affect the opened device.
+	r.Release()
+	r.Release()
+	if _, err := dev.Manufacturer(); err != nil {
+		t.Errorf("%s.Manufacturer() after releasing the DeviceRef: %v", dev, err)
+	}
+	dev.Close()
+	if _, err := r.Open(); err == nil {
+		t.Errorf("%s.Open() after Release: got nil error, want non-nil", r)
+	}
+
+	// Unreleased references are released by Context.Close.
+	if _, err := ctx.ListDevices(nil); err != nil {
+		t.Fatalf("ListDevices(nil): %v", err)
+	}
+}
@@ -0,0 +1,480 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dfu implements the USB Device Firmware Upgrade (DFU) protocol,
+// version 1.1, and the DfuSe extensions used by STMicroelectronics
+// microcontrollers.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x0483, 0xdf11)
+//	intf, done, err := dev.DefaultInterface()
+//	if err != nil { ... }
+//	defer done()
+//	fd, err := dfu.ReadFunctionalDesc(dev, 0, intf.Setting.Number)
+//	if err != nil { ... }
+//	d := dfu.New(dev, intf.Setting.Number, fd)
+//	if err := d.Download(firmware); err != nil { ... }
+package dfu
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// Interface class codes of DFU interfaces.
+const (
+	SubClass        = gousb.SubClassDFU
+	ProtocolRuntime = gousb.ProtocolDFURuntime
+	ProtocolDFU     = gousb.ProtocolDFUMode
+)
+
+// DFU class requests.
+const (
+	requestDetach    = 0x00
+	requestDnload    = 0x01
+	requestUpload    = 0x02
+	requestGetStatus = 0x03
+	requestClrStatus = 0x04
+	requestGetState  = 0x05
+	requestAbort     = 0x06
+
+	// descTypeFunctional is the type of the DFU functional descriptor.
+	descTypeFunctional = 0x21
+)
+
+// Controller is the subset of *gousb.Device used to talk to a DFU
+// interface.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// IsDFU reports whether the interface setting is a DFU interface, in
+// either runtime or DFU mode.
+func IsDFU(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassApplication && s.SubClass == SubClass &&
+		(s.Protocol == ProtocolRuntime || s.Protocol == ProtocolDFU)
+}
+
+// State is the state of the DFU state machine of a device.
+type State uint8
+
+// DFU states.
+const (
+	StateAppIdle           State = 0
+	StateAppDetach         State = 1
+	StateIdle              State = 2
+	StateDnloadSync        State = 3
+	StateDnBusy            State = 4
+	StateDnloadIdle        State = 5
+	StateManifestSync      State = 6
+	StateManifest          State = 7
+	StateManifestWaitReset State = 8
+	StateUploadIdle        State = 9
+	StateError             State = 10
+)
+
+var stateNames = map[State]string{
+	StateAppIdle:           "appIDLE",
+	StateAppDetach:         "appDETACH",
+	StateIdle:              "dfuIDLE",
+	StateDnloadSync:        "dfuDNLOAD-SYNC",
+	StateDnBusy:            "dfuDNBUSY",
+	StateDnloadIdle:        "dfuDNLOAD-IDLE",
+	StateManifestSync:      "dfuMANIFEST-SYNC",
+	StateManifest:          "dfuMANIFEST",
+	StateManifestWaitReset: "dfuMANIFEST-WAIT-RESET",
+	StateUploadIdle:        "dfuUPLOAD-IDLE",
+	StateError:             "dfuERROR",
+}
+
+func (s State) String() string {
+	if n, ok := stateNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("unknown state %d", uint8(s))
+}
+
+// Status is the result of the last operation of a device.
+type Status uint8
+
+// DFU status codes.
+const (
+	StatusOK             Status = 0x00
+	StatusErrTarget      Status = 0x01
+	StatusErrFile        Status = 0x02
+	StatusErrWrite       Status = 0x03
+	StatusErrErase       Status = 0x04
+	StatusErrCheckErased Status = 0x05
+	StatusErrProg        Status = 0x06
+	StatusErrVerify      Status = 0x07
+	StatusErrAddress     Status = 0x08
+	StatusErrNotDone     Status = 0x09
+	StatusErrFirmware    Status = 0x0a
+	StatusErrVendor      Status = 0x0b
+	StatusErrUSBReset    Status = 0x0c
+	StatusErrPOR         Status = 0x0d
+	StatusErrUnknown     Status = 0x0e
+	StatusErrStalledPkt  Status = 0x0f
+)
+
+var statusNames = map[Status]string{
+	StatusOK:             "no error",
+	StatusErrTarget:      "file is not targeted for this device",
+	StatusErrFile:        "file fails a vendor-specific verification test",
+	StatusErrWrite:       "unable to write memory",
+	StatusErrErase:       "memory erase failed",
+	StatusErrCheckErased: "memory erase check failed",
+	StatusErrProg:        "program memory function failed",
+	StatusErrVerify:      "programmed memory failed verification",
+	StatusErrAddress:     "address out of range",
+	StatusErrNotDone:     "received a zero length download, but the firmware is incomplete",
+	StatusErrFirmware:    "firmware is corrupt",
+	StatusErrVendor:      "vendor-specific error",
+	StatusErrUSBReset:    "unexpected USB reset",
+	StatusErrPOR:         "unexpected power on reset",
+	StatusErrUnknown:     "unknown error",
+	StatusErrStalledPkt:  "unexpected request",
+}
+
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("unknown status %d", uint8(s))
+}
+
+// DeviceStatus is the response to a DFU_GETSTATUS request.
+type DeviceStatus struct {
+	Status Status
+	// PollTimeout is the time the host needs to wait before the next
+	// DFU_GETSTATUS request.
+	PollTimeout time.Duration
+	State       State
+	// StringIndex is the index of a string descriptor describing the
+	// status.
+	StringIndex int
+}
+
+// StatusError is returned when a device reports a status other than
+// StatusOK.
+type StatusError struct {
+	Status Status
+	State  State
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("dfu: %s (state %s)", e.Status, e.State)
+}
+
+// FunctionalDesc is the DFU functional descriptor of an interface.
+type FunctionalDesc struct {
+	CanDownload bool
+	CanUpload   bool
+	// ManifestationTolerant is true if the device can communicate after
+	// the manifestation phase, otherwise it needs a reset.
+	ManifestationTolerant bool
+	// WillDetach is true if the device detaches by itself after a
+	// DFU_DETACH request, otherwise the host needs to reset it.
+	WillDetach bool
+	// DetachTimeout is the time the device waits for a reset after a
+	// DFU_DETACH request.
+	DetachTimeout time.Duration
+	// TransferSize is the maximum length of a download or upload block.
+	TransferSize int
+	// Version is the DFU version of the device. Version 1.1a (0x011a)
+	// indicates the DfuSe extensions.
+	Version gousb.BCD
+}
+
+// DfuSe reports whether the device uses the DfuSe extensions.
+func (fd FunctionalDesc) DfuSe() bool {
+	return fd.Version == 0x011a
+}
+
+// ReadFunctionalDesc reads the functional descriptor of the DFU interface
+// intf from the configuration descriptor with index cfgIndex, which is 0
+// for devices with a single configuration.
+func ReadFunctionalDesc(c Controller, cfgIndex, intf int) (FunctionalDesc, error) {
+	buf := make([]byte, 4096)
+	n, err := c.Control(gousb.ControlIn|gousb.ControlDevice, uint8(gousb.RequestGetDescriptor), uint16(gousb.DescriptorTypeConfig)<<8|uint16(cfgIndex), 0, buf)
+	if err != nil {
+		return FunctionalDesc{}, fmt.Errorf("failed to read configuration descriptor %d: %v", cfgIndex, err)
+	}
+	return parseFunctionalDesc(buf[:n], intf)
+}
+
+// parseFunctionalDesc finds the functional descriptor of the DFU interface
+// intf in the raw configuration descriptor b.
+func parseFunctionalDesc(b []byte, intf int) (FunctionalDesc, error) {
+	inIntf := false
+	for off := 0; off+2 <= len(b); {
+		l := int(b[off])
+		if l < 2 || off+l > len(b) {
+			break
+		}
+		d := b[off : off+l]
+		off += l
+		switch d[1] {
+		case byte(gousb.DescriptorTypeInterface):
+			inIntf = l >= 9 && int(d[2]) == intf && gousb.Class(d[5]) == gousb.ClassApplication && gousb.Class(d[6]) == SubClass
+		case descTypeFunctional:
+			if !inIntf {
+				continue
+			}
+			if l < 7 {
+				return FunctionalDesc{}, fmt.Errorf("DFU functional descriptor too short: % x", d)
+			}
+			fd := FunctionalDesc{
+				CanDownload:           d[2]&0x01 != 0,
+				CanUpload:             d[2]&0x02 != 0,
+				ManifestationTolerant: d[2]&0x04 != 0,
+				WillDetach:            d[2]&0x08 != 0,
+				DetachTimeout:         time.Duration(uint16(d[3])|uint16(d[4])<<8) * time.Millisecond,
+				TransferSize:          int(d[5]) | int(d[6])<<8,
+				Version:               gousb.Version(1, 0),
+			}
+			if l >= 9 {
+				fd.Version = gousb.BCD(uint16(d[7]) | uint16(d[8])<<8)
+			}
+			return fd, nil
+		}
+	}
+	return FunctionalDesc{}, fmt.Errorf("no DFU functional descriptor for interface %d", intf)
+}
+
+// Device is a DFU interface of a device.
+type Device struct {
+	c    Controller
+	intf uint16
+	// Func is the functional descriptor of the interface.
+	Func FunctionalDesc
+}
+
+// New returns a Device for the DFU interface intf, which needs to be
+// claimed, with the functional descriptor fd.
+func New(c Controller, intf int, fd FunctionalDesc) *Device {
+	return &Device{c: c, intf: uint16(intf), Func: fd}
+}
+
+func (d *Device) out(request uint8, val uint16, data []byte) error {
+	_, err := d.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, request, val, d.intf, data)
+	return err
+}
+
+func (d *Device) in(request uint8, val uint16, data []byte) (int, error) {
+	return d.c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlInterface, request, val, d.intf, data)
+}
+
+// Detach asks a device in runtime mode to switch to DFU mode. Unless
+// Func.WillDetach is set, the device switches only after it's reset
+// within Func.DetachTimeout, e.g. with gousb.Device.Reset.
+func (d *Device) Detach() error {
+	timeout := d.Func.DetachTimeout / time.Millisecond
+	if timeout <= 0 || timeout > 0xffff {
+		timeout = 0xffff
+	}
+	if err := d.out(requestDetach, uint16(timeout), nil); err != nil {
+		return fmt.Errorf("DFU_DETACH: %v", err)
+	}
+	return nil
+}
+
+// GetStatus returns the status of the device. It also advances the state
+// machine, e.g. from dfuDNLOAD-SYNC to dfuDNBUSY.
+func (d *Device) GetStatus() (DeviceStatus, error) {
+	buf := make([]byte, 6)
+	n, err := d.in(requestGetStatus, 0, buf)
+	if err != nil {
+		return DeviceStatus{}, fmt.Errorf("DFU_GETSTATUS: %v", err)
+	}
+	if n < 6 {
+		return DeviceStatus{}, fmt.Errorf("DFU_GETSTATUS: short response % x", buf[:n])
+	}
+	return DeviceStatus{
+		Status:      Status(buf[0]),
+		PollTimeout: time.Duration(uint32(buf[1])|uint32(buf[2])<<8|uint32(buf[3])<<16) * time.Millisecond,
+		State:       State(buf[4]),
+		StringIndex: int(buf[5]),
+	}, nil
+}
+
+// GetState returns the state of the device, without changing it.
+func (d *Device) GetState() (State, error) {
+	buf := make([]byte, 1)
+	n, err := d.in(requestGetState, 0, buf)
+	if err != nil {
+		return 0, fmt.Errorf("DFU_GETSTATE: %v", err)
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("DFU_GETSTATE: empty response")
+	}
+	return State(buf[0]), nil
+}
+
+// ClearStatus clears an error status, moving the device from dfuERROR to
+// dfuIDLE.
+func (d *Device) ClearStatus() error {
+	if err := d.out(requestClrStatus, 0, nil); err != nil {
+		return fmt.Errorf("DFU_CLRSTATUS: %v", err)
+	}
+	return nil
+}
+
+// Abort returns the device to dfuIDLE, ending a download or upload.
+func (d *Device) Abort() error {
+	if err := d.out(requestAbort, 0, nil); err != nil {
+		return fmt.Errorf("DFU_ABORT: %v", err)
+	}
+	return nil
+}
+
+// Idle brings a device in DFU mode to the dfuIDLE state, clearing a
+// pending error or aborting an unfinished transfer.
+func (d *Device) Idle() error {
+	st, err := d.GetStatus()
+	if err != nil {
+		return err
+	}
+	switch st.State {
+	case StateIdle:
+		return nil
+	case StateError:
+		if err := d.ClearStatus(); err != nil {
+			return err
+		}
+	case StateAppIdle, StateAppDetach:
+		return fmt.Errorf("dfu: device is in runtime mode (state %s), detach it first", st.State)
+	default:
+		if err := d.Abort(); err != nil {
+			return err
+		}
+	}
+	if st, err = d.GetStatus(); err != nil {
+		return err
+	}
+	if st.State != StateIdle {
+		return fmt.Errorf("dfu: device is in state %s, want %s", st.State, StateIdle)
+	}
+	return nil
+}
+
+// wait polls the status of the device until it finishes processing the
+// last request.
+func (d *Device) wait() (DeviceStatus, error) {
+	for {
+		st, err := d.GetStatus()
+		if err != nil {
+			return st, err
+		}
+		if st.Status != StatusOK {
+			return st, &StatusError{Status: st.Status, State: st.State}
+		}
+		switch st.State {
+		case StateDnBusy, StateDnloadSync, StateManifestSync:
+		case StateManifest:
+			if !d.Func.ManifestationTolerant {
+				// The device won't respond until it's reset.
+				return st, nil
+			}
+		default:
+			return st, nil
+		}
+		time.Sleep(st.PollTimeout)
+	}
+}
+
+// dnload sends a block of a download and waits until the device
+// processed it.
+func (d *Device) dnload(block uint16, data []byte) (DeviceStatus, error) {
+	if err := d.out(requestDnload, block, data); err != nil {
+		return DeviceStatus{}, fmt.Errorf("DFU_DNLOAD of block %d: %v", block, err)
+	}
+	return d.wait()
+}
+
+func (d *Device) transferSize() int {
+	if d.Func.TransferSize > 0 {
+		return d.Func.TransferSize
+	}
+	return 1024
+}
+
+// Download writes firmware to the device, which needs to be in dfuIDLE,
+// and starts its manifestation. Devices that aren't manifestation tolerant
+// need to be reset afterwards.
+func (d *Device) Download(firmware []byte) error {
+	size := d.transferSize()
+	block := uint16(0)
+	for off := 0; off < len(firmware); off += size {
+		end := off + size
+		if end > len(firmware) {
+			end = len(firmware)
+		}
+		if _, err := d.dnload(block, firmware[off:end]); err != nil {
+			return err
+		}
+		block++
+	}
+	return d.manifest(block)
+}
+
+// manifest ends a download with a zero length block.
+func (d *Device) manifest(block uint16) error {
+	st, err := d.dnload(block, nil)
+	if err != nil {
+		return err
+	}
+	if st.State == StateManifest || st.State == StateManifestWaitReset || st.State == StateIdle {
+		return nil
+	}
+	return fmt.Errorf("dfu: device is in state %s after the download", st.State)
+}
+
+// Upload reads the firmware of the device, which needs to be in dfuIDLE,
+// and writes it to w. It returns the number of bytes read.
+func (d *Device) Upload(w io.Writer) (int, error) {
+	return d.upload(w, 0, -1)
+}
+
+// upload reads blocks starting at block until the device sends a short
+// block or limit bytes were read. A negative limit reads everything.
+func (d *Device) upload(w io.Writer, block uint16, limit int) (int, error) {
+	size := d.transferSize()
+	buf := make([]byte, size)
+	total := 0
+	for limit < 0 || total < limit {
+		want := size
+		if limit >= 0 && limit-total < want {
+			want = limit - total
+		}
+		n, err := d.in(requestUpload, block, buf[:want])
+		if err != nil {
+			return total, fmt.Errorf("DFU_UPLOAD of block %d: %v", block, err)
+		}
+		if _, err := w.Write(buf[:n]); err != nil {
+			return total, err
+		}
+		total += n
+		block++
+		if n < want {
+			// A short block ends the upload, the device returns to dfuIDLE.
+			return total, nil
+		}
+	}
+	return total, d.Abort()
+}
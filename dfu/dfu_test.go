@@ -0,0 +1,335 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfu
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+const (
+	testIntf     = 0
+	testXferSize = 64
+	flashBase    = 0x08000000
+)
+
+// fakeDFU emulates the DFU state machine of a device. With dfuse set, it
+// implements the DfuSe commands on a flash memory at flashBase.
+type fakeDFU struct {
+	state    State
+	status   Status
+	tolerant bool
+	dfuse    bool
+
+	firmware []byte
+	pending  []byte
+	block    uint16
+	detached uint16
+
+	flash  []byte
+	erased []uint32
+	addr   uint32
+}
+
+func (f *fakeDFU) fail(s Status) {
+	f.state, f.status = StateError, s
+}
+
+func (f *fakeDFU) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if rType&0x60 != gousb.ControlClass || idx != testIntf {
+		return 0, errors.New("unsupported request")
+	}
+	switch request {
+	case requestDetach:
+		if f.state != StateAppIdle {
+			return 0, gousb.ErrorPipe
+		}
+		f.state, f.detached = StateAppDetach, val
+		return 0, nil
+	case requestGetStatus:
+		f.getStatus()
+		return copy(data, []byte{byte(f.status), 0, 0, 0, byte(f.state), 0}), nil
+	case requestGetState:
+		return copy(data, []byte{byte(f.state)}), nil
+	case requestClrStatus:
+		if f.state != StateError {
+			return 0, gousb.ErrorPipe
+		}
+		f.state, f.status = StateIdle, StatusOK
+		return 0, nil
+	case requestAbort:
+		f.state = StateIdle
+		return 0, nil
+	case requestDnload:
+		if f.state != StateIdle && f.state != StateDnloadIdle {
+			f.fail(StatusErrStalledPkt)
+			return 0, gousb.ErrorPipe
+		}
+		if len(data) == 0 && (!f.dfuse || val != 2) {
+			f.state = StateManifestSync
+			return 0, nil
+		}
+		f.block, f.pending = val, append([]byte(nil), data...)
+		f.state = StateDnloadSync
+		return len(data), nil
+	case requestUpload:
+		if f.state != StateIdle && f.state != StateUploadIdle {
+			f.fail(StatusErrStalledPkt)
+			return 0, gousb.ErrorPipe
+		}
+		src := f.firmware
+		off := int(val) * len(data)
+		if f.dfuse {
+			src = f.flash
+			off = int(f.addr-flashBase) + int(val-2)*len(data)
+		}
+		if off > len(src) {
+			off = len(src)
+		}
+		n := copy(data, src[off:])
+		f.state = StateUploadIdle
+		if n < len(data) {
+			f.state = StateIdle
+		}
+		return n, nil
+	}
+	return 0, gousb.ErrorPipe
+}
+
+// getStatus advances the state machine as a DFU_GETSTATUS request does.
+func (f *fakeDFU) getStatus() {
+	switch f.state {
+	case StateDnloadSync:
+		f.state = StateDnBusy
+	case StateDnBusy:
+		f.state = StateDnloadIdle
+		if !f.dfuse {
+			f.firmware = append(f.firmware, f.pending...)
+			return
+		}
+		f.execDfuse()
+	case StateManifestSync:
+		f.state = StateManifest
+	case StateManifest:
+		f.state = StateIdle
+		if !f.tolerant {
+			f.state = StateManifestWaitReset
+		}
+	}
+}
+
+func (f *fakeDFU) execDfuse() {
+	p := f.pending
+	if f.block != 0 {
+		off := int(f.addr - flashBase)
+		for i, b := range p {
+			if f.flash[off+i] != 0xff {
+				f.fail(StatusErrWrite)
+				return
+			}
+			f.flash[off+i] = b
+		}
+		return
+	}
+	if len(p) == 1 && p[0] == dfuseErase {
+		f.erased = append(f.erased, 0)
+		for i := range f.flash {
+			f.flash[i] = 0xff
+		}
+		return
+	}
+	if len(p) != 5 {
+		f.fail(StatusErrStalledPkt)
+		return
+	}
+	addr := uint32(p[1]) | uint32(p[2])<<8 | uint32(p[3])<<16 | uint32(p[4])<<24
+	if addr < flashBase || addr >= flashBase+uint32(len(f.flash)) {
+		f.fail(StatusErrAddress)
+		return
+	}
+	switch p[0] {
+	case dfuseSetAddress:
+		f.addr = addr
+	case dfuseErase:
+		f.erased = append(f.erased, addr)
+		page := (addr - flashBase) / 1024 * 1024
+		for i := page; i < page+1024; i++ {
+			f.flash[i] = 0xff
+		}
+	default:
+		f.fail(StatusErrStalledPkt)
+	}
+}
+
+func testFunc(tolerant bool) FunctionalDesc {
+	return FunctionalDesc{
+		CanDownload:           true,
+		CanUpload:             true,
+		ManifestationTolerant: tolerant,
+		TransferSize:          testXferSize,
+		Version:               gousb.Version(1, 1),
+	}
+}
+
+func TestParseFunctionalDesc(t *testing.T) {
+	cfg := []byte{
+		0x09, 0x02, 0x24, 0x00, 0x01, 0x01, 0x00, 0x80, 0x32,
+		// A vendor interface with a descriptor of the same type.
+		0x09, 0x04, 0x01, 0x00, 0x00, 0xff, 0x01, 0x02, 0x00,
+		0x03, 0x21, 0x00,
+		// DFU interface 0, alt 0 and its functional descriptor.
+		0x09, 0x04, 0x00, 0x00, 0x00, 0xfe, 0x01, 0x02, 0x00,
+		0x09, 0x21, 0x0b, 0xff, 0x00, 0x00, 0x08, 0x1a, 0x01,
+	}
+	got, err := parseFunctionalDesc(cfg, 0)
+	if err != nil {
+		t.Fatalf("parseFunctionalDesc(): %v", err)
+	}
+	want := FunctionalDesc{
+		CanDownload:   true,
+		CanUpload:     true,
+		WillDetach:    true,
+		DetachTimeout: 255 * time.Millisecond,
+		TransferSize:  2048,
+		Version:       0x011a,
+	}
+	if got != want {
+		t.Errorf("parseFunctionalDesc(): got %+v, want %+v", got, want)
+	}
+	if !got.DfuSe() {
+		t.Error("DfuSe(): got false, want true")
+	}
+	if _, err := parseFunctionalDesc(cfg, 1); err == nil {
+		t.Error("parseFunctionalDesc() of a non-DFU interface: got nil error, want an error")
+	}
+	if _, err := parseFunctionalDesc(cfg[:len(cfg)-3], 0); err == nil {
+		t.Error("parseFunctionalDesc() of a truncated descriptor: got nil error, want an error")
+	}
+}
+
+func TestIsDFU(t *testing.T) {
+	for _, tc := range []struct {
+		s    gousb.InterfaceSetting
+		want bool
+	}{
+		{gousb.InterfaceSetting{Class: gousb.ClassApplication, SubClass: SubClass, Protocol: ProtocolDFU}, true},
+		{gousb.InterfaceSetting{Class: gousb.ClassApplication, SubClass: SubClass, Protocol: ProtocolRuntime}, true},
+		{gousb.InterfaceSetting{Class: gousb.ClassApplication, SubClass: 0x02, Protocol: ProtocolDFU}, false},
+		{gousb.InterfaceSetting{Class: gousb.ClassVendorSpec, SubClass: SubClass, Protocol: ProtocolDFU}, false},
+	} {
+		if got := IsDFU(tc.s); got != tc.want {
+			t.Errorf("IsDFU(%+v): got %v, want %v", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestDownloadUpload(t *testing.T) {
+	fw := make([]byte, 3*testXferSize+10)
+	for i := range fw {
+		fw[i] = byte(i)
+	}
+	for _, tolerant := range []bool{true, false} {
+		f := &fakeDFU{state: StateIdle, tolerant: tolerant}
+		d := New(f, testIntf, testFunc(tolerant))
+		if err := d.Download(fw); err != nil {
+			t.Fatalf("Download(tolerant=%v): %v", tolerant, err)
+		}
+		if !bytes.Equal(f.firmware, fw) {
+			t.Errorf("Download(tolerant=%v): device got % x, want % x", tolerant, f.firmware, fw)
+		}
+		if !tolerant {
+			continue
+		}
+		if got, err := d.GetState(); err != nil || got != StateIdle {
+			t.Errorf("GetState() after the download: got (%s, %v), want (%s, nil)", got, err, StateIdle)
+		}
+		var buf bytes.Buffer
+		n, err := d.Upload(&buf)
+		if err != nil || n != len(fw) || !bytes.Equal(buf.Bytes(), fw) {
+			t.Errorf("Upload(): got (%d, %v), want (%d, nil) and the downloaded firmware", n, err, len(fw))
+		}
+	}
+}
+
+func TestIdle(t *testing.T) {
+	f := &fakeDFU{state: StateIdle}
+	d := New(f, testIntf, testFunc(true))
+	if _, err := d.dnload(0, make([]byte, testXferSize)); err != nil {
+		t.Fatalf("dnload(): %v", err)
+	}
+	if err := d.Idle(); err != nil || f.state != StateIdle {
+		t.Errorf("Idle() in %s: got (%s, %v), want (%s, nil)", StateDnloadIdle, f.state, err, StateIdle)
+	}
+	f.fail(StatusErrVendor)
+	if err := d.Idle(); err != nil || f.state != StateIdle || f.status != StatusOK {
+		t.Errorf("Idle() in %s: got (%s, %s, %v), want (%s, %s, nil)", StateError, f.state, f.status, err, StateIdle, StatusOK)
+	}
+	f.state = StateAppIdle
+	if err := d.Idle(); err == nil {
+		t.Errorf("Idle() in %s: got nil error, want an error", StateAppIdle)
+	}
+}
+
+func TestDownloadError(t *testing.T) {
+	f := &fakeDFU{state: StateUploadIdle}
+	d := New(f, testIntf, testFunc(true))
+	err := d.Download([]byte{1, 2, 3})
+	if err == nil {
+		t.Fatal("Download() in dfuUPLOAD-IDLE: got nil error, want an error")
+	}
+	if st, err := d.GetStatus(); err != nil || st.Status != StatusErrStalledPkt || st.State != StateError {
+		t.Errorf("GetStatus(): got (%+v, %v), want status %s in %s", st, err, StatusErrStalledPkt, StateError)
+	}
+
+	f = &fakeDFU{state: StateIdle, dfuse: true, flash: make([]byte, 1024)}
+	d = New(f, testIntf, testFunc(true))
+	err = d.SetAddress(0x1000)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Status != StatusErrAddress {
+		t.Errorf("SetAddress(0x1000): got error %v, want a StatusError with %s", err, StatusErrAddress)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	f := &fakeDFU{state: StateAppIdle}
+	fd := testFunc(true)
+	fd.DetachTimeout = time.Second
+	if err := New(f, testIntf, fd).Detach(); err != nil {
+		t.Fatalf("Detach(): %v", err)
+	}
+	if f.state != StateAppDetach || f.detached != 1000 {
+		t.Errorf("Detach(): got state %s with timeout %d, want %s with timeout 1000", f.state, f.detached, StateAppDetach)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	for _, tc := range []struct {
+		got, want string
+	}{
+		{StateDnBusy.String(), "dfuDNBUSY"},
+		{State(42).String(), "unknown state 42"},
+		{StatusErrAddress.String(), "address out of range"},
+		{Status(42).String(), "unknown status 42"},
+		{(&StatusError{StatusErrWrite, StateError}).Error(), "dfu: unable to write memory (state dfuERROR)"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfu
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DfuSe commands, sent as block 0 of a download.
+const (
+	dfuseSetAddress = 0x21
+	dfuseErase      = 0x41
+)
+
+// Sector is a sector of a DfuSe memory region.
+type Sector struct {
+	Addr      uint32
+	Size      uint32
+	Readable  bool
+	Erasable  bool
+	Writeable bool
+}
+
+// MemoryLayout is a DfuSe memory region, as described by the name of its
+// alternate setting.
+type MemoryLayout struct {
+	Name    string
+	Sectors []Sector
+}
+
+// ParseMemoryLayout parses a DfuSe memory layout string, for example
+// "@Internal Flash  /0x08000000/04*016Kg,01*064Kg,07*128Kg".
+func ParseMemoryLayout(s string) (*MemoryLayout, error) {
+	if !strings.HasPrefix(s, "@") {
+		return nil, fmt.Errorf("DfuSe memory layout %q does not start with @", s)
+	}
+	parts := strings.Split(s[1:], "/")
+	if len(parts) < 3 || len(parts)%2 != 1 {
+		return nil, fmt.Errorf("malformed DfuSe memory layout %q", s)
+	}
+	l := &MemoryLayout{Name: strings.TrimSpace(parts[0])}
+	for i := 1; i < len(parts); i += 2 {
+		addr, err := strconv.ParseUint(strings.TrimSpace(parts[i]), 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("malformed address %q in DfuSe memory layout %q: %v", parts[i], s, err)
+		}
+		for _, group := range strings.Split(parts[i+1], ",") {
+			sectors, err := parseSectors(uint32(addr), strings.TrimSpace(group))
+			if err != nil {
+				return nil, fmt.Errorf("malformed sectors %q in DfuSe memory layout %q: %v", group, s, err)
+			}
+			l.Sectors = append(l.Sectors, sectors...)
+			last := sectors[len(sectors)-1]
+			addr = uint64(last.Addr) + uint64(last.Size)
+		}
+	}
+	return l, nil
+}
+
+// parseSectors parses a group of sectors like "04*016Kg" starting at addr.
+func parseSectors(addr uint32, s string) ([]Sector, error) {
+	star := strings.Index(s, "*")
+	if star < 0 || len(s) < star+3 {
+		return nil, fmt.Errorf("want <count>*<size><unit><type>")
+	}
+	count, err := strconv.Atoi(s[:star])
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid sector count %q", s[:star])
+	}
+	rest := s[star+1:]
+	typ := rest[len(rest)-1]
+	if typ < 'a' || typ > 'g' {
+		return nil, fmt.Errorf("invalid sector type %q", typ)
+	}
+	rest = rest[:len(rest)-1]
+	mult := uint64(1)
+	if n := len(rest); n > 0 {
+		switch rest[n-1] {
+		case 'K':
+			mult, rest = 1024, rest[:n-1]
+		case 'M':
+			mult, rest = 1024*1024, rest[:n-1]
+		case 'B', ' ':
+			rest = rest[:n-1]
+		}
+	}
+	size, err := strconv.ParseUint(rest, 10, 32)
+	if err != nil || size == 0 {
+		return nil, fmt.Errorf("invalid sector size %q", rest)
+	}
+	size *= mult
+	if uint64(addr)+size*uint64(count) > 1<<32 {
+		return nil, fmt.Errorf("sectors extend beyond the 32-bit address space")
+	}
+	bits := typ - 'a' + 1
+	sectors := make([]Sector, count)
+	for i := range sectors {
+		sectors[i] = Sector{
+			Addr:      addr + uint32(uint64(i)*size),
+			Size:      uint32(size),
+			Readable:  bits&0x1 != 0,
+			Erasable:  bits&0x2 != 0,
+			Writeable: bits&0x4 != 0,
+		}
+	}
+	return sectors, nil
+}
+
+// Find returns the sector containing addr.
+func (l *MemoryLayout) Find(addr uint32) (Sector, bool) {
+	for _, s := range l.Sectors {
+		if addr >= s.Addr && uint64(addr) < uint64(s.Addr)+uint64(s.Size) {
+			return s, true
+		}
+	}
+	return Sector{}, false
+}
+
+// dfuseCommand sends a DfuSe command and waits until the device executed
+// it.
+func (d *Device) dfuseCommand(cmd byte, addr uint32, withAddr bool) error {
+	b := []byte{cmd}
+	if withAddr {
+		b = append(b, byte(addr), byte(addr>>8), byte(addr>>16), byte(addr>>24))
+	}
+	_, err := d.dnload(0, b)
+	return err
+}
+
+// SetAddress sets the address used by the following DfuSe download or
+// upload.
+func (d *Device) SetAddress(addr uint32) error {
+	return d.dfuseCommand(dfuseSetAddress, addr, true)
+}
+
+// ErasePage erases the DfuSe page containing addr.
+func (d *Device) ErasePage(addr uint32) error {
+	return d.dfuseCommand(dfuseErase, addr, true)
+}
+
+// MassErase erases the whole DfuSe memory region.
+func (d *Device) MassErase() error {
+	return d.dfuseCommand(dfuseErase, 0, false)
+}
+
+// Leave makes a DfuSe device leave DFU mode and run the firmware at addr.
+// The device usually disconnects, so errors after the request was sent
+// are ignored.
+func (d *Device) Leave(addr uint32) error {
+	if err := d.SetAddress(addr); err != nil {
+		return err
+	}
+	if err := d.out(requestDnload, 2, nil); err != nil {
+		return fmt.Errorf("DFU_DNLOAD of block 2: %v", err)
+	}
+	d.GetStatus()
+	return nil
+}
+
+// DfuseDownload writes data to a DfuSe device at addr. If layout is not
+// nil, the erasable sectors covered by data are erased first and writes to
+// sectors that aren't writeable are refused.
+func (d *Device) DfuseDownload(layout *MemoryLayout, addr uint32, data []byte) error {
+	if layout != nil {
+		end := uint64(addr) + uint64(len(data))
+		for a := uint64(addr); a < end; {
+			s, ok := layout.Find(uint32(a))
+			if !ok {
+				return fmt.Errorf("address 0x%08x is outside of memory region %q", a, layout.Name)
+			}
+			if !s.Writeable {
+				return fmt.Errorf("sector at 0x%08x of memory region %q is not writeable", s.Addr, layout.Name)
+			}
+			if s.Erasable {
+				if err := d.ErasePage(s.Addr); err != nil {
+					return err
+				}
+			}
+			a = uint64(s.Addr) + uint64(s.Size)
+		}
+	}
+	size := d.transferSize()
+	for off := 0; off < len(data); off += size {
+		end := off + size
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := d.SetAddress(addr + uint32(off)); err != nil {
+			return err
+		}
+		if _, err := d.dnload(2, data[off:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DfuseUpload reads length bytes starting at addr from a DfuSe device and
+// writes them to w. A negative length reads until the device sends a short
+// block.
+func (d *Device) DfuseUpload(w io.Writer, addr uint32, length int) (int, error) {
+	if err := d.SetAddress(addr); err != nil {
+		return 0, err
+	}
+	if err := d.Abort(); err != nil {
+		return 0, err
+	}
+	return d.upload(w, 2, length)
+}
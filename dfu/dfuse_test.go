@@ -0,0 +1,107 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfu
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseMemoryLayout(t *testing.T) {
+	l, err := ParseMemoryLayout("@Internal Flash  /0x08000000/02*016Kg,01*064Kg/0x1FFF7800/01*512 e")
+	if err != nil {
+		t.Fatalf("ParseMemoryLayout(): %v", err)
+	}
+	want := &MemoryLayout{
+		Name: "Internal Flash",
+		Sectors: []Sector{
+			{Addr: 0x08000000, Size: 16 << 10, Readable: true, Erasable: true, Writeable: true},
+			{Addr: 0x08004000, Size: 16 << 10, Readable: true, Erasable: true, Writeable: true},
+			{Addr: 0x08008000, Size: 64 << 10, Readable: true, Erasable: true, Writeable: true},
+			{Addr: 0x1fff7800, Size: 512, Readable: true, Writeable: true},
+		},
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("ParseMemoryLayout(): got %+v, want %+v", l, want)
+	}
+	if s, ok := l.Find(0x08004abc); !ok || s.Addr != 0x08004000 {
+		t.Errorf("Find(0x08004abc): got (%+v, %v), want the sector at 0x08004000", s, ok)
+	}
+	if _, ok := l.Find(0x08018000); ok {
+		t.Error("Find(0x08018000): got a sector, want none")
+	}
+
+	for _, bad := range []string{
+		"Internal Flash/0x08000000/04*016Kg",
+		"@Flash/0x08000000",
+		"@Flash/nothex/04*016Kg",
+		"@Flash/0x08000000/04x016Kg",
+		"@Flash/0x08000000/04*016Kz",
+		"@Flash/0x08000000/00*016Kg",
+		"@Flash/0xfffff000/04*016Kg",
+	} {
+		if _, err := ParseMemoryLayout(bad); err == nil {
+			t.Errorf("ParseMemoryLayout(%q): got nil error, want an error", bad)
+		}
+	}
+}
+
+func TestDfuse(t *testing.T) {
+	l, err := ParseMemoryLayout("@Flash/0x08000000/02*001Kg,02*001Ka")
+	if err != nil {
+		t.Fatalf("ParseMemoryLayout(): %v", err)
+	}
+	f := &fakeDFU{state: StateIdle, dfuse: true, flash: make([]byte, 4096)}
+	d := New(f, testIntf, testFunc(true))
+	data := bytes.Repeat([]byte{0xa5}, 1024+testXferSize+1)
+	if err := d.DfuseDownload(l, flashBase+0x10, data); err != nil {
+		t.Fatalf("DfuseDownload(): %v", err)
+	}
+	if got, want := f.erased, []uint32{flashBase, flashBase + 1024}; !reflect.DeepEqual(got, want) {
+		t.Errorf("erased pages: got %x, want %x", got, want)
+	}
+	if !bytes.Equal(f.flash[0x10:0x10+len(data)], data) {
+		t.Error("DfuseDownload(): the flash differs from the data")
+	}
+	if err := d.DfuseDownload(nil, flashBase+0x10, data); err == nil {
+		t.Error("DfuseDownload() without erasing: got nil error, want an error")
+	}
+	if err := d.Idle(); err != nil {
+		t.Fatalf("Idle(): %v", err)
+	}
+	if err := d.DfuseDownload(l, flashBase+2048, data); err == nil {
+		t.Error("DfuseDownload() to a read-only sector: got nil error, want an error")
+	}
+
+	var buf bytes.Buffer
+	n, err := d.DfuseUpload(&buf, flashBase+0x10, len(data))
+	if err != nil || n != len(data) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("DfuseUpload(): got (%d, %v), want (%d, nil) and the downloaded data", n, err, len(data))
+	}
+	if f.state != StateIdle {
+		t.Errorf("state after DfuseUpload(): got %s, want %s", f.state, StateIdle)
+	}
+
+	if err := d.MassErase(); err != nil {
+		t.Errorf("MassErase(): %v", err)
+	}
+	if !bytes.Equal(f.flash, bytes.Repeat([]byte{0xff}, len(f.flash))) {
+		t.Error("MassErase(): the flash is not erased")
+	}
+	if err := d.Leave(flashBase); err != nil {
+		t.Errorf("Leave(): %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "sync"
+
+// disconnectState tracks whether the device was found to be disconnected.
+type disconnectState struct {
+	mu   sync.Mutex
+	done chan struct{}
+	gone bool
+}
+
+func (s *disconnectState) channel() chan struct{} {
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
+
+// check marks the device as disconnected if err reports that the device
+// is gone.
+func (s *disconnectState) check(err error) {
+	if !isDisconnect(err) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gone {
+		return
+	}
+	s.gone = true
+	close(s.channel())
+}
+
+// Done returns a channel that's closed once the device is found to be
+// disconnected, i.e. when a transfer or a control request on the device
+// fails with ErrorNoDevice or TransferNoDevice. It allows the application
+// to react to the device going away, e.g. by stopping all workers using
+// the device, instead of discovering it separately on each endpoint.
+// The channel is not closed by Close.
+func (d *Device) Done() <-chan struct{} {
+	d.disconnect.mu.Lock()
+	defer d.disconnect.mu.Unlock()
+	return d.disconnect.channel()
+}
+
+// Disconnected returns true if the device was found to be disconnected,
+// see Done.
+func (d *Device) Disconnected() bool {
+	d.disconnect.mu.Lock()
+	defer d.disconnect.mu.Unlock()
+	return d.disconnect.gone
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// dumper writes an indented descriptor tree, remembering the first
+// write error.
+type dumper struct {
+	w   io.Writer
+	err error
+	// str returns the string descriptor with the given index, or an empty
+	// string if it's not available.
+	str func(int) string
+}
+
+func (d *dumper) line(indent int, format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, args...))
+}
+
+// field writes a single "name value" line, with the values aligned.
+func (d *dumper) field(indent int, name string, format string, args ...interface{}) {
+	d.line(indent, "%-20s %s", name, fmt.Sprintf(format, args...))
+}
+
+// strField writes a string descriptor index, followed by the string itself
+// if it's available.
+func (d *dumper) strField(indent int, name string, idx int) {
+	if idx > 0 && d.str != nil {
+		if s := d.str(idx); s != "" {
+			d.field(indent, name, "%d %s", idx, s)
+			return
+		}
+	}
+	d.field(indent, name, "%d", idx)
+}
+
+func (d *dumper) device(desc *DeviceDesc) {
+	d.line(0, "Device Descriptor:")
+	d.field(1, "bcdUSB", "%s", desc.Spec)
+	d.field(1, "bDeviceClass", "%d %s", uint8(desc.Class), desc.Class)
+	d.field(1, "bDeviceSubClass", "%d", uint8(desc.SubClass))
+	d.field(1, "bDeviceProtocol", "%d", uint8(desc.Protocol))
+	d.field(1, "bMaxPacketSize0", "%d", desc.MaxControlPacketSize)
+	d.field(1, "idVendor", "0x%s", desc.Vendor)
+	d.field(1, "idProduct", "0x%s", desc.Product)
+	d.field(1, "bcdDevice", "%s", desc.Device)
+	d.strField(1, "iManufacturer", desc.iManufacturer)
+	d.strField(1, "iProduct", desc.iProduct)
+	d.strField(1, "iSerial", desc.iSerialNumber)
+	d.field(1, "bNumConfigurations", "%d", len(desc.Configs))
+	d.field(1, "Speed", "%s", desc.Speed)
+	d.field(1, "Bus", "%d", desc.Bus)
+	d.field(1, "Address", "%d", desc.Address)
+	d.field(1, "Port", "%d", desc.Port)
+	d.field(1, "Path", "%s", desc.portPath())
+	for _, n := range desc.sortedConfigIds() {
+		d.config(desc.Configs[n])
+	}
+}
+
+func (d *dumper) config(cfg ConfigDesc) {
+	d.line(1, "Configuration Descriptor:")
+	d.field(2, "bNumInterfaces", "%d", len(cfg.Interfaces))
+	d.field(2, "bConfigurationValue", "%d", cfg.Number)
+	d.strField(2, "iConfiguration", cfg.iConfiguration)
+	var attrs []string
+	if cfg.SelfPowered {
+		attrs = append(attrs, "Self Powered")
+	} else {
+		attrs = append(attrs, "Bus Powered")
+	}
+	if cfg.RemoteWakeup {
+		attrs = append(attrs, "Remote Wakeup")
+	}
+	d.field(2, "bmAttributes", "%s", strings.Join(attrs, ", "))
+	d.field(2, "MaxPower", "%dmA", cfg.MaxPower)
+	for _, intf := range cfg.Interfaces {
+		for _, alt := range intf.AltSettings {
+			d.intf(alt)
+		}
+	}
+}
+
+func (d *dumper) intf(alt InterfaceSetting) {
+	d.line(2, "Interface Descriptor:")
+	d.field(3, "bInterfaceNumber", "%d", alt.Number)
+	d.field(3, "bAlternateSetting", "%d", alt.Alternate)
+	d.field(3, "bNumEndpoints", "%d", len(alt.Endpoints))
+	d.field(3, "bInterfaceClass", "%d %s", uint8(alt.Class), alt.Class)
+	d.field(3, "bInterfaceSubClass", "%d", uint8(alt.SubClass))
+	d.field(3, "bInterfaceProtocol", "%d", uint8(alt.Protocol))
+	d.strField(3, "iInterface", alt.iInterface)
+	d.extra(3, alt.Extra)
+	var addrs []int
+	for a := range alt.Endpoints {
+		addrs = append(addrs, int(a))
+	}
+	sort.Ints(addrs)
+	for _, a := range addrs {
+		d.endpoint(alt.Endpoints[EndpointAddress(a)])
+	}
+}
+
+func (d *dumper) endpoint(ep EndpointDesc) {
+	d.line(3, "Endpoint Descriptor:")
+	d.field(4, "bEndpointAddress", "%s EP %d %s", ep.Address, ep.Number, ep.Direction)
+	d.line(4, "bmAttributes:")
+	d.field(5, "Transfer Type", "%s", ep.TransferType)
+	switch ep.TransferType {
+	case TransferTypeIsochronous:
+		d.field(5, "Synch Type", "%s", ep.IsoSyncType)
+		d.field(5, "Usage Type", "%s", ep.UsageType)
+	case TransferTypeInterrupt:
+		d.field(5, "Usage Type", "%s", ep.UsageType)
+	}
+	d.field(4, "wMaxPacketSize", "%d bytes", ep.MaxPacketSize)
+	d.field(4, "bInterval", "%s", ep.PollInterval)
+	d.extra(4, ep.Extra)
+}
+
+// extra writes the raw extra descriptors b, one per line.
+func (d *dumper) extra(indent int, b []byte) {
+	for len(b) >= 2 {
+		l := int(b[0])
+		if l < 2 || l > len(b) {
+			l = len(b)
+		}
+		d.line(indent, "Extra Descriptor (type 0x%02x): % x", b[1], b[:l])
+		b = b[l:]
+	}
+}
+
+// Dump writes the full descriptor tree of the device to w, in a layout
+// similar to that of "lsusb -v": the device descriptor followed by all
+// configurations, interface alternate settings and endpoints, including
+// endpoint attributes, max packet sizes and polling intervals.
+// String descriptors are not available without an open device and only
+// their indices are included, see Device.Dump.
+func (d *DeviceDesc) Dump(w io.Writer) error {
+	dd := &dumper{w: w}
+	dd.device(d)
+	return dd.err
+}
+
+// Dump writes the full descriptor tree of the device to w, like
+// DeviceDesc.Dump, with the string descriptors read from the device.
+func (d *Device) Dump(w io.Writer) error {
+	if d.handle == nil {
+		return fmt.Errorf("Dump() called on %s after Close", d)
+	}
+	dd := &dumper{
+		w: w,
+		str: func(idx int) string {
+			s, err := d.GetStringDescriptor(idx)
+			if err != nil {
+				return ""
+			}
+			return s
+		},
+	}
+	dd.device(d.Desc)
+	return dd.err
+}
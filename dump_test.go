@@ -0,0 +1,124 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const wantDump = `Device Descriptor:
+  bcdUSB               2.00
+  bDeviceClass         0 per-interface
+  bDeviceSubClass      0
+  bDeviceProtocol      255
+  bMaxPacketSize0      0
+  idVendor             0x9999
+  idProduct            0x0001
+  bcdDevice            1.00
+  iManufacturer        0
+  iProduct             0
+  iSerial              0
+  bNumConfigurations   1
+  Speed                unknown
+  Bus                  1
+  Address              1
+  Port                 1
+  Path                 1-1
+  Configuration Descriptor:
+    bNumInterfaces       1
+    bConfigurationValue  1
+    iConfiguration       0
+    bmAttributes         Bus Powered
+    MaxPower             100mA
+    Interface Descriptor:
+      bInterfaceNumber     0
+      bAlternateSetting    0
+      bNumEndpoints        2
+      bInterfaceClass      255 vendor-specific
+      bInterfaceSubClass   0
+      bInterfaceProtocol   0
+      iInterface           0
+      Endpoint Descriptor:
+        bEndpointAddress     0x01 EP 1 OUT
+        bmAttributes:
+          Transfer Type        bulk
+        wMaxPacketSize       512 bytes
+        bInterval            0s
+      Endpoint Descriptor:
+        bEndpointAddress     0x82 EP 2 IN
+        bmAttributes:
+          Transfer Type        bulk
+        wMaxPacketSize       512 bytes
+        bInterval            0s
+`
+
+func TestDeviceDescDump(t *testing.T) {
+	var buf bytes.Buffer
+	if err := fakeDevices[0].devDesc.Dump(&buf); err != nil {
+		t.Fatalf("Dump(): %v", err)
+	}
+	if got := buf.String(); got != wantDump {
+		t.Errorf("Dump():\n%s\nwant:\n%s", got, wantDump)
+	}
+}
+
+func TestDeviceDump(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(8888, 0002): %v", err)
+	}
+	var buf bytes.Buffer
+	if err := dev.Dump(&buf); err != nil {
+		t.Fatalf("%s.Dump(): %v", dev, err)
+	}
+	for _, want := range []string{
+		"  iManufacturer        1 ACME Industries\n",
+		"  iSerial              3 01234567\n",
+		"    iConfiguration       5 Weird configuration\n",
+		"      iInterface           7 Fast streaming\n",
+		"          Synch Type           ",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s.Dump(): output does not contain %q:\n%s", dev, want, buf.String())
+		}
+	}
+	dev.Close()
+	if err := dev.Dump(&buf); err == nil {
+		t.Errorf("%s.Dump() after Close: got nil error, want non-nil", dev)
+	}
+}
+
+func TestDumpExtra(t *testing.T) {
+	var buf bytes.Buffer
+	d := &dumper{w: &buf}
+	d.intf(InterfaceSetting{Class: ClassHID, Extra: []byte{0x09, 0x21, 0x11, 0x01, 0x00, 0x01, 0x22, 0x20, 0x00, 0x03, 0x24}})
+	for _, want := range []string{
+		"      Extra Descriptor (type 0x21): 09 21 11 01 00 01 22 20 00\n",
+		"      Extra Descriptor (type 0x24): 03 24\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("intf(): output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -16,9 +16,13 @@
 package gousb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,6 +34,22 @@ func (a EndpointAddress) String() string {
 	return fmt.Sprintf("0x%02x", uint8(a))
 }
 
+// MarshalText implements encoding.TextMarshaler, using the representation
+// returned by String.
+func (a EndpointAddress) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *EndpointAddress) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseUint(string(text), 0, 8)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint address %q, want an 8-bit number, e.g. 0x81", text)
+	}
+	*a = EndpointAddress(v)
+	return nil
+}
+
 // EndpointDesc contains the information about an interface endpoint, extracted
 // from the descriptor.
 type EndpointDesc struct {
@@ -44,17 +64,75 @@ type EndpointDesc struct {
 	// Direction defines whether the data is flowing IN or OUT from the host perspective.
 	Direction EndpointDirection
 	// MaxPacketSize is the maximum USB packet size for a single frame/microframe.
+	// For high-bandwidth isochronous and interrupt endpoints it includes
+	// the additional transactions per microframe, e.g. 3072 bytes for
+	// an endpoint doing three 1024 byte transactions.
 	MaxPacketSize int
 	// TransferType defines the endpoint type - bulk, interrupt, isochronous.
 	TransferType TransferType
+	// Interval is the raw bInterval field of the endpoint descriptor. Its
+	// meaning depends on the transfer type and the device speed, see
+	// PollInterval for the decoded value.
+	Interval int
 	// PollInterval is the maximum time between transfers for interrupt and isochronous transfer,
 	// or the NAK interval for a control transfer. See endpoint descriptor bInterval documentation
 	// in the USB spec for details.
 	PollInterval time.Duration
+	// MaxBurst is the number of packets the endpoint can send or receive
+	// as part of a burst, from the SuperSpeed endpoint companion
+	// descriptor. It's 0 for devices operating below SuperSpeed.
+	MaxBurst int
+	// MaxStreams is the number of bulk streams supported by a SuperSpeed
+	// bulk endpoint, 0 if streams are not supported.
+	MaxStreams int
+	// Mult is the number of bursts in a service interval of a SuperSpeed
+	// isochronous endpoint, 0 for other endpoints.
+	Mult int
+	// BytesPerInterval is the total number of bytes a SuperSpeed periodic
+	// endpoint transfers every service interval, 0 if not known.
+	BytesPerInterval int
 	// IsoSyncType is the isochronous endpoint synchronization type, as defined by USB spec.
 	IsoSyncType IsoSyncType
 	// UsageType is the isochronous or interrupt endpoint usage type, as defined by USB spec.
 	UsageType UsageType
+	// Extra holds the raw descriptors that follow the endpoint descriptor,
+	// e.g. the SuperSpeed endpoint companion or class-specific endpoint
+	// descriptors of audio devices. It's empty if there are none.
+	Extra []byte
+}
+
+// periodicMaxPacketSize returns the number of bytes an isochronous or
+// interrupt endpoint with the given wMaxPacketSize can transfer in a single
+// (micro)frame. Bits 0-10 of wMaxPacketSize identify the packet size, bits
+// 11-12 are the number of additional transaction opportunities per
+// microframe of high-bandwidth high speed endpoints, e.g. 0x1400 is three
+// 1024 byte transactions, 3072 bytes per microframe.
+func periodicMaxPacketSize(wMaxPacketSize uint16) int {
+	return int(wMaxPacketSize&0x07ff) * (int(wMaxPacketSize>>11&3) + 1)
+}
+
+// setSSCompanion applies the fields of the SuperSpeed endpoint companion
+// descriptor to the endpoint. On SuperSpeed devices, a single iso packet
+// carries all the data of a service interval, so MaxPacketSize of
+// isochronous endpoints is set to the number of bytes per interval.
+// Interrupt endpoints keep wMaxPacketSize, which still delimits their
+// short packets, even if a burst carries several packets per interval.
+func (e *EndpointDesc) setSSCompanion(maxBurst, attrs uint8, bytesPerInterval uint16) {
+	e.MaxBurst = int(maxBurst) + 1
+	switch e.TransferType {
+	case TransferTypeBulk:
+		if n := attrs & 0x1f; n > 0 {
+			e.MaxStreams = 1 << n
+		}
+	case TransferTypeIsochronous:
+		e.Mult = int(attrs&0x3) + 1
+		e.BytesPerInterval = int(bytesPerInterval)
+		if e.BytesPerInterval > 0 {
+			e.MaxPacketSize = e.BytesPerInterval
+		}
+	case TransferTypeInterrupt:
+		e.BytesPerInterval = int(bytesPerInterval)
+	}
 }
 
 // String returns the human-readable description of the endpoint.
@@ -78,6 +156,138 @@ type endpoint struct {
 	Desc EndpointDesc
 
 	ctx *Context
+	dev *Device
+
+	// metrics collects the transfer metrics of this endpoint.
+	metrics *endpointMetrics
+
+	// mu protects the endpoint settings below, which may be changed
+	// while transfers are in flight.
+	mu sync.Mutex
+	// flags are applied to all transfers submitted on this endpoint.
+	flags transferFlags
+	// deadline, if not zero, bounds all transfers on this endpoint.
+	deadline time.Time
+	// softZLP makes writes send the zero-length packet requested with
+	// SetZeroPacket as a separate transfer, where libusb can't add it.
+	softZLP bool
+	// maxTransfer, if not zero, is the size above which reads and writes
+	// are split into several transfers, see SetMaxTransferSize.
+	maxTransfer int
+	// inflight holds the cancel functions of the transfers in flight,
+	// see CancelAll. idle is signalled when the last of them finishes.
+	inflight map[*context.CancelFunc]bool
+	idle     *sync.Cond
+}
+
+func (e *endpoint) setDeadline(t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deadline = t
+}
+
+func (e *endpoint) getDeadline() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.deadline
+}
+
+func (e *endpoint) setFlag(f transferFlags, v bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flags = e.flags.set(f, v)
+}
+
+func (e *endpoint) getFlags() transferFlags {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.flags
+}
+
+// track returns a context for a transfer on the endpoint, which is
+// cancelled by CancelAll. done must be called when the transfer finished.
+func (e *endpoint) track(ctx context.Context) (tctx context.Context, done func()) {
+	tctx, cancel := context.WithCancel(ctx)
+	key := &cancel
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.inflight == nil {
+		e.inflight = make(map[*context.CancelFunc]bool)
+	}
+	e.inflight[key] = true
+	return tctx, func() {
+		cancel()
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.inflight, key)
+		if len(e.inflight) == 0 && e.idle != nil {
+			e.idle.Broadcast()
+		}
+	}
+}
+
+// CancelAll cancels all transfers in flight on the endpoint, started by
+// Read, Write, their Context variants or ReadIsoPackets, e.g. to unblock
+// a goroutine waiting for data on a quiet interrupt endpoint during
+// shutdown. The cancelled transfers return TransferCancelled along with
+// the data transferred so far. Transfers started after CancelAll returns
+// are not affected, and neither are streams, which are stopped by their
+// Close method.
+func (e *endpoint) CancelAll() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for cancel := range e.inflight {
+		(*cancel)()
+	}
+}
+
+// SetMaxTransferSize sets the size above which a Read or Write is split
+// into several transfers, each of at most n bytes, rounded down to
+// a multiple of EndpointDesc.MaxPacketSize. Reads stop at the first
+// transfer that ends with a short packet. By default the limit of
+// the platform is used, e.g. on Linux 1 MiB, or less if the
+// usbfs_memory_mb parameter of the usbcore module, shared by all
+// transfers in flight, is small, since exceeding it makes transfers fail.
+// Zero disables the splitting. Isochronous transfers are never split.
+func (e *endpoint) SetMaxTransferSize(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxTransfer = n
+}
+
+// MaxTransferSize returns the size above which transfers are split, see
+// SetMaxTransferSize. It's zero if transfers are not split.
+func (e *endpoint) MaxTransferSize() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.maxTransfer
+}
+
+// waitIdle waits until no transfers are in flight on the endpoint.
+func (e *endpoint) waitIdle() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.idle == nil {
+		e.idle = sync.NewCond(&e.mu)
+	}
+	for len(e.inflight) > 0 {
+		e.idle.Wait()
+	}
+}
+
+// transferWithDeadline runs the transfer, bounded by the deadline dl if
+// it's not zero. Transfers that didn't complete before the deadline fail
+// with TransferTimedOut.
+func (e *endpoint) transferWithDeadline(ctx context.Context, bufs [][]byte, flags transferFlags, dl time.Time) (int, error) {
+	if dl.IsZero() {
+		return e.doTransfer(ctx, bufs, flags)
+	}
+	if !time.Now().Before(dl) {
+		return 0, TransferTimedOut
+	}
+	dctx, cancel := context.WithDeadline(ctx, dl)
+	defer cancel()
+	return e.doTransfer(dctx, bufs, flags)
 }
 
 // String returns a human-readable description of the endpoint.
@@ -86,13 +296,120 @@ func (e *endpoint) String() string {
 }
 
 func (e *endpoint) transfer(ctx context.Context, buf []byte) (int, error) {
-	t, err := newUSBTransfer(e.ctx, e.h, &e.Desc, len(buf))
+	return e.transferUntil(ctx, [][]byte{buf}, e.getDeadline())
+}
+
+// transferTimeout runs a transfer bounded by timeout instead of
+// the endpoint deadline. A timeout of zero or less disables it.
+func (e *endpoint) transferTimeout(buf []byte, timeout time.Duration) (int, error) {
+	var dl time.Time
+	if timeout > 0 {
+		dl = time.Now().Add(timeout)
+	}
+	return e.transferUntil(context.Background(), [][]byte{buf}, dl)
+}
+
+// transferUntil transfers the concatenation of bufs, split into several
+// transfers if it exceeds the maximum transfer size of the endpoint.
+// The split transfers end early at the first short one, like a single
+// transfer ends with a short packet.
+func (e *endpoint) transferUntil(ctx context.Context, bufs [][]byte, dl time.Time) (int, error) {
+	flags := e.getFlags()
+	length := bufsLen(bufs)
+	zlp := flags&transferAddZeroPacket != 0 && e.Desc.Direction == EndpointDirectionOut
+	if zlp && e.softZLP {
+		flags = flags.set(transferAddZeroPacket, false)
+	}
+	limit := e.transferLimit()
+	chunks := [][][]byte{bufs}
+	if limit > 0 && length > limit {
+		chunks = splitBufs(bufs, limit)
+	}
+	total := 0
+	for i, chunk := range chunks {
+		f := flags
+		if i < len(chunks)-1 {
+			// Only the end of the last transfer is the end of the data.
+			f = f.set(transferAddZeroPacket, false)
+		}
+		n, err := e.transferOnce(ctx, chunk, f, dl)
+		total += n
+		if err != nil || n < bufsLen(chunk) {
+			return total, err
+		}
+	}
+	if zlp && e.softZLP && length > 0 && e.Desc.MaxPacketSize > 0 && length%e.Desc.MaxPacketSize == 0 {
+		if _, err := e.transferOnce(ctx, [][]byte{nil}, flags, dl); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// transferOnce runs a single transfer of the concatenation of bufs.
+func (e *endpoint) transferOnce(ctx context.Context, bufs [][]byte, flags transferFlags, dl time.Time) (int, error) {
+	length := bufsLen(bufs)
+	start := time.Now()
+	tctx, done := e.track(ctx)
+	n, err := e.transferWithDeadline(tctx, bufs, flags, dl)
+	done()
+	if e.dev != nil {
+		e.dev.disconnect.check(err)
+	}
+	if e.metrics != nil {
+		e.metrics.record(e.Desc.Direction, n, err, time.Since(start))
+	}
+	if e.ctx.tracing() {
+		buf := bufs[0]
+		if len(bufs) > 1 {
+			buf = bytes.Join(bufs, nil)
+		}
+		r := traceRecord{
+			dir:    e.Desc.Direction,
+			ep:     e.Desc.Address,
+			tt:     e.Desc.TransferType,
+			length: length,
+			data:   buf[:n],
+			err:    err,
+			start:  start,
+			dur:    time.Since(start),
+		}
+		if e.dev != nil {
+			r.bus, r.addr = e.dev.Desc.Bus, e.dev.Desc.Address
+		}
+		if e.Desc.Direction == EndpointDirectionOut {
+			r.outData = buf
+		}
+		e.ctx.trace(r)
+	}
+	e.ctx.log(LogOpTransfer, err, LogField{"endpoint", e.Desc.Address}, LogField{"length", length}, LogField{"bytes", n})
+	return n, err
+}
+
+// doTransfer runs a transfer of the concatenation of bufs. The data is
+// copied between bufs and the transfer buffer, unless bufs is a single
+// buffer allocated with Device.AllocTransferBuffer.
+func (e *endpoint) doTransfer(ctx context.Context, bufs [][]byte, flags transferFlags) (int, error) {
+	length := bufsLen(bufs)
+	// Buffers from Device.AllocTransferBuffer are used by the transfer
+	// directly, without copying the data.
+	direct := len(bufs) == 1 && e.dev != nil && e.dev.isTransferBuffer(bufs[0])
+	var t *usbTransfer
+	var err error
+	if direct {
+		t, err = newUSBTransferWithBuffer(e.ctx, e.h, &e.Desc, flags, bufs[0])
+	} else {
+		t, err = newUSBTransfer(e.ctx, e.h, &e.Desc, flags, length)
+	}
 	if err != nil {
 		return 0, err
 	}
 	defer t.free()
-	if e.Desc.Direction == EndpointDirectionOut {
-		copy(t.data(), buf)
+	if e.Desc.Direction == EndpointDirectionOut && !direct {
+		data := t.data()
+		for _, b := range bufs {
+			data = data[copy(data, b):]
+		}
 	}
 
 	if err := t.submit(); err != nil {
@@ -100,8 +417,16 @@ func (e *endpoint) transfer(ctx context.Context, buf []byte) (int, error) {
 	}
 
 	n, err := t.wait(ctx)
-	if e.Desc.Direction == EndpointDirectionIn {
-		copy(buf, t.data())
+	if e.Desc.Direction == EndpointDirectionIn && !direct {
+		data := t.data()[:n]
+		for _, b := range bufs {
+			data = data[copy(b, data):]
+		}
+	}
+	if err == TransferCancelled && ctx.Err() == context.DeadlineExceeded {
+		// Report an expired deadline as a timeout, like the net package
+		// does, along with the data transferred so far.
+		err = TransferTimedOut
 	}
 	if err != nil {
 		return n, err
@@ -110,13 +435,20 @@ func (e *endpoint) transfer(ctx context.Context, buf []byte) (int, error) {
 }
 
 // InEndpoint represents an IN endpoint open for transfer.
-// InEndpoint implements the io.Reader interface.
+// InEndpoint implements the io.Reader interface. Each Read is a single
+// transfer and returns as soon as the device ends it with a short packet,
+// so reads shorter than the buffer are normal. A zero-length packet sent by
+// the device results in a Read returning 0 and a nil error. When wrapping
+// the endpoint in bufio.Reader or similar, use a buffer size that's
+// a multiple of EndpointDesc.MaxPacketSize.
 // For high-throughput transfers, consider creating a buffered read stream
 // through InEndpoint.ReadStream.
 type InEndpoint struct {
 	*endpoint
 }
 
+var _ io.Reader = (*InEndpoint)(nil)
+
 // Read reads data from an IN endpoint. Read returns number of bytes obtained
 // from the endpoint. Read may return non-zero length even if
 // the returned error is not nil (partial read).
@@ -136,7 +468,10 @@ func (e *InEndpoint) Read(buf []byte) (int, error) {
 // even if the returned error is not nil (partial read).
 // The passed context can be used to control the cancellation of the read. If
 // the context is cancelled, ReadContext will cancel the underlying transfers,
-// resulting in TransferCancelled error.
+// resulting in TransferCancelled error. If the context deadline expires,
+// the error is TransferTimedOut instead. In both cases the data received
+// before the cancellation is returned, which allows reading variable-length
+// bursts that are terminated only by the device going silent.
 // It's recommended to use buffer sizes that are multiples of
 // EndpointDesc.MaxPacketSize to avoid overflows.
 // When a USB device receives a read request, it doesn't know the size of the
@@ -148,16 +483,106 @@ func (e *InEndpoint) ReadContext(ctx context.Context, buf []byte) (int, error) {
 	return e.transfer(ctx, buf)
 }
 
+// messageChunkSize is the size of the reads used by ReadMessage, unless
+// the maximum transfer size of the endpoint is smaller, before rounding to
+// a multiple of the packet size.
+const messageChunkSize = 16 << 10
+
+// ReadMessage reads a single protocol message, which the device ends with
+// a packet shorter than EndpointDesc.MaxPacketSize, or with a zero-length
+// packet if the message is a multiple of the packet size. This is the
+// framing used by most bulk-based vendor protocols. ReadMessage reads as
+// many transfers as needed, unlike Read, which also stops when its buffer
+// is full. If a read fails, the data received so far is returned along
+// with the error.
+func (e *InEndpoint) ReadMessage() ([]byte, error) {
+	return e.ReadMessageContext(context.Background())
+}
+
+// ReadMessageContext is like ReadMessage, but the reads are cancelled when
+// ctx is done, see ReadContext.
+func (e *InEndpoint) ReadMessageContext(ctx context.Context) ([]byte, error) {
+	chunk := messageChunkSize
+	if limit := e.transferLimit(); limit > 0 && limit < chunk {
+		chunk = limit
+	}
+	if mps := e.Desc.MaxPacketSize; mps > 0 {
+		chunk -= chunk % mps
+		if chunk == 0 {
+			chunk = mps
+		}
+	}
+	var msg []byte
+	for {
+		off := len(msg)
+		msg = append(msg, make([]byte, chunk)...)
+		n, err := e.transfer(ctx, msg[off:])
+		msg = msg[:off+n]
+		if err != nil || n < chunk {
+			return msg, err
+		}
+	}
+}
+
+// MessageReader reads protocol messages ending with a short packet, like
+// InEndpoint.ReadMessage. The class drivers accept it in place of an
+// InEndpoint, e.g. to read from a fake in tests.
+type MessageReader interface {
+	ReadMessage() ([]byte, error)
+}
+
+// InEndpoint implements MessageReader.
+var _ MessageReader = (*InEndpoint)(nil)
+
+// ReadTimeout reads data from an IN endpoint like Read, but the read is
+// bounded by timeout instead of the read deadline of the endpoint, e.g.
+// for an occasional slow response of the device. A read that doesn't
+// complete in time fails with TransferTimedOut, returning the data received
+// until then. A timeout of zero or less disables the timeout.
+func (e *InEndpoint) ReadTimeout(buf []byte, timeout time.Duration) (int, error) {
+	return e.transferTimeout(buf, timeout)
+}
+
+// SetShortNotOK controls the handling of short packets on the endpoint.
+// When enabled, a read that receives less data than requested fails with
+// a TransferError status, instead of returning the shorter data
+// successfully. Some device protocols rely on this to detect framing errors.
+// The setting applies to reads and streams started after the call.
+func (e *InEndpoint) SetShortNotOK(v bool) {
+	e.setFlag(transferShortNotOK, v)
+}
+
+// SetReadDeadline sets the deadline for reads on the endpoint, like
+// net.Conn.SetReadDeadline. A Read that doesn't complete before
+// the deadline fails with TransferTimedOut, returning the data received
+// until then. The deadline applies to reads started after the call and can
+// be extended after a timeout. A zero value disables the deadline.
+// The deadline doesn't apply to streams created with NewStream.
+func (e *InEndpoint) SetReadDeadline(t time.Time) {
+	e.setDeadline(t)
+}
+
+// SetDeadline is the same as SetReadDeadline.
+func (e *InEndpoint) SetDeadline(t time.Time) {
+	e.setDeadline(t)
+}
+
 // OutEndpoint represents an OUT endpoint open for transfer.
+// OutEndpoint implements io.Writer and can be used with io.Copy, bufio
+// and other standard library helpers.
 type OutEndpoint struct {
 	*endpoint
 }
 
+var _ io.Writer = (*OutEndpoint)(nil)
+
 // Write writes data to an OUT endpoint. Write returns number of bytes comitted
 // to the endpoint. Write may return non-zero length even if the returned error
-// is not nil (partial write).
+// is not nil (partial write). If the device accepted only a part of
+// the data without reporting an error, Write returns io.ErrShortWrite,
+// as required by io.Writer.
 func (e *OutEndpoint) Write(buf []byte) (int, error) {
-	return e.transfer(context.Background(), buf)
+	return e.write(context.Background(), buf)
 }
 
 // WriteContext writes data to an OUT endpoint. WriteContext returns number of
@@ -165,7 +590,78 @@ func (e *OutEndpoint) Write(buf []byte) (int, error) {
 // if the returned error is not nil (partial write).
 // The passed context can be used to control the cancellation of the write. If
 // the context is cancelled, WriteContext will cancel the underlying transfers,
-// resulting in TransferCancelled error.
+// resulting in TransferCancelled error, or TransferTimedOut if the context
+// deadline expired.
 func (e *OutEndpoint) WriteContext(ctx context.Context, buf []byte) (int, error) {
-	return e.transfer(ctx, buf)
+	return e.write(ctx, buf)
+}
+
+// WriteTimeout writes data to an OUT endpoint like Write, but the write is
+// bounded by timeout instead of the write deadline of the endpoint.
+// A write that doesn't complete in time fails with TransferTimedOut,
+// returning the number of bytes sent until then. A timeout of zero or
+// less disables the timeout.
+func (e *OutEndpoint) WriteTimeout(buf []byte, timeout time.Duration) (int, error) {
+	n, err := e.transferTimeout(buf, timeout)
+	if err == nil && n < len(buf) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// WriteV writes the concatenation of bufs to the endpoint as a single
+// transfer, like Write, e.g. when a protocol header and the payload are
+// in separate buffers. The buffers are copied directly into the transfer,
+// without joining them first.
+func (e *OutEndpoint) WriteV(bufs [][]byte) (int, error) {
+	return e.WriteVContext(context.Background(), bufs)
+}
+
+// WriteVContext is like WriteV, but the write is cancelled when ctx is
+// done, see WriteContext.
+func (e *OutEndpoint) WriteVContext(ctx context.Context, bufs [][]byte) (int, error) {
+	if len(bufs) == 0 {
+		bufs = [][]byte{nil}
+	}
+	n, err := e.transferUntil(ctx, bufs, e.getDeadline())
+	if err == nil && n < bufsLen(bufs) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+func (e *OutEndpoint) write(ctx context.Context, buf []byte) (int, error) {
+	n, err := e.transfer(ctx, buf)
+	if err == nil && n < len(buf) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// SetZeroPacket controls termination of writes that are an exact multiple
+// of the endpoint's maximum packet size. When enabled, such a write is
+// followed by a zero-length packet, which many device protocols use to mark
+// the end of a message, and without which the device may wait for more data
+// forever. Where libusb can't add the zero-length packet to the transfer,
+// i.e. on platforms other than Linux, Write sends it as a separate transfer.
+// Streams rely on libusb, on these platforms they fail with
+// ErrorNotSupported.
+// The setting applies to writes and streams started after the call.
+func (e *OutEndpoint) SetZeroPacket(v bool) {
+	e.setFlag(transferAddZeroPacket, v)
+}
+
+// SetWriteDeadline sets the deadline for writes on the endpoint, like
+// net.Conn.SetWriteDeadline. A Write that doesn't complete before
+// the deadline fails with TransferTimedOut, returning the number of bytes
+// sent until then. The deadline applies to writes started after the call
+// and can be extended after a timeout. A zero value disables the deadline.
+// The deadline doesn't apply to streams created with NewStream.
+func (e *OutEndpoint) SetWriteDeadline(t time.Time) {
+	e.setDeadline(t)
+}
+
+// SetDeadline is the same as SetWriteDeadline.
+func (e *OutEndpoint) SetDeadline(t time.Time) {
+	e.setDeadline(t)
 }
@@ -14,10 +14,75 @@
 
 package gousb
 
+import "fmt"
+
+// StreamParams are the parameters of an endpoint stream, trading memory
+// for throughput. Up to Size*Count bytes of buffers are allocated
+// for the stream.
+type StreamParams struct {
+	// Size is the buffer size of a single transfer, in bytes.
+	Size int
+	// Count is the number of transfers kept in flight.
+	Count int
+}
+
+// packetsPerTransfer returns the suggested number of max-size packets
+// transferred in a single transfer on an endpoint.
+func packetsPerTransfer(tt TransferType, speed Speed) int {
+	switch tt {
+	case TransferTypeInterrupt:
+		// Interrupt endpoints deliver at most a packet per interval.
+		return 1
+	case TransferTypeIsochronous:
+		// 8ms worth of packets, microframes are 125us on high speed
+		// and faster buses.
+		if speed >= SpeedHigh {
+			return 64
+		}
+		return 8
+	}
+	switch {
+	case speed >= SpeedSuper:
+		return 64
+	case speed == SpeedHigh:
+		return 32
+	default:
+		return 16
+	}
+}
+
+// DefaultStreamParams returns suggested parameters for a stream on the
+// endpoint, derived from the endpoint type, its max packet size and
+// the device speed. The transfer size is a multiple of the max packet
+// size and large enough to keep a bulk endpoint busy for a few hundred
+// microseconds, with four transfers in flight, or eight on high speed and
+// faster devices. The values can be adjusted and passed to NewStream.
+func (e *endpoint) DefaultStreamParams() StreamParams {
+	speed := SpeedUnknown
+	if e.dev != nil {
+		speed = e.dev.Desc.Speed
+	}
+	maxPkt := e.Desc.MaxPacketSize
+	if maxPkt <= 0 {
+		maxPkt = 64
+	}
+	p := StreamParams{
+		Size:  maxPkt * packetsPerTransfer(e.Desc.TransferType, speed),
+		Count: 4,
+	}
+	if speed >= SpeedHigh {
+		p.Count = 8
+	}
+	return p
+}
+
 func (e *endpoint) newStream(size, count int) (*stream, error) {
+	if size <= 0 || count <= 0 {
+		return nil, fmt.Errorf("invalid stream parameters for %s: size %d and count %d must be positive", e, size, count)
+	}
 	var ts []transferIntf
 	for i := 0; i < count; i++ {
-		t, err := newUSBTransfer(e.ctx, e.h, &e.Desc, size)
+		t, err := newUSBTransfer(e.ctx, e.h, &e.Desc, e.getFlags(), size)
 		if err != nil {
 			for _, t := range ts {
 				t.free()
@@ -26,7 +91,11 @@ func (e *endpoint) newStream(size, count int) (*stream, error) {
 		}
 		ts = append(ts, t)
 	}
-	return newStream(ts), nil
+	s := newStream(ts)
+	if e.dev != nil {
+		s.disconnect = &e.dev.disconnect
+	}
+	return s, nil
 }
 
 // NewStream prepares a new read stream that will keep reading data from
@@ -37,7 +106,8 @@ func (e *endpoint) newStream(size, count int) (*stream, error) {
 // the latency between subsequent transfers and increases reading throughput.
 // Similarly to InEndpoint.Read, the size of the buffer should be a multiple
 // of EndpointDesc.MaxPacketSize to avoid overflows, see documentation
-// in InEndpoint.Read for more details.
+// in InEndpoint.Read for more details. DefaultStreamParams returns suggested
+// values for size and count.
 func (e *InEndpoint) NewStream(size, count int) (*ReadStream, error) {
 	s, err := e.newStream(size, count)
 	if err != nil {
@@ -51,11 +121,14 @@ func (e *InEndpoint) NewStream(size, count int) (*ReadStream, error) {
 // background. Size defines a buffer size for a single write transaction and
 // count defines how many transactions may be active at any time. By buffering
 // the writes, a Stream reduces the latency between subsequent transfers and
-// increases writing throughput.
+// increases writing throughput. DefaultStreamParams returns suggested values
+// for size and count.
 func (e *OutEndpoint) NewStream(size, count int) (*WriteStream, error) {
 	s, err := e.newStream(size, count)
 	if err != nil {
 		return nil, err
 	}
+	// Transfers of a write stream are submitted as the data is written.
+	s.unsubmitted = count
 	return &WriteStream{s: s}, nil
 }
@@ -82,9 +82,51 @@ func TestEndpointReadStream(t *testing.T) {
 	if got != want {
 		t.Errorf("stream.Read(): read %d bytes, want %d", got, want)
 	}
+	st := stream.Stats()
+	if st.Bytes != int64(want) || st.Transfers != int64(goodTransfers) || st.Errors != 1 || st.QueueDepth != 0 {
+		t.Errorf("stream.Stats(): got %+v, want %d bytes in %d transfers, 1 error and an empty queue", st, want, goodTransfers)
+	}
 	close(done)
 }
 
+func TestEndpointStreamDisconnect(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	go func() {
+		lib.waitForSubmitted(nil).setStatus(TransferNoDevice)
+		// The other transfer is cancelled by the stream.
+		lib.waitForSubmitted(nil)
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	stream, err := ep.NewStream(512, 2)
+	if err != nil {
+		t.Fatalf("%s.NewStream(512, 2): %v", ep, err)
+	}
+	defer stream.Close()
+	if _, err := stream.Read(make([]byte, 512)); err != TransferNoDevice {
+		t.Errorf("stream.Read(): got error %v, want %v", err, TransferNoDevice)
+	}
+	if !dev.Disconnected() {
+		t.Errorf("%s.Disconnected() after a stream failed with %v: got false, want true", dev, TransferNoDevice)
+	}
+}
+
 func TestEndpointWriteStream(t *testing.T) {
 	t.Parallel()
 	lib := newFakeLibusb()
@@ -156,4 +198,40 @@ func TestEndpointWriteStream(t *testing.T) {
 	if wantXfers := 20; num != wantXfers { // transferred 10240 bytes, device max packet size is 512
 		t.Errorf("received transfers: got %d, want %d", num, wantXfers)
 	}
+	st := stream.Stats()
+	if st.Bytes != 10240 || st.Transfers != 20 || st.ShortTransfers != 0 || st.Errors != 0 || st.QueueDepth != 0 {
+		t.Errorf("stream.Stats(): got %+v, want 10240 bytes in 20 full transfers, no errors and an empty queue", st)
+	}
+	if st.Throughput() <= 0 {
+		t.Errorf("stream.Stats().Throughput(): got %f, want > 0", st.Throughput())
+	}
+}
+
+func TestDefaultStreamParams(t *testing.T) {
+	for _, tc := range []struct {
+		tt     TransferType
+		maxPkt int
+		speed  Speed
+		want   StreamParams
+	}{
+		{TransferTypeBulk, 64, SpeedFull, StreamParams{Size: 1024, Count: 4}},
+		{TransferTypeBulk, 512, SpeedHigh, StreamParams{Size: 16384, Count: 8}},
+		{TransferTypeBulk, 1024, SpeedSuper, StreamParams{Size: 65536, Count: 8}},
+		{TransferTypeInterrupt, 8, SpeedLow, StreamParams{Size: 8, Count: 4}},
+		{TransferTypeIsochronous, 3072, SpeedHigh, StreamParams{Size: 196608, Count: 8}},
+		{TransferTypeIsochronous, 1023, SpeedFull, StreamParams{Size: 8184, Count: 4}},
+	} {
+		ep := &endpoint{
+			Desc: EndpointDesc{TransferType: tc.tt, MaxPacketSize: tc.maxPkt},
+			dev:  &Device{Desc: &DeviceDesc{Speed: tc.speed}},
+		}
+		if got := ep.DefaultStreamParams(); got != tc.want {
+			t.Errorf("DefaultStreamParams() for %s endpoint with %d byte packets on a %s speed device: got %+v, want %+v", tc.tt, tc.maxPkt, tc.speed, got, tc.want)
+		}
+	}
+
+	ep := &endpoint{Desc: EndpointDesc{TransferType: TransferTypeBulk, MaxPacketSize: 512}}
+	if _, err := ep.newStream(0, 4); err == nil {
+		t.Errorf("newStream(0, 4): got nil error, want non-nil")
+	}
 }
@@ -15,7 +15,13 @@
 package gousb
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -235,9 +241,10 @@ func TestEndpointInOut(t *testing.T) {
 		fakeT.setData(make([]byte, dataTransferred))
 		fakeT.setStatus(TransferCompleted)
 	}()
+	// The device accepted only a part of the buffer.
 	got, err = oep.Write(buf)
-	if err != nil {
-		t.Errorf("%s.Write: got error %v, want nil", oep, err)
+	if err != io.ErrShortWrite {
+		t.Errorf("%s.Write: got error %v, want %v", oep, err, io.ErrShortWrite)
 	} else if got != dataTransferred {
 		t.Errorf("%s.Write: got %d, want %d", oep, got, dataTransferred)
 	}
@@ -246,6 +253,22 @@ func TestEndpointInOut(t *testing.T) {
 	if err == nil {
 		t.Errorf("%s.OutEndpoint(2): got nil, want error", intf)
 	}
+
+	// Endpoints can also be looked up by address, but the direction
+	// needs to match.
+	if _, err := intf.InEndpoint(0x82); err != nil {
+		t.Errorf("%s.InEndpoint(0x82): got error %v, want nil", intf, err)
+	}
+	for _, num := range []int{0x82, 0, 16, -1} {
+		if _, err := intf.OutEndpoint(num); err == nil {
+			t.Errorf("%s.OutEndpoint(%#x): got nil, want error", intf, num)
+		}
+	}
+	for _, num := range []int{0x80, 0x90, 0} {
+		if _, err := intf.InEndpoint(num); err == nil {
+			t.Errorf("%s.InEndpoint(%#x): got nil, want error", intf, num)
+		}
+	}
 }
 
 func TestSameEndpointNumberInOut(t *testing.T) {
@@ -287,6 +310,22 @@ func TestSameEndpointNumberInOut(t *testing.T) {
 	if _, err := intf.OutEndpoint(1); err != nil {
 		t.Errorf("%s.OutEndpoint(1): got error %v, want nil", intf, err)
 	}
+
+	// Opening an endpoint again reuses it instead of tracking another one.
+	in1, err := intf.InEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(1): %v", intf, err)
+	}
+	in2, err := intf.InEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(1): %v", intf, err)
+	}
+	if in1.endpoint != in2.endpoint {
+		t.Errorf("%s.InEndpoint(1) called twice: got different endpoints", intf)
+	}
+	if got := len(intf.endpoints); got != 2 {
+		t.Errorf("%s: got %d open endpoints, want 2", intf, got)
+	}
 }
 
 func TestReadContext(t *testing.T) {
@@ -356,3 +395,686 @@ func TestReadContext(t *testing.T) {
 		t.Errorf("%s.Write: got %d bytes, want %d (partial write success)", oep, got, want)
 	}
 }
+
+func TestEndpointTransferFlags(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	d, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): got error %v, want nil", err)
+	}
+	defer d.Close()
+	intf, done, err := d.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", d, err)
+	}
+	defer done()
+
+	iep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): got error %v, want nil", intf, err)
+	}
+	oep, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): got error %v, want nil", intf, err)
+	}
+
+	gotFlags := make(chan transferFlags)
+	go func() {
+		for {
+			ft := lib.waitForSubmitted(nil)
+			if ft == nil {
+				return
+			}
+			ft.setLength(len(ft.buf))
+			ft.setStatus(TransferCompleted)
+			gotFlags <- ft.flags
+		}
+	}()
+
+	buf := make([]byte, 512)
+	for _, tc := range []struct {
+		desc string
+		set  func(bool)
+		op   func([]byte) (int, error)
+		v    bool
+		want transferFlags
+	}{
+		{"IN default", func(bool) {}, iep.Read, false, 0},
+		{"IN short not ok", iep.SetShortNotOK, iep.Read, true, transferShortNotOK},
+		{"IN short ok", iep.SetShortNotOK, iep.Read, false, 0},
+		{"OUT default", func(bool) {}, oep.Write, false, 0},
+		{"OUT zero packet", oep.SetZeroPacket, oep.Write, true, transferAddZeroPacket},
+		{"OUT no zero packet", oep.SetZeroPacket, oep.Write, false, 0},
+	} {
+		tc.set(tc.v)
+		if _, err := tc.op(buf); err != nil {
+			t.Errorf("%s: transfer: got error %v, want nil", tc.desc, err)
+		}
+		if got := <-gotFlags; got != tc.want {
+			t.Errorf("%s: transfer flags: got %08b, want %08b", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestEndpointReaderWriter(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+
+	// Two short packets are assembled by io.ReadFull.
+	go func() {
+		for _, d := range [][]byte{{1, 2, 3}, {4, 5}} {
+			ft := lib.waitForSubmitted(nil)
+			ft.setData(d)
+			ft.setStatus(TransferCompleted)
+		}
+	}()
+	got := make([]byte, 5)
+	if _, err := io.ReadFull(bufio.NewReaderSize(in, 512), got); err != nil {
+		t.Fatalf("io.ReadFull(%s): %v", in, err)
+	}
+	if want := []byte{1, 2, 3, 4, 5}; !bytes.Equal(got, want) {
+		t.Errorf("io.ReadFull(%s): got %v, want %v", in, got, want)
+	}
+
+	// io.Copy reports a write accepted only partially by the device.
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setLength(2)
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := io.Copy(out, bytes.NewReader([]byte{1, 2, 3, 4})); err != io.ErrShortWrite || n != 2 {
+		t.Errorf("io.Copy(%s): got %d, %v, want 2, %v", out, n, err, io.ErrShortWrite)
+	}
+}
+
+func TestEndpointDeadline(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	buf := make([]byte, 512)
+
+	// A deadline in the past fails without submitting a transfer.
+	in.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, err := in.Read(buf); err != TransferTimedOut {
+		t.Errorf("%s.Read() after the deadline: got error %v, want %v", in, err, TransferTimedOut)
+	}
+
+	// The device doesn't answer before the deadline.
+	go lib.waitForSubmitted(nil)
+	out.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := out.Write(buf); err != TransferTimedOut {
+		t.Errorf("%s.Write() with a deadline: got error %v, want %v", out, err, TransferTimedOut)
+	}
+
+	// The deadline is cleared.
+	in.SetReadDeadline(time.Time{})
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData([]byte{1})
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := in.Read(buf); err != nil || n != 1 {
+		t.Errorf("%s.Read() without a deadline: got %d, %v, want 1, nil", in, n, err)
+	}
+
+	// A per-call timeout overrides the expired deadline of the endpoint.
+	in.SetReadDeadline(time.Now().Add(-time.Second))
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData([]byte{1, 2})
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := in.ReadTimeout(buf, time.Hour); err != nil || n != 2 {
+		t.Errorf("%s.ReadTimeout(1h) after the deadline: got %d, %v, want 2, nil", in, n, err)
+	}
+	out.SetWriteDeadline(time.Time{})
+	go lib.waitForSubmitted(nil)
+	if _, err := out.WriteTimeout(buf, 10*time.Millisecond); err != TransferTimedOut {
+		t.Errorf("%s.WriteTimeout(10ms): got error %v, want %v", out, err, TransferTimedOut)
+	}
+}
+
+func TestReadTimeoutPartialData(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	// The device sends a burst and goes silent.
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData([]byte{1, 2, 3})
+	}()
+	buf := make([]byte, 512)
+	buf[3] = 0xff
+	rctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n, err := in.ReadContext(rctx, buf)
+	if err != TransferTimedOut {
+		t.Errorf("%s.ReadContext(): got error %v, want %v", in, err, TransferTimedOut)
+	}
+	if want := []byte{1, 2, 3}; !bytes.Equal(buf[:n], want) {
+		t.Errorf("%s.ReadContext(): got data %v, want %v", in, buf[:n], want)
+	}
+	if buf[3] != 0xff {
+		t.Errorf("%s.ReadContext(): buffer past the received data was overwritten", in)
+	}
+}
+
+func TestPeriodicMaxPacketSize(t *testing.T) {
+	for _, tc := range []struct {
+		wMaxPacketSize uint16
+		want           int
+	}{
+		{0x0040, 64},
+		{0x0400, 1024},
+		{0x0c00, 2048},
+		{0x1400, 3072},
+		{0x13fc, 3060},
+	} {
+		if got := periodicMaxPacketSize(tc.wMaxPacketSize); got != tc.want {
+			t.Errorf("periodicMaxPacketSize(0x%04x): got %d, want %d", tc.wMaxPacketSize, got, tc.want)
+		}
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	for _, tc := range []struct {
+		bInterval uint8
+		tt        TransferType
+		spec      BCD
+		speed     Speed
+		want      time.Duration
+	}{
+		{10, TransferTypeInterrupt, Version(1, 1), SpeedFull, 10 * time.Millisecond},
+		{10, TransferTypeInterrupt, Version(2, 0), SpeedLow, 10 * time.Millisecond},
+		{1, TransferTypeIsochronous, Version(2, 0), SpeedFull, time.Millisecond},
+		{4, TransferTypeInterrupt, Version(2, 0), SpeedHigh, time.Millisecond},
+		{1, TransferTypeIsochronous, Version(2, 0), SpeedHigh, 125 * time.Microsecond},
+		{8, TransferTypeBulk, Version(2, 0), SpeedHigh, time.Millisecond},
+		{4, TransferTypeIsochronous, Version(3, 0), SpeedSuper, time.Millisecond},
+		// Out of range values are clamped.
+		{0, TransferTypeInterrupt, Version(2, 0), SpeedHigh, 125 * time.Microsecond},
+		{20, TransferTypeInterrupt, Version(2, 0), SpeedHigh, 125 * time.Microsecond << 15},
+	} {
+		dev := &DeviceDesc{Spec: tc.spec, Speed: tc.speed}
+		if got := pollInterval(tc.bInterval, tc.tt, dev); got != tc.want {
+			t.Errorf("pollInterval(%d, %s, USB %s %s speed): got %s, want %s", tc.bInterval, tc.tt, tc.spec, tc.speed, got, tc.want)
+		}
+	}
+}
+
+func TestSSCompanion(t *testing.T) {
+	for _, tc := range []struct {
+		desc             string
+		tt               TransferType
+		maxBurst, attrs  uint8
+		bytesPerInterval uint16
+		want             EndpointDesc
+	}{
+		{
+			desc:     "bulk with streams",
+			tt:       TransferTypeBulk,
+			maxBurst: 15,
+			attrs:    4,
+			want:     EndpointDesc{TransferType: TransferTypeBulk, MaxPacketSize: 1024, MaxBurst: 16, MaxStreams: 16},
+		},
+		{
+			desc:             "isochronous with 3 bursts",
+			tt:               TransferTypeIsochronous,
+			maxBurst:         3,
+			attrs:            2,
+			bytesPerInterval: 12288,
+			want:             EndpointDesc{TransferType: TransferTypeIsochronous, MaxPacketSize: 12288, MaxBurst: 4, Mult: 3, BytesPerInterval: 12288},
+		},
+		{
+			desc:             "interrupt",
+			tt:               TransferTypeInterrupt,
+			bytesPerInterval: 64,
+			want:             EndpointDesc{TransferType: TransferTypeInterrupt, MaxPacketSize: 1024, MaxBurst: 1, BytesPerInterval: 64},
+		},
+		{
+			desc:             "interrupt with 2 packets per interval",
+			tt:               TransferTypeInterrupt,
+			maxBurst:         1,
+			bytesPerInterval: 2048,
+			want:             EndpointDesc{TransferType: TransferTypeInterrupt, MaxPacketSize: 1024, MaxBurst: 2, BytesPerInterval: 2048},
+		},
+	} {
+		got := EndpointDesc{TransferType: tc.tt, MaxPacketSize: 1024}
+		got.setSSCompanion(tc.maxBurst, tc.attrs, tc.bytesPerInterval)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: setSSCompanion(%d, 0x%02x, %d): got %+v, want %+v", tc.desc, tc.maxBurst, tc.attrs, tc.bytesPerInterval, got, tc.want)
+		}
+	}
+}
+
+func TestFindEndpoint(t *testing.T) {
+	alt := InterfaceSetting{
+		Endpoints: map[EndpointAddress]EndpointDesc{
+			0x01: {Address: 0x01, Direction: EndpointDirectionOut, TransferType: TransferTypeBulk},
+			0x84: {Address: 0x84, Direction: EndpointDirectionIn, TransferType: TransferTypeBulk},
+			0x82: {Address: 0x82, Direction: EndpointDirectionIn, TransferType: TransferTypeBulk},
+			0x83: {Address: 0x83, Direction: EndpointDirectionIn, TransferType: TransferTypeInterrupt},
+		},
+	}
+	for _, tc := range []struct {
+		name   string
+		find   func() (EndpointDesc, bool)
+		want   EndpointAddress
+		wantOK bool
+	}{
+		{"BulkIn", alt.BulkIn, 0x82, true},
+		{"BulkOut", alt.BulkOut, 0x01, true},
+		{"InterruptIn", alt.InterruptIn, 0x83, true},
+		{"InterruptOut", alt.InterruptOut, 0, false},
+		{"IsoIn", alt.IsoIn, 0, false},
+		{"IsoOut", alt.IsoOut, 0, false},
+	} {
+		got, ok := tc.find()
+		if ok != tc.wantOK || got.Address != tc.want {
+			t.Errorf("%s(): got %s, %v, want %s, %v", tc.name, got.Address, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestEndpointConcurrentTransfers(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+
+	lib.mu.Lock()
+	lib.controlFn = func(_, _ uint8, _, _ uint16, data []byte) (int, error) {
+		return len(data), nil
+	}
+	lib.mu.Unlock()
+
+	// The fake device completes every transfer, in a separate goroutine
+	// each, so that the transfers finish out of order.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			ft := lib.waitForSubmitted(stop)
+			if ft == nil {
+				return
+			}
+			go func() {
+				if ft.ep.Direction == EndpointDirectionIn {
+					ft.setData([]byte{byte(ft.ep.Address)})
+				} else {
+					ft.setLength(len(ft.buf))
+				}
+				ft.setStatus(TransferCompleted)
+			}()
+		}
+	}()
+
+	const workers, transfers = 4, 50
+	errs := make(chan error, 3*workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(5)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 64)
+			for j := 0; j < transfers; j++ {
+				if n, err := in.Read(buf); err != nil || n != 1 || buf[0] != 0x82 {
+					errs <- fmt.Errorf("%s.Read(): got %v, %v, want [0x82], nil", in, buf[:n], err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 64)
+			for j := 0; j < transfers; j++ {
+				if n, err := out.Write(buf); err != nil || n != len(buf) {
+					errs <- fmt.Errorf("%s.Write(): got %d, %v, want %d, nil", out, n, err, len(buf))
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 8)
+			for j := 0; j < transfers; j++ {
+				if n, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 1, 0, 0, buf); err != nil || n != len(buf) {
+					errs <- fmt.Errorf("%s.Control(): got %d, %v, want %d, nil", dev, n, err, len(buf))
+					return
+				}
+			}
+		}()
+		// Endpoint settings may be changed while transfers are in
+		// flight, they apply to the transfers started afterwards.
+		go func() {
+			defer wg.Done()
+			for j := 0; j < transfers; j++ {
+				in.SetShortNotOK(j%2 == 0)
+				in.SetReadDeadline(time.Now().Add(time.Hour))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < transfers; j++ {
+				out.SetZeroPacket(j%2 == 0)
+				out.SetWriteDeadline(time.Time{})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	key := dev.String() + ",ep=0x82"
+	if got, want := ctx.TransferMetrics()[key].Transfers, int64(workers*transfers); got != want {
+		t.Errorf("TransferMetrics()[%q].Transfers: got %d, want %d", key, got, want)
+	}
+}
+
+func TestEndpointCancelAll(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	read := func() <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			n, err := in.Read(make([]byte, 64))
+			ch <- result{n, err}
+		}()
+		// The device doesn't respond, the transfer stays in flight.
+		lib.waitForSubmitted(nil)
+		return ch
+	}
+
+	ch := read()
+	in.CancelAll()
+	if r := <-ch; r.err != TransferCancelled || r.n != 0 {
+		t.Errorf("%s.Read() after CancelAll(): got %d, %v, want 0, %v", in, r.n, r.err, TransferCancelled)
+	}
+
+	ch = read()
+	intf.Close()
+	if r := <-ch; r.err != TransferCancelled {
+		t.Errorf("%s.Read() after Interface.Close(): got %d, %v, want 0, %v", in, r.n, r.err, TransferCancelled)
+	}
+}
+
+func TestEndpointWriteV(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		bufs    [][]byte
+		accept  int
+		want    []byte
+		wantErr error
+	}{
+		{
+			desc:   "header and payload",
+			bufs:   [][]byte{{1, 2}, {3, 4, 5}, nil, {6}},
+			accept: 6,
+			want:   []byte{1, 2, 3, 4, 5, 6},
+		},
+		{
+			desc:    "short write",
+			bufs:    [][]byte{{1, 2}, {3, 4}},
+			accept:  3,
+			want:    []byte{1, 2, 3, 4},
+			wantErr: io.ErrShortWrite,
+		},
+		{
+			desc: "zero length packet",
+		},
+	} {
+		got := make(chan []byte, 1)
+		go func() {
+			ft := lib.waitForSubmitted(nil)
+			ft.mu.Lock()
+			got <- append([]byte{}, ft.buf...)
+			ft.mu.Unlock()
+			ft.setLength(tc.accept)
+			ft.setStatus(TransferCompleted)
+		}()
+		n, err := out.WriteV(tc.bufs)
+		if n != tc.accept || err != tc.wantErr {
+			t.Errorf("%s: %s.WriteV(): got %d, %v, want %d, %v", tc.desc, out, n, err, tc.accept, tc.wantErr)
+		}
+		if data := <-got; !bytes.Equal(data, tc.want) {
+			t.Errorf("%s: %s.WriteV(): device got % x, want % x", tc.desc, out, data, tc.want)
+		}
+	}
+}
+
+func TestEndpointSoftwareZeroPacket(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	// Pretend libusb can't add the zero-length packet.
+	out.softZLP = true
+	out.SetZeroPacket(true)
+
+	type xfer struct {
+		length int
+		flags  transferFlags
+	}
+	for _, tc := range []struct {
+		length int
+		want   []xfer
+	}{
+		{length: 512, want: []xfer{{512, 0}, {0, 0}}},
+		{length: 100, want: []xfer{{100, 0}}},
+		{length: 0, want: []xfer{{0, 0}}},
+	} {
+		got := make(chan []xfer, 1)
+		go func() {
+			var xs []xfer
+			for range tc.want {
+				ft := lib.waitForSubmitted(nil)
+				xs = append(xs, xfer{len(ft.buf), ft.flags})
+				ft.setLength(len(ft.buf))
+				ft.setStatus(TransferCompleted)
+			}
+			got <- xs
+		}()
+		if n, err := out.Write(make([]byte, tc.length)); n != tc.length || err != nil {
+			t.Errorf("%s.Write(%d bytes): got %d, %v, want %d, nil", out, tc.length, n, err, tc.length)
+		}
+		if xs := <-got; !reflect.DeepEqual(xs, tc.want) {
+			t.Errorf("%s.Write(%d bytes): got transfers %v, want %v", out, tc.length, xs, tc.want)
+		}
+		if !lib.empty() {
+			t.Errorf("%s.Write(%d bytes): unexpected transfers pending", out, tc.length)
+		}
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	// The fake transfers carry at most one 512 byte packet each, read
+	// the message one packet at a time.
+	in.SetMaxTransferSize(512)
+	full := bytes.Repeat([]byte{0xaa}, 512)
+	for _, tc := range []struct {
+		desc    string
+		packets [][]byte
+		status  TransferStatus
+		want    int
+		wantErr bool
+	}{
+		{desc: "short packet", packets: [][]byte{full, full, {1, 2, 3}}, want: 1027},
+		{desc: "zero length packet", packets: [][]byte{full, {}}, want: 512},
+		{desc: "single short packet", packets: [][]byte{{1}}, want: 1},
+		{desc: "error", packets: [][]byte{full, {1}}, status: TransferStall, want: 513, wantErr: true},
+	} {
+		go func() {
+			for i, p := range tc.packets {
+				ft := lib.waitForSubmitted(nil)
+				ft.setData(p)
+				st := TransferCompleted
+				if i == len(tc.packets)-1 {
+					st = tc.status
+				}
+				ft.setStatus(st)
+			}
+		}()
+		msg, err := in.ReadMessage()
+		if len(msg) != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("%s: %s.ReadMessage(): got %d bytes, %v, want %d bytes, error: %v", tc.desc, in, len(msg), err, tc.want, tc.wantErr)
+		}
+		if !lib.empty() {
+			t.Errorf("%s: %s.ReadMessage(): unexpected transfers pending", tc.desc, in)
+		}
+	}
+}
@@ -19,43 +19,33 @@ import (
 	"fmt"
 )
 
-// #include <libusb.h>
-import "C"
-
 // Error is an error code from a USB operation. See the list of Error constants below.
-type Error C.int
+// The values are the error codes of libusb.
+type Error int
 
 // Error implements the error interface.
 func (e Error) Error() string {
 	return fmt.Sprintf("libusb: %s [code %d]", errorString[e], e)
 }
 
-func fromErrNo(errno C.int) error {
-	err := Error(errno)
-	if err == Success {
-		return nil
-	}
-	return err
-}
-
 // Defined result codes.
 const (
-	Success           Error = C.LIBUSB_SUCCESS
-	ErrorIO           Error = C.LIBUSB_ERROR_IO
-	ErrorInvalidParam Error = C.LIBUSB_ERROR_INVALID_PARAM
-	ErrorAccess       Error = C.LIBUSB_ERROR_ACCESS
-	ErrorNoDevice     Error = C.LIBUSB_ERROR_NO_DEVICE
-	ErrorNotFound     Error = C.LIBUSB_ERROR_NOT_FOUND
-	ErrorBusy         Error = C.LIBUSB_ERROR_BUSY
-	ErrorTimeout      Error = C.LIBUSB_ERROR_TIMEOUT
+	Success           Error = 0
+	ErrorIO           Error = -1
+	ErrorInvalidParam Error = -2
+	ErrorAccess       Error = -3
+	ErrorNoDevice     Error = -4
+	ErrorNotFound     Error = -5
+	ErrorBusy         Error = -6
+	ErrorTimeout      Error = -7
 	// ErrorOverflow indicates that the device tried to send more data than was
 	// requested and that could fit in the packet buffer.
-	ErrorOverflow     Error = C.LIBUSB_ERROR_OVERFLOW
-	ErrorPipe         Error = C.LIBUSB_ERROR_PIPE
-	ErrorInterrupted  Error = C.LIBUSB_ERROR_INTERRUPTED
-	ErrorNoMem        Error = C.LIBUSB_ERROR_NO_MEM
-	ErrorNotSupported Error = C.LIBUSB_ERROR_NOT_SUPPORTED
-	ErrorOther        Error = C.LIBUSB_ERROR_OTHER
+	ErrorOverflow     Error = -8
+	ErrorPipe         Error = -9
+	ErrorInterrupted  Error = -10
+	ErrorNoMem        Error = -11
+	ErrorNotSupported Error = -12
+	ErrorOther        Error = -99
 )
 
 var errorString = map[Error]string{
@@ -80,13 +70,13 @@ type TransferStatus uint8
 
 // Defined Transfer status values.
 const (
-	TransferCompleted TransferStatus = C.LIBUSB_TRANSFER_COMPLETED
-	TransferError     TransferStatus = C.LIBUSB_TRANSFER_ERROR
-	TransferTimedOut  TransferStatus = C.LIBUSB_TRANSFER_TIMED_OUT
-	TransferCancelled TransferStatus = C.LIBUSB_TRANSFER_CANCELLED
-	TransferStall     TransferStatus = C.LIBUSB_TRANSFER_STALL
-	TransferNoDevice  TransferStatus = C.LIBUSB_TRANSFER_NO_DEVICE
-	TransferOverflow  TransferStatus = C.LIBUSB_TRANSFER_OVERFLOW
+	TransferCompleted TransferStatus = 0
+	TransferError     TransferStatus = 1
+	TransferTimedOut  TransferStatus = 2
+	TransferCancelled TransferStatus = 3
+	TransferStall     TransferStatus = 4
+	TransferNoDevice  TransferStatus = 5
+	TransferOverflow  TransferStatus = 6
 )
 
 var transferStatusDescription = map[TransferStatus]string{
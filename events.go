@@ -0,0 +1,81 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultEventTick is the default value of ContextOptions.EventTick.
+const defaultEventTick = 100 * time.Millisecond
+
+// HandleEvents handles libusb events, i.e. completes transfers and
+// dispatches hotplug notifications, until ctx is done or the Context is
+// closed. It's needed only if the Context was created with
+// ContextOptions.ManualEvents, which allows running event handling on
+// a goroutine chosen by the program, e.g. one locked to an OS thread
+// with elevated priority. HandleEvents returns ctx.Err() if ctx is done
+// and nil if the Context was closed.
+//
+// HandleEvents can be called from several goroutines, libusb lets one of
+// them handle the events at a time.
+func (c *Context) HandleEvents(ctx context.Context) error {
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return errors.New("HandleEvents called on a closed Context")
+	default:
+	}
+	c.events.Add(1)
+	c.mu.Unlock()
+	defer c.events.Done()
+
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.done:
+		case <-finished:
+			return
+		}
+		close(stop)
+		c.libusb.interruptEvents(c.ctx)
+	}()
+	c.libusb.handleEvents(c.ctx, c.eventTick, stop)
+	return ctx.Err()
+}
+
+// InterruptEvents wakes up the goroutines waiting for events in
+// HandleEvents or in the event handling goroutine of the Context, e.g.
+// to make them notice a change sooner than at the next EventTick.
+// Requires libusb >= 1.0.21, with older versions it has no effect.
+func (c *Context) InterruptEvents() {
+	c.libusb.interruptEvents(c.ctx)
+}
+
+// stopEvents stops all event handlers of the Context and waits for them
+// to return.
+func (c *Context) stopEvents() {
+	c.mu.Lock()
+	close(c.done)
+	c.mu.Unlock()
+	c.libusb.interruptEvents(c.ctx)
+	c.events.Wait()
+}
@@ -30,14 +30,16 @@ var fakeDevices = []fakeDevice{
 			Bus:      1,
 			Address:  1,
 			Port:     1,
+			Path:     []int{1},
 			Spec:     Version(2, 0),
 			Device:   Version(1, 0),
 			Vendor:   ID(0x9999),
 			Product:  ID(0x0001),
 			Protocol: 255,
 			Configs: map[int]ConfigDesc{1: {
-				Number:   1,
-				MaxPower: Milliamperes(100),
+				Number:      1,
+				MaxPower:    Milliamperes(100),
+				MaxPowerRaw: 50,
 				Interfaces: []InterfaceDesc{{
 					Number: 0,
 					AltSettings: []InterfaceSetting{{
@@ -74,6 +76,7 @@ var fakeDevices = []fakeDevice{
 			Bus:      1,
 			Address:  2,
 			Port:     2,
+			Path:     []int{2},
 			Spec:     Version(2, 0),
 			Device:   Version(1, 3),
 			Vendor:   ID(0x8888),
@@ -82,6 +85,7 @@ var fakeDevices = []fakeDevice{
 			Configs: map[int]ConfigDesc{1: {
 				Number:         1,
 				MaxPower:       Milliamperes(100),
+				MaxPowerRaw:    50,
 				iConfiguration: 5,
 				Interfaces: []InterfaceDesc{{
 					Number: 0,
@@ -195,14 +199,16 @@ var fakeDevices = []fakeDevice{
 			Bus:      1,
 			Address:  3,
 			Port:     3,
+			Path:     []int{3},
 			Spec:     Version(2, 0),
 			Device:   Version(1, 0),
 			Vendor:   ID(0x1111),
 			Product:  ID(0x1111),
 			Protocol: 255,
 			Configs: map[int]ConfigDesc{1: {
-				Number:   1,
-				MaxPower: Milliamperes(100),
+				Number:      1,
+				MaxPower:    Milliamperes(100),
+				MaxPowerRaw: 50,
 				Interfaces: []InterfaceDesc{{
 					Number: 0,
 					AltSettings: []InterfaceSetting{{
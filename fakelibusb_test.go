@@ -44,6 +44,10 @@ type fakeTransfer struct {
 	isoPackets int
 	// maxLength is the maximum number of bytes this transfer could contain
 	maxLength int
+	// flags are the libusb transfer flags the transfer was allocated with.
+	flags transferFlags
+	// isoResults are the results of individual isochronous packets.
+	isoResults []isoPacketResult
 }
 
 func (t *fakeTransfer) setData(d []byte) {
@@ -65,6 +69,16 @@ func (t *fakeTransfer) setLength(n int) {
 	t.length = n
 }
 
+// setIsoPackets sets the results of the individual isochronous packets.
+func (t *fakeTransfer) setIsoPackets(r []isoPacketResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	t.isoResults = r
+}
+
 func (t *fakeTransfer) setStatus(st TransferStatus) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -96,12 +110,66 @@ type fakeLibusb struct {
 	handles map[*libusbDevHandle]*libusbDevice
 	// claims is a map of devices to a set of claimed interfaces
 	claims map[*libusbDevice]map[uint8]bool
+	// opts are the options the context was initialized with.
+	opts ContextOptions
+	// debug is the last debug level set on the context.
+	debug int
+	// resetErr is returned by reset.
+	resetErr error
+	// openErr is returned by open.
+	openErr error
+	// claimErr is returned by claim.
+	claimErr error
+	// busyClaims is the number of the following claims that fail with
+	// ErrorBusy.
+	busyClaims int
+	// claimCalls counts the calls to claim.
+	claimCalls int
+	// detached is the set of interfaces with a detached kernel driver.
+	detached map[uint8]bool
+	// noDetach makes setAutoDetach fail like on platforms without
+	// CapabilityDetachKernelDriver.
+	noDetach bool
+	// unconfigured makes getConfig report that no config is active.
+	unconfigured bool
+	// stringReads counts the calls to getStringDesc.
+	stringReads int
+	// maxTransferCalls counts the calls to maxTransferSize.
+	maxTransferCalls int
+	// interrupts counts the calls to interruptEvents.
+	interrupts int
+	// handlers is the number of running handleEvents calls.
+	handlers int
+	// controlFn, if set, handles the synchronous control requests.
+	controlFn func(rType, request uint8, val, idx uint16, data []byte) (int, error)
+	// controlTimeout is the timeout of the last synchronous control request.
+	controlTimeout time.Duration
 }
 
-func (f *fakeLibusb) init() (*libusbContext, error)                       { return newContextPointer(), nil }
-func (f *fakeLibusb) handleEvents(c *libusbContext, done <-chan struct{}) { <-done }
+func (f *fakeLibusb) init(opts ContextOptions) (*libusbContext, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opts = opts
+	return newContextPointer(), nil
+}
+func (f *fakeLibusb) handleEvents(c *libusbContext, _ time.Duration, done <-chan struct{}) {
+	f.mu.Lock()
+	f.handlers++
+	f.mu.Unlock()
+	<-done
+	f.mu.Lock()
+	f.handlers--
+	f.mu.Unlock()
+}
+func (f *fakeLibusb) interruptEvents(*libusbContext) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.interrupts++
+}
 func (f *fakeLibusb) getDevices(*libusbContext) ([]*libusbDevice, error) {
-	ret := make([]*libusbDevice, 0, len(fakeDevices))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ret := make([]*libusbDevice, 0, len(f.fakeDevices))
 	for d := range f.fakeDevices {
 		ret = append(ret, d)
 	}
@@ -119,32 +187,98 @@ func (f *fakeLibusb) exit(*libusbContext) error {
 	return nil
 }
 
-func (f *fakeLibusb) setDebug(*libusbContext, int) {}
-func (f *fakeLibusb) dereference(d *libusbDevice)  {}
+func (f *fakeLibusb) setDebug(_ *libusbContext, lvl int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.debug = lvl
+}
+
+func (f *fakeLibusb) getVersion() LibusbVersion {
+	return LibusbVersion{Major: 1, Minor: 0, Micro: 26, Nano: 11724}
+}
+
+func (f *fakeLibusb) hasCapability(c Capability) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return c == CapabilityHasCapability || c == CapabilityDetachKernelDriver && !f.noDetach
+}
+
+// registerHotplug reports no native hotplug support, like libusb on Windows.
+func (f *fakeLibusb) registerHotplug(*libusbContext, func(*libusbDevice, HotplugEventType)) (func(), error) {
+	return nil, ErrorNotSupported
+}
+
+func (f *fakeLibusb) dereference(d *libusbDevice) {}
 func (f *fakeLibusb) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if dev, ok := f.fakeDevices[d]; ok {
 		return dev.devDesc, nil
 	}
 	return nil, fmt.Errorf("invalid USB device %p", d)
 }
 func (f *fakeLibusb) open(d *libusbDevice) (*libusbDevHandle, error) {
-	h := newDevHandlePointer()
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	h := newDevHandlePointer()
 	f.handles[h] = d
 	return h, nil
 }
 
+// wrapSysDevice treats fd as an index into the fakeDevices list.
+func (f *fakeLibusb) wrapSysDevice(_ *libusbContext, fd uintptr) (*libusbDevHandle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fd >= uintptr(len(fakeDevices)) {
+		return nil, ErrorNoDevice
+	}
+	for d, fd2 := range f.fakeDevices {
+		if fd2.devDesc == fakeDevices[fd].devDesc {
+			h := newDevHandlePointer()
+			f.handles[h] = d
+			return h, nil
+		}
+	}
+	return nil, ErrorNoDevice
+}
+
+func (f *fakeLibusb) getDevice(h *libusbDevHandle) *libusbDevice {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.handles[h]
+}
+
 func (f *fakeLibusb) close(h *libusbDevHandle) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	delete(f.handles, h)
 }
-func (f *fakeLibusb) reset(*libusbDevHandle) error { return nil }
-func (f *fakeLibusb) control(*libusbDevHandle, time.Duration, uint8, uint8, uint16, uint16, []byte) (int, error) {
-	return 0, errors.New("not implemented")
+func (f *fakeLibusb) reset(*libusbDevHandle) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resetErr
+}
+func (f *fakeLibusb) control(_ *libusbDevHandle, timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	f.mu.Lock()
+	f.controlTimeout = timeout
+	fn := f.controlFn
+	f.mu.Unlock()
+	if fn == nil {
+		return 0, errors.New("not implemented")
+	}
+	return fn(rType, request, val, idx, data)
+}
+func (f *fakeLibusb) getConfig(*libusbDevHandle) (uint8, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.unconfigured {
+		return 0, nil
+	}
+	return 1, nil
 }
-func (f *fakeLibusb) getConfig(*libusbDevHandle) (uint8, error) { return 1, nil }
 func (f *fakeLibusb) setConfig(d *libusbDevHandle, cfg uint8) error {
 	debug.Printf("setConfig(%p, %d)\n", d, cfg)
 	f.mu.Lock()
@@ -158,6 +292,9 @@ func (f *fakeLibusb) setConfig(d *libusbDevHandle, cfg uint8) error {
 	return nil
 }
 func (f *fakeLibusb) getStringDesc(d *libusbDevHandle, index int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stringReads++
 	dev, ok := f.fakeDevices[f.handles[d]]
 	if !ok {
 		return "", fmt.Errorf("invalid USB device %p", d)
@@ -168,14 +305,48 @@ func (f *fakeLibusb) getStringDesc(d *libusbDevHandle, index int) (string, error
 	}
 	return str, nil
 }
-func (f *fakeLibusb) setAutoDetach(*libusbDevHandle, int) error { return nil }
+func (f *fakeLibusb) setAutoDetach(*libusbDevHandle, int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.noDetach {
+		return ErrorNotSupported
+	}
+	return nil
+}
+
+func (f *fakeLibusb) detachKernelDriver(d *libusbDevHandle, intf uint8) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.detached == nil {
+		f.detached = make(map[uint8]bool)
+	}
+	f.detached[intf] = true
+	return nil
+}
+func (f *fakeLibusb) attachKernelDriver(d *libusbDevHandle, intf uint8) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.detached, intf)
+	return nil
+}
 
-func (f *fakeLibusb) detachKernelDriver(*libusbDevHandle, uint8) error { return nil }
+func (f *fakeLibusb) devMemAlloc(_ *libusbDevHandle, n int) ([]byte, error) {
+	return make([]byte, n), nil
+}
+func (f *fakeLibusb) devMemFree(*libusbDevHandle, []byte) error { return nil }
 
 func (f *fakeLibusb) claim(d *libusbDevHandle, intf uint8) error {
 	debug.Printf("claim(%p, %d)\n", d, intf)
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	f.claimCalls++
+	if f.claimErr != nil {
+		return f.claimErr
+	}
+	if f.busyClaims > 0 {
+		f.busyClaims--
+		return ErrorBusy
+	}
 	c := f.claims[f.handles[d]]
 	if c == nil {
 		c = make(map[uint8]bool)
@@ -205,26 +376,37 @@ func (f *fakeLibusb) setAlt(d *libusbDevHandle, intf, alt uint8) error {
 	return nil
 }
 
-func (f *fakeLibusb) alloc(_ *libusbDevHandle, ep *EndpointDesc, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
+func (f *fakeLibusb) alloc(_ *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
+	return f.allocFake(ep, flags, isoPackets, make([]byte, bufLen), done)
+}
+func (f *fakeLibusb) allocWithBuffer(_ *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, buf []byte, done chan struct{}) (*libusbTransfer, error) {
+	return f.allocFake(ep, flags, isoPackets, buf, done)
+}
+func (f *fakeLibusb) allocFake(ep *EndpointDesc, flags transferFlags, isoPackets int, buf []byte, done chan struct{}) (*libusbTransfer, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	maxLen := ep.MaxPacketSize
+	if ep.TransferType == TransferTypeControl {
+		// Control transfers hold the setup packet and the whole data stage.
+		maxLen = len(buf)
+	}
 	if isoPackets > 0 {
 		if ep.TransferType != TransferTypeIsochronous {
 			return nil, fmt.Errorf("alloc(..., ep: %s, isoPackets: %d, ...): endpoint is not an isochronous type endpoint, iso packets must be 0", ep, isoPackets)
 		}
 		maxLen = isoPackets * ep.MaxPacketSize
 	}
-	if bufLen > maxLen {
-		bufLen = maxLen
+	if len(buf) > maxLen {
+		buf = buf[:maxLen]
 	}
 	t := newFakeTransferPointer()
 	f.ts[t] = &fakeTransfer{
-		buf:        make([]byte, bufLen),
+		buf:        buf,
 		ep:         ep,
 		isoPackets: isoPackets,
 		maxLength:  maxLen,
 		done:       done,
+		flags:      flags,
 	}
 	return t, nil
 }
@@ -258,6 +440,21 @@ func (f *fakeLibusb) free(t *libusbTransfer) {
 	defer f.mu.Unlock()
 	delete(f.ts, t)
 }
+func (f *fakeLibusb) isoPackets(t *libusbTransfer) ([]isoPacketResult, TransferStatus) {
+	f.mu.Lock()
+	ft := f.ts[t]
+	f.mu.Unlock()
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	return ft.isoResults, ft.status
+}
+func (f *fakeLibusb) maxTransferSize() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxTransferCalls++
+	return 0
+}
+
 func (f *fakeLibusb) setIsoPacketLengths(t *libusbTransfer, length uint32) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -281,6 +478,24 @@ func (f *fakeLibusb) waitForSubmitted(done <-chan struct{}) *fakeTransfer {
 	}
 }
 
+// plug attaches a new fake device to the fake USB stack.
+func (f *fakeLibusb) plug(d fakeDevice) *libusbDevice {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dev := newDevicePointer()
+	fd := new(fakeDevice)
+	*fd = d
+	f.fakeDevices[dev] = fd
+	return dev
+}
+
+// unplug detaches a fake device from the fake USB stack.
+func (f *fakeLibusb) unplug(dev *libusbDevice) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.fakeDevices, dev)
+}
+
 // empty can be used to confirm that all transfers were cleaned up.
 func (f *fakeLibusb) empty() bool {
 	return len(f.submitted) == 0
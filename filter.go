@@ -0,0 +1,202 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The functions below return predicates over device descriptors, for use
+// with OpenDevices, ListDevices, WaitForDevice and RegisterHotplugMatch.
+// They are evaluated before the device is opened.
+
+// MatchAll returns a predicate that matches the devices matched by all
+// of the predicates.
+func MatchAll(preds ...func(desc *DeviceDesc) bool) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		for _, p := range preds {
+			if !p(desc) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchVIDPID returns a predicate that matches devices with the vendor
+// and product IDs. A zero pid matches all products of the vendor.
+func MatchVIDPID(vid, pid ID) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		return desc.Vendor == vid && (pid == 0 || desc.Product == pid)
+	}
+}
+
+// MatchClass returns a predicate that matches devices with the given
+// device class.
+func MatchClass(c Class) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		return desc.Class == c
+	}
+}
+
+// MatchInterfaceClass returns a predicate that matches devices with
+// an interface of the given class in any configuration or alternate
+// setting.
+func MatchInterfaceClass(c Class) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		for _, cfg := range desc.Configs {
+			for _, intf := range cfg.Interfaces {
+				for _, alt := range intf.AltSettings {
+					if alt.Class == c {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
+
+// MatchBus returns a predicate that matches devices on the given bus.
+func MatchBus(bus int) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		return desc.Bus == bus
+	}
+}
+
+// MatchPortPath returns a predicate that matches the device attached at
+// the port path in the format used by Linux sysfs, e.g. "1-2.4" for
+// port 4 of the hub attached to port 2 of the root hub of bus 1.
+func MatchPortPath(path string) func(desc *DeviceDesc) bool {
+	return func(desc *DeviceDesc) bool {
+		return len(desc.Path) > 0 && desc.portPath() == path
+	}
+}
+
+// sysfsSerial returns the serial number of the device as reported by
+// Linux sysfs, without opening the device. ok is false if sysfs doesn't
+// know the serial number.
+func sysfsSerial(desc *DeviceDesc) (serial string, ok bool) {
+	if runtime.GOOS != "linux" || len(desc.Path) == 0 {
+		return "", false
+	}
+	b, err := ioutil.ReadFile(filepath.Join(sysfsUSBDevices, desc.portPath(), "serial"))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(b)), true
+}
+
+// OpenDeviceWithSerial opens the device with the serial number among
+// the devices matched by match, which can be nil to consider all devices.
+// It returns nil and a nil error if there's no such device.
+//
+// The serial number is not part of the device descriptor. On Linux it's
+// read from sysfs, elsewhere each matching device is opened briefly to
+// read its serial number, so a narrow match makes the lookup cheaper.
+func (c *Context) OpenDeviceWithSerial(serial string, match func(desc *DeviceDesc) bool) (*Device, error) {
+	refs, err := c.ListDevices(match)
+	defer func() {
+		for _, r := range refs {
+			r.Release()
+		}
+	}()
+	for _, r := range refs {
+		if r.Desc.iSerialNumber == 0 {
+			continue
+		}
+		if s, ok := sysfsSerial(r.Desc); ok && s != serial {
+			continue
+		}
+		dev, openErr := r.Open()
+		if openErr != nil {
+			err = openErr
+			continue
+		}
+		s, serr := dev.SerialNumber()
+		if serr == nil && s == serial {
+			return dev, nil
+		}
+		dev.Close()
+		if serr != nil {
+			err = serr
+		}
+	}
+	return nil, err
+}
+
+// ParsePortPath parses a port path in the format used by Linux sysfs,
+// e.g. "3-1.4.2" for port 2 of the hub attached to port 4 of the hub
+// attached to port 1 of the root hub of bus 3, into the bus number and
+// the chain of port numbers, here 3 and [1 4 2].
+func ParsePortPath(path string) (bus int, ports []int, err error) {
+	i := strings.IndexByte(path, '-')
+	if i < 0 {
+		return 0, nil, fmt.Errorf("invalid port path %q, want bus-port[.port...]", path)
+	}
+	if bus, err = strconv.Atoi(path[:i]); err != nil || bus < 0 {
+		return 0, nil, fmt.Errorf("invalid bus number in port path %q", path)
+	}
+	for _, p := range strings.Split(path[i+1:], ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n <= 0 || n > 255 {
+			return 0, nil, fmt.Errorf("invalid port number %q in port path %q", p, path)
+		}
+		ports = append(ports, n)
+	}
+	return bus, ports, nil
+}
+
+// OpenPath opens the device attached at the port path, in the format used
+// by Linux sysfs, e.g. "3-1.4.2", see ParsePortPath. Unlike the vendor
+// and product IDs, the path tells apart identical devices without serial
+// numbers. It returns nil and a nil error if no device is attached there.
+func (c *Context) OpenPath(path string) (*Device, error) {
+	bus, ports, err := ParsePortPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.OpenPortChain(bus, ports...)
+}
+
+// OpenPortChain is like OpenPath, with the path given as the bus number
+// and the port numbers from the root hub down to the device.
+func (c *Context) OpenPortChain(bus int, ports ...int) (*Device, error) {
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("OpenPortChain: no port numbers given for bus %d", bus)
+	}
+	var found bool
+	devs, err := c.OpenDevices(func(desc *DeviceDesc) bool {
+		if found || desc.Bus != bus || len(desc.Path) != len(ports) {
+			return false
+		}
+		for i, p := range ports {
+			if desc.Path[i] != p {
+				return false
+			}
+		}
+		found = true
+		return true
+	})
+	if len(devs) == 0 {
+		return nil, err
+	}
+	return devs[0], nil
+}
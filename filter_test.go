@@ -0,0 +1,141 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	desc := fakeDevices[1].devDesc // 8888:0002 at 1-2
+	for _, tc := range []struct {
+		name string
+		pred func(*DeviceDesc) bool
+		want bool
+	}{
+		{"MatchVIDPID(8888, 0002)", MatchVIDPID(0x8888, 0x0002), true},
+		{"MatchVIDPID(8888, 0)", MatchVIDPID(0x8888, 0), true},
+		{"MatchVIDPID(8888, 0001)", MatchVIDPID(0x8888, 0x0001), false},
+		{"MatchClass(per-interface)", MatchClass(ClassPerInterface), true},
+		{"MatchClass(hub)", MatchClass(ClassHub), false},
+		{"MatchInterfaceClass(vendor)", MatchInterfaceClass(ClassVendorSpec), true},
+		{"MatchInterfaceClass(HID)", MatchInterfaceClass(ClassHID), false},
+		{"MatchBus(1)", MatchBus(1), true},
+		{"MatchPortPath(1-2)", MatchPortPath("1-2"), true},
+		{"MatchPortPath(1-1)", MatchPortPath("1-1"), false},
+		{"MatchAll()", MatchAll(), true},
+		{"MatchAll(bus 1, 1-1)", MatchAll(MatchBus(1), MatchPortPath("1-1")), false},
+	} {
+		if got := tc.pred(desc); got != tc.want {
+			t.Errorf("%s(%s): got %v, want %v", tc.name, desc, got, tc.want)
+		}
+	}
+}
+
+func TestOpenDeviceWithSerial(t *testing.T) {
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	// Point sysfs lookups at a directory without devices, so that
+	// the serial numbers are read from the fake devices.
+	old := sysfsUSBDevices
+	sysfsUSBDevices = t.Name()
+	defer func() { sysfsUSBDevices = old }()
+
+	dev, err := ctx.OpenDeviceWithSerial("01234567", nil)
+	if err != nil || dev == nil {
+		t.Fatalf("OpenDeviceWithSerial(01234567): got %v, %v, want a device", dev, err)
+	}
+	if dev.Desc.Vendor != 0x8888 {
+		t.Errorf("OpenDeviceWithSerial(01234567): got %s, want 8888:0002", dev)
+	}
+	dev.Close()
+
+	dev, err = ctx.OpenDeviceWithSerial("76543210", MatchBus(1))
+	if dev != nil {
+		dev.Close()
+		t.Errorf("OpenDeviceWithSerial(76543210): got %s, want nil", dev)
+	}
+	if err != nil {
+		t.Errorf("OpenDeviceWithSerial(76543210): got error %v, want nil", err)
+	}
+	ctx.mu.Lock()
+	if n := len(ctx.devices); n != 0 {
+		t.Errorf("%d devices left open after OpenDeviceWithSerial, want none", n)
+	}
+	ctx.mu.Unlock()
+}
+
+func TestParsePortPath(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		bus   int
+		ports []int
+	}{
+		{"3-1", 3, []int{1}},
+		{"3-1.4.2", 3, []int{1, 4, 2}},
+	} {
+		bus, ports, err := ParsePortPath(tc.path)
+		if err != nil {
+			t.Errorf("ParsePortPath(%q): %v", tc.path, err)
+			continue
+		}
+		if bus != tc.bus || !reflect.DeepEqual(ports, tc.ports) {
+			t.Errorf("ParsePortPath(%q): got %d, %v, want %d, %v", tc.path, bus, ports, tc.bus, tc.ports)
+		}
+	}
+	for _, path := range []string{"", "3", "3-", "x-1", "3-1..2", "3-0", "3-1.256"} {
+		if _, _, err := ParsePortPath(path); err == nil {
+			t.Errorf("ParsePortPath(%q): got nil error, want non-nil", path)
+		}
+	}
+}
+
+func TestOpenPath(t *testing.T) {
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenPath("1-2")
+	if err != nil {
+		t.Fatalf("OpenPath(1-2): %v", err)
+	}
+	if dev == nil {
+		t.Fatal("OpenPath(1-2): got nil device")
+	}
+	if dev.Desc.Vendor != 0x8888 || dev.Desc.Product != 0x0002 {
+		t.Errorf("OpenPath(1-2): got device %s, want 8888:0002", dev)
+	}
+	dev.Close()
+
+	for _, path := range []string{"1-4", "2-2", "1-2.1"} {
+		if dev, err := ctx.OpenPath(path); dev != nil || err != nil {
+			if dev != nil {
+				dev.Close()
+			}
+			t.Errorf("OpenPath(%s): got %v, %v, want nil, nil", path, dev, err)
+		}
+	}
+	if _, err := ctx.OpenPath("1"); err == nil {
+		t.Error("OpenPath(1): got nil error, want non-nil")
+	}
+}
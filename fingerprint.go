@@ -0,0 +1,62 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// portPath returns the location of the device in the bus topology, in the
+// format used by Linux sysfs, e.g. "1-2.4" for bus 1, root hub port 2,
+// port 4 of the hub attached to it. If the path is not known, only the bus
+// number is returned, e.g. "1".
+func (d *DeviceDesc) portPath() string {
+	if len(d.Path) == 0 {
+		return strconv.Itoa(d.Bus)
+	}
+	ports := make([]string, len(d.Path))
+	for i, p := range d.Path {
+		ports[i] = strconv.Itoa(p)
+	}
+	return fmt.Sprintf("%d-%s", d.Bus, strings.Join(ports, "."))
+}
+
+// Fingerprint returns an identifier of the physical device that remains
+// the same across enumerations, unlike the bus address, which changes
+// every time the device is reattached.
+//
+// If the device reports a serial number, the fingerprint is composed of
+// the vendor and product IDs and the serial number, e.g.
+// "1d6b:0002:serial=0123ABC", and follows the device between ports.
+// Otherwise the port path is used instead, e.g. "1d6b:0002@1-2.4", which
+// identifies the device as long as it's attached to the same port.
+func (d *Device) Fingerprint() (string, error) {
+	if d.handle == nil {
+		return "", fmt.Errorf("Fingerprint() called on %s after Close", d)
+	}
+	id := fmt.Sprintf("%s:%s", d.Desc.Vendor, d.Desc.Product)
+	if d.Desc.iSerialNumber > 0 {
+		serial, err := d.SerialNumber()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the serial number of %s: %v", d, err)
+		}
+		if serial != "" {
+			return fmt.Sprintf("%s:serial=%s", id, serial), nil
+		}
+	}
+	return fmt.Sprintf("%s@%s", id, d.Desc.portPath()), nil
+}
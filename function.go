@@ -0,0 +1,220 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InterfaceAssociation is an interface association descriptor (IAD),
+// which groups consecutive interfaces of a configuration into a single
+// function.
+type InterfaceAssociation struct {
+	// FirstInterface is the number of the first interface of the function.
+	FirstInterface int
+	// InterfaceCount is the number of consecutive interfaces of the
+	// function.
+	InterfaceCount int
+	// Class, SubClass and Protocol identify the function.
+	Class    Class
+	SubClass Class
+	Protocol Protocol
+
+	iFunction int // index of a string descriptor describing the function
+}
+
+// interfaceAssociation has the fields of InterfaceAssociation, without its
+// methods.
+type interfaceAssociation InterfaceAssociation
+
+// MarshalJSON implements json.Marshaler. Along with the exported fields,
+// the encoded descriptor includes the index of the function string
+// descriptor.
+func (a InterfaceAssociation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		interfaceAssociation
+		IFunction int `json:"iFunction"`
+	}{interfaceAssociation(a), a.iFunction})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *InterfaceAssociation) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*interfaceAssociation
+		IFunction int `json:"iFunction"`
+	}{interfaceAssociation: (*interfaceAssociation)(a)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	a.iFunction = v.IFunction
+	return nil
+}
+
+// Function is a group of interfaces of a composite device that together
+// implement one function, e.g. the control and data interfaces of a CDC
+// ACM serial port, which a driver needs to claim together.
+type Function struct {
+	// Interfaces has the numbers of the interfaces of the function.
+	Interfaces []int
+	// Class, SubClass and Protocol identify the function. They come from
+	// the interface association descriptor, or from the first interface
+	// if the function was found by the class heuristics.
+	Class    Class
+	SubClass Class
+	Protocol Protocol
+	// Association is true if the function is defined by an interface
+	// association descriptor.
+	Association bool
+}
+
+// String returns a human-readable description of the function.
+func (f Function) String() string {
+	return fmt.Sprintf("%s function (interfaces %v)", f.Class, f.Interfaces)
+}
+
+// Functions groups the interfaces of the configuration into functions.
+// Interfaces covered by an interface association descriptor form one
+// function. The remaining interfaces are grouped by class heuristics for
+// devices without IADs: a communications interface with the data
+// interface following it, and an audio or video control interface with
+// the streaming interfaces following it. Every other interface is
+// a function by itself.
+func (c ConfigDesc) Functions() []Function {
+	var (
+		funcs   []Function
+		grouped = make(map[int]bool)
+		byNum   = make(map[int]InterfaceSetting)
+	)
+	for _, intf := range c.Interfaces {
+		if len(intf.AltSettings) > 0 {
+			byNum[intf.Number] = intf.AltSettings[0]
+		}
+	}
+	for _, a := range c.Associations {
+		f := Function{Class: a.Class, SubClass: a.SubClass, Protocol: a.Protocol, Association: true}
+		for n := a.FirstInterface; n < a.FirstInterface+a.InterfaceCount; n++ {
+			if _, ok := byNum[n]; ok && !grouped[n] {
+				f.Interfaces = append(f.Interfaces, n)
+				grouped[n] = true
+			}
+		}
+		if len(f.Interfaces) > 0 {
+			funcs = append(funcs, f)
+		}
+	}
+
+	// cur is the index of the function found by the heuristics that the
+	// next interface may belong to, or -1.
+	cur := -1
+	for _, intf := range c.Interfaces {
+		s, ok := byNum[intf.Number]
+		if !ok || grouped[intf.Number] {
+			cur = -1
+			continue
+		}
+		if cur >= 0 && belongsTo(funcs[cur], s) {
+			funcs[cur].Interfaces = append(funcs[cur].Interfaces, s.Number)
+			continue
+		}
+		funcs = append(funcs, Function{Interfaces: []int{s.Number}, Class: s.Class, SubClass: s.SubClass, Protocol: s.Protocol})
+		cur = len(funcs) - 1
+	}
+	return funcs
+}
+
+// belongsTo reports whether the interface with the setting s, following
+// the interfaces of the function f, is part of f.
+func belongsTo(f Function, s InterfaceSetting) bool {
+	switch {
+	case f.Class == ClassComm:
+		// A CDC function has a single data interface.
+		return s.Class == ClassData && len(f.Interfaces) == 1
+	case f.Class == ClassAudio && f.SubClass == SubClassAudioControl:
+		return s.Class == ClassAudio && s.SubClass != SubClassAudioControl
+	case f.Class == ClassVideo && f.SubClass == SubClassVideoControl:
+		return s.Class == ClassVideo && s.SubClass != SubClassVideoControl
+	}
+	return false
+}
+
+// Functions groups the interfaces of the configuration into functions,
+// see ConfigDesc.Functions. The interface association descriptors are
+// read from the device if the configuration descriptor doesn't have them,
+// falling back to the class heuristics if they can't be read.
+func (c *Config) Functions() []Function {
+	desc := c.Desc
+	if desc.Associations == nil && c.dev != nil {
+		if raw, err := c.dev.rawConfigDescriptor(desc.Number); err == nil {
+			if parsed, err := ParseConfigDescriptor(raw, c.dev.Desc.Speed); err == nil {
+				desc.Associations = parsed.Associations
+			}
+		}
+	}
+	return desc.Functions()
+}
+
+// ClaimFunction claims all interfaces of the function, in their first
+// alternate setting. If any of the interfaces can't be claimed, the ones
+// already claimed are released and an error is returned. Otherwise the
+// interfaces are returned in the order of f.Interfaces, along with
+// a function that releases all of them.
+func (c *Config) ClaimFunction(f Function) (intfs []*Interface, done func(), err error) {
+	if c.dev == nil {
+		return nil, nil, fmt.Errorf("ClaimFunction(%s) called on %s after Close", f, c)
+	}
+	release := func(intfs []*Interface) {
+		for i := len(intfs) - 1; i >= 0; i-- {
+			intfs[i].Close()
+		}
+	}
+	for _, num := range f.Interfaces {
+		alt := 0
+		for _, desc := range c.Desc.Interfaces {
+			if desc.Number == num && len(desc.AltSettings) > 0 {
+				alt = desc.AltSettings[0].Alternate
+			}
+		}
+		intf, err := c.Interface(num, alt)
+		if err != nil {
+			release(intfs)
+			return nil, nil, fmt.Errorf("failed to claim %s: %v", f, err)
+		}
+		intfs = append(intfs, intf)
+	}
+	return intfs, func() { release(intfs) }, nil
+}
+
+// rawConfigDescriptor reads the raw configuration descriptor with the
+// configuration number cfgNum from the device.
+func (d *Device) rawConfigDescriptor(cfgNum int) ([]byte, error) {
+	for idx := 0; idx < len(d.Desc.Configs); idx++ {
+		s := StandardRequest(EndpointDirectionIn, requestGetDescriptor).WithValue(rawDescTypeConfig<<8 | uint16(idx))
+		hdr := make([]byte, rawConfigDescSize)
+		n, err := d.ControlSetup(s, hdr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration descriptor %d of %s: %v", idx, d, err)
+		}
+		if n < rawConfigDescSize || int(hdr[5]) != cfgNum {
+			continue
+		}
+		buf := make([]byte, int(hdr[2])|int(hdr[3])<<8)
+		if n, err = d.ControlSetup(s, buf); err != nil {
+			return nil, fmt.Errorf("failed to read configuration descriptor %d of %s: %v", idx, d, err)
+		}
+		return buf[:n], nil
+	}
+	return nil, fmt.Errorf("%s has no configuration descriptor with number %d", d, cfgNum)
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testConfig(classes ...[2]Class) ConfigDesc {
+	c := ConfigDesc{Number: 1}
+	for i, cl := range classes {
+		c.Interfaces = append(c.Interfaces, InterfaceDesc{
+			Number:      i,
+			AltSettings: []InterfaceSetting{{Number: i, Class: cl[0], SubClass: cl[1]}},
+		})
+	}
+	return c
+}
+
+func TestFunctions(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		cfg  ConfigDesc
+		want []Function
+	}{{
+		desc: "CDC ACM and HID without IADs",
+		cfg:  testConfig([2]Class{ClassComm, 0x02}, [2]Class{ClassData, 0}, [2]Class{ClassHID, 0}),
+		want: []Function{
+			{Interfaces: []int{0, 1}, Class: ClassComm, SubClass: 0x02},
+			{Interfaces: []int{2}, Class: ClassHID},
+		},
+	}, {
+		desc: "two CDC functions without IADs",
+		cfg:  testConfig([2]Class{ClassComm, 0x02}, [2]Class{ClassData, 0}, [2]Class{ClassComm, 0x02}, [2]Class{ClassData, 0}, [2]Class{ClassData, 0}),
+		want: []Function{
+			{Interfaces: []int{0, 1}, Class: ClassComm, SubClass: 0x02},
+			{Interfaces: []int{2, 3}, Class: ClassComm, SubClass: 0x02},
+			{Interfaces: []int{4}, Class: ClassData},
+		},
+	}, {
+		desc: "audio and video without IADs",
+		cfg:  testConfig([2]Class{ClassAudio, 1}, [2]Class{ClassAudio, 2}, [2]Class{ClassAudio, 3}, [2]Class{ClassVideo, 1}, [2]Class{ClassVideo, 2}),
+		want: []Function{
+			{Interfaces: []int{0, 1, 2}, Class: ClassAudio, SubClass: 1},
+			{Interfaces: []int{3, 4}, Class: ClassVideo, SubClass: 1},
+		},
+	}, {
+		desc: "IAD and a vendor interface",
+		cfg: func() ConfigDesc {
+			c := testConfig([2]Class{ClassVendorSpec, 0}, [2]Class{ClassComm, 0x02}, [2]Class{ClassData, 0}, [2]Class{ClassVendorSpec, 0})
+			c.Associations = []InterfaceAssociation{{FirstInterface: 1, InterfaceCount: 2, Class: ClassComm, SubClass: 0x02, Protocol: 1}}
+			return c
+		}(),
+		want: []Function{
+			{Interfaces: []int{1, 2}, Class: ClassComm, SubClass: 0x02, Protocol: 1, Association: true},
+			{Interfaces: []int{0}, Class: ClassVendorSpec},
+			{Interfaces: []int{3}, Class: ClassVendorSpec},
+		},
+	}} {
+		if got := tc.cfg.Functions(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Functions():\ngot  %+v\nwant %+v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+// testIADConfig is a raw configuration descriptor in which an IAD groups
+// the interfaces 0 and 1 of fakeDevices[1].
+var testIADConfig = []byte{
+	0x09, 0x02, 0x2c, 0x00, 0x03, 0x01, 0x00, 0x80, 0x32,
+	0x08, 0x0b, 0x00, 0x02, 0xff, 0x00, 0x00, 0x07, // IAD, interfaces 0-1, iFunction 7
+	0x09, 0x04, 0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
+	0x09, 0x04, 0x01, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
+	0x09, 0x04, 0x03, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
+}
+
+func TestParseConfigDescriptorIAD(t *testing.T) {
+	c, err := ParseConfigDescriptor(testIADConfig, SpeedHigh)
+	if err != nil {
+		t.Fatalf("ParseConfigDescriptor(): %v", err)
+	}
+	want := []InterfaceAssociation{{FirstInterface: 0, InterfaceCount: 2, Class: ClassVendorSpec, iFunction: 7}}
+	if !reflect.DeepEqual(c.Associations, want) {
+		t.Errorf("Associations: got %+v, want %+v", c.Associations, want)
+	}
+	b, err := json.Marshal(c.Associations[0])
+	if err != nil {
+		t.Fatalf("json.Marshal(): %v", err)
+	}
+	var got InterfaceAssociation
+	if err := json.Unmarshal(b, &got); err != nil || got != want[0] {
+		t.Errorf("json.Unmarshal(%s): got (%+v, %v), want (%+v, nil)", b, got, err, want[0])
+	}
+	bad := append([]byte(nil), testIADConfig...)
+	bad[9] = 0x05
+	if _, err := ParseConfigDescriptor(bad, SpeedHigh); err == nil {
+		t.Error("ParseConfigDescriptor() with a short IAD: got nil error, want non-nil")
+	}
+}
+
+func TestClaimFunction(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(8888:0002): %v", err)
+	}
+	defer dev.Close()
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		if request != requestGetDescriptor || val != rawDescTypeConfig<<8 {
+			return 0, ErrorPipe
+		}
+		return copy(data, testIADConfig), nil
+	}
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+
+	funcs := cfg.Functions()
+	want := []Function{
+		{Interfaces: []int{0, 1}, Class: ClassVendorSpec, Association: true},
+		{Interfaces: []int{3}, Class: ClassVendorSpec},
+	}
+	if !reflect.DeepEqual(funcs, want) {
+		t.Fatalf("%s.Functions():\ngot  %+v\nwant %+v", cfg, funcs, want)
+	}
+
+	intfs, done, err := cfg.ClaimFunction(funcs[0])
+	if err != nil {
+		t.Fatalf("%s.ClaimFunction(%s): %v", cfg, funcs[0], err)
+	}
+	if len(intfs) != 2 || intfs[0].Setting.Number != 0 || intfs[1].Setting.Number != 1 {
+		t.Errorf("%s.ClaimFunction(%s): got interfaces %v, want 0 and 1", cfg, funcs[0], intfs)
+	}
+	done()
+
+	// A failure to claim one of the interfaces releases the others.
+	intf, err := cfg.Interface(1, 1)
+	if err != nil {
+		t.Fatalf("%s.Interface(1, 1): %v", cfg, err)
+	}
+	if _, _, err := cfg.ClaimFunction(funcs[0]); err == nil {
+		t.Errorf("%s.ClaimFunction(%s) with interface 1 claimed: got nil error, want non-nil", cfg, funcs[0])
+	}
+	intf.Close()
+	intf, err = cfg.Interface(0, 0)
+	if err != nil {
+		t.Errorf("%s.Interface(0, 0) after a failed ClaimFunction: %v", cfg, err)
+	} else {
+		intf.Close()
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fx2 loads firmware into the RAM of Cypress EZ-USB FX2 and FX2LP
+// microcontrollers, using the 0xA0 vendor request implemented by their
+// built-in boot loader, like the fxload tool.
+//
+// Typical use:
+//
+//	f, _ := os.Open("firmware.ihx")
+//	img, err := fx2.ReadHex(f)
+//	if err != nil { ... }
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x04b4, 0x8613)
+//	if err := fx2.Load(dev, img); err != nil { ... }
+//	dev.Close()
+//
+// The firmware usually makes the device re-enumerate with a different
+// identity, which can be awaited with Context.WaitForDevice.
+package fx2
+
+import (
+	"fmt"
+
+	"github.com/google/gousb"
+)
+
+const (
+	// requestFirmwareLoad reads and writes the memory of the
+	// microcontroller. The address is passed in wValue.
+	requestFirmwareLoad = 0xa0
+
+	// ChunkSize is the maximum length of a single memory write.
+	ChunkSize = 4096
+)
+
+// Addresses of the CPUCS register, which holds the 8051 core in reset.
+const (
+	// CPUCSFX2 is the CPUCS address of the FX2 and FX2LP.
+	CPUCSFX2 = 0xe600
+	// CPUCSAN21 is the CPUCS address of the original EZ-USB (AN21xx)
+	// and the FX (CY7C646xx).
+	CPUCSAN21 = 0x7f92
+)
+
+// Controller is the subset of *gousb.Device used to talk to the boot
+// loader.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// WriteRAM writes data to the memory of the microcontroller, starting at
+// addr. The boot loader can write the internal RAM, the 8051 core needs
+// to be held in reset while its program memory is written.
+func WriteRAM(c Controller, addr uint16, data []byte) error {
+	if int(addr)+len(data) > 0x10000 {
+		return fmt.Errorf("write of %d bytes at %#04x exceeds the address space", len(data), addr)
+	}
+	for len(data) > 0 {
+		n := len(data)
+		if n > ChunkSize {
+			n = ChunkSize
+		}
+		if _, err := c.Control(gousb.ControlOut|gousb.ControlVendor|gousb.ControlDevice, requestFirmwareLoad, addr, 0, data[:n]); err != nil {
+			return fmt.Errorf("failed to write %d bytes at %#04x: %v", n, addr, err)
+		}
+		addr += uint16(n)
+		data = data[n:]
+	}
+	return nil
+}
+
+// ReadRAM reads len(data) bytes of the memory of the microcontroller,
+// starting at addr.
+func ReadRAM(c Controller, addr uint16, data []byte) error {
+	if int(addr)+len(data) > 0x10000 {
+		return fmt.Errorf("read of %d bytes at %#04x exceeds the address space", len(data), addr)
+	}
+	for off := 0; off < len(data); {
+		n := len(data) - off
+		if n > ChunkSize {
+			n = ChunkSize
+		}
+		got, err := c.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, requestFirmwareLoad, addr+uint16(off), 0, data[off:off+n])
+		if err != nil {
+			return fmt.Errorf("failed to read %d bytes at %#04x: %v", n, int(addr)+off, err)
+		}
+		if got != n {
+			return fmt.Errorf("short read at %#04x: got %d bytes, want %d", int(addr)+off, got, n)
+		}
+		off += n
+	}
+	return nil
+}
+
+// Reset holds the 8051 core in reset if hold is true, or lets it run,
+// writing the CPUCS register at cpucs, e.g. CPUCSFX2. Releasing the reset
+// starts the firmware at address 0.
+func Reset(c Controller, cpucs uint16, hold bool) error {
+	v := byte(0)
+	if hold {
+		v = 1
+	}
+	return WriteRAM(c, cpucs, []byte{v})
+}
+
+// Load stops the 8051 core of an FX2 or FX2LP, writes img to its RAM and
+// starts it. The boot loader only reaches the internal RAM, images for the
+// external RAM need a second stage loader. Segments beyond the 16-bit
+// address space are rejected.
+func Load(c Controller, img *Image) error {
+	return LoadWithCPUCS(c, img, CPUCSFX2)
+}
+
+// LoadWithCPUCS is like Load, for microcontrollers with the CPUCS register
+// at cpucs.
+func LoadWithCPUCS(c Controller, img *Image, cpucs uint16) error {
+	for _, s := range img.Segments {
+		if s.Addr+uint32(len(s.Data)) > 0x10000 {
+			return fmt.Errorf("segment of %d bytes at %#x is outside of the 16-bit address space", len(s.Data), s.Addr)
+		}
+	}
+	if err := Reset(c, cpucs, true); err != nil {
+		return fmt.Errorf("failed to stop the CPU: %v", err)
+	}
+	for _, s := range img.Segments {
+		if err := WriteRAM(c, uint16(s.Addr), s.Data); err != nil {
+			return err
+		}
+	}
+	if err := Reset(c, cpucs, false); err != nil {
+		return fmt.Errorf("failed to start the CPU: %v", err)
+	}
+	return nil
+}
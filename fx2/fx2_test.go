@@ -0,0 +1,101 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fx2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/gousb"
+)
+
+// fakeFX2 emulates the boot loader of an FX2, recording the writes to
+// CPUCS.
+type fakeFX2 struct {
+	mem    [0x10000]byte
+	cpucs  []byte
+	writes int
+}
+
+func (f *fakeFX2) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if request != requestFirmwareLoad || rType&0x60 != gousb.ControlVendor || idx != 0 {
+		return 0, errors.New("unsupported request")
+	}
+	if len(data) > ChunkSize {
+		return 0, errors.New("transfer too long")
+	}
+	if rType&gousb.ControlIn != 0 {
+		return copy(data, f.mem[val:]), nil
+	}
+	if val == CPUCSFX2 {
+		f.cpucs = append(f.cpucs, data...)
+		return len(data), nil
+	}
+	if len(f.cpucs) == 0 || f.cpucs[len(f.cpucs)-1] != 1 {
+		return 0, errors.New("write to RAM with the CPU running")
+	}
+	f.writes++
+	return copy(f.mem[val:], data), nil
+}
+
+func TestLoad(t *testing.T) {
+	big := bytes.Repeat([]byte{0x5a}, ChunkSize+100)
+	img := &Image{Segments: []Segment{
+		{Addr: 0x0000, Data: []byte{0x02, 0x00, 0x10}},
+		{Addr: 0x0100, Data: big},
+	}}
+	f := &fakeFX2{}
+	if err := Load(f, img); err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if got, want := f.cpucs, []byte{1, 0}; !bytes.Equal(got, want) {
+		t.Errorf("CPUCS writes: got %v, want %v", got, want)
+	}
+	if got, want := f.writes, 3; got != want {
+		t.Errorf("RAM writes: got %d, want %d", got, want)
+	}
+	buf := make([]byte, len(big))
+	if err := ReadRAM(f, 0x0100, buf); err != nil {
+		t.Fatalf("ReadRAM(): %v", err)
+	}
+	if !bytes.Equal(buf, big) {
+		t.Error("ReadRAM(): the data differs from the loaded image")
+	}
+	if err := ReadRAM(f, 0x0000, buf[:3]); err != nil || !bytes.Equal(buf[:3], []byte{0x02, 0x00, 0x10}) {
+		t.Errorf("ReadRAM(0, 3): got (% x, %v), want (02 00 10, nil)", buf[:3], err)
+	}
+
+	// A write ending past 64kB fails without wrapping around to 0.
+	f = &fakeFX2{cpucs: []byte{1}}
+	if err := WriteRAM(f, 0xf000, bytes.Repeat([]byte{0xff}, ChunkSize+1)); err == nil {
+		t.Error("WriteRAM() beyond 64kB: got nil error, want non-nil")
+	}
+	if f.writes != 0 || f.mem[0] != 0 {
+		t.Errorf("WriteRAM() beyond 64kB: got %d writes, want none", f.writes)
+	}
+	if err := ReadRAM(f, 0xf000, make([]byte, ChunkSize+1)); err == nil {
+		t.Error("ReadRAM() beyond 64kB: got nil error, want non-nil")
+	}
+
+	outside := &Image{Segments: []Segment{{Addr: 0x10000, Data: []byte{1}}}}
+	f = &fakeFX2{}
+	if err := Load(f, outside); err == nil {
+		t.Error("Load() of an image beyond 64kB: got nil error, want non-nil")
+	}
+	if len(f.cpucs) != 0 {
+		t.Errorf("Load() of an invalid image wrote CPUCS %v, want no writes", f.cpucs)
+	}
+}
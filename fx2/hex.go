@@ -0,0 +1,143 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fx2
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Intel HEX record types.
+const (
+	recordData                   = 0x00
+	recordEOF                    = 0x01
+	recordExtendedSegmentAddress = 0x02
+	recordStartSegmentAddress    = 0x03
+	recordExtendedLinearAddress  = 0x04
+	recordStartLinearAddress     = 0x05
+)
+
+// Segment is a contiguous block of firmware data.
+type Segment struct {
+	// Addr is the address of the first byte of Data.
+	Addr uint32
+	Data []byte
+}
+
+// Image is a firmware image, as read from an Intel HEX file.
+type Image struct {
+	// Segments are the blocks of the image, sorted by address and with
+	// adjacent records merged.
+	Segments []Segment
+}
+
+// Size returns the number of bytes of the image.
+func (img *Image) Size() int {
+	n := 0
+	for _, s := range img.Segments {
+		n += len(s.Data)
+	}
+	return n
+}
+
+// ReadHex reads a firmware image in the Intel HEX format, as produced by
+// the SDCC and Keil toolchains. The checksums of all records are verified
+// and the image needs to be terminated by an end of file record.
+func ReadHex(r io.Reader) (*Image, error) {
+	var (
+		segs []Segment
+		base uint32
+		eof  bool
+	)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		if eof {
+			return nil, fmt.Errorf("line %d: data after the end of file record", line)
+		}
+		if text[0] != ':' {
+			return nil, fmt.Errorf("line %d: record doesn't start with ':'", line)
+		}
+		rec, err := hex.DecodeString(text[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if len(rec) < 5 || len(rec) != 5+int(rec[0]) {
+			return nil, fmt.Errorf("line %d: invalid record length", line)
+		}
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0 {
+			return nil, fmt.Errorf("line %d: checksum mismatch", line)
+		}
+		addr := uint32(rec[1])<<8 | uint32(rec[2])
+		data := rec[4 : len(rec)-1]
+		switch rec[3] {
+		case recordData:
+			segs = append(segs, Segment{Addr: base + addr, Data: data})
+		case recordEOF:
+			eof = true
+		case recordExtendedSegmentAddress:
+			if len(data) != 2 {
+				return nil, fmt.Errorf("line %d: invalid extended segment address record", line)
+			}
+			base = (uint32(data[0])<<8 | uint32(data[1])) << 4
+		case recordExtendedLinearAddress:
+			if len(data) != 2 {
+				return nil, fmt.Errorf("line %d: invalid extended linear address record", line)
+			}
+			base = (uint32(data[0])<<8 | uint32(data[1])) << 16
+		case recordStartSegmentAddress, recordStartLinearAddress:
+			// The start address is irrelevant for the 8051, which
+			// always starts at 0 after a reset.
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %#02x", line, rec[3])
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if !eof {
+		return nil, fmt.Errorf("missing end of file record")
+	}
+	return &Image{Segments: mergeSegments(segs)}, nil
+}
+
+// mergeSegments sorts the segments by address and merges the adjacent
+// ones.
+func mergeSegments(segs []Segment) []Segment {
+	sort.SliceStable(segs, func(i, j int) bool { return segs[i].Addr < segs[j].Addr })
+	var ret []Segment
+	for _, s := range segs {
+		if len(s.Data) == 0 {
+			continue
+		}
+		if n := len(ret); n > 0 && ret[n-1].Addr+uint32(len(ret[n-1].Data)) == s.Addr {
+			ret[n-1].Data = append(ret[n-1].Data, s.Data...)
+			continue
+		}
+		ret = append(ret, Segment{Addr: s.Addr, Data: append([]byte(nil), s.Data...)})
+	}
+	return ret
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fx2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadHex(t *testing.T) {
+	const img = `:0300000002000CEF
+:03000300020010E8
+:0400100075810712DD
+:03000C00020014DB
+
+:020000040001F9
+:02000000AA55FF
+:00000001FF
+`
+	got, err := ReadHex(strings.NewReader(img))
+	if err != nil {
+		t.Fatalf("ReadHex(): %v", err)
+	}
+	want := &Image{Segments: []Segment{
+		{Addr: 0x0000, Data: []byte{0x02, 0x00, 0x0c, 0x02, 0x00, 0x10}},
+		{Addr: 0x000c, Data: []byte{0x02, 0x00, 0x14}},
+		{Addr: 0x0010, Data: []byte{0x75, 0x81, 0x07, 0x12}},
+		{Addr: 0x10000, Data: []byte{0xaa, 0x55}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadHex():\ngot  %+v\nwant %+v", got, want)
+	}
+	if got, want := got.Size(), 15; got != want {
+		t.Errorf("Size(): got %d, want %d", got, want)
+	}
+}
+
+func TestReadHexErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc, img string
+	}{
+		{"no end of file", ":0300000002000CEF\n"},
+		{"bad checksum", ":0300000002000CEE\n:00000001FF\n"},
+		{"bad length", ":0400000002000CEF\n:00000001FF\n"},
+		{"no colon", "0300000002000CEF\n:00000001FF\n"},
+		{"not hex", ":03000000020G0CEF\n:00000001FF\n"},
+		{"unknown type", ":00000006FA\n:00000001FF\n"},
+		{"data after eof", ":00000001FF\n:0300000002000CEF\n"},
+	} {
+		if _, err := ReadHex(strings.NewReader(tc.img)); err == nil {
+			t.Errorf("ReadHex() with %s: got nil error, want non-nil", tc.desc)
+		}
+	}
+}
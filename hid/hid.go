@@ -0,0 +1,336 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hid implements the boot protocol of HID keyboards and mice,
+// a fixed report format every boot device supports without parsing its
+// report descriptor, which is enough for kiosks and input forwarding.
+// The input, output and feature reports of any HID interface can be
+// accessed through the control endpoint, which configuration-style
+// devices often use instead of the interrupt endpoints.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x046d, 0xc31c)
+//	dev.SetAutoDetach(true)
+//	cfg, _ := dev.Config(1)
+//	kbd, err := hid.Open(dev, cfg, 0)
+//	if err != nil { ... }
+//	defer kbd.Close()
+//	for {
+//		evs, err := kbd.ReadKeyEvents()
+//		if err != nil { ... }
+//		for _, ev := range evs { fmt.Println(ev) }
+//	}
+package hid
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// HID class requests.
+const (
+	requestGetReport   = 0x01
+	requestSetReport   = 0x09
+	requestSetIdle     = 0x0a
+	requestSetProtocol = 0x0b
+)
+
+// ReportType is the type of a report accessed with GetReport and
+// SetReport.
+type ReportType uint8
+
+// Report types.
+const (
+	ReportInput   ReportType = 0x01
+	ReportOutput  ReportType = 0x02
+	ReportFeature ReportType = 0x03
+)
+
+var reportTypeNames = map[ReportType]string{
+	ReportInput:   "input",
+	ReportOutput:  "output",
+	ReportFeature: "feature",
+}
+
+// String returns a human-readable name of the report type.
+func (t ReportType) String() string {
+	if n, ok := reportTypeNames[t]; ok {
+		return n
+	}
+	return fmt.Sprintf("report type %d", uint8(t))
+}
+
+// Protocols of SET_PROTOCOL.
+const (
+	protocolBoot   = 0
+	protocolReport = 1
+)
+
+// bootReportSize is the length of boot keyboard reports and the maximum
+// length read from a boot device, unless its endpoint tells otherwise.
+const bootReportSize = 8
+
+// Controller is the subset of *gousb.Device used to send the class
+// requests of a HID interface.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// IsBootKeyboard reports whether the interface setting is a HID boot
+// keyboard.
+func IsBootKeyboard(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassHID && s.SubClass == gousb.SubClassHIDBoot && s.Protocol == gousb.ProtocolHIDKeyboard
+}
+
+// IsBootMouse reports whether the interface setting is a HID boot mouse.
+func IsBootMouse(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassHID && s.SubClass == gousb.SubClassHIDBoot && s.Protocol == gousb.ProtocolHIDMouse
+}
+
+// Device is a HID interface. The reports of boot keyboards and mice are
+// decoded by ReadKeyboard, ReadKeyEvents and ReadMouse, the reports of any
+// HID interface can be accessed through the control endpoint with
+// GetReport and SetReport.
+type Device struct {
+	// Protocol is gousb.ProtocolHIDKeyboard or gousb.ProtocolHIDMouse for
+	// a boot interface, 0 otherwise.
+	Protocol gousb.Protocol
+
+	c    Controller
+	intf uint16
+	in   io.Reader
+	// size is the length of the reads of in.
+	size int
+	// keys is the state of the keyboard after the last report.
+	keys map[Key]bool
+	done func()
+}
+
+// New returns a Device for the HID interface intf, which sends its class
+// requests through c and reads its reports from in, the interrupt IN
+// endpoint of the interface. proto is the protocol of the interface
+// setting. in may be nil if only GetReport and SetReport are used. New
+// doesn't send any request, see Init.
+func New(c Controller, intf int, proto gousb.Protocol, in io.Reader) *Device {
+	return &Device{Protocol: proto, c: c, intf: uint16(intf), in: in, size: bootReportSize, keys: map[Key]bool{}}
+}
+
+// Open claims the HID interface intf of the configuration cfg and returns
+// a Device for it. Boot keyboards and mice are switched to the boot
+// protocol by Init, other interfaces are left as they are. The kernel
+// driver of the interface, if any, needs to be detached first, e.g. with
+// dev.SetAutoDetach. The interface is released by Close.
+func Open(dev *gousb.Device, cfg *gousb.Config, intf int) (_ *Device, err error) {
+	var setting *gousb.InterfaceSetting
+	for i, desc := range cfg.Desc.Interfaces {
+		if desc.Number == intf && len(desc.AltSettings) > 0 {
+			setting = &cfg.Desc.Interfaces[i].AltSettings[0]
+		}
+	}
+	if setting == nil || setting.Class != gousb.ClassHID {
+		return nil, fmt.Errorf("interface %d of %s is not a HID interface", intf, cfg)
+	}
+	boot := IsBootKeyboard(*setting) || IsBootMouse(*setting)
+	epDesc, ok := setting.InterruptIn()
+	if !ok {
+		return nil, fmt.Errorf("interface %d of %s has no interrupt IN endpoint", intf, cfg)
+	}
+	i, err := cfg.Interface(intf, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			i.Close()
+		}
+	}()
+	in, err := i.InEndpoint(epDesc.Number)
+	if err != nil {
+		return nil, err
+	}
+	var proto gousb.Protocol
+	if boot {
+		proto = setting.Protocol
+	}
+	d := New(dev, intf, proto, in)
+	if epDesc.MaxPacketSize > 0 {
+		d.size = epDesc.MaxPacketSize
+	}
+	if boot {
+		if err := d.Init(); err != nil {
+			return nil, err
+		}
+	}
+	d.done = i.Close
+	return d, nil
+}
+
+// Close releases the interface claimed by Open.
+func (d *Device) Close() error {
+	if d.done != nil {
+		d.done()
+		d.done = nil
+	}
+	return nil
+}
+
+func (d *Device) request(req uint8, name string, val uint16, data []byte) error {
+	if _, err := d.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, req, val, d.intf, data); err != nil {
+		return fmt.Errorf("%s failed: %v", name, err)
+	}
+	return nil
+}
+
+// Init switches the interface to the boot protocol with SET_PROTOCOL and
+// makes it report only changes with SET_IDLE. Some devices stall
+// SET_IDLE, which is ignored.
+func (d *Device) Init() error {
+	if err := d.request(requestSetProtocol, "SET_PROTOCOL", protocolBoot, nil); err != nil {
+		return err
+	}
+	d.SetIdle(0)
+	return nil
+}
+
+// SetIdle sets the interval at which the device repeats its last report
+// when nothing changes, in steps of 4ms up to 1020ms. 0 makes the device
+// report only changes.
+func (d *Device) SetIdle(interval time.Duration) error {
+	steps := interval / (4 * time.Millisecond)
+	if steps < 0 || steps > 0xff {
+		return fmt.Errorf("invalid idle interval %v, want 0 to 1020ms", interval)
+	}
+	return d.request(requestSetIdle, "SET_IDLE", uint16(steps)<<8, nil)
+}
+
+// SetLEDs sets the LEDs of a keyboard.
+func (d *Device) SetLEDs(l LEDs) error {
+	if d.Protocol != gousb.ProtocolHIDKeyboard {
+		return fmt.Errorf("SetLEDs called on a HID device that isn't a keyboard")
+	}
+	return d.SetReport(ReportOutput, 0, []byte{byte(l)})
+}
+
+// GetReport reads the report id of type typ through the control endpoint
+// with GET_REPORT. size is the length of the report, without the report
+// ID. id is 0 for devices that don't use report IDs, otherwise the report
+// ID the device sends first is checked and removed.
+func (d *Device) GetReport(typ ReportType, id uint8, size int) ([]byte, error) {
+	if id != 0 {
+		size++
+	}
+	buf := make([]byte, size)
+	n, err := d.c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlInterface, requestGetReport, uint16(typ)<<8|uint16(id), d.intf, buf)
+	if err != nil {
+		return nil, fmt.Errorf("GET_REPORT of %s report %d failed: %v", typ, id, err)
+	}
+	buf = buf[:n]
+	if id == 0 {
+		return buf, nil
+	}
+	if n == 0 || buf[0] != id {
+		return nil, fmt.Errorf("GET_REPORT of %s report %d: got report % x, want report ID %d first", typ, id, buf, id)
+	}
+	return buf[1:], nil
+}
+
+// SetReport sends data as the report id of type typ through the control
+// endpoint with SET_REPORT. id is 0 for devices that don't use report
+// IDs, otherwise it's sent before data.
+func (d *Device) SetReport(typ ReportType, id uint8, data []byte) error {
+	if id != 0 {
+		data = append([]byte{id}, data...)
+	}
+	return d.request(requestSetReport, fmt.Sprintf("SET_REPORT of %s report %d", typ, id), uint16(typ)<<8|uint16(id), data)
+}
+
+// GetFeature reads the feature report id, see GetReport.
+func (d *Device) GetFeature(id uint8, size int) ([]byte, error) {
+	return d.GetReport(ReportFeature, id, size)
+}
+
+// SetFeature sends the feature report id, see SetReport.
+func (d *Device) SetFeature(id uint8, data []byte) error {
+	return d.SetReport(ReportFeature, id, data)
+}
+
+// ReadReport reads a single report of the device.
+func (d *Device) ReadReport() ([]byte, error) {
+	buf := make([]byte, d.size)
+	n, err := d.in.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// ReadKeyboard reads the next report of a keyboard.
+func (d *Device) ReadKeyboard() (KeyboardReport, error) {
+	if d.Protocol != gousb.ProtocolHIDKeyboard {
+		return KeyboardReport{}, fmt.Errorf("ReadKeyboard called on a HID device that isn't a keyboard")
+	}
+	b, err := d.ReadReport()
+	if err != nil {
+		return KeyboardReport{}, err
+	}
+	return ParseKeyboardReport(b)
+}
+
+// ReadKeyEvents reads the next report of a keyboard and returns the keys
+// released and pressed since the previous report, the modifiers included.
+// Reports of too many keys pressed at once leave the state unchanged and
+// return no events.
+func (d *Device) ReadKeyEvents() ([]KeyEvent, error) {
+	r, err := d.ReadKeyboard()
+	if err != nil {
+		return nil, err
+	}
+	if r.RollOver {
+		return nil, nil
+	}
+	keys := r.Pressed()
+	down := make(map[Key]bool, len(keys))
+	for _, k := range keys {
+		down[k] = true
+	}
+	var evs []KeyEvent
+	for _, k := range sortedKeys(d.keys) {
+		if !down[k] {
+			evs = append(evs, KeyEvent{Key: k})
+		}
+	}
+	for _, k := range keys {
+		if !d.keys[k] {
+			evs = append(evs, KeyEvent{Key: k, Down: true})
+		}
+	}
+	d.keys = down
+	return evs, nil
+}
+
+// ReadMouse reads the next report of a mouse.
+func (d *Device) ReadMouse() (MouseReport, error) {
+	if d.Protocol != gousb.ProtocolHIDMouse {
+		return MouseReport{}, fmt.Errorf("ReadMouse called on a HID device that isn't a mouse")
+	}
+	b, err := d.ReadReport()
+	if err != nil {
+		return MouseReport{}, err
+	}
+	return ParseMouseReport(b)
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hid
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+type request struct {
+	rType, request uint8
+	val, idx       uint16
+	data           string
+}
+
+// fakeHID records the control requests, failing those in fail.
+type fakeHID struct {
+	reqs []request
+	fail map[uint8]bool
+	// in is returned by the IN requests.
+	in []byte
+}
+
+func (f *fakeHID) Control(rType, req uint8, val, idx uint16, data []byte) (int, error) {
+	if rType&0x80 != 0 {
+		f.reqs = append(f.reqs, request{rType, req, val, idx, ""})
+	} else {
+		f.reqs = append(f.reqs, request{rType, req, val, idx, string(data)})
+	}
+	if f.fail[req] {
+		return 0, errors.New("pipe error")
+	}
+	if rType&0x80 != 0 {
+		return copy(data, f.in), nil
+	}
+	return len(data), nil
+}
+
+// reports returns a report per Read.
+type reports [][]byte
+
+func (r *reports) Read(b []byte) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, (*r)[0])
+	*r = (*r)[1:]
+	return n, nil
+}
+
+func TestInit(t *testing.T) {
+	f := &fakeHID{fail: map[uint8]bool{requestSetIdle: true}}
+	d := New(f, 1, gousb.ProtocolHIDKeyboard, nil)
+	if err := d.Init(); err != nil {
+		t.Fatalf("Init(): %v", err)
+	}
+	if err := d.SetLEDs(LEDNumLock | LEDCapsLock); err != nil {
+		t.Fatalf("SetLEDs(): %v", err)
+	}
+	delete(f.fail, requestSetIdle)
+	if err := d.SetIdle(500 * time.Millisecond); err != nil {
+		t.Fatalf("SetIdle(): %v", err)
+	}
+	want := []request{
+		{0x21, requestSetProtocol, protocolBoot, 1, ""},
+		{0x21, requestSetIdle, 0, 1, ""},
+		{0x21, requestSetReport, 0x0200, 1, "\x03"},
+		{0x21, requestSetIdle, 125 << 8, 1, ""},
+	}
+	if !reflect.DeepEqual(f.reqs, want) {
+		t.Errorf("requests: got %+v, want %+v", f.reqs, want)
+	}
+	if err := d.SetIdle(2 * time.Second); err == nil {
+		t.Error("SetIdle(2s): got nil error, want non-nil")
+	}
+
+	f.fail[requestSetProtocol] = true
+	if err := d.Init(); err == nil {
+		t.Error("Init() with SET_PROTOCOL failing: got nil error, want non-nil")
+	}
+}
+
+func TestReports(t *testing.T) {
+	f := &fakeHID{in: []byte{0x05, 0xaa, 0xbb}}
+	d := New(f, 2, 0, nil)
+	got, err := d.GetFeature(5, 2)
+	if err != nil {
+		t.Fatalf("GetFeature(): %v", err)
+	}
+	if string(got) != "\xaa\xbb" {
+		t.Errorf("GetFeature(): got % x, want aa bb", got)
+	}
+	if err := d.SetFeature(5, []byte{0x01}); err != nil {
+		t.Fatalf("SetFeature(): %v", err)
+	}
+	f.in = []byte{0x11, 0x22}
+	if got, err = d.GetReport(ReportInput, 0, 2); err != nil || string(got) != "\x11\x22" {
+		t.Errorf("GetReport(input, 0): got % x, %v, want 11 22, nil", got, err)
+	}
+	if err := d.SetReport(ReportOutput, 0, []byte{0x33}); err != nil {
+		t.Fatalf("SetReport(): %v", err)
+	}
+	want := []request{
+		{0xa1, requestGetReport, 0x0305, 2, ""},
+		{0x21, requestSetReport, 0x0305, 2, "\x05\x01"},
+		{0xa1, requestGetReport, 0x0100, 2, ""},
+		{0x21, requestSetReport, 0x0200, 2, "\x33"},
+	}
+	if !reflect.DeepEqual(f.reqs, want) {
+		t.Errorf("requests: got %+v, want %+v", f.reqs, want)
+	}
+
+	f.in = []byte{0x06, 0xaa, 0xbb}
+	if _, err := d.GetFeature(5, 2); err == nil {
+		t.Error("GetFeature() with the wrong report ID: got nil error, want non-nil")
+	}
+	if d.SetLEDs(LEDNumLock) == nil {
+		t.Error("SetLEDs() on a HID interface that isn't a keyboard: got nil error, want non-nil")
+	}
+}
+
+func TestReadKeyEvents(t *testing.T) {
+	in := &reports{
+		{0x02, 0, 0x04, 0, 0, 0, 0, 0},
+		{0x02, 0, 0x04, 0x05, 0, 0, 0, 0},
+		{0x02, 0, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+		{0, 0, 0x05, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	d := New(&fakeHID{}, 0, gousb.ProtocolHIDKeyboard, in)
+	for i, want := range [][]KeyEvent{
+		{{KeyLeftShift, true}, {KeyA, true}},
+		{{KeyA + 1, true}},
+		nil,
+		{{KeyA, false}, {KeyLeftShift, false}},
+		{{KeyA + 1, false}},
+	} {
+		got, err := d.ReadKeyEvents()
+		if err != nil {
+			t.Fatalf("report %d: ReadKeyEvents(): %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("report %d: ReadKeyEvents(): got %v, want %v", i, got, want)
+		}
+	}
+	if _, err := d.ReadKeyEvents(); err != io.EOF {
+		t.Errorf("ReadKeyEvents() at the end of the reports: got %v, want EOF", err)
+	}
+	if _, err := d.ReadMouse(); err == nil {
+		t.Error("ReadMouse() on a keyboard: got nil error, want non-nil")
+	}
+}
+
+func TestReadMouse(t *testing.T) {
+	in := &reports{{0x05, 0x10, 0xfe, 0xff}}
+	d := New(&fakeHID{}, 0, gousb.ProtocolHIDMouse, in)
+	got, err := d.ReadMouse()
+	if err != nil {
+		t.Fatalf("ReadMouse(): %v", err)
+	}
+	want := MouseReport{Buttons: ButtonLeft | ButtonMiddle, X: 16, Y: -2, Wheel: -1}
+	if got != want {
+		t.Errorf("ReadMouse(): got %+v, want %+v", got, want)
+	}
+	if err := d.SetLEDs(LEDNumLock); err == nil {
+		t.Error("SetLEDs() on a mouse: got nil error, want non-nil")
+	}
+}
+
+func TestIsBoot(t *testing.T) {
+	kbd := gousb.InterfaceSetting{Class: gousb.ClassHID, SubClass: gousb.SubClassHIDBoot, Protocol: gousb.ProtocolHIDKeyboard}
+	mouse := kbd
+	mouse.Protocol = gousb.ProtocolHIDMouse
+	other := kbd
+	other.SubClass = 0
+	if !IsBootKeyboard(kbd) || IsBootMouse(kbd) {
+		t.Error("boot keyboard not recognized as a keyboard only")
+	}
+	if !IsBootMouse(mouse) || IsBootKeyboard(mouse) {
+		t.Error("boot mouse not recognized as a mouse only")
+	}
+	if IsBootKeyboard(other) || IsBootMouse(other) {
+		t.Error("non-boot HID interface recognized as a boot device")
+	}
+}
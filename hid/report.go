@@ -0,0 +1,272 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key is a usage of the keyboard usage page, the key codes of the boot
+// keyboard reports.
+type Key uint8
+
+// Keys. Letters are KeyA + 0 to 25, digits Key1 + 0 to 9 with Key0 last,
+// function keys KeyF1 + 0 to 11.
+const (
+	keyErrorRollOver Key = 0x01
+
+	KeyA         Key = 0x04
+	Key1         Key = 0x1e
+	Key0         Key = 0x27
+	KeyEnter     Key = 0x28
+	KeyEscape    Key = 0x29
+	KeyBackspace Key = 0x2a
+	KeyTab       Key = 0x2b
+	KeySpace     Key = 0x2c
+	KeyCapsLock  Key = 0x39
+	KeyF1        Key = 0x3a
+	KeyInsert    Key = 0x49
+	KeyHome      Key = 0x4a
+	KeyPageUp    Key = 0x4b
+	KeyDelete    Key = 0x4c
+	KeyEnd       Key = 0x4d
+	KeyPageDown  Key = 0x4e
+	KeyRight     Key = 0x4f
+	KeyLeft      Key = 0x50
+	KeyDown      Key = 0x51
+	KeyUp        Key = 0x52
+
+	// The modifier keys, reported by bits of Modifiers.
+	KeyLeftCtrl   Key = 0xe0
+	KeyLeftShift  Key = 0xe1
+	KeyLeftAlt    Key = 0xe2
+	KeyLeftGUI    Key = 0xe3
+	KeyRightCtrl  Key = 0xe4
+	KeyRightShift Key = 0xe5
+	KeyRightAlt   Key = 0xe6
+	KeyRightGUI   Key = 0xe7
+)
+
+var keyNames = map[Key]string{
+	KeyEnter:      "enter",
+	KeyEscape:     "escape",
+	KeyBackspace:  "backspace",
+	KeyTab:        "tab",
+	KeySpace:      "space",
+	0x2d:          "-",
+	0x2e:          "=",
+	0x2f:          "[",
+	0x30:          "]",
+	0x31:          "\\",
+	0x33:          ";",
+	0x34:          "'",
+	0x35:          "`",
+	0x36:          ",",
+	0x37:          ".",
+	0x38:          "/",
+	KeyCapsLock:   "caps lock",
+	KeyInsert:     "insert",
+	KeyHome:       "home",
+	KeyPageUp:     "page up",
+	KeyDelete:     "delete",
+	KeyEnd:        "end",
+	KeyPageDown:   "page down",
+	KeyRight:      "right",
+	KeyLeft:       "left",
+	KeyDown:       "down",
+	KeyUp:         "up",
+	KeyLeftCtrl:   "left ctrl",
+	KeyLeftShift:  "left shift",
+	KeyLeftAlt:    "left alt",
+	KeyLeftGUI:    "left GUI",
+	KeyRightCtrl:  "right ctrl",
+	KeyRightShift: "right shift",
+	KeyRightAlt:   "right alt",
+	KeyRightGUI:   "right GUI",
+}
+
+// String returns a human-readable name of the key.
+func (k Key) String() string {
+	switch {
+	case k >= KeyA && k < KeyA+26:
+		return string(rune('a' + k - KeyA))
+	case k >= Key1 && k < Key0:
+		return string(rune('1' + k - Key1))
+	case k == Key0:
+		return "0"
+	case k >= KeyF1 && k < KeyF1+12:
+		return fmt.Sprintf("F%d", k-KeyF1+1)
+	}
+	if n, ok := keyNames[k]; ok {
+		return n
+	}
+	return fmt.Sprintf("key %#02x", uint8(k))
+}
+
+// Modifiers are the modifier keys pressed, bit i is key KeyLeftCtrl + i.
+type Modifiers uint8
+
+// Modifier bits.
+const (
+	ModLeftCtrl Modifiers = 1 << iota
+	ModLeftShift
+	ModLeftAlt
+	ModLeftGUI
+	ModRightCtrl
+	ModRightShift
+	ModRightAlt
+	ModRightGUI
+)
+
+// Shift reports whether either shift key is pressed.
+func (m Modifiers) Shift() bool { return m&(ModLeftShift|ModRightShift) != 0 }
+
+// Ctrl reports whether either ctrl key is pressed.
+func (m Modifiers) Ctrl() bool { return m&(ModLeftCtrl|ModRightCtrl) != 0 }
+
+// Alt reports whether either alt key is pressed.
+func (m Modifiers) Alt() bool { return m&(ModLeftAlt|ModRightAlt) != 0 }
+
+// LEDs are the LEDs of a keyboard, set with SetLEDs.
+type LEDs uint8
+
+// Keyboard LEDs.
+const (
+	LEDNumLock LEDs = 1 << iota
+	LEDCapsLock
+	LEDScrollLock
+	LEDCompose
+	LEDKana
+)
+
+// KeyboardReport is a boot keyboard report.
+type KeyboardReport struct {
+	Modifiers Modifiers
+	// Keys are the other keys pressed, at most 6.
+	Keys []Key
+	// RollOver is true if more keys than the report holds are pressed,
+	// Keys is then empty.
+	RollOver bool
+}
+
+// Pressed returns the keys pressed, the modifiers first.
+func (r KeyboardReport) Pressed() []Key {
+	var keys []Key
+	for i := uint(0); i < 8; i++ {
+		if r.Modifiers&(1<<i) != 0 {
+			keys = append(keys, KeyLeftCtrl+Key(i))
+		}
+	}
+	return append(keys, r.Keys...)
+}
+
+// ParseKeyboardReport decodes the boot keyboard report b.
+func ParseKeyboardReport(b []byte) (KeyboardReport, error) {
+	if len(b) < bootReportSize {
+		return KeyboardReport{}, fmt.Errorf("boot keyboard report too short: % x", b)
+	}
+	r := KeyboardReport{Modifiers: Modifiers(b[0])}
+	for _, k := range b[2:bootReportSize] {
+		switch {
+		case Key(k) == keyErrorRollOver:
+			return KeyboardReport{Modifiers: r.Modifiers, RollOver: true}, nil
+		case k > 0x03:
+			// 0 is no key, 2 and 3 are the POST fail and undefined
+			// error codes.
+			r.Keys = append(r.Keys, Key(k))
+		}
+	}
+	return r, nil
+}
+
+// KeyEvent is a key pressed or released.
+type KeyEvent struct {
+	Key Key
+	// Down is true if the key was pressed, false if released.
+	Down bool
+}
+
+func (e KeyEvent) String() string {
+	if e.Down {
+		return e.Key.String() + " down"
+	}
+	return e.Key.String() + " up"
+}
+
+func sortedKeys(m map[Key]bool) []Key {
+	keys := make([]Key, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// Buttons are the mouse buttons pressed.
+type Buttons uint8
+
+// Mouse buttons.
+const (
+	ButtonLeft Buttons = 1 << iota
+	ButtonRight
+	ButtonMiddle
+)
+
+func (b Buttons) String() string {
+	var names []string
+	for _, n := range []struct {
+		b    Buttons
+		name string
+	}{{ButtonLeft, "left"}, {ButtonRight, "right"}, {ButtonMiddle, "middle"}} {
+		if b&n.b != 0 {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// MouseReport is a boot mouse report.
+type MouseReport struct {
+	Buttons Buttons
+	// X and Y are the relative movement of the mouse, right and down
+	// being positive.
+	X, Y int
+	// Wheel is the relative movement of the wheel, 0 if the mouse
+	// doesn't report it.
+	Wheel int
+}
+
+// ParseMouseReport decodes the boot mouse report b. Bytes following the
+// 3 bytes of the boot report are ignored, except the wheel reported by
+// most mice in the fourth byte.
+func ParseMouseReport(b []byte) (MouseReport, error) {
+	if len(b) < 3 {
+		return MouseReport{}, fmt.Errorf("boot mouse report too short: % x", b)
+	}
+	r := MouseReport{
+		Buttons: Buttons(b[0] & 0x07),
+		X:       int(int8(b[1])),
+		Y:       int(int8(b[2])),
+	}
+	if len(b) > 3 {
+		r.Wheel = int(int8(b[3]))
+	}
+	return r, nil
+}
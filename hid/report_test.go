@@ -0,0 +1,100 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyboardReport(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		b    []byte
+		want KeyboardReport
+	}{
+		{
+			desc: "no key",
+			b:    []byte{0, 0, 0, 0, 0, 0, 0, 0},
+			want: KeyboardReport{},
+		},
+		{
+			desc: "ctrl-alt-delete",
+			b:    []byte{0x05, 0, 0x4c, 0, 0, 0, 0, 0},
+			want: KeyboardReport{Modifiers: ModLeftCtrl | ModLeftAlt, Keys: []Key{KeyDelete}},
+		},
+		{
+			desc: "roll over",
+			b:    []byte{0x20, 0, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+			want: KeyboardReport{Modifiers: ModRightShift, RollOver: true},
+		},
+	} {
+		got, err := ParseKeyboardReport(tc.b)
+		if err != nil {
+			t.Errorf("%s: ParseKeyboardReport(): %v", tc.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: ParseKeyboardReport(): got %+v, want %+v", tc.desc, got, tc.want)
+		}
+	}
+	if _, err := ParseKeyboardReport([]byte{0, 0, 4}); err == nil {
+		t.Error("ParseKeyboardReport(short report): got nil error, want non-nil")
+	}
+}
+
+func TestPressed(t *testing.T) {
+	r := KeyboardReport{Modifiers: ModLeftShift | ModRightGUI, Keys: []Key{KeyF1, KeySpace}}
+	want := []Key{KeyLeftShift, KeyRightGUI, KeyF1, KeySpace}
+	if got := r.Pressed(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pressed(): got %v, want %v", got, want)
+	}
+	if !r.Modifiers.Shift() || r.Modifiers.Ctrl() || r.Modifiers.Alt() {
+		t.Errorf("Modifiers %#x: got Shift/Ctrl/Alt %v/%v/%v, want true/false/false", r.Modifiers, r.Modifiers.Shift(), r.Modifiers.Ctrl(), r.Modifiers.Alt())
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	for k, want := range map[Key]string{
+		KeyA + 25:    "z",
+		Key1:         "1",
+		Key0:         "0",
+		KeyF1 + 11:   "F12",
+		KeyEnter:     "enter",
+		KeyLeftShift: "left shift",
+		0x90:         "key 0x90",
+	} {
+		if got := k.String(); got != want {
+			t.Errorf("Key(%#x).String(): got %q, want %q", uint8(k), got, want)
+		}
+	}
+}
+
+func TestParseMouseReport(t *testing.T) {
+	got, err := ParseMouseReport([]byte{0x0a, 0x80, 0x7f})
+	if err != nil {
+		t.Fatalf("ParseMouseReport(): %v", err)
+	}
+	want := MouseReport{Buttons: ButtonRight, X: -128, Y: 127}
+	if got != want {
+		t.Errorf("ParseMouseReport(): got %+v, want %+v", got, want)
+	}
+	if s := (ButtonLeft | ButtonMiddle).String(); s != "left|middle" {
+		t.Errorf("Buttons.String(): got %q, want left|middle", s)
+	}
+	if _, err := ParseMouseReport([]byte{0x01}); err == nil {
+		t.Error("ParseMouseReport(short report): got nil error, want non-nil")
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultHotplugPollInterval is the interval between device enumerations
+// used when the platform does not support native hotplug notifications.
+const defaultHotplugPollInterval = time.Second
+
+// HotplugEvent describes a device that was attached to or detached from
+// the system.
+type HotplugEvent struct {
+	Type HotplugEventType
+	// Desc is the descriptor of the device. For HotplugEventDeviceLeft,
+	// it is the descriptor the device had while it was attached.
+	Desc *DeviceDesc
+}
+
+// hotplugKey identifies a device between two successive enumerations.
+type hotplugKey struct {
+	bus, addr int
+	vid, pid  ID
+}
+
+func hotplugKeyOf(desc *DeviceDesc) hotplugKey {
+	return hotplugKey{bus: desc.Bus, addr: desc.Address, vid: desc.Vendor, pid: desc.Product}
+}
+
+// hotplugWatcher delivers hotplug events of a single registration.
+// Events are queued by the producer (the libusb event thread or the poller)
+// and passed to the user callback from a dedicated goroutine, so a slow
+// callback never blocks the libusb event handling.
+type hotplugWatcher struct {
+	fn    func(HotplugEvent)
+	match func(desc *DeviceDesc) bool
+
+	mu      sync.Mutex
+	queue   []HotplugEvent
+	stopped bool
+	notify  chan struct{}
+	// last is the type of the last event queued for each device, used to
+	// drop the duplicate events some platforms deliver. The entry of a
+	// device is removed when its leave event is dispatched, so that the
+	// map doesn't grow with every device ever seen.
+	last map[hotplugKey]HotplugEventType
+
+	// stop is closed when the registration is cancelled.
+	stop chan struct{}
+	// deregister cancels the native libusb registration, if any.
+	deregister func()
+	// pollDone is closed when the poller goroutine exits.
+	pollDone chan struct{}
+	once     sync.Once
+}
+
+func newHotplugWatcher(match func(desc *DeviceDesc) bool, fn func(HotplugEvent)) *hotplugWatcher {
+	w := &hotplugWatcher{
+		fn:     fn,
+		match:  match,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		last:   make(map[hotplugKey]HotplugEventType),
+	}
+	go w.dispatch()
+	return w
+}
+
+// push queues ev, unless it's not matched by the filter of the watcher
+// or it repeats the previous event of the same device.
+func (w *hotplugWatcher) push(ev HotplugEvent) {
+	if w.match != nil && !w.match(ev.Desc) {
+		return
+	}
+	k := hotplugKeyOf(ev.Desc)
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	if typ, ok := w.last[k]; ok && typ == ev.Type {
+		w.mu.Unlock()
+		return
+	}
+	w.last[k] = ev.Type
+	w.queue = append(w.queue, ev)
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *hotplugWatcher) dispatch() {
+	for {
+		select {
+		case <-w.notify:
+		case <-w.stop:
+			return
+		}
+		for {
+			w.mu.Lock()
+			if w.stopped || len(w.queue) == 0 {
+				w.mu.Unlock()
+				break
+			}
+			ev := w.queue[0]
+			w.queue = w.queue[1:]
+			if ev.Type == HotplugEventDeviceLeft {
+				// Unless the device arrived again meanwhile.
+				if k := hotplugKeyOf(ev.Desc); w.last[k] == HotplugEventDeviceLeft {
+					delete(w.last, k)
+				}
+			}
+			w.mu.Unlock()
+			w.fn(ev)
+		}
+	}
+}
+
+// close cancels the registration. After close returns, no new callbacks
+// will be started and the watcher no longer uses libusb.
+func (w *hotplugWatcher) close() {
+	w.once.Do(func() {
+		if w.deregister != nil {
+			w.deregister()
+		}
+		w.mu.Lock()
+		w.stopped = true
+		w.queue = nil
+		w.mu.Unlock()
+		close(w.stop)
+		if w.pollDone != nil {
+			<-w.pollDone
+		}
+	})
+}
+
+// poll periodically enumerates the devices and emits events for the
+// differences between two successive enumerations. prev is the baseline
+// taken at registration, so that, like with native hotplug, devices that
+// were already attached are not reported.
+func (w *hotplugWatcher) poll(c *Context, prev map[hotplugKey]*DeviceDesc, interval time.Duration) {
+	defer close(w.pollDone)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+		}
+		cur := c.hotplugSnapshot()
+		if cur == nil {
+			// enumeration failed, try again on the next tick.
+			continue
+		}
+		for k, desc := range prev {
+			if _, ok := cur[k]; !ok {
+				w.push(HotplugEvent{Type: HotplugEventDeviceLeft, Desc: desc})
+			}
+		}
+		for k, desc := range cur {
+			if _, ok := prev[k]; !ok {
+				w.push(HotplugEvent{Type: HotplugEventDeviceArrived, Desc: desc})
+			}
+		}
+		prev = cur
+	}
+}
+
+// hotplugSnapshot returns descriptors of all currently attached devices,
+// or nil if the enumeration failed.
+func (c *Context) hotplugSnapshot() map[hotplugKey]*DeviceDesc {
+	list, err := c.libusb.getDevices(c.ctx)
+	if err != nil {
+		return nil
+	}
+	ret := make(map[hotplugKey]*DeviceDesc, len(list))
+	for _, dev := range list {
+		desc, err := c.libusb.getDeviceDesc(dev)
+		c.libusb.dereference(dev)
+		if err != nil {
+			continue
+		}
+		ret[hotplugKeyOf(desc)] = desc
+	}
+	return ret
+}
+
+// RegisterHotplug calls fn whenever a device is attached to or detached
+// from the system. Devices attached before the call are not reported.
+// Callbacks are made sequentially from a separate goroutine; fn may open
+// the reported device using the Context.
+//
+// If libusb does not support hotplug notifications on the current platform
+// (e.g. on Windows, OpenBSD or NetBSD), the devices are enumerated periodically instead and
+// the differences are reported as the same events. Detachments and
+// reattachments faster than the polling interval may go unnoticed.
+//
+// Some platforms report an attachment more than once, repeated events
+// of the same device are dropped, so each attachment and detachment is
+// reported once.
+//
+// The returned function cancels the registration; it may be called from
+// within fn. All registrations are cancelled by Context.Close.
+func (c *Context) RegisterHotplug(fn func(HotplugEvent)) (func(), error) {
+	return c.RegisterHotplugMatch(nil, fn)
+}
+
+// RegisterHotplugMatch is like RegisterHotplug, but fn is only called for
+// the devices for which match returns true, e.g. one of the predicates of
+// MatchVIDPID or MatchClass. A nil match matches all devices. match is
+// called with the descriptor of the event, before the event is queued.
+func (c *Context) RegisterHotplugMatch(match func(desc *DeviceDesc) bool, fn func(HotplugEvent)) (func(), error) {
+	if c.ctx == nil {
+		return nil, errors.New("RegisterHotplug called on a closed or uninitialized Context")
+	}
+	w := newHotplugWatcher(match, fn)
+	dereg, err := c.libusb.registerHotplug(c.ctx, func(dev *libusbDevice, typ HotplugEventType) {
+		desc, err := c.libusb.getDeviceDesc(dev)
+		if err != nil {
+			return
+		}
+		w.push(HotplugEvent{Type: typ, Desc: desc})
+	})
+	switch {
+	case err == nil:
+		w.deregister = dereg
+	case err == ErrorNotSupported:
+		w.pollDone = make(chan struct{})
+		go w.poll(c, c.hotplugSnapshot(), c.hotplugPollInterval)
+	default:
+		w.close()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.hotplug[w] = true
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		delete(c.hotplug, w)
+		c.mu.Unlock()
+		w.close()
+	}, nil
+}
+
+// closeHotplug cancels all hotplug registrations of the Context.
+func (c *Context) closeHotplug() {
+	c.mu.Lock()
+	ws := c.hotplug
+	c.hotplug = make(map[*hotplugWatcher]bool)
+	c.mu.Unlock()
+	for w := range ws {
+		w.close()
+	}
+}
+
+// WaitForDevice blocks until a device for which match returns true is
+// attached, and returns it opened. If such a device is already attached,
+// it's returned immediately. Arrivals are detected using RegisterHotplug,
+// which falls back to polling on platforms without native hotplug support.
+//
+// A typical use is waiting for a device to reboot, e.g. into a bootloader
+// with a different VID/PID. WaitForDevice returns an error when ctx is done
+// before a matching device could be opened. As with OpenDevices, the
+// returned Device must be closed.
+func (c *Context) WaitForDevice(ctx context.Context, match func(desc *DeviceDesc) bool) (*Device, error) {
+	arrived := make(chan *DeviceDesc)
+	done := make(chan struct{})
+	defer close(done)
+	stop, err := c.RegisterHotplugMatch(match, func(ev HotplugEvent) {
+		if ev.Type != HotplugEventDeviceArrived {
+			return
+		}
+		select {
+		case arrived <- ev.Desc:
+		case <-done:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	// open opens the first device matched by m.
+	var openErr error
+	open := func(m func(*DeviceDesc) bool) *Device {
+		var found bool
+		devs, err := c.OpenDevices(func(desc *DeviceDesc) bool {
+			if found || !m(desc) {
+				return false
+			}
+			found = true
+			return true
+		})
+		if len(devs) > 0 {
+			return devs[0]
+		}
+		if err != nil {
+			openErr = err
+		}
+		return nil
+	}
+
+	// The device might have been attached before the hotplug registration.
+	if dev := open(match); dev != nil {
+		return dev, nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if openErr != nil {
+				return nil, fmt.Errorf("WaitForDevice: %v, last error opening a matching device: %v", ctx.Err(), openErr)
+			}
+			return nil, ctx.Err()
+		case desc := <-arrived:
+			if dev := open(func(d *DeviceDesc) bool {
+				return d.Bus == desc.Bus && d.Address == desc.Address
+			}); dev != nil {
+				return dev, nil
+			}
+		}
+	}
+}
+
+// Reenumerate waits for the device to drop off the bus and come back,
+// and returns the new device opened. This is the usual workflow for
+// devices whose firmware is loaded over USB, e.g. Cypress FX2 or FPGA
+// boards, which reattach with the new firmware and often with a different
+// VID/PID. match selects the reattached device, if nil the device is
+// expected to keep its vendor and product IDs.
+//
+// If reset is true, the device is reset first, which is how some devices
+// are told to switch to the new firmware. If the reset completes without
+// re-enumeration and the device still matches, d is returned as is.
+// Otherwise d is closed, as its handle is no longer valid, and the device
+// attached at the old bus address is not considered a match. Reenumerate
+// fails without waiting if d can't be closed, e.g. because it still has
+// an open Config.
+func (d *Device) Reenumerate(ctx context.Context, reset bool, match func(desc *DeviceDesc) bool) (*Device, error) {
+	if d.handle == nil {
+		return nil, fmt.Errorf("Reenumerate() called on %s after Close", d)
+	}
+	if match == nil {
+		vid, pid := d.Desc.Vendor, d.Desc.Product
+		match = func(desc *DeviceDesc) bool {
+			return desc.Vendor == vid && desc.Product == pid
+		}
+	}
+	if reset {
+		switch err := d.Reset(); err {
+		case nil:
+			if match(d.Desc) {
+				return d, nil
+			}
+		case ErrDeviceReenumerated:
+			// The device is coming back as a new device.
+		default:
+			return nil, fmt.Errorf("failed to reset %s: %v", d, err)
+		}
+	}
+	bus, addr := d.Desc.Bus, d.Desc.Address
+	if err := d.Close(); err != nil {
+		return nil, err
+	}
+	return d.ctx.WaitForDevice(ctx, func(desc *DeviceDesc) bool {
+		return (desc.Bus != bus || desc.Address != addr) && match(desc)
+	})
+}
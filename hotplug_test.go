@@ -0,0 +1,274 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHotplugPolling(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close: %v", err)
+		}
+	}()
+
+	events := make(chan HotplugEvent, 10)
+	stop, err := ctx.RegisterHotplug(func(ev HotplugEvent) {
+		events <- ev
+	})
+	if err != nil {
+		t.Fatalf("RegisterHotplug: %v", err)
+	}
+	defer stop()
+
+	next := func() HotplugEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for a hotplug event")
+		}
+		return HotplugEvent{}
+	}
+
+	desc := &DeviceDesc{Bus: 2, Address: 7, Vendor: 0x1234, Product: 0x5678}
+	dev := lib.plug(fakeDevice{devDesc: desc})
+	if ev := next(); ev.Type != HotplugEventDeviceArrived || ev.Desc != desc {
+		t.Errorf("after plug: got event %s for %v, want %s for %v", ev.Type, ev.Desc, HotplugEventDeviceArrived, desc)
+	}
+	lib.unplug(dev)
+	if ev := next(); ev.Type != HotplugEventDeviceLeft || ev.Desc != desc {
+		t.Errorf("after unplug: got event %s for %v, want %s for %v", ev.Type, ev.Desc, HotplugEventDeviceLeft, desc)
+	}
+
+	stop()
+	lib.plug(fakeDevice{devDesc: desc})
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case ev := <-events:
+		t.Errorf("got event %s for %v after the registration was cancelled", ev.Type, ev.Desc)
+	default:
+	}
+}
+
+func TestHotplugDedup(t *testing.T) {
+	t.Parallel()
+	events := make(chan HotplugEvent, 10)
+	// The events are dispatched once they're all queued, the duplicates
+	// of an event already dispatched are not detected.
+	pushed := make(chan struct{})
+	w := newHotplugWatcher(MatchVIDPID(0x1234, 0), func(ev HotplugEvent) {
+		<-pushed
+		events <- ev
+	})
+	defer w.close()
+
+	dev := &DeviceDesc{Bus: 1, Address: 3, Vendor: 0x1234, Product: 0x5678}
+	other := &DeviceDesc{Bus: 1, Address: 4, Vendor: 0x4321, Product: 0x5678}
+	for _, ev := range []HotplugEvent{
+		{HotplugEventDeviceArrived, dev},
+		{HotplugEventDeviceArrived, dev},
+		{HotplugEventDeviceArrived, other},
+		{HotplugEventDeviceLeft, dev},
+		{HotplugEventDeviceLeft, dev},
+		{HotplugEventDeviceArrived, dev},
+		{HotplugEventDeviceLeft, dev},
+	} {
+		w.push(ev)
+	}
+	close(pushed)
+	want := []HotplugEventType{HotplugEventDeviceArrived, HotplugEventDeviceLeft, HotplugEventDeviceArrived, HotplugEventDeviceLeft}
+	for i, typ := range want {
+		select {
+		case ev := <-events:
+			if ev.Type != typ || ev.Desc != dev {
+				t.Errorf("event %d: got %s for %v, want %s for %v", i, ev.Type, ev.Desc, typ, dev)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for event %d", i)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("got unexpected event %s for %v", ev.Type, ev.Desc)
+	case <-time.After(10 * time.Millisecond):
+	}
+	// The device left, it's forgotten.
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if typ, ok := w.last[hotplugKeyOf(dev)]; ok {
+		t.Errorf("last event of %v after it left: got %s, want none", dev, typ)
+	}
+}
+
+func TestRegisterHotplugMatch(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer ctx.Close()
+
+	events := make(chan HotplugEvent, 10)
+	stop, err := ctx.RegisterHotplugMatch(MatchClass(ClassHID), func(ev HotplugEvent) {
+		events <- ev
+	})
+	if err != nil {
+		t.Fatalf("RegisterHotplugMatch: %v", err)
+	}
+	defer stop()
+
+	lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 2, Address: 8, Vendor: 0x1234, Product: 0x0001}})
+	hid := &DeviceDesc{Bus: 2, Address: 9, Vendor: 0x1234, Product: 0x0002, Class: ClassHID}
+	lib.plug(fakeDevice{devDesc: hid})
+	select {
+	case ev := <-events:
+		if ev.Type != HotplugEventDeviceArrived || ev.Desc != hid {
+			t.Errorf("got event %s for %v, want %s for %v", ev.Type, ev.Desc, HotplugEventDeviceArrived, hid)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a hotplug event")
+	}
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case ev := <-events:
+		t.Errorf("got event %s for %v not matched by the filter", ev.Type, ev.Desc)
+	default:
+	}
+}
+
+func TestWaitForDevice(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close: %v", err)
+		}
+	}()
+
+	// Already attached device is returned immediately.
+	dev, err := ctx.WaitForDevice(context.Background(), func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x9999 && desc.Product == 0x0001
+	})
+	if err != nil {
+		t.Fatalf("WaitForDevice(9999:0001): %v", err)
+	}
+	dev.Close()
+
+	// No matching device, the wait times out.
+	isBootloader := func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x1234 && desc.Product == 0xb007
+	}
+	tctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if dev, err := ctx.WaitForDevice(tctx, isBootloader); err != context.DeadlineExceeded {
+		if dev != nil {
+			dev.Close()
+		}
+		t.Fatalf("WaitForDevice(1234:b007) with no device: got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The device is attached while waiting.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 2, Address: 5, Vendor: 0x1234, Product: 0xb007}})
+	}()
+	tctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dev, err = ctx.WaitForDevice(tctx, isBootloader)
+	if err != nil {
+		t.Fatalf("WaitForDevice(1234:b007): %v", err)
+	}
+	if dev.Desc.Bus != 2 || dev.Desc.Address != 5 {
+		t.Errorf("WaitForDevice(1234:b007): got device %s, want bus 2 address 5", dev)
+	}
+	dev.Close()
+}
+
+func TestReenumerate(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close: %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	// Reset without re-enumeration keeps the same device.
+	got, err := dev.Reenumerate(context.Background(), true, nil)
+	if err != nil {
+		t.Fatalf("Reenumerate(reset): %v", err)
+	}
+	if got != dev {
+		t.Errorf("Reenumerate(reset): got device %s, want the same device %s", got, dev)
+	}
+
+	// A device with an open config can't be closed.
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	if _, err := dev.Reenumerate(context.Background(), false, nil); err == nil {
+		t.Errorf("Reenumerate() with an open config: got nil error, want non-nil")
+	}
+	if err := cfg.Close(); err != nil {
+		t.Fatalf("%s.Close(): %v", cfg, err)
+	}
+
+	// The firmware loader drops off the bus and comes back with a new PID.
+	var old *libusbDevice
+	lib.mu.Lock()
+	for d, fd := range lib.fakeDevices {
+		if fd.devDesc.Vendor == 0x9999 && fd.devDesc.Product == 0x0001 {
+			old = d
+		}
+	}
+	lib.mu.Unlock()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lib.unplug(old)
+		lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 1, Address: 4, Vendor: 0x9999, Product: 0x0002}})
+	}()
+	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err = dev.Reenumerate(tctx, false, func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x9999 && desc.Product == 0x0002
+	})
+	if err != nil {
+		t.Fatalf("Reenumerate(): %v", err)
+	}
+	defer got.Close()
+	if got.Desc.Address != 4 {
+		t.Errorf("Reenumerate(): got device %s, want address 4", got)
+	}
+	if _, err := dev.Manufacturer(); err == nil {
+		t.Errorf("Manufacturer() on the old device: got nil error, want non-nil after Reenumerate")
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hub implements the USB hub class requests, which report and
+// control the state of the downstream ports of a hub.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x05e3, 0x0610)
+//	h, err := hub.New(dev)
+//	if err != nil { ... }
+//	for port := 1; port <= h.Desc.NumPorts; port++ {
+//	  st, _ := h.PortStatus(port)
+//	  fmt.Println(port, st)
+//	}
+package hub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// Hub class requests and descriptor types.
+const (
+	requestGetStatus     = 0x00
+	requestClearFeature  = 0x01
+	requestSetFeature    = 0x03
+	requestGetDescriptor = 0x06
+
+	descTypeHub           = 0x29
+	descTypeSuperSpeedHub = 0x2a
+
+	hubDescSize = 7
+)
+
+// Controller is the subset of *gousb.Device used to talk to a hub.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// Descriptor is the hub descriptor.
+type Descriptor struct {
+	// NumPorts is the number of downstream ports of the hub.
+	NumPorts int
+	// Characteristics is the raw wHubCharacteristics field.
+	Characteristics uint16
+	// PowerOnToPowerGood is the time from powering on a port until
+	// the power is good on that port.
+	PowerOnToPowerGood time.Duration
+	// ControllerCurrent is the maximum current drawn by the hub
+	// controller. USB 2.0 hubs report it in mA, SuperSpeed hubs in units
+	// of 4 mA.
+	ControllerCurrent gousb.Milliamperes
+}
+
+// PerPortPower is true if the hub switches the power of each port
+// individually.
+func (d Descriptor) PerPortPower() bool {
+	return d.Characteristics&0x03 == 0x01
+}
+
+// Hub is a USB hub device.
+type Hub struct {
+	dev Controller
+	// SuperSpeed is true for the SuperSpeed part of a USB 3.x hub,
+	// which uses a different hub descriptor and port status layout.
+	SuperSpeed bool
+	// Desc is the hub descriptor.
+	Desc Descriptor
+}
+
+// New returns a Hub for dev and reads its hub descriptor. dev needs
+// to be a hub.
+func New(dev *gousb.Device) (*Hub, error) {
+	if dev.Desc.Class != gousb.ClassHub {
+		return nil, fmt.Errorf("%s is not a hub (class %s)", dev, dev.Desc.Class)
+	}
+	return NewWithController(dev, dev.Desc.Speed >= gousb.SpeedSuper)
+}
+
+// NewWithController returns a Hub that sends its requests through c.
+// superSpeed selects the USB 3.x hub protocol.
+func NewWithController(c Controller, superSpeed bool) (*Hub, error) {
+	h := &Hub{dev: c, SuperSpeed: superSpeed}
+	descType := descTypeHub
+	if superSpeed {
+		descType = descTypeSuperSpeedHub
+	}
+	buf := make([]byte, 64)
+	n, err := c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlDevice, requestGetDescriptor, uint16(descType)<<8, 0, buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the hub descriptor: %v", err)
+	}
+	if n < hubDescSize || buf[1] != byte(descType) {
+		return nil, fmt.Errorf("invalid hub descriptor % x", buf[:n])
+	}
+	h.Desc = Descriptor{
+		NumPorts:           int(buf[2]),
+		Characteristics:    uint16(buf[3]) | uint16(buf[4])<<8,
+		PowerOnToPowerGood: time.Duration(buf[5]) * 2 * time.Millisecond,
+		ControllerCurrent:  gousb.Milliamperes(buf[6]),
+	}
+	if superSpeed {
+		h.Desc.ControllerCurrent *= 4
+	}
+	return h, nil
+}
+
+// PortStatus is the state of a downstream port of a hub.
+type PortStatus struct {
+	// Connection is true if a device is attached to the port.
+	Connection bool
+	// Enable is true if the port is enabled.
+	Enable bool
+	// Suspend is true if the attached device is suspended.
+	// Only reported by USB 2.0 hubs.
+	Suspend bool
+	// OverCurrent is true if the port is in an over-current condition.
+	OverCurrent bool
+	// Reset is true while the port is being reset.
+	Reset bool
+	// Power is true if the port is powered.
+	Power bool
+	// Speed is the speed of the attached device.
+	Speed gousb.Speed
+	// LinkState is the link state of a SuperSpeed port, e.g. 0 for U0.
+	LinkState int
+
+	// Change holds the raw wPortChange bits, which report state changes
+	// since they were last cleared.
+	Change uint16
+}
+
+// Port change bits.
+const (
+	ChangeConnection  = 1 << 0
+	ChangeEnable      = 1 << 1
+	ChangeSuspend     = 1 << 2
+	ChangeOverCurrent = 1 << 3
+	ChangeReset       = 1 << 4
+)
+
+func (s PortStatus) String() string {
+	var flags []string
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{
+		{s.Connection, "connected"},
+		{s.Enable, "enabled"},
+		{s.Suspend, "suspended"},
+		{s.OverCurrent, "over-current"},
+		{s.Reset, "reset"},
+		{s.Power, "powered"},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	if s.Connection {
+		flags = append(flags, fmt.Sprintf("%s speed", s.Speed))
+	}
+	if len(flags) == 0 {
+		return "off"
+	}
+	return strings.Join(flags, ", ")
+}
+
+// superSpeedPortSpeed decodes the port speed field of a USB 3.0 hub,
+// which only reports 0 for SuperSpeed.
+func superSpeedPortSpeed(v uint16) gousb.Speed {
+	if v == 0 {
+		return gousb.SpeedSuper
+	}
+	return gousb.SpeedUnknown
+}
+
+// decodePortStatus decodes the wPortStatus and wPortChange fields.
+func decodePortStatus(st, change uint16, superSpeed bool) PortStatus {
+	ret := PortStatus{
+		Connection:  st&(1<<0) != 0,
+		Enable:      st&(1<<1) != 0,
+		OverCurrent: st&(1<<3) != 0,
+		Reset:       st&(1<<4) != 0,
+		Change:      change,
+	}
+	if superSpeed {
+		ret.LinkState = int(st>>5) & 0x0f
+		ret.Power = st&(1<<9) != 0
+		ret.Speed = superSpeedPortSpeed(st >> 10 & 0x07)
+		return ret
+	}
+	ret.Suspend = st&(1<<2) != 0
+	ret.Power = st&(1<<8) != 0
+	switch {
+	case st&(1<<9) != 0:
+		ret.Speed = gousb.SpeedLow
+	case st&(1<<10) != 0:
+		ret.Speed = gousb.SpeedHigh
+	default:
+		ret.Speed = gousb.SpeedFull
+	}
+	return ret
+}
+
+// PortStatus returns the status of the downstream port, numbered from 1.
+func (h *Hub) PortStatus(port int) (PortStatus, error) {
+	if port < 1 || port > h.Desc.NumPorts {
+		return PortStatus{}, fmt.Errorf("port %d out of range, the hub has %d ports", port, h.Desc.NumPorts)
+	}
+	buf := make([]byte, 4)
+	n, err := h.dev.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlOther, requestGetStatus, 0, uint16(port), buf)
+	if err != nil {
+		return PortStatus{}, fmt.Errorf("failed to read the status of port %d: %v", port, err)
+	}
+	if n != 4 {
+		return PortStatus{}, fmt.Errorf("status of port %d: got %d bytes, want 4", port, n)
+	}
+	st := uint16(buf[0]) | uint16(buf[1])<<8
+	change := uint16(buf[2]) | uint16(buf[3])<<8
+	return decodePortStatus(st, change, h.SuperSpeed), nil
+}
+
+// PortFeature is a hub class feature selector of a port.
+type PortFeature uint16
+
+// Port feature selectors, as defined in the USB 2.0 spec.
+const (
+	PortFeatureEnable            PortFeature = 1
+	PortFeatureSuspend           PortFeature = 2
+	PortFeatureReset             PortFeature = 4
+	PortFeaturePower             PortFeature = 8
+	PortFeatureChangeConnection  PortFeature = 16
+	PortFeatureChangeEnable      PortFeature = 17
+	PortFeatureChangeSuspend     PortFeature = 18
+	PortFeatureChangeOverCurrent PortFeature = 19
+	PortFeatureChangeReset       PortFeature = 20
+)
+
+func (h *Hub) portFeature(req uint8, port int, f PortFeature) error {
+	if port < 1 || port > h.Desc.NumPorts {
+		return fmt.Errorf("port %d out of range, the hub has %d ports", port, h.Desc.NumPorts)
+	}
+	if _, err := h.dev.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlOther, req, uint16(f), uint16(port), nil); err != nil {
+		return fmt.Errorf("failed to change feature %d of port %d: %v", f, port, err)
+	}
+	return nil
+}
+
+// SetPortFeature sets the feature f of the downstream port, e.g.
+// PortFeaturePower to power on the port.
+func (h *Hub) SetPortFeature(port int, f PortFeature) error {
+	return h.portFeature(requestSetFeature, port, f)
+}
+
+// ClearPortFeature clears the feature f of the downstream port, e.g.
+// PortFeaturePower to power off the port, or one of the change features
+// to acknowledge a change reported in PortStatus.Change.
+func (h *Hub) ClearPortFeature(port int, f PortFeature) error {
+	return h.portFeature(requestClearFeature, port, f)
+}
+
+// PowerCycle powers off the downstream port for at least off, then powers
+// it back on and waits until the power is good. The device attached to
+// the port is disconnected and enumerated again. PowerCycle fails on
+// hubs without per-port power switching, since those power all ports
+// together, or don't switch the power at all.
+func (h *Hub) PowerCycle(port int, off time.Duration) error {
+	if !h.Desc.PerPortPower() {
+		return fmt.Errorf("the hub doesn't support per-port power switching")
+	}
+	if err := h.ClearPortFeature(port, PortFeaturePower); err != nil {
+		return err
+	}
+	if off < h.Desc.PowerOnToPowerGood {
+		off = h.Desc.PowerOnToPowerGood
+	}
+	time.Sleep(off)
+	if err := h.SetPortFeature(port, PortFeaturePower); err != nil {
+		return err
+	}
+	time.Sleep(h.Desc.PowerOnToPowerGood)
+	return nil
+}
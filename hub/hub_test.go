@@ -0,0 +1,136 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// fakeHub is a 4 port USB 2.0 hub with per-port power switching.
+type fakeHub struct {
+	ports [5]uint16
+}
+
+func (f *fakeHub) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	switch {
+	case request == requestGetDescriptor && val == descTypeHub<<8:
+		return copy(data, []byte{0x09, 0x29, 0x04, 0x09, 0x00, 0x32, 0x64, 0x00, 0xff}), nil
+	case request == requestGetDescriptor && val == descTypeSuperSpeedHub<<8:
+		return copy(data, []byte{0x0c, 0x2a, 0x04, 0x09, 0x00, 0x32, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00}), nil
+	case request == requestGetStatus && rType&0x1f == gousb.ControlOther:
+		st := f.ports[idx]
+		return copy(data, []byte{byte(st), byte(st >> 8), 0x01, 0x00}), nil
+	case request == requestSetFeature && rType&0x1f == gousb.ControlOther:
+		f.ports[idx] |= 1 << val
+		return 0, nil
+	case request == requestClearFeature && rType&0x1f == gousb.ControlOther:
+		f.ports[idx] &^= 1 << val
+		return 0, nil
+	}
+	return 0, errors.New("unsupported request")
+}
+
+func TestPortStatus(t *testing.T) {
+	f := &fakeHub{}
+	f.ports[1] = 0x0100              // powered, empty
+	f.ports[2] = 0x0100 | 0x0400 | 3 // powered, high speed device attached
+	h, err := NewWithController(f, false)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	wantDesc := Descriptor{NumPorts: 4, Characteristics: 0x09, PowerOnToPowerGood: 100 * time.Millisecond, ControllerCurrent: 100}
+	if h.Desc != wantDesc {
+		t.Errorf("hub descriptor: got %+v, want %+v", h.Desc, wantDesc)
+	}
+	if !h.Desc.PerPortPower() {
+		t.Errorf("PerPortPower(): got false, want true")
+	}
+
+	for _, tc := range []struct {
+		port int
+		want PortStatus
+	}{
+		{1, PortStatus{Power: true, Speed: gousb.SpeedFull, Change: ChangeConnection}},
+		{2, PortStatus{Connection: true, Enable: true, Power: true, Speed: gousb.SpeedHigh, Change: ChangeConnection}},
+	} {
+		got, err := h.PortStatus(tc.port)
+		if err != nil {
+			t.Fatalf("PortStatus(%d): %v", tc.port, err)
+		}
+		if got != tc.want {
+			t.Errorf("PortStatus(%d): got %+v, want %+v", tc.port, got, tc.want)
+		}
+	}
+	if _, err := h.PortStatus(5); err == nil {
+		t.Errorf("PortStatus(5): got nil error, want non-nil")
+	}
+}
+
+func TestSuperSpeedDescriptor(t *testing.T) {
+	h, err := NewWithController(&fakeHub{}, true)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	// bHubContrCurrent of a SuperSpeed hub is in units of 4 mA.
+	wantDesc := Descriptor{NumPorts: 4, Characteristics: 0x09, PowerOnToPowerGood: 100 * time.Millisecond, ControllerCurrent: 100}
+	if h.Desc != wantDesc {
+		t.Errorf("hub descriptor: got %+v, want %+v", h.Desc, wantDesc)
+	}
+}
+
+func TestDecodeSuperSpeedPortStatus(t *testing.T) {
+	// Connected, enabled, powered, link in U3.
+	got := decodePortStatus(0x0200|3<<5|0x03, 0, true)
+	want := PortStatus{Connection: true, Enable: true, Power: true, LinkState: 3, Speed: gousb.SpeedSuper}
+	if got != want {
+		t.Errorf("decodePortStatus(): got %+v, want %+v", got, want)
+	}
+	if s, want := got.String(), "connected, enabled, powered, super speed"; s != want {
+		t.Errorf("String(): got %q, want %q", s, want)
+	}
+}
+
+func TestPowerCycle(t *testing.T) {
+	f := &fakeHub{}
+	f.ports[3] = 0x0100
+	h, err := NewWithController(f, false)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	h.Desc.PowerOnToPowerGood = time.Millisecond
+	if err := h.ClearPortFeature(3, PortFeaturePower); err != nil {
+		t.Fatalf("ClearPortFeature(3, power): %v", err)
+	}
+	if st, err := h.PortStatus(3); err != nil || st.Power {
+		t.Errorf("PortStatus(3) after ClearPortFeature: got %+v, %v, want port powered off", st, err)
+	}
+	if err := h.PowerCycle(3, time.Millisecond); err != nil {
+		t.Fatalf("PowerCycle(3): %v", err)
+	}
+	if st, err := h.PortStatus(3); err != nil || !st.Power {
+		t.Errorf("PortStatus(3) after PowerCycle: got %+v, %v, want port powered on", st, err)
+	}
+	if err := h.SetPortFeature(0, PortFeaturePower); err == nil {
+		t.Errorf("SetPortFeature(0, power): got nil error, want non-nil")
+	}
+	h.Desc.Characteristics = 0
+	if err := h.PowerCycle(3, 0); err == nil {
+		t.Errorf("PowerCycle() on a ganged hub: got nil error, want non-nil")
+	}
+}
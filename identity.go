@@ -0,0 +1,160 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Identity identifies a physical device across re-enumerations, which
+// change its bus address and often its product ID, e.g. when it jumps to
+// or from a bootloader. See Device.Identity and Device.Relocate.
+type Identity struct {
+	// Bus and Path are the bus number and the port numbers from the root
+	// hub to the device, see DeviceDesc.Path. Path is empty if the
+	// platform doesn't report it.
+	Bus  int
+	Path []int
+	// ContainerID is the container ID of the device, from its BOS
+	// descriptor, or nil if the device doesn't report one. Unlike the
+	// path, it follows the device between ports.
+	ContainerID []byte
+}
+
+// String returns the port path of the identity in the format used by
+// Linux sysfs, e.g. "1-2.4", followed by the container ID if known.
+func (id Identity) String() string {
+	s := (&DeviceDesc{Bus: id.Bus, Path: id.Path}).portPath()
+	if id.ContainerID != nil {
+		s += fmt.Sprintf(",container=%x", id.ContainerID)
+	}
+	return s
+}
+
+// atPath reports whether desc is attached at the path of the identity.
+func (id Identity) atPath(desc *DeviceDesc) bool {
+	if len(id.Path) == 0 || desc.Bus != id.Bus || len(desc.Path) != len(id.Path) {
+		return false
+	}
+	for i, p := range id.Path {
+		if desc.Path[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// Identity returns the identity of the device, to find it again with
+// Relocate after it re-enumerates. The container ID is read from the BOS
+// descriptor of devices conforming to USB 2.1 or later.
+func (d *Device) Identity() (Identity, error) {
+	if d.handle == nil {
+		return Identity{}, fmt.Errorf("Identity() called on %s after Close", d)
+	}
+	id := Identity{Bus: d.Desc.Bus, Path: append([]int(nil), d.Desc.Path...)}
+	if d.Desc.Spec >= Version(2, 1) {
+		bos, err := d.BOS()
+		if err != nil {
+			return Identity{}, err
+		}
+		id.ContainerID = bos.ContainerID
+	}
+	if len(id.Path) == 0 && id.ContainerID == nil {
+		return Identity{}, fmt.Errorf("%s has neither a known port path nor a container ID to identify it", d)
+	}
+	return id, nil
+}
+
+// Relocate waits for the device with the identity id, taken with Identity
+// before the device re-enumerated, to be attached again and rebinds d to
+// it. d keeps being the same *Device, so objects wrapping it keep working,
+// but its descriptor, handle and string descriptor cache are replaced.
+//
+// A device matches if it's attached at the port path of id, or, if id has
+// a container ID, if it reports the same container ID. The latter devices
+// are opened to read their BOS descriptor, to find the device on another
+// port. The device at the bus address of d before Relocate is never
+// a match, as that's the old device about to go away.
+//
+// The config and interfaces claimed on d are released and the old handle
+// is closed first, they need to be claimed again on the new device.
+// Relocate must not be called concurrently with other methods of d. If
+// ctx is done before the device is found, d is left closed.
+func (d *Device) Relocate(ctx context.Context, id Identity) error {
+	if d.handle == nil {
+		return fmt.Errorf("Relocate() called on %s after Close", d)
+	}
+	d.mu.Lock()
+	cfg := d.claimed
+	d.mu.Unlock()
+	if cfg != nil {
+		if err := cfg.release(); err != nil {
+			return fmt.Errorf("failed to release %s before relocating the device: %v", cfg, err)
+		}
+	}
+	c := d.ctx
+	bus, addr := d.Desc.Bus, d.Desc.Address
+	d.Close()
+
+	type busAddr struct{ bus, addr int }
+	rejected := map[busAddr]bool{{bus, addr}: true}
+	for {
+		nd, err := c.WaitForDevice(ctx, func(desc *DeviceDesc) bool {
+			if rejected[busAddr{desc.Bus, desc.Address}] {
+				return false
+			}
+			return id.atPath(desc) || id.ContainerID != nil && desc.Spec >= Version(2, 1)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to relocate %s: %v", id, err)
+		}
+		if !id.atPath(nd.Desc) {
+			bos, err := nd.BOS()
+			if err != nil || !bytes.Equal(bos.ContainerID, id.ContainerID) {
+				rejected[busAddr{nd.Desc.Bus, nd.Desc.Address}] = true
+				nd.Close()
+				continue
+			}
+		}
+		d.rebind(nd)
+		return nil
+	}
+}
+
+// rebind moves the open handle of nd to d, which was closed, and drops nd.
+func (d *Device) rebind(nd *Device) {
+	c := d.ctx
+	c.mu.Lock()
+	delete(c.devices, nd)
+	c.devices[d] = true
+	c.mu.Unlock()
+
+	d.mu.Lock()
+	d.handle, d.Desc = nd.handle, nd.Desc
+	d.claimed = nil
+	d.mu.Unlock()
+	d.strMu.Lock()
+	d.strCache = nil
+	d.strMu.Unlock()
+	d.disconnect.mu.Lock()
+	d.disconnect.done, d.disconnect.gone = nil, false
+	d.disconnect.mu.Unlock()
+	if d.autodetach {
+		d.SetAutoDetach(true)
+	}
+	c.log(LogOpOpen, nil, LogField{"device", d.String()})
+}
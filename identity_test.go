@@ -0,0 +1,123 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRelocate(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	id, err := dev.Identity()
+	if err != nil {
+		t.Fatalf("Identity(): %v", err)
+	}
+	if got, want := id.String(), "1-1"; got != want {
+		t.Errorf("Identity(): got %s, want %s", got, want)
+	}
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	if _, err := cfg.Interface(0, 0); err != nil {
+		t.Fatalf("%s.Interface(0, 0): %v", cfg, err)
+	}
+
+	// The device jumps to its bootloader, which has another PID. A device
+	// attached elsewhere meanwhile isn't a match.
+	var old *libusbDevice
+	lib.mu.Lock()
+	for d, fd := range lib.fakeDevices {
+		if fd.devDesc == dev.Desc {
+			old = d
+		}
+	}
+	lib.mu.Unlock()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lib.unplug(old)
+		lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 1, Address: 8, Path: []int{4}, Vendor: 0x9999, Product: 0xb007}})
+		time.Sleep(10 * time.Millisecond)
+		lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 1, Address: 9, Path: []int{1}, Vendor: 0x9999, Product: 0xb007}})
+	}()
+	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dev.Relocate(tctx, id); err != nil {
+		t.Fatalf("Relocate(): %v", err)
+	}
+	if dev.Desc.Address != 9 || dev.Desc.Product != 0xb007 {
+		t.Errorf("Relocate(): rebound to %s, want the device at address 9", dev)
+	}
+	if _, err := dev.Manufacturer(); err != nil {
+		t.Errorf("Manufacturer() after Relocate(): %v", err)
+	}
+}
+
+func TestRelocateContainerID(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	cid := []byte("0123456789abcdef")
+	bos := append([]byte{0x05, 0x0f, 0x19, 0x00, 0x01, 0x14, 0x10, 0x04, 0x00}, cid...)
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		return copy(data, bos), nil
+	}
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	// The device comes back on another port, e.g. after it was moved.
+	id := Identity{Bus: 1, Path: []int{1}, ContainerID: cid}
+	lib.plug(fakeDevice{devDesc: &DeviceDesc{Bus: 2, Address: 3, Path: []int{2}, Spec: Version(2, 1), Vendor: 0x9999, Product: 0x0002}})
+	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dev.Relocate(tctx, id); err != nil {
+		t.Fatalf("Relocate(): %v", err)
+	}
+	if dev.Desc.Bus != 2 || dev.Desc.Address != 3 {
+		t.Errorf("Relocate(): rebound to %s, want the device at bus 2 address 3", dev)
+	}
+
+	tctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := dev.Relocate(tctx, Identity{Bus: 3, Path: []int{1}, ContainerID: []byte("fedcba9876543210")}); err == nil {
+		t.Error("Relocate() to a missing device: got nil error, want non-nil")
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration && linux
+// +build integration,linux
+
+// Integration tests in this file run against a real USB device emulated by
+// the Linux kernel: a configfs gadget with the Loopback function, bound to a
+// dummy_hcd UDC. They require root privileges and the libcomposite,
+// usb_f_ss_lb and dummy_hcd kernel modules. Run them with:
+//
+//	sudo go test -tags integration -run Integration .
+//
+// The UDC used can be selected with the GOUSB_UDC environment variable,
+// by default the first dummy_udc found in /sys/class/udc is used.
+
+package gousb_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+const (
+	gadgetRoot    = "/sys/kernel/config/usb_gadget"
+	gadgetName    = "gousb-integration"
+	gadgetVendor  = gousb.ID(0x1209) // pid.codes
+	gadgetProduct = gousb.ID(0x0001) // pid.codes test PID
+	gadgetSerial  = "gousb-0001"
+)
+
+// gadget is a configfs USB gadget bound to a UDC for the duration of a test.
+type gadget struct {
+	dir string
+	udc string
+	// undo lists the paths to remove on teardown, in order.
+	undo []string
+}
+
+func (g *gadget) write(t *testing.T, rel, val string) {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(g.dir, rel), []byte(val), 0644); err != nil {
+		t.Fatalf("gadget: %v", err)
+	}
+}
+
+func (g *gadget) mkdir(t *testing.T, rel string) {
+	t.Helper()
+	p := filepath.Join(g.dir, rel)
+	if err := os.Mkdir(p, 0755); err != nil {
+		t.Fatalf("gadget: %v", err)
+	}
+	g.undo = append([]string{p}, g.undo...)
+}
+
+func (g *gadget) symlink(t *testing.T, target, rel string) {
+	t.Helper()
+	p := filepath.Join(g.dir, rel)
+	if err := os.Symlink(filepath.Join(g.dir, target), p); err != nil {
+		t.Fatalf("gadget: %v", err)
+	}
+	g.undo = append([]string{p}, g.undo...)
+}
+
+// teardown unbinds the gadget and removes its configfs tree. configfs
+// requires the entries to be removed in reverse order of creation.
+func (g *gadget) teardown(t *testing.T) {
+	if g.udc != "" {
+		if err := ioutil.WriteFile(filepath.Join(g.dir, "UDC"), []byte("\n"), 0644); err != nil {
+			t.Errorf("gadget: unbind from %s: %v", g.udc, err)
+		}
+	}
+	for _, p := range g.undo {
+		if err := os.Remove(p); err != nil {
+			t.Errorf("gadget: %v", err)
+		}
+	}
+}
+
+// findUDC returns the name of the UDC to bind the gadget to.
+func findUDC() (string, error) {
+	if udc := os.Getenv("GOUSB_UDC"); udc != "" {
+		return udc, nil
+	}
+	udcs, err := ioutil.ReadDir("/sys/class/udc")
+	if err != nil {
+		return "", err
+	}
+	for _, u := range udcs {
+		if strings.HasPrefix(u.Name(), "dummy_udc") {
+			return u.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("no dummy_udc found in /sys/class/udc")
+}
+
+// setupGadget creates a loopback gadget on a dummy_hcd UDC. The test is
+// skipped if the environment doesn't support gadgets. The caller must call
+// teardown on the returned gadget.
+func setupGadget(t *testing.T) *gadget {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		t.Skip("integration tests require root privileges")
+	}
+	// Loading the modules is best effort, they might be built in.
+	exec.Command("modprobe", "-a", "libcomposite", "usb_f_ss_lb", "dummy_hcd").Run()
+	if _, err := os.Stat(gadgetRoot); err != nil {
+		t.Skipf("configfs gadget support not available: %v", err)
+	}
+	udc, err := findUDC()
+	if err != nil {
+		t.Skipf("no UDC available: %v", err)
+	}
+
+	g := &gadget{dir: filepath.Join(gadgetRoot, gadgetName)}
+	if err := os.Mkdir(g.dir, 0755); err != nil {
+		t.Fatalf("gadget: %v", err)
+	}
+	g.undo = []string{g.dir}
+	done := false
+	defer func() {
+		if !done {
+			g.teardown(t)
+		}
+	}()
+
+	g.write(t, "idVendor", fmt.Sprintf("0x%04x", uint16(gadgetVendor)))
+	g.write(t, "idProduct", fmt.Sprintf("0x%04x", uint16(gadgetProduct)))
+	g.write(t, "bcdUSB", "0x0200")
+	g.mkdir(t, "strings/0x409")
+	g.write(t, "strings/0x409/manufacturer", "gousb")
+	g.write(t, "strings/0x409/product", "gousb loopback")
+	g.write(t, "strings/0x409/serialnumber", gadgetSerial)
+	g.mkdir(t, "configs/c.1")
+	g.write(t, "configs/c.1/MaxPower", "100")
+	g.mkdir(t, "configs/c.1/strings/0x409")
+	g.write(t, "configs/c.1/strings/0x409/configuration", "loopback")
+	g.mkdir(t, "functions/Loopback.0")
+	g.symlink(t, "functions/Loopback.0", "configs/c.1/Loopback.0")
+
+	g.write(t, "UDC", udc)
+	g.udc = udc
+	done = true
+	return g
+}
+
+// openGadget waits for the gadget to enumerate and opens it. The caller
+// must close the returned device.
+func openGadget(t *testing.T, ctx *gousb.Context) *gousb.Device {
+	t.Helper()
+	wctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dev, err := ctx.WaitForDevice(wctx, func(desc *gousb.DeviceDesc) bool {
+		return desc.Vendor == gadgetVendor && desc.Product == gadgetProduct
+	})
+	if err != nil {
+		t.Fatalf("WaitForDevice(%s:%s): %v", gadgetVendor, gadgetProduct, err)
+	}
+	return dev
+}
+
+func TestIntegrationEnumeration(t *testing.T) {
+	g := setupGadget(t)
+	defer g.teardown(t)
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	dev := openGadget(t, ctx)
+	defer dev.Close()
+
+	for _, tc := range []struct {
+		name string
+		get  func() (string, error)
+		want string
+	}{
+		{"Manufacturer", dev.Manufacturer, "gousb"},
+		{"Product", dev.Product, "gousb loopback"},
+		{"SerialNumber", dev.SerialNumber, gadgetSerial},
+	} {
+		got, err := tc.get()
+		if err != nil {
+			t.Errorf("%s(): %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s(): got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	cfg, ok := dev.Desc.Configs[1]
+	if !ok {
+		t.Fatalf("%s: configuration 1 not found in %v", dev, dev.Desc.Configs)
+	}
+	if got, want := cfg.MaxPower, gousb.Milliamperes(100); got != want {
+		t.Errorf("%s: MaxPower: got %v, want %v", cfg, got, want)
+	}
+	if len(cfg.Interfaces) != 1 {
+		t.Fatalf("%s: got %d interfaces, want 1", cfg, len(cfg.Interfaces))
+	}
+	alt := cfg.Interfaces[0].AltSettings[0]
+	if alt.Class != gousb.ClassVendorSpec {
+		t.Errorf("%s: got class %s, want %s", alt, alt.Class, gousb.ClassVendorSpec)
+	}
+	if _, ok := alt.BulkIn(); !ok {
+		t.Errorf("%s: no bulk IN endpoint", alt)
+	}
+	if _, ok := alt.BulkOut(); !ok {
+		t.Errorf("%s: no bulk OUT endpoint", alt)
+	}
+}
+
+func TestIntegrationControl(t *testing.T) {
+	g := setupGadget(t)
+	defer g.teardown(t)
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	dev := openGadget(t, ctx)
+	defer dev.Close()
+
+	// GET_STATUS on the device.
+	status := make([]byte, 2)
+	n, err := dev.Control(gousb.ControlIn|gousb.ControlDevice, 0x00, 0, 0, status)
+	if err != nil {
+		t.Fatalf("%s: GET_STATUS: %v", dev, err)
+	}
+	if n != len(status) {
+		t.Errorf("%s: GET_STATUS returned %d bytes, want %d", dev, n, len(status))
+	}
+
+	// GET_DESCRIPTOR(DEVICE) must match the cached device descriptor.
+	desc := make([]byte, 18)
+	n, err = dev.Control(gousb.ControlIn|gousb.ControlDevice, 0x06, 0x0100, 0, desc)
+	if err != nil {
+		t.Fatalf("%s: GET_DESCRIPTOR: %v", dev, err)
+	}
+	if n != len(desc) {
+		t.Fatalf("%s: GET_DESCRIPTOR returned %d bytes, want %d", dev, n, len(desc))
+	}
+	vid := gousb.ID(desc[8]) | gousb.ID(desc[9])<<8
+	pid := gousb.ID(desc[10]) | gousb.ID(desc[11])<<8
+	if vid != gadgetVendor || pid != gadgetProduct {
+		t.Errorf("%s: GET_DESCRIPTOR returned %s:%s, want %s:%s", dev, vid, pid, gadgetVendor, gadgetProduct)
+	}
+}
+
+func TestIntegrationBulkLoopback(t *testing.T) {
+	g := setupGadget(t)
+	defer g.teardown(t)
+	ctx := gousb.NewContext()
+	defer ctx.Close()
+	dev := openGadget(t, ctx)
+	defer dev.Close()
+
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+	intf, err := cfg.Interface(0, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(0, 0): %v", cfg, err)
+	}
+	defer intf.Close()
+
+	inDesc, ok := intf.Setting.BulkIn()
+	if !ok {
+		t.Fatalf("%s: no bulk IN endpoint", intf)
+	}
+	outDesc, ok := intf.Setting.BulkOut()
+	if !ok {
+		t.Fatalf("%s: no bulk OUT endpoint", intf)
+	}
+	in, err := intf.InEndpoint(inDesc.Number)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(%d): %v", intf, inDesc.Number, err)
+	}
+	out, err := intf.OutEndpoint(outDesc.Number)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(%d): %v", intf, outDesc.Number, err)
+	}
+
+	tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	buf := make([]byte, inDesc.MaxPacketSize)
+	for i := 0; i < 16; i++ {
+		want := bytes.Repeat([]byte{byte(i)}, outDesc.MaxPacketSize)
+		if n, err := out.WriteContext(tctx, want); err != nil || n != len(want) {
+			t.Fatalf("%s.Write(): got (%d, %v), want (%d, nil)", out, n, err, len(want))
+		}
+		n, err := in.ReadContext(tctx, buf)
+		if err != nil {
+			t.Fatalf("%s.Read(): %v", in, err)
+		}
+		if !bytes.Equal(buf[:n], want) {
+			t.Fatalf("%s.Read() #%d: got %d bytes % x..., want %d bytes of %02x", in, i, n, buf[:4], len(want), i)
+		}
+	}
+}
@@ -16,8 +16,11 @@
 package gousb
 
 import (
+	"encoding/json"
 	"fmt"
+	"runtime"
 	"sort"
+	"sync"
 )
 
 // InterfaceDesc contains information about a USB interface, extracted from
@@ -51,6 +54,15 @@ type InterfaceSetting struct {
 	// Endpoints enumerates the endpoints available on this interface with
 	// this alternate setting.
 	Endpoints map[EndpointAddress]EndpointDesc
+	// Extra holds the raw class-specific and vendor-specific descriptors
+	// that follow the interface descriptor, before its first endpoint,
+	// e.g. the HID descriptor or the UAC and UVC class-specific interface
+	// descriptors. It's empty if there are none.
+	Extra []byte
+	// ClassDesc holds the class-specific descriptors decoded by the parser
+	// registered for Class and SubClass with RegisterClassDescriptorParser,
+	// or an error if the parser failed. It's nil if there's no parser.
+	ClassDesc interface{} `json:"-"`
 
 	iInterface int // index of a string descriptor describing this interface.
 }
@@ -64,12 +76,84 @@ func (a InterfaceSetting) sortedEndpointIds() []string {
 	return eps
 }
 
+// interfaceSetting has the fields of InterfaceSetting, without its methods.
+type interfaceSetting InterfaceSetting
+
+// MarshalJSON implements json.Marshaler. Along with the exported fields,
+// the encoded descriptor includes the index of the interface string
+// descriptor.
+func (a InterfaceSetting) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		interfaceSetting
+		IInterface int `json:"iInterface"`
+	}{interfaceSetting(a), a.iInterface})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *InterfaceSetting) UnmarshalJSON(b []byte) error {
+	v := struct {
+		*interfaceSetting
+		IInterface int `json:"iInterface"`
+	}{interfaceSetting: (*interfaceSetting)(a)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	a.iInterface = v.IInterface
+	parseClassDesc(a)
+	return nil
+}
+
 // String returns a human-readable description of the particular
 // alternate setting of an interface.
 func (a InterfaceSetting) String() string {
 	return fmt.Sprintf("Interface %d alternate setting %d (available endpoints: %v)", a.Number, a.Alternate, a.sortedEndpointIds())
 }
 
+// FindEndpoint returns the endpoint of the alternate setting with
+// the given transfer type and direction. If there are several, the one
+// with the lowest address is returned. ok is false if there's none.
+func (a InterfaceSetting) FindEndpoint(tt TransferType, dir EndpointDirection) (desc EndpointDesc, ok bool) {
+	for _, ep := range a.Endpoints {
+		if ep.TransferType != tt || ep.Direction != dir {
+			continue
+		}
+		if !ok || ep.Address < desc.Address {
+			desc, ok = ep, true
+		}
+	}
+	return desc, ok
+}
+
+// BulkIn returns the first bulk IN endpoint, see FindEndpoint.
+func (a InterfaceSetting) BulkIn() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeBulk, EndpointDirectionIn)
+}
+
+// BulkOut returns the first bulk OUT endpoint, see FindEndpoint.
+func (a InterfaceSetting) BulkOut() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeBulk, EndpointDirectionOut)
+}
+
+// InterruptIn returns the first interrupt IN endpoint, see FindEndpoint.
+func (a InterfaceSetting) InterruptIn() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeInterrupt, EndpointDirectionIn)
+}
+
+// InterruptOut returns the first interrupt OUT endpoint, see FindEndpoint.
+func (a InterfaceSetting) InterruptOut() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeInterrupt, EndpointDirectionOut)
+}
+
+// IsoIn returns the first isochronous IN endpoint, see FindEndpoint.
+func (a InterfaceSetting) IsoIn() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeIsochronous, EndpointDirectionIn)
+}
+
+// IsoOut returns the first isochronous OUT endpoint, see FindEndpoint.
+func (a InterfaceSetting) IsoOut() (EndpointDesc, bool) {
+	return a.FindEndpoint(TransferTypeIsochronous, EndpointDirectionOut)
+}
+
 // Interface is a representation of a claimed interface with a particular setting.
 // To access device endpoints use InEndpoint() and OutEndpoint() methods.
 // The interface should be Close()d after use.
@@ -77,18 +161,38 @@ type Interface struct {
 	Setting InterfaceSetting
 
 	config *Config
+	// endpoints are the endpoints opened on the interface, indexed by
+	// address, their transfers are cancelled by Close.
+	mu        sync.Mutex
+	endpoints map[EndpointAddress]*endpoint
+	// reattach is set if the kernel driver of the interface was detached
+	// when claiming it, see DetachKernelDriver.
+	reattach bool
 }
 
 func (i *Interface) String() string {
 	return fmt.Sprintf("%s,if=%d,alt=%d", i.config, i.Setting.Number, i.Setting.Alternate)
 }
 
-// Close releases the interface.
+// Close releases the interface. Transfers in flight on its endpoints are
+// cancelled, see CancelAll, and Close waits until they return.
 func (i *Interface) Close() {
 	if i.config == nil {
 		return
 	}
+	i.mu.Lock()
+	eps := i.endpoints
+	i.endpoints = nil
+	i.mu.Unlock()
+	for _, ep := range eps {
+		ep.CancelAll()
+		ep.waitIdle()
+	}
 	i.config.dev.ctx.libusb.release(i.config.dev.handle, uint8(i.Setting.Number))
+	if i.reattach {
+		i.config.dev.ctx.libusb.attachKernelDriver(i.config.dev.handle, uint8(i.Setting.Number))
+	}
+	i.config.dev.ctx.log(LogOpRelease, nil, LogField{"device", i.config.dev.String()}, LogField{"config", i.config.Desc.Number}, LogField{"interface", i.Setting.Number}, LogField{"alt", i.Setting.Alternate})
 	i.config.mu.Lock()
 	defer i.config.mu.Unlock()
 	delete(i.config.claimed, i.Setting.Number)
@@ -101,19 +205,48 @@ func (i *Interface) openEndpoint(epAddr EndpointAddress) (*endpoint, error) {
 	if !ok {
 		return nil, fmt.Errorf("%s does not have endpoint with address %s. Available endpoints: %v", i, epAddr, i.Setting.sortedEndpointIds())
 	}
-	return &endpoint{
+	if ep.TransferType == TransferTypeControl {
+		return nil, fmt.Errorf("%s: endpoint %s is a control endpoint, use Device.Control", i, epAddr)
+	}
+	if isLibusb(i.config.dev.ctx.libusb) && ep.TransferType == TransferTypeIsochronous && !isoSupported(runtime.GOOS) {
+		return nil, newUnsupportedError(fmt.Sprintf("isochronous endpoint %s of %s", epAddr, i), CapabilityHasCapability)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if e, ok := i.endpoints[epAddr]; ok {
+		return e, nil
+	}
+	e := &endpoint{
 		InterfaceSetting: i.Setting,
 		Desc:             ep,
 		h:                i.config.dev.handle,
 		ctx:              i.config.dev.ctx,
-	}, nil
+		dev:              i.config.dev,
+		metrics:          i.config.dev.ctx.metrics.endpoint(i.config.dev, epAddr),
+	}
+	e.maxTransfer = i.config.dev.ctx.maxTransferSize()
+	if isLibusb(i.config.dev.ctx.libusb) {
+		e.softZLP = !zeroPacketSupported(runtime.GOOS)
+	}
+	if i.endpoints == nil {
+		i.endpoints = make(map[EndpointAddress]*endpoint)
+	}
+	i.endpoints[epAddr] = e
+	return e, nil
 }
 
-// InEndpoint prepares an IN endpoint for transfer.
+// InEndpoint prepares an IN endpoint for transfer. epNum is the endpoint
+// number, e.g. 2 for the endpoint with address 0x82. The full address is
+// accepted as well. Repeated calls for the same endpoint return
+// InEndpoints sharing their settings, e.g. the deadline and the transfer
+// flags.
 func (i *Interface) InEndpoint(epNum int) (*InEndpoint, error) {
 	if i.config == nil {
 		return nil, fmt.Errorf("InEndpoint(%d) called on %s after Close", epNum, i)
 	}
+	if epNum&^0x80 < 1 || epNum&^0x80 > 15 {
+		return nil, fmt.Errorf("InEndpoint(%d): invalid endpoint number, want 1..15 or an address 0x81..0x8f", epNum)
+	}
 	ep, err := i.openEndpoint(EndpointAddress(0x80 | epNum))
 	if err != nil {
 		return nil, err
@@ -123,11 +256,20 @@ func (i *Interface) InEndpoint(epNum int) (*InEndpoint, error) {
 	}, nil
 }
 
-// OutEndpoint prepares an OUT endpoint for transfer.
+// OutEndpoint prepares an OUT endpoint for transfer. epNum is the endpoint
+// number, which is also the address of the endpoint. Repeated calls for
+// the same endpoint return OutEndpoints sharing their settings, like
+// InEndpoint.
 func (i *Interface) OutEndpoint(epNum int) (*OutEndpoint, error) {
 	if i.config == nil {
 		return nil, fmt.Errorf("OutEndpoint(%d) called on %s after Close", epNum, i)
 	}
+	if epNum&0x80 != 0 {
+		return nil, fmt.Errorf("OutEndpoint(%d): address %s is an IN endpoint, use InEndpoint", epNum, EndpointAddress(epNum))
+	}
+	if epNum < 1 || epNum > 15 {
+		return nil, fmt.Errorf("OutEndpoint(%d): invalid endpoint number, want 1..15", epNum)
+	}
 	ep, err := i.openEndpoint(EndpointAddress(epNum))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,32 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usbtest has fakes shared by the tests of the class drivers.
+package usbtest
+
+import "io"
+
+// Messages is a gousb.MessageReader that returns the queued messages,
+// then io.EOF.
+type Messages [][]byte
+
+// ReadMessage implements gousb.MessageReader.
+func (m *Messages) ReadMessage() ([]byte, error) {
+	if len(*m) == 0 {
+		return nil, io.EOF
+	}
+	msg := (*m)[0]
+	*m = (*m)[1:]
+	return msg, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"fmt"
+)
+
+// isoPacketResult is the result of a single packet of an isochronous
+// transfer, as reported by libusb.
+type isoPacketResult struct {
+	// length is the requested length of the packet.
+	length int
+	// actual is the number of bytes transferred.
+	actual int
+	status TransferStatus
+}
+
+// IsoPacket is a single packet of an isochronous transfer.
+type IsoPacket struct {
+	// Data is the data received in the packet, a slice of the buffer
+	// passed to ReadIsoPackets. Data may be shorter than the packet size,
+	// or empty if the device had nothing to send in this interval.
+	Data []byte
+	// Status is the status of the packet. Packets with a status other than
+	// TransferCompleted were dropped or damaged and hold no valid data.
+	Status TransferStatus
+}
+
+// ReadIsoPackets performs a single isochronous transfer on the endpoint and
+// returns the individual packets, unlike Read, which concatenates the data
+// of all packets and stops at the first failed one. This allows audio and
+// video pipelines to handle short and dropped packets individually.
+//
+// The transfer consists of len(buf)/MaxPacketSize packets, packet i is read
+// into buf[i*MaxPacketSize:]. The returned error reports the status of
+// the whole transfer, the status of each packet is in IsoPacket.Status.
+// ReadIsoPackets can only be used with isochronous endpoints.
+func (e *InEndpoint) ReadIsoPackets(ctx context.Context, buf []byte) ([]IsoPacket, error) {
+	if e.Desc.TransferType != TransferTypeIsochronous {
+		return nil, fmt.Errorf("ReadIsoPackets() called on %s, which is not an isochronous endpoint", e)
+	}
+	if len(buf) < e.Desc.MaxPacketSize {
+		return nil, fmt.Errorf("ReadIsoPackets(): buffer of %d bytes is smaller than the max packet size %d of %s", len(buf), e.Desc.MaxPacketSize, e)
+	}
+	ctx, done := e.track(ctx)
+	defer done()
+	t, err := newUSBTransfer(e.ctx, e.h, &e.Desc, e.getFlags(), len(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer t.free()
+	t.isoRaw = true
+	if err := t.submit(); err != nil {
+		return nil, err
+	}
+	_, err = t.wait(ctx)
+	if err == TransferCancelled && ctx.Err() == context.DeadlineExceeded {
+		err = TransferTimedOut
+	}
+	if e.dev != nil {
+		e.dev.disconnect.check(err)
+	}
+	data := t.data()
+	ret := make([]IsoPacket, len(t.isoResults))
+	off := 0
+	for i, p := range t.isoResults {
+		start, end := off, off+p.actual
+		if start > len(buf) {
+			start = len(buf)
+		}
+		if end > len(buf) {
+			end = len(buf)
+		}
+		copy(buf[start:end], data[start:end])
+		ret[i] = IsoPacket{Data: buf[start:end], Status: p.status}
+		off += p.length
+	}
+	return ret, err
+}
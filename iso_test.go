@@ -0,0 +1,95 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestReadIsoPackets(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x8888, 0x0002)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(8888:0002): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+	intf, err := cfg.Interface(1, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(1, 0): %v", cfg, err)
+	}
+	defer intf.Close()
+	ep, err := intf.InEndpoint(6)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(6): %v", intf, err)
+	}
+
+	const pktSize = 3 * 1024
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		copy(ft.buf, []byte{1, 2, 3})
+		copy(ft.buf[2*pktSize:], []byte{7, 8})
+		ft.setIsoPackets([]isoPacketResult{
+			{length: pktSize, actual: 3, status: TransferCompleted},
+			{length: pktSize, actual: 0, status: TransferError},
+			{length: pktSize, actual: 2, status: TransferCompleted},
+		})
+		ft.setStatus(TransferCompleted)
+	}()
+	pkts, err := ep.ReadIsoPackets(context.Background(), make([]byte, 3*pktSize))
+	if err != nil {
+		t.Fatalf("%s.ReadIsoPackets(): %v", ep, err)
+	}
+	want := []IsoPacket{
+		{Data: []byte{1, 2, 3}, Status: TransferCompleted},
+		{Data: []byte{}, Status: TransferError},
+		{Data: []byte{7, 8}, Status: TransferCompleted},
+	}
+	if len(pkts) != len(want) {
+		t.Fatalf("%s.ReadIsoPackets(): got %d packets, want %d", ep, len(pkts), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(pkts[i].Data, want[i].Data) || pkts[i].Status != want[i].Status {
+			t.Errorf("%s.ReadIsoPackets(): packet %d: got %v %v, want %v %v", ep, i, pkts[i].Data, pkts[i].Status, want[i].Data, want[i].Status)
+		}
+	}
+
+	bulk, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer bulk.Close()
+	bintf, done, err := bulk.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", bulk, err)
+	}
+	defer done()
+	bep, err := bintf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", bintf, err)
+	}
+	if _, err := bep.ReadIsoPackets(context.Background(), make([]byte, 512)); err == nil {
+		t.Errorf("%s.ReadIsoPackets(): got nil error on a bulk endpoint, want non-nil", bep)
+	}
+}
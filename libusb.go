@@ -12,26 +12,40 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build !js
+// +build !js
+
 package gousb
 
 import (
 	"fmt"
 	"log"
 	"reflect"
+	"runtime"
 	"sync"
 	"time"
 	"unsafe"
 )
 
 /*
-#cgo pkg-config: libusb-1.0
+#cgo !freebsd pkg-config: libusb-1.0
+#cgo freebsd LDFLAGS: -lusb
 #include <libusb.h>
 
 int gousb_compact_iso_data(struct libusb_transfer *xfer, unsigned char *status);
+void gousb_iso_packets(struct libusb_transfer *xfer, int *lengths, int *actual, int *status);
 struct libusb_transfer *gousb_alloc_transfer_and_buffer(int bufLen, int numIsoPackets);
+struct libusb_transfer *gousb_alloc_transfer_with_buffer(unsigned char *buf, int bufLen, int numIsoPackets);
 void gousb_free_transfer_and_buffer(struct libusb_transfer *xfer);
 int submit(struct libusb_transfer *xfer);
 void gousb_set_debug(libusb_context *ctx, int lvl);
+unsigned char *gousb_dev_mem_alloc(libusb_device_handle *handle, size_t length);
+int gousb_dev_mem_free(libusb_device_handle *handle, unsigned char *buffer, size_t length);
+int gousb_init(libusb_context **ctx, int no_discovery, int use_usbdk);
+void gousb_interrupt_event_handler(libusb_context *ctx);
+int gousb_handle_events(libusb_context *ctx, long long usec);
+int gousb_wrap_sys_device(libusb_context *ctx, intptr_t sys_dev, libusb_device_handle **handle);
+int gousb_hotplug_register_callback(libusb_context *ctx, int id, libusb_hotplug_callback_handle *handle);
 */
 import "C"
 
@@ -41,6 +55,31 @@ type libusbDevHandle C.libusb_device_handle
 type libusbTransfer C.struct_libusb_transfer
 type libusbEndpoint C.struct_libusb_endpoint_descriptor
 
+func fromErrNo(errno C.int) error {
+	err := Error(errno)
+	if err == Success {
+		return nil
+	}
+	return err
+}
+
+// The constants of the package are defined without cgo, so that it builds
+// for targets without libusb. A non-zero difference to the libusb.h value
+// overflows uint, failing the compilation if they ever diverge.
+const (
+	_ = -uint(ErrorIO - C.LIBUSB_ERROR_IO)
+	_ = -uint(ErrorNoDevice - C.LIBUSB_ERROR_NO_DEVICE)
+	_ = -uint(ErrorNotSupported - C.LIBUSB_ERROR_NOT_SUPPORTED)
+	_ = -uint(ErrorOther - C.LIBUSB_ERROR_OTHER)
+	_ = -uint(TransferOverflow - C.LIBUSB_TRANSFER_OVERFLOW)
+	_ = -uint(TransferTypeInterrupt - C.LIBUSB_TRANSFER_TYPE_INTERRUPT)
+	_ = -uint(transferAddZeroPacket - C.LIBUSB_TRANSFER_ADD_ZERO_PACKET)
+	_ = -uint(SpeedSuper - C.LIBUSB_SPEED_SUPER)
+	_ = -uint(CapabilityDetachKernelDriver - C.LIBUSB_CAP_SUPPORTS_DETACH_KERNEL_DRIVER)
+	_ = -uint(HotplugEventDeviceLeft - C.LIBUSB_HOTPLUG_EVENT_DEVICE_LEFT)
+	_ = -uint(DescriptorTypeHub - C.LIBUSB_DT_HUB)
+)
+
 func (ep libusbEndpoint) endpointDesc(dev *DeviceDesc) EndpointDesc {
 	ei := EndpointDesc{
 		Address:       EndpointAddress(ep.bEndpointAddress),
@@ -49,13 +88,14 @@ func (ep libusbEndpoint) endpointDesc(dev *DeviceDesc) EndpointDesc {
 		TransferType:  TransferType(ep.bmAttributes & transferTypeMask),
 		MaxPacketSize: int(ep.wMaxPacketSize),
 	}
-	if ei.TransferType == TransferTypeIsochronous {
-		// bits 0-10 identify the packet size, bits 11-12 are the number of additional transactions per microframe.
+	if ei.TransferType == TransferTypeIsochronous || ei.TransferType == TransferTypeInterrupt {
 		// Don't use libusb_get_max_iso_packet_size, as it has a bug where it returns the same value
 		// regardless of alternative setting used, where different alternative settings might define different
 		// max packet sizes.
 		// See http://libusb.org/ticket/77 for more background.
-		ei.MaxPacketSize = int(ep.wMaxPacketSize) & 0x07ff * (int(ep.wMaxPacketSize)>>11&3 + 1)
+		ei.MaxPacketSize = periodicMaxPacketSize(uint16(ep.wMaxPacketSize))
+	}
+	if ei.TransferType == TransferTypeIsochronous {
 		ei.IsoSyncType = IsoSyncType(ep.bmAttributes & isoSyncTypeMask)
 		switch ep.bmAttributes & usageTypeMask {
 		case C.LIBUSB_ISO_USAGE_TYPE_DATA:
@@ -66,133 +106,72 @@ func (ep libusbEndpoint) endpointDesc(dev *DeviceDesc) EndpointDesc {
 			ei.UsageType = IsoUsageTypeImplicit
 		}
 	}
-	switch {
-	// If the device conforms to USB1.x:
-	//   Interval for polling endpoint for data transfers. Expressed in
-	//   milliseconds.
-	//   This field is ignored for bulk and control endpoints. For
-	//   isochronous endpoints this field must be set to 1. For interrupt
-	//   endpoints, this field may range from 1 to 255.
-	// Note: in low-speed mode, isochronous transfers are not supported.
-	case dev.Spec < Version(2, 0):
-		ei.PollInterval = time.Duration(ep.bInterval) * time.Millisecond
-
-	// If the device conforms to USB[23].x and the device is in low or full
-	// speed mode:
-	//   Interval for polling endpoint for data transfers.  Expressed in
-	//   frames (1ms)
-	//   For full-speed isochronous endpoints, the value of this field should
-	//   be 1.
-	//   For full-/low-speed interrupt endpoints, the value of this field may
-	//   be from 1 to 255.
-	// Note: in low-speed mode, isochronous transfers are not supported.
-	case dev.Speed == SpeedUnknown || dev.Speed == SpeedLow || dev.Speed == SpeedFull:
-		ei.PollInterval = time.Duration(ep.bInterval) * time.Millisecond
-
-	// If the device conforms to USB[23].x and the device is in high speed
-	// mode:
-	//   Interval is expressed in microframe units (125 µs).
-	//   For high-speed bulk/control OUT endpoints, the bInterval must
-	//   specify the maximum NAK rate of the endpoint. A value of 0 indicates
-	//   the endpoint never NAKs. Other values indicate at most 1 NAK each
-	//   bInterval number of microframes. This value must be in the range
-	//   from 0 to 255.
-	case dev.Speed == SpeedHigh && ei.TransferType == TransferTypeBulk:
-		ei.PollInterval = time.Duration(ep.bInterval) * 125 * time.Microsecond
-
-	// If the device conforms to USB[23].x and the device is in high speed
-	// mode:
-	//   For high-speed isochronous endpoints, this value must be in
-	//   the range from 1 to 16. The bInterval value is used as the exponent
-	//   for a 2bInterval-1 value; e.g., a bInterval of 4 means a period
-	//   of 8 (2^(4-1)).
-	//   For high-speed interrupt endpoints, the bInterval value is used as
-	//   the exponent for a 2bInterval-1 value; e.g., a bInterval of 4 means
-	//   a period of 8 (2^(4-1)). This value must be from 1 to 16.
-	// If the device conforms to USB3.x and the device is in SuperSpeed mode:
-	//   Interval for servicing the endpoint for data transfers. Expressed in
-	//   125-µs units.
-	//   For Enhanced SuperSpeed isochronous and interrupt endpoints, this
-	//   value shall be in the range from 1 to 16. However, the valid ranges
-	//   are 8 to 16 for Notification type Interrupt endpoints. The bInterval
-	//   value is used as the exponent for a 2(^bInterval-1) value; e.g., a
-	//   bInterval of 4 means a period of 8 (2^(4-1) → 2^3 → 8).
-	//   This field is reserved and shall not be used for Enhanced SuperSpeed
-	//   bulk or control endpoints.
-	case dev.Speed == SpeedHigh || dev.Speed == SpeedSuper:
-		ei.PollInterval = 125 * time.Microsecond << (ep.bInterval - 1)
+	ei.Interval = int(ep.bInterval)
+	ei.PollInterval = pollInterval(uint8(ep.bInterval), ei.TransferType, dev)
+	if ep.extra_length > 0 {
+		ei.Extra = C.GoBytes(unsafe.Pointer(ep.extra), ep.extra_length)
+	}
+	if dev.Speed >= SpeedSuper {
+		var comp *C.struct_libusb_ss_endpoint_companion_descriptor
+		desc := C.struct_libusb_endpoint_descriptor(ep)
+		if C.libusb_get_ss_endpoint_companion_descriptor(nil, &desc, &comp) == C.LIBUSB_SUCCESS && comp != nil {
+			ei.setSSCompanion(uint8(comp.bMaxBurst), uint8(comp.bmAttributes), uint16(comp.wBytesPerInterval))
+			C.libusb_free_ss_endpoint_companion_descriptor(comp)
+		}
 	}
 	return ei
 }
 
-// libusbIntf is a set of trivial idiomatic Go wrappers around libusb C functions.
-// The underlying code is generally not testable or difficult to test,
-// since libusb interacts directly with the host USB stack.
-//
-// All functions here should operate on types defined on C.libusb* data types,
-// and occasionally on convenience data types (like TransferType or DeviceDesc).
-type libusbIntf interface {
-	// context
-	init() (*libusbContext, error)
-	handleEvents(*libusbContext, <-chan struct{})
-	getDevices(*libusbContext) ([]*libusbDevice, error)
-	exit(*libusbContext) error
-	setDebug(*libusbContext, int)
-
-	// device
-	dereference(*libusbDevice)
-	getDeviceDesc(*libusbDevice) (*DeviceDesc, error)
-	open(*libusbDevice) (*libusbDevHandle, error)
-
-	close(*libusbDevHandle)
-	reset(*libusbDevHandle) error
-	control(*libusbDevHandle, time.Duration, uint8, uint8, uint16, uint16, []byte) (int, error)
-	getConfig(*libusbDevHandle) (uint8, error)
-	setConfig(*libusbDevHandle, uint8) error
-	getStringDesc(*libusbDevHandle, int) (string, error)
-	setAutoDetach(*libusbDevHandle, int) error
-	detachKernelDriver(*libusbDevHandle, uint8) error
-
-	// interface
-	claim(*libusbDevHandle, uint8) error
-	release(*libusbDevHandle, uint8)
-	setAlt(*libusbDevHandle, uint8, uint8) error
-
-	// transfer
-	alloc(*libusbDevHandle, *EndpointDesc, int, int, chan struct{}) (*libusbTransfer, error)
-	cancel(*libusbTransfer) error
-	submit(*libusbTransfer) error
-	buffer(*libusbTransfer) []byte
-	data(*libusbTransfer) (int, TransferStatus)
-	free(*libusbTransfer)
-	setIsoPacketLengths(*libusbTransfer, uint32)
+// defaultBackendName is the name of the built-in backend.
+const defaultBackendName = "libusb"
+
+// newDefaultImpl returns the implementation of the built-in backend.
+func newDefaultImpl() libusbIntf {
+	return libusbImpl{}
+}
+
+// isLibusb reports whether impl uses the libusb library directly.
+func isLibusb(impl libusbIntf) bool {
+	_, ok := impl.(libusbImpl)
+	return ok
 }
 
 // libusbImpl is an implementation of libusbIntf using real CGo-wrapped libusb.
 type libusbImpl struct{}
 
-func (libusbImpl) init() (*libusbContext, error) {
+func (libusbImpl) init(opts ContextOptions) (*libusbContext, error) {
 	var ctx *C.libusb_context
-	if err := fromErrNo(C.libusb_init(&ctx)); err != nil {
+	var noDiscovery, useUsbDk C.int
+	if opts.NoDeviceDiscovery {
+		noDiscovery = 1
+	}
+	if opts.UseUsbDk {
+		useUsbDk = 1
+	}
+	if err := fromErrNo(C.gousb_init(&ctx, noDiscovery, useUsbDk)); err != nil {
 		return nil, err
 	}
 	return (*libusbContext)(ctx), nil
 }
 
-func (libusbImpl) handleEvents(c *libusbContext, done <-chan struct{}) {
-	tv := C.struct_timeval{tv_usec: 100e3}
+func (libusbImpl) handleEvents(c *libusbContext, tick time.Duration, done <-chan struct{}) {
+	usec := C.longlong(tick / time.Microsecond)
 	for {
 		select {
 		case <-done:
 			return
 		default:
 		}
-		if errno := C.libusb_handle_events_timeout_completed((*C.libusb_context)(c), &tv, nil); errno < 0 {
+		if errno := C.gousb_handle_events((*C.libusb_context)(c), usec); errno < 0 {
 			log.Printf("handle_events: error: %s", Error(errno))
 		}
 	}
 }
 
+func (libusbImpl) interruptEvents(c *libusbContext) {
+	C.gousb_interrupt_event_handler((*C.libusb_context)(c))
+}
+
 func (libusbImpl) getDevices(ctx *libusbContext) ([]*libusbDevice, error) {
 	var list **C.libusb_device
 	cnt := C.libusb_get_device_list((*C.libusb_context)(ctx), &list)
@@ -209,7 +188,7 @@ func (libusbImpl) getDevices(ctx *libusbContext) ([]*libusbDevice, error) {
 	for _, d := range devs {
 		ret = append(ret, (*libusbDevice)(d))
 	}
-	// devices will be dereferenced later, during close.
+	// devices will be dereferenced later, see dereference.
 	C.libusb_free_device_list(list, 0)
 	return ret, nil
 }
@@ -223,6 +202,43 @@ func (libusbImpl) setDebug(c *libusbContext, lvl int) {
 	C.gousb_set_debug((*C.libusb_context)(c), C.int(lvl))
 }
 
+func (libusbImpl) getVersion() LibusbVersion {
+	v := C.libusb_get_version()
+	return LibusbVersion{
+		Major: uint16(v.major),
+		Minor: uint16(v.minor),
+		Micro: uint16(v.micro),
+		Nano:  uint16(v.nano),
+		RC:    C.GoString(v.rc),
+	}
+}
+
+func (libusbImpl) hasCapability(c Capability) bool {
+	return C.libusb_has_capability(C.uint32_t(c)) != 0
+}
+
+func (libusbImpl) registerHotplug(ctx *libusbContext, fn func(*libusbDevice, HotplugEventType)) (func(), error) {
+	hotplugCallbacks.Lock()
+	id := hotplugCallbacks.next
+	hotplugCallbacks.next++
+	hotplugCallbacks.m[id] = fn
+	hotplugCallbacks.Unlock()
+
+	var handle C.libusb_hotplug_callback_handle
+	if err := fromErrNo(C.gousb_hotplug_register_callback((*C.libusb_context)(ctx), C.int(id), &handle)); err != nil {
+		hotplugCallbacks.Lock()
+		delete(hotplugCallbacks.m, id)
+		hotplugCallbacks.Unlock()
+		return nil, err
+	}
+	return func() {
+		C.libusb_hotplug_deregister_callback((*C.libusb_context)(ctx), handle)
+		hotplugCallbacks.Lock()
+		delete(hotplugCallbacks.m, id)
+		hotplugCallbacks.Unlock()
+	}, nil
+}
+
 func (libusbImpl) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
 	var desc C.struct_libusb_device_descriptor
 	if err := fromErrNo(C.libusb_get_device_descriptor((*C.libusb_device)(d), &desc)); err != nil {
@@ -245,6 +261,14 @@ func (libusbImpl) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
 		iProduct:             int(desc.iProduct),
 		iSerialNumber:        int(desc.iSerialNumber),
 	}
+	// USB 3.0 spec limits the depth of the hub tier to 7.
+	var path [7]C.uint8_t
+	if n := int(C.libusb_get_port_numbers((*C.libusb_device)(d), &path[0], C.int(len(path)))); n > 0 {
+		dev.Path = make([]int, n)
+		for i := range dev.Path {
+			dev.Path[i] = int(path[i])
+		}
+	}
 	// Enumerate configurations
 	cfgs := make(map[int]ConfigDesc)
 	for i := 0; i < int(desc.bNumConfigurations); i++ {
@@ -256,13 +280,10 @@ func (libusbImpl) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
 			Number:         int(cfg.bConfigurationValue),
 			SelfPowered:    (cfg.bmAttributes & selfPoweredMask) != 0,
 			RemoteWakeup:   (cfg.bmAttributes & remoteWakeupMask) != 0,
-			MaxPower:       2 * Milliamperes(cfg.MaxPower),
+			MaxPower:       maxPower(uint8(cfg.MaxPower), dev.Speed),
+			MaxPowerRaw:    int(cfg.MaxPower),
 			iConfiguration: int(cfg.iConfiguration),
 		}
-		// at GenX speeds MaxPower is expressed in units of 8mA, not 2mA.
-		if dev.Speed == SpeedSuper {
-			c.MaxPower *= 4
-		}
 
 		var ifaces []C.struct_libusb_interface
 		*(*reflect.SliceHeader)(unsafe.Pointer(&ifaces)) = reflect.SliceHeader{
@@ -294,6 +315,9 @@ func (libusbImpl) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
 					Protocol:   Protocol(alt.bInterfaceProtocol),
 					iInterface: int(alt.iInterface),
 				}
+				if alt.extra_length > 0 {
+					i.Extra = C.GoBytes(unsafe.Pointer(alt.extra), alt.extra_length)
+				}
 
 				if hasIntf[i.Number][i.Alternate] {
 					log.Printf("Device on bus %d address %d offered a descriptor for config %d with two different entries with the same interface number (%d) and the same alternate setting number (%d). gousb will use only the first one.", dev.Bus, dev.Address, c.Number, i.Number, i.Alternate)
@@ -315,6 +339,7 @@ func (libusbImpl) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
 					epi := libusbEndpoint(end).endpointDesc(dev)
 					i.Endpoints[epi.Address] = epi
 				}
+				parseClassDesc(&i)
 				descs = append(descs, i)
 			}
 			c.Interfaces = append(c.Interfaces, InterfaceDesc{
@@ -342,6 +367,18 @@ func (libusbImpl) open(d *libusbDevice) (*libusbDevHandle, error) {
 	return (*libusbDevHandle)(handle), nil
 }
 
+func (libusbImpl) wrapSysDevice(ctx *libusbContext, fd uintptr) (*libusbDevHandle, error) {
+	var handle *C.libusb_device_handle
+	if err := fromErrNo(C.gousb_wrap_sys_device((*C.libusb_context)(ctx), C.intptr_t(fd), &handle)); err != nil {
+		return nil, err
+	}
+	return (*libusbDevHandle)(handle), nil
+}
+
+func (libusbImpl) getDevice(d *libusbDevHandle) *libusbDevice {
+	return (*libusbDevice)(C.libusb_get_device((*C.libusb_device_handle)(d)))
+}
+
 func (libusbImpl) close(d *libusbDevHandle) {
 	C.libusb_close((*C.libusb_device_handle)(d))
 }
@@ -399,11 +436,9 @@ func (libusbImpl) getStringDesc(d *libusbDevHandle, index int) (string, error) {
 }
 
 func (libusbImpl) setAutoDetach(d *libusbDevHandle, val int) error {
-	err := fromErrNo(C.libusb_set_auto_detach_kernel_driver((*C.libusb_device_handle)(d), C.int(val)))
-	if err != nil && err != ErrorNotSupported {
-		return err
-	}
-	return nil
+	// ErrorNotSupported is returned on platforms without
+	// CapabilityDetachKernelDriver, Device.SetAutoDetach handles it.
+	return fromErrNo(C.libusb_set_auto_detach_kernel_driver((*C.libusb_device_handle)(d), C.int(val)))
 }
 
 func (libusbImpl) detachKernelDriver(d *libusbDevHandle, iface uint8) error {
@@ -416,6 +451,35 @@ func (libusbImpl) detachKernelDriver(d *libusbDevHandle, iface uint8) error {
 	return nil
 }
 
+func (libusbImpl) attachKernelDriver(d *libusbDevHandle, iface uint8) error {
+	err := fromErrNo(C.libusb_attach_kernel_driver((*C.libusb_device_handle)(d), C.int(iface)))
+	if err != nil && err != ErrorNotSupported && err != ErrorNotFound {
+		// ErrorNotFound is returned if no kernel driver binds to the interface
+		return err
+	}
+	return nil
+}
+
+func (libusbImpl) devMemAlloc(d *libusbDevHandle, n int) ([]byte, error) {
+	p := C.gousb_dev_mem_alloc((*C.libusb_device_handle)(d), C.size_t(n))
+	if p == nil {
+		// libusb doesn't report the reason, but the most common one is
+		// the lack of support on the platform.
+		return nil, ErrorNotSupported
+	}
+	var ret []byte
+	*(*reflect.SliceHeader)(unsafe.Pointer(&ret)) = reflect.SliceHeader{
+		Data: uintptr(unsafe.Pointer(p)),
+		Len:  n,
+		Cap:  n,
+	}
+	return ret, nil
+}
+
+func (libusbImpl) devMemFree(d *libusbDevHandle, buf []byte) error {
+	return fromErrNo(C.gousb_dev_mem_free((*C.libusb_device_handle)(d), (*C.uchar)(unsafe.Pointer(&buf[0])), C.size_t(cap(buf))))
+}
+
 func (libusbImpl) claim(d *libusbDevHandle, iface uint8) error {
 	return fromErrNo(C.libusb_claim_interface((*C.libusb_device_handle)(d), C.int(iface)))
 }
@@ -428,7 +492,7 @@ func (libusbImpl) setAlt(d *libusbDevHandle, iface, setup uint8) error {
 	return fromErrNo(C.libusb_set_interface_alt_setting((*C.libusb_device_handle)(d), C.int(iface), C.int(setup)))
 }
 
-func (libusbImpl) alloc(d *libusbDevHandle, ep *EndpointDesc, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
+func (libusbImpl) alloc(d *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
 	xfer := C.gousb_alloc_transfer_and_buffer(C.int(bufLen), C.int(isoPackets))
 	if xfer == nil {
 		return nil, fmt.Errorf("gousb_alloc_transfer_and_buffer(%d, %d) failed", bufLen, isoPackets)
@@ -436,15 +500,31 @@ func (libusbImpl) alloc(d *libusbDevHandle, ep *EndpointDesc, isoPackets int, bu
 	if int(xfer.length) != bufLen {
 		return nil, fmt.Errorf("gousb_alloc_transfer_and_buffer(%d, %d): length = %d, want %d", bufLen, isoPackets, xfer.length, bufLen)
 	}
+	return initTransfer(xfer, d, ep, flags, isoPackets, done), nil
+}
+
+func (libusbImpl) allocWithBuffer(d *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, buf []byte, done chan struct{}) (*libusbTransfer, error) {
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("gousb_alloc_transfer_with_buffer: empty buffer")
+	}
+	xfer := C.gousb_alloc_transfer_with_buffer((*C.uchar)(unsafe.Pointer(&buf[0])), C.int(len(buf)), C.int(isoPackets))
+	if xfer == nil {
+		return nil, fmt.Errorf("gousb_alloc_transfer_with_buffer(%d, %d) failed", len(buf), isoPackets)
+	}
+	return initTransfer(xfer, d, ep, flags, isoPackets, done), nil
+}
+
+func initTransfer(xfer *C.struct_libusb_transfer, d *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, done chan struct{}) *libusbTransfer {
 	xfer.dev_handle = (*C.libusb_device_handle)(d)
 	xfer.endpoint = C.uchar(ep.Address)
 	xfer._type = C.uchar(ep.TransferType)
+	xfer.flags |= C.uint8_t(flags)
 	xfer.num_iso_packets = C.int(isoPackets)
 	ret := (*libusbTransfer)(xfer)
 	xferDoneMap.Lock()
 	xferDoneMap.m[ret] = done
 	xferDoneMap.Unlock()
-	return ret, nil
+	return ret
 }
 
 func (libusbImpl) cancel(t *libusbTransfer) error {
@@ -476,6 +556,26 @@ func (libusbImpl) data(t *libusbTransfer) (int, TransferStatus) {
 	return int(t.actual_length), TransferStatus(t.status)
 }
 
+func (libusbImpl) isoPackets(t *libusbTransfer) ([]isoPacketResult, TransferStatus) {
+	n := int(t.num_iso_packets)
+	if n == 0 {
+		return nil, TransferStatus(t.status)
+	}
+	lengths := make([]C.int, n)
+	actual := make([]C.int, n)
+	status := make([]C.int, n)
+	C.gousb_iso_packets((*C.struct_libusb_transfer)(t), &lengths[0], &actual[0], &status[0])
+	ret := make([]isoPacketResult, n)
+	for i := range ret {
+		ret[i] = isoPacketResult{
+			length: int(lengths[i]),
+			actual: int(actual[i]),
+			status: TransferStatus(status[i]),
+		}
+	}
+	return ret, TransferStatus(t.status)
+}
+
 func (libusbImpl) free(t *libusbTransfer) {
 	xferDoneMap.Lock()
 	delete(xferDoneMap.m, t)
@@ -483,6 +583,10 @@ func (libusbImpl) free(t *libusbTransfer) {
 	C.gousb_free_transfer_and_buffer((*C.struct_libusb_transfer)(t))
 }
 
+func (libusbImpl) maxTransferSize() int {
+	return platformMaxTransferSize(runtime.GOOS)
+}
+
 func (libusbImpl) setIsoPacketLengths(t *libusbTransfer, length uint32) {
 	C.libusb_set_iso_packet_lengths((*C.struct_libusb_transfer)(t), C.uint(length))
 }
@@ -503,6 +607,28 @@ func xferCallback(xfer *C.struct_libusb_transfer) {
 	ch <- struct{}{}
 }
 
+// hotplugCallbacks keeps a map of registered hotplug callbacks, indexed by
+// the id passed to gousb_hotplug_register_callback.
+var hotplugCallbacks = struct {
+	m    map[int]func(*libusbDevice, HotplugEventType)
+	next int
+	sync.RWMutex
+}{
+	m: make(map[int]func(*libusbDevice, HotplugEventType)),
+}
+
+//export goHotplugCallback
+func goHotplugCallback(ctx *C.libusb_context, dev *C.libusb_device, event C.libusb_hotplug_event, id C.int) C.int {
+	hotplugCallbacks.RLock()
+	fn := hotplugCallbacks.m[int(id)]
+	hotplugCallbacks.RUnlock()
+	if fn != nil {
+		fn((*libusbDevice)(dev), HotplugEventType(event))
+	}
+	// keep the callback registered.
+	return 0
+}
+
 // for benchmarking of method on implementation vs vanilla function.
 func libusbSetDebug(c *libusbContext, lvl int) {
 	C.gousb_set_debug((*C.libusb_context)(c), C.int(lvl))
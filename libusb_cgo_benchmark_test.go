@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 package gousb
 
 import "testing"
@@ -35,7 +38,7 @@ func BenchmarkCGo(b *testing.B) {
 		},
 	} {
 		b.Run(bc.name, func(b *testing.B) {
-			ctx, err := libusbImpl{}.init()
+			ctx, err := libusbImpl{}.init(ContextOptions{})
 			if err != nil {
 				b.Fatalf("libusb_init() failed: %v", err)
 			}
@@ -0,0 +1,155 @@
+// Copyright 2017 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "time"
+
+// maxPower returns the current consumption encoded in the bMaxPower field
+// of a configuration descriptor of a device operating at the given speed.
+// At SuperSpeed and above bMaxPower is expressed in units of 8mA, not 2mA.
+func maxPower(bMaxPower uint8, speed Speed) Milliamperes {
+	if speed >= SpeedSuper {
+		return 8 * Milliamperes(bMaxPower)
+	}
+	return 2 * Milliamperes(bMaxPower)
+}
+
+// pollInterval returns the polling period of an endpoint with the given
+// bInterval, which is encoded differently depending on the USB version
+// and the speed of the device.
+func pollInterval(bInterval uint8, tt TransferType, dev *DeviceDesc) time.Duration {
+	switch {
+	// If the device conforms to USB1.x:
+	//   Interval for polling endpoint for data transfers. Expressed in
+	//   milliseconds.
+	//   This field is ignored for bulk and control endpoints. For
+	//   isochronous endpoints this field must be set to 1. For interrupt
+	//   endpoints, this field may range from 1 to 255.
+	// Note: in low-speed mode, isochronous transfers are not supported.
+	case dev.Spec < Version(2, 0):
+		return time.Duration(bInterval) * time.Millisecond
+
+	// If the device conforms to USB[23].x and the device is in low or full
+	// speed mode:
+	//   Interval for polling endpoint for data transfers.  Expressed in
+	//   frames (1ms)
+	//   For full-speed isochronous endpoints, the value of this field should
+	//   be 1.
+	//   For full-/low-speed interrupt endpoints, the value of this field may
+	//   be from 1 to 255.
+	// Note: in low-speed mode, isochronous transfers are not supported.
+	case dev.Speed == SpeedUnknown || dev.Speed == SpeedLow || dev.Speed == SpeedFull:
+		return time.Duration(bInterval) * time.Millisecond
+
+	// If the device conforms to USB[23].x and the device is in high speed
+	// mode:
+	//   Interval is expressed in microframe units (125 µs).
+	//   For high-speed bulk/control OUT endpoints, the bInterval must
+	//   specify the maximum NAK rate of the endpoint. A value of 0 indicates
+	//   the endpoint never NAKs. Other values indicate at most 1 NAK each
+	//   bInterval number of microframes. This value must be in the range
+	//   from 0 to 255.
+	case dev.Speed == SpeedHigh && tt == TransferTypeBulk:
+		return time.Duration(bInterval) * 125 * time.Microsecond
+
+	// If the device conforms to USB[23].x and the device is in high speed
+	// mode:
+	//   For high-speed isochronous endpoints, this value must be in
+	//   the range from 1 to 16. The bInterval value is used as the exponent
+	//   for a 2bInterval-1 value; e.g., a bInterval of 4 means a period
+	//   of 8 (2^(4-1)).
+	//   For high-speed interrupt endpoints, the bInterval value is used as
+	//   the exponent for a 2bInterval-1 value; e.g., a bInterval of 4 means
+	//   a period of 8 (2^(4-1)). This value must be from 1 to 16.
+	// If the device conforms to USB3.x and the device is in SuperSpeed mode:
+	//   Interval for servicing the endpoint for data transfers. Expressed in
+	//   125-µs units.
+	//   For Enhanced SuperSpeed isochronous and interrupt endpoints, this
+	//   value shall be in the range from 1 to 16. However, the valid ranges
+	//   are 8 to 16 for Notification type Interrupt endpoints. The bInterval
+	//   value is used as the exponent for a 2(^bInterval-1) value; e.g., a
+	//   bInterval of 4 means a period of 8 (2^(4-1) → 2^3 → 8).
+	//   This field is reserved and shall not be used for Enhanced SuperSpeed
+	//   bulk or control endpoints.
+	case dev.Speed >= SpeedHigh:
+		if bInterval < 1 {
+			bInterval = 1
+		} else if bInterval > 16 {
+			bInterval = 16
+		}
+		return 125 * time.Microsecond << (bInterval - 1)
+	}
+	return 0
+}
+
+// libusbIntf is a set of trivial idiomatic Go wrappers around libusb C functions.
+// The underlying code is generally not testable or difficult to test,
+// since libusb interacts directly with the host USB stack.
+//
+// All functions here should operate on types defined on C.libusb* data types,
+// and occasionally on convenience data types (like TransferType or DeviceDesc).
+type libusbIntf interface {
+	// context
+	init(ContextOptions) (*libusbContext, error)
+	handleEvents(*libusbContext, time.Duration, <-chan struct{})
+	interruptEvents(*libusbContext)
+	getDevices(*libusbContext) ([]*libusbDevice, error)
+	exit(*libusbContext) error
+	setDebug(*libusbContext, int)
+	getVersion() LibusbVersion
+	hasCapability(Capability) bool
+	registerHotplug(*libusbContext, func(*libusbDevice, HotplugEventType)) (func(), error)
+
+	// device
+	dereference(*libusbDevice)
+	getDeviceDesc(*libusbDevice) (*DeviceDesc, error)
+	open(*libusbDevice) (*libusbDevHandle, error)
+	wrapSysDevice(*libusbContext, uintptr) (*libusbDevHandle, error)
+	getDevice(*libusbDevHandle) *libusbDevice
+
+	close(*libusbDevHandle)
+	reset(*libusbDevHandle) error
+	control(*libusbDevHandle, time.Duration, uint8, uint8, uint16, uint16, []byte) (int, error)
+	getConfig(*libusbDevHandle) (uint8, error)
+	setConfig(*libusbDevHandle, uint8) error
+	getStringDesc(*libusbDevHandle, int) (string, error)
+	setAutoDetach(*libusbDevHandle, int) error
+	detachKernelDriver(*libusbDevHandle, uint8) error
+	attachKernelDriver(*libusbDevHandle, uint8) error
+	devMemAlloc(*libusbDevHandle, int) ([]byte, error)
+	devMemFree(*libusbDevHandle, []byte) error
+
+	// interface
+	claim(*libusbDevHandle, uint8) error
+	release(*libusbDevHandle, uint8)
+	setAlt(*libusbDevHandle, uint8, uint8) error
+
+	// transfer
+	alloc(*libusbDevHandle, *EndpointDesc, transferFlags, int, int, chan struct{}) (*libusbTransfer, error)
+	allocWithBuffer(*libusbDevHandle, *EndpointDesc, transferFlags, int, []byte, chan struct{}) (*libusbTransfer, error)
+	cancel(*libusbTransfer) error
+	submit(*libusbTransfer) error
+	buffer(*libusbTransfer) []byte
+	data(*libusbTransfer) (int, TransferStatus)
+	// isoPackets returns the results of the individual packets of an
+	// isochronous transfer and the status of the whole transfer, without
+	// compacting the data like data does.
+	isoPackets(*libusbTransfer) ([]isoPacketResult, TransferStatus)
+	free(*libusbTransfer)
+	setIsoPacketLengths(*libusbTransfer, uint32)
+	// maxTransferSize returns the size of the largest bulk or interrupt
+	// transfer the backend can submit, or 0 if it's not limited.
+	maxTransferSize() int
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operations reported in LogEvent.Op.
+const (
+	LogOpOpen        = "open"
+	LogOpClose       = "close"
+	LogOpConfig      = "config"
+	LogOpConfigClose = "config_close"
+	LogOpClaim       = "claim"
+	LogOpRelease     = "release"
+	LogOpTransfer    = "transfer"
+)
+
+// LogField is a single key/value pair of a LogEvent.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// LogEvent describes a lifecycle event of a device, config, interface
+// or transfer.
+type LogEvent struct {
+	// Op is the operation, one of the LogOp constants.
+	Op string
+	// Fields carries the details of the event, e.g. "device", "config",
+	// "interface", "endpoint" or "bytes".
+	Fields []LogField
+	// Err is the error returned by the operation, or nil if it succeeded.
+	Err error
+}
+
+// String returns a logfmt-like representation of the event.
+func (e LogEvent) String() string {
+	parts := []string{"op=" + e.Op}
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s=%v", f.Key, f.Value))
+	}
+	if e.Err != nil {
+		parts = append(parts, fmt.Sprintf("err=%q", e.Err))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Logger receives the lifecycle events of a Context. Log may be called
+// concurrently from multiple goroutines, including from transfer paths,
+// and should return quickly.
+type Logger interface {
+	Log(LogEvent)
+}
+
+// LoggerFunc adapts an ordinary function to the Logger interface.
+type LoggerFunc func(LogEvent)
+
+// Log calls f(e).
+func (f LoggerFunc) Log(e LogEvent) { f(e) }
+
+// loggerHolder allows storing a nil Logger in an atomic.Value.
+type loggerHolder struct {
+	l Logger
+}
+
+// SetLogger installs l as the receiver of lifecycle events of all devices
+// opened through the Context. A nil l disables logging.
+func (c *Context) SetLogger(l Logger) {
+	c.logger.Store(loggerHolder{l})
+}
+
+func (c *Context) log(op string, err error, fields ...LogField) {
+	h, _ := c.logger.Load().(loggerHolder)
+	if h.l == nil {
+		return
+	}
+	h.l.Log(LogEvent{Op: op, Fields: fields, Err: err})
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	var mu sync.Mutex
+	var got []string
+	ctx.SetLogger(LoggerFunc(func(e LogEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e.String())
+	}))
+
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData(make([]byte, 512))
+		ft.setStatus(TransferCompleted)
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	if _, err := ep.Read(make([]byte, 512)); err != nil {
+		t.Errorf("%s.Read(): %v", ep, err)
+	}
+	done()
+	dev.Close()
+
+	dev0 := "vid=9999,pid=0001,bus=1,addr=1"
+	want := []string{
+		"op=open device=" + dev0,
+		"op=config device=" + dev0 + " config=1",
+		"op=claim device=" + dev0 + " config=1 interface=0 alt=0",
+		"op=transfer endpoint=0x82 length=512 bytes=512",
+		"op=release device=" + dev0 + " config=1 interface=0 alt=0",
+		"op=config_close device=" + dev0 + " config=1",
+		"op=close device=" + dev0,
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logged events:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TransferLatencyBuckets are the upper bounds of the transfer latency
+// histogram buckets reported in TransferMetrics. A Context uses the
+// buckets set when it's created.
+var TransferLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// TransferMetrics is a snapshot of the transfer counters of an endpoint.
+// All values are cumulative since the endpoint was first used through
+// the Context while the device was open.
+type TransferMetrics struct {
+	// Transfers is the number of completed transfers, including failed ones.
+	Transfers int64
+	// Errors is the number of failed transfers, including timeouts and stalls.
+	Errors int64
+	// Timeouts is the number of transfers that timed out.
+	Timeouts int64
+	// Stalls is the number of transfers that ended with the endpoint halted.
+	Stalls int64
+	// BytesIn and BytesOut count the data transferred from and to the device.
+	BytesIn  int64
+	BytesOut int64
+	// LatencyCounts is a cumulative histogram of the transfer latencies,
+	// in the format used by Prometheus. LatencyCounts[i] is the number
+	// of transfers that took at most TransferLatencyBuckets[i], as set
+	// when the Context was created. The last element is the total number
+	// of transfers.
+	LatencyCounts []int64
+	// LatencySum is the sum of latencies of all transfers.
+	LatencySum time.Duration
+}
+
+// endpointMetrics holds the live counters of a single endpoint.
+type endpointMetrics struct {
+	transfers, errors, timeouts, stalls int64
+	bytesIn, bytesOut                   int64
+	latencySum                          int64
+	// buckets are the latency bucket bounds of the Context, latency has
+	// a counter per bucket, plus one for transfers slower than the last
+	// bound.
+	buckets []time.Duration
+	latency []int64
+}
+
+func newEndpointMetrics(buckets []time.Duration) *endpointMetrics {
+	return &endpointMetrics{buckets: buckets, latency: make([]int64, len(buckets)+1)}
+}
+
+func (m *endpointMetrics) record(dir EndpointDirection, n int, err error, d time.Duration) {
+	atomic.AddInt64(&m.transfers, 1)
+	if dir == EndpointDirectionIn {
+		atomic.AddInt64(&m.bytesIn, int64(n))
+	} else {
+		atomic.AddInt64(&m.bytesOut, int64(n))
+	}
+	if err != nil {
+		atomic.AddInt64(&m.errors, 1)
+		switch err {
+		case TransferTimedOut, ErrorTimeout:
+			atomic.AddInt64(&m.timeouts, 1)
+		case TransferStall, ErrorPipe:
+			atomic.AddInt64(&m.stalls, 1)
+		}
+	}
+	atomic.AddInt64(&m.latencySum, int64(d))
+	i := 0
+	for i < len(m.buckets) && d > m.buckets[i] {
+		i++
+	}
+	atomic.AddInt64(&m.latency[i], 1)
+}
+
+func (m *endpointMetrics) snapshot() TransferMetrics {
+	ret := TransferMetrics{
+		Transfers:     atomic.LoadInt64(&m.transfers),
+		Errors:        atomic.LoadInt64(&m.errors),
+		Timeouts:      atomic.LoadInt64(&m.timeouts),
+		Stalls:        atomic.LoadInt64(&m.stalls),
+		BytesIn:       atomic.LoadInt64(&m.bytesIn),
+		BytesOut:      atomic.LoadInt64(&m.bytesOut),
+		LatencySum:    time.Duration(atomic.LoadInt64(&m.latencySum)),
+		LatencyCounts: make([]int64, len(m.latency)),
+	}
+	var total int64
+	for i := range m.latency {
+		total += atomic.LoadInt64(&m.latency[i])
+		ret.LatencyCounts[i] = total
+	}
+	return ret
+}
+
+// metricsRegistry keeps the endpoint metrics of a Context, indexed by
+// the device and endpoint description.
+type metricsRegistry struct {
+	// buckets is a copy of TransferLatencyBuckets made when the Context
+	// was created, so that changes to the exported slice don't affect the
+	// existing counters.
+	buckets []time.Duration
+
+	mu sync.Mutex
+	m  map[string]*endpointMetrics
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		buckets: append([]time.Duration(nil), TransferLatencyBuckets...),
+		m:       make(map[string]*endpointMetrics),
+	}
+}
+
+func (r *metricsRegistry) endpoint(dev *Device, addr EndpointAddress) *endpointMetrics {
+	key := dev.String() + ",ep=" + addr.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.m[key]
+	if !ok {
+		m = newEndpointMetrics(r.buckets)
+		r.m[key] = m
+	}
+	return m
+}
+
+// drop removes the metrics of all endpoints of dev.
+func (r *metricsRegistry) drop(dev *Device) {
+	prefix := dev.String() + ",ep="
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.m {
+		if strings.HasPrefix(k, prefix) {
+			delete(r.m, k)
+		}
+	}
+}
+
+// TransferMetrics returns a snapshot of the transfer metrics of all
+// endpoints used through the Context, including the control endpoint.
+// The map is indexed by the device and endpoint, e.g.
+// "vid=1234,pid=5678,bus=1,addr=3,ep=0x81". The metrics of a device are
+// dropped when its last open Device is closed.
+func (c *Context) TransferMetrics() map[string]TransferMetrics {
+	c.metrics.mu.Lock()
+	defer c.metrics.mu.Unlock()
+	ret := make(map[string]TransferMetrics, len(c.metrics.m))
+	for k, m := range c.metrics.m {
+		ret[k] = m.snapshot()
+	}
+	return ret
+}
+
+// publishedMetrics maps the expvar names used by PublishMetrics to the
+// Context whose metrics they report, until it's closed. expvar variables
+// can't be removed, names keeps all the names published by PublishMetrics,
+// publishing a name again makes it report the metrics of another Context.
+var publishedMetrics = struct {
+	sync.Mutex
+	m     map[string]*Context
+	names map[string]bool
+}{m: make(map[string]*Context), names: make(map[string]bool)}
+
+// PublishMetrics exports the transfer metrics of the Context as an expvar
+// variable with the given name, served by the expvar HTTP handler at
+// /debug/vars. The variable is empty once the Context is closed. Calling
+// PublishMetrics again with the same name, e.g. for a Context that
+// replaced a closed one, makes the variable report the metrics of the new
+// Context. Like expvar.Publish, it panics if the name is already
+// registered by another package.
+func (c *Context) PublishMetrics(name string) {
+	publishedMetrics.Lock()
+	defer publishedMetrics.Unlock()
+	if publishedMetrics.names[name] {
+		publishedMetrics.m[name] = c
+		return
+	}
+	if expvar.Get(name) != nil {
+		panic(fmt.Sprintf("gousb: PublishMetrics(%q): name already registered with expvar", name))
+	}
+	publishedMetrics.names[name] = true
+	publishedMetrics.m[name] = c
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		publishedMetrics.Lock()
+		c, ok := publishedMetrics.m[name]
+		publishedMetrics.Unlock()
+		if !ok {
+			return map[string]TransferMetrics{}
+		}
+		return c.TransferMetrics()
+	}))
+}
+
+// unpublishMetrics stops reporting the metrics of the Context through
+// the expvar variables of PublishMetrics, so that a closed Context can
+// be garbage collected.
+func (c *Context) unpublishMetrics() {
+	publishedMetrics.Lock()
+	defer publishedMetrics.Unlock()
+	for name, pc := range publishedMetrics.m {
+		if pc == c {
+			delete(publishedMetrics.m, name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestTransferMetrics(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	go func() {
+		for _, st := range []TransferStatus{TransferCompleted, TransferStall, TransferTimedOut} {
+			ft := lib.waitForSubmitted(nil)
+			ft.setData(make([]byte, 100))
+			ft.setStatus(st)
+		}
+		ft := lib.waitForSubmitted(nil)
+		ft.setLength(10)
+		ft.setStatus(TransferCompleted)
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	for i := 0; i < 3; i++ {
+		in.Read(make([]byte, 512))
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	if _, err := out.Write(make([]byte, 10)); err != nil {
+		t.Errorf("%s.Write(): %v", out, err)
+	}
+
+	got := ctx.TransferMetrics()
+	inKey := dev.String() + ",ep=0x82"
+	m, ok := got[inKey]
+	if !ok {
+		t.Fatalf("TransferMetrics(): no metrics for %q, got %v", inKey, got)
+	}
+	if m.Transfers != 3 || m.Errors != 2 || m.Stalls != 1 || m.Timeouts != 1 || m.BytesIn != 300 || m.BytesOut != 0 {
+		t.Errorf("TransferMetrics()[%q]: got %+v, want 3 transfers, 2 errors, 1 stall, 1 timeout, 300 bytes in", inKey, m)
+	}
+	if l := len(m.LatencyCounts); l != len(TransferLatencyBuckets)+1 || m.LatencyCounts[l-1] != 3 {
+		t.Errorf("TransferMetrics()[%q].LatencyCounts: got %v, want %d buckets with a total of 3", inKey, m.LatencyCounts, len(TransferLatencyBuckets)+1)
+	}
+	outKey := dev.String() + ",ep=0x01"
+	if m := got[outKey]; m.Transfers != 1 || m.BytesOut != 10 {
+		t.Errorf("TransferMetrics()[%q]: got %+v, want 1 transfer with 10 bytes out", outKey, m)
+	}
+
+	ctx.PublishMetrics("gousb_test_metrics")
+	var pub map[string]TransferMetrics
+	if err := json.Unmarshal([]byte(expvar.Get("gousb_test_metrics").String()), &pub); err != nil {
+		t.Fatalf("json.Unmarshal(expvar): %v", err)
+	}
+	if pub[inKey].Transfers != 3 {
+		t.Errorf("published metrics for %q: got %+v, want 3 transfers", inKey, pub[inKey])
+	}
+}
+
+func TestTransferMetricsDroppedOnClose(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		return len(data), nil
+	}
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	other, err := dev.OpenAgain()
+	if err != nil {
+		t.Fatalf("%s.OpenAgain(): %v", dev, err)
+	}
+	if _, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0, 0, make([]byte, 2)); err != nil {
+		t.Fatalf("%s.Control(): %v", dev, err)
+	}
+	key := dev.String() + ",ep=0x00"
+	if err := dev.Close(); err != nil {
+		t.Fatalf("%s.Close(): %v", dev, err)
+	}
+	if got := ctx.TransferMetrics()[key].Transfers; got != 1 {
+		t.Errorf("TransferMetrics()[%q].Transfers with another handle open: got %d, want 1", key, got)
+	}
+	if err := other.Close(); err != nil {
+		t.Fatalf("%s.Close(): %v", other, err)
+	}
+	if got := ctx.TransferMetrics(); len(got) != 0 {
+		t.Errorf("TransferMetrics() after closing the device: got %v, want none", got)
+	}
+}
+
+func TestPublishMetricsClose(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	ctx.PublishMetrics("gousb_test_closed_metrics")
+	if err := ctx.Close(); err != nil {
+		t.Fatalf("Context.Close(): %v", err)
+	}
+	publishedMetrics.Lock()
+	_, ok := publishedMetrics.m["gousb_test_closed_metrics"]
+	publishedMetrics.Unlock()
+	if ok {
+		t.Error("the published metrics reference the Context after Close")
+	}
+	if got, want := expvar.Get("gousb_test_closed_metrics").String(), "{}"; got != want {
+		t.Errorf("published metrics after Close: got %s, want %s", got, want)
+	}
+
+	// The name can be published again for another Context.
+	ctx = newContextWithImpl(newFakeLibusb())
+	defer ctx.Close()
+	ctx.PublishMetrics("gousb_test_closed_metrics")
+}
@@ -17,6 +17,8 @@ package gousb
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // BCD is a binary-coded decimal version number. Its first 8 bits represent
@@ -38,11 +40,51 @@ func (s BCD) Minor() uint8 {
 	return 10*(min>>4) + min&0x0f
 }
 
-// String returns a dotted representation of the BCD (major.minor).
+// Valid reports whether all 4-bit groups of the BCD are decimal digits.
+// Some devices use other values, e.g. DfuSe devices report the DFU
+// version 0x011a. Major and Minor are meaningless for invalid BCDs.
+func (s BCD) Valid() bool {
+	for v := s; v != 0; v >>= 4 {
+		if v&0x0f > 9 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a dotted representation of the BCD (major.minor), e.g.
+// "2.10" for USB 2.1. Invalid BCDs are represented by their hexadecimal
+// digits, e.g. "1.1a".
 func (s BCD) String() string {
+	if !s.Valid() {
+		return fmt.Sprintf("%x.%02x", uint8(s>>8), uint8(s))
+	}
 	return fmt.Sprintf("%d.%02d", s.Major(), s.Minor())
 }
 
+// MarshalText implements encoding.TextMarshaler, using the dotted
+// representation returned by String.
+func (s BCD) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the dotted
+// representation returned by String.
+func (s *BCD) UnmarshalText(text []byte) error {
+	// The digits of a BCD are its hexadecimal digits, which also covers
+	// the representation of invalid BCDs.
+	parts := strings.Split(string(text), ".")
+	if len(parts) == 2 {
+		major, errMaj := strconv.ParseUint(parts[0], 16, 8)
+		minor, errMin := strconv.ParseUint(parts[1], 16, 8)
+		if errMaj == nil && errMin == nil {
+			*s = BCD(major<<8 | minor)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid BCD version %q, want major.minor, e.g. 2.00", text)
+}
+
 // Version returns a BCD version number with given major/minor.
 func Version(major, minor uint8) BCD {
 	return (BCD(major)/10)<<12 | (BCD(major)%10)<<8 | (BCD(minor)/10)<<4 | BCD(minor)%10
@@ -55,3 +97,20 @@ type ID uint16
 func (id ID) String() string {
 	return fmt.Sprintf("%04x", int(id))
 }
+
+// MarshalText implements encoding.TextMarshaler, using the hexadecimal
+// representation returned by String.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting
+// a hexadecimal ID, e.g. "1d6b".
+func (id *ID) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseUint(string(text), 16, 16)
+	if err != nil {
+		return fmt.Errorf("invalid ID %q, want a 16-bit hexadecimal number", text)
+	}
+	*id = ID(v)
+	return nil
+}
@@ -40,4 +40,48 @@ func TestBCD(t *testing.T) {
 			t.Errorf("String(%04x) = %q, want %q", uint16(test.bcd), got, want)
 		}
 	}
+
+	for _, tc := range []struct {
+		bcd   BCD
+		valid bool
+		str   string
+	}{
+		{0x0210, true, "2.10"},
+		{0x011a, false, "1.1a"},
+		{0xa000, false, "a0.00"},
+	} {
+		if got := tc.bcd.Valid(); got != tc.valid {
+			t.Errorf("BCD(%04x).Valid(): got %v, want %v", uint16(tc.bcd), got, tc.valid)
+		}
+		if got := tc.bcd.String(); got != tc.str {
+			t.Errorf("BCD(%04x).String(): got %q, want %q", uint16(tc.bcd), got, tc.str)
+		}
+		var got BCD
+		if err := got.UnmarshalText([]byte(tc.str)); err != nil || got != tc.bcd {
+			t.Errorf("UnmarshalText(%q): got %04x, %v, want %04x, nil", tc.str, uint16(got), err, uint16(tc.bcd))
+		}
+	}
+	for _, bad := range []string{"2", "2.100", "100.00", "x.00", "2.-1"} {
+		var got BCD
+		if err := got.UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalText(%q): got %04x, want an error", bad, uint16(got))
+		}
+	}
+}
+
+func TestMaxPower(t *testing.T) {
+	for _, tc := range []struct {
+		bMaxPower uint8
+		speed     Speed
+		want      Milliamperes
+	}{
+		{50, SpeedFull, 100},
+		{250, SpeedHigh, 500},
+		{112, SpeedSuper, 896},
+		{112, SpeedSuper + 1, 896},
+	} {
+		if got := maxPower(tc.bMaxPower, tc.speed); got != tc.want {
+			t.Errorf("maxPower(%d, %s): got %dmA, want %dmA", tc.bMaxPower, tc.speed, got, tc.want)
+		}
+	}
 }
@@ -0,0 +1,95 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"os/user"
+	"runtime"
+	"strings"
+)
+
+// PermissionError is returned when a device can't be opened due to
+// missing access rights. Along with the underlying ErrorAccess, it holds
+// the details needed to grant the access.
+type PermissionError struct {
+	// Desc is the descriptor of the device that failed to open.
+	Desc *DeviceDesc
+	// User is the name of the user running the program.
+	User string
+	// DevNode is the device node of the device, only known on Linux and
+	// FreeBSD.
+	DevNode string
+	// NodeOwner, NodeGroup and NodeMode describe the access rights of
+	// DevNode, if it could be inspected.
+	NodeOwner, NodeGroup string
+	NodeMode             string
+	// Hint suggests how to grant access on the current platform.
+	Hint string
+	// Err is the underlying error, ErrorAccess.
+	Err error
+}
+
+func (e *PermissionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "access to %s denied for user %q", e.Desc, e.User)
+	if e.DevNode != "" {
+		fmt.Fprintf(&b, ", device node %s", e.DevNode)
+		if e.NodeMode != "" {
+			fmt.Fprintf(&b, " (%s %s:%s)", e.NodeMode, e.NodeOwner, e.NodeGroup)
+		}
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if e.Hint != "" {
+		fmt.Fprintf(&b, "; %s", e.Hint)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error, so that errors.Is(err, ErrorAccess)
+// holds for a PermissionError.
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// permissionHint returns the usual remedy for access errors on goos.
+func permissionHint(goos string) string {
+	switch goos {
+	case "linux":
+		return "add a udev rule granting access to the device, see the udev package"
+	case "darwin":
+		return "sandboxed apps need the com.apple.security.device.usb entitlement, and devices claimed by a kernel driver can't be opened"
+	case "windows":
+		return "install the WinUSB driver for the device, e.g. with Zadig"
+	case "freebsd":
+		return "add the user to the operator group, or add a devfs rule for the ugen device in /etc/devfs.rules"
+	case "openbsd", "netbsd":
+		return "grant the user access to the /dev/usb* and /dev/ugen* device nodes"
+	}
+	return "check the permissions of the device node"
+}
+
+// newPermissionError wraps err, returned when opening the device
+// described by desc, in a PermissionError.
+func newPermissionError(desc *DeviceDesc, err error) *PermissionError {
+	e := &PermissionError{Desc: desc, Hint: permissionHint(runtime.GOOS), Err: err}
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+	if e.DevNode = desc.devNode(runtime.GOOS); e.DevNode != "" {
+		e.NodeOwner, e.NodeGroup, e.NodeMode = nodeOwner(e.DevNode)
+	}
+	return e
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPermissionError(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	lib.openErr = ErrorAccess
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if dev != nil {
+		dev.Close()
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): got a device, want nil")
+	}
+	pe, ok := err.(*PermissionError)
+	if !ok {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): got error %v (%T), want a *PermissionError", err, err)
+	}
+	if !errors.Is(err, ErrorAccess) {
+		t.Errorf("errors.Is(%v, ErrorAccess): got false, want true", err)
+	}
+	if pe.Desc.Vendor != 0x9999 || pe.Hint == "" {
+		t.Errorf("PermissionError: got %+v, want the descriptor of 9999:0001 and a hint", pe)
+	}
+	if msg := err.Error(); !strings.Contains(msg, pe.Hint) || !strings.Contains(msg, ErrorAccess.Error()) {
+		t.Errorf("PermissionError.Error(): got %q, want the hint and the underlying error", msg)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || freebsd
+// +build linux freebsd
+
+package gousb
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// nodeOwner returns the owner, group and mode of the file at path,
+// or empty strings if it can't be inspected.
+func nodeOwner(path string) (owner, group, mode string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", "", ""
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", ""
+	}
+	owner = strconv.Itoa(int(st.Uid))
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group = strconv.Itoa(int(st.Gid))
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group, fi.Mode().String()
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// PowerState is the runtime power state of a device.
+type PowerState string
+
+// Runtime power states, as reported by the Linux kernel.
+const (
+	PowerActive     PowerState = "active"
+	PowerSuspended  PowerState = "suspended"
+	PowerSuspending PowerState = "suspending"
+	PowerResuming   PowerState = "resuming"
+)
+
+// powerAttr returns the path of a runtime power management attribute
+// of the device in sysfs.
+func (d *Device) powerAttr(name string) (string, error) {
+	dir, err := d.SysfsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "power", name), nil
+}
+
+func (d *Device) readPowerAttr(name string) (string, error) {
+	p, err := d.powerAttr(name)
+	if err != nil {
+		return "", err
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s of %s: %v", name, d, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (d *Device) writePowerAttr(name, val string) error {
+	p, err := d.powerAttr(name)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(p, []byte(val), 0644); err != nil {
+		return fmt.Errorf("failed to set %s of %s: %v", name, d, err)
+	}
+	return nil
+}
+
+// Suspend allows the operating system to suspend the device as soon as it
+// is idle. The kernel only suspends a device when none of its interfaces
+// is in use, so Suspend is typically followed by releasing the interfaces
+// or closing the device. Suspend requires write access to the device's
+// power/control and power/autosuspend_delay_ms attributes in sysfs and is
+// only supported on Linux.
+//
+// Suspend sets the autosuspend delay of the device to 0. The previous
+// delay is restored by Resume on the same Device, if the device is closed
+// without Resume, the delay stays at 0.
+func (d *Device) Suspend() error {
+	if d.handle == nil {
+		return fmt.Errorf("Suspend() called on %s after Close", d)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.autosuspendDelay == "" {
+		delay, err := d.readPowerAttr("autosuspend_delay_ms")
+		if err != nil {
+			return err
+		}
+		d.autosuspendDelay = delay
+	}
+	if err := d.writePowerAttr("autosuspend_delay_ms", "0"); err != nil {
+		return err
+	}
+	return d.writePowerAttr("control", "auto")
+}
+
+// Resume wakes up the device if it's suspended and keeps it powered until
+// the next call to Suspend. It restores the autosuspend delay changed by
+// Suspend. Resume is only supported on Linux.
+func (d *Device) Resume() error {
+	if d.handle == nil {
+		return fmt.Errorf("Resume() called on %s after Close", d)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.writePowerAttr("control", "on"); err != nil {
+		return err
+	}
+	if d.autosuspendDelay == "" {
+		return nil
+	}
+	if err := d.writePowerAttr("autosuspend_delay_ms", d.autosuspendDelay); err != nil {
+		return err
+	}
+	d.autosuspendDelay = ""
+	return nil
+}
+
+// PowerState returns the current runtime power state of the device.
+// PowerState is only supported on Linux.
+func (d *Device) PowerState() (PowerState, error) {
+	st, err := d.readPowerAttr("runtime_status")
+	if err != nil {
+		return "", err
+	}
+	return PowerState(st), nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSuspendResume(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("device power management is only supported on Linux")
+	}
+	dir, err := ioutil.TempDir("", "gousb-sysfs")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	old := sysfsUSBDevices
+	sysfsUSBDevices = dir
+	defer func() { sysfsUSBDevices = old }()
+
+	power := filepath.Join(dir, "1-1", "power")
+	if err := os.MkdirAll(power, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for name, val := range map[string]string{
+		"runtime_status":       "suspended\n",
+		"autosuspend_delay_ms": "2000\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(power, name), []byte(val), 0644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	attr := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(power, name))
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", name, err)
+		}
+		return string(b)
+	}
+	if err := dev.Suspend(); err != nil {
+		t.Fatalf("Suspend(): %v", err)
+	}
+	if got, want := attr("control"), "auto"; got != want {
+		t.Errorf("Suspend(): power/control is %q, want %q", got, want)
+	}
+	if got, want := attr("autosuspend_delay_ms"), "0"; got != want {
+		t.Errorf("Suspend(): power/autosuspend_delay_ms is %q, want %q", got, want)
+	}
+	if st, err := dev.PowerState(); err != nil || st != PowerSuspended {
+		t.Errorf("PowerState(): got %q, %v, want %q, nil", st, err, PowerSuspended)
+	}
+	if err := dev.Resume(); err != nil {
+		t.Fatalf("Resume(): %v", err)
+	}
+	if got, want := attr("control"), "on"; got != want {
+		t.Errorf("Resume(): power/control is %q, want %q", got, want)
+	}
+	if got, want := attr("autosuspend_delay_ms"), "2000"; got != want {
+		t.Errorf("Resume(): power/autosuspend_delay_ms is %q, want the previous delay %q", got, want)
+	}
+}
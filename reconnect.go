@@ -0,0 +1,271 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDeviceDisconnected is returned by ReconnectingDevice operations when
+// the device was detached. The operation can be retried, it will succeed
+// once the device is reattached.
+var ErrDeviceDisconnected = errors.New("device disconnected, the operation can be retried after the device is reattached")
+
+// isDisconnect returns true if err signals that the device is gone.
+func isDisconnect(err error) bool {
+	return errors.Is(err, ErrorNoDevice) || errors.Is(err, TransferNoDevice)
+}
+
+// errReconnectingClosed is returned by the operations on a closed
+// ReconnectingDevice.
+var errReconnectingClosed = errors.New("operation called on a closed ReconnectingDevice")
+
+// ReconnectingDevice is a device with a claimed interface that survives
+// the device being detached and reattached. When an operation fails because
+// the device was disconnected, ReconnectingDevice releases the stale
+// resources and returns ErrDeviceDisconnected. The next operation reopens
+// the device, selects the config and claims the interface again.
+//
+// Endpoints are addressed by number on each call, as Endpoint objects
+// become invalid when the device is reopened. Any device state set up by
+// the application, e.g. with vendor control requests, needs to be restored
+// by the application after a reconnect, see SetOnConnect.
+type ReconnectingDevice struct {
+	ctx   *Context
+	match func(*DeviceDesc) bool
+
+	cfgNum, intfNum, alt int
+
+	mu        sync.Mutex
+	dev       *Device
+	cfg       *Config
+	intf      *Interface
+	onConnect func(*Device) error
+	closed    bool
+	// ops holds the cancel functions of the operations in flight on intf,
+	// which run without holding mu. inflight counts them, disconnect
+	// cancels them and waits for them before releasing the interface.
+	ops      map[*context.CancelFunc]bool
+	inflight sync.WaitGroup
+}
+
+// OpenReconnecting opens the first device for which match returns true,
+// selects the config cfgNum and claims interface intfNum with alternate
+// setting alt. The same match function is used to find the device again
+// after it's reattached.
+func (c *Context) OpenReconnecting(match func(desc *DeviceDesc) bool, cfgNum, intfNum, alt int) (*ReconnectingDevice, error) {
+	r := &ReconnectingDevice{
+		ctx:     c,
+		match:   match,
+		cfgNum:  cfgNum,
+		intfNum: intfNum,
+		alt:     alt,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetOnConnect installs fn to be called every time the device is reopened
+// after a disconnect, before the pending operation is retried. If fn returns
+// an error, the device is closed and the error is returned from the operation.
+func (r *ReconnectingDevice) SetOnConnect(fn func(*Device) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onConnect = fn
+}
+
+// connect opens the device, if it's not already open. r.mu must be held.
+func (r *ReconnectingDevice) connect() error {
+	if r.closed {
+		return errReconnectingClosed
+	}
+	if r.intf != nil {
+		return nil
+	}
+	var found bool
+	devs, err := r.ctx.OpenDevices(func(desc *DeviceDesc) bool {
+		if found || !r.match(desc) {
+			return false
+		}
+		found = true
+		return true
+	})
+	if len(devs) == 0 {
+		if err != nil {
+			return err
+		}
+		return ErrDeviceDisconnected
+	}
+	dev := devs[0]
+	cfg, err := dev.Config(r.cfgNum)
+	if err != nil {
+		dev.Close()
+		return err
+	}
+	intf, err := cfg.Interface(r.intfNum, r.alt)
+	if err != nil {
+		cfg.Close()
+		dev.Close()
+		return err
+	}
+	r.dev, r.cfg, r.intf = dev, cfg, intf
+	return nil
+}
+
+// disconnect cancels the operations in flight and releases the interface,
+// config and device. r.mu must be held.
+func (r *ReconnectingDevice) disconnect() {
+	if r.intf == nil {
+		return
+	}
+	for cancel := range r.ops {
+		(*cancel)()
+	}
+	r.inflight.Wait()
+	r.intf.Close()
+	r.cfg.Close()
+	r.dev.Close()
+	r.dev, r.cfg, r.intf = nil, nil, nil
+}
+
+// do runs fn with a connected interface, reconnecting if needed. r.mu
+// is held only to connect, fn runs with a context that is cancelled when
+// the interface is released, e.g. by Close or after another operation
+// found the device disconnected.
+func (r *ReconnectingDevice) do(ctx context.Context, fn func(context.Context, *Interface) error) error {
+	r.mu.Lock()
+	if r.intf == nil {
+		if err := r.connect(); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+		if r.onConnect != nil {
+			if err := r.onConnect(r.dev); err != nil {
+				r.disconnect()
+				r.mu.Unlock()
+				return err
+			}
+		}
+	}
+	intf := r.intf
+	octx, cancel := context.WithCancel(ctx)
+	key := &cancel
+	if r.ops == nil {
+		r.ops = make(map[*context.CancelFunc]bool)
+	}
+	r.ops[key] = true
+	r.inflight.Add(1)
+	r.mu.Unlock()
+
+	err := fn(octx, intf)
+	cancel()
+	r.inflight.Done()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.ops, key)
+	switch {
+	case r.intf != intf:
+		// The interface was released while fn was running.
+		if err == nil {
+			return nil
+		}
+		if r.closed {
+			return errReconnectingClosed
+		}
+		return ErrDeviceDisconnected
+	case isDisconnect(err):
+		r.disconnect()
+		return ErrDeviceDisconnected
+	}
+	return err
+}
+
+// Read reads from the IN endpoint epNum of the claimed interface,
+// like InEndpoint.ReadContext.
+func (r *ReconnectingDevice) Read(ctx context.Context, epNum int, buf []byte) (int, error) {
+	var n int
+	err := r.do(ctx, func(ctx context.Context, intf *Interface) error {
+		ep, err := intf.InEndpoint(epNum)
+		if err != nil {
+			return err
+		}
+		n, err = ep.ReadContext(ctx, buf)
+		return err
+	})
+	return n, err
+}
+
+// Write writes to the OUT endpoint epNum of the claimed interface,
+// like OutEndpoint.WriteContext.
+func (r *ReconnectingDevice) Write(ctx context.Context, epNum int, buf []byte) (int, error) {
+	var n int
+	err := r.do(ctx, func(ctx context.Context, intf *Interface) error {
+		ep, err := intf.OutEndpoint(epNum)
+		if err != nil {
+			return err
+		}
+		n, err = ep.WriteContext(ctx, buf)
+		return err
+	})
+	return n, err
+}
+
+// Control sends a control request to the device, like Device.Control.
+// Like the transfers, the request is cancelled by Close.
+func (r *ReconnectingDevice) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	var n int
+	s := NewSetupPacket(rType, request).WithValue(val).WithIndex(idx)
+	err := r.do(context.Background(), func(ctx context.Context, intf *Interface) error {
+		var err error
+		n, err = intf.config.dev.ControlContext(ctx, s, data)
+		return err
+	})
+	return n, err
+}
+
+// WaitConnected blocks until the device is attached and reopened, or until
+// ctx is done. It returns immediately if the device is connected.
+func (r *ReconnectingDevice) WaitConnected(ctx context.Context) error {
+	r.mu.Lock()
+	connected := r.intf != nil
+	r.mu.Unlock()
+	if connected {
+		return nil
+	}
+	dev, err := r.ctx.WaitForDevice(ctx, r.match)
+	if err != nil {
+		return err
+	}
+	// The device will be reopened by the next operation.
+	dev.Close()
+	return r.do(ctx, func(context.Context, *Interface) error { return nil })
+}
+
+// Close cancels the operations in flight and releases the interface,
+// config and device.
+func (r *ReconnectingDevice) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disconnect()
+	r.closed = true
+	return nil
+}
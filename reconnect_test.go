@@ -0,0 +1,182 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReconnectingDevice(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	ctx.hotplugPollInterval = time.Millisecond
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close: %v", err)
+		}
+	}()
+
+	match := func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x9999 && desc.Product == 0x0001
+	}
+	r, err := ctx.OpenReconnecting(match, 1, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenReconnecting(9999:0001): %v", err)
+	}
+	defer r.Close()
+	var connects int
+	r.SetOnConnect(func(*Device) error {
+		connects++
+		return nil
+	})
+
+	// complete answers the next transfer with the given status.
+	complete := func(st TransferStatus) {
+		go func() {
+			ft := lib.waitForSubmitted(nil)
+			if st == TransferCompleted {
+				ft.setData([]byte{1, 2, 3})
+			}
+			ft.setStatus(st)
+		}()
+	}
+
+	buf := make([]byte, 512)
+	complete(TransferCompleted)
+	if n, err := r.Read(context.Background(), 2, buf); err != nil || n != 3 {
+		t.Fatalf("Read(): got %d, %v, want 3, nil", n, err)
+	}
+
+	// The device goes away in the middle of a transfer.
+	var old *libusbDevice
+	lib.mu.Lock()
+	for dev, fd := range lib.fakeDevices {
+		if match(fd.devDesc) {
+			old = dev
+		}
+	}
+	lib.mu.Unlock()
+	lib.unplug(old)
+	complete(TransferNoDevice)
+	if _, err := r.Read(context.Background(), 2, buf); err != ErrDeviceDisconnected {
+		t.Fatalf("Read() after unplug: got error %v, want %v", err, ErrDeviceDisconnected)
+	}
+	if _, err := r.Read(context.Background(), 2, buf); err != ErrDeviceDisconnected {
+		t.Fatalf("Read() while unplugged: got error %v, want %v", err, ErrDeviceDisconnected)
+	}
+
+	// The device is reattached at a new address.
+	desc := *fakeDevices[0].devDesc
+	desc.Address = 9
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lib.plug(fakeDevice{devDesc: &desc, strDesc: fakeDevices[0].strDesc})
+	}()
+	wctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.WaitConnected(wctx); err != nil {
+		t.Fatalf("WaitConnected(): %v", err)
+	}
+	if connects != 1 {
+		t.Errorf("OnConnect called %d times, want 1", connects)
+	}
+	complete(TransferCompleted)
+	if n, err := r.Read(context.Background(), 2, buf); err != nil || n != 3 {
+		t.Fatalf("Read() after reconnect: got %d, %v, want 3, nil", n, err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if _, err := r.Read(context.Background(), 2, buf); err == nil {
+		t.Errorf("Read() after Close: got nil error, want non-nil")
+	}
+}
+
+func TestReconnectingDeviceConcurrent(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+
+	r, err := ctx.OpenReconnecting(func(desc *DeviceDesc) bool {
+		return desc.Vendor == 0x9999 && desc.Product == 0x0001
+	}, 1, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenReconnecting(9999:0001): %v", err)
+	}
+
+	// A Read on a device that never sends data doesn't block other
+	// operations.
+	readErr := make(chan error)
+	go func() {
+		_, err := r.Read(context.Background(), 2, make([]byte, 512))
+		readErr <- err
+	}()
+	lib.waitForSubmitted(nil)
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setLength(3)
+		ft.setStatus(TransferCompleted)
+	}()
+	if n, err := r.Write(context.Background(), 1, []byte{1, 2, 3}); err != nil || n != 3 {
+		t.Errorf("Write() during a pending Read: got %d, %v, want 3, nil", n, err)
+	}
+
+	// A control request the device never answers.
+	controlErr := make(chan error)
+	go func() {
+		_, err := r.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0, 0, make([]byte, 8))
+		controlErr <- err
+	}()
+	lib.waitForSubmitted(nil)
+
+	// Close cancels the pending Read and Control.
+	if err := r.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	for op, errc := range map[string]chan error{"Read": readErr, "Control": controlErr} {
+		select {
+		case err := <-errc:
+			if err == nil {
+				t.Errorf("%s() interrupted by Close: got nil error, want non-nil", op)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s() still pending after Close", op)
+		}
+	}
+}
+
+func TestIsDisconnect(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrorNoDevice, true},
+		{TransferNoDevice, true},
+		{fmt.Errorf("read: %w", TransferNoDevice), true},
+		{ErrorIO, false},
+		{TransferStall, false},
+	} {
+		if got := isDisconnect(tc.err); got != tc.want {
+			t.Errorf("isDisconnect(%v): got %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/remote/internal/remotepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// AddrEnv is the environment variable with the gRPC target of the server
+// used by the "remote" backend.
+const AddrEnv = "GOUSB_REMOTE"
+
+func init() {
+	gousb.RegisterBackend("remote", func(gousb.ContextOptions) (gousb.Backend, error) {
+		addr := os.Getenv(AddrEnv)
+		if addr == "" {
+			return nil, fmt.Errorf("the server address is not set, set %s to the host:port of the server", AddrEnv)
+		}
+		return NewBackend(addr)
+	})
+}
+
+// backend implements gousb.Backend by forwarding the calls to a server
+// started with Serve.
+type backend struct {
+	conn   *grpc.ClientConn
+	client remotepb.GousbClient
+	info   *remotepb.InfoReply
+
+	mu sync.Mutex
+	// devices are the attached devices by bus and address, so that
+	// a device is represented by the same value in every enumeration.
+	devices map[[2]int]*device
+	nextID  uint64
+}
+
+// NewBackend returns a backend that uses the devices of a server started
+// with Serve at the gRPC target addr, see gousb.NewContextWithBackend.
+// The connection is not encrypted by default, opts can set transport
+// credentials and other options of the gRPC client.
+func NewBackend(addr string, opts ...grpc.DialOption) (gousb.Backend, error) {
+	conn, err := grpc.NewClient(addr, append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessage)),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	b := &backend{
+		conn:    conn,
+		client:  remotepb.NewGousbClient(conn),
+		devices: make(map[[2]int]*device),
+	}
+	b.info, err = b.client.Info(context.Background(), &remotepb.InfoRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewContext returns a Context that uses the devices of a server started
+// with Serve at the gRPC target addr, see NewBackend. Reads and writes
+// larger than 4 MiB are split into multiple transfers. Transfers that
+// were in flight when the connection to the server is lost fail with
+// TransferNoDevice.
+func NewContext(addr string, opts ...grpc.DialOption) (*gousb.Context, error) {
+	b, err := NewBackend(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := gousb.NewContextWithBackend(b, gousb.ContextOptions{})
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return ctx, nil
+}
+
+func (b *backend) Devices() ([]gousb.BackendDevice, error) {
+	reply, err := b.client.List(context.Background(), &remotepb.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	descs := make([]*gousb.DeviceDesc, 0, len(reply.Devices))
+	for _, data := range reply.Devices {
+		desc := new(gousb.DeviceDesc)
+		if err := json.Unmarshal(data, desc); err != nil {
+			return nil, fmt.Errorf("invalid remote device descriptor: %v", err)
+		}
+		descs = append(descs, desc)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seen := make(map[[2]int]bool)
+	ret := make([]gousb.BackendDevice, 0, len(descs))
+	for _, desc := range descs {
+		key := [2]int{desc.Bus, desc.Address}
+		d, ok := b.devices[key]
+		if !ok {
+			d = &device{b: b}
+			b.devices[key] = d
+		}
+		d.mu.Lock()
+		d.desc = desc
+		d.mu.Unlock()
+		seen[key] = true
+		ret = append(ret, d)
+	}
+	for key := range b.devices {
+		if !seen[key] {
+			delete(b.devices, key)
+		}
+	}
+	return ret, nil
+}
+
+func (b *backend) Close() error {
+	return b.conn.Close()
+}
+
+func (b *backend) Version() gousb.LibusbVersion {
+	return gousb.LibusbVersion{
+		Major: uint16(b.info.Major),
+		Minor: uint16(b.info.Minor),
+		Micro: uint16(b.info.Micro),
+		Nano:  uint16(b.info.Nano),
+		RC:    b.info.Rc,
+	}
+}
+
+func (b *backend) HasCapability(c gousb.Capability) bool {
+	for _, cap := range b.info.Capabilities {
+		if gousb.Capability(cap) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *backend) SetDebug(int) {}
+
+func (b *backend) MaxTransferSize() int { return maxTransfer }
+
+// device is an attached device of the server.
+type device struct {
+	b *backend
+
+	mu   sync.Mutex
+	desc *gousb.DeviceDesc
+}
+
+func (d *device) Desc() (*gousb.DeviceDesc, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.desc, nil
+}
+
+func (d *device) Open() (gousb.BackendHandle, error) {
+	desc, _ := d.Desc()
+	reply, err := d.b.client.Open(context.Background(), &remotepb.OpenRequest{Bus: int32(desc.Bus), Address: int32(desc.Address)})
+	if err != nil {
+		return nil, err
+	}
+	if err := replyError(reply.Error); err != nil {
+		return nil, err
+	}
+	return &handle{b: d.b, id: reply.Handle}, nil
+}
+
+func (d *device) Release() {}
+
+// handle is a device handle opened on the server.
+type handle struct {
+	b  *backend
+	id uint64
+}
+
+// call executes one of the simple device handle operations on the server.
+func (h *handle) call(op remotepb.Operation, value, alt int) (*remotepb.CallReply, error) {
+	reply, err := h.b.client.Call(context.Background(), &remotepb.CallRequest{Handle: h.id, Op: op, Value: int32(value), Alt: int32(alt)})
+	if err != nil {
+		return &remotepb.CallReply{}, err
+	}
+	return reply, replyError(reply.Error)
+}
+
+func (h *handle) Close() {
+	h.b.client.Close(context.Background(), &remotepb.CloseRequest{Handle: h.id})
+}
+
+func (h *handle) Reset() error {
+	_, err := h.call(remotepb.Operation_OPERATION_RESET, 0, 0)
+	return err
+}
+
+func (h *handle) Control(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	in := rType&gousb.ControlIn != 0
+	req := &remotepb.ControlRequest{
+		Handle:      h.id,
+		Timeout:     int64(timeout),
+		RequestType: uint32(rType),
+		Request:     uint32(request),
+		Value:       uint32(val),
+		Index:       uint32(idx),
+		Length:      uint32(len(data)),
+	}
+	if !in {
+		req.Data = data
+	}
+	reply, err := h.b.client.Control(context.Background(), req)
+	if err != nil {
+		return 0, err
+	}
+	if in {
+		copy(data, reply.Data)
+	}
+	return int(reply.N), replyError(reply.Error)
+}
+
+func (h *handle) ActiveConfig() (int, error) {
+	reply, err := h.call(remotepb.Operation_OPERATION_GET_CONFIG, 0, 0)
+	return int(reply.Value), err
+}
+
+func (h *handle) SetConfig(cfg int) error {
+	_, err := h.call(remotepb.Operation_OPERATION_SET_CONFIG, cfg, 0)
+	return err
+}
+
+func (h *handle) StringDescriptor(index int) (string, error) {
+	reply, err := h.call(remotepb.Operation_OPERATION_GET_STRING_DESC, index, 0)
+	return reply.Str, err
+}
+
+func (h *handle) SetAutoDetach(autodetach bool) error {
+	val := 0
+	if autodetach {
+		val = 1
+	}
+	_, err := h.call(remotepb.Operation_OPERATION_SET_AUTO_DETACH, val, 0)
+	return err
+}
+
+func (h *handle) DetachKernelDriver(intf int) error {
+	_, err := h.call(remotepb.Operation_OPERATION_DETACH_KERNEL_DRIVER, intf, 0)
+	return err
+}
+
+func (h *handle) AttachKernelDriver(intf int) error {
+	_, err := h.call(remotepb.Operation_OPERATION_ATTACH_KERNEL_DRIVER, intf, 0)
+	return err
+}
+
+func (h *handle) ClaimInterface(intf int) error {
+	_, err := h.call(remotepb.Operation_OPERATION_CLAIM, intf, 0)
+	return err
+}
+
+func (h *handle) ReleaseInterface(intf int) {
+	h.call(remotepb.Operation_OPERATION_RELEASE, intf, 0)
+}
+
+func (h *handle) SetAlternate(intf, alt int) error {
+	_, err := h.call(remotepb.Operation_OPERATION_SET_ALT, intf, alt)
+	return err
+}
+
+func (h *handle) NewTransfer(ep gousb.EndpointDesc, opts gousb.BackendTransferOptions, buf []byte, done chan<- struct{}) (gousb.BackendTransfer, error) {
+	if ep.TransferType == gousb.TransferTypeIsochronous {
+		return nil, gousb.ErrorNotSupported
+	}
+	if len(buf) > maxTransfer {
+		return nil, fmt.Errorf("transfer length %d exceeds the maximum of %d bytes of a remote transfer", len(buf), maxTransfer)
+	}
+	var flags uint32
+	if opts.ShortNotOK {
+		flags |= flagShortNotOK
+	}
+	if opts.AddZeroPacket {
+		flags |= flagAddZeroPacket
+	}
+	return &transfer{h: h, ep: ep, flags: flags, buf: buf, done: done}, nil
+}
+
+// transfer is a bulk or interrupt transfer executed by the server.
+type transfer struct {
+	h     *handle
+	ep    gousb.EndpointDesc
+	flags uint32
+	buf   []byte
+	done  chan<- struct{}
+
+	mu     sync.Mutex
+	id     uint64
+	n      int
+	status gousb.TransferStatus
+}
+
+// Submit sends the transfer to the server. The server replies when the
+// transfer completes, the completion is signaled on the done channel.
+func (t *transfer) Submit() error {
+	b := t.h.b
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.mu.Unlock()
+	t.mu.Lock()
+	t.id = id
+	t.mu.Unlock()
+	req := &remotepb.SubmitRequest{
+		Handle: t.h.id,
+		Id:     id,
+		Endpoint: &remotepb.Endpoint{
+			Address:       uint32(t.ep.Address),
+			TransferType:  uint32(t.ep.TransferType),
+			MaxPacketSize: int32(t.ep.MaxPacketSize),
+		},
+		Flags:  t.flags,
+		Length: uint32(len(t.buf)),
+	}
+	if t.ep.Direction == gousb.EndpointDirectionOut {
+		req.Data = t.buf
+	}
+	go func() {
+		reply, err := b.client.Submit(context.Background(), req)
+		if err == nil {
+			err = replyError(reply.Error)
+		}
+		t.mu.Lock()
+		switch {
+		case errors.Is(err, gousb.ErrorNoDevice) || status.Code(err) == codes.Unavailable || status.Code(err) == codes.Canceled:
+			t.n, t.status = 0, gousb.TransferNoDevice
+		case err != nil:
+			t.n, t.status = 0, gousb.TransferError
+		default:
+			t.n, t.status = int(reply.N), gousb.TransferStatus(reply.Status)
+			if t.ep.Direction == gousb.EndpointDirectionIn {
+				t.n = copy(t.buf, reply.Data)
+			}
+		}
+		t.mu.Unlock()
+		t.done <- struct{}{}
+	}()
+	return nil
+}
+
+func (t *transfer) Cancel() error {
+	t.mu.Lock()
+	id := t.id
+	t.mu.Unlock()
+	reply, err := t.h.b.client.Cancel(context.Background(), &remotepb.CancelRequest{Id: id})
+	if err != nil {
+		return err
+	}
+	return replyError(reply.Error)
+}
+
+func (t *transfer) Result() (int, gousb.TransferStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n, t.status
+}
+
+func (t *transfer) Free() {}
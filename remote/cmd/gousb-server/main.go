@@ -0,0 +1,57 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gousb-server makes the USB devices attached to this machine available
+// to programs on other machines, which connect to it with
+// remote.NewContext or the "remote" gousb backend.
+//
+// Usage:
+//
+//	gousb-server [-listen localhost:5055] [-debug 0]
+//
+// The devices are served over gRPC, without authentication or
+// encryption, the server should only listen on trusted networks. To use
+// it across untrusted networks, listen on localhost and tunnel the
+// connections, e.g. with ssh -L.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/remote"
+)
+
+var (
+	listen = flag.String("listen", "localhost:5055", "TCP address to listen on.")
+	debug  = flag.Int("debug", 0, "libusb debug level (0..3).")
+)
+
+func main() {
+	flag.Parse()
+	ctx, err := gousb.NewContextWithOptions(gousb.ContextOptions{DebugLevel: *debug})
+	if err != nil {
+		log.Fatalf("NewContextWithOptions(): %v", err)
+	}
+	defer ctx.Close()
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("listen on %s: %v", *listen, err)
+	}
+	log.Printf("serving USB devices on %s", l.Addr())
+	log.Fatal(remote.Serve(ctx, l))
+}
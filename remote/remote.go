@@ -0,0 +1,93 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remote is a gousb backend that uses the USB devices of another
+// machine, e.g. a lab machine or a CI host with the devices attached.
+//
+// The machine with the devices runs Serve, or the gousb-server command.
+// Programs use its devices through a Context returned by NewContext, or
+// through the "remote" backend, which connects to the address in the
+// GOUSB_REMOTE environment variable:
+//
+//	import _ "github.com/google/gousb/remote"
+//
+//	ctx, err := gousb.NewContextWithOptions(gousb.ContextOptions{Backend: "remote"})
+//
+// Devices of a remote Context are enumerated, opened, configured and used
+// for transfers like local ones. Isochronous transfers and
+// Device.AllocTransferBuffer are not supported, and hotplug events are
+// detected by polling.
+//
+// The devices are served over gRPC, the service is described in
+// internal/remotepb/remote.proto. By default the connection has no
+// authentication or encryption. The gRPC options of Serve and NewContext
+// can add them, otherwise the server should be used only on trusted
+// networks, or tunneled, e.g. through SSH.
+package remote
+
+import (
+	"errors"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/remote/internal/remotepb"
+)
+
+const (
+	// maxTransfer is the largest bulk or interrupt transfer accepted by
+	// the server. Endpoints of a remote Context split larger reads and
+	// writes.
+	maxTransfer = 4 << 20
+	// maxMessage is the gRPC message size limit of the server and the
+	// client, leaving room for the fields of a transfer next to its data.
+	maxMessage = maxTransfer + 1<<16
+)
+
+// Flags of SubmitRequest, with the values of the libusb transfer flags.
+const (
+	flagShortNotOK    = 1 << 0
+	flagAddZeroPacket = 1 << 6
+)
+
+// newError encodes err for a reply, preserving gousb error codes and
+// transfer statuses.
+func newError(err error) *remotepb.Error {
+	var e gousb.Error
+	var ts gousb.TransferStatus
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gousb.ErrDeviceReenumerated):
+		// The client releases the interfaces and the config itself when
+		// a reset fails with ErrorNotFound.
+		return &remotepb.Error{Code: int32(gousb.ErrorNotFound)}
+	case errors.As(err, &e):
+		return &remotepb.Error{Code: int32(e)}
+	case errors.As(err, &ts):
+		return &remotepb.Error{Status: int32(ts)}
+	}
+	return &remotepb.Error{Message: err.Error()}
+}
+
+// replyError decodes an error encoded by newError.
+func replyError(e *remotepb.Error) error {
+	switch {
+	case e.GetCode() != 0:
+		return gousb.Error(e.GetCode())
+	case e.GetStatus() != int32(gousb.TransferCompleted):
+		return gousb.TransferStatus(e.GetStatus())
+	case e.GetMessage() != "":
+		return errors.New(e.GetMessage())
+	}
+	return nil
+}
@@ -0,0 +1,409 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/remote/internal/remotepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDesc is the descriptor of the device of fakeBackend, with bulk
+// endpoints 0x01 OUT and 0x82 IN.
+var fakeDesc = &gousb.DeviceDesc{
+	Bus:      1,
+	Address:  1,
+	Port:     1,
+	Path:     []int{1},
+	Spec:     gousb.Version(2, 0),
+	Device:   gousb.Version(1, 0),
+	Vendor:   gousb.ID(0x9999),
+	Product:  gousb.ID(0x0001),
+	Protocol: 255,
+	Configs: map[int]gousb.ConfigDesc{1: {
+		Number:      1,
+		MaxPower:    gousb.Milliamperes(100),
+		MaxPowerRaw: 50,
+		Interfaces: []gousb.InterfaceDesc{{
+			Number: 0,
+			AltSettings: []gousb.InterfaceSetting{{
+				Number:    0,
+				Alternate: 0,
+				Class:     gousb.ClassVendorSpec,
+				Endpoints: map[gousb.EndpointAddress]gousb.EndpointDesc{
+					0x01: {
+						Address:       0x01,
+						Number:        1,
+						Direction:     gousb.EndpointDirectionOut,
+						MaxPacketSize: 512,
+						TransferType:  gousb.TransferTypeBulk,
+					},
+					0x82: {
+						Address:       0x82,
+						Number:        2,
+						Direction:     gousb.EndpointDirectionIn,
+						MaxPacketSize: 512,
+						TransferType:  gousb.TransferTypeBulk,
+					},
+				},
+			}},
+		}},
+	}},
+}
+
+// fakeBackend is a gousb.Backend with a single device. Its IN transfers
+// are completed by the test, see submitted.
+type fakeBackend struct {
+	in chan *fakeTransfer
+
+	mu      sync.Mutex
+	handles int
+	written [][]byte
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{in: make(chan *fakeTransfer, 10)}
+}
+
+func (b *fakeBackend) Devices() ([]gousb.BackendDevice, error) {
+	return []gousb.BackendDevice{b}, nil
+}
+func (b *fakeBackend) Close() error                 { return nil }
+func (b *fakeBackend) Version() gousb.LibusbVersion { return gousb.LibusbVersion{Major: 1, Micro: 27} }
+func (b *fakeBackend) HasCapability(c gousb.Capability) bool {
+	return c == gousb.CapabilityDetachKernelDriver
+}
+func (b *fakeBackend) SetDebug(int)                     {}
+func (b *fakeBackend) Desc() (*gousb.DeviceDesc, error) { return fakeDesc, nil }
+func (b *fakeBackend) Open() (gousb.BackendHandle, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handles++
+	return fakeHandle{b}, nil
+}
+func (b *fakeBackend) Release() {}
+
+// openHandles returns the number of device handles still open.
+func (b *fakeBackend) openHandles() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.handles
+}
+
+// submitted returns the next IN transfer submitted to the device.
+func (b *fakeBackend) submitted(t *testing.T) *fakeTransfer {
+	t.Helper()
+	select {
+	case ft := <-b.in:
+		return ft
+	case <-time.After(5 * time.Second):
+		t.Fatal("no IN transfer submitted within 5s")
+		return nil
+	}
+}
+
+type fakeHandle struct{ b *fakeBackend }
+
+func (h fakeHandle) Close() {
+	h.b.mu.Lock()
+	defer h.b.mu.Unlock()
+	h.b.handles--
+}
+func (fakeHandle) Reset() error { return nil }
+func (fakeHandle) Control(_ time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	if request != 0x01 {
+		return 0, gousb.ErrorPipe
+	}
+	return copy(data, []byte{0xca, 0xfe}), nil
+}
+func (fakeHandle) ActiveConfig() (int, error) { return 1, nil }
+func (fakeHandle) SetConfig(int) error        { return nil }
+func (fakeHandle) StringDescriptor(index int) (string, error) {
+	if index != 1 {
+		return "", gousb.ErrorPipe
+	}
+	return "ACME Industries", nil
+}
+func (fakeHandle) SetAutoDetach(bool) error     { return nil }
+func (fakeHandle) DetachKernelDriver(int) error { return nil }
+func (fakeHandle) ClaimInterface(int) error     { return nil }
+func (fakeHandle) ReleaseInterface(int)         {}
+func (fakeHandle) SetAlternate(int, int) error  { return nil }
+func (h fakeHandle) NewTransfer(ep gousb.EndpointDesc, _ gousb.BackendTransferOptions, buf []byte, done chan<- struct{}) (gousb.BackendTransfer, error) {
+	return &fakeTransfer{b: h.b, ep: ep, buf: buf, done: done}, nil
+}
+
+type fakeTransfer struct {
+	b    *fakeBackend
+	ep   gousb.EndpointDesc
+	buf  []byte
+	done chan<- struct{}
+
+	mu       sync.Mutex
+	finished bool
+	n        int
+	st       gousb.TransferStatus
+}
+
+func (t *fakeTransfer) Submit() error {
+	t.mu.Lock()
+	t.finished = false
+	t.mu.Unlock()
+	if t.ep.Direction == gousb.EndpointDirectionIn {
+		t.b.in <- t
+		return nil
+	}
+	t.b.mu.Lock()
+	t.b.written = append(t.b.written, append([]byte(nil), t.buf...))
+	t.b.mu.Unlock()
+	t.finish(len(t.buf), gousb.TransferCompleted)
+	return nil
+}
+
+// finish completes the transfer, unless it already finished.
+func (t *fakeTransfer) finish(n int, st gousb.TransferStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	t.finished, t.n, t.st = true, n, st
+	t.done <- struct{}{}
+}
+
+// status returns the status of the transfer, or false if it's in flight.
+func (t *fakeTransfer) status() (gousb.TransferStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.st, t.finished
+}
+
+func (t *fakeTransfer) Cancel() error {
+	t.finish(0, gousb.TransferCancelled)
+	return nil
+}
+func (t *fakeTransfer) Result() (int, gousb.TransferStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n, t.st
+}
+func (t *fakeTransfer) Free() {}
+
+// startServer serves the devices of a Context using b and returns the
+// address of the server.
+func startServer(t *testing.T, b *fakeBackend) string {
+	t.Helper()
+	server, err := gousb.NewContextWithBackend(b, gousb.ContextOptions{})
+	if err != nil {
+		t.Fatalf("NewContextWithBackend(): %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen(): %v", err)
+	}
+	go Serve(server, l)
+	t.Cleanup(func() {
+		l.Close()
+		server.CloseWithMode(gousb.CloseForce)
+	})
+	return l.Addr().String()
+}
+
+func TestRemote(t *testing.T) {
+	t.Parallel()
+	b := newFakeBackend()
+	addr := startServer(t, b)
+	ctx, err := NewContext(addr)
+	if err != nil {
+		t.Fatalf("NewContext(%s): %v", addr, err)
+	}
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	if got, want := ctx.LibusbVersion(), b.Version(); got != want {
+		t.Errorf("LibusbVersion(): got %v, want %v", got, want)
+	}
+	if !ctx.HasCapability(gousb.CapabilityDetachKernelDriver) {
+		t.Errorf("HasCapability(%s): got false, want true", gousb.CapabilityDetachKernelDriver)
+	}
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	if !reflect.DeepEqual(*dev.Desc, *fakeDesc) {
+		t.Errorf("remote device descriptor:\ngot  %+v\nwant %+v", *dev.Desc, *fakeDesc)
+	}
+	if got, err := dev.GetStringDescriptor(1); err != nil || got != "ACME Industries" {
+		t.Errorf("%s.GetStringDescriptor(1): got (%q, %v), want (\"ACME Industries\", nil)", dev, got, err)
+	}
+
+	buf := make([]byte, 2)
+	if n, err := dev.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, 0x01, 0, 0, buf); err != nil || n != 2 || buf[0] != 0xca || buf[1] != 0xfe {
+		t.Errorf("%s.Control(): got (% x, %v), want (ca fe, nil)", dev, buf[:n], err)
+	}
+	if _, err := dev.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, 0x02, 0, 0, buf); err != gousb.ErrorPipe {
+		t.Errorf("%s.Control() with an unsupported request: got error %v, want %v", dev, err, gousb.ErrorPipe)
+	}
+
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	if got, want := in.MaxTransferSize(), maxTransfer; got != want {
+		t.Errorf("%s.MaxTransferSize(): got %d, want %d", in, got, want)
+	}
+
+	go func() {
+		ft := <-b.in
+		ft.finish(copy(ft.buf, "hello"), gousb.TransferCompleted)
+		ft = <-b.in
+		ft.finish(0, gousb.TransferStall)
+		// The next transfer is left to time out.
+		<-b.in
+	}()
+	rbuf := make([]byte, 512)
+	if n, err := in.Read(rbuf); err != nil || string(rbuf[:n]) != "hello" {
+		t.Errorf("%s.Read(): got (%q, %v), want (\"hello\", nil)", in, rbuf[:n], err)
+	}
+	if n, err := out.Write([]byte("world")); err != nil || n != 5 {
+		t.Errorf("%s.Write(): got (%d, %v), want (5, nil)", out, n, err)
+	}
+	b.mu.Lock()
+	if len(b.written) != 1 || string(b.written[0]) != "world" {
+		t.Errorf("data written to the device: got %q, want [\"world\"]", b.written)
+	}
+	b.mu.Unlock()
+	if _, err := in.Read(rbuf); err != gousb.TransferStall {
+		t.Errorf("%s.Read() of a stalled endpoint: got error %v, want %v", in, err, gousb.TransferStall)
+	}
+	tctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := in.ReadContext(tctx, rbuf); err != gousb.TransferTimedOut {
+		t.Errorf("%s.ReadContext() with a timeout: got error %v, want %v", in, err, gousb.TransferTimedOut)
+	}
+}
+
+// newTestClient starts a server for b and returns a client of the gRPC
+// service.
+func newTestClient(t *testing.T, b *fakeBackend) (remotepb.GousbClient, *grpc.ClientConn) {
+	t.Helper()
+	addr := startServer(t, b)
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient(%s): %v", addr, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return remotepb.NewGousbClient(conn), conn
+}
+
+func TestTransferLength(t *testing.T) {
+	t.Parallel()
+	client, _ := newTestClient(t, newFakeBackend())
+	bg := context.Background()
+
+	for _, tc := range []struct {
+		desc string
+		call func() error
+	}{{
+		desc: "IN control transfer of 65536 bytes",
+		call: func() error {
+			_, err := client.Control(bg, &remotepb.ControlRequest{RequestType: uint32(gousb.ControlIn), Length: 0x10000})
+			return err
+		},
+	}, {
+		desc: "OUT control transfer of 65536 bytes",
+		call: func() error {
+			_, err := client.Control(bg, &remotepb.ControlRequest{Data: make([]byte, 0x10000)})
+			return err
+		},
+	}, {
+		desc: "IN transfer above the maximum",
+		call: func() error {
+			_, err := client.Submit(bg, &remotepb.SubmitRequest{Endpoint: &remotepb.Endpoint{Address: 0x82}, Length: maxTransfer + 1})
+			return err
+		},
+	}, {
+		desc: "transfer without an endpoint",
+		call: func() error {
+			_, err := client.Submit(bg, &remotepb.SubmitRequest{Length: 1})
+			return err
+		},
+	}} {
+		if err := tc.call(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: got error %v, want code %s", tc.desc, err, codes.InvalidArgument)
+		}
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	t.Parallel()
+	b := newFakeBackend()
+	client, conn := newTestClient(t, b)
+	bg := context.Background()
+
+	open, err := client.Open(bg, &remotepb.OpenRequest{Bus: 1, Address: 1})
+	if err != nil || open.Error != nil {
+		t.Fatalf("Open(1, 1): got (%v, %v), want no error", open.GetError(), err)
+	}
+	claim, err := client.Call(bg, &remotepb.CallRequest{Handle: open.Handle, Op: remotepb.Operation_OPERATION_CLAIM})
+	if err != nil || claim.Error != nil {
+		t.Fatalf("Call(CLAIM, 0): got (%v, %v), want no error", claim.GetError(), err)
+	}
+	go client.Submit(bg, &remotepb.SubmitRequest{
+		Handle:   open.Handle,
+		Id:       1,
+		Endpoint: &remotepb.Endpoint{Address: 0x82, TransferType: uint32(gousb.TransferTypeBulk), MaxPacketSize: 512},
+		Length:   512,
+	})
+	ft := b.submitted(t)
+	conn.Close()
+
+	// The transfer is cancelled and the device closed without waiting for
+	// the transfer to complete.
+	deadline := time.Now().Add(5 * time.Second)
+	for b.openHandles() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("device handle still open %v after the client disconnected", 5*time.Second)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if st, finished := ft.status(); !finished || st != gousb.TransferCancelled {
+		t.Errorf("transfer status after the client disconnected: got (%v, finished %v), want %v", st, finished, gousb.TransferCancelled)
+	}
+}
@@ -0,0 +1,538 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/gousb"
+	"github.com/google/gousb/remote/internal/remotepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// capabilities are the capabilities reported to the clients.
+var capabilities = []gousb.Capability{
+	gousb.CapabilityHasCapability,
+	gousb.CapabilityHotplug,
+	gousb.CapabilityHIDAccess,
+	gousb.CapabilityDetachKernelDriver,
+}
+
+// devHandle is a device opened by a client. The client claims interfaces
+// and selects configs and alternate settings with the libusb operations,
+// devHandle maps them to the Config and the Interfaces of the device.
+type devHandle struct {
+	dev *gousb.Device
+
+	mu    sync.Mutex
+	cfg   *gousb.Config
+	intfs map[int]*gousb.Interface
+	// detach has the interfaces whose kernel driver the client detached,
+	// the driver is detached when the interface is claimed.
+	detach map[int]bool
+}
+
+// config returns the Config of the device, claiming the active config if
+// the client did not select one. h.mu must be held.
+func (h *devHandle) config() (*gousb.Config, error) {
+	if h.cfg != nil {
+		return h.cfg, nil
+	}
+	num, err := h.dev.ActiveConfigNum()
+	if err != nil {
+		return nil, err
+	}
+	h.cfg, err = h.dev.Config(num)
+	return h.cfg, err
+}
+
+// releaseConfig releases the claimed interfaces and the config. h.mu must
+// be held.
+func (h *devHandle) releaseConfig() error {
+	for num, intf := range h.intfs {
+		intf.Close()
+		delete(h.intfs, num)
+	}
+	if h.cfg == nil {
+		return nil
+	}
+	err := h.cfg.Close()
+	h.cfg = nil
+	return err
+}
+
+// claim claims an interface with the given alternate setting. h.mu must
+// be held.
+func (h *devHandle) claim(num, alt int) error {
+	cfg, err := h.config()
+	if err != nil {
+		return err
+	}
+	var opts []gousb.InterfaceOption
+	if h.detach[num] {
+		opts = append(opts, gousb.DetachKernelDriver())
+	}
+	intf, err := cfg.Interface(num, alt, opts...)
+	if err != nil {
+		return err
+	}
+	h.intfs[num] = intf
+	return nil
+}
+
+// call executes one of the simple device handle operations.
+func (h *devHandle) call(op remotepb.Operation, value, alt int) (*remotepb.CallReply, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	reply := &remotepb.CallReply{}
+	var err error
+	switch op {
+	case remotepb.Operation_OPERATION_RESET:
+		err = h.dev.Reset()
+		if err == gousb.ErrDeviceReenumerated {
+			// Reset released the interfaces and the config.
+			h.intfs = make(map[int]*gousb.Interface)
+			h.cfg = nil
+		}
+	case remotepb.Operation_OPERATION_GET_CONFIG:
+		var cfg int
+		cfg, err = h.dev.ActiveConfigNum()
+		reply.Value = int32(cfg)
+	case remotepb.Operation_OPERATION_SET_CONFIG:
+		if err = h.releaseConfig(); err == nil {
+			h.cfg, err = h.dev.Config(value)
+		}
+	case remotepb.Operation_OPERATION_GET_STRING_DESC:
+		reply.Str, err = h.dev.GetStringDescriptor(value)
+	case remotepb.Operation_OPERATION_SET_AUTO_DETACH:
+		err = h.dev.SetAutoDetach(value != 0)
+	case remotepb.Operation_OPERATION_DETACH_KERNEL_DRIVER:
+		h.detach[value] = true
+	case remotepb.Operation_OPERATION_ATTACH_KERNEL_DRIVER:
+		// The driver is given back when the interface is released.
+		delete(h.detach, value)
+	case remotepb.Operation_OPERATION_CLAIM:
+		if h.intfs[value] != nil {
+			return reply, nil
+		}
+		err = h.claim(value, 0)
+	case remotepb.Operation_OPERATION_RELEASE:
+		if intf := h.intfs[value]; intf != nil {
+			intf.Close()
+			delete(h.intfs, value)
+		}
+	case remotepb.Operation_OPERATION_SET_ALT:
+		intf := h.intfs[value]
+		if intf == nil {
+			err = gousb.ErrorNotFound
+			break
+		}
+		intf.Close()
+		delete(h.intfs, value)
+		err = h.claim(value, alt)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown operation %v", op)
+	}
+	reply.Error = newError(err)
+	return reply, nil
+}
+
+// endpoint returns the endpoint with the given address of a claimed
+// interface.
+func (h *devHandle) endpoint(addr gousb.EndpointAddress) (*gousb.Interface, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, intf := range h.intfs {
+		if _, ok := intf.Setting.Endpoints[addr]; ok {
+			return intf, nil
+		}
+	}
+	return nil, gousb.ErrorNotFound
+}
+
+// close releases the interfaces and the config and closes the device.
+func (h *devHandle) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.releaseConfig()
+	h.dev.Close()
+}
+
+// session holds the device handles and transfers of a single client
+// connection.
+type session struct {
+	// cancel cancels the calls of the connection.
+	cancel context.CancelFunc
+	// calls are the calls being executed.
+	calls sync.WaitGroup
+
+	mu sync.Mutex
+	// closed is set once the connection ends, new calls are rejected.
+	closed  bool
+	nextID  uint64
+	handles map[uint64]*devHandle
+	// xfers cancel the in-flight transfers started by Submit.
+	xfers map[uint64]context.CancelFunc
+	// cancelled has the transfers cancelled before their Submit call
+	// was received.
+	cancelled map[uint64]bool
+}
+
+func (s *session) handle(id uint64) (*devHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.handles[id]
+	if !ok {
+		return nil, gousb.ErrorNoDevice
+	}
+	return h, nil
+}
+
+// release cancels the in-flight transfers of a disconnected client, waits
+// for its calls to return and closes the device handles left open.
+func (s *session) release() {
+	s.mu.Lock()
+	s.closed = true
+	for _, cancel := range s.xfers {
+		cancel()
+	}
+	s.mu.Unlock()
+	s.cancel()
+	s.calls.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, h := range s.handles {
+		h.close()
+		delete(s.handles, id)
+	}
+}
+
+type sessionKey struct{}
+
+// server implements the Gousb gRPC service using the devices of a
+// Context. It's also the stats.Handler of the gRPC server, tracking the
+// connections to release their sessions.
+type server struct {
+	remotepb.UnimplementedGousbServer
+	c *gousb.Context
+}
+
+// begin returns the session of the connection of a call and registers
+// the call, end must be called when it returns.
+func (r *server) begin(ctx context.Context) (*session, error) {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return nil, status.Error(codes.Internal, "no session for the connection")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, status.Error(codes.Unavailable, "connection closed")
+	}
+	s.calls.Add(1)
+	return s, nil
+}
+
+func (r *server) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	return context.WithValue(ctx, sessionKey{}, &session{
+		cancel:    cancel,
+		handles:   make(map[uint64]*devHandle),
+		xfers:     make(map[uint64]context.CancelFunc),
+		cancelled: make(map[uint64]bool),
+	})
+}
+
+func (r *server) HandleConn(ctx context.Context, st stats.ConnStats) {
+	if _, ok := st.(*stats.ConnEnd); !ok {
+		return
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.release()
+	}
+}
+
+func (r *server) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *server) HandleRPC(context.Context, stats.RPCStats) {}
+
+// Info returns the libusb version and capabilities of the server.
+func (r *server) Info(context.Context, *remotepb.InfoRequest) (*remotepb.InfoReply, error) {
+	v := r.c.LibusbVersion()
+	reply := &remotepb.InfoReply{
+		Major: uint32(v.Major),
+		Minor: uint32(v.Minor),
+		Micro: uint32(v.Micro),
+		Nano:  uint32(v.Nano),
+		Rc:    v.RC,
+	}
+	for _, cap := range capabilities {
+		if r.c.HasCapability(cap) {
+			reply.Capabilities = append(reply.Capabilities, uint32(cap))
+		}
+	}
+	return reply, nil
+}
+
+// List returns the descriptors of the devices attached to the server.
+func (r *server) List(context.Context, *remotepb.ListRequest) (*remotepb.ListReply, error) {
+	refs, err := r.c.ListDevices(nil)
+	if err != nil && len(refs) == 0 {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	reply := &remotepb.ListReply{}
+	for _, ref := range refs {
+		if b, err := json.Marshal(ref.Desc); err == nil {
+			reply.Devices = append(reply.Devices, b)
+		}
+		ref.Release()
+	}
+	return reply, nil
+}
+
+// Open opens the device with the given bus and address.
+func (r *server) Open(ctx context.Context, req *remotepb.OpenRequest) (*remotepb.OpenReply, error) {
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	refs, err := r.c.ListDevices(func(desc *gousb.DeviceDesc) bool {
+		return desc.Bus == int(req.Bus) && desc.Address == int(req.Address)
+	})
+	var dev *gousb.Device
+	if len(refs) > 0 {
+		dev, err = refs[0].Open()
+	} else if err == nil {
+		err = gousb.ErrorNoDevice
+	}
+	for _, ref := range refs {
+		ref.Release()
+	}
+	if dev == nil {
+		return &remotepb.OpenReply{Error: newError(err)}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.handles[s.nextID] = &devHandle{
+		dev:    dev,
+		intfs:  make(map[int]*gousb.Interface),
+		detach: make(map[int]bool),
+	}
+	return &remotepb.OpenReply{Handle: s.nextID}, nil
+}
+
+// Close closes a device handle.
+func (r *server) Close(ctx context.Context, req *remotepb.CloseRequest) (*remotepb.CloseReply, error) {
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	s.mu.Lock()
+	h, ok := s.handles[req.Handle]
+	delete(s.handles, req.Handle)
+	s.mu.Unlock()
+	if !ok {
+		return &remotepb.CloseReply{Error: newError(gousb.ErrorNoDevice)}, nil
+	}
+	h.close()
+	return &remotepb.CloseReply{}, nil
+}
+
+// Call executes one of the simple device handle operations.
+func (r *server) Call(ctx context.Context, req *remotepb.CallRequest) (*remotepb.CallReply, error) {
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	h, err := s.handle(req.Handle)
+	if err != nil {
+		return &remotepb.CallReply{Error: newError(err)}, nil
+	}
+	return h.call(req.Op, int(req.Value), int(req.Alt))
+}
+
+// Control performs a synchronous control transfer.
+func (r *server) Control(ctx context.Context, req *remotepb.ControlRequest) (*remotepb.TransferReply, error) {
+	in := req.RequestType&uint32(gousb.ControlIn) != 0
+	data := req.Data
+	if in {
+		if req.Length > 0xffff {
+			return nil, status.Errorf(codes.InvalidArgument, "control transfer length %d is out of range 0..65535", req.Length)
+		}
+		data = make([]byte, req.Length)
+	} else if len(data) > 0xffff {
+		return nil, status.Errorf(codes.InvalidArgument, "control transfer data length %d exceeds the maximum of 65535 bytes", len(data))
+	}
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	reply := &remotepb.TransferReply{}
+	h, err := s.handle(req.Handle)
+	if err != nil {
+		reply.Error = newError(err)
+		return reply, nil
+	}
+	n, err := h.dev.ControlWithTimeout(time.Duration(req.Timeout), uint8(req.RequestType), uint8(req.Request), uint16(req.Value), uint16(req.Index), data)
+	reply.N = int64(n)
+	if in && n > 0 {
+		reply.Data = data[:n]
+	}
+	reply.Error = newError(err)
+	return reply, nil
+}
+
+// Submit performs a bulk or interrupt transfer and waits for its
+// completion. The transfer is cancelled if the client disconnects.
+func (r *server) Submit(ctx context.Context, req *remotepb.SubmitRequest) (*remotepb.TransferReply, error) {
+	if req.Endpoint == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing endpoint")
+	}
+	addr := gousb.EndpointAddress(req.Endpoint.Address)
+	in := addr&0x80 != 0
+	length := int(req.Length)
+	if !in {
+		length = len(req.Data)
+	}
+	if length > maxTransfer {
+		return nil, status.Errorf(codes.InvalidArgument, "transfer length %d is out of range 0..%d", length, maxTransfer)
+	}
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	reply := &remotepb.TransferReply{}
+	if gousb.TransferType(req.Endpoint.TransferType) == gousb.TransferTypeIsochronous {
+		reply.Error = newError(gousb.ErrorNotSupported)
+		return reply, nil
+	}
+	h, err := s.handle(req.Handle)
+	if err != nil {
+		reply.Error = newError(err)
+		return reply, nil
+	}
+	intf, err := h.endpoint(addr)
+	if err != nil {
+		reply.Error = newError(err)
+		return reply, nil
+	}
+	s.mu.Lock()
+	if s.cancelled[req.Id] {
+		delete(s.cancelled, req.Id)
+		s.mu.Unlock()
+		reply.Status = int32(gousb.TransferCancelled)
+		return reply, nil
+	}
+	// The call context is done when the client disconnects.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.xfers[req.Id] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.xfers, req.Id)
+		s.mu.Unlock()
+	}()
+
+	data := req.Data
+	if in {
+		data = make([]byte, length)
+	}
+	n, err := endpointTransfer(ctx, intf, addr, req.Flags, data)
+	if in {
+		reply.Data = data[:n]
+	}
+	reply.N, reply.Status = int64(n), int32(gousb.TransferCompleted)
+	if ts, ok := err.(gousb.TransferStatus); ok {
+		reply.Status = int32(ts)
+	} else {
+		reply.Error = newError(err)
+	}
+	return reply, nil
+}
+
+// endpointTransfer reads into data from, or writes data to the endpoint addr of
+// intf.
+func endpointTransfer(ctx context.Context, intf *gousb.Interface, addr gousb.EndpointAddress, flags uint32, data []byte) (int, error) {
+	num := int(addr & 0x0f)
+	if addr&0x80 == 0 {
+		ep, err := intf.OutEndpoint(num)
+		if err != nil {
+			return 0, err
+		}
+		ep.SetZeroPacket(flags&flagAddZeroPacket != 0)
+		return ep.WriteContext(ctx, data)
+	}
+	ep, err := intf.InEndpoint(num)
+	if err != nil {
+		return 0, err
+	}
+	ep.SetShortNotOK(flags&flagShortNotOK != 0)
+	return ep.ReadContext(ctx, data)
+}
+
+// Cancel cancels an in-flight transfer started with Submit.
+func (r *server) Cancel(ctx context.Context, req *remotepb.CancelRequest) (*remotepb.CancelReply, error) {
+	s, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.calls.Done()
+	s.mu.Lock()
+	cancel, ok := s.xfers[req.Id]
+	if !ok {
+		// Calls are served concurrently, Cancel can overtake the Submit
+		// of the transfer.
+		s.cancelled[req.Id] = true
+	}
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return &remotepb.CancelReply{}, nil
+}
+
+// Serve accepts connections on l and serves the devices of c over gRPC
+// to remote clients, see NewContext. Each client can enumerate and open
+// all devices of the Context. The device handles and in-flight transfers
+// of a client are closed and cancelled when its connection ends. Serve
+// blocks until l is closed, then closes the client connections and
+// returns the error from Accept.
+//
+// By default the connections have no authentication or encryption, opts
+// can add them, e.g. with grpc.Creds.
+func Serve(c *gousb.Context, l net.Listener, opts ...grpc.ServerOption) error {
+	r := &server{c: c}
+	srv := grpc.NewServer(append([]grpc.ServerOption{grpc.StatsHandler(r), grpc.MaxRecvMsgSize(maxMessage)}, opts...)...)
+	remotepb.RegisterGousbServer(srv, r)
+	err := srv.Serve(l)
+	srv.Stop()
+	return err
+}
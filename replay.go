@@ -0,0 +1,537 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// linktypeUSBLinux is the pcap link type of usbmon captures with the
+// 48-byte packet header.
+const linktypeUSBLinux = 189
+
+// readCapture parses a pcap stream with usbmon packets, as written by
+// SetCapture or by libpcap on Linux.
+func readCapture(r io.Reader) ([]usbmonEvent, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 24 {
+		return nil, fmt.Errorf("capture too short for a pcap header: %d bytes", len(b))
+	}
+	var order binary.ByteOrder
+	switch magic := binary.LittleEndian.Uint32(b); magic {
+	case 0xa1b2c3d4, 0xa1b23c4d:
+		order = binary.LittleEndian
+	case 0xd4c3b2a1, 0x4d3cb2a1:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a pcap capture, magic number 0x%08x", magic)
+	}
+	var hdrLen int
+	switch lt := order.Uint32(b[20:]); lt {
+	case linktypeUSBLinuxMmapped:
+		hdrLen = usbmonHeaderLen
+	case linktypeUSBLinux:
+		hdrLen = 48
+	default:
+		return nil, fmt.Errorf("unsupported pcap link type %d, want usbmon (%d or %d)", lt, linktypeUSBLinux, linktypeUSBLinuxMmapped)
+	}
+
+	var ret []usbmonEvent
+	for b = b[24:]; len(b) > 0; {
+		if len(b) < 16 {
+			return nil, fmt.Errorf("truncated pcap record header after %d packets", len(ret))
+		}
+		capLen := int(order.Uint32(b[8:]))
+		if capLen < hdrLen || len(b) < 16+capLen {
+			return nil, fmt.Errorf("truncated usbmon packet after %d packets", len(ret))
+		}
+		h := b[16 : 16+capLen]
+		b = b[16+capLen:]
+		e := usbmonEvent{
+			id:     order.Uint64(h[0:]),
+			typ:    h[8],
+			tt:     h[9],
+			ep:     h[10],
+			addr:   int(h[11]),
+			bus:    int(order.Uint16(h[12:])),
+			status: int32(order.Uint32(h[28:])),
+			length: int(order.Uint32(h[32:])),
+			data:   h[hdrLen:],
+		}
+		if h[14] == 0 {
+			e.setup = h[40:48]
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// replayKey identifies the queue of recorded exchanges of an endpoint.
+// Both directions of the control endpoint share a queue.
+type replayKey struct {
+	bus, addr int
+	ep        uint8
+}
+
+// replayExchange is a single recorded transfer, a submission paired with
+// its completion.
+type replayExchange struct {
+	tt    uint8
+	ep    uint8
+	setup []byte
+	// out is the submitted payload of an OUT transfer.
+	out []byte
+	// in is the payload returned by an IN transfer.
+	in []byte
+	// actual is the number of bytes transferred.
+	actual int
+	status int32
+}
+
+func (e *replayExchange) String() string {
+	if e.setup != nil {
+		return fmt.Sprintf("control request % x", e.setup)
+	}
+	for tt, v := range usbmonTransferType {
+		if v == e.tt {
+			return fmt.Sprintf("%s transfer on ep 0x%02x", tt, e.ep)
+		}
+	}
+	return fmt.Sprintf("transfer type %d on ep 0x%02x", e.tt, e.ep)
+}
+
+// transferStatus returns the libusb transfer status of a recorded exchange.
+func (e *replayExchange) transferStatus() TransferStatus {
+	switch -e.status {
+	case 0:
+		return TransferCompleted
+	case usbmonENOENT:
+		return TransferTimedOut
+	case usbmonEPIPE:
+		return TransferStall
+	case usbmonENODEV:
+		return TransferNoDevice
+	case usbmonEOVERFLOW:
+		return TransferOverflow
+	}
+	return TransferError
+}
+
+// controlError returns the error of a recorded synchronous control request.
+func (e *replayExchange) controlError() error {
+	switch -e.status {
+	case 0:
+		return nil
+	case usbmonENOENT:
+		return ErrorTimeout
+	case usbmonEPIPE:
+		return ErrorPipe
+	case usbmonENODEV:
+		return ErrorNoDevice
+	case usbmonEOVERFLOW:
+		return ErrorOverflow
+	}
+	return ErrorIO
+}
+
+// replayExchanges pairs the submissions and completions of the capture
+// into per-endpoint queues of exchanges, in the order of submission.
+func replayExchanges(events []usbmonEvent) (map[replayKey][]*replayExchange, error) {
+	type pending struct {
+		key replayKey
+		ex  *replayExchange
+	}
+	submitted := make(map[uint64]pending)
+	queues := make(map[replayKey][]*replayExchange)
+	for i, e := range events {
+		key := replayKey{bus: e.bus, addr: e.addr, ep: e.ep}
+		if e.tt == usbmonTransferType[TransferTypeControl] {
+			key.ep &^= endpointDirectionMask
+		}
+		switch e.typ {
+		case 'S':
+			ex := &replayExchange{tt: e.tt, ep: e.ep, setup: e.setup}
+			if e.ep&endpointDirectionMask == 0 {
+				ex.out = e.data
+			}
+			submitted[e.id] = pending{key, ex}
+			queues[key] = append(queues[key], ex)
+		case 'C':
+			p, ok := submitted[e.id]
+			if !ok || p.key != key {
+				return nil, fmt.Errorf("usbmon packet %d: completion of transfer %#x without a submission", i, e.id)
+			}
+			delete(submitted, e.id)
+			p.ex.status = e.status
+			p.ex.actual = e.length
+			if e.ep&endpointDirectionMask != 0 {
+				p.ex.in = e.data
+			}
+		case 'E':
+			// Submission errors are not replayed, the URB never reached
+			// the device.
+			if p, ok := submitted[e.id]; ok {
+				q := queues[p.key]
+				queues[p.key] = q[:len(q)-1]
+				delete(submitted, e.id)
+			}
+		}
+	}
+	// Transfers still in flight at the end of the capture never completed.
+	for _, p := range submitted {
+		q := queues[p.key]
+		for i, ex := range q {
+			if ex == p.ex {
+				queues[p.key] = append(q[:i:i], q[i+1:]...)
+				break
+			}
+		}
+	}
+	return queues, nil
+}
+
+// replayTransfer is an asynchronous transfer of the replay backend.
+type replayTransfer struct {
+	dev    *DeviceDesc
+	ep     *EndpointDesc
+	buf    []byte
+	done   chan struct{}
+	n      int
+	status TransferStatus
+}
+
+// replayLibusb implements libusbIntf by replaying recorded exchanges,
+// see NewReplayContext.
+type replayLibusb struct {
+	descs []*DeviceDesc
+
+	mu      sync.Mutex
+	devices map[*libusbDevice]*DeviceDesc
+	handles map[*libusbDevHandle]*libusbDevice
+	configs map[*libusbDevHandle]uint8
+	ts      map[*libusbTransfer]*replayTransfer
+	queues  map[replayKey][]*replayExchange
+	// errs are the replay mismatches, reported by exit.
+	errs []error
+}
+
+// next removes and returns the next recorded exchange of the endpoint,
+// or records a mismatch and returns nil if there is none left.
+func (r *replayLibusb) next(desc *DeviceDesc, ep uint8, what string) *replayExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := replayKey{bus: desc.Bus, addr: desc.Address, ep: ep}
+	q := r.queues[key]
+	if len(q) == 0 {
+		r.errs = append(r.errs, fmt.Errorf("replay: %s on device at bus %d address %d, no recorded transfers left", what, desc.Bus, desc.Address))
+		return nil
+	}
+	r.queues[key] = q[1:]
+	return q[0]
+}
+
+// mismatch records and returns a replay mismatch.
+func (r *replayLibusb) mismatch(desc *DeviceDesc, format string, args ...interface{}) error {
+	err := fmt.Errorf("replay: device at bus %d address %d: %s", desc.Bus, desc.Address, fmt.Sprintf(format, args...))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+	return err
+}
+
+func (r *replayLibusb) handleDesc(h *libusbDevHandle) *DeviceDesc {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.devices[r.handles[h]]
+}
+
+func (r *replayLibusb) init(ContextOptions) (*libusbContext, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.descs {
+		r.devices[newDevicePointer()] = d
+	}
+	return newContextPointer(), nil
+}
+
+func (r *replayLibusb) handleEvents(_ *libusbContext, _ time.Duration, done <-chan struct{}) {
+	<-done
+}
+
+func (r *replayLibusb) interruptEvents(*libusbContext) {}
+
+func (r *replayLibusb) getDevices(*libusbContext) ([]*libusbDevice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make([]*libusbDevice, 0, len(r.devices))
+	for d := range r.devices {
+		ret = append(ret, d)
+	}
+	return ret, nil
+}
+
+// exit reports the mismatches and the recorded exchanges that were not
+// replayed.
+func (r *replayLibusb) exit(*libusbContext) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	left := 0
+	for _, q := range r.queues {
+		left += len(q)
+	}
+	if len(r.errs) == 0 && left == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("replay: %d mismatched transfers, %d recorded transfers not replayed", len(r.errs), left)
+	if len(r.errs) > 0 {
+		msg += fmt.Sprintf(", first mismatch: %v", r.errs[0])
+	}
+	return errors.New(msg)
+}
+
+func (r *replayLibusb) setDebug(*libusbContext, int)  {}
+func (r *replayLibusb) getVersion() LibusbVersion     { return LibusbVersion{} }
+func (r *replayLibusb) hasCapability(Capability) bool { return false }
+
+func (r *replayLibusb) registerHotplug(*libusbContext, func(*libusbDevice, HotplugEventType)) (func(), error) {
+	return nil, ErrorNotSupported
+}
+
+func (r *replayLibusb) dereference(*libusbDevice) {}
+
+func (r *replayLibusb) getDeviceDesc(d *libusbDevice) (*DeviceDesc, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if desc, ok := r.devices[d]; ok {
+		return desc, nil
+	}
+	return nil, fmt.Errorf("invalid USB device %p", d)
+}
+
+func (r *replayLibusb) open(d *libusbDevice) (*libusbDevHandle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := newDevHandlePointer()
+	r.handles[h] = d
+	if cfgs := r.devices[d].sortedConfigIds(); len(cfgs) > 0 {
+		r.configs[h] = uint8(cfgs[0])
+	}
+	return h, nil
+}
+
+func (r *replayLibusb) wrapSysDevice(*libusbContext, uintptr) (*libusbDevHandle, error) {
+	return nil, ErrorNotSupported
+}
+
+func (r *replayLibusb) getDevice(h *libusbDevHandle) *libusbDevice {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.handles[h]
+}
+
+func (r *replayLibusb) close(h *libusbDevHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.handles, h)
+	delete(r.configs, h)
+}
+
+func (r *replayLibusb) reset(*libusbDevHandle) error { return nil }
+
+func (r *replayLibusb) control(h *libusbDevHandle, _ time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	desc := r.handleDesc(h)
+	setup := make([]byte, 8)
+	setup[0], setup[1] = rType, request
+	binary.LittleEndian.PutUint16(setup[2:], val)
+	binary.LittleEndian.PutUint16(setup[4:], idx)
+	binary.LittleEndian.PutUint16(setup[6:], uint16(len(data)))
+	what := fmt.Sprintf("control request % x", setup)
+	ex := r.next(desc, 0, what)
+	if ex == nil {
+		return 0, ErrorIO
+	}
+	if !bytes.Equal(ex.setup, setup) {
+		r.mismatch(desc, "%s, recorded %s", what, ex)
+		return 0, ErrorIO
+	}
+	if rType&ControlIn == 0 {
+		if !bytes.Equal(ex.out, data) {
+			r.mismatch(desc, "%s with data % x, recorded data % x", what, data, ex.out)
+			return 0, ErrorIO
+		}
+		return ex.actual, ex.controlError()
+	}
+	return copy(data, ex.in), ex.controlError()
+}
+
+func (r *replayLibusb) getConfig(h *libusbDevHandle) (uint8, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.configs[h], nil
+}
+
+func (r *replayLibusb) setConfig(h *libusbDevHandle, cfg uint8) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.devices[r.handles[h]].Configs[int(cfg)]; !ok {
+		return ErrorNotFound
+	}
+	r.configs[h] = cfg
+	return nil
+}
+
+// getStringDesc fails, string descriptors are read by libusb directly
+// and are not part of the capture.
+func (r *replayLibusb) getStringDesc(*libusbDevHandle, int) (string, error) {
+	return "", ErrorNotSupported
+}
+
+func (r *replayLibusb) setAutoDetach(*libusbDevHandle, int) error        { return nil }
+func (r *replayLibusb) detachKernelDriver(*libusbDevHandle, uint8) error { return nil }
+func (r *replayLibusb) attachKernelDriver(*libusbDevHandle, uint8) error { return nil }
+func (r *replayLibusb) devMemAlloc(*libusbDevHandle, int) ([]byte, error) {
+	return nil, ErrorNotSupported
+}
+func (r *replayLibusb) devMemFree(*libusbDevHandle, []byte) error   { return ErrorNotSupported }
+func (r *replayLibusb) claim(*libusbDevHandle, uint8) error         { return nil }
+func (r *replayLibusb) release(*libusbDevHandle, uint8)             {}
+func (r *replayLibusb) setAlt(*libusbDevHandle, uint8, uint8) error { return nil }
+
+func (r *replayLibusb) alloc(h *libusbDevHandle, ep *EndpointDesc, flags transferFlags, isoPackets int, bufLen int, done chan struct{}) (*libusbTransfer, error) {
+	return r.allocWithBuffer(h, ep, flags, isoPackets, make([]byte, bufLen), done)
+}
+
+func (r *replayLibusb) allocWithBuffer(h *libusbDevHandle, ep *EndpointDesc, _ transferFlags, _ int, buf []byte, done chan struct{}) (*libusbTransfer, error) {
+	if ep.TransferType == TransferTypeIsochronous {
+		return nil, ErrorNotSupported
+	}
+	desc := r.handleDesc(h)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := newFakeTransferPointer()
+	r.ts[t] = &replayTransfer{dev: desc, ep: ep, buf: buf, done: done}
+	return t, nil
+}
+
+// cancel reports that the transfer is not in flight, replayed transfers
+// complete as soon as they're submitted.
+func (r *replayLibusb) cancel(*libusbTransfer) error { return ErrorNotFound }
+
+func (r *replayLibusb) submit(t *libusbTransfer) error {
+	r.mu.Lock()
+	rt := r.ts[t]
+	r.mu.Unlock()
+	what := fmt.Sprintf("%s transfer on ep %s", rt.ep.TransferType, rt.ep.Address)
+	rt.n, rt.status = 0, TransferError
+	ex := r.next(rt.dev, uint8(rt.ep.Address), what)
+	switch {
+	case ex == nil:
+	case ex.tt != usbmonTransferType[rt.ep.TransferType]:
+		r.mismatch(rt.dev, "%s, recorded %s", what, ex)
+	case rt.ep.Direction == EndpointDirectionOut && !bytes.Equal(ex.out, rt.buf):
+		r.mismatch(rt.dev, "%s with data % x, recorded data % x", what, rt.buf, ex.out)
+	case rt.ep.Direction == EndpointDirectionOut:
+		rt.n, rt.status = ex.actual, ex.transferStatus()
+	default:
+		rt.n, rt.status = copy(rt.buf, ex.in), ex.transferStatus()
+		if len(ex.in) > len(rt.buf) && rt.status == TransferCompleted {
+			rt.status = TransferOverflow
+		}
+	}
+	rt.done <- struct{}{}
+	return nil
+}
+
+func (r *replayLibusb) buffer(t *libusbTransfer) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ts[t].buf
+}
+
+func (r *replayLibusb) data(t *libusbTransfer) (int, TransferStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ts[t].n, r.ts[t].status
+}
+
+func (r *replayLibusb) isoPackets(*libusbTransfer) ([]isoPacketResult, TransferStatus) {
+	return nil, TransferError
+}
+
+func (r *replayLibusb) free(t *libusbTransfer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.ts, t)
+}
+
+func (r *replayLibusb) setIsoPacketLengths(*libusbTransfer, uint32) {}
+
+func (r *replayLibusb) maxTransferSize() int { return 0 }
+
+// NewReplayContext returns a Context that doesn't talk to real devices,
+// but replays a session recorded with SetCapture instead. It's meant for
+// regression testing of device drivers against sessions recorded with
+// real devices.
+//
+// The capture doesn't include the device descriptors, devices lists the
+// descriptors of the recorded devices, matched with the capture by Bus
+// and Address. They can be saved during recording using the JSON encoding
+// of DeviceDesc. Only the listed devices are enumerated by the Context.
+//
+// Each control, bulk and interrupt transfer is answered with the next
+// recorded transfer of the same endpoint, in the order of recording.
+// The request and the OUT payload must match the recording, otherwise the
+// transfer fails. Close returns an error describing the mismatches, if any,
+// and the recorded transfers that were not replayed. Isochronous
+// transfers and string descriptors are not supported, as they're not part
+// of the capture.
+func NewReplayContext(capture io.Reader, devices ...*DeviceDesc) (*Context, error) {
+	events, err := readCapture(capture)
+	if err != nil {
+		return nil, err
+	}
+	queues, err := replayExchanges(events)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[[2]int]bool)
+	for _, d := range devices {
+		known[[2]int{d.Bus, d.Address}] = true
+	}
+	// Transfers of devices that are not replayed can't be consumed.
+	for k := range queues {
+		if !known[[2]int{k.bus, k.addr}] {
+			delete(queues, k)
+		}
+	}
+	return newContextWithImplAndOptions(&replayLibusb{
+		descs:   devices,
+		devices: make(map[*libusbDevice]*DeviceDesc),
+		handles: make(map[*libusbDevHandle]*libusbDevice),
+		configs: make(map[*libusbDevHandle]uint8),
+		ts:      make(map[*libusbTransfer]*replayTransfer),
+		queues:  queues,
+	}, ContextOptions{})
+}
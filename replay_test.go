@@ -0,0 +1,140 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// recordSession records a short session with the first fake device: a
+// vendor control request, a bulk write and a bulk read.
+func recordSession(t *testing.T) []byte {
+	t.Helper()
+	lib := newFakeLibusb()
+	lib.controlFn = func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		return copy(data, []byte{0xca, 0xfe}), nil
+	}
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	var capture bytes.Buffer
+	if err := ctx.SetCapture(&capture); err != nil {
+		t.Fatalf("SetCapture(): %v", err)
+	}
+
+	go func() {
+		out := lib.waitForSubmitted(nil)
+		out.setLength(5)
+		out.setStatus(TransferCompleted)
+		in := lib.waitForSubmitted(nil)
+		in.setData([]byte("world"))
+		in.setStatus(TransferCompleted)
+	}()
+	runSession(t, ctx)
+	return capture.Bytes()
+}
+
+// runSession runs the operations of the recorded session.
+func runSession(t *testing.T, ctx *Context) {
+	t.Helper()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	buf := make([]byte, 2)
+	if n, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0x0002, 0, buf); err != nil || !bytes.Equal(buf[:n], []byte{0xca, 0xfe}) {
+		t.Errorf("%s.Control(): got (% x, %v), want (ca fe, nil)", dev, buf[:n], err)
+	}
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	if n, err := out.Write([]byte("hello")); err != nil || n != 5 {
+		t.Errorf("%s.Write(): got (%d, %v), want (5, nil)", out, n, err)
+	}
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	buf = make([]byte, 512)
+	if n, err := in.Read(buf); err != nil || string(buf[:n]) != "world" {
+		t.Errorf("%s.Read(): got (%q, %v), want (\"world\", nil)", in, buf[:n], err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+	capture := recordSession(t)
+
+	ctx, err := NewReplayContext(bytes.NewReader(capture), fakeDevices[0].devDesc)
+	if err != nil {
+		t.Fatalf("NewReplayContext(): %v", err)
+	}
+	runSession(t, ctx)
+	if err := ctx.Close(); err != nil {
+		t.Errorf("Context.Close(): %v", err)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	t.Parallel()
+	capture := recordSession(t)
+
+	ctx, err := NewReplayContext(bytes.NewReader(capture), fakeDevices[0].devDesc)
+	if err != nil {
+		t.Fatalf("NewReplayContext(): %v", err)
+	}
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	// Different wValue than recorded.
+	if _, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0x0003, 0, make([]byte, 2)); err == nil {
+		t.Errorf("%s.Control() with a request that was not recorded: got nil error, want an error", dev)
+	}
+	dev.Close()
+	err = ctx.Close()
+	if err == nil {
+		t.Fatal("Context.Close(): got nil error, want a replay mismatch")
+	}
+	for _, want := range []string{"1 mismatched", "2 recorded transfers not replayed", "control request c0 01 03 00"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Context.Close(): got error %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestReadCaptureErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", make([]byte, 24)},
+		{"ethernet", []byte{0xd4, 0xc3, 0xb2, 0xa1, 2, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 0, 1, 0, 0, 0}},
+		{"truncated", append(pcapHeader(), 1, 2, 3)},
+	} {
+		if _, err := NewReplayContext(bytes.NewReader(tc.data)); err == nil {
+			t.Errorf("%s: NewReplayContext(): got nil error, want an error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rndis
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// packetHdrSize is the length of the REMOTE_NDIS_PACKET_MSG header. The
+// data offset of the header is counted from its DataOffset field.
+const (
+	packetHdrSize    = 44
+	packetDataOffset = packetHdrSize - 8
+)
+
+// EncodePacket wraps the Ethernet frame in a REMOTE_NDIS_PACKET_MSG.
+func EncodePacket(frame []byte) []byte {
+	le := binary.LittleEndian
+	b := make([]byte, packetHdrSize+len(frame))
+	le.PutUint32(b[0:], msgPacket)
+	le.PutUint32(b[4:], uint32(len(b)))
+	le.PutUint32(b[8:], packetDataOffset)
+	le.PutUint32(b[12:], uint32(len(frame)))
+	copy(b[packetHdrSize:], frame)
+	return b
+}
+
+// DecodePackets returns the Ethernet frames of the REMOTE_NDIS_PACKET_MSG
+// messages in b, a single transfer received from the device, which may
+// hold several messages. The returned slices point into b.
+func DecodePackets(b []byte) ([][]byte, error) {
+	le := binary.LittleEndian
+	var frames [][]byte
+	for len(b) > 0 {
+		if len(b) < packetHdrSize {
+			// Some devices pad the transfer with a trailing zero byte.
+			if isZero(b) {
+				break
+			}
+			return nil, fmt.Errorf("RNDIS packet message too short: % x", b)
+		}
+		typ, l := le.Uint32(b[0:]), int(le.Uint32(b[4:]))
+		if typ != msgPacket {
+			return nil, fmt.Errorf("unexpected RNDIS message type %#x in data transfer", typ)
+		}
+		if l < packetHdrSize || l > len(b) {
+			return nil, fmt.Errorf("invalid RNDIS packet message length %d, %d bytes left in the transfer", l, len(b))
+		}
+		off, dl := 8+int(le.Uint32(b[8:])), int(le.Uint32(b[12:]))
+		if off < packetHdrSize || off+dl > l {
+			return nil, fmt.Errorf("RNDIS packet data at offset %d with length %d exceeds the message length %d", off, dl, l)
+		}
+		frames = append(frames, b[off:off+dl])
+		b = b[l:]
+	}
+	return frames, nil
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
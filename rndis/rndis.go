@@ -0,0 +1,588 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rndis implements the host side of the Remote NDIS (RNDIS)
+// protocol, used by the USB Ethernet gadgets of many embedded Linux
+// boards and by Android phones sharing their connection.
+//
+// Control messages are exchanged through the CDC encapsulated command
+// requests on the control interface, Ethernet frames wrapped in packet
+// messages through the bulk endpoints of the data interface.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x0525, 0xa4a2)
+//	cfg, _ := dev.Config(2)
+//	d, err := rndis.Open(dev, cfg, 0)
+//	if err != nil { ... }
+//	defer d.Close()
+//	fmt.Println(d.HardwareAddr)
+//	frame, err := d.ReadPacket()
+package rndis
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// CDC requests carrying the RNDIS control messages.
+const (
+	requestSendEncapsulatedCommand = 0x00
+	requestGetEncapsulatedResponse = 0x01
+
+	// responseBufSize is the length of the response requests, the same
+	// as used by the Linux driver.
+	responseBufSize = 1025
+)
+
+// RNDIS message types. Completion messages have msgCompletion set.
+const (
+	msgPacket         = 0x00000001
+	msgInitialize     = 0x00000002
+	msgHalt           = 0x00000003
+	msgQuery          = 0x00000004
+	msgSet            = 0x00000005
+	msgIndicateStatus = 0x00000007
+	msgKeepalive      = 0x00000008
+
+	msgCompletion = 0x80000000
+)
+
+var msgNames = map[uint32]string{
+	msgInitialize: "REMOTE_NDIS_INITIALIZE_MSG",
+	msgQuery:      "REMOTE_NDIS_QUERY_MSG",
+	msgSet:        "REMOTE_NDIS_SET_MSG",
+	msgKeepalive:  "REMOTE_NDIS_KEEPALIVE_MSG",
+}
+
+// Parameters of the initialization. hostMaxTransfer is the longest data
+// transfer the host accepts.
+const (
+	versionMajor    = 1
+	versionMinor    = 0
+	hostMaxTransfer = 0x4000
+)
+
+// Timing of the polling for responses to control messages.
+var (
+	responseTimeout = 5 * time.Second
+	pollInterval    = 5 * time.Millisecond
+)
+
+// Controller is the subset of *gousb.Device used to exchange control
+// messages with an RNDIS device.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// IsRNDIS reports whether the interface setting is an RNDIS control
+// interface, using any of the class codes common for RNDIS.
+func IsRNDIS(s gousb.InterfaceSetting) bool {
+	switch {
+	case s.Class == gousb.ClassComm && s.SubClass == gousb.SubClassCDCACM && s.Protocol == 0xff:
+		return true
+	case s.Class == gousb.ClassWireless && s.SubClass == gousb.SubClassRadioFrequency && s.Protocol == 0x03:
+		return true
+	case s.Class == gousb.ClassMiscellaneous && s.SubClass == 0x04 && s.Protocol == 0x01:
+		return true
+	}
+	return false
+}
+
+// Status is an RNDIS status code.
+type Status uint32
+
+// Status codes.
+const (
+	StatusSuccess         Status = 0x00000000
+	StatusFailure         Status = 0xc0000001
+	StatusInvalidData     Status = 0xc0010015
+	StatusNotSupported    Status = 0xc00000bb
+	StatusMediaConnect    Status = 0x4001000b
+	StatusMediaDisconnect Status = 0x4001000c
+)
+
+var statusNames = map[Status]string{
+	StatusSuccess:         "success",
+	StatusFailure:         "failure",
+	StatusInvalidData:     "invalid data",
+	StatusNotSupported:    "not supported",
+	StatusMediaConnect:    "media connect",
+	StatusMediaDisconnect: "media disconnect",
+}
+
+// String returns a human-readable name of the status.
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("status %#08x", uint32(s))
+}
+
+// StatusError is returned when the device completes a control message
+// with a status other than StatusSuccess.
+type StatusError struct {
+	// Message is the name of the failed message.
+	Message string
+	// OID is the object of a failed query or set message.
+	OID    OID
+	Status Status
+}
+
+func (e *StatusError) Error() string {
+	if e.OID != 0 {
+		return fmt.Sprintf("rndis: %s of OID %#08x failed: %s", e.Message, uint32(e.OID), e.Status)
+	}
+	return fmt.Sprintf("rndis: %s failed: %s", e.Message, e.Status)
+}
+
+// OID is an NDIS object identifier, the subject of Query and Set.
+type OID uint32
+
+// Common OIDs.
+const (
+	OIDGenMaximumFrameSize    OID = 0x00010106
+	OIDGenLinkSpeed           OID = 0x00010107
+	OIDGenCurrentPacketFilter OID = 0x0001010e
+	OIDGenMediaConnectStatus  OID = 0x00010114
+	OID8023PermanentAddress   OID = 0x01010101
+	OID8023CurrentAddress     OID = 0x01010102
+)
+
+// PacketFilter selects the frames the device passes to the host, the
+// value of OIDGenCurrentPacketFilter.
+type PacketFilter uint32
+
+// Packet filter bits.
+const (
+	FilterDirected     PacketFilter = 0x01
+	FilterMulticast    PacketFilter = 0x02
+	FilterAllMulticast PacketFilter = 0x04
+	FilterBroadcast    PacketFilter = 0x08
+	FilterPromiscuous  PacketFilter = 0x20
+
+	// DefaultFilter is the filter set by Open.
+	DefaultFilter = FilterDirected | FilterBroadcast | FilterAllMulticast
+)
+
+// InitInfo holds the parameters of the device, returned in the
+// initialization completion message.
+type InitInfo struct {
+	// Major and Minor are the RNDIS protocol version of the device.
+	Major, Minor uint32
+	// DeviceFlags is the raw DeviceFlags field.
+	DeviceFlags uint32
+	// Medium is the medium of the device, 0 for 802.3 Ethernet.
+	Medium uint32
+	// MaxPacketsPerTransfer is the maximum number of packet messages in
+	// a single transfer to the device.
+	MaxPacketsPerTransfer int
+	// MaxTransferSize is the maximum length of a transfer to the device.
+	MaxTransferSize int
+	// PacketAlignment is the alignment of packet messages in transfers
+	// with several messages, in bytes.
+	PacketAlignment int
+}
+
+// Device is an RNDIS function of a device.
+type Device struct {
+	// Info holds the parameters reported by Initialize.
+	Info InitInfo
+	// HardwareAddr is the MAC address of the device, read by Open.
+	HardwareAddr net.HardwareAddr
+
+	c    Controller
+	intf uint16
+	in   gousb.MessageReader
+	out  io.Writer
+	done func()
+
+	// mu serializes the control message exchanges.
+	mu    sync.Mutex
+	reqID uint32
+
+	rmu     sync.Mutex
+	pending [][]byte
+
+	wmu sync.Mutex
+}
+
+// New returns a Device for the RNDIS function with the control interface
+// intf, which exchanges control messages through c and Ethernet frames
+// through in and out, the bulk endpoints of its data interface. The
+// device needs to be initialized with Initialize before use.
+//
+// Writes to out need to be terminated with a short or zero-length packet,
+// see gousb.OutEndpoint.SetZeroPacket.
+func New(c Controller, intf int, in gousb.MessageReader, out io.Writer) *Device {
+	return &Device{c: c, intf: uint16(intf), in: in, out: out}
+}
+
+// Open claims the RNDIS control interface ctrl of the configuration cfg
+// and its data interface, and returns an initialized Device. Open reads
+// the MAC address and sets DefaultFilter. The interfaces are released by
+// Close.
+func Open(dev *gousb.Device, cfg *gousb.Config, ctrl int) (_ *Device, err error) {
+	var setting *gousb.InterfaceSetting
+	for i, intf := range cfg.Desc.Interfaces {
+		if intf.Number == ctrl && len(intf.AltSettings) > 0 {
+			setting = &cfg.Desc.Interfaces[i].AltSettings[0]
+		}
+	}
+	if setting == nil || !IsRNDIS(*setting) {
+		return nil, fmt.Errorf("interface %d of %s is not an RNDIS control interface", ctrl, cfg)
+	}
+	dataNum := dataInterface(setting.Extra, ctrl)
+	var data *gousb.InterfaceDesc
+	for i, intf := range cfg.Desc.Interfaces {
+		if intf.Number == dataNum {
+			data = &cfg.Desc.Interfaces[i]
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("data interface %d of %s not found", dataNum, cfg)
+	}
+	var inEP, outEP gousb.EndpointDesc
+	alt := -1
+	for _, s := range data.AltSettings {
+		i, iok := s.BulkIn()
+		o, ook := s.BulkOut()
+		if iok && ook {
+			alt, inEP, outEP = s.Alternate, i, o
+			break
+		}
+	}
+	if alt < 0 {
+		return nil, fmt.Errorf("data interface %d of %s has no setting with bulk endpoints", dataNum, cfg)
+	}
+
+	ci, err := cfg.Interface(ctrl, 0)
+	if err != nil {
+		return nil, err
+	}
+	var di *gousb.Interface
+	defer func() {
+		if err != nil {
+			if di != nil {
+				di.Close()
+			}
+			ci.Close()
+		}
+	}()
+	if di, err = cfg.Interface(dataNum, alt); err != nil {
+		return nil, err
+	}
+	in, err := di.InEndpoint(inEP.Number)
+	if err != nil {
+		return nil, err
+	}
+	out, err := di.OutEndpoint(outEP.Number)
+	if err != nil {
+		return nil, err
+	}
+	out.SetZeroPacket(true)
+	d := New(dev, ctrl, in, out)
+	if err := d.Initialize(); err != nil {
+		return nil, err
+	}
+	mac, err := d.Query(OID8023PermanentAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("invalid MAC address % x", mac)
+	}
+	d.HardwareAddr = net.HardwareAddr(mac)
+	if err := d.SetPacketFilter(DefaultFilter); err != nil {
+		return nil, err
+	}
+	d.done = func() {
+		di.Close()
+		ci.Close()
+	}
+	return d, nil
+}
+
+// dataInterface returns the data interface from the union functional
+// descriptor in the class-specific descriptors extra of the control
+// interface ctrl, or the following interface if there's none.
+func dataInterface(extra []byte, ctrl int) int {
+	for off := 0; off+2 <= len(extra); {
+		l := int(extra[off])
+		if l < 2 || off+l > len(extra) {
+			break
+		}
+		d := extra[off : off+l]
+		off += l
+		if l >= 5 && d[1] == 0x24 && d[2] == 0x06 {
+			return int(d[4])
+		}
+	}
+	return ctrl + 1
+}
+
+// send sends the control message msg.
+func (d *Device) send(msg []byte) error {
+	_, err := d.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, requestSendEncapsulatedCommand, 0, d.intf, msg)
+	return err
+}
+
+// response returns the next control message from the device, or nil if
+// there's none yet.
+func (d *Device) response() ([]byte, error) {
+	buf := make([]byte, responseBufSize)
+	n, err := d.c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlInterface, requestGetEncapsulatedResponse, 0, d.intf, buf)
+	if err != nil {
+		return nil, err
+	}
+	// A device without a response returns a single zero byte.
+	if n < 8 && isZero(buf[:n]) {
+		return nil, nil
+	}
+	return buf[:n], nil
+}
+
+// request sends the control message of type typ with the body body and
+// returns the matching completion message.
+func (d *Device) request(typ uint32, body []byte) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	le := binary.LittleEndian
+	d.reqID++
+	id := d.reqID
+	msg := make([]byte, 12+len(body))
+	le.PutUint32(msg[0:], typ)
+	le.PutUint32(msg[4:], uint32(len(msg)))
+	le.PutUint32(msg[8:], id)
+	copy(msg[12:], body)
+	if err := d.send(msg); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %v", msgNames[typ], err)
+	}
+
+	deadline := time.Now().Add(responseTimeout)
+	for {
+		resp, err := d.response()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the response to %s: %v", msgNames[typ], err)
+		}
+		if len(resp) == 0 {
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("no response to %s within %s", msgNames[typ], responseTimeout)
+			}
+			time.Sleep(pollInterval)
+			continue
+		}
+		if len(resp) < 12 {
+			return nil, fmt.Errorf("RNDIS message too short: % x", resp)
+		}
+		switch rt, rid := le.Uint32(resp[0:]), le.Uint32(resp[8:]); {
+		case rt == msgKeepalive:
+			// The device checks if the host is alive.
+			ack := make([]byte, 16)
+			le.PutUint32(ack[0:], msgKeepalive|msgCompletion)
+			le.PutUint32(ack[4:], uint32(len(ack)))
+			le.PutUint32(ack[8:], rid)
+			if err := d.send(ack); err != nil {
+				return nil, fmt.Errorf("failed to acknowledge a keepalive: %v", err)
+			}
+		case rt == typ|msgCompletion && rid == id:
+			if len(resp) < 16 {
+				return nil, fmt.Errorf("RNDIS message too short: % x", resp)
+			}
+			if st := Status(le.Uint32(resp[12:])); st != StatusSuccess {
+				return nil, &StatusError{Message: msgNames[typ], Status: st}
+			}
+			return resp, nil
+		}
+		// Status indications and late completions of earlier requests
+		// are skipped.
+	}
+}
+
+// Initialize initializes the device and sets Info.
+func (d *Device) Initialize() error {
+	le := binary.LittleEndian
+	body := make([]byte, 12)
+	le.PutUint32(body[0:], versionMajor)
+	le.PutUint32(body[4:], versionMinor)
+	le.PutUint32(body[8:], hostMaxTransfer)
+	resp, err := d.request(msgInitialize, body)
+	if err != nil {
+		return err
+	}
+	if len(resp) < 44 {
+		return fmt.Errorf("initialization completion message too short: % x", resp)
+	}
+	d.Info = InitInfo{
+		Major:                 le.Uint32(resp[16:]),
+		Minor:                 le.Uint32(resp[20:]),
+		DeviceFlags:           le.Uint32(resp[24:]),
+		Medium:                le.Uint32(resp[28:]),
+		MaxPacketsPerTransfer: int(le.Uint32(resp[32:])),
+		MaxTransferSize:       int(le.Uint32(resp[36:])),
+		PacketAlignment:       1 << (le.Uint32(resp[40:]) & 0x1f),
+	}
+	return nil
+}
+
+// oidBody returns the body of a query or set message for oid with the
+// information buffer value.
+func oidBody(oid OID, value []byte) []byte {
+	le := binary.LittleEndian
+	body := make([]byte, 16+len(value))
+	le.PutUint32(body[0:], uint32(oid))
+	le.PutUint32(body[4:], uint32(len(value)))
+	if len(value) > 0 {
+		// The offset is counted from the RequestId field.
+		le.PutUint32(body[8:], 20)
+	}
+	copy(body[16:], value)
+	return body
+}
+
+// Query returns the value of the object oid.
+func (d *Device) Query(oid OID) ([]byte, error) {
+	resp, err := d.request(msgQuery, oidBody(oid, nil))
+	if err != nil {
+		return nil, withOID(err, oid)
+	}
+	le := binary.LittleEndian
+	if len(resp) < 24 {
+		return nil, fmt.Errorf("query completion message too short: % x", resp)
+	}
+	l, off := int(le.Uint32(resp[16:])), 8+int(le.Uint32(resp[20:]))
+	if l == 0 {
+		return nil, nil
+	}
+	if off < 24 || off+l > len(resp) {
+		return nil, fmt.Errorf("query of OID %#08x: information buffer at offset %d with length %d exceeds the message length %d", uint32(oid), off, l, len(resp))
+	}
+	return append([]byte(nil), resp[off:off+l]...), nil
+}
+
+// Set sets the object oid to value.
+func (d *Device) Set(oid OID, value []byte) error {
+	_, err := d.request(msgSet, oidBody(oid, value))
+	return withOID(err, oid)
+}
+
+// withOID adds oid to err if it's a *StatusError.
+func withOID(err error, oid OID) error {
+	if se, ok := err.(*StatusError); ok {
+		se.OID = oid
+	}
+	return err
+}
+
+// KeepAlive checks that the device is responsive. Devices may reset
+// themselves if the host is silent for a few seconds, a network stack
+// should call KeepAlive periodically when there are no other control
+// messages.
+func (d *Device) KeepAlive() error {
+	_, err := d.request(msgKeepalive, nil)
+	return err
+}
+
+// SetPacketFilter selects the frames the device passes to the host.
+func (d *Device) SetPacketFilter(f PacketFilter) error {
+	v := make([]byte, 4)
+	binary.LittleEndian.PutUint32(v, uint32(f))
+	return d.Set(OIDGenCurrentPacketFilter, v)
+}
+
+// Connected reports whether the network link of the device is up.
+func (d *Device) Connected() (bool, error) {
+	v, err := d.Query(OIDGenMediaConnectStatus)
+	if err != nil {
+		return false, err
+	}
+	if len(v) < 4 {
+		return false, fmt.Errorf("invalid media connect status % x", v)
+	}
+	// 0 is connected, 1 is disconnected.
+	return binary.LittleEndian.Uint32(v) == 0, nil
+}
+
+// ReadPacket returns the next Ethernet frame received from the device.
+// The frames of a transfer with several packet messages are returned by
+// consecutive calls.
+func (d *Device) ReadPacket() ([]byte, error) {
+	d.rmu.Lock()
+	defer d.rmu.Unlock()
+	for len(d.pending) == 0 {
+		msg, err := d.in.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if d.pending, err = DecodePackets(msg); err != nil {
+			return nil, err
+		}
+	}
+	p := d.pending[0]
+	d.pending = d.pending[1:]
+	return p, nil
+}
+
+// WritePacket sends the Ethernet frame p to the device.
+func (d *Device) WritePacket(p []byte) error {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	msg := EncodePacket(p)
+	if d.Info.MaxTransferSize > 0 && len(msg) > d.Info.MaxTransferSize {
+		return fmt.Errorf("packet message of %d bytes exceeds the maximum transfer size %d of the device", len(msg), d.Info.MaxTransferSize)
+	}
+	n, err := d.out.Write(msg)
+	if err != nil {
+		return err
+	}
+	if n < len(msg) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// Halt tells the device that the host stops using it. The device
+// doesn't respond to the message and needs to be initialized again.
+func (d *Device) Halt() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	le := binary.LittleEndian
+	d.reqID++
+	msg := make([]byte, 12)
+	le.PutUint32(msg[0:], msgHalt)
+	le.PutUint32(msg[4:], uint32(len(msg)))
+	le.PutUint32(msg[8:], d.reqID)
+	if err := d.send(msg); err != nil {
+		return fmt.Errorf("failed to send REMOTE_NDIS_HALT_MSG: %v", err)
+	}
+	return nil
+}
+
+// Close halts the device and releases the interfaces claimed by Open.
+// Transfers in progress are cancelled.
+func (d *Device) Close() error {
+	if d.done == nil {
+		return nil
+	}
+	err := d.Halt()
+	d.done()
+	d.done = nil
+	return err
+}
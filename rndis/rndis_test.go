@@ -0,0 +1,241 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rndis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/google/gousb/internal/usbtest"
+)
+
+// fakeRNDIS is an RNDIS device answering the control messages.
+type fakeRNDIS struct {
+	// responses are returned by GET_ENCAPSULATED_RESPONSE, an empty
+	// response is a single zero byte.
+	responses [][]byte
+	// sent are the messages received from the host.
+	sent   [][]byte
+	filter uint32
+	// before are queued ahead of every completion message.
+	before [][]byte
+}
+
+func (f *fakeRNDIS) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	le := binary.LittleEndian
+	switch request {
+	case requestSendEncapsulatedCommand:
+		f.sent = append(f.sent, append([]byte(nil), data...))
+		typ, id := le.Uint32(data[0:]), le.Uint32(data[8:])
+		var resp []byte
+		switch typ {
+		case msgInitialize:
+			resp = make([]byte, 52)
+			le.PutUint32(resp[16:], 1)
+			le.PutUint32(resp[32:], 1)
+			le.PutUint32(resp[36:], 1558)
+			le.PutUint32(resp[40:], 2)
+		case msgQuery:
+			var v []byte
+			switch OID(le.Uint32(data[12:])) {
+			case OID8023PermanentAddress:
+				v = []byte{0x02, 0x00, 0x5e, 0x10, 0xa0, 0xff}
+			case OIDGenMediaConnectStatus:
+				v = []byte{0, 0, 0, 0}
+			default:
+				resp = make([]byte, 24)
+				le.PutUint32(resp[12:], uint32(StatusNotSupported))
+			}
+			if resp == nil {
+				resp = make([]byte, 24+len(v))
+				le.PutUint32(resp[16:], uint32(len(v)))
+				le.PutUint32(resp[20:], 16)
+				copy(resp[24:], v)
+			}
+		case msgSet:
+			resp = make([]byte, 16)
+			if OID(le.Uint32(data[12:])) == OIDGenCurrentPacketFilter {
+				f.filter = le.Uint32(data[28:])
+			}
+		case msgKeepalive:
+			resp = make([]byte, 16)
+		default:
+			return len(data), nil
+		}
+		le.PutUint32(resp[0:], typ|msgCompletion)
+		le.PutUint32(resp[4:], uint32(len(resp)))
+		le.PutUint32(resp[8:], id)
+		f.responses = append(f.responses, f.before...)
+		f.responses = append(f.responses, resp)
+		return len(data), nil
+	case requestGetEncapsulatedResponse:
+		if len(f.responses) == 0 {
+			return copy(data, []byte{0}), nil
+		}
+		r := f.responses[0]
+		f.responses = f.responses[1:]
+		return copy(data, r), nil
+	}
+	return 0, errors.New("unsupported request")
+}
+
+func TestControlMessages(t *testing.T) {
+	f := &fakeRNDIS{}
+	d := New(f, 0, nil, nil)
+	if err := d.Initialize(); err != nil {
+		t.Fatalf("Initialize(): %v", err)
+	}
+	want := InitInfo{Major: 1, MaxPacketsPerTransfer: 1, MaxTransferSize: 1558, PacketAlignment: 4}
+	if d.Info != want {
+		t.Errorf("Info: got %+v, want %+v", d.Info, want)
+	}
+	mac, err := d.Query(OID8023PermanentAddress)
+	if err != nil {
+		t.Fatalf("Query(OID8023PermanentAddress): %v", err)
+	}
+	if want := []byte{0x02, 0x00, 0x5e, 0x10, 0xa0, 0xff}; !bytes.Equal(mac, want) {
+		t.Errorf("Query(OID8023PermanentAddress): got % x, want % x", mac, want)
+	}
+	if err := d.SetPacketFilter(DefaultFilter); err != nil {
+		t.Fatalf("SetPacketFilter(): %v", err)
+	}
+	if f.filter != uint32(DefaultFilter) {
+		t.Errorf("packet filter: got %#x, want %#x", f.filter, uint32(DefaultFilter))
+	}
+	if up, err := d.Connected(); err != nil || !up {
+		t.Errorf("Connected(): got %v, %v, want true, nil", up, err)
+	}
+	if err := d.KeepAlive(); err != nil {
+		t.Errorf("KeepAlive(): %v", err)
+	}
+	var se *StatusError
+	if _, err := d.Query(OIDGenLinkSpeed); !errors.As(err, &se) || se.Status != StatusNotSupported {
+		t.Errorf("Query(OIDGenLinkSpeed): got %v, want a StatusError with %s", err, StatusNotSupported)
+	}
+	if err := d.Halt(); err != nil {
+		t.Errorf("Halt(): %v", err)
+	}
+	var ids []uint32
+	for _, m := range f.sent {
+		ids = append(ids, binary.LittleEndian.Uint32(m[8:]))
+	}
+	if want := []uint32{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("request IDs: got %v, want %v", ids, want)
+	}
+}
+
+func TestControlUnsolicitedMessages(t *testing.T) {
+	le := binary.LittleEndian
+	indication := make([]byte, 20)
+	le.PutUint32(indication[0:], msgIndicateStatus)
+	le.PutUint32(indication[4:], 20)
+	le.PutUint32(indication[8:], uint32(StatusMediaConnect))
+	keepalive := make([]byte, 12)
+	le.PutUint32(keepalive[0:], msgKeepalive)
+	le.PutUint32(keepalive[4:], 12)
+	le.PutUint32(keepalive[8:], 0x1234)
+
+	f := &fakeRNDIS{before: [][]byte{indication, keepalive}}
+	d := New(f, 0, nil, nil)
+	if err := d.Initialize(); err != nil {
+		t.Fatalf("Initialize(): %v", err)
+	}
+	if len(f.sent) != 2 {
+		t.Fatalf("sent messages: got %d, want the initialization and a keepalive completion", len(f.sent))
+	}
+	ack := f.sent[1]
+	if typ, id := le.Uint32(ack[0:]), le.Uint32(ack[8:]); typ != msgKeepalive|msgCompletion || id != 0x1234 {
+		t.Errorf("keepalive completion: got type %#x, id %#x, want %#x, 0x1234", typ, id, msgKeepalive|msgCompletion)
+	}
+}
+
+func TestControlTimeout(t *testing.T) {
+	oldTimeout := responseTimeout
+	defer func() { responseTimeout = oldTimeout }()
+	responseTimeout = 20 * pollInterval
+	f := &fakeRNDIS{}
+	d := New(f, 0, nil, nil)
+	// Drop the completion message.
+	d.c = controlFunc(func(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+		n, err := f.Control(rType, request, val, idx, data)
+		f.responses = nil
+		return n, err
+	})
+	if err := d.KeepAlive(); err == nil {
+		t.Error("KeepAlive() without a response: got nil error, want non-nil")
+	}
+}
+
+type controlFunc func(rType, request uint8, val, idx uint16, data []byte) (int, error)
+
+func (f controlFunc) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	return f(rType, request, val, idx, data)
+}
+
+func TestPackets(t *testing.T) {
+	transfer := append(EncodePacket([]byte("frame 1")), EncodePacket([]byte("frame 2"))...)
+	in := &usbtest.Messages{transfer, append(EncodePacket([]byte("frame 3")), 0)}
+	var out bytes.Buffer
+	d := New(&fakeRNDIS{}, 0, in, &out)
+	for _, want := range []string{"frame 1", "frame 2", "frame 3"} {
+		p, err := d.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket(): %v", err)
+		}
+		if string(p) != want {
+			t.Errorf("ReadPacket(): got %q, want %q", p, want)
+		}
+	}
+	if _, err := d.ReadPacket(); err != io.EOF {
+		t.Errorf("ReadPacket() at the end: got %v, want io.EOF", err)
+	}
+
+	if err := d.WritePacket([]byte("sent")); err != nil {
+		t.Fatalf("WritePacket(): %v", err)
+	}
+	got, err := DecodePackets(out.Bytes())
+	if err != nil {
+		t.Fatalf("DecodePackets(written data): %v", err)
+	}
+	if len(got) != 1 || string(got[0]) != "sent" {
+		t.Errorf("written frames: got %q, want [\"sent\"]", got)
+	}
+	d.Info.MaxTransferSize = 40
+	if err := d.WritePacket([]byte("sent")); err == nil {
+		t.Error("WritePacket() exceeding MaxTransferSize: got nil error, want non-nil")
+	}
+}
+
+func TestDecodePacketsInvalid(t *testing.T) {
+	valid := EncodePacket([]byte("frame"))
+	for _, tc := range []struct {
+		desc   string
+		modify func(b []byte) []byte
+	}{
+		{"short", func(b []byte) []byte { return b[:20] }},
+		{"wrong type", func(b []byte) []byte { b[0] = 2; return b }},
+		{"length beyond the transfer", func(b []byte) []byte { b[4] = 0xff; return b }},
+		{"data beyond the message", func(b []byte) []byte { b[12] = 0xff; return b }},
+	} {
+		b := tc.modify(append([]byte(nil), valid...))
+		if _, err := DecodePackets(b); err == nil {
+			t.Errorf("DecodePackets(%s): got nil error, want non-nil", tc.desc)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/gousb"
+)
+
+// CDC ACM class requests.
+const (
+	requestSetLineCoding       = 0x20
+	requestSetControlLineState = 0x22
+)
+
+// ACM is a CDC ACM serial port.
+type ACM struct {
+	port
+	intf uint16
+
+	mu       sync.Mutex
+	dtr, rts bool
+}
+
+// OpenACM returns a Port for the CDC ACM function with index n of the
+// active configuration of dev, counted from 0.
+func OpenACM(dev *gousb.Device, n int) (*ACM, error) {
+	cfg, err := activeConfig(dev)
+	if err != nil {
+		return nil, err
+	}
+	var acm []gousb.Function
+	for _, f := range cfg.Functions() {
+		// RNDIS functions use the ACM subclass with a vendor protocol.
+		if f.Class == gousb.ClassComm && f.SubClass == gousb.SubClassCDCACM && f.Protocol != 0xff && len(f.Interfaces) == 2 {
+			acm = append(acm, f)
+		}
+	}
+	if n < 0 || n >= len(acm) {
+		cfg.Close()
+		return nil, fmt.Errorf("%s has %d CDC ACM functions, port %d not found", dev, len(acm), n)
+	}
+	ctrl, err := cfg.Interface(acm[n].Interfaces[0], 0)
+	if err != nil {
+		cfg.Close()
+		return nil, err
+	}
+	data, in, out, err := openBulk(cfg, acm[n].Interfaces[1])
+	if err != nil {
+		ctrl.Close()
+		cfg.Close()
+		return nil, err
+	}
+	p := newACM(dev, ctrl.Setting.Number, in, out)
+	p.done = func() {
+		data.Close()
+		ctrl.Close()
+		cfg.Close()
+	}
+	return p, nil
+}
+
+func newACM(c Controller, intf int, in io.Reader, out io.Writer) *ACM {
+	return &ACM{port: port{c: c, in: in, out: out}, intf: uint16(intf)}
+}
+
+func (p *ACM) control(request uint8, val uint16, data []byte) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, request, val, p.intf, data)
+	return err
+}
+
+// SetMode sets the line coding of the port. CDC ACM has no flow
+// control requests, m.Flow needs to be FlowNone.
+func (p *ACM) SetMode(m Mode) error {
+	m, err := m.check()
+	if err != nil {
+		return err
+	}
+	if m.Flow != FlowNone {
+		return fmt.Errorf("CDC ACM doesn't support flow control")
+	}
+	lc := make([]byte, 7)
+	binary.LittleEndian.PutUint32(lc, uint32(m.BaudRate))
+	lc[4] = byte(m.StopBits)
+	lc[5] = byte(m.Parity)
+	lc[6] = byte(m.DataBits)
+	if err := p.control(requestSetLineCoding, 0, lc); err != nil {
+		return fmt.Errorf("SET_LINE_CODING failed: %v", err)
+	}
+	return nil
+}
+
+func (p *ACM) setLines(dtr, rts *bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, r := p.dtr, p.rts
+	if dtr != nil {
+		d = *dtr
+	}
+	if rts != nil {
+		r = *rts
+	}
+	var v uint16
+	if d {
+		v |= 0x01
+	}
+	if r {
+		v |= 0x02
+	}
+	if err := p.control(requestSetControlLineState, v, nil); err != nil {
+		return fmt.Errorf("SET_CONTROL_LINE_STATE failed: %v", err)
+	}
+	p.dtr, p.rts = d, r
+	return nil
+}
+
+// SetDTR sets the DTR line.
+func (p *ACM) SetDTR(on bool) error {
+	return p.setLines(&on, nil)
+}
+
+// SetRTS sets the RTS line.
+func (p *ACM) SetRTS(on bool) error {
+	return p.setLines(nil, &on)
+}
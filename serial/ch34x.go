@@ -0,0 +1,277 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/gousb"
+)
+
+const vendorWCH gousb.ID = 0x1a86
+
+// ch34xProducts are the product IDs of the CH340 and CH341 in serial mode.
+var ch34xProducts = map[gousb.ID]bool{
+	0x5523: true,
+	0x7522: true,
+	0x7523: true,
+	0xe523: true,
+}
+
+func isCH34x(vid, pid gousb.ID) bool {
+	return vid == vendorWCH && ch34xProducts[pid]
+}
+
+// CH34x vendor requests and registers.
+const (
+	ch34xReadVersion = 0x5f
+	ch34xWriteReg    = 0x9a
+	ch34xSerialInit  = 0xa1
+	ch34xModemCtrl   = 0xa4
+
+	ch34xRegPrescaler = 0x12
+	ch34xRegDivisor   = 0x13
+	ch34xRegLCR       = 0x18
+	ch34xRegLCR2      = 0x25
+	ch34xRegRTSCTS    = 0x27
+)
+
+// Bits of the line control register.
+const (
+	ch34xLCREnableRX  = 0x80
+	ch34xLCREnableTX  = 0x40
+	ch34xLCRMarkSpace = 0x20
+	ch34xLCRParEven   = 0x10
+	ch34xLCREnablePar = 0x08
+	ch34xLCRStopBits2 = 0x04
+)
+
+// Bits of the modem control request, which are active low.
+const (
+	ch34xBitDTR = 1 << 5
+	ch34xBitRTS = 1 << 6
+)
+
+// The baud rate generator of the CH34x divides a 48 MHz clock by
+// a prescaler of 2, 16, 128 or 1024, times 1 or 2 (fact), and by a divisor
+// of 2 to 256.
+const (
+	ch34xClockRate = 48000000
+	ch34xMinRate   = 46
+	ch34xMaxRate   = 3000000
+)
+
+// ch34xClockDiv returns the clock division of the prescaler ps and fact.
+func ch34xClockDiv(ps, fact int) int {
+	return 1 << uint(12-3*ps-fact)
+}
+
+// ch34xDivisor returns the value of the prescaler and divisor registers
+// for the baud rate closest to baud, following the Linux driver.
+func ch34xDivisor(baud int) (uint16, error) {
+	if baud < ch34xMinRate || baud > ch34xMaxRate {
+		return 0, fmt.Errorf("baud rate %d out of range %d..%d for CH34x", baud, ch34xMinRate, ch34xMaxRate)
+	}
+	// Pick the highest base clock (fact = 1) giving a divisor strictly
+	// less than 512.
+	fact := 1
+	ps := 3
+	for ; ps > 0; ps-- {
+		if baud > ch34xClockRate/(ch34xClockDiv(ps, 1)*512) {
+			break
+		}
+	}
+	clkDiv := ch34xClockDiv(ps, fact)
+	div := ch34xClockRate / (clkDiv * baud)
+	if div < 9 || div > 255 {
+		div /= 2
+		clkDiv *= 2
+		fact = 0
+	}
+	if div < 2 {
+		return 0, fmt.Errorf("baud rate %d too high for CH34x", baud)
+	}
+	// Use the next divisor if its rate is closer to the requested one.
+	if 16*ch34xClockRate/(clkDiv*div)-16*baud >= 16*baud-16*ch34xClockRate/(clkDiv*(div+1)) {
+		div++
+	}
+	// Prefer the lower base clock for even divisors, which some chips
+	// need for rates above 1 Mbps.
+	if fact == 1 && div%2 == 0 {
+		div /= 2
+		fact = 0
+	}
+	return uint16((0x100-div)<<8 | fact<<2 | ps), nil
+}
+
+// CH34x is the serial port of a WCH CH340 or CH341 chip.
+type CH34x struct {
+	port
+	// Version is the chip version reported at initialization.
+	Version uint8
+
+	mu       sync.Mutex
+	dtr, rts bool
+}
+
+// OpenCH34x returns a Port for a CH340 or CH341 chip. The chips have
+// a single port, n needs to be 0.
+func OpenCH34x(dev *gousb.Device, n int) (*CH34x, error) {
+	if n != 0 {
+		return nil, fmt.Errorf("%s has a single port, port %d not found", dev, n)
+	}
+	cfg, err := activeConfig(dev)
+	if err != nil {
+		return nil, err
+	}
+	intf, in, out, err := openBulk(cfg, 0)
+	if err != nil {
+		cfg.Close()
+		return nil, err
+	}
+	p := newCH34x(dev, in, out)
+	p.done = func() {
+		intf.Close()
+		cfg.Close()
+	}
+	if err := p.init(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func newCH34x(c Controller, in io.Reader, out io.Writer) *CH34x {
+	return &CH34x{port: port{c: c, in: in, out: out}}
+}
+
+func (p *CH34x) control(request uint8, val, idx uint16) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlVendor|gousb.ControlDevice, request, val, idx, nil)
+	return err
+}
+
+// init reads the version of the chip and initializes it for 9600 baud
+// with 8 data bits, no parity and 1 stop bit.
+func (p *CH34x) init() error {
+	buf := make([]byte, 2)
+	n, err := p.c.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, ch34xReadVersion, 0, 0, buf)
+	if err != nil {
+		return fmt.Errorf("failed to read the CH34x version: %v", err)
+	}
+	if n < 1 {
+		return fmt.Errorf("failed to read the CH34x version: got %d bytes, want 2", n)
+	}
+	p.Version = buf[0]
+	if err := p.control(ch34xSerialInit, 0, 0); err != nil {
+		return fmt.Errorf("failed to initialize the CH34x: %v", err)
+	}
+	return p.SetMode(Mode{BaudRate: 9600})
+}
+
+// SetMode sets the baud rate, framing and flow control of the port.
+// Chips older than version 0x30 only support 8 data bits, no parity and
+// 1 stop bit. StopBits1Half, FlowDTRDSR and FlowXonXoff aren't supported.
+func (p *CH34x) SetMode(m Mode) error {
+	m, err := m.check()
+	if err != nil {
+		return err
+	}
+	if m.StopBits == StopBits1Half {
+		return fmt.Errorf("CH34x doesn't support 1.5 stop bits")
+	}
+	div, err := ch34xDivisor(m.BaudRate)
+	if err != nil {
+		return err
+	}
+	lcr := uint16(ch34xLCREnableRX | ch34xLCREnableTX | (m.DataBits - 5))
+	switch m.Parity {
+	case ParityOdd:
+		lcr |= ch34xLCREnablePar
+	case ParityEven:
+		lcr |= ch34xLCREnablePar | ch34xLCRParEven
+	case ParityMark:
+		lcr |= ch34xLCREnablePar | ch34xLCRMarkSpace
+	case ParitySpace:
+		lcr |= ch34xLCREnablePar | ch34xLCRMarkSpace | ch34xLCRParEven
+	}
+	if m.StopBits == StopBits2 {
+		lcr |= ch34xLCRStopBits2
+	}
+	if p.Version < 0x30 && lcr != ch34xLCREnableRX|ch34xLCREnableTX|3 {
+		return fmt.Errorf("CH34x version %#x only supports 8 data bits, no parity and 1 stop bit", p.Version)
+	}
+	var flow uint16
+	switch m.Flow {
+	case FlowNone:
+	case FlowRTSCTS:
+		flow = 0x0101
+	default:
+		return fmt.Errorf("CH34x doesn't support flow control %d", m.Flow)
+	}
+
+	// Without bit 7 of the divisor newer chips buffer the received data
+	// until the buffer is full.
+	if p.Version > 0x27 {
+		div |= 0x80
+	}
+	if err := p.control(ch34xWriteReg, ch34xRegDivisor<<8|ch34xRegPrescaler, div); err != nil {
+		return fmt.Errorf("failed to set the baud rate to %d: %v", m.BaudRate, err)
+	}
+	if p.Version >= 0x30 {
+		if err := p.control(ch34xWriteReg, ch34xRegLCR2<<8|ch34xRegLCR, lcr); err != nil {
+			return fmt.Errorf("failed to set the line properties: %v", err)
+		}
+	}
+	if err := p.control(ch34xWriteReg, ch34xRegRTSCTS<<8|ch34xRegRTSCTS, flow); err != nil {
+		return fmt.Errorf("failed to set the flow control: %v", err)
+	}
+	return nil
+}
+
+func (p *CH34x) setLines(dtr, rts *bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, r := p.dtr, p.rts
+	if dtr != nil {
+		d = *dtr
+	}
+	if rts != nil {
+		r = *rts
+	}
+	var v uint8
+	if d {
+		v |= ch34xBitDTR
+	}
+	if r {
+		v |= ch34xBitRTS
+	}
+	if err := p.control(ch34xModemCtrl, uint16(^v), 0); err != nil {
+		return fmt.Errorf("failed to set the modem control lines: %v", err)
+	}
+	p.dtr, p.rts = d, r
+	return nil
+}
+
+// SetDTR sets the DTR line.
+func (p *CH34x) SetDTR(on bool) error {
+	return p.setLines(&on, nil)
+}
+
+// SetRTS sets the RTS line.
+func (p *CH34x) SetRTS(on bool) error {
+	return p.setLines(nil, &on)
+}
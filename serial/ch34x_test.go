@@ -0,0 +1,77 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import "testing"
+
+func TestCH34xDivisor(t *testing.T) {
+	for _, tc := range []struct {
+		baud int
+		want uint16
+	}{
+		{9600, 0xb202},
+		{115200, 0xcc03},
+	} {
+		got, err := ch34xDivisor(tc.baud)
+		if err != nil {
+			t.Errorf("ch34xDivisor(%d): %v", tc.baud, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ch34xDivisor(%d): got %#04x, want %#04x", tc.baud, got, tc.want)
+		}
+	}
+	for _, baud := range []int{45, 3000001} {
+		if _, err := ch34xDivisor(baud); err == nil {
+			t.Errorf("ch34xDivisor(%d): got nil error, want non-nil", baud)
+		}
+	}
+}
+
+func TestCH34x(t *testing.T) {
+	f := &fakeChip{in: []byte{0x31, 0x00}}
+	p := newCH34x(f, nil, nil)
+	if err := p.init(); err != nil {
+		t.Fatalf("init(): %v", err)
+	}
+	if p.Version != 0x31 {
+		t.Errorf("Version: got %#x, want 0x31", p.Version)
+	}
+	if err := p.SetMode(Mode{BaudRate: 115200, Parity: ParityEven, Flow: FlowRTSCTS}); err != nil {
+		t.Fatalf("SetMode(): %v", err)
+	}
+	if err := p.SetDTR(true); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	f.check(t, "CH34x", []request{
+		{0xc0, ch34xReadVersion, 0, 0, ""},
+		{0x40, ch34xSerialInit, 0, 0, ""},
+		{0x40, ch34xWriteReg, 0x1312, 0xb282, ""},
+		{0x40, ch34xWriteReg, 0x2518, 0xc3, ""},
+		{0x40, ch34xWriteReg, 0x2727, 0, ""},
+		{0x40, ch34xWriteReg, 0x1312, 0xcc83, ""},
+		{0x40, ch34xWriteReg, 0x2518, 0xdb, ""},
+		{0x40, ch34xWriteReg, 0x2727, 0x0101, ""},
+		{0x40, ch34xModemCtrl, 0xdf, 0, ""},
+	})
+
+	p.Version = 0x27
+	if err := p.SetMode(Mode{BaudRate: 9600, DataBits: 7}); err == nil {
+		t.Error("SetMode() with 7 data bits on version 0x27: got nil error, want non-nil")
+	}
+	if !isCH34x(0x1a86, 0x7523) || isCH34x(0x1a86, 0x1234) {
+		t.Error("isCH34x(): CH340 not recognized or another device recognized")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/google/gousb"
+)
+
+const vendorSiliconLabs gousb.ID = 0x10c4
+
+// cp210xProducts are the product IDs of the CP210x chips with the
+// Silicon Labs vendor ID: CP2101 to CP2104 and CP2102N, CP2105 and CP2108.
+var cp210xProducts = map[gousb.ID]bool{
+	0xea60: true,
+	0xea70: true,
+	0xea71: true,
+}
+
+func isCP210x(vid, pid gousb.ID) bool {
+	return vid == vendorSiliconLabs && cp210xProducts[pid]
+}
+
+// CP210x vendor requests, see Silicon Labs AN571.
+const (
+	cp210xIfcEnable   = 0x00
+	cp210xSetLineCtl  = 0x03
+	cp210xSetMHS      = 0x07
+	cp210xSetFlow     = 0x13
+	cp210xSetBaudRate = 0x1e
+
+	// cp210xMaxBaudRate is the highest baud rate of the CP2102N and
+	// CP2108, the other chips reject rates above 1 or 2 Mbps.
+	cp210xMaxBaudRate = 3000000
+)
+
+// The SET_FLOW request data: the control handshake and flow replace bit
+// fields, followed by the XON and XOFF limits.
+const (
+	cp210xDTRActive    = 0x01
+	cp210xCTSHandshake = 0x08
+	cp210xAutoTransmit = 0x01
+	cp210xAutoReceive  = 0x02
+	cp210xRTSActive    = 0x40
+	cp210xRTSHandshake = 0x80
+
+	cp210xFlowLimit     = 128
+	cp210xSetFlowLength = 16
+)
+
+// CP210x is a serial port of a Silicon Labs CP210x chip.
+type CP210x struct {
+	port
+	intf uint16
+}
+
+// OpenCP210x returns a Port for the port with index n of a CP210x chip,
+// counted from 0. Only the CP2105 and CP2108 have more than one port.
+func OpenCP210x(dev *gousb.Device, n int) (*CP210x, error) {
+	cfg, err := activeConfig(dev)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n >= len(cfg.Desc.Interfaces) {
+		cfg.Close()
+		return nil, fmt.Errorf("%s has %d ports, port %d not found", dev, len(cfg.Desc.Interfaces), n)
+	}
+	intf, in, out, err := openBulk(cfg, cfg.Desc.Interfaces[n].Number)
+	if err != nil {
+		cfg.Close()
+		return nil, err
+	}
+	p := newCP210x(dev, intf.Setting.Number, in, out)
+	p.done = func() {
+		// The port is disabled again when it's closed.
+		p.control(cp210xIfcEnable, 0, nil)
+		intf.Close()
+		cfg.Close()
+	}
+	if err := p.control(cp210xIfcEnable, 1, nil); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to enable CP210x port %d: %v", n, err)
+	}
+	return p, nil
+}
+
+func newCP210x(c Controller, intf int, in io.Reader, out io.Writer) *CP210x {
+	return &CP210x{port: port{c: c, in: in, out: out}, intf: uint16(intf)}
+}
+
+func (p *CP210x) control(request uint8, val uint16, data []byte) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlVendor|gousb.ControlInterface, request, val, p.intf, data)
+	return err
+}
+
+// SetMode sets the baud rate, framing and flow control of the port.
+// FlowDTRDSR isn't supported.
+func (p *CP210x) SetMode(m Mode) error {
+	m, err := m.check()
+	if err != nil {
+		return err
+	}
+	if m.BaudRate > cp210xMaxBaudRate {
+		return fmt.Errorf("baud rate %d too high for CP210x", m.BaudRate)
+	}
+	le := binary.LittleEndian
+	baud := make([]byte, 4)
+	le.PutUint32(baud, uint32(m.BaudRate))
+	if err := p.control(cp210xSetBaudRate, 0, baud); err != nil {
+		return fmt.Errorf("failed to set the baud rate to %d: %v", m.BaudRate, err)
+	}
+	lineCtl := uint16(m.StopBits) | uint16(m.Parity)<<4 | uint16(m.DataBits)<<8
+	if err := p.control(cp210xSetLineCtl, lineCtl, nil); err != nil {
+		return fmt.Errorf("failed to set the line properties: %v", err)
+	}
+	var handshake, replace uint32
+	switch m.Flow {
+	case FlowNone:
+		handshake, replace = cp210xDTRActive, cp210xRTSActive
+	case FlowRTSCTS:
+		handshake, replace = cp210xDTRActive|cp210xCTSHandshake, cp210xRTSHandshake
+	case FlowXonXoff:
+		handshake, replace = cp210xDTRActive, cp210xRTSActive|cp210xAutoTransmit|cp210xAutoReceive
+	default:
+		return fmt.Errorf("CP210x doesn't support flow control %d", m.Flow)
+	}
+	flow := make([]byte, cp210xSetFlowLength)
+	le.PutUint32(flow[0:], handshake)
+	le.PutUint32(flow[4:], replace)
+	le.PutUint32(flow[8:], cp210xFlowLimit)
+	le.PutUint32(flow[12:], cp210xFlowLimit)
+	if err := p.control(cp210xSetFlow, 0, flow); err != nil {
+		return fmt.Errorf("failed to set the flow control: %v", err)
+	}
+	return nil
+}
+
+// setMHS sets the modem handshake line selected by mask, 0x01 for DTR
+// and 0x02 for RTS.
+func (p *CP210x) setMHS(mask uint16, on bool) error {
+	v := mask << 8
+	if on {
+		v |= mask
+	}
+	return p.control(cp210xSetMHS, v, nil)
+}
+
+// SetDTR sets the DTR line.
+func (p *CP210x) SetDTR(on bool) error {
+	if err := p.setMHS(0x01, on); err != nil {
+		return fmt.Errorf("failed to set DTR: %v", err)
+	}
+	return nil
+}
+
+// SetRTS sets the RTS line.
+func (p *CP210x) SetRTS(on bool) error {
+	if err := p.setMHS(0x02, on); err != nil {
+		return fmt.Errorf("failed to set RTS: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import "testing"
+
+func TestCP210x(t *testing.T) {
+	f := &fakeChip{}
+	p := newCP210x(f, 1, nil, nil)
+	if err := p.SetMode(Mode{BaudRate: 921600, Flow: FlowRTSCTS}); err != nil {
+		t.Fatalf("SetMode(): %v", err)
+	}
+	if err := p.SetDTR(true); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	if err := p.SetRTS(false); err != nil {
+		t.Fatalf("SetRTS(): %v", err)
+	}
+	f.check(t, "CP210x", []request{
+		{0x41, cp210xSetBaudRate, 0, 1, "\x00\x10\x0e\x00"},
+		{0x41, cp210xSetLineCtl, 0x0800, 1, ""},
+		{0x41, cp210xSetFlow, 0, 1, "\x09\x00\x00\x00\x80\x00\x00\x00\x80\x00\x00\x00\x80\x00\x00\x00"},
+		{0x41, cp210xSetMHS, 0x0101, 1, ""},
+		{0x41, cp210xSetMHS, 0x0200, 1, ""},
+	})
+	for _, m := range []Mode{
+		{BaudRate: 4000000},
+		{BaudRate: 9600, Flow: FlowDTRDSR},
+	} {
+		if err := p.SetMode(m); err == nil {
+			t.Errorf("SetMode(%+v): got nil error, want non-nil", m)
+		}
+	}
+	if !isCP210x(0x10c4, 0xea60) || isCP210x(0x10c4, 0x1234) {
+		t.Error("isCP210x(): CP2102 not recognized or another device recognized")
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+const vendorFTDI gousb.ID = 0x0403
+
+// FTDI vendor requests.
+const (
+	ftdiReset           = 0x00
+	ftdiModemCtrl       = 0x01
+	ftdiSetFlowCtrl     = 0x02
+	ftdiSetBaudRate     = 0x03
+	ftdiSetData         = 0x04
+	ftdiSetLatencyTimer = 0x09
+	ftdiGetLatencyTimer = 0x0a
+
+	// ftdiStatusSize is the length of the modem and line status at the
+	// start of every packet received from the chip.
+	ftdiStatusSize = 2
+	// ftdiReadPackets is the number of packets read at once.
+	ftdiReadPackets = 16
+)
+
+// FTDIChip is the type of an FTDI chip.
+type FTDIChip int
+
+// FTDI chip types, identified by the device version.
+const (
+	ChipFT232BM FTDIChip = iota
+	ChipFT2232C
+	ChipFT232R
+	ChipFT2232H
+	ChipFT4232H
+	ChipFT232H
+	ChipFTX
+)
+
+var ftdiChipNames = map[FTDIChip]string{
+	ChipFT232BM: "FT232BM",
+	ChipFT2232C: "FT2232C",
+	ChipFT232R:  "FT232R",
+	ChipFT2232H: "FT2232H",
+	ChipFT4232H: "FT4232H",
+	ChipFT232H:  "FT232H",
+	ChipFTX:     "FT-X",
+}
+
+// String returns the name of the chip.
+func (c FTDIChip) String() string {
+	if n, ok := ftdiChipNames[c]; ok {
+		return n
+	}
+	return fmt.Sprintf("FTDIChip(%d)", int(c))
+}
+
+// ftdiChip returns the chip type for the device version v.
+func ftdiChip(v gousb.BCD) FTDIChip {
+	switch v {
+	case 0x0500:
+		return ChipFT2232C
+	case 0x0600:
+		return ChipFT232R
+	case 0x0700:
+		return ChipFT2232H
+	case 0x0800:
+		return ChipFT4232H
+	case 0x0900:
+		return ChipFT232H
+	case 0x1000:
+		return ChipFTX
+	}
+	return ChipFT232BM
+}
+
+// hiSpeed reports whether the chip has the 120 MHz baud rate generator
+// of the high speed chips.
+func (c FTDIChip) hiSpeed() bool {
+	return c == ChipFT2232H || c == ChipFT4232H || c == ChipFT232H
+}
+
+// ftdiDivFrac encodes the fractional part of a baud rate divisor, in
+// eighths, into the bits 14 to 16 of the divisor.
+var ftdiDivFrac = [8]uint32{0, 3, 2, 4, 1, 5, 6, 7}
+
+// ftdiDivisor returns the encoded baud rate divisor for baud.
+func ftdiDivisor(baud int, chip FTDIChip) (uint32, error) {
+	var d3 int
+	hi := chip.hiSpeed() && baud >= 1200
+	switch {
+	case hi && baud <= 12000000:
+		// 120 MHz clock divided by 10, in eighths.
+		d3 = (8*120000000 + 5*baud) / (10 * baud)
+	case !hi && baud <= 3000000:
+		// 48 MHz clock divided by 16, in eighths.
+		d3 = (48000000 + baud) / (2 * baud)
+	default:
+		return 0, fmt.Errorf("baud rate %d too high for %s", baud, chip)
+	}
+	if d3>>3 > 0x3fff {
+		return 0, fmt.Errorf("baud rate %d too low for %s", baud, chip)
+	}
+	d := uint32(d3>>3) | ftdiDivFrac[d3&7]<<14
+	// Divisors of 1 and 1.5 have special encodings.
+	switch d {
+	case 1:
+		d = 0
+	case 0x4001:
+		d = 1
+	}
+	if hi {
+		// Turns off the divide by 2.5 of the high speed chips.
+		d |= 0x20000
+	}
+	return d, nil
+}
+
+// FTDI is a serial port of an FTDI chip.
+type FTDI struct {
+	port
+	// Chip is the type of the chip.
+	Chip FTDIChip
+	// index is the port of a multi-port chip, 1 for the first one, or
+	// 0 for a single port chip.
+	index      uint16
+	packetSize int
+
+	rmu     sync.Mutex
+	buf     []byte
+	pending []byte
+}
+
+// OpenFTDI returns a Port for the port with index n of an FTDI chip,
+// counted from 0, e.g. 1 for the B port of an FT2232.
+func OpenFTDI(dev *gousb.Device, n int) (*FTDI, error) {
+	cfg, err := activeConfig(dev)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n >= len(cfg.Desc.Interfaces) {
+		cfg.Close()
+		return nil, fmt.Errorf("%s has %d ports, port %d not found", dev, len(cfg.Desc.Interfaces), n)
+	}
+	intf, in, out, err := openBulk(cfg, cfg.Desc.Interfaces[n].Number)
+	if err != nil {
+		cfg.Close()
+		return nil, err
+	}
+	index := 0
+	if len(cfg.Desc.Interfaces) > 1 {
+		index = n + 1
+	}
+	p := newFTDI(dev, ftdiChip(dev.Desc.Device), index, in.Desc.MaxPacketSize, in, out)
+	p.done = func() {
+		intf.Close()
+		cfg.Close()
+	}
+	if err := p.control(ftdiReset, 0, p.index); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to reset %s port %d: %v", p.Chip, n, err)
+	}
+	return p, nil
+}
+
+func newFTDI(c Controller, chip FTDIChip, index, packetSize int, in io.Reader, out io.Writer) *FTDI {
+	return &FTDI{
+		port:       port{c: c, in: in, out: out},
+		Chip:       chip,
+		index:      uint16(index),
+		packetSize: packetSize,
+		buf:        make([]byte, ftdiReadPackets*packetSize),
+	}
+}
+
+func (p *FTDI) control(request uint8, val, idx uint16) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlVendor|gousb.ControlDevice, request, val, idx, nil)
+	return err
+}
+
+// Read reads the data received by the port. The status bytes the chip
+// sends at the start of every packet are removed.
+func (p *FTDI) Read(b []byte) (int, error) {
+	p.rmu.Lock()
+	defer p.rmu.Unlock()
+	for len(p.pending) == 0 {
+		n, err := p.in.Read(p.buf)
+		p.pending = ftdiStrip(p.buf[:n], p.packetSize)
+		if err != nil && len(p.pending) == 0 {
+			return 0, err
+		}
+	}
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+// ftdiStrip removes the status bytes from the packets of size ps in b,
+// in place, and returns the data.
+func ftdiStrip(b []byte, ps int) []byte {
+	n := 0
+	for off := 0; off < len(b); off += ps {
+		end := off + ps
+		if end > len(b) {
+			end = len(b)
+		}
+		if end-off > ftdiStatusSize {
+			n += copy(b[n:], b[off+ftdiStatusSize:end])
+		}
+	}
+	return b[:n]
+}
+
+// SetMode sets the baud rate, framing and flow control of the port.
+func (p *FTDI) SetMode(m Mode) error {
+	m, err := m.check()
+	if err != nil {
+		return err
+	}
+	div, err := ftdiDivisor(m.BaudRate, p.Chip)
+	if err != nil {
+		return err
+	}
+	idx := uint16(div >> 16)
+	if p.Chip != ChipFT232BM && p.Chip != ChipFT232R {
+		idx = idx<<8 | p.index
+	}
+	if err := p.control(ftdiSetBaudRate, uint16(div), idx); err != nil {
+		return fmt.Errorf("failed to set the baud rate to %d: %v", m.BaudRate, err)
+	}
+	data := uint16(m.DataBits) | uint16(m.Parity)<<8 | uint16(m.StopBits)<<11
+	if err := p.control(ftdiSetData, data, p.index); err != nil {
+		return fmt.Errorf("failed to set the line properties: %v", err)
+	}
+	var flow, val uint16
+	switch m.Flow {
+	case FlowNone:
+	case FlowRTSCTS:
+		flow = 0x01
+	case FlowDTRDSR:
+		flow = 0x02
+	case FlowXonXoff:
+		flow = 0x04
+		val = 0x11 | 0x13<<8 // XON and XOFF characters
+	default:
+		return fmt.Errorf("invalid flow control %d", m.Flow)
+	}
+	if err := p.control(ftdiSetFlowCtrl, val, flow<<8|p.index); err != nil {
+		return fmt.Errorf("failed to set the flow control: %v", err)
+	}
+	return nil
+}
+
+// SetDTR sets the DTR line.
+func (p *FTDI) SetDTR(on bool) error {
+	var v uint16 = 0x0100
+	if on {
+		v |= 0x01
+	}
+	if err := p.control(ftdiModemCtrl, v, p.index); err != nil {
+		return fmt.Errorf("failed to set DTR: %v", err)
+	}
+	return nil
+}
+
+// SetRTS sets the RTS line.
+func (p *FTDI) SetRTS(on bool) error {
+	var v uint16 = 0x0200
+	if on {
+		v |= 0x02
+	}
+	if err := p.control(ftdiModemCtrl, v, p.index); err != nil {
+		return fmt.Errorf("failed to set RTS: %v", err)
+	}
+	return nil
+}
+
+// SetLatencyTimer sets the time the chip waits before sending an
+// incomplete packet, 1 to 255 ms. The default of 16 ms slows down
+// protocols with short request and response messages.
+func (p *FTDI) SetLatencyTimer(d time.Duration) error {
+	ms := d / time.Millisecond
+	if ms < 1 || ms > 255 {
+		return fmt.Errorf("invalid latency timer %s, want 1ms..255ms", d)
+	}
+	if err := p.control(ftdiSetLatencyTimer, uint16(ms), p.index); err != nil {
+		return fmt.Errorf("failed to set the latency timer: %v", err)
+	}
+	return nil
+}
+
+// LatencyTimer returns the latency timer of the chip.
+func (p *FTDI) LatencyTimer() (time.Duration, error) {
+	buf := make([]byte, 1)
+	n, err := p.c.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, ftdiGetLatencyTimer, 0, p.index, buf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the latency timer: %v", err)
+	}
+	if n != 1 {
+		return 0, fmt.Errorf("failed to read the latency timer: got %d bytes, want 1", n)
+	}
+	return time.Duration(buf[0]) * time.Millisecond, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestFTDIDivisor(t *testing.T) {
+	for _, tc := range []struct {
+		baud int
+		chip FTDIChip
+		want uint32
+	}{
+		{9600, ChipFT232R, 0x4138},
+		{115200, ChipFT232R, 0x001a},
+		{3000000, ChipFT232R, 0x0000},
+		{2000000, ChipFT232R, 0x0001},
+		{115200, ChipFT2232H, 0x2c068},
+		{300, ChipFT2232H, 0x2710},
+		{12000000, ChipFT2232H, 0x20000},
+	} {
+		got, err := ftdiDivisor(tc.baud, tc.chip)
+		if err != nil {
+			t.Errorf("ftdiDivisor(%d, %s): %v", tc.baud, tc.chip, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ftdiDivisor(%d, %s): got %#x, want %#x", tc.baud, tc.chip, got, tc.want)
+		}
+	}
+	for _, tc := range []struct {
+		baud int
+		chip FTDIChip
+	}{
+		{4000000, ChipFT232R},
+		{100, ChipFT232R},
+		{15000000, ChipFT232H},
+	} {
+		if _, err := ftdiDivisor(tc.baud, tc.chip); err == nil {
+			t.Errorf("ftdiDivisor(%d, %s): got nil error, want non-nil", tc.baud, tc.chip)
+		}
+	}
+}
+
+// packets is a reader returning a transfer per Read, made of 4 byte
+// packets starting with two status bytes.
+type packets [][]byte
+
+func (p *packets) Read(b []byte) (int, error) {
+	if len(*p) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, (*p)[0])
+	*p = (*p)[1:]
+	return n, nil
+}
+
+func TestFTDIRead(t *testing.T) {
+	in := &packets{
+		[]byte("\x01\x60ab\x01\x60cd\x01\x60e"),
+		// Only the status, sent when the latency timer expires.
+		[]byte("\x01\x60"),
+		[]byte("\x01\x60fg"),
+	}
+	p := newFTDI(&fakeChip{}, ChipFT232R, 0, 4, in, nil)
+	got, err := readAll(p)
+	if err != io.EOF {
+		t.Errorf("Read(): got error %v, want io.EOF", err)
+	}
+	if want := "abcdefg"; string(got) != want {
+		t.Errorf("Read(): got %q, want %q", got, want)
+	}
+}
+
+// readAll reads r with a small buffer until an error.
+func readAll(r io.Reader) ([]byte, error) {
+	var out bytes.Buffer
+	buf := make([]byte, 3)
+	for {
+		n, err := r.Read(buf)
+		out.Write(buf[:n])
+		if err != nil {
+			return out.Bytes(), err
+		}
+	}
+}
+
+func TestFTDIRequests(t *testing.T) {
+	f := &fakeChip{in: []byte{16}}
+	p := newFTDI(f, ChipFT2232H, 2, 512, nil, nil)
+	if err := p.SetMode(Mode{BaudRate: 115200, Parity: ParityOdd, Flow: FlowRTSCTS}); err != nil {
+		t.Fatalf("SetMode(): %v", err)
+	}
+	if err := p.SetDTR(true); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	if err := p.SetRTS(false); err != nil {
+		t.Fatalf("SetRTS(): %v", err)
+	}
+	if err := p.SetLatencyTimer(2 * time.Millisecond); err != nil {
+		t.Fatalf("SetLatencyTimer(): %v", err)
+	}
+	if d, err := p.LatencyTimer(); err != nil || d != 16*time.Millisecond {
+		t.Errorf("LatencyTimer(): got %s, %v, want 16ms, nil", d, err)
+	}
+	f.check(t, "FT2232H port B", []request{
+		{0x40, ftdiSetBaudRate, 0xc068, 0x0202, ""},
+		{0x40, ftdiSetData, 0x0108, 2, ""},
+		{0x40, ftdiSetFlowCtrl, 0, 0x0102, ""},
+		{0x40, ftdiModemCtrl, 0x0101, 2, ""},
+		{0x40, ftdiModemCtrl, 0x0200, 2, ""},
+		{0x40, ftdiSetLatencyTimer, 2, 2, ""},
+		{0xc0, ftdiGetLatencyTimer, 0, 2, ""},
+	})
+	if err := p.SetLatencyTimer(time.Second); err == nil {
+		t.Error("SetLatencyTimer(1s): got nil error, want non-nil")
+	}
+}
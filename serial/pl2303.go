@@ -0,0 +1,271 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/google/gousb"
+)
+
+const (
+	vendorProlific gousb.ID = 0x067b
+	productPL2303  gousb.ID = 0x2303
+)
+
+func isPL2303(vid, pid gousb.ID) bool {
+	return vid == vendorProlific && pid == productPL2303
+}
+
+// PL2303 requests. Besides the vendor register access, the chip uses
+// the CDC ACM line coding and control line requests.
+const (
+	pl2303Vendor             = 0x01
+	pl2303SetLineRequest     = 0x20
+	pl2303SetControlRequest  = 0x22
+	pl2303FlowCtrlMask       = 0xf0
+	pl2303FlowCtrlRTSCTS     = 0x60
+	pl2303FlowCtrlRTSCTSType = 0x40 // legacy chips
+	pl2303FlowCtrlXonXoff    = 0xc0
+)
+
+// pl2303Rates are the baud rates the chip supports directly, other rates
+// are set through a divisor.
+var pl2303Rates = map[int]bool{
+	75: true, 150: true, 300: true, 600: true, 1200: true, 1800: true,
+	2400: true, 3600: true, 4800: true, 7200: true, 9600: true, 14400: true,
+	19200: true, 28800: true, 38400: true, 57600: true, 115200: true,
+	230400: true, 460800: true, 614400: true, 921600: true, 1228800: true,
+	2457600: true, 3000000: true, 6000000: true,
+}
+
+// pl2303EncodeBaud writes the baud rate to the first 4 bytes of the line
+// coding lc.
+func pl2303EncodeBaud(lc []byte, baud int) {
+	if pl2303Rates[baud] {
+		binary.LittleEndian.PutUint32(lc, uint32(baud))
+		return
+	}
+	// baud = 12 MHz * 32 / (mantissa * 4^exponent), with a 9 bit
+	// mantissa and a 3 bit exponent.
+	const baseline = 12000000 * 32
+	mantissa := baseline / baud
+	if mantissa == 0 {
+		mantissa = 1
+	}
+	exponent := 0
+	for mantissa >= 512 {
+		if exponent == 7 {
+			mantissa = 511
+			break
+		}
+		mantissa >>= 2
+		exponent++
+	}
+	lc[0] = byte(mantissa)
+	lc[1] = byte(exponent<<1 | mantissa>>8)
+	lc[2] = 0
+	lc[3] = 0x80
+}
+
+// PL2303 is the serial port of a Prolific PL2303 chip. The PL2303HXN
+// variants, e.g. the PL2303GC, use a different protocol and aren't
+// supported.
+type PL2303 struct {
+	port
+	// Legacy is true for the original PL2303, type H, which is limited
+	// to 1228800 baud.
+	Legacy bool
+
+	mu       sync.Mutex
+	dtr, rts bool
+}
+
+// OpenPL2303 returns a Port for a PL2303 chip. The chip has a single
+// port, n needs to be 0.
+func OpenPL2303(dev *gousb.Device, n int) (*PL2303, error) {
+	if n != 0 {
+		return nil, fmt.Errorf("%s has a single port, port %d not found", dev, n)
+	}
+	cfg, err := activeConfig(dev)
+	if err != nil {
+		return nil, err
+	}
+	intf, in, out, err := openBulk(cfg, 0)
+	if err != nil {
+		cfg.Close()
+		return nil, err
+	}
+	legacy := dev.Desc.Class == gousb.ClassComm || dev.Desc.MaxControlPacketSize != 64
+	p := newPL2303(dev, legacy, in, out)
+	p.done = func() {
+		intf.Close()
+		cfg.Close()
+	}
+	if err := p.init(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func newPL2303(c Controller, legacy bool, in io.Reader, out io.Writer) *PL2303 {
+	return &PL2303{port: port{c: c, in: in, out: out}, Legacy: legacy}
+}
+
+func (p *PL2303) vendorRead(val uint16) (byte, error) {
+	buf := make([]byte, 1)
+	n, err := p.c.Control(gousb.ControlIn|gousb.ControlVendor|gousb.ControlDevice, pl2303Vendor, val, 0, buf)
+	if err != nil {
+		return 0, err
+	}
+	if n != 1 {
+		return 0, fmt.Errorf("vendor read %#04x: got %d bytes, want 1", val, n)
+	}
+	return buf[0], nil
+}
+
+func (p *PL2303) vendorWrite(val, idx uint16) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlVendor|gousb.ControlDevice, pl2303Vendor, val, idx, nil)
+	return err
+}
+
+func (p *PL2303) classOut(request uint8, val uint16, data []byte) error {
+	_, err := p.c.Control(gousb.ControlOut|gousb.ControlClass|gousb.ControlInterface, request, val, 0, data)
+	return err
+}
+
+// init runs the vendor initialization sequence of the chip, the same as
+// the one of the Windows and Linux drivers.
+func (p *PL2303) init() error {
+	steps := []struct {
+		read     bool
+		val, idx uint16
+	}{
+		{true, 0x8484, 0},
+		{false, 0x0404, 0},
+		{true, 0x8484, 0},
+		{true, 0x8383, 0},
+		{true, 0x8484, 0},
+		{false, 0x0404, 1},
+		{true, 0x8484, 0},
+		{true, 0x8383, 0},
+		{false, 0, 1},
+		{false, 1, 0},
+		{false, 2, 0x44},
+	}
+	if p.Legacy {
+		steps[len(steps)-1].idx = 0x24
+	}
+	for _, s := range steps {
+		var err error
+		if s.read {
+			_, err = p.vendorRead(s.val)
+		} else {
+			err = p.vendorWrite(s.val, s.idx)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to initialize the PL2303: %v", err)
+		}
+	}
+	return nil
+}
+
+// updateReg sets the bits mask of the vendor register reg to val.
+func (p *PL2303) updateReg(reg, mask, val uint8) error {
+	v, err := p.vendorRead(uint16(reg | 0x80))
+	if err != nil {
+		return err
+	}
+	return p.vendorWrite(uint16(reg), uint16(v&^mask|val&mask))
+}
+
+// SetMode sets the baud rate, framing and flow control of the port.
+// FlowDTRDSR isn't supported, nor is FlowXonXoff on legacy chips.
+func (p *PL2303) SetMode(m Mode) error {
+	m, err := m.check()
+	if err != nil {
+		return err
+	}
+	max := 6000000
+	if p.Legacy {
+		max = 1228800
+	}
+	if m.BaudRate > max {
+		return fmt.Errorf("baud rate %d too high for PL2303, the maximum is %d", m.BaudRate, max)
+	}
+	lc := make([]byte, 7)
+	pl2303EncodeBaud(lc, m.BaudRate)
+	lc[4] = byte(m.StopBits)
+	lc[5] = byte(m.Parity)
+	lc[6] = byte(m.DataBits)
+
+	var flow uint8
+	switch {
+	case m.Flow == FlowNone:
+	case m.Flow == FlowRTSCTS && p.Legacy:
+		flow = pl2303FlowCtrlRTSCTSType
+	case m.Flow == FlowRTSCTS:
+		flow = pl2303FlowCtrlRTSCTS
+	case m.Flow == FlowXonXoff && !p.Legacy:
+		flow = pl2303FlowCtrlXonXoff
+	default:
+		return fmt.Errorf("PL2303 doesn't support flow control %d", m.Flow)
+	}
+	if err := p.classOut(pl2303SetLineRequest, 0, lc); err != nil {
+		return fmt.Errorf("failed to set the line coding: %v", err)
+	}
+	if err := p.updateReg(0, pl2303FlowCtrlMask, flow); err != nil {
+		return fmt.Errorf("failed to set the flow control: %v", err)
+	}
+	return nil
+}
+
+func (p *PL2303) setLines(dtr, rts *bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d, r := p.dtr, p.rts
+	if dtr != nil {
+		d = *dtr
+	}
+	if rts != nil {
+		r = *rts
+	}
+	var v uint16
+	if d {
+		v |= 0x01
+	}
+	if r {
+		v |= 0x02
+	}
+	if err := p.classOut(pl2303SetControlRequest, v, nil); err != nil {
+		return fmt.Errorf("failed to set the modem control lines: %v", err)
+	}
+	p.dtr, p.rts = d, r
+	return nil
+}
+
+// SetDTR sets the DTR line.
+func (p *PL2303) SetDTR(on bool) error {
+	return p.setLines(&on, nil)
+}
+
+// SetRTS sets the RTS line.
+func (p *PL2303) SetRTS(on bool) error {
+	return p.setLines(nil, &on)
+}
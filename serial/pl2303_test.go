@@ -0,0 +1,84 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import "testing"
+
+func TestPL2303EncodeBaud(t *testing.T) {
+	for _, tc := range []struct {
+		baud int
+		want string
+	}{
+		{9600, "\x80\x25\x00\x00"},
+		{115200, "\x00\xc2\x01\x00"},
+		// 12 MHz * 32 / 500000 = 768, 192 * 4^1.
+		{500000, "\xc0\x02\x00\x80"},
+	} {
+		lc := make([]byte, 4)
+		pl2303EncodeBaud(lc, tc.baud)
+		if got := string(lc); got != tc.want {
+			t.Errorf("pl2303EncodeBaud(%d): got % x, want % x", tc.baud, got, tc.want)
+		}
+	}
+}
+
+func TestPL2303(t *testing.T) {
+	f := &fakeChip{in: []byte{0xff}}
+	p := newPL2303(f, false, nil, nil)
+	if err := p.init(); err != nil {
+		t.Fatalf("init(): %v", err)
+	}
+	if err := p.SetMode(Mode{BaudRate: 115200, StopBits: StopBits2, Flow: FlowRTSCTS}); err != nil {
+		t.Fatalf("SetMode(): %v", err)
+	}
+	if err := p.SetRTS(true); err != nil {
+		t.Fatalf("SetRTS(): %v", err)
+	}
+	if err := p.SetDTR(true); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	f.check(t, "PL2303", []request{
+		{0xc0, pl2303Vendor, 0x8484, 0, ""},
+		{0x40, pl2303Vendor, 0x0404, 0, ""},
+		{0xc0, pl2303Vendor, 0x8484, 0, ""},
+		{0xc0, pl2303Vendor, 0x8383, 0, ""},
+		{0xc0, pl2303Vendor, 0x8484, 0, ""},
+		{0x40, pl2303Vendor, 0x0404, 1, ""},
+		{0xc0, pl2303Vendor, 0x8484, 0, ""},
+		{0xc0, pl2303Vendor, 0x8383, 0, ""},
+		{0x40, pl2303Vendor, 0, 1, ""},
+		{0x40, pl2303Vendor, 1, 0, ""},
+		{0x40, pl2303Vendor, 2, 0x44, ""},
+		{0x21, pl2303SetLineRequest, 0, 0, "\x00\xc2\x01\x00\x02\x00\x08"},
+		{0xc0, pl2303Vendor, 0x80, 0, ""},
+		{0x40, pl2303Vendor, 0, 0x6f, ""},
+		{0x21, pl2303SetControlRequest, 0x02, 0, ""},
+		{0x21, pl2303SetControlRequest, 0x03, 0, ""},
+	})
+
+	p.Legacy = true
+	for _, m := range []Mode{
+		{BaudRate: 2457600},
+		{BaudRate: 9600, Flow: FlowXonXoff},
+		{BaudRate: 9600, Flow: FlowDTRDSR},
+	} {
+		if err := p.SetMode(m); err == nil {
+			t.Errorf("SetMode(%+v) on a legacy chip: got nil error, want non-nil", m)
+		}
+	}
+	if !isPL2303(0x067b, 0x2303) || isPL2303(0x067b, 0x23a3) {
+		t.Error("isPL2303(): PL2303 not recognized or another device recognized")
+	}
+}
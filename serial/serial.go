@@ -0,0 +1,215 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serial implements drivers for USB serial ports: the standard
+// CDC ACM class and the vendor protocols of common USB to serial bridge
+// chips. All drivers implement Port.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x0403, 0x6001)
+//	p, err := serial.Open(dev, 0)
+//	if err != nil { ... }
+//	defer p.Close()
+//	if err := p.SetMode(serial.Mode{BaudRate: 115200}); err != nil { ... }
+//	p.Write([]byte("AT\r"))
+package serial
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/gousb"
+)
+
+// Port is a USB serial port.
+type Port interface {
+	// Read reads the data received by the port.
+	Read(p []byte) (int, error)
+	// Write sends p through the port.
+	Write(p []byte) (int, error)
+	// Close releases the interfaces claimed by the driver.
+	Close() error
+	// SetMode sets the baud rate, framing and flow control of the port.
+	SetMode(m Mode) error
+	// SetDTR and SetRTS set the DTR and RTS modem control lines.
+	SetDTR(on bool) error
+	SetRTS(on bool) error
+}
+
+// Parity is the parity of the characters.
+type Parity int
+
+// Parity modes.
+const (
+	ParityNone Parity = iota
+	ParityOdd
+	ParityEven
+	ParityMark
+	ParitySpace
+)
+
+// StopBits is the number of stop bits of the characters.
+type StopBits int
+
+// Stop bit settings.
+const (
+	StopBits1 StopBits = iota
+	StopBits1Half
+	StopBits2
+)
+
+// FlowControl is the flow control of the port.
+type FlowControl int
+
+// Flow control modes. Not every driver supports every mode.
+const (
+	FlowNone FlowControl = iota
+	FlowRTSCTS
+	FlowDTRDSR
+	FlowXonXoff
+)
+
+// Mode is the configuration of a serial port.
+type Mode struct {
+	// BaudRate is the speed of the port in bits per second.
+	BaudRate int
+	// DataBits is the number of data bits of a character, 8 if 0.
+	DataBits int
+	Parity   Parity
+	StopBits StopBits
+	Flow     FlowControl
+}
+
+// check validates m and fills in the defaults.
+func (m Mode) check() (Mode, error) {
+	if m.BaudRate <= 0 {
+		return m, fmt.Errorf("invalid baud rate %d", m.BaudRate)
+	}
+	if m.DataBits == 0 {
+		m.DataBits = 8
+	}
+	if m.DataBits < 5 || m.DataBits > 8 {
+		return m, fmt.Errorf("invalid number of data bits %d, want 5..8", m.DataBits)
+	}
+	if m.Parity < ParityNone || m.Parity > ParitySpace {
+		return m, fmt.Errorf("invalid parity %d", m.Parity)
+	}
+	if m.StopBits < StopBits1 || m.StopBits > StopBits2 {
+		return m, fmt.Errorf("invalid stop bits %d", m.StopBits)
+	}
+	return m, nil
+}
+
+// Controller is the subset of *gousb.Device used to send the control
+// requests of a serial port.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// Open returns a Port for the serial port with index n of dev, counted
+// from 0. The driver is selected by the vendor and product IDs of dev,
+// CDC ACM is used for devices no vendor driver handles.
+func Open(dev *gousb.Device, n int) (Port, error) {
+	var (
+		p   Port
+		err error
+	)
+	switch {
+	case dev.Desc.Vendor == vendorFTDI:
+		p, err = OpenFTDI(dev, n)
+	case isCP210x(dev.Desc.Vendor, dev.Desc.Product):
+		p, err = OpenCP210x(dev, n)
+	case isCH34x(dev.Desc.Vendor, dev.Desc.Product):
+		p, err = OpenCH34x(dev, n)
+	case isPL2303(dev.Desc.Vendor, dev.Desc.Product):
+		p, err = OpenPL2303(dev, n)
+	default:
+		p, err = OpenACM(dev, n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// port holds what the drivers have in common: the control pipe, the
+// bulk endpoints and the release of the claimed interfaces.
+type port struct {
+	c    Controller
+	in   io.Reader
+	out  io.Writer
+	done func()
+}
+
+// Read reads the data received by the port.
+func (p *port) Read(b []byte) (int, error) {
+	return p.in.Read(b)
+}
+
+// Write sends b through the port.
+func (p *port) Write(b []byte) (int, error) {
+	return p.out.Write(b)
+}
+
+// Close releases the interfaces claimed by the driver.
+func (p *port) Close() error {
+	if p.done != nil {
+		p.done()
+		p.done = nil
+	}
+	return nil
+}
+
+// activeConfig returns the active configuration of dev.
+func activeConfig(dev *gousb.Device) (*gousb.Config, error) {
+	num, err := dev.ActiveConfigNum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active config number of device %s: %v", dev, err)
+	}
+	if num == 0 && len(dev.Desc.Configs) > 0 {
+		// Config value 0 means the device is in the address state.
+		for n := range dev.Desc.Configs {
+			if num == 0 || n < num {
+				num = n
+			}
+		}
+	}
+	return dev.Config(num)
+}
+
+// openBulk claims interface num of cfg and opens its bulk endpoints.
+func openBulk(cfg *gousb.Config, num int) (*gousb.Interface, *gousb.InEndpoint, *gousb.OutEndpoint, error) {
+	intf, err := cfg.Interface(num, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	inDesc, iok := intf.Setting.BulkIn()
+	outDesc, ook := intf.Setting.BulkOut()
+	if !iok || !ook {
+		intf.Close()
+		return nil, nil, nil, fmt.Errorf("%s doesn't have bulk IN and OUT endpoints", intf)
+	}
+	in, err := intf.InEndpoint(inDesc.Number)
+	if err != nil {
+		intf.Close()
+		return nil, nil, nil, err
+	}
+	out, err := intf.OutEndpoint(outDesc.Number)
+	if err != nil {
+		intf.Close()
+		return nil, nil, nil, err
+	}
+	return intf, in, out, nil
+}
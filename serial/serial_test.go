@@ -0,0 +1,111 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serial
+
+import (
+	"bytes"
+	"testing"
+)
+
+// request is a control request sent to a fake chip.
+type request struct {
+	rType, request uint8
+	val, idx       uint16
+	data           string
+}
+
+// fakeChip records the control requests.
+type fakeChip struct {
+	reqs []request
+	// in is returned by the IN requests.
+	in []byte
+}
+
+func (f *fakeChip) Control(rType, req uint8, val, idx uint16, data []byte) (int, error) {
+	if rType&0x80 != 0 {
+		f.reqs = append(f.reqs, request{rType, req, val, idx, ""})
+		return copy(data, f.in), nil
+	}
+	f.reqs = append(f.reqs, request{rType, req, val, idx, string(data)})
+	return len(data), nil
+}
+
+func (f *fakeChip) check(t *testing.T, desc string, want []request) {
+	t.Helper()
+	if len(f.reqs) != len(want) {
+		t.Fatalf("%s: got requests %+v, want %+v", desc, f.reqs, want)
+	}
+	for i := range want {
+		if f.reqs[i] != want[i] {
+			t.Errorf("%s: request %d: got %+v, want %+v", desc, i, f.reqs[i], want[i])
+		}
+	}
+	f.reqs = nil
+}
+
+func TestModeCheck(t *testing.T) {
+	m, err := Mode{BaudRate: 9600}.check()
+	if err != nil {
+		t.Fatalf("check(): %v", err)
+	}
+	if m.DataBits != 8 {
+		t.Errorf("check(): got %d data bits, want the default of 8", m.DataBits)
+	}
+	for _, m := range []Mode{
+		{},
+		{BaudRate: 9600, DataBits: 9},
+		{BaudRate: 9600, Parity: ParitySpace + 1},
+		{BaudRate: 9600, StopBits: -1},
+	} {
+		if _, err := m.check(); err == nil {
+			t.Errorf("check(%+v): got nil error, want non-nil", m)
+		}
+	}
+}
+
+func TestACM(t *testing.T) {
+	f := &fakeChip{}
+	var out bytes.Buffer
+	p := newACM(f, 2, bytes.NewReader([]byte("hello")), &out)
+	if err := p.SetMode(Mode{BaudRate: 115200, Parity: ParityEven, StopBits: StopBits2, DataBits: 7}); err != nil {
+		t.Fatalf("SetMode(): %v", err)
+	}
+	if err := p.SetDTR(true); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	if err := p.SetRTS(true); err != nil {
+		t.Fatalf("SetRTS(): %v", err)
+	}
+	if err := p.SetDTR(false); err != nil {
+		t.Fatalf("SetDTR(): %v", err)
+	}
+	f.check(t, "ACM", []request{
+		{0x21, requestSetLineCoding, 0, 2, "\x00\xc2\x01\x00\x02\x02\x07"},
+		{0x21, requestSetControlLineState, 0x01, 2, ""},
+		{0x21, requestSetControlLineState, 0x03, 2, ""},
+		{0x21, requestSetControlLineState, 0x02, 2, ""},
+	})
+	if err := p.SetMode(Mode{BaudRate: 115200, Flow: FlowRTSCTS}); err == nil {
+		t.Error("SetMode() with flow control: got nil error, want non-nil")
+	}
+
+	buf := make([]byte, 10)
+	if n, err := p.Read(buf); err != nil || string(buf[:n]) != "hello" {
+		t.Errorf("Read(): got %q, %v, want \"hello\", nil", buf[:n], err)
+	}
+	if _, err := p.Write([]byte("world")); err != nil || out.String() != "world" {
+		t.Errorf("Write(): got %v with %q written, want nil with \"world\"", err, out.String())
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Directories in which Linux exposes USB devices. Tests replace them
+// with temporary directories.
+var (
+	sysfsUSBDevices = "/sys/bus/usb/devices"
+	usbfsDevices    = "/dev/bus/usb"
+)
+
+// SysfsPath returns the directory of the device in sysfs, e.g.
+// "/sys/bus/usb/devices/3-1.2", whose attributes are also used by udev
+// rules. SysfsPath is only supported on Linux.
+func (d *Device) SysfsPath() (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("sysfs is not supported on %s", runtime.GOOS)
+	}
+	if len(d.Desc.Path) == 0 {
+		return "", fmt.Errorf("port path of %s is not known", d)
+	}
+	return filepath.Join(sysfsUSBDevices, d.Desc.portPath()), nil
+}
+
+// DevNode returns the device node through which libusb accesses the
+// device: the usbfs node on Linux, e.g. "/dev/bus/usb/003/007", or the
+// ugen node on FreeBSD, e.g. "/dev/ugen3.7". DevNode is only supported
+// on Linux and FreeBSD.
+func (d *Device) DevNode() (string, error) {
+	node := d.Desc.devNode(runtime.GOOS)
+	if node == "" {
+		return "", fmt.Errorf("device nodes are not supported on %s", runtime.GOOS)
+	}
+	return node, nil
+}
+
+// devNode returns the path of the device node of the device on the given
+// platform, or an empty string if the platform has no device nodes.
+func (d *DeviceDesc) devNode(goos string) string {
+	switch goos {
+	case "linux":
+		return filepath.Join(usbfsDevices, fmt.Sprintf("%03d", d.Bus), fmt.Sprintf("%03d", d.Address))
+	case "freebsd":
+		return fmt.Sprintf("/dev/ugen%d.%d", d.Bus, d.Address)
+	}
+	return ""
+}
+
+// kernelDriver returns the name of the kernel driver bound to interface
+// intf of config cfg of the device, e.g. "usbhid", or an empty string if
+// there's none or it can't be determined. It's only supported on Linux.
+func (d *DeviceDesc) kernelDriver(cfg, intf int) string {
+	if runtime.GOOS != "linux" || len(d.Path) == 0 {
+		return ""
+	}
+	link, err := os.Readlink(filepath.Join(sysfsUSBDevices, fmt.Sprintf("%s:%d.%d", d.portPath(), cfg, intf), "driver"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(link)
+}
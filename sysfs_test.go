@@ -0,0 +1,119 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSysfsPath(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sysfs is only supported on Linux")
+	}
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+
+	if got, err := dev.SysfsPath(); err != nil || got != "/sys/bus/usb/devices/1-1" {
+		t.Errorf("SysfsPath(): got %q, %v, want %q, nil", got, err, "/sys/bus/usb/devices/1-1")
+	}
+	if got, err := dev.DevNode(); err != nil || got != "/dev/bus/usb/001/001" {
+		t.Errorf("DevNode(): got %q, %v, want %q, nil", got, err, "/dev/bus/usb/001/001")
+	}
+	desc := *dev.Desc
+	desc.Path = nil
+	dev.Desc = &desc
+	if _, err := dev.SysfsPath(); err == nil {
+		t.Errorf("SysfsPath() with unknown port path: got nil error, want non-nil")
+	}
+}
+
+func TestDevNodePlatforms(t *testing.T) {
+	desc := &DeviceDesc{Bus: 3, Address: 7}
+	for _, tc := range []struct {
+		goos string
+		want string
+	}{
+		{"linux", usbfsDevices + "/003/007"},
+		{"freebsd", "/dev/ugen3.7"},
+		{"darwin", ""},
+		{"windows", ""},
+		{"openbsd", ""},
+		{"netbsd", ""},
+	} {
+		if got := desc.devNode(tc.goos); got != tc.want {
+			t.Errorf("devNode(%q): got %q, want %q", tc.goos, got, tc.want)
+		}
+		if hint := permissionHint(tc.goos); hint == "" {
+			t.Errorf("permissionHint(%q): got an empty hint", tc.goos)
+		}
+	}
+}
+
+func TestClaimBusyKernelDriver(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sysfs is only supported on Linux")
+	}
+	dir, err := ioutil.TempDir("", "gousb-sysfs")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	old := sysfsUSBDevices
+	sysfsUSBDevices = dir
+	defer func() { sysfsUSBDevices = old }()
+
+	if err := os.MkdirAll(filepath.Join(dir, "1-1:1.0"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.Symlink("../../../bus/usb/drivers/usbhid", filepath.Join(dir, "1-1:1.0", "driver")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	lib := newFakeLibusb()
+	lib.claimErr = ErrorBusy
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	defer cfg.Close()
+
+	_, err = cfg.Interface(0, 0)
+	if err == nil || !strings.Contains(err.Error(), "kernel driver usbhid") {
+		t.Errorf("Interface(0, 0): got error %v, want a mention of kernel driver usbhid", err)
+	}
+
+	lib.claimErr = ErrorAccess
+	_, err = cfg.Interface(0, 0)
+	if err == nil || strings.Contains(err.Error(), "kernel driver") {
+		t.Errorf("Interface(0, 0) with ErrorAccess: got error %v, want an error without a kernel driver", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// tracer writes transfer traces, see Context.SetTrace.
+type tracer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	dump bool
+}
+
+// traceRecord describes a single traced transfer.
+type traceRecord struct {
+	// bus and addr identify the device, they are 0 if unknown.
+	bus, addr int
+	dir       EndpointDirection
+	ep        EndpointAddress
+	tt        TransferType
+	// setup is the description of the control request, empty for other
+	// transfer types.
+	setup string
+	// setupPacket is the raw SETUP packet of a control request, nil for
+	// other transfer types.
+	setupPacket []byte
+	// length is the requested transfer length.
+	length int
+	// outData is the payload submitted by an OUT transfer.
+	outData []byte
+	// data is the payload actually transferred.
+	data  []byte
+	err   error
+	start time.Time
+	dur   time.Duration
+}
+
+func (t *tracer) trace(r traceRecord) {
+	var buf bytes.Buffer
+	status := TransferCompleted.String()
+	if r.err != nil {
+		status = r.err.Error()
+	}
+	fmt.Fprintf(&buf, "%s %s ep=%s", r.tt, r.dir, r.ep)
+	if r.setup != "" {
+		fmt.Fprintf(&buf, " %s", r.setup)
+	}
+	fmt.Fprintf(&buf, " len=%d actual=%d status=%q dur=%s\n", r.length, len(r.data), status, r.dur)
+	if t.dump && len(r.data) > 0 {
+		buf.WriteString(hex.Dump(r.data))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(buf.Bytes())
+}
+
+// SetTrace enables tracing of all control, bulk, interrupt and isochronous
+// transfers of the devices opened through the Context. Every completed
+// transfer is written to w as a single line with the transfer type,
+// direction, endpoint, requested and actual length, status and duration.
+// If dump is true, the line is followed by a hex dump of the transferred
+// payload. A nil w disables tracing.
+//
+// Tracing is meant for debugging and reverse-engineering of device
+// protocols and slows down the transfers, especially with dump enabled.
+// Transfers done through ReadStream and WriteStream are not traced.
+func (c *Context) SetTrace(w io.Writer, dump bool) {
+	if w == nil {
+		c.tracer.Store((*tracer)(nil))
+		return
+	}
+	c.tracer.Store(&tracer{w: w, dump: dump})
+}
+
+// getTracer returns the active tracer, or nil if tracing is disabled.
+func (c *Context) getTracer() *tracer {
+	t, _ := c.tracer.Load().(*tracer)
+	return t
+}
+
+// tracing reports whether completed transfers need to be passed to trace,
+// i.e. whether a tracer or a capture is active.
+func (c *Context) tracing() bool {
+	return c.getTracer() != nil || c.getCapture() != nil
+}
+
+// trace passes a completed transfer to the active tracer and capture.
+func (c *Context) trace(r traceRecord) {
+	if t := c.getTracer(); t != nil {
+		t.trace(r)
+	}
+	if p := c.getCapture(); p != nil {
+		p.capture(r)
+	}
+}
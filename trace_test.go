@@ -0,0 +1,75 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	var out bytes.Buffer
+	ctx.SetTrace(&out, true)
+
+	go func() {
+		ft := lib.waitForSubmitted(nil)
+		ft.setData([]byte("hello"))
+		ft.setStatus(TransferCompleted)
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999, 0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	if _, err := ep.Read(make([]byte, 512)); err != nil {
+		t.Errorf("%s.Read(): %v", ep, err)
+	}
+	// fakeLibusb doesn't implement control transfers, the error is traced.
+	dev.Control(ControlIn|ControlVendor|ControlDevice, 0x42, 0x1234, 0, make([]byte, 8))
+
+	want := regexp.MustCompile(`^bulk IN ep=0x82 len=512 actual=5 status="transfer completed without error" dur=\S+
+00000000  68 65 6c 6c 6f +\|hello\|
+control IN ep=0x00 bmRequestType=0xc0 bRequest=0x42 wValue=0x1234 wIndex=0x0000 len=8 actual=0 status="not implemented" dur=\S+
+$`)
+	if got := out.String(); !want.MatchString(got) {
+		t.Errorf("trace output:\n%s\ndoes not match:\n%s", got, want)
+	}
+
+	out.Reset()
+	ctx.SetTrace(nil, false)
+	dev.Control(ControlIn|ControlVendor|ControlDevice, 0x42, 0x1234, 0, make([]byte, 8))
+	if out.Len() != 0 {
+		t.Errorf("trace output after SetTrace(nil): got %q, want empty", out.String())
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 // Copyright 2013 Google Inc.  All rights reserved.
 // Copyright 2016 the gousb Authors.  All rights reserved.
 //
@@ -76,7 +79,19 @@ int gousb_compact_iso_data(struct libusb_transfer *xfer, unsigned char *status)
 	return sum;
 }
 
+// copies the results of the isochronous packets of the transfer to lengths,
+// actual and status, each of which holds xfer->num_iso_packets elements.
+void gousb_iso_packets(struct libusb_transfer *xfer, int *lengths, int *actual, int *status) {
+	int i;
+	for (i = 0; i < xfer->num_iso_packets; i++) {
+		lengths[i] = xfer->iso_packet_desc[i].length;
+		actual[i] = xfer->iso_packet_desc[i].actual_length;
+		status[i] = xfer->iso_packet_desc[i].status;
+	}
+}
+
 // allocates a libusb transfer and a buffer for packet data.
+// The buffer is owned by the transfer and released together with it.
 struct libusb_transfer *gousb_alloc_transfer_and_buffer(int bufLen, int isoPackets) {
         struct libusb_transfer *xfer = libusb_alloc_transfer(isoPackets);
         if (xfer == NULL) {
@@ -88,13 +103,28 @@ struct libusb_transfer *gousb_alloc_transfer_and_buffer(int bufLen, int isoPacke
                 return NULL;
         }
         xfer->length = bufLen;
+        xfer->flags = LIBUSB_TRANSFER_FREE_BUFFER;
+        return xfer;
+}
+
+// allocates a libusb transfer using an existing buffer for packet data,
+// e.g. one obtained from libusb_dev_mem_alloc. The buffer is not owned
+// by the transfer and is left intact when the transfer is released.
+struct libusb_transfer *gousb_alloc_transfer_with_buffer(unsigned char *buf, int bufLen, int isoPackets) {
+        struct libusb_transfer *xfer = libusb_alloc_transfer(isoPackets);
+        if (xfer == NULL) {
+                return NULL;
+        }
+        xfer->buffer = buf;
+        xfer->length = bufLen;
         return xfer;
 }
 
-// frees a libusb transfer and its buffer. The buffer of the given
-// libusb_transfer must have been allocated with alloc_transfer_and_buffer.
+// frees a libusb transfer and, if the transfer owns it, its buffer.
+// The given libusb_transfer must have been allocated with
+// gousb_alloc_transfer_and_buffer or gousb_alloc_transfer_with_buffer.
 void gousb_free_transfer_and_buffer(struct libusb_transfer *xfer) {
-        free(xfer->buffer);
         xfer->length = 0;
+        // LIBUSB_TRANSFER_FREE_BUFFER makes libusb release the owned buffer.
         libusb_free_transfer(xfer);
 }
@@ -37,6 +37,13 @@ type usbTransfer struct {
 	submitted bool
 	// ctx is the Context that created this transfer.
 	ctx *Context
+	// key identifies the transfer in the Context transfer pool. Transfers
+	// with a nil key are not pooled.
+	key *transferKey
+	// isoRaw is true if the data of an isochronous transfer is left as is,
+	// with the per-packet results stored in isoResults by wait().
+	isoRaw     bool
+	isoResults []isoPacketResult
 }
 
 // submits the transfer. After submit() the transfer is in flight and is owned by libusb.
@@ -75,6 +82,17 @@ func (t *usbTransfer) wait(ctx context.Context) (n int, err error) {
 	case <-t.done:
 	}
 	t.submitted = false
+	if t.isoRaw {
+		var status TransferStatus
+		t.isoResults, status = t.ctx.libusb.isoPackets(t.xfer)
+		for _, p := range t.isoResults {
+			n += p.actual
+		}
+		if status != TransferCompleted {
+			return n, status
+		}
+		return n, nil
+	}
 	n, status := t.ctx.libusb.data(t.xfer)
 	if status != TransferCompleted {
 		return n, status
@@ -110,7 +128,11 @@ func (t *usbTransfer) free() error {
 	if t.xfer == nil {
 		return nil
 	}
-	t.ctx.libusb.free(t.xfer)
+	if t.key != nil {
+		t.ctx.pool.put(*t.key, pooledTransfer{xfer: t.xfer, buf: t.buf, done: t.done})
+	} else {
+		t.ctx.libusb.free(t.xfer)
+	}
 	t.xfer = nil
 	t.buf = nil
 	t.done = nil
@@ -123,8 +145,20 @@ func (t *usbTransfer) data() []byte {
 }
 
 // newUSBTransfer allocates a new transfer structure and a new buffer for
-// communication with a given device/endpoint.
-func newUSBTransfer(ctx *Context, dev *libusbDevHandle, ei *EndpointDesc, bufLen int) (*usbTransfer, error) {
+// communication with a given device/endpoint, using the given transfer flags.
+func newUSBTransfer(ctx *Context, dev *libusbDevHandle, ei *EndpointDesc, flags transferFlags, bufLen int) (*usbTransfer, error) {
+	return allocUSBTransfer(ctx, dev, ei, flags, bufLen, nil)
+}
+
+// newUSBTransferWithBuffer allocates a new transfer structure that uses
+// buf for data, instead of allocating a new buffer. buf must be a buffer
+// obtained from Device.AllocTransferBuffer and needs to remain valid
+// until the transfer is freed.
+func newUSBTransferWithBuffer(ctx *Context, dev *libusbDevHandle, ei *EndpointDesc, flags transferFlags, buf []byte) (*usbTransfer, error) {
+	return allocUSBTransfer(ctx, dev, ei, flags, len(buf), buf)
+}
+
+func allocUSBTransfer(ctx *Context, dev *libusbDevHandle, ei *EndpointDesc, flags transferFlags, bufLen int, buf []byte) (*usbTransfer, error) {
 	var isoPackets, isoPktSize int
 	if ei.TransferType == TransferTypeIsochronous {
 		isoPktSize = ei.MaxPacketSize
@@ -139,22 +173,57 @@ func newUSBTransfer(ctx *Context, dev *libusbDevHandle, ei *EndpointDesc, bufLen
 		debug.Printf("New isochronous transfer - buffer length %d, using %d packets of %d bytes each", bufLen, isoPackets, isoPktSize)
 	}
 
-	done := make(chan struct{}, 1)
-	xfer, err := ctx.libusb.alloc(dev, ei, isoPackets, bufLen, done)
-	if err != nil {
-		return nil, err
+	var t *usbTransfer
+	if buf != nil {
+		// Transfers using caller-provided buffers are not pooled.
+		done := make(chan struct{}, 1)
+		xfer, err := ctx.libusb.allocWithBuffer(dev, ei, flags, isoPackets, buf, done)
+		if err != nil {
+			return nil, err
+		}
+		t = &usbTransfer{
+			xfer: xfer,
+			buf:  ctx.libusb.buffer(xfer),
+			done: done,
+			ctx:  ctx,
+		}
+	} else {
+		key := &transferKey{
+			h:      dev,
+			addr:   ei.Address,
+			tt:     ei.TransferType,
+			maxPkt: ei.MaxPacketSize,
+			flags:  flags,
+			bufLen: bufLen,
+		}
+		if pt, ok := ctx.pool.get(*key); ok {
+			t = &usbTransfer{
+				xfer: pt.xfer,
+				buf:  pt.buf,
+				done: pt.done,
+				ctx:  ctx,
+				key:  key,
+			}
+		} else {
+			done := make(chan struct{}, 1)
+			xfer, err := ctx.libusb.alloc(dev, ei, flags, isoPackets, bufLen, done)
+			if err != nil {
+				return nil, err
+			}
+			t = &usbTransfer{
+				xfer: xfer,
+				buf:  ctx.libusb.buffer(xfer),
+				done: done,
+				ctx:  ctx,
+				key:  key,
+			}
+		}
 	}
 
 	if ei.TransferType == TransferTypeIsochronous {
-		ctx.libusb.setIsoPacketLengths(xfer, uint32(isoPktSize))
+		ctx.libusb.setIsoPacketLengths(t.xfer, uint32(isoPktSize))
 	}
 
-	t := &usbTransfer{
-		xfer: xfer,
-		buf:  ctx.libusb.buffer(xfer),
-		done: done,
-		ctx:  ctx,
-	}
 	runtime.SetFinalizer(t, func(t *usbTransfer) {
 		t.cancel()
 		t.wait(context.Background())
@@ -0,0 +1,191 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "sync"
+
+// DefaultTransferPoolSize is the default limit of memory held by the
+// idle transfers in the transfer pool of a Context.
+const DefaultTransferPoolSize = 4 << 20
+
+// TransferPoolStats describes the state of the transfer pool of a Context.
+type TransferPoolStats struct {
+	// Gets is the number of transfers requested from the pool.
+	Gets uint64
+	// Hits is the number of requests served with a pooled transfer,
+	// without allocating a new one.
+	Hits uint64
+	// Puts is the number of transfers returned to the pool for reuse.
+	Puts uint64
+	// Evictions is the number of transfers released instead of being
+	// returned to the pool, because the pool was full.
+	Evictions uint64
+	// Idle is the number of transfers currently held by the pool.
+	Idle int
+	// IdleBytes is the total size of buffers of the transfers currently
+	// held by the pool.
+	IdleBytes int
+	// MaxIdleBytes is the limit of IdleBytes.
+	MaxIdleBytes int
+}
+
+// transferKey identifies transfers that are interchangeable, i.e. that
+// were allocated for the same device, endpoint and buffer size.
+type transferKey struct {
+	h      *libusbDevHandle
+	addr   EndpointAddress
+	tt     TransferType
+	maxPkt int
+	flags  transferFlags
+	bufLen int
+}
+
+type pooledTransfer struct {
+	xfer *libusbTransfer
+	buf  []byte
+	done chan struct{}
+}
+
+// transferPool keeps libusb transfers and their buffers after they are
+// freed, so that subsequent transfers on the same endpoint, like
+// repeated Reads or a new stream, don't need to allocate them again.
+type transferPool struct {
+	libusb libusbIntf
+
+	mu    sync.Mutex
+	idle  map[transferKey][]pooledTransfer
+	stats TransferPoolStats
+	// closed is set when the Context is closed, transfers freed after
+	// that are released instead of being pooled.
+	closed bool
+}
+
+func newTransferPool(impl libusbIntf) *transferPool {
+	return &transferPool{
+		libusb: impl,
+		idle:   make(map[transferKey][]pooledTransfer),
+		stats:  TransferPoolStats{MaxIdleBytes: DefaultTransferPoolSize},
+	}
+}
+
+// get returns an idle transfer matching the key, if the pool has one.
+func (p *transferPool) get(k transferKey) (pooledTransfer, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Gets++
+	ts := p.idle[k]
+	if len(ts) == 0 {
+		return pooledTransfer{}, false
+	}
+	t := ts[len(ts)-1]
+	if len(ts) == 1 {
+		delete(p.idle, k)
+	} else {
+		p.idle[k] = ts[:len(ts)-1]
+	}
+	p.stats.Hits++
+	p.stats.Idle--
+	p.stats.IdleBytes -= len(t.buf)
+	return t, true
+}
+
+// put returns the transfer to the pool, or releases it if the pool is full
+// or closed. The pool lock is shared by all transfers of the Context,
+// transfers are released after unlocking it.
+func (p *transferPool) put(k transferKey, t pooledTransfer) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.libusb.free(t.xfer)
+		return
+	}
+	if p.stats.IdleBytes+len(t.buf) > p.stats.MaxIdleBytes {
+		p.stats.Evictions++
+		p.mu.Unlock()
+		p.libusb.free(t.xfer)
+		return
+	}
+	p.stats.Puts++
+	p.stats.Idle++
+	p.stats.IdleBytes += len(t.buf)
+	p.idle[k] = append(p.idle[k], t)
+	p.mu.Unlock()
+}
+
+// release frees the pooled transfers for which drop returns true.
+func (p *transferPool) release(drop func(transferKey) bool) {
+	var freed []*libusbTransfer
+	p.mu.Lock()
+	for k, ts := range p.idle {
+		if !drop(k) {
+			continue
+		}
+		for _, t := range ts {
+			p.stats.Idle--
+			p.stats.IdleBytes -= len(t.buf)
+			freed = append(freed, t.xfer)
+		}
+		delete(p.idle, k)
+	}
+	p.mu.Unlock()
+	for _, x := range freed {
+		p.libusb.free(x)
+	}
+}
+
+// releaseHandle frees all pooled transfers of the device handle.
+func (p *transferPool) releaseHandle(h *libusbDevHandle) {
+	p.release(func(k transferKey) bool { return k.h == h })
+}
+
+// close frees all pooled transfers, and the transfers put back later.
+func (p *transferPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.release(func(transferKey) bool { return true })
+}
+
+func (p *transferPool) setMaxIdleBytes(n int) {
+	p.mu.Lock()
+	p.stats.MaxIdleBytes = n
+	p.mu.Unlock()
+	// Trim the pool to the new limit.
+	p.release(func(transferKey) bool {
+		return p.stats.IdleBytes > p.stats.MaxIdleBytes
+	})
+}
+
+func (p *transferPool) getStats() TransferPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// TransferPoolStats returns the statistics of the transfer pool of the
+// Context. Transfers used by Read and Write calls on endpoints and by read
+// and write streams are reused through the pool, avoiding repeated
+// allocation of transfer buffers for sustained traffic. The statistics
+// can be used to tune the pool size with SetTransferPoolSize.
+func (c *Context) TransferPoolStats() TransferPoolStats {
+	return c.pool.getStats()
+}
+
+// SetTransferPoolSize sets the limit of memory held by idle transfers in
+// the transfer pool of the Context. A limit of 0 disables the pooling.
+// The default is DefaultTransferPoolSize.
+func (c *Context) SetTransferPoolSize(maxBytes int) {
+	c.pool.setMaxIdleBytes(maxBytes)
+}
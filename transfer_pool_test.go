@@ -0,0 +1,115 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import "testing"
+
+func TestTransferPool(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	go func() {
+		for {
+			ft := lib.waitForSubmitted(nil)
+			if ft == nil {
+				return
+			}
+			ft.setData(make([]byte, 10))
+			ft.setStatus(TransferCompleted)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	buf := make([]byte, 512)
+	for i := 0; i < 3; i++ {
+		if _, err := ep.Read(buf); err != nil {
+			t.Fatalf("%s.Read(): %v", ep, err)
+		}
+	}
+	// a different buffer size can't reuse the pooled transfer.
+	if _, err := ep.Read(buf[:100]); err != nil {
+		t.Fatalf("%s.Read(): %v", ep, err)
+	}
+	got := ctx.TransferPoolStats()
+	want := TransferPoolStats{
+		Gets:         4,
+		Hits:         2,
+		Puts:         4,
+		Idle:         2,
+		IdleBytes:    612,
+		MaxIdleBytes: DefaultTransferPoolSize,
+	}
+	if got != want {
+		t.Errorf("TransferPoolStats(): got %+v, want %+v", got, want)
+	}
+
+	ctx.SetTransferPoolSize(0)
+	if _, err := ep.Read(buf); err != nil {
+		t.Fatalf("%s.Read(): %v", ep, err)
+	}
+	got = ctx.TransferPoolStats()
+	if got.Idle != 0 || got.IdleBytes != 0 {
+		t.Errorf("TransferPoolStats() after SetTransferPoolSize(0): got %d idle transfers with %d bytes, want none", got.Idle, got.IdleBytes)
+	}
+	if got.Evictions != 1 {
+		t.Errorf("TransferPoolStats() after SetTransferPoolSize(0): got %d evictions, want 1", got.Evictions)
+	}
+
+	ctx.SetTransferPoolSize(DefaultTransferPoolSize)
+	if _, err := ep.Read(buf); err != nil {
+		t.Fatalf("%s.Read(): %v", ep, err)
+	}
+	done()
+	if err := dev.Close(); err != nil {
+		t.Fatalf("%s.Close(): %v", dev, err)
+	}
+	if got := ctx.TransferPoolStats().Idle; got != 0 {
+		t.Errorf("TransferPoolStats() after device Close: got %d idle transfers, want 0", got)
+	}
+}
+
+func TestTransferPoolClosed(t *testing.T) {
+	lib := newFakeLibusb()
+	p := newTransferPool(lib)
+	xfer := new(libusbTransfer)
+	lib.ts[xfer] = &fakeTransfer{}
+
+	p.close()
+	p.put(transferKey{bufLen: 10}, pooledTransfer{xfer: xfer, buf: make([]byte, 10)})
+	if got := p.getStats(); got.Idle != 0 || got.IdleBytes != 0 {
+		t.Errorf("getStats() after put on a closed pool: got %d idle transfers with %d bytes, want none", got.Idle, got.IdleBytes)
+	}
+	if _, ok := lib.ts[xfer]; ok {
+		t.Error("put() on a closed pool didn't free the transfer")
+	}
+}
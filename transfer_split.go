@@ -0,0 +1,101 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// usbfsMemoryMB is the parameter of the usbcore module that limits
+// the memory used by all usbfs transfers in flight on Linux, in megabytes.
+// Tests replace it with a temporary file.
+var usbfsMemoryMB = "/sys/module/usbcore/parameters/usbfs_memory_mb"
+
+const (
+	// linuxMaxTransferSize is the largest transfer submitted on Linux.
+	linuxMaxTransferSize = 1 << 20
+	// usbfsTransferShare is the fraction of usbfs_memory_mb a single
+	// transfer may use, leaving room for the other transfers in flight,
+	// e.g. of streams or of other devices and programs.
+	usbfsTransferShare = 16
+)
+
+// platformMaxTransferSize returns the size of the largest transfer that
+// should be submitted at once on goos, or 0 if it's not limited.
+func platformMaxTransferSize(goos string) int {
+	if goos != "linux" {
+		return 0
+	}
+	limit := linuxMaxTransferSize
+	if b, err := ioutil.ReadFile(usbfsMemoryMB); err == nil {
+		// Zero means that usbfs doesn't limit the memory.
+		if mb, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil && mb > 0 && mb<<20/usbfsTransferShare < limit {
+			limit = mb << 20 / usbfsTransferShare
+		}
+	}
+	return limit
+}
+
+// transferLimit returns the maximum size of a single transfer on
+// the endpoint, rounded down to a multiple of the packet size, or 0 if
+// transfers are not split.
+func (e *endpoint) transferLimit() int {
+	if e.Desc.TransferType == TransferTypeIsochronous {
+		return 0
+	}
+	limit := e.MaxTransferSize()
+	if mps := e.Desc.MaxPacketSize; mps > 0 && limit > mps {
+		limit -= limit % mps
+	}
+	return limit
+}
+
+// bufsLen returns the total length of bufs.
+func bufsLen(bufs [][]byte) int {
+	n := 0
+	for _, b := range bufs {
+		n += len(b)
+	}
+	return n
+}
+
+// splitBufs splits the concatenation of bufs into chunks of at most limit
+// bytes, without copying the data.
+func splitBufs(bufs [][]byte, limit int) [][][]byte {
+	var chunks [][][]byte
+	var cur [][]byte
+	curLen := 0
+	for _, b := range bufs {
+		for len(b) > 0 {
+			n := limit - curLen
+			if n > len(b) {
+				n = len(b)
+			}
+			cur = append(cur, b[:n])
+			curLen += n
+			b = b[n:]
+			if curLen == limit {
+				chunks = append(chunks, cur)
+				cur, curLen = nil, 0
+			}
+		}
+	}
+	if curLen > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitBufs(t *testing.T) {
+	for _, tc := range []struct {
+		bufs  [][]byte
+		limit int
+		want  [][][]byte
+	}{
+		{
+			bufs:  [][]byte{{1, 2, 3, 4, 5}},
+			limit: 2,
+			want:  [][][]byte{{{1, 2}}, {{3, 4}}, {{5}}},
+		},
+		{
+			bufs:  [][]byte{{1}, {2, 3, 4}, nil, {5, 6}},
+			limit: 3,
+			want:  [][][]byte{{{1}, {2, 3}}, {{4}, {5, 6}}},
+		},
+		{
+			bufs:  [][]byte{{1, 2}, {3, 4}},
+			limit: 4,
+			want:  [][][]byte{{{1, 2}, {3, 4}}},
+		},
+	} {
+		if got := splitBufs(tc.bufs, tc.limit); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitBufs(%v, %d): got %v, want %v", tc.bufs, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestPlatformMaxTransferSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gousb-usbcore")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	old := usbfsMemoryMB
+	usbfsMemoryMB = filepath.Join(dir, "usbfs_memory_mb")
+	defer func() { usbfsMemoryMB = old }()
+
+	if got, want := platformMaxTransferSize("linux"), 1<<20; got != want {
+		t.Errorf("platformMaxTransferSize(linux) without usbfs_memory_mb: got %d, want %d", got, want)
+	}
+	for _, tc := range []struct {
+		param string
+		want  int
+	}{
+		{"16\n", 1 << 20},
+		{"64\n", 1 << 20},
+		{"4\n", 256 << 10},
+		{"0\n", 1 << 20},
+	} {
+		if err := ioutil.WriteFile(usbfsMemoryMB, []byte(tc.param), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if got := platformMaxTransferSize("linux"); got != tc.want {
+			t.Errorf("platformMaxTransferSize(linux) with usbfs_memory_mb %q: got %d, want %d", tc.param, got, tc.want)
+		}
+	}
+	if got := platformMaxTransferSize("darwin"); got != 0 {
+		t.Errorf("platformMaxTransferSize(darwin): got %d, want 0", got)
+	}
+}
+
+func TestEndpointSplitTransfers(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev.Close()
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	lib.mu.Lock()
+	calls := lib.maxTransferCalls
+	lib.mu.Unlock()
+	if calls != 1 {
+		t.Errorf("maxTransferSize called %d times for 2 endpoints, want 1", calls)
+	}
+
+	// The limit is rounded down to a multiple of the 512 byte packets.
+	// Only the last transfer is terminated with a ZLP.
+	out.SetMaxTransferSize(700)
+	out.SetZeroPacket(true)
+	type xfer struct {
+		length int
+		flags  transferFlags
+	}
+	got := make(chan []xfer, 1)
+	go func() {
+		var xs []xfer
+		for i := 0; i < 3; i++ {
+			ft := lib.waitForSubmitted(nil)
+			xs = append(xs, xfer{len(ft.buf), ft.flags})
+			ft.setLength(len(ft.buf))
+			ft.setStatus(TransferCompleted)
+		}
+		got <- xs
+	}()
+	if n, err := out.Write(make([]byte, 1100)); n != 1100 || err != nil {
+		t.Errorf("%s.Write(1100 bytes): got %d, %v, want 1100, nil", out, n, err)
+	}
+	want := []xfer{{512, 0}, {512, 0}, {76, transferAddZeroPacket}}
+	if xs := <-got; !reflect.DeepEqual(xs, want) {
+		t.Errorf("%s.Write(1100 bytes): got transfers %v, want %v", out, xs, want)
+	}
+
+	// A short transfer ends the read.
+	in.SetMaxTransferSize(512)
+	go func() {
+		for _, n := range []int{512, 100} {
+			ft := lib.waitForSubmitted(nil)
+			ft.setData(make([]byte, n))
+			ft.setStatus(TransferCompleted)
+		}
+	}()
+	if n, err := in.Read(make([]byte, 4096)); n != 612 || err != nil {
+		t.Errorf("%s.Read(4096 bytes): got %d, %v, want 612, nil", in, n, err)
+	}
+	if !lib.empty() {
+		t.Errorf("%s.Read(4096 bytes): transfers still pending after a short transfer", in)
+	}
+}
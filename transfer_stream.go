@@ -17,6 +17,8 @@ package gousb
 import (
 	"context"
 	"io"
+	"sync"
+	"time"
 )
 
 type transferIntf interface {
@@ -34,6 +36,114 @@ type stream struct {
 	err error
 	// finished is true if transfers has been already closed.
 	finished bool
+	// unsubmitted is the number of transfers at the head of the queue
+	// that were never submitted, only used by write streams.
+	unsubmitted int
+	// disconnect, if set, is the disconnect state of the device, updated
+	// with the errors of the transfers.
+	disconnect *disconnectState
+
+	// statsMu protects the statistics below, which can be read
+	// concurrently with the stream operations.
+	statsMu  sync.Mutex
+	stats    StreamStats
+	start    time.Time
+	inFlight int
+}
+
+// StreamStats are the statistics of a ReadStream or WriteStream,
+// allowing to detect underruns and overruns.
+type StreamStats struct {
+	// Bytes is the number of bytes transferred to or from the device
+	// in completed transfers.
+	Bytes int64
+	// Transfers is the number of completed transfers.
+	Transfers int64
+	// ShortTransfers is the number of completed transfers that moved less
+	// data than the transfer buffer size, e.g. reads terminated by a short
+	// packet.
+	ShortTransfers int64
+	// Errors is the number of transfers that failed or couldn't be
+	// resubmitted.
+	Errors int64
+	// Elapsed is the time since the stream was created.
+	Elapsed time.Duration
+	// QueueDepth is the number of transfers in flight.
+	QueueDepth int
+	// MinQueueDepth is the lowest number of transfers left in flight
+	// observed when a transfer completed. For a read stream, 0 means that
+	// at some point the device had no transfer to send data with, i.e. data
+	// might have been lost. For a write stream, 0 means the device was idle
+	// waiting for data.
+	MinQueueDepth int
+}
+
+// Throughput returns the average number of bytes transferred per second.
+func (s StreamStats) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Elapsed.Seconds()
+}
+
+func (s *stream) submitted() {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.inFlight++
+}
+
+// completed records the result of a transfer that was submitted.
+func (s *stream) completed(t transferIntf, n int, err error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.inFlight--
+	if err != nil {
+		s.stats.Errors++
+		return
+	}
+	s.stats.Bytes += int64(n)
+	s.stats.Transfers++
+	if n < len(t.data()) {
+		s.stats.ShortTransfers++
+	}
+	if s.stats.Transfers == 1 || s.inFlight < s.stats.MinQueueDepth {
+		s.stats.MinQueueDepth = s.inFlight
+	}
+}
+
+func (s *stream) submitFailed(err error) {
+	s.checkDisconnect(err)
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats.Errors++
+}
+
+func (s *stream) getStats() StreamStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	st := s.stats
+	st.Elapsed = time.Since(s.start)
+	st.QueueDepth = s.inFlight
+	return st
+}
+
+// wait waits for the next transfer taken from the queue. Transfers that
+// were never submitted return immediately.
+func (s *stream) wait(ctx context.Context, t transferIntf) (int, error) {
+	if s.unsubmitted > 0 {
+		s.unsubmitted--
+		return t.wait(ctx)
+	}
+	n, err := t.wait(ctx)
+	s.checkDisconnect(err)
+	s.completed(t, n, err)
+	return n, err
+}
+
+func (s *stream) checkDisconnect(err error) {
+	if s.disconnect != nil {
+		s.disconnect.check(err)
+	}
 }
 
 func (s *stream) gotError(err error) {
@@ -57,11 +167,13 @@ func (s *stream) submitAll() {
 	}
 	for _, t := range all {
 		if err := t.submit(); err != nil {
+			s.submitFailed(err)
 			t.free()
 			s.gotError(err)
 			s.noMore()
 			return
 		}
+		s.submitted()
 		s.transfers <- t
 	}
 	return
@@ -74,6 +186,9 @@ func (s *stream) flushRemaining() {
 		t.wait(context.Background())
 		t.free()
 	}
+	s.statsMu.Lock()
+	s.inFlight = 0
+	s.statsMu.Unlock()
 }
 
 func (s *stream) done() {
@@ -129,7 +244,7 @@ func (r *ReadStream) ReadContext(ctx context.Context, p []byte) (int, error) {
 			r.s.transfers = nil
 			return 0, r.s.err
 		}
-		n, err := t.wait(ctx)
+		n, err := r.s.wait(ctx, t)
 		if err != nil {
 			// wait error aborts immediately, all remaining data is invalid.
 			t.free()
@@ -150,9 +265,11 @@ func (r *ReadStream) ReadContext(ctx context.Context, p []byte) (int, error) {
 	if r.used == r.total {
 		if r.s.err == nil {
 			if err := r.current.submit(); err == nil {
+				r.s.submitted()
 				// guaranteed to not block, len(transfers) == number of allocated transfers
 				r.s.transfers <- r.current
 			} else {
+				r.s.submitFailed(err)
 				r.s.gotError(err)
 				r.s.noMore()
 			}
@@ -227,7 +344,7 @@ func (w *WriteStream) WriteContext(ctx context.Context, p []byte) (int, error) {
 	all := len(p)
 	for written < all {
 		t := <-w.s.transfers
-		n, err := t.wait(ctx) // unsubmitted transfers will return 0 bytes and no error
+		n, err := w.s.wait(ctx, t) // unsubmitted transfers will return 0 bytes and no error
 		w.total += n
 		if err != nil {
 			t.free()
@@ -244,6 +361,7 @@ func (w *WriteStream) WriteContext(ctx context.Context, p []byte) (int, error) {
 		}
 		copy(t.data(), p[written:written+use])
 		if err := t.submit(); err != nil {
+			w.s.submitFailed(err)
 			t.free()
 			w.s.gotError(err)
 			// Even though this submit failed, all the transfers in flight are still valid.
@@ -252,15 +370,56 @@ func (w *WriteStream) WriteContext(ctx context.Context, p []byte) (int, error) {
 			w.s.noMore()
 			return written, err
 		}
+		w.s.submitted()
 		written += use
 		w.s.transfers <- t // guaranteed non blocking
 	}
 	return written, nil
 }
 
+// Flush blocks until all data written so far was sent to the device,
+// like Drain with a background context.
+func (w *WriteStream) Flush() error {
+	return w.Drain(context.Background())
+}
+
+// Drain blocks until all transfers queued by previous writes are finished,
+// without ending the stream. Drain returns the first error encountered by
+// the stream, including errors of transfers that failed after the last
+// Write returned. After an error, the stream can only be closed.
+// If ctx is done before the transfers finish, the pending transfers are
+// cancelled and the stream fails with TransferCancelled.
+// Drain cannot be called concurrently with Write, WriteContext, Close
+// or CloseContext.
+func (w *WriteStream) Drain(ctx context.Context) error {
+	if w.s.transfers == nil {
+		return io.ErrClosedPipe
+	}
+	if w.s.err != nil {
+		return w.s.err
+	}
+	count := len(w.s.transfers)
+	for i := 0; i < count; i++ {
+		t := <-w.s.transfers
+		n, err := w.s.wait(ctx, t)
+		w.total += n
+		if err != nil {
+			t.free()
+			w.s.gotError(err)
+			w.s.flushRemaining()
+			return err
+		}
+		w.s.transfers <- t // guaranteed non blocking
+	}
+	// All transfers in the queue are now idle.
+	w.s.unsubmitted = count
+	return nil
+}
+
 // Close signals end of data to write. Close blocks until all transfers
 // that were sent are finished. The error returned by Close is the first
-// error encountered during writing the entire stream (if any).
+// error encountered during writing the entire stream (if any), including
+// errors of transfers that failed after the last Write returned.
 // Close returning nil indicates all transfers completed successfully.
 // After Close, the total number of bytes successfully written can be
 // retrieved using Written().
@@ -283,7 +442,7 @@ func (w *WriteStream) CloseContext(ctx context.Context) error {
 	}
 	w.s.noMore()
 	for t := range w.s.transfers {
-		n, err := t.wait(ctx)
+		n, err := w.s.wait(ctx, t)
 		w.total += n
 		t.free()
 		if err != nil {
@@ -303,9 +462,22 @@ func (w *WriteStream) Written() int {
 	return w.total
 }
 
+// Stats returns the statistics of the stream. Stats can be called
+// concurrently with other methods of the stream.
+func (r *ReadStream) Stats() StreamStats {
+	return r.s.getStats()
+}
+
+// Stats returns the statistics of the stream. Stats can be called
+// concurrently with other methods of the stream.
+func (w *WriteStream) Stats() StreamStats {
+	return w.s.getStats()
+}
+
 func newStream(tt []transferIntf) *stream {
 	s := &stream{
 		transfers: make(chan transferIntf, len(tt)),
+		start:     time.Now(),
 	}
 	for _, t := range tt {
 		s.transfers <- t
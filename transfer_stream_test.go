@@ -355,3 +355,62 @@ func TestTransferWriteStream(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStreamDrain(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		transfers [][]fakeStreamResult
+		drainErr  error
+		total     int
+	}{
+		{
+			desc: "all transfers completed",
+			transfers: [][]fakeStreamResult{
+				{{n: 1500}, {n: 1500}},
+				{{n: 1500}, {n: 1500}},
+			},
+			total: 6000,
+		},
+		{
+			desc: "transfer failed after the write",
+			transfers: [][]fakeStreamResult{
+				{{n: 1500}},
+				{{waitErr: errSentinel}},
+			},
+			drainErr: errSentinel,
+			total:    1500,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			tt := make([]transferIntf, len(tc.transfers))
+			for i := range tc.transfers {
+				tt[i] = &fakeStreamTransfer{res: tc.transfers[i]}
+			}
+			s := WriteStream{s: newStream(tt)}
+			s.s.unsubmitted = len(tt)
+			if _, err := s.Write(make([]byte, 3000)); err != nil {
+				t.Fatalf("WriteStream.Write: %v", err)
+			}
+			if err := s.Flush(); err != tc.drainErr {
+				t.Fatalf("WriteStream.Flush: got %v, want %v", err, tc.drainErr)
+			}
+			if tc.drainErr == nil {
+				if got := s.Stats().QueueDepth; got != 0 {
+					t.Errorf("WriteStream.Stats().QueueDepth after Flush: got %d, want 0", got)
+				}
+				// The stream is still usable after a successful flush.
+				if _, err := s.Write(make([]byte, 3000)); err != nil {
+					t.Fatalf("WriteStream.Write after Flush: %v", err)
+				}
+			}
+			if err := s.Close(); err != tc.drainErr {
+				t.Errorf("WriteStream.Close: got %v, want %v", err, tc.drainErr)
+			}
+			if got := s.Written(); got != tc.total {
+				t.Errorf("WriteStream.Written: got %d, want %d", got, tc.total)
+			}
+		})
+	}
+}
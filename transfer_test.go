@@ -68,7 +68,7 @@ func TestNewTransfer(t *testing.T) {
 			Direction:     tc.dir,
 			TransferType:  tc.tt,
 			MaxPacketSize: tc.maxPkt,
-		}, tc.buf)
+		}, 0, tc.buf)
 
 		if err != nil {
 			t.Fatalf("newUSBTransfer(): %v", err)
@@ -98,7 +98,7 @@ func TestTransferProtocol(t *testing.T) {
 			Direction:     EndpointDirectionIn,
 			TransferType:  TransferTypeBulk,
 			MaxPacketSize: 512,
-		}, 10240)
+		}, 0, 10240)
 		if err != nil {
 			t.Fatalf("newUSBTransfer: %v", err)
 		}
@@ -0,0 +1,145 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"sort"
+)
+
+// DeviceNode is a node of the bus topology returned by DeviceTree.
+type DeviceNode struct {
+	// Desc is the descriptor of the device attached at the node. It's nil
+	// for hubs that weren't enumerated, e.g. root hubs on platforms that
+	// don't list them.
+	Desc *DeviceDesc
+	// Bus is the bus number and Path the port numbers from the root hub
+	// to the device, see DeviceDesc.Path. Path is empty for the root hub
+	// and for devices whose position isn't known.
+	Bus  int
+	Path []int
+	// Children are the nodes attached to the ports of the hub, sorted by
+	// port number.
+	Children []*DeviceNode
+}
+
+// Port returns the port of the parent hub the node is attached to, or 0
+// for a root hub or a device whose position isn't known.
+func (n *DeviceNode) Port() int {
+	if len(n.Path) == 0 {
+		return 0
+	}
+	return n.Path[len(n.Path)-1]
+}
+
+// Walk calls fn for n and all nodes below it, parents before their
+// children. depth is 0 for n, 1 for its children and so on.
+func (n *DeviceNode) Walk(fn func(n *DeviceNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *DeviceNode) walk(fn func(n *DeviceNode, depth int), depth int) {
+	fn(n, depth)
+	for _, c := range n.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// DeviceTree returns the topology of the buses, as a tree per bus rooted
+// at its root hub, sorted by bus number. Devices are placed by their
+// port path, hubs that weren't enumerated are added without a descriptor
+// to keep the tree connected. Devices whose position isn't known, e.g.
+// on platforms that don't report port numbers, are children of the root
+// of their bus, after the devices attached to its ports.
+//
+// If there were errors reading the descriptors of some devices, the tree
+// of the other devices is returned with the last error.
+func (c *Context) DeviceTree() ([]*DeviceNode, error) {
+	if c.ctx == nil {
+		return nil, errors.New("DeviceTree called on a closed or uninitialized Context")
+	}
+	list, err := c.libusb.getDevices(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reterr error
+	var descs []*DeviceDesc
+	for _, dev := range list {
+		desc, err := c.libusb.getDeviceDesc(dev)
+		c.libusb.dereference(dev)
+		if err != nil {
+			reterr = err
+			continue
+		}
+		descs = append(descs, desc)
+	}
+	return buildDeviceTree(descs), reterr
+}
+
+// buildDeviceTree arranges descs into the trees returned by DeviceTree.
+func buildDeviceTree(descs []*DeviceDesc) []*DeviceNode {
+	roots := make(map[int]*DeviceNode)
+	nodes := make(map[string]*DeviceNode)
+	// node returns the node at the path, adding it and its missing
+	// parents.
+	var node func(bus int, path []int) *DeviceNode
+	node = func(bus int, path []int) *DeviceNode {
+		if len(path) == 0 {
+			if roots[bus] == nil {
+				roots[bus] = &DeviceNode{Bus: bus}
+			}
+			return roots[bus]
+		}
+		key := (&DeviceDesc{Bus: bus, Path: path}).portPath()
+		if n := nodes[key]; n != nil {
+			return n
+		}
+		parent := node(bus, path[:len(path)-1])
+		n := &DeviceNode{Bus: bus, Path: append([]int(nil), path...)}
+		parent.Children = append(parent.Children, n)
+		nodes[key] = n
+		return n
+	}
+
+	var unplaced []*DeviceDesc
+	for _, desc := range descs {
+		if len(desc.Path) > 0 {
+			node(desc.Bus, desc.Path).Desc = desc
+			continue
+		}
+		if root := node(desc.Bus, nil); desc.Class == ClassHub && root.Desc == nil {
+			root.Desc = desc
+			continue
+		}
+		unplaced = append(unplaced, desc)
+	}
+	for _, desc := range unplaced {
+		root := node(desc.Bus, nil)
+		root.Children = append(root.Children, &DeviceNode{Desc: desc, Bus: desc.Bus})
+	}
+
+	ret := make([]*DeviceNode, 0, len(roots))
+	for _, r := range roots {
+		ret = append(ret, r)
+		r.Walk(func(n *DeviceNode, _ int) {
+			sort.SliceStable(n.Children, func(i, j int) bool {
+				pi, pj := n.Children[i].Port(), n.Children[j].Port()
+				return pi != 0 && (pj == 0 || pi < pj)
+			})
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Bus < ret[j].Bus })
+	return ret
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// treeString renders the trees one node per line, indented by depth.
+func treeString(roots []*DeviceNode) string {
+	var b strings.Builder
+	for _, r := range roots {
+		r.Walk(func(n *DeviceNode, depth int) {
+			name := "?"
+			if n.Desc != nil {
+				name = fmt.Sprintf("%s:%s", n.Desc.Vendor, n.Desc.Product)
+			}
+			fmt.Fprintf(&b, "%s%d/%d %s\n", strings.Repeat("  ", depth), n.Bus, n.Port(), name)
+		})
+	}
+	return b.String()
+}
+
+func TestBuildDeviceTree(t *testing.T) {
+	descs := []*DeviceDesc{
+		{Bus: 2, Path: []int{3, 1}, Vendor: 0x1234, Product: 0x0003},
+		{Bus: 1, Path: []int{2}, Vendor: 0x1234, Product: 0x0002},
+		{Bus: 2, Path: []int{1}, Vendor: 0x1234, Product: 0x0001},
+		{Bus: 1, Vendor: 0x1234, Product: 0x00ff},
+		{Bus: 1, Class: ClassHub, Vendor: 0x1d6b, Product: 0x0002},
+		{Bus: 2, Path: []int{3, 4, 2}, Vendor: 0x1234, Product: 0x0004},
+		{Bus: 2, Path: []int{3}, Class: ClassHub, Vendor: 0x05e3, Product: 0x0608},
+	}
+	want := strings.Join([]string{
+		"1/0 1d6b:0002",
+		"  1/2 1234:0002",
+		"  1/0 1234:00ff",
+		"2/0 ?",
+		"  2/1 1234:0001",
+		"  2/3 05e3:0608",
+		"    2/1 1234:0003",
+		"    2/4 ?",
+		"      2/2 1234:0004",
+	}, "\n") + "\n"
+	if got := treeString(buildDeviceTree(descs)); got != want {
+		t.Errorf("buildDeviceTree():\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDeviceTree(t *testing.T) {
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	roots, err := ctx.DeviceTree()
+	if err != nil {
+		t.Fatalf("DeviceTree(): %v", err)
+	}
+	if len(roots) != 1 || roots[0].Desc != nil {
+		t.Fatalf("DeviceTree(): got\n%s\nwant a single root hub without descriptor", treeString(roots))
+	}
+	var ports []int
+	for _, n := range roots[0].Children {
+		ports = append(ports, n.Port())
+	}
+	if fmt.Sprint(ports) != "[1 2 3]" {
+		t.Errorf("DeviceTree(): got devices at ports %v of the root hub, want [1 2 3]", ports)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package udev generates udev rules that grant non-root users access
+// to USB devices on Linux.
+//
+// Typical use in an application installer:
+//
+//	r := udev.Rule{Vendor: 0x1d50, Product: 0x6089, Comment: "my gadget"}
+//	if err := udev.Install(udev.DefaultDir, "70-mygadget.rules", r); err != nil { ... }
+//	if err := udev.Reload(); err != nil { ... }
+package udev
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/gousb"
+)
+
+// DefaultDir is the directory for local udev rules.
+const DefaultDir = "/etc/udev/rules.d"
+
+// Rule describes the devices matched by a udev rule and the access
+// granted to them.
+type Rule struct {
+	// Vendor and Product are the IDs of the matched devices.
+	Vendor, Product gousb.ID
+	// Serial, if not empty, restricts the rule to the device with
+	// this serial number.
+	Serial string
+	// Mode is the permission of the device node, 0660 if zero.
+	Mode os.FileMode
+	// Group is the group owning the device node, e.g. "plugdev".
+	// If empty, the group is left unchanged.
+	Group string
+	// Uaccess grants access to the user logged in at the local seat.
+	Uaccess bool
+	// Comment is written on a line preceding the rule.
+	Comment string
+}
+
+// RuleFor returns a rule matching dev, including its serial number
+// if it has one. The returned rule grants access through the "plugdev"
+// group and to the logged in user.
+func RuleFor(dev *gousb.Device) (Rule, error) {
+	serial, err := dev.SerialNumber()
+	if err != nil {
+		return Rule{}, fmt.Errorf("failed to read the serial number of %s: %v", dev, err)
+	}
+	return Rule{
+		Vendor:  dev.Desc.Vendor,
+		Product: dev.Desc.Product,
+		Serial:  serial,
+		Group:   "plugdev",
+		Uaccess: true,
+	}, nil
+}
+
+// globChars are the characters of shell-style patterns, which udev
+// interprets in the values matched by a rule.
+const globChars = "*?[]|"
+
+// checkValue returns an error if s can't be written as a quoted value
+// of a udev rule, or, for comments, on a comment line. Values matched by
+// the rule can't contain patterns either.
+func checkValue(field, s string, comment, match bool) error {
+	for _, c := range s {
+		if c < 0x20 || c == 0x7f || (!comment && (c == '"' || c == '\\')) || (match && strings.ContainsRune(globChars, c)) {
+			return fmt.Errorf("invalid character %q in the %s %q of the udev rule", c, field, s)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the rule can't be written safely to
+// a rules file: the Serial and Group can't contain quotes, backslashes
+// or control characters, the Comment can't contain control characters,
+// including newlines, and the Serial can't contain the pattern characters
+// "*?[]|". As the serial number is read from the device, this prevents
+// a device from adding its own directives to the rules, or from making
+// the rule match other devices.
+func (r Rule) Validate() error {
+	if err := checkValue("serial number", r.Serial, false, true); err != nil {
+		return err
+	}
+	if err := checkValue("group", r.Group, false, false); err != nil {
+		return err
+	}
+	return checkValue("comment", r.Comment, true, false)
+}
+
+// String returns the rule in the udev rules file syntax, without
+// the trailing newline. String doesn't validate the rule, see Validate.
+func (r Rule) String() string {
+	var b strings.Builder
+	if r.Comment != "" {
+		fmt.Fprintf(&b, "# %s\n", r.Comment)
+	}
+	mode := r.Mode
+	if mode == 0 {
+		mode = 0660
+	}
+	fmt.Fprintf(&b, `SUBSYSTEM=="usb", ATTRS{idVendor}=="%s", ATTRS{idProduct}=="%s"`, r.Vendor, r.Product)
+	if r.Serial != "" {
+		fmt.Fprintf(&b, `, ATTRS{serial}=="%s"`, r.Serial)
+	}
+	fmt.Fprintf(&b, `, MODE="%04o"`, uint32(mode.Perm()))
+	if r.Group != "" {
+		fmt.Fprintf(&b, `, GROUP="%s"`, r.Group)
+	}
+	if r.Uaccess {
+		b.WriteString(`, TAG+="uaccess"`)
+	}
+	return b.String()
+}
+
+// Generate returns the content of a udev rules file with the given rules.
+// It returns an error if any of the rules is not valid, see Rule.Validate.
+func Generate(rules ...Rule) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("# Generated by gousb.\n")
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		b.WriteString(r.String())
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// Install writes the rules to the file name in dir, usually DefaultDir,
+// which requires root privileges. The name needs to have the ".rules"
+// suffix and, to take precedence over the default rules, sort before
+// "73-seat-late.rules", e.g. "70-mydevice.rules". Call Reload afterwards
+// to apply the rules to attached devices. Nothing is written if any of
+// the rules is not valid, see Rule.Validate.
+func Install(dir, name string, rules ...Rule) error {
+	if filepath.Ext(name) != ".rules" || filepath.Base(name) != name {
+		return fmt.Errorf("invalid udev rules file name %q", name)
+	}
+	content, err := Generate(rules...)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+		return fmt.Errorf("failed to install udev rules: %v", err)
+	}
+	return nil
+}
+
+// Reload makes udev reread the rules and reapply them to the devices
+// that are already attached.
+func Reload() error {
+	for _, args := range [][]string{
+		{"control", "--reload-rules"},
+		{"trigger", "--subsystem-match=usb", "--action=change"},
+	} {
+		if out, err := exec.Command("udevadm", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("udevadm %s: %v: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+		}
+	}
+	return nil
+}
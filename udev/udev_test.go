@@ -0,0 +1,102 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package udev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRule(t *testing.T) {
+	for _, tc := range []struct {
+		r    Rule
+		want string
+	}{
+		{
+			r:    Rule{Vendor: 0x1d50, Product: 0x6089},
+			want: `SUBSYSTEM=="usb", ATTRS{idVendor}=="1d50", ATTRS{idProduct}=="6089", MODE="0660"`,
+		},
+		{
+			r:    Rule{Vendor: 0x0403, Product: 0x6001, Serial: "A50285BI", Mode: 0666, Group: "plugdev", Uaccess: true, Comment: "FTDI cable"},
+			want: "# FTDI cable\n" + `SUBSYSTEM=="usb", ATTRS{idVendor}=="0403", ATTRS{idProduct}=="6001", ATTRS{serial}=="A50285BI", MODE="0666", GROUP="plugdev", TAG+="uaccess"`,
+		},
+	} {
+		if got := tc.r.String(); got != tc.want {
+			t.Errorf("%+v.String():\ngot  %s\nwant %s", tc.r, got, tc.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gousb-udev")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := Rule{Vendor: 0x1d50, Product: 0x6089}
+	if err := Install(dir, "70-test.rules", r); err != nil {
+		t.Fatalf("Install(): %v", err)
+	}
+	got, err := ioutil.ReadFile(filepath.Join(dir, "70-test.rules"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want, err := Generate(r)
+	if err != nil {
+		t.Fatalf("Generate(): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("installed rules: got %q, want %q", got, want)
+	}
+	for _, name := range []string{"70-test", "../70-test.rules"} {
+		if err := Install(dir, name, r); err == nil {
+			t.Errorf("Install(%q): got nil error, want non-nil", name)
+		}
+	}
+	bad := Rule{Vendor: 0x1d50, Product: 0x6089, Serial: "x\"\nRUN+=\"/bin/sh"}
+	if err := Install(dir, "71-bad.rules", bad); err == nil {
+		t.Errorf("Install() with serial %q: got nil error, want non-nil", bad.Serial)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "71-bad.rules")); !os.IsNotExist(err) {
+		t.Errorf("Install() with an invalid rule: got file stat error %v, want the file not to exist", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		r     Rule
+		valid bool
+	}{
+		{Rule{Serial: "A50285BI", Group: "plugdev", Comment: `FTDI "cable" \ adapter`}, true},
+		{Rule{Serial: `A5"0`}, false},
+		{Rule{Serial: `A5\0`}, false},
+		{Rule{Serial: "A5\n0"}, false},
+		{Rule{Serial: "*"}, false},
+		{Rule{Serial: "A5?0"}, false},
+		{Rule{Serial: "A5[0-9]"}, false},
+		{Rule{Serial: "A50|B50"}, false},
+		{Rule{Serial: "A5-0_x.y"}, true},
+		{Rule{Group: `plugdev", RUN+="x`}, false},
+		{Rule{Comment: "line\nRUN+=\"x\""}, false},
+		{Rule{Comment: "bell\x07"}, false},
+	} {
+		if err := tc.r.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%+v.Validate(): got error %v, want valid %v", tc.r, err, tc.valid)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// UnsupportedError is returned when an operation can't be performed
+// because libusb doesn't implement it on the current platform, e.g.
+// detaching kernel drivers on OpenBSD or NetBSD.
+type UnsupportedError struct {
+	// Op is the operation that failed.
+	Op string
+	// GOOS is the platform the program runs on.
+	GOOS string
+	// Capability is the missing libusb capability. It's
+	// CapabilityHasCapability for operations that libusb doesn't report
+	// through Context.HasCapability.
+	Capability Capability
+	// Err is the underlying error, ErrorNotSupported.
+	Err error
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Capability != CapabilityHasCapability {
+		return fmt.Sprintf("%s is not supported by libusb on %s (no %s capability): %v", e.Op, e.GOOS, e.Capability, e.Err)
+	}
+	return fmt.Sprintf("%s is not supported by libusb on %s: %v", e.Op, e.GOOS, e.Err)
+}
+
+// Unwrap returns the underlying error, so that
+// errors.Is(err, ErrorNotSupported) holds for an UnsupportedError.
+func (e *UnsupportedError) Unwrap() error {
+	return e.Err
+}
+
+// newUnsupportedError returns an UnsupportedError for op on the current
+// platform, which requires the capability c.
+func newUnsupportedError(op string, c Capability) *UnsupportedError {
+	return &UnsupportedError{Op: op, GOOS: runtime.GOOS, Capability: c, Err: ErrorNotSupported}
+}
+
+// isoSupported reports whether libusb implements isochronous transfers on
+// goos. The OpenBSD and NetBSD backends of libusb don't, and libusb has no
+// capability to query it.
+func isoSupported(goos string) bool {
+	return goos != "openbsd" && goos != "netbsd"
+}
+
+// zeroPacketSupported reports whether libusb can terminate transfers with
+// a zero-length packet on goos, which it only implements on Linux.
+// Elsewhere gousb sends the zero-length packet as a separate transfer.
+func zeroPacketSupported(goos string) bool {
+	return goos == "linux"
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnsupportedError(t *testing.T) {
+	for _, tc := range []struct {
+		err  *UnsupportedError
+		want string
+	}{
+		{
+			err:  &UnsupportedError{Op: "detaching kernel drivers", GOOS: "openbsd", Capability: CapabilityDetachKernelDriver, Err: ErrorNotSupported},
+			want: "detaching kernel drivers is not supported by libusb on openbsd (no detach kernel driver capability): " + ErrorNotSupported.Error(),
+		},
+		{
+			err:  &UnsupportedError{Op: "isochronous endpoint 0x86(6,IN)", GOOS: "netbsd", Err: ErrorNotSupported},
+			want: "isochronous endpoint 0x86(6,IN) is not supported by libusb on netbsd: " + ErrorNotSupported.Error(),
+		},
+	} {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("Error(): got %q, want %q", got, tc.want)
+		}
+		if !errors.Is(tc.err, ErrorNotSupported) {
+			t.Errorf("errors.Is(%v, ErrorNotSupported): got false, want true", tc.err)
+		}
+	}
+}
+
+func TestIsoSupported(t *testing.T) {
+	for goos, want := range map[string]bool{
+		"linux":   true,
+		"darwin":  true,
+		"freebsd": true,
+		"openbsd": false,
+		"netbsd":  false,
+	} {
+		if got := isoSupported(goos); got != want {
+			t.Errorf("isoSupported(%q): got %v, want %v", goos, got, want)
+		}
+	}
+}
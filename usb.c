@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 // Copyright 2013 Google Inc.  All rights reserved.
 // Copyright 2018 the gousb Authors.  All rights reserved.
 //
@@ -14,6 +17,13 @@
 // limitations under the License.
 
 #include <libusb.h>
+#include <stdint.h>
+
+// The libusb implementation in the FreeBSD base system doesn't define
+// LIBUSB_CALL, it only matters for the calling convention on Windows.
+#ifndef LIBUSB_CALL
+#define LIBUSB_CALL
+#endif
 
 void gousb_set_debug(libusb_context *ctx, int lvl) {
     // TODO(sebek): remove libusb_debug entirely in 2.1 or 3.0,
@@ -24,3 +34,108 @@ void gousb_set_debug(libusb_context *ctx, int lvl) {
     libusb_set_debug(ctx, lvl);
 #endif
 }
+
+// initializes a new libusb context, applying the options that have to be
+// set before or immediately after libusb_init.
+int gousb_init(libusb_context **ctx, int no_discovery, int use_usbdk) {
+    int r;
+#if LIBUSB_API_VERSION >= 0x0100010A
+    // libusb_init_context is available in libusb >= 1.0.27 and applies
+    // the options to the new context only.
+    struct libusb_init_option opts[1];
+    int nopts = 0;
+    if (no_discovery) {
+        opts[nopts].option = LIBUSB_OPTION_NO_DEVICE_DISCOVERY;
+        nopts++;
+    }
+    r = libusb_init_context(ctx, opts, nopts);
+#else
+    if (no_discovery) {
+        // LIBUSB_OPTION_WEAK_AUTHORITY (renamed to NO_DEVICE_DISCOVERY in
+        // libusb 1.0.25) is available in libusb >= 1.0.24.
+#if LIBUSB_API_VERSION >= 0x01000108
+        r = libusb_set_option(NULL, LIBUSB_OPTION_WEAK_AUTHORITY);
+        if (r != LIBUSB_SUCCESS) {
+            return r;
+        }
+#else
+        return LIBUSB_ERROR_NOT_SUPPORTED;
+#endif
+    }
+    r = libusb_init(ctx);
+#endif
+    if (r != LIBUSB_SUCCESS) {
+        return r;
+    }
+    // UsbDk backend exists only on Windows, the option is ignored elsewhere.
+#if defined(_WIN32) && LIBUSB_API_VERSION >= 0x01000106
+    if (use_usbdk) {
+        r = libusb_set_option(*ctx, LIBUSB_OPTION_USE_USBDK);
+        if (r != LIBUSB_SUCCESS) {
+            libusb_exit(*ctx);
+            return r;
+        }
+    }
+#endif
+    return LIBUSB_SUCCESS;
+}
+
+// handles libusb events for at most usec microseconds.
+int gousb_handle_events(libusb_context *ctx, long long usec) {
+    struct timeval tv;
+    tv.tv_sec = usec / 1000000;
+    tv.tv_usec = usec % 1000000;
+    return libusb_handle_events_timeout_completed(ctx, &tv, NULL);
+}
+
+void gousb_interrupt_event_handler(libusb_context *ctx) {
+    // libusb_interrupt_event_handler is available in libusb >= 1.0.21.
+    // Older versions return from event handling at the next tick.
+#if LIBUSB_API_VERSION >= 0x01000105
+    libusb_interrupt_event_handler(ctx);
+#endif
+}
+
+int gousb_wrap_sys_device(libusb_context *ctx, intptr_t sys_dev, libusb_device_handle **handle) {
+    // libusb_wrap_sys_device is available in libusb >= 1.0.23.
+#if LIBUSB_API_VERSION >= 0x01000107
+    return libusb_wrap_sys_device(ctx, sys_dev, handle);
+#else
+    return LIBUSB_ERROR_NOT_SUPPORTED;
+#endif
+}
+
+unsigned char *gousb_dev_mem_alloc(libusb_device_handle *handle, size_t length) {
+    // libusb_dev_mem_alloc is available in libusb >= 1.0.21.
+#if LIBUSB_API_VERSION >= 0x01000105
+    return libusb_dev_mem_alloc(handle, length);
+#else
+    return NULL;
+#endif
+}
+
+int gousb_dev_mem_free(libusb_device_handle *handle, unsigned char *buffer, size_t length) {
+#if LIBUSB_API_VERSION >= 0x01000105
+    return libusb_dev_mem_free(handle, buffer, length);
+#else
+    return LIBUSB_ERROR_NOT_SUPPORTED;
+#endif
+}
+
+int goHotplugCallback(libusb_context *ctx, libusb_device *dev, libusb_hotplug_event event, int id);
+
+static int LIBUSB_CALL gousb_hotplug_callback(libusb_context *ctx, libusb_device *dev, libusb_hotplug_event event, void *user_data) {
+    return goHotplugCallback(ctx, dev, event, (int)(intptr_t)user_data);
+}
+
+// registers a hotplug callback for arrival and departure of all devices.
+// Events are delivered to goHotplugCallback with the given id.
+int gousb_hotplug_register_callback(libusb_context *ctx, int id, libusb_hotplug_callback_handle *handle) {
+    if (!libusb_has_capability(LIBUSB_CAP_HAS_HOTPLUG)) {
+        return LIBUSB_ERROR_NOT_SUPPORTED;
+    }
+    return libusb_hotplug_register_callback(ctx,
+        LIBUSB_HOTPLUG_EVENT_DEVICE_ARRIVED | LIBUSB_HOTPLUG_EVENT_DEVICE_LEFT,
+        0, LIBUSB_HOTPLUG_MATCH_ANY, LIBUSB_HOTPLUG_MATCH_ANY, LIBUSB_HOTPLUG_MATCH_ANY,
+        gousb_hotplug_callback, (void*)(intptr_t)id, handle);
+}
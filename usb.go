@@ -117,6 +117,23 @@ standard for control commands though, and many devices implement their custom co
 
 Control commands can be issued through Device.Control().
 
+Concurrency
+
+Transfers may be issued from multiple goroutines. Reads and writes on
+different endpoints of a device, control requests and transfers on
+different devices don't block each other. Several goroutines may also use
+the same endpoint at once: each Read or Write is a separate USB transfer,
+the data of a transfer is never interleaved with another one, but the
+order in which concurrent transfers reach the device depends on
+the scheduling of the goroutines. Endpoint settings, like SetShortNotOK,
+SetZeroPacket and the deadlines, may be changed while transfers are in
+flight and apply to the transfers started afterwards.
+
+ReadStream and WriteStream are not safe for concurrent use, see their
+methods for details. Transfers in flight on an endpoint can be cancelled
+with CancelAll, Interface.Close cancels the transfers on the endpoints of
+the interface and waits for them. Streams need to be closed before.
+
 See Also
 
 For more information about USB protocol and handling USB devices,
@@ -128,17 +145,62 @@ package gousb
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Context manages all resources related to USB device handling.
+//
+// Each Context has its own libusb context and event handling goroutine.
+// Multiple Contexts can be used in the same process, e.g. by independent
+// libraries, and don't share devices, transfers, debug levels, loggers,
+// tracers, captures or metrics.
 type Context struct {
 	ctx    *libusbContext
-	done   chan struct{}
 	libusb libusbIntf
+	pool   *transferPool
+
+	// done is closed when the Context is closed, to stop event handling.
+	done chan struct{}
+	// events tracks the running event handlers, see HandleEvents.
+	events sync.WaitGroup
+	// eventTick is the maximum time an event handler waits for events.
+	eventTick time.Duration
+
+	// hotplugPollInterval is the device enumeration interval used by
+	// RegisterHotplug when native hotplug is not supported.
+	hotplugPollInterval time.Duration
+	// closeMode is the mode used by Close.
+	closeMode CloseMode
+
+	// logger holds a loggerHolder, see SetLogger.
+	logger atomic.Value
+	// tracer holds a *tracer, see SetTrace.
+	tracer atomic.Value
+	// capture holds a *capturer, see SetCapture.
+	capture atomic.Value
+	// metrics collects the per-endpoint transfer metrics.
+	metrics *metricsRegistry
+
+	// maxTransfer caches the maximum transfer size of the backend, which
+	// may be read from sysfs, see maxTransferSize.
+	maxTransferOnce sync.Once
+	maxTransfer     int
 
 	mu      sync.Mutex
 	devices map[*Device]bool
+	hotplug map[*hotplugWatcher]bool
+	refs    map[*DeviceRef]bool
+}
+
+// maxTransferSize returns the size of the largest bulk or interrupt
+// transfer supported by the backend, 0 if there's no limit. It's queried
+// once per Context.
+func (c *Context) maxTransferSize() int {
+	c.maxTransferOnce.Do(func() { c.maxTransfer = c.libusb.maxTransferSize() })
+	return c.maxTransfer
 }
 
 // Debug changes the debug level. Level 0 means no debug, higher levels
@@ -149,24 +211,128 @@ func (c *Context) Debug(level int) {
 	c.libusb.setDebug(c.ctx, level)
 }
 
+// LibusbVersion is the version of the libusb library used by gousb.
+type LibusbVersion struct {
+	Major, Minor, Micro, Nano uint16
+	// RC is the release candidate suffix, e.g. "-rc4", or an empty string.
+	RC string
+}
+
+// String returns the version in the format used by libusb, e.g. "1.0.26.11724".
+func (v LibusbVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d%s", v.Major, v.Minor, v.Micro, v.Nano, v.RC)
+}
+
+// LibusbVersion returns the version of the loaded libusb library.
+func (c *Context) LibusbVersion() LibusbVersion {
+	return c.libusb.getVersion()
+}
+
+// HasCapability reports whether the loaded libusb library supports
+// the capability on the current platform.
+func (c *Context) HasCapability(cap Capability) bool {
+	return c.libusb.hasCapability(cap)
+}
+
+// ContextOptions configures a Context created with NewContextWithOptions.
+// The zero value is equivalent to NewContext.
+type ContextOptions struct {
+	// DebugLevel is the initial debug level, see Context.Debug.
+	DebugLevel int
+	// NoDeviceDiscovery disables scanning for devices when the Context is
+	// initialized. Devices can then only be opened from a system handle,
+	// e.g. a file descriptor obtained from Android's UsbManager.
+	// With libusb >= 1.0.27 the option only applies to the new Context.
+	// With older versions it's global to libusb and applies to all Contexts
+	// created afterwards. Requires libusb >= 1.0.24.
+	NoDeviceDiscovery bool
+	// UseUsbDk selects the UsbDk backend on Windows. It is ignored on other
+	// platforms.
+	UseUsbDk bool
+	// HotplugPollInterval is the device enumeration interval used by
+	// RegisterHotplug on platforms without native hotplug support.
+	// Defaults to 1 second.
+	HotplugPollInterval time.Duration
+	// Logger receives lifecycle events, see Context.SetLogger.
+	Logger Logger
+	// EventTick is the maximum time the event handler waits for events
+	// before checking whether the Context was closed. Shorter ticks make
+	// Close return faster with libusb versions that can't interrupt event
+	// handling. Defaults to 100 milliseconds.
+	EventTick time.Duration
+	// ManualEvents disables the event handling goroutine of the Context.
+	// The program then needs to call Context.HandleEvents on a goroutine
+	// of its choice, otherwise no transfers complete.
+	ManualEvents bool
+	// CloseMode selects what Close does with the devices still open.
+	// Defaults to CloseStrict.
+	CloseMode CloseMode
+	// Backend is the name of the USB stack used by the Context, see
+	// RegisterBackend. Defaults to "libusb", or "webusb" in WebAssembly
+	// builds. The libusb specific options are ignored by other backends.
+	Backend string
+}
+
 func newContextWithImpl(impl libusbIntf) *Context {
-	c, err := impl.init()
+	ctx, err := newContextWithImplAndOptions(impl, ContextOptions{})
 	if err != nil {
 		panic(err)
 	}
+	return ctx
+}
+
+func newContextWithImplAndOptions(impl libusbIntf, opts ContextOptions) (*Context, error) {
+	c, err := impl.init(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.HotplugPollInterval <= 0 {
+		opts.HotplugPollInterval = defaultHotplugPollInterval
+	}
+	if opts.EventTick <= 0 {
+		opts.EventTick = defaultEventTick
+	}
 	ctx := &Context{
 		ctx:     c,
 		done:    make(chan struct{}),
 		libusb:  impl,
+		pool:    newTransferPool(impl),
 		devices: make(map[*Device]bool),
+		hotplug: make(map[*hotplugWatcher]bool),
+		refs:    make(map[*DeviceRef]bool),
+		metrics: newMetricsRegistry(),
+
+		eventTick:           opts.EventTick,
+		hotplugPollInterval: opts.HotplugPollInterval,
+		closeMode:           opts.CloseMode,
 	}
-	go impl.handleEvents(ctx.ctx, ctx.done)
-	return ctx
+	ctx.SetLogger(opts.Logger)
+	if opts.DebugLevel != 0 {
+		impl.setDebug(c, opts.DebugLevel)
+	}
+	if !opts.ManualEvents {
+		ctx.events.Add(1)
+		go func() {
+			defer ctx.events.Done()
+			impl.handleEvents(ctx.ctx, ctx.eventTick, ctx.done)
+		}()
+	}
+	return ctx, nil
 }
 
 // NewContext returns a new Context instance.
 func NewContext() *Context {
-	return newContextWithImpl(libusbImpl{})
+	return newContextWithImpl(newDefaultImpl())
+}
+
+// NewContextWithOptions returns a new Context instance configured with opts.
+// Unlike NewContext, it returns an error if libusb fails to initialize.
+func NewContextWithOptions(opts ContextOptions) (*Context, error) {
+	impl, err := backendImpl(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newContextWithImplAndOptions(impl, opts)
 }
 
 // OpenDevices calls opener with each enumerated device.
@@ -174,6 +340,8 @@ func NewContext() *Context {
 // Every Device returned (whether an error is also returned or not) must be closed.
 // If there are any errors enumerating the devices,
 // the final one is returned along with any successfully opened devices.
+// A device that can't be opened due to missing access rights is reported
+// as a *PermissionError.
 func (c *Context) OpenDevices(opener func(desc *DeviceDesc) bool) ([]*Device, error) {
 	if c.ctx == nil {
 		return nil, errors.New("OpenDevices called on a closed or uninitialized Context")
@@ -194,17 +362,15 @@ func (c *Context) OpenDevices(opener func(desc *DeviceDesc) bool) ([]*Device, er
 		}
 
 		if opener(desc) {
-			handle, err := c.libusb.open(dev)
+			o, err := c.openDevice(dev, desc)
 			if err != nil {
 				c.libusb.dereference(dev)
 				reterr = err
 				continue
 			}
-			o := &Device{handle: handle, ctx: c, Desc: desc}
+			// The open device holds its own reference.
+			c.libusb.dereference(dev)
 			ret = append(ret, o)
-			c.mu.Lock()
-			c.devices[o] = true
-			c.mu.Unlock()
 		} else {
 			c.libusb.dereference(dev)
 		}
@@ -212,6 +378,24 @@ func (c *Context) OpenDevices(opener func(desc *DeviceDesc) bool) ([]*Device, er
 	return ret, reterr
 }
 
+// openDevice opens the enumerated device dev with the descriptor desc.
+func (c *Context) openDevice(dev *libusbDevice, desc *DeviceDesc) (*Device, error) {
+	handle, err := c.libusb.open(dev)
+	if err != nil {
+		c.log(LogOpOpen, err, LogField{"device", desc.String()})
+		if err == ErrorAccess {
+			err = newPermissionError(desc, err)
+		}
+		return nil, err
+	}
+	o := &Device{handle: handle, ctx: c, Desc: desc}
+	c.log(LogOpOpen, nil, LogField{"device", o.String()})
+	c.mu.Lock()
+	c.devices[o] = true
+	c.mu.Unlock()
+	return o, nil
+}
+
 // OpenDeviceWithVIDPID opens Device from specific VendorId and ProductId.
 // If none is found, it returns nil and nil error. If there are multiple devices
 // with the same VID/PID, it will return one of them, picked arbitrarily.
@@ -236,11 +420,50 @@ func (c *Context) OpenDeviceWithVIDPID(vid, pid ID) (*Device, error) {
 	return devs[0], nil
 }
 
+// OpenDeviceWithFileDescriptor opens a Device from a file descriptor of an
+// already opened usbfs device node, e.g. one obtained on Android from
+// UsbDeviceConnection.getFileDescriptor(). This allows using devices on
+// platforms where the process is not allowed to enumerate the bus, see
+// ContextOptions.NoDeviceDiscovery.
+//
+// The file descriptor must remain open until the Device is closed, closing
+// the Device does not close the file descriptor. Requires libusb >= 1.0.23
+// on Linux or Android.
+func (c *Context) OpenDeviceWithFileDescriptor(fd uintptr) (*Device, error) {
+	if c.ctx == nil {
+		return nil, errors.New("OpenDeviceWithFileDescriptor called on a closed or uninitialized Context")
+	}
+	handle, err := c.libusb.wrapSysDevice(c.ctx, fd)
+	if err != nil {
+		c.log(LogOpOpen, err, LogField{"fd", fd})
+		return nil, err
+	}
+	desc, err := c.libusb.getDeviceDesc(c.libusb.getDevice(handle))
+	if err != nil {
+		c.log(LogOpOpen, err, LogField{"fd", fd})
+		c.libusb.close(handle)
+		return nil, err
+	}
+	o := &Device{handle: handle, ctx: c, Desc: desc}
+	c.log(LogOpOpen, nil, LogField{"device", o.String()}, LogField{"fd", fd})
+	c.mu.Lock()
+	c.devices[o] = true
+	c.mu.Unlock()
+	return o, nil
+}
+
 func (c *Context) closeDev(d *Device) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.pool.releaseHandle(d.handle)
 	c.libusb.close(d.handle)
 	delete(c.devices, d)
+	for o := range c.devices {
+		if o.Desc.Bus == d.Desc.Bus && o.Desc.Address == d.Desc.Address {
+			return
+		}
+	}
+	c.metrics.drop(d)
 }
 
 func (c *Context) checkOpenDevs() error {
@@ -252,16 +475,103 @@ func (c *Context) checkOpenDevs() error {
 	return nil
 }
 
-// Close releases the Context and all associated resources.
+// closeOpenDevs releases the interfaces and configs claimed on the devices
+// still open and closes the devices. It goes through all devices even if
+// some of them fail, and returns the errors combined.
+func (c *Context) closeOpenDevs() error {
+	c.mu.Lock()
+	devs := make([]*Device, 0, len(c.devices))
+	for d := range c.devices {
+		devs = append(devs, d)
+	}
+	c.mu.Unlock()
+	var errs []string
+	for _, d := range devs {
+		d.mu.Lock()
+		cfg := d.claimed
+		d.mu.Unlock()
+		if cfg != nil {
+			// Interface may be waiting to retry a busy claim, it
+			// must be done with the handle before it's closed.
+			cfg.cancelClaims()
+			if err := cfg.release(); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to release %s: %v", cfg, err))
+				// Drop the config anyway, so that the device is closed.
+				d.mu.Lock()
+				d.claimed = nil
+				d.mu.Unlock()
+			}
+		}
+		if err := d.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// CloseMode selects what Context.Close does with the devices that are
+// still open.
+type CloseMode int
+
+const (
+	// CloseStrict makes Close fail while devices are still open, leaving
+	// the Context usable. The application needs to close the interfaces,
+	// configs and devices first.
+	CloseStrict CloseMode = iota
+	// CloseForce makes Close release everything still in use: the
+	// transfers in flight on the endpoints of the claimed interfaces and
+	// the claims waiting for RetryBusy are cancelled, then the interfaces
+	// and configs are released and the devices are closed. The Interfaces, Configs and Devices can no longer
+	// be used, closing them again has no effect. ReadStreams and
+	// WriteStreams aren't tracked and need to be closed before. If some
+	// of the devices fail to close cleanly, the remaining devices and
+	// the Context are closed anyway and the errors are returned.
+	CloseForce
+)
+
+// Close releases the Context and all associated resources, including
+// the DeviceRefs that were not released yet. Devices still open are
+// handled according to ContextOptions.CloseMode, by default Close fails
+// if there are any, see CloseWithMode.
 func (c *Context) Close() error {
+	return c.CloseWithMode(c.closeMode)
+}
+
+// CloseWithMode is like Close, but handles the devices still open
+// according to mode, e.g. CloseForce on the shutdown path of a server
+// that can't wait for all its clients to close their devices.
+func (c *Context) CloseWithMode(mode CloseMode) error {
 	if c.ctx == nil {
 		return nil
 	}
-	if err := c.checkOpenDevs(); err != nil {
-		return err
+	var devErr error
+	switch mode {
+	case CloseStrict:
+		if err := c.checkOpenDevs(); err != nil {
+			return err
+		}
+	case CloseForce:
+		// The Context is torn down even if some devices failed to close,
+		// the error is returned at the end.
+		devErr = c.closeOpenDevs()
+	default:
+		return fmt.Errorf("invalid close mode %d", mode)
 	}
-	c.done <- struct{}{}
+	c.closeHotplug()
+	c.releaseRefs()
+	c.stopEvents()
+	c.pool.close()
+	c.unpublishMetrics()
 	err := c.libusb.exit(c.ctx)
 	c.ctx = nil
+	switch {
+	case devErr != nil && err != nil:
+		return fmt.Errorf("%v; %v", devErr, err)
+	case devErr != nil:
+		return devErr
+	}
 	return err
 }
@@ -15,7 +15,11 @@
 
 package gousb
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestOPenDevices(t *testing.T) {
 	t.Parallel()
@@ -60,6 +64,151 @@ func TestOPenDevices(t *testing.T) {
 	}
 }
 
+func TestCloseForce(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	intf, err := cfg.Interface(0, 0)
+	if err != nil {
+		t.Fatalf("%s.Interface(0, 0): %v", cfg, err)
+	}
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	readErr := make(chan error)
+	go func() {
+		_, err := ep.Read(make([]byte, 512))
+		readErr <- err
+	}()
+	lib.waitForSubmitted(nil)
+
+	if err := ctx.CloseWithMode(CloseStrict); err == nil {
+		t.Fatal("CloseWithMode(CloseStrict) succeeded while a device was still open")
+	}
+	if err := ctx.CloseWithMode(CloseForce); err != nil {
+		t.Fatalf("CloseWithMode(CloseForce): %v", err)
+	}
+	select {
+	case err := <-readErr:
+		if err != TransferCancelled {
+			t.Errorf("%s.Read during CloseWithMode(CloseForce): got error %v, want %v", ep, err, TransferCancelled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s.Read still running after CloseWithMode(CloseForce)", ep)
+	}
+	if _, err := dev.Manufacturer(); err == nil {
+		t.Errorf("%s.Manufacturer() after CloseWithMode(CloseForce): got nil error, want non-nil", dev)
+	}
+	intf.Close()
+	if err := cfg.Close(); err != nil {
+		t.Errorf("%s.Close() after CloseWithMode(CloseForce): %v", cfg, err)
+	}
+	if err := dev.Close(); err != nil {
+		t.Errorf("%s.Close() after CloseWithMode(CloseForce): %v", dev, err)
+	}
+}
+
+func TestCloseForceErrors(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+
+	var devs []*Device
+	for _, id := range [][2]ID{{0x9999, 0x0001}, {0x8888, 0x0002}} {
+		dev, err := ctx.OpenDeviceWithVIDPID(id[0], id[1])
+		if err != nil {
+			t.Fatalf("OpenDeviceWithVIDPID(%s, %s): %v", id[0], id[1], err)
+		}
+		if _, err := dev.Config(1); err != nil {
+			t.Fatalf("%s.Config(1): %v", dev, err)
+		}
+		devs = append(devs, dev)
+	}
+	// Make releasing the config of every device fail, with an interface
+	// that stays claimed when closed.
+	for _, dev := range devs {
+		cfg := dev.claimed
+		cfg.mu.Lock()
+		cfg.claimed[5] = &Interface{}
+		cfg.mu.Unlock()
+	}
+
+	if err := ctx.CloseWithMode(CloseForce); err == nil {
+		t.Error("CloseWithMode(CloseForce) with configs failing to release: got nil error, want non-nil")
+	}
+	for _, dev := range devs {
+		if dev.handle != nil {
+			t.Errorf("%s is still open after CloseWithMode(CloseForce)", dev)
+		}
+	}
+	if ctx.ctx != nil {
+		t.Error("the Context is still open after CloseWithMode(CloseForce)")
+	}
+	if err := ctx.Close(); err != nil {
+		t.Errorf("Close() after CloseWithMode(CloseForce): %v", err)
+	}
+}
+
+func TestCloseForceCancelsClaims(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx := newContextWithImpl(lib)
+
+	dev, err := ctx.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(0x9999, 0x0001): %v", err)
+	}
+	cfg, err := dev.Config(1)
+	if err != nil {
+		t.Fatalf("%s.Config(1): %v", dev, err)
+	}
+	lib.mu.Lock()
+	lib.busyClaims = 1
+	lib.mu.Unlock()
+	claimErr := make(chan error)
+	go func() {
+		_, err := cfg.Interface(0, 0, RetryBusy(1, time.Hour))
+		claimErr <- err
+	}()
+	// Wait for the busy claim, the retry would follow an hour later.
+	for {
+		lib.mu.Lock()
+		calls := lib.claimCalls
+		lib.mu.Unlock()
+		if calls > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := ctx.CloseWithMode(CloseForce); err != nil {
+		t.Errorf("CloseWithMode(CloseForce): %v", err)
+	}
+	select {
+	case err := <-claimErr:
+		if err == nil {
+			t.Errorf("%s.Interface(0, 0, RetryBusy(1, time.Hour)) during CloseWithMode(CloseForce): got nil error, want non-nil", cfg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s.Interface(0, 0, RetryBusy(1, time.Hour)) still running after CloseWithMode(CloseForce)", cfg)
+	}
+	lib.mu.Lock()
+	calls := lib.claimCalls
+	lib.mu.Unlock()
+	if calls != 1 {
+		t.Errorf("claim attempts: got %d, want 1, the retry must not run after CloseWithMode(CloseForce)", calls)
+	}
+}
+
 func TestOpenDeviceWithVIDPID(t *testing.T) {
 	t.Parallel()
 	ctx := newContextWithImpl(newFakeLibusb())
@@ -94,3 +243,175 @@ func TestOpenDeviceWithVIDPID(t *testing.T) {
 		}
 	}
 }
+
+func TestContextWithOptions(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	opts := ContextOptions{
+		DebugLevel:          3,
+		NoDeviceDiscovery:   true,
+		HotplugPollInterval: 10 * time.Millisecond,
+	}
+	ctx, err := newContextWithImplAndOptions(lib, opts)
+	if err != nil {
+		t.Fatalf("newContextWithImplAndOptions(%+v): %v", opts, err)
+	}
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	if lib.opts != opts {
+		t.Errorf("libusb initialized with options %+v, want %+v", lib.opts, opts)
+	}
+	if got, want := lib.debug, 3; got != want {
+		t.Errorf("debug level: got %d, want %d", got, want)
+	}
+	if got, want := ctx.hotplugPollInterval, opts.HotplugPollInterval; got != want {
+		t.Errorf("hotplug poll interval: got %v, want %v", got, want)
+	}
+}
+
+func TestLibusbVersionAndCapabilities(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+	if got, want := ctx.LibusbVersion().String(), "1.0.26.11724"; got != want {
+		t.Errorf("LibusbVersion(): got %q, want %q", got, want)
+	}
+	for c, want := range map[Capability]bool{
+		CapabilityHasCapability:      true,
+		CapabilityHotplug:            false,
+		CapabilityHIDAccess:          false,
+		CapabilityDetachKernelDriver: true,
+	} {
+		if got := ctx.HasCapability(c); got != want {
+			t.Errorf("HasCapability(%s): got %v, want %v", c, got, want)
+		}
+	}
+}
+
+func TestOpenDeviceWithFileDescriptor(t *testing.T) {
+	t.Parallel()
+	ctx := newContextWithImpl(newFakeLibusb())
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			t.Errorf("Context.Close(): %v", err)
+		}
+	}()
+
+	dev, err := ctx.OpenDeviceWithFileDescriptor(1)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithFileDescriptor(1): %v", err)
+	}
+	if got, want := dev.Desc, fakeDevices[1].devDesc; got != want {
+		t.Errorf("OpenDeviceWithFileDescriptor(1): got device %v, want %v", got, want)
+	}
+	if err := dev.Close(); err != nil {
+		t.Errorf("%s.Close(): %v", dev, err)
+	}
+
+	if dev, err := ctx.OpenDeviceWithFileDescriptor(100); err == nil {
+		dev.Close()
+		t.Error("OpenDeviceWithFileDescriptor(100): got nil error, want non-nil")
+	}
+}
+
+func TestContextIsolation(t *testing.T) {
+	t.Parallel()
+	lib1, lib2 := newFakeLibusb(), newFakeLibusb()
+	ctx1, ctx2 := newContextWithImpl(lib1), newContextWithImpl(lib2)
+	defer ctx2.Close()
+
+	ctx1.Debug(4)
+	lib2.mu.Lock()
+	if lib2.debug != 0 {
+		t.Errorf("debug level of the second context after Debug(4) on the first: got %d, want 0", lib2.debug)
+	}
+	lib2.mu.Unlock()
+
+	dev2, err := ctx2.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("ctx2.OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	defer dev2.Close()
+	intf, done, err := dev2.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev2, err)
+	}
+	defer done()
+	ep, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+
+	// A transfer of the second context survives closing the first one.
+	errc := make(chan error)
+	go func() {
+		_, err := ep.Read(make([]byte, 512))
+		errc <- err
+	}()
+	xfer := lib2.waitForSubmitted(nil)
+	dev1, err := ctx1.OpenDeviceWithVIDPID(0x9999, 0x0001)
+	if err != nil {
+		t.Fatalf("ctx1.OpenDeviceWithVIDPID(9999:0001): %v", err)
+	}
+	dev1.Close()
+	if err := ctx1.Close(); err != nil {
+		t.Fatalf("ctx1.Close(): %v", err)
+	}
+	xfer.setData(make([]byte, 512))
+	xfer.setStatus(TransferCompleted)
+	if err := <-errc; err != nil {
+		t.Errorf("%s.Read() after closing another Context: %v", ep, err)
+	}
+	if _, err := ctx1.OpenDeviceWithVIDPID(0x9999, 0x0001); err == nil {
+		t.Errorf("ctx1.OpenDeviceWithVIDPID() after Close: got nil error, want non-nil")
+	}
+}
+
+func TestManualEvents(t *testing.T) {
+	t.Parallel()
+	lib := newFakeLibusb()
+	ctx, err := newContextWithImplAndOptions(lib, ContextOptions{ManualEvents: true, EventTick: time.Millisecond})
+	if err != nil {
+		t.Fatalf("newContextWithImplAndOptions(): %v", err)
+	}
+	if got, want := ctx.eventTick, time.Millisecond; got != want {
+		t.Errorf("event tick: got %s, want %s", got, want)
+	}
+
+	cctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- ctx.HandleEvents(cctx) }()
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("HandleEvents() after cancel: got %v, want %v", err, context.Canceled)
+	}
+
+	go func() { errc <- ctx.HandleEvents(context.Background()) }()
+	for running := false; !running; time.Sleep(time.Millisecond) {
+		lib.mu.Lock()
+		running = lib.handlers == 1
+		lib.mu.Unlock()
+	}
+	ctx.InterruptEvents()
+	if err := ctx.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("HandleEvents() after Close: got %v, want nil", err)
+	}
+	lib.mu.Lock()
+	if lib.interrupts < 2 {
+		t.Errorf("interruptEvents calls: got %d, want at least 2", lib.interrupts)
+	}
+	lib.mu.Unlock()
+	if err := ctx.HandleEvents(context.Background()); err == nil {
+		t.Errorf("HandleEvents() on a closed Context: got nil error, want non-nil")
+	}
+}
@@ -26,6 +26,7 @@ package usbid
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/google/gousb"
 )
@@ -38,7 +39,7 @@ import (
 func Describe(val interface{}) string {
 	switch val := val.(type) {
 	case *gousb.DeviceDesc:
-		if v, ok := Vendors[val.Vendor]; ok {
+		if v, ok := CurrentVendors()[val.Vendor]; ok {
 			if d, ok := v.Product[val.Product]; ok {
 				return fmt.Sprintf("%s (%s)", d, v)
 			}
@@ -69,7 +70,7 @@ func Classify(val interface{}) string {
 		return fmt.Sprintf("Unknown (%T)", val)
 	}
 
-	if c, ok := Classes[class]; ok {
+	if c, ok := CurrentClasses()[class]; ok {
 		if s, ok := c.SubClass[sub]; ok {
 			if p, ok := s.Protocol[proto]; ok {
 				return fmt.Sprintf("%s (%s) %s", c, s, p)
@@ -80,3 +81,41 @@ func Classify(val interface{}) string {
 	}
 	return fmt.Sprintf("Unknown %s.%s.%s", class, sub, proto)
 }
+
+// ClassNames returns the names of the class, subclass and protocol from the
+// loaded ID database. The subclass and protocol are qualified by the class
+// and subclass, as in the USB spec. Names that are not found in the
+// database are returned as empty strings.
+func ClassNames(class, sub gousb.Class, proto gousb.Protocol) (className, subClassName, protocolName string) {
+	c, ok := CurrentClasses()[class]
+	if !ok {
+		return "", "", ""
+	}
+	s, ok := c.SubClass[sub]
+	if !ok {
+		return c.Name, "", ""
+	}
+	return c.Name, s.Name, s.Protocol[proto]
+}
+
+// ClassName returns a human-readable name of the class, subclass and
+// protocol triple, with the names that are known joined by " / ",
+// e.g. "Audio / Streaming". If the class is not in the database, the class
+// name defined by gousb or its number is used instead.
+//
+// Device-level triples are taken from DeviceDesc.Class, SubClass and Protocol,
+// interface-level ones from the same fields of InterfaceSetting.
+func ClassName(class, sub gousb.Class, proto gousb.Protocol) string {
+	c, s, p := ClassNames(class, sub, proto)
+	if c == "" {
+		return class.String()
+	}
+	parts := []string{c}
+	if s != "" {
+		parts = append(parts, s)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usbid
+
+import (
+	"testing"
+
+	"github.com/google/gousb"
+)
+
+func TestClassName(t *testing.T) {
+	defer keepDB()()
+	if err := LoadFromFile(testDBPath); err != nil {
+		t.Fatalf("LoadFromFile(%q): %v", testDBPath, err)
+	}
+
+	for _, tc := range []struct {
+		class, sub gousb.Class
+		proto      gousb.Protocol
+		want       string
+	}{
+		{0x01, 0x02, 0x00, "Audio / Streaming"},
+		{0x02, 0x02, 0x01, "Communications / Abstract (modem) / AT-commands (v.25ter)"},
+		{0x02, 0x02, 0x42, "Communications / Abstract (modem)"},
+		{0x02, 0x42, 0x01, "Communications"},
+		{0x0e, 0x01, 0x00, "video"},
+		{0x42, 0x00, 0x00, "66"},
+	} {
+		if got := ClassName(tc.class, tc.sub, tc.proto); got != tc.want {
+			t.Errorf("ClassName(%s, %s, %s): got %q, want %q", tc.class, tc.sub, tc.proto, got, tc.want)
+		}
+	}
+
+	c, s, p := ClassNames(0x02, 0x01, 0x00)
+	if c != "Communications" || s != "Direct Line" || p != "" {
+		t.Errorf("ClassNames(2, 1, 0): got (%q, %q, %q), want (%q, %q, %q)", c, s, p, "Communications", "Direct Line", "")
+	}
+}
+
+func TestDescribeAssignedVendors(t *testing.T) {
+	defer keepDB()()
+	Vendors = map[gousb.ID]*Vendor{
+		0x1234: {Name: "Acme", Product: map[gousb.ID]*Product{0x5678: {Name: "Widget"}}},
+	}
+
+	desc := &gousb.DeviceDesc{Vendor: 0x1234, Product: 0x5678}
+	if got, want := Describe(desc), "Widget (Acme)"; got != want {
+		t.Errorf("Describe(%s:%s) with assigned Vendors: got %q, want %q", desc.Vendor, desc.Product, got, want)
+	}
+}
@@ -16,9 +16,14 @@
 package usbid
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/gousb"
@@ -30,35 +35,129 @@ const (
 )
 
 var (
-	// Vendors stores the vendor and product ID mappings.
+	// Vendors stores the vendor and product ID mappings used by Describe.
+	// Load and LoadFromURL replace it. Code running concurrently with
+	// RefreshFromURL should read it with CurrentVendors.
 	Vendors map[gousb.ID]*Vendor
 
-	// Classes stores the class, subclass and protocol mappings.
+	// Classes stores the class, subclass and protocol mappings used by
+	// Classify. Load and LoadFromURL replace it. Code running concurrently
+	// with RefreshFromURL should read it with CurrentClasses.
 	Classes map[gousb.Class]*Class
 )
 
-// LoadFromURL replaces the built-in vendor and class mappings with ones loaded
-// from the given URL.
+// mu serializes the updates of Vendors, Classes and LastUpdate by Load
+// with the reads of CurrentVendors, CurrentClasses and CurrentLastUpdate.
+var mu sync.RWMutex
+
+// CurrentVendors returns Vendors. Unlike reading the variable, it's safe
+// to call concurrently with Load and RefreshFromURL. The returned map
+// must not be modified.
+func CurrentVendors() map[gousb.ID]*Vendor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return Vendors
+}
+
+// CurrentClasses returns Classes. Unlike reading the variable, it's safe
+// to call concurrently with Load and RefreshFromURL. The returned map
+// must not be modified.
+func CurrentClasses() map[gousb.Class]*Class {
+	mu.RLock()
+	defer mu.RUnlock()
+	return Classes
+}
+
+// CurrentLastUpdate returns LastUpdate. Unlike reading the variable, it's
+// safe to call concurrently with Load and RefreshFromURL.
+func CurrentLastUpdate() time.Time {
+	mu.RLock()
+	defer mu.RUnlock()
+	return LastUpdate
+}
+
+// Load replaces Vendors and Classes with the mappings parsed from r, in
+// the usb.ids format, and sets LastUpdate to the current time. If parsing
+// fails, the current mappings are kept.
+func Load(r io.Reader) error {
+	ids, cls, err := ParseIDs(r)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	Vendors, Classes, LastUpdate = ids, cls, time.Now()
+	return nil
+}
+
+// LoadFromFile replaces Vendors and Classes with the mappings loaded from
+// the file at path, e.g. /usr/share/hwdata/usb.ids.
+func LoadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// httpTimeout bounds the downloads of LoadFromURL and RefreshFromURL.
+var httpTimeout = time.Minute
+
+// LoadFromURL replaces Vendors and Classes with the mappings loaded from
+// the given URL, e.g. LinuxUsbDotOrg.
 //
-// This should usually only be necessary if the mappings in the library are
-// stale.  The contents of this file as of February 2012 are embedded in the
-// library itself.
+// This should usually only be necessary if the mappings embedded in the
+// library are stale.
 func LoadFromURL(url string) error {
-	resp, err := http.Get(url)
+	return loadFromURL(context.Background(), url)
+}
+
+// loadFromURL is LoadFromURL, aborting the download when ctx is done.
+func loadFromURL(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	ids, cls, err := ParseIDs(resp.Body)
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("usbid: fetching %s: %s", url, resp.Status)
+	}
+	return Load(resp.Body)
+}
 
-	Vendors = ids
-	Classes = cls
-	LastUpdate = time.Now()
-	return nil
+// RefreshFromURL reloads the mappings from the given URL every interval,
+// in a background goroutine, until the returned function is called.
+// Failed reloads are logged and leave the current mappings in place.
+// The returned function aborts an in-progress reload and waits for the
+// goroutine to exit.
+func RefreshFromURL(url string, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			if err := loadFromURL(ctx, url); err != nil && ctx.Err() == nil {
+				log.Printf("usbid: failed to refresh from %s: %s", url, err)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-finished
+	}
 }
 
 //go:generate go run regen/regen.go --template regen/load_data.go.tpl -o load_data.go
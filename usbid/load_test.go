@@ -22,7 +22,12 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/gousb"
 )
 
 func TestLoaded(t *testing.T) {
@@ -34,6 +39,17 @@ func TestLoaded(t *testing.T) {
 	}
 }
 
+// keepDB returns a function restoring Vendors, Classes and LastUpdate,
+// to be deferred by the tests changing them.
+func keepDB() (restore func()) {
+	vendors, classes, updated := CurrentVendors(), CurrentClasses(), CurrentLastUpdate()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		Vendors, Classes, LastUpdate = vendors, classes, updated
+	}
+}
+
 type handler struct{}
 
 func (handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,9 +65,8 @@ func (handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func TestLoadFromURL(t *testing.T) {
-	origV, origC := Vendors, Classes
+	defer keepDB()()
 	Vendors, Classes = nil, nil
-	defer func() { Vendors, Classes = origV, origC }()
 
 	s := httptest.NewServer(handler{})
 	defer s.Close()
@@ -66,8 +81,7 @@ func TestLoadFromURL(t *testing.T) {
 }
 
 func TestLoadFromURLError(t *testing.T) {
-	origV, origC := Vendors, Classes
-	defer func() { Vendors, Classes = origV, origC }()
+	defer keepDB()()
 
 	s := httptest.NewServer(http.NotFoundHandler())
 	defer s.Close()
@@ -81,3 +95,128 @@ func TestLoadFromURLError(t *testing.T) {
 		t.Errorf("LastUpdate was unexpectedly updated when LoadFromURL failed")
 	}
 }
+
+func TestLoadFromFile(t *testing.T) {
+	defer keepDB()()
+
+	if err := LoadFromFile(testDBPath); err != nil {
+		t.Fatalf("LoadFromFile(%q): got unexpected error: %v", testDBPath, err)
+	}
+	if !reflect.DeepEqual(Vendors, testDBVendors) {
+		t.Errorf("LoadFromFile Vendors:\ngot: %v\nwant: %v", Vendors, testDBVendors)
+	}
+	if !reflect.DeepEqual(Classes, testDBClasses) {
+		t.Errorf("LoadFromFile Classes:\ngot: %v\nwant: %v", Classes, testDBClasses)
+	}
+	if err := LoadFromFile("testdata/does-not-exist"); err == nil {
+		t.Error("LoadFromFile(nonexistent): err is nil, want not nil")
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	defer keepDB()()
+
+	orig := Vendors
+	if err := Load(strings.NewReader("\tnot a valid database\n")); err == nil {
+		t.Fatal("Load(invalid data): err is nil, want not nil")
+	}
+	if !reflect.DeepEqual(Vendors, orig) {
+		t.Error("Vendors were replaced after Load failed")
+	}
+}
+
+func TestRefreshFromURL(t *testing.T) {
+	defer keepDB()()
+
+	s := httptest.NewServer(handler{})
+	defer s.Close()
+
+	// Describe runs concurrently with the refreshes, to let the race
+	// detector verify the swap. It runs on a ticker rather than in a busy
+	// loop, so that the server gets to run where goroutines are not
+	// preempted, e.g. on js/wasm.
+	desc := &gousb.DeviceDesc{Vendor: 0x1234, Product: 0x5678}
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(100 * time.Microsecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				Describe(desc)
+			}
+		}
+	}()
+
+	ts := CurrentLastUpdate()
+	stop := RefreshFromURL(s.URL, time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for CurrentLastUpdate() == ts {
+		if time.Now().After(deadline) {
+			t.Fatal("RefreshFromURL did not reload the database within 5s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+	close(done)
+	wg.Wait()
+
+	if got := CurrentVendors(); !reflect.DeepEqual(got, testDBVendors) {
+		t.Errorf("RefreshFromURL CurrentVendors():\ngot: %v\nwant: %v", got, testDBVendors)
+	}
+}
+
+// stallHandler accepts requests and never responds, until the client
+// gives up.
+type stallHandler chan struct{}
+
+func (h stallHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	select {
+	case h <- struct{}{}:
+	default:
+	}
+	<-r.Context().Done()
+}
+
+func TestLoadFromURLTimeout(t *testing.T) {
+	defer keepDB()()
+	orig := httpTimeout
+	httpTimeout = 10 * time.Millisecond
+	defer func() { httpTimeout = orig }()
+
+	s := httptest.NewServer(make(stallHandler))
+	defer s.Close()
+	if err := LoadFromURL(s.URL); err == nil {
+		t.Fatalf("LoadFromURL(%q) of a stalled server: err is nil, want not nil", s.URL)
+	}
+}
+
+func TestRefreshFromURLStop(t *testing.T) {
+	defer keepDB()()
+	requests := make(stallHandler)
+	s := httptest.NewServer(requests)
+	defer s.Close()
+
+	stop := RefreshFromURL(s.URL, time.Millisecond)
+	select {
+	case <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RefreshFromURL did not fetch the database within 5s")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop() did not abort a stalled download within 5s")
+	}
+}
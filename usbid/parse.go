@@ -74,7 +74,7 @@ func (s SubClass) String() string {
 // ParseIDs parses and returns mappings from the given reader.  In general, this
 // should not be necessary, as a set of mappings is already embedded in the library.
 // If a new or specialized file is obtained, this can be used to retrieve the mappings,
-// which can be stored in the global Vendors and Classes map.
+// in the format of the Vendors and Classes maps.
 func ParseIDs(r io.Reader) (map[gousb.ID]*Vendor, map[gousb.Class]*Class, error) {
 	vendors := make(map[gousb.ID]*Vendor, 2800)
 	classes := make(map[gousb.Class]*Class) // TODO(kevlar): count
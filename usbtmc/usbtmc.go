@@ -0,0 +1,517 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usbtmc implements the USB Test and Measurement Class (USBTMC)
+// and its USB488 subclass, spoken by oscilloscopes, multimeters, power
+// supplies and other lab instruments, which are usually controlled with
+// SCPI commands.
+//
+// Typical use:
+//
+//	dev, _ := ctx.OpenDeviceWithVIDPID(0x1ab1, 0x04ce)
+//	intf, done, err := dev.DefaultInterface()
+//	if err != nil { ... }
+//	defer done()
+//	d, err := usbtmc.New(dev, intf)
+//	if err != nil { ... }
+//	id, err := d.Query("*IDN?")
+package usbtmc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gousb"
+)
+
+// Interface class codes of USBTMC interfaces.
+const (
+	SubClass       = gousb.SubClassTestMeasurement
+	ProtocolUSBTMC = gousb.ProtocolUSBTMC
+	ProtocolUSB488 = gousb.ProtocolUSB488
+)
+
+// Bulk messages. Every message starts with a 12 byte header, the data
+// of DEV_DEP_MSG_OUT and DEV_DEP_MSG_IN follows it.
+const (
+	msgDevDepMsgOut       = 1
+	msgRequestDevDepMsgIn = 2
+	msgDevDepMsgIn        = 2
+	msgTrigger            = 128
+
+	bulkHdrSize  = 12
+	attrEOM      = 0x01
+	attrTermChar = 0x02
+
+	// maxTransferSize is the maximum length of the data of a single
+	// bulk message sent or requested.
+	maxTransferSize = 64 << 10
+
+	// interruptNotifyMsgBase marks the status byte notifications on the
+	// interrupt endpoint of USB488 interfaces.
+	interruptNotifyMsgBase = 0x80
+)
+
+// USBTMC and USB488 class requests.
+const (
+	requestInitiateClear    = 5
+	requestCheckClearStatus = 6
+	requestGetCapabilities  = 7
+	requestIndicatorPulse   = 64
+	requestReadStatusByte   = 128
+	requestRENControl       = 160
+	requestGoToLocal        = 161
+	requestLocalLockout     = 162
+)
+
+// pollInterval is the time between CHECK_CLEAR_STATUS requests.
+var pollInterval = 10 * time.Millisecond
+
+// Controller is the subset of *gousb.Device used to send the class
+// requests of a USBTMC interface.
+type Controller interface {
+	Control(rType, request uint8, val, idx uint16, data []byte) (int, error)
+}
+
+// IsUSBTMC reports whether the interface setting is a USBTMC interface,
+// including the USB488 subclass.
+func IsUSBTMC(s gousb.InterfaceSetting) bool {
+	return s.Class == gousb.ClassApplication && s.SubClass == SubClass &&
+		(s.Protocol == ProtocolUSBTMC || s.Protocol == ProtocolUSB488)
+}
+
+// Status is the status returned by the class requests.
+type Status uint8
+
+// Status codes.
+const (
+	StatusSuccess               Status = 0x01
+	StatusPending               Status = 0x02
+	StatusInterruptInBusy       Status = 0x20
+	StatusFailed                Status = 0x80
+	StatusTransferNotInProgress Status = 0x81
+	StatusSplitNotInProgress    Status = 0x82
+	StatusSplitInProgress       Status = 0x83
+)
+
+var statusNames = map[Status]string{
+	StatusSuccess:               "success",
+	StatusPending:               "pending",
+	StatusInterruptInBusy:       "interrupt IN busy",
+	StatusFailed:                "failed",
+	StatusTransferNotInProgress: "transfer not in progress",
+	StatusSplitNotInProgress:    "split not in progress",
+	StatusSplitInProgress:       "split in progress",
+}
+
+// String returns a human-readable name of the status.
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("status %#02x", uint8(s))
+}
+
+// StatusError is returned when a class request fails with a status other
+// than StatusSuccess.
+type StatusError struct {
+	// Request is the name of the failed request.
+	Request string
+	Status  Status
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("usbtmc: %s: %s", e.Request, e.Status)
+}
+
+// Capabilities are the capabilities of a USBTMC interface, returned by
+// the GET_CAPABILITIES request.
+type Capabilities struct {
+	// Version is the USBTMC version of the interface.
+	Version gousb.BCD
+	// IndicatorPulse is true if the interface supports IndicatorPulse.
+	IndicatorPulse bool
+	// TalkOnly and ListenOnly are true if the interface only sends or
+	// only receives device dependent messages.
+	TalkOnly   bool
+	ListenOnly bool
+	// TermChar is true if the interface can end reads at a termination
+	// character, see SetTermChar.
+	TermChar bool
+
+	// USB488Version is the USB488 version of the interface, 0 if it's
+	// not a USB488 interface.
+	USB488Version gousb.BCD
+	// IEEE4882 is true for an IEEE 488.2 interface.
+	IEEE4882 bool
+	// RemoteLocal is true if the interface supports RemoteEnable,
+	// GoToLocal and LocalLockout.
+	RemoteLocal bool
+	// Trigger is true if the interface supports Trigger.
+	Trigger bool
+	// SCPI is true if the device understands SCPI commands.
+	SCPI bool
+	// SR1, RL1 and DT1 are the IEEE 488.1 service request, remote/local
+	// and device trigger capabilities.
+	SR1, RL1, DT1 bool
+}
+
+// parseCapabilities decodes the GET_CAPABILITIES response b.
+func parseCapabilities(b []byte) (Capabilities, error) {
+	if len(b) < 24 {
+		return Capabilities{}, fmt.Errorf("GET_CAPABILITIES response too short: % x", b)
+	}
+	le := binary.LittleEndian
+	return Capabilities{
+		Version:        gousb.BCD(le.Uint16(b[2:])),
+		IndicatorPulse: b[4]&0x04 != 0,
+		TalkOnly:       b[4]&0x02 != 0,
+		ListenOnly:     b[4]&0x01 != 0,
+		TermChar:       b[5]&0x01 != 0,
+		USB488Version:  gousb.BCD(le.Uint16(b[12:])),
+		IEEE4882:       b[14]&0x04 != 0,
+		RemoteLocal:    b[14]&0x02 != 0,
+		Trigger:        b[14]&0x01 != 0,
+		SCPI:           b[15]&0x08 != 0,
+		SR1:            b[15]&0x04 != 0,
+		RL1:            b[15]&0x02 != 0,
+		DT1:            b[15]&0x01 != 0,
+	}, nil
+}
+
+// Device is a USBTMC interface of an instrument.
+type Device struct {
+	// Caps holds the capabilities of the interface.
+	Caps Capabilities
+
+	c    Controller
+	intf uint16
+	in   gousb.MessageReader
+	out  io.Writer
+	intr gousb.MessageReader
+
+	// mu serializes the bulk message exchanges.
+	mu       sync.Mutex
+	tag      uint8
+	termChar int
+	// stbTag is the tag of the last READ_STATUS_BYTE request.
+	stbMu  sync.Mutex
+	stbTag uint8
+}
+
+// New returns a Device for the claimed USBTMC interface intf of dev. The
+// bulk endpoints of the interface carry the device dependent messages,
+// the interrupt endpoint of a USB488 interface, if any, the status byte.
+// New reads the capabilities of the interface.
+func New(dev *gousb.Device, intf *gousb.Interface) (*Device, error) {
+	if !IsUSBTMC(intf.Setting) {
+		return nil, fmt.Errorf("%s is not a USBTMC interface", intf)
+	}
+	inDesc, ok := intf.Setting.BulkIn()
+	if !ok {
+		return nil, fmt.Errorf("%s has no bulk IN endpoint", intf)
+	}
+	outDesc, ok := intf.Setting.BulkOut()
+	if !ok {
+		return nil, fmt.Errorf("%s has no bulk OUT endpoint", intf)
+	}
+	in, err := intf.InEndpoint(inDesc.Number)
+	if err != nil {
+		return nil, err
+	}
+	out, err := intf.OutEndpoint(outDesc.Number)
+	if err != nil {
+		return nil, err
+	}
+	var intr gousb.MessageReader
+	if desc, ok := intf.Setting.InterruptIn(); ok {
+		if intr, err = intf.InEndpoint(desc.Number); err != nil {
+			return nil, err
+		}
+	}
+	return NewWithController(dev, intf.Setting.Number, in, out, intr)
+}
+
+// NewWithController returns a Device for the USBTMC interface intf, which
+// sends its class requests through c and its bulk messages through in and
+// out. intr is the interrupt endpoint of a USB488 interface, or nil.
+func NewWithController(c Controller, intf int, in gousb.MessageReader, out io.Writer, intr gousb.MessageReader) (*Device, error) {
+	d := &Device{c: c, intf: uint16(intf), in: in, out: out, intr: intr, termChar: -1, stbTag: 1}
+	buf, err := d.request(requestGetCapabilities, "GET_CAPABILITIES", 0, 24)
+	if err != nil {
+		return nil, err
+	}
+	if d.Caps, err = parseCapabilities(buf); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// request sends the class request req to the interface and returns the
+// response of length n, which starts with the status.
+func (d *Device) request(req uint8, name string, val uint16, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	got, err := d.c.Control(gousb.ControlIn|gousb.ControlClass|gousb.ControlInterface, req, val, d.intf, buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %v", name, err)
+	}
+	if got < 1 {
+		return nil, fmt.Errorf("%s: empty response", name)
+	}
+	if st := Status(buf[0]); st != StatusSuccess && st != StatusPending {
+		return nil, &StatusError{Request: name, Status: st}
+	}
+	return buf[:got], nil
+}
+
+// nextTag returns the bTag of the next bulk message, 1 to 255.
+func (d *Device) nextTag() uint8 {
+	d.tag++
+	if d.tag == 0 {
+		d.tag = 1
+	}
+	return d.tag
+}
+
+// header returns the bulk OUT header of the message msgID with room for
+// n bytes of data, padded to a multiple of 4 bytes.
+func (d *Device) header(msgID uint8, n int) []byte {
+	b := make([]byte, bulkHdrSize+(n+3)&^3)
+	tag := d.nextTag()
+	b[0], b[1], b[2] = msgID, tag, ^tag
+	return b
+}
+
+// SetTermChar makes reads end at the termination character c, in
+// addition to the end of a message. A negative c disables it. The
+// interface needs to support it, see Capabilities.TermChar.
+func (d *Device) SetTermChar(c int) error {
+	if c >= 0 && !d.Caps.TermChar {
+		return fmt.Errorf("the interface doesn't support a termination character")
+	}
+	if c > 0xff {
+		return fmt.Errorf("invalid termination character %d", c)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.termChar = c
+	return nil
+}
+
+// Write sends p to the device as a single device dependent message,
+// split into several transfers if it's long.
+func (d *Device) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	le := binary.LittleEndian
+	n := 0
+	for {
+		chunk := p[n:]
+		if len(chunk) > maxTransferSize {
+			chunk = chunk[:maxTransferSize]
+		}
+		msg := d.header(msgDevDepMsgOut, len(chunk))
+		le.PutUint32(msg[4:], uint32(len(chunk)))
+		eom := n+len(chunk) == len(p)
+		if eom {
+			msg[8] = attrEOM
+		}
+		copy(msg[bulkHdrSize:], chunk)
+		if _, err := d.out.Write(msg); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		if eom {
+			return n, nil
+		}
+	}
+}
+
+// ReadMessage reads a device dependent message from the device, issuing
+// REQUEST_DEV_DEP_MSG_IN requests until the device marks the end of the
+// message or sends the termination character set with SetTermChar.
+func (d *Device) ReadMessage() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	le := binary.LittleEndian
+	var msg []byte
+	for {
+		req := d.header(msgRequestDevDepMsgIn, 0)
+		le.PutUint32(req[4:], maxTransferSize)
+		if d.termChar >= 0 {
+			req[8] = attrTermChar
+			req[9] = byte(d.termChar)
+		}
+		if _, err := d.out.Write(req); err != nil {
+			return nil, err
+		}
+		resp, err := d.in.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if len(resp) < bulkHdrSize {
+			return nil, fmt.Errorf("DEV_DEP_MSG_IN too short: % x", resp)
+		}
+		if resp[0] != msgDevDepMsgIn || resp[1] != req[1] || resp[2] != ^req[1] {
+			return nil, fmt.Errorf("unexpected bulk IN header % x, want DEV_DEP_MSG_IN with bTag %d", resp[:bulkHdrSize], req[1])
+		}
+		size := int(le.Uint32(resp[4:]))
+		data := resp[bulkHdrSize:]
+		if size > len(data) {
+			return nil, fmt.Errorf("DEV_DEP_MSG_IN with %d bytes of data, the header announced %d", len(data), size)
+		}
+		msg = append(msg, data[:size]...)
+		if resp[8]&(attrEOM|attrTermChar) != 0 {
+			return msg, nil
+		}
+	}
+}
+
+// Query sends the command cmd, terminated by a newline, and returns the
+// response of the device without trailing newlines. It's a convenience
+// for SCPI queries, such as "*IDN?".
+func (d *Device) Query(cmd string) (string, error) {
+	if !strings.HasSuffix(cmd, "\n") {
+		cmd += "\n"
+	}
+	if _, err := d.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+	resp, err := d.ReadMessage()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(resp), "\r\n"), nil
+}
+
+// Clear clears the input and output buffers of the device, e.g. to
+// recover from an aborted exchange.
+func (d *Device) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.request(requestInitiateClear, "INITIATE_CLEAR", 0, 1); err != nil {
+		return err
+	}
+	for {
+		st, err := d.request(requestCheckClearStatus, "CHECK_CLEAR_STATUS", 0, 2)
+		if err != nil {
+			return err
+		}
+		if Status(st[0]) == StatusSuccess {
+			break
+		}
+		if len(st) > 1 && st[1]&0x01 != 0 {
+			// The device waits for the host to read its pending data.
+			if _, err := d.in.ReadMessage(); err != nil {
+				return err
+			}
+			continue
+		}
+		time.Sleep(pollInterval)
+	}
+	// The bulk OUT endpoint is halted at the end of the clear.
+	if hc, ok := d.out.(interface{ ClearFeature(gousb.Feature) error }); ok {
+		return hc.ClearFeature(gousb.FeatureEndpointHalt)
+	}
+	return nil
+}
+
+// IndicatorPulse makes the device blink its activity indicator, to
+// identify it among several instruments.
+func (d *Device) IndicatorPulse() error {
+	if !d.Caps.IndicatorPulse {
+		return fmt.Errorf("the interface doesn't support INDICATOR_PULSE")
+	}
+	_, err := d.request(requestIndicatorPulse, "INDICATOR_PULSE", 0, 1)
+	return err
+}
+
+// Trigger sends the USB488 TRIGGER message, the equivalent of the
+// IEEE 488.1 group execute trigger.
+func (d *Device) Trigger() error {
+	if !d.Caps.Trigger {
+		return fmt.Errorf("the interface doesn't support TRIGGER")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.out.Write(d.header(msgTrigger, 0))
+	return err
+}
+
+// ReadStatusByte returns the IEEE 488.2 status byte of a USB488 device.
+func (d *Device) ReadStatusByte() (byte, error) {
+	if d.Caps.USB488Version == 0 {
+		return 0, fmt.Errorf("the interface isn't a USB488 interface")
+	}
+	d.stbMu.Lock()
+	defer d.stbMu.Unlock()
+	// The tag is 2 to 127.
+	d.stbTag++
+	if d.stbTag > 127 {
+		d.stbTag = 2
+	}
+	tag := d.stbTag
+	resp, err := d.request(requestReadStatusByte, "READ_STATUS_BYTE", uint16(tag), 3)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp) < 3 {
+		return 0, fmt.Errorf("READ_STATUS_BYTE response too short: % x", resp)
+	}
+	if d.intr == nil {
+		return resp[2], nil
+	}
+	// With an interrupt endpoint the status byte comes in a
+	// notification.
+	n, err := d.intr.ReadMessage()
+	if err != nil {
+		return 0, err
+	}
+	if len(n) < 2 || n[0] != interruptNotifyMsgBase|tag {
+		return 0, fmt.Errorf("unexpected notification % x, want the status byte with bTag %d", n, tag)
+	}
+	return n[1], nil
+}
+
+func (d *Device) remoteLocal(req uint8, name string, val uint16) error {
+	if !d.Caps.RemoteLocal {
+		return fmt.Errorf("the interface doesn't support %s", name)
+	}
+	_, err := d.request(req, name, val, 1)
+	return err
+}
+
+// RemoteEnable sets or clears the USB488 remote enable (REN) state of
+// the device.
+func (d *Device) RemoteEnable(on bool) error {
+	var v uint16
+	if on {
+		v = 1
+	}
+	return d.remoteLocal(requestRENControl, "REN_CONTROL", v)
+}
+
+// GoToLocal returns the device to local control, enabling its front panel.
+func (d *Device) GoToLocal() error {
+	return d.remoteLocal(requestGoToLocal, "GO_TO_LOCAL", 0)
+}
+
+// LocalLockout disables the local controls of the device.
+func (d *Device) LocalLockout() error {
+	return d.remoteLocal(requestLocalLockout, "LOCAL_LOCKOUT", 0)
+}
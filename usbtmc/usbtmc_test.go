@@ -0,0 +1,222 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usbtmc
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// fakeInstrument is a USB488 instrument answering "*IDN?" and echoing
+// other commands back, in chunks of at most chunk bytes.
+type fakeInstrument struct {
+	chunk int
+	// received is the last command received.
+	received []byte
+	// pending is the response not sent yet.
+	pending []byte
+	// requests are the REQUEST_DEV_DEP_MSG_IN headers waiting for data.
+	requests [][]byte
+	triggers int
+	clears   int
+	ren      bool
+}
+
+func (f *fakeInstrument) Control(rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	switch request {
+	case requestGetCapabilities:
+		caps := make([]byte, 24)
+		caps[0] = byte(StatusSuccess)
+		binary.LittleEndian.PutUint16(caps[2:], 0x0100)
+		caps[4] = 0x04
+		caps[5] = 0x01
+		binary.LittleEndian.PutUint16(caps[12:], 0x0100)
+		caps[14] = 0x07
+		caps[15] = 0x0f
+		return copy(data, caps), nil
+	case requestReadStatusByte:
+		return copy(data, []byte{byte(StatusSuccess), byte(val), 0x10}), nil
+	case requestRENControl:
+		f.ren = val == 1
+		return copy(data, []byte{byte(StatusSuccess)}), nil
+	case requestInitiateClear:
+		f.clears++
+		return copy(data, []byte{byte(StatusSuccess)}), nil
+	case requestCheckClearStatus:
+		if f.pending != nil {
+			f.pending = nil
+			return copy(data, []byte{byte(StatusPending), 0x00}), nil
+		}
+		return copy(data, []byte{byte(StatusSuccess), 0x00}), nil
+	case requestIndicatorPulse:
+		return copy(data, []byte{byte(StatusFailed)}), nil
+	}
+	return 0, errors.New("unsupported request")
+}
+
+func (f *fakeInstrument) Write(p []byte) (int, error) {
+	if len(p)%4 != 0 || p[1] != ^p[2] {
+		return 0, errors.New("invalid bulk OUT message")
+	}
+	switch p[0] {
+	case msgDevDepMsgOut:
+		size := int(binary.LittleEndian.Uint32(p[4:]))
+		f.received = append(f.received, p[bulkHdrSize:bulkHdrSize+size]...)
+		if p[8]&attrEOM != 0 {
+			if string(f.received) == "*IDN?\n" {
+				f.pending = []byte("ACME,Scope,1,1.0\n")
+			} else {
+				f.pending = f.received
+			}
+			f.received = nil
+		}
+	case msgRequestDevDepMsgIn:
+		f.requests = append(f.requests, append([]byte(nil), p...))
+	case msgTrigger:
+		f.triggers++
+	}
+	return len(p), nil
+}
+
+func (f *fakeInstrument) ReadMessage() ([]byte, error) {
+	if len(f.requests) == 0 {
+		return nil, errors.New("no REQUEST_DEV_DEP_MSG_IN")
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	data := f.pending
+	if len(data) > f.chunk {
+		data = data[:f.chunk]
+	}
+	f.pending = f.pending[len(data):]
+	resp := make([]byte, bulkHdrSize+(len(data)+3)&^3)
+	resp[0], resp[1], resp[2] = msgDevDepMsgIn, req[1], req[2]
+	binary.LittleEndian.PutUint32(resp[4:], uint32(len(data)))
+	if len(f.pending) == 0 {
+		resp[8] = attrEOM
+	}
+	copy(resp[bulkHdrSize:], data)
+	return resp, nil
+}
+
+func TestQuery(t *testing.T) {
+	f := &fakeInstrument{chunk: 5}
+	d, err := NewWithController(f, 0, f, f, nil)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	want := Capabilities{
+		Version: 0x0100, IndicatorPulse: true, TermChar: true,
+		USB488Version: 0x0100, IEEE4882: true, RemoteLocal: true, Trigger: true,
+		SCPI: true, SR1: true, RL1: true, DT1: true,
+	}
+	if d.Caps != want {
+		t.Errorf("Caps: got %+v, want %+v", d.Caps, want)
+	}
+	got, err := d.Query("*IDN?")
+	if err != nil {
+		t.Fatalf("Query(*IDN?): %v", err)
+	}
+	if want := "ACME,Scope,1,1.0"; got != want {
+		t.Errorf("Query(*IDN?): got %q, want %q", got, want)
+	}
+	got, err = d.Query("MEAS:VOLT?")
+	if err != nil {
+		t.Fatalf("Query(MEAS:VOLT?): %v", err)
+	}
+	if want := "MEAS:VOLT?"; got != want {
+		t.Errorf("Query(MEAS:VOLT?): got %q, want %q", got, want)
+	}
+}
+
+func TestWriteSplit(t *testing.T) {
+	f := &fakeInstrument{chunk: maxTransferSize}
+	d, err := NewWithController(f, 0, f, f, nil)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	cmd := make([]byte, maxTransferSize+100)
+	for i := range cmd {
+		cmd[i] = 'a'
+	}
+	if n, err := d.Write(cmd); n != len(cmd) || err != nil {
+		t.Fatalf("Write(): got %d, %v, want %d, nil", n, err, len(cmd))
+	}
+	if len(f.pending) != len(cmd) {
+		t.Errorf("received message: got %d bytes, want %d", len(f.pending), len(cmd))
+	}
+	msg, err := d.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage(): %v", err)
+	}
+	if len(msg) != len(cmd) {
+		t.Errorf("ReadMessage(): got %d bytes, want %d", len(msg), len(cmd))
+	}
+}
+
+func TestUSB488(t *testing.T) {
+	f := &fakeInstrument{chunk: 64}
+	d, err := NewWithController(f, 0, f, f, nil)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	if stb, err := d.ReadStatusByte(); stb != 0x10 || err != nil {
+		t.Errorf("ReadStatusByte(): got %#x, %v, want 0x10, nil", stb, err)
+	}
+	if err := d.Trigger(); err != nil || f.triggers != 1 {
+		t.Errorf("Trigger(): got %v with %d triggers, want nil with 1", err, f.triggers)
+	}
+	if err := d.RemoteEnable(true); err != nil || !f.ren {
+		t.Errorf("RemoteEnable(true): got %v, REN %v, want nil, true", err, f.ren)
+	}
+	var se *StatusError
+	if err := d.IndicatorPulse(); !errors.As(err, &se) || se.Status != StatusFailed {
+		t.Errorf("IndicatorPulse(): got %v, want a StatusError with %s", err, StatusFailed)
+	}
+	if _, err := d.Write([]byte("unread")); err != nil {
+		t.Fatalf("Write(): %v", err)
+	}
+	if err := d.Clear(); err != nil || f.clears != 1 {
+		t.Errorf("Clear(): got %v with %d clears, want nil with 1", err, f.clears)
+	}
+}
+
+func TestReadStatusByteInterrupt(t *testing.T) {
+	f := &fakeInstrument{chunk: 64}
+	intr := notifications{{0x82, 0x40}, {0x80 | 5, 0x40}}
+	d, err := NewWithController(f, 0, f, f, &intr)
+	if err != nil {
+		t.Fatalf("NewWithController(): %v", err)
+	}
+	if stb, err := d.ReadStatusByte(); stb != 0x40 || err != nil {
+		t.Errorf("ReadStatusByte(): got %#x, %v, want 0x40, nil", stb, err)
+	}
+	if _, err := d.ReadStatusByte(); err == nil {
+		t.Error("ReadStatusByte() with a notification for another bTag: got nil error, want non-nil")
+	}
+}
+
+// notifications are the queued messages of an interrupt endpoint.
+type notifications [][]byte
+
+func (n *notifications) ReadMessage() ([]byte, error) {
+	if len(*n) == 0 {
+		return nil, errors.New("no notification")
+	}
+	m := (*n)[0]
+	*n = (*n)[1:]
+	return m, nil
+}
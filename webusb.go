@@ -0,0 +1,148 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Helpers of the WebUSB backend, see webusb_js.go. They don't depend on
+// syscall/js, so that they can be tested on any platform.
+
+const rawDeviceDescSize = 18
+
+// parseDeviceDescriptor decodes a raw device descriptor. The returned
+// descriptor has no configurations.
+func parseDeviceDescriptor(b []byte) (*DeviceDesc, error) {
+	if len(b) < rawDeviceDescSize || b[1] != byte(DescriptorTypeDevice) {
+		return nil, fmt.Errorf("invalid device descriptor % x", b)
+	}
+	le16 := func(i int) uint16 { return uint16(b[i]) | uint16(b[i+1])<<8 }
+	d := &DeviceDesc{
+		Spec:                 BCD(le16(2)),
+		Device:               BCD(le16(12)),
+		Vendor:               ID(le16(8)),
+		Product:              ID(le16(10)),
+		Class:                Class(b[4]),
+		SubClass:             Class(b[5]),
+		Protocol:             Protocol(b[6]),
+		MaxControlPacketSize: int(b[7]),
+		Configs:              make(map[int]ConfigDesc),
+		iManufacturer:        int(b[14]),
+		iProduct:             int(b[15]),
+		iSerialNumber:        int(b[16]),
+	}
+	d.Speed = specSpeed(d.Spec)
+	return d, nil
+}
+
+// specSpeed guesses the speed of a device from its USB version, when the
+// negotiated speed isn't known. Only SuperSpeed devices are told apart, as
+// their descriptors are encoded differently.
+func specSpeed(spec BCD) Speed {
+	if spec >= Version(3, 0) {
+		return SpeedSuper
+	}
+	return SpeedUnknown
+}
+
+// asciiStringDescriptor decodes a raw string descriptor like
+// libusb_get_string_descriptor_ascii: non-ASCII characters are replaced
+// with "?".
+func asciiStringDescriptor(b []byte) (string, error) {
+	if len(b) < 2 || b[0] < 2 || int(b[0]) > len(b) || b[1] != byte(DescriptorTypeString) {
+		return "", errors.New("invalid string descriptor")
+	}
+	b = b[2:b[0]]
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	ret := []rune(string(utf16.Decode(u)))
+	for i, r := range ret {
+		if r >= 0x80 {
+			ret[i] = '?'
+		}
+	}
+	return string(ret), nil
+}
+
+// webusbSetup returns the requestType and recipient members of a WebUSB
+// USBControlTransferParameters for the request type rType.
+func webusbSetup(rType uint8) (requestType, recipient string) {
+	switch rType & 0x60 {
+	case 0:
+		requestType = "standard"
+	case ControlClass:
+		requestType = "class"
+	default:
+		requestType = "vendor"
+	}
+	switch rType & 0x1f {
+	case ControlDevice:
+		recipient = "device"
+	case ControlInterface:
+		recipient = "interface"
+	case ControlEndpoint:
+		recipient = "endpoint"
+	default:
+		recipient = "other"
+	}
+	return requestType, recipient
+}
+
+// webusbStatus converts the status of a WebUSB transfer result.
+func webusbStatus(status string) TransferStatus {
+	switch status {
+	case "ok":
+		return TransferCompleted
+	case "stall":
+		return TransferStall
+	case "babble":
+		return TransferOverflow
+	}
+	return TransferError
+}
+
+// webusbError converts the name of a DOMException raised by WebUSB.
+func webusbError(name string) Error {
+	switch name {
+	case "NotFoundError":
+		return ErrorNoDevice
+	case "SecurityError":
+		return ErrorAccess
+	case "InvalidStateError":
+		return ErrorBusy
+	case "NetworkError":
+		return ErrorIO
+	case "NotSupportedError":
+		return ErrorNotSupported
+	case "AbortError":
+		return ErrorInterrupted
+	case "TimeoutError":
+		return ErrorTimeout
+	case "DataError", "TypeError":
+		return ErrorInvalidParam
+	}
+	return ErrorOther
+}
+
+var webusbTransferType = map[string]TransferType{
+	"bulk":        TransferTypeBulk,
+	"interrupt":   TransferTypeInterrupt,
+	"isochronous": TransferTypeIsochronous,
+}
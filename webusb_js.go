@@ -0,0 +1,848 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package gousb
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// Without libusb, the objects of the backends are represented by unique
+// pointers to these types, see backendAdapter.
+type (
+	libusbContext   struct{ _ byte }
+	libusbDevice    struct{ _ byte }
+	libusbDevHandle struct{ _ byte }
+	libusbTransfer  struct{ _ byte }
+)
+
+func newDevicePointer() *libusbDevice         { return new(libusbDevice) }
+func newFakeTransferPointer() *libusbTransfer { return new(libusbTransfer) }
+func newContextPointer() *libusbContext       { return new(libusbContext) }
+func newDevHandlePointer() *libusbDevHandle   { return new(libusbDevHandle) }
+
+// defaultBackendName is the name of the built-in backend.
+const defaultBackendName = "webusb"
+
+// newDefaultImpl returns the implementation of the built-in backend, which
+// uses the WebUSB API of the browser.
+func newDefaultImpl() libusbIntf {
+	return newBackendAdapter(newWebUSB())
+}
+
+// isLibusb reports whether impl uses the libusb library directly.
+func isLibusb(libusbIntf) bool {
+	return false
+}
+
+// WebUSBFilter selects the devices offered to the user by
+// RequestWebUSBDevice. Zero fields match any device.
+type WebUSBFilter struct {
+	Vendor       ID
+	Product      ID
+	Class        Class
+	SubClass     Class
+	Protocol     Protocol
+	SerialNumber string
+}
+
+func (f WebUSBFilter) value() js.Value {
+	v := js.Global().Get("Object").New()
+	if f.Vendor != 0 {
+		v.Set("vendorId", int(f.Vendor))
+	}
+	if f.Product != 0 {
+		v.Set("productId", int(f.Product))
+	}
+	if f.Class != 0 {
+		v.Set("classCode", int(f.Class))
+	}
+	if f.SubClass != 0 {
+		v.Set("subclassCode", int(f.SubClass))
+	}
+	if f.Protocol != 0 {
+		v.Set("protocolCode", int(f.Protocol))
+	}
+	if f.SerialNumber != "" {
+		v.Set("serialNumber", f.SerialNumber)
+	}
+	return v
+}
+
+// RequestWebUSBDevice asks the user to grant the page access to a device
+// matching one of the filters, using navigator.usb.requestDevice. The
+// devices the page has access to are returned by Context.OpenDevices.
+//
+// Browsers allow the request only in response to a user gesture, so
+// RequestWebUSBDevice needs to be called from a js.FuncOf handler of
+// e.g. a click event. As the handler must not block, the outcome is sent on
+// the returned channel: nil if the user selected a device.
+func RequestWebUSBDevice(filters ...WebUSBFilter) <-chan error {
+	ret := make(chan error, 1)
+	usb := js.Global().Get("navigator").Get("usb")
+	if usb.IsUndefined() {
+		ret <- ErrorNotSupported
+		return ret
+	}
+	fs := make([]interface{}, len(filters))
+	for i, f := range filters {
+		fs[i] = f.value()
+	}
+	opts := js.Global().Get("Object").New()
+	opts.Set("filters", fs)
+	p := usb.Call("requestDevice", opts)
+	go func() {
+		_, err := await(p, 0)
+		ret <- err
+	}()
+	return ret
+}
+
+type promiseResult struct {
+	v   js.Value
+	err error
+}
+
+// await waits for the promise p to settle and returns its value. A
+// positive timeout limits the wait, the promise is abandoned afterwards.
+// await must not be called from JavaScript callbacks, as the promise can't
+// settle while they block.
+func await(p js.Value, timeout time.Duration) (js.Value, error) {
+	ch := make(chan promiseResult, 1)
+	var then, catch js.Func
+	settle := func(r promiseResult) {
+		then.Release()
+		catch.Release()
+		ch <- r
+	}
+	then = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		settle(promiseResult{v: args[0]})
+		return nil
+	})
+	catch = js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		settle(promiseResult{err: jsError(args[0])})
+		return nil
+	})
+	p.Call("then", then, catch)
+	if timeout <= 0 {
+		r := <-ch
+		return r.v, r.err
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case r := <-ch:
+		return r.v, r.err
+	case <-t.C:
+		return js.Undefined(), ErrorTimeout
+	}
+}
+
+// jsError converts a rejection reason of a WebUSB promise.
+func jsError(v js.Value) error {
+	if v.Type() != js.TypeObject || v.Get("name").Type() != js.TypeString {
+		return fmt.Errorf("webusb: %s", v)
+	}
+	return webusbError(v.Get("name").String())
+}
+
+// call calls the method of v and waits for the returned promise.
+func call(v js.Value, method string, args ...interface{}) (js.Value, error) {
+	return await(v.Call(method, args...), 0)
+}
+
+func jsBytes(b []byte) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	return arr
+}
+
+// copyDataView copies the contents of a DataView to b and returns the
+// number of bytes copied.
+func copyDataView(b []byte, dv js.Value) int {
+	if dv.IsUndefined() || dv.IsNull() {
+		return 0
+	}
+	arr := js.Global().Get("Uint8Array").New(dv.Get("buffer"), dv.Get("byteOffset"), dv.Get("byteLength"))
+	return js.CopyBytesToGo(b, arr)
+}
+
+// webusb is a Backend using the WebUSB API, available in Chromium based
+// browsers.
+type webusb struct {
+	usb js.Value
+
+	mu       sync.Mutex
+	devices  []*webusbDevice
+	nextAddr int
+}
+
+func newWebUSB() *webusb {
+	return &webusb{usb: js.Global().Get("navigator").Get("usb")}
+}
+
+// device returns the webusbDevice of the USBDevice v, so that the same
+// device is represented by the same value.
+func (b *webusb) device(v js.Value) *webusbDevice {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, d := range b.devices {
+		if d.v.Equal(v) {
+			return d
+		}
+	}
+	b.nextAddr++
+	d := &webusbDevice{v: v, addr: b.nextAddr}
+	b.devices = append(b.devices, d)
+	return d
+}
+
+func (b *webusb) Devices() ([]BackendDevice, error) {
+	if b.usb.IsUndefined() {
+		return nil, ErrorNotSupported
+	}
+	list, err := call(b.usb, "getDevices")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]BackendDevice, list.Length())
+	for i := range ret {
+		ret[i] = b.device(list.Index(i))
+	}
+	return ret, nil
+}
+
+func (b *webusb) Close() error { return nil }
+
+func (b *webusb) Version() LibusbVersion { return LibusbVersion{} }
+func (b *webusb) SetDebug(int)           {}
+
+func (b *webusb) HasCapability(c Capability) bool {
+	return c == CapabilityHasCapability || c == CapabilityHotplug
+}
+
+func (b *webusb) RegisterHotplug(fn func(BackendDevice, HotplugEventType)) (func(), error) {
+	if b.usb.IsUndefined() {
+		return nil, ErrorNotSupported
+	}
+	// The events are delivered from a separate goroutine, fn may call
+	// WebUSB and wait for the results, which the event handlers can't do.
+	var (
+		mu     sync.Mutex
+		queue  []func()
+		wakeup = make(chan struct{}, 1)
+		done   = make(chan struct{})
+	)
+	handler := func(typ HotplugEventType) js.Func {
+		return js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			d := b.device(args[0].Get("device"))
+			mu.Lock()
+			queue = append(queue, func() { fn(d, typ) })
+			mu.Unlock()
+			select {
+			case wakeup <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+	}
+	connect := handler(HotplugEventDeviceArrived)
+	disconnect := handler(HotplugEventDeviceLeft)
+	b.usb.Call("addEventListener", "connect", connect)
+	b.usb.Call("addEventListener", "disconnect", disconnect)
+	go func() {
+		for {
+			select {
+			case <-wakeup:
+			case <-done:
+				return
+			}
+			mu.Lock()
+			q := queue
+			queue = nil
+			mu.Unlock()
+			for _, f := range q {
+				f()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.usb.Call("removeEventListener", "connect", connect)
+			b.usb.Call("removeEventListener", "disconnect", disconnect)
+			connect.Release()
+			disconnect.Release()
+			close(done)
+		})
+	}, nil
+}
+
+// webusbDevice is a USBDevice the page was granted access to.
+type webusbDevice struct {
+	v    js.Value
+	addr int
+
+	mu    sync.Mutex
+	desc  *DeviceDesc
+	opens int
+	// pendingIn has the WebUSB transfers still pending on IN endpoints,
+	// by endpoint number, whose gousb transfers were cancelled. The next
+	// transfer on the endpoint takes over the pending one, so that the
+	// data it receives is not lost.
+	pendingIn map[int]<-chan webusbInResult
+}
+
+// takePendingIn returns the pending WebUSB transfer on the IN endpoint
+// num left by a cancelled transfer, or nil if there's none.
+func (d *webusbDevice) takePendingIn(num int) <-chan webusbInResult {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ch := d.pendingIn[num]
+	delete(d.pendingIn, num)
+	return ch
+}
+
+// putPendingIn leaves the pending WebUSB transfer ch on the IN endpoint
+// num for the next transfer.
+func (d *webusbDevice) putPendingIn(num int, ch <-chan webusbInResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pendingIn == nil {
+		d.pendingIn = make(map[int]<-chan webusbInResult)
+	}
+	d.pendingIn[num] = ch
+}
+
+// Desc returns the descriptor of the device. The raw descriptors are read
+// from the device, which requires opening it. If that fails, e.g. because
+// another page uses the device, the descriptor is assembled from the
+// attributes of the USBDevice, without the string descriptor indices and
+// the details WebUSB doesn't expose.
+func (d *webusbDevice) Desc() (*DeviceDesc, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.desc == nil {
+		desc, err := d.readDesc()
+		if err != nil {
+			return d.attrDesc(), nil
+		}
+		d.desc = desc
+	}
+	return d.desc, nil
+}
+
+// readDesc reads the device and configuration descriptors. d.mu must be
+// held.
+func (d *webusbDevice) readDesc() (*DeviceDesc, error) {
+	if d.opens == 0 {
+		if _, err := call(d.v, "open"); err != nil {
+			return nil, err
+		}
+		defer call(d.v, "close")
+	}
+	read := func(val uint16, n int) ([]byte, error) {
+		b := make([]byte, n)
+		n, err := d.control(0, ControlIn|ControlDevice, requestGetDescriptor, val, 0, b)
+		return b[:n], err
+	}
+	b, err := read(uint16(DescriptorTypeDevice)<<8, rawDeviceDescSize)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := parseDeviceDescriptor(b)
+	if err != nil {
+		return nil, err
+	}
+	d.setAddr(desc)
+	for i := 0; i < int(b[17]); i++ {
+		val := uint16(DescriptorTypeConfig)<<8 | uint16(i)
+		hdr, err := read(val, rawConfigDescSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(hdr) < rawConfigDescSize {
+			return nil, fmt.Errorf("configuration descriptor %d too short: % x", i, hdr)
+		}
+		raw, err := read(val, int(hdr[2])|int(hdr[3])<<8)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := ParseConfigDescriptor(raw, desc.Speed)
+		if err != nil {
+			return nil, err
+		}
+		desc.Configs[cfg.Number] = cfg
+	}
+	return desc, nil
+}
+
+// attrDesc assembles the descriptor from the attributes of the USBDevice.
+func (d *webusbDevice) attrDesc() *DeviceDesc {
+	v := d.v
+	version := func(prefix string) BCD {
+		return Version(uint8(v.Get(prefix+"Major").Int()), uint8(10*v.Get(prefix+"Minor").Int()+v.Get(prefix+"Subminor").Int()))
+	}
+	desc := &DeviceDesc{
+		Spec:     version("usbVersion"),
+		Device:   version("deviceVersion"),
+		Vendor:   ID(v.Get("vendorId").Int()),
+		Product:  ID(v.Get("productId").Int()),
+		Class:    Class(v.Get("deviceClass").Int()),
+		SubClass: Class(v.Get("deviceSubclass").Int()),
+		Protocol: Protocol(v.Get("deviceProtocol").Int()),
+		Configs:  make(map[int]ConfigDesc),
+	}
+	desc.Speed = specSpeed(desc.Spec)
+	d.setAddr(desc)
+	cfgs := v.Get("configurations")
+	for i := 0; i < cfgs.Length(); i++ {
+		c := cfgs.Index(i)
+		cfg := ConfigDesc{Number: c.Get("configurationValue").Int()}
+		intfs := c.Get("interfaces")
+		for j := 0; j < intfs.Length(); j++ {
+			intf := intfs.Index(j)
+			num := intf.Get("interfaceNumber").Int()
+			id := InterfaceDesc{Number: num}
+			alts := intf.Get("alternates")
+			for k := 0; k < alts.Length(); k++ {
+				alt := alts.Index(k)
+				s := InterfaceSetting{
+					Number:    num,
+					Alternate: alt.Get("alternateSetting").Int(),
+					Class:     Class(alt.Get("interfaceClass").Int()),
+					SubClass:  Class(alt.Get("interfaceSubclass").Int()),
+					Protocol:  Protocol(alt.Get("interfaceProtocol").Int()),
+					Endpoints: make(map[EndpointAddress]EndpointDesc),
+				}
+				eps := alt.Get("endpoints")
+				for l := 0; l < eps.Length(); l++ {
+					ep := eps.Index(l)
+					e := EndpointDesc{
+						Number:        ep.Get("endpointNumber").Int(),
+						Direction:     EndpointDirection(ep.Get("direction").String() == "in"),
+						TransferType:  webusbTransferType[ep.Get("type").String()],
+						MaxPacketSize: ep.Get("packetSize").Int(),
+					}
+					e.Address = EndpointAddress(e.Number)
+					if e.Direction == EndpointDirectionIn {
+						e.Address |= endpointDirectionMask
+					}
+					s.Endpoints[e.Address] = e
+				}
+				id.AltSettings = append(id.AltSettings, s)
+			}
+			cfg.Interfaces = append(cfg.Interfaces, id)
+		}
+		desc.Configs[cfg.Number] = cfg
+	}
+	return desc
+}
+
+// setAddr sets the bus information of desc. WebUSB doesn't expose the
+// topology, devices are numbered in the order they were seen instead.
+func (d *webusbDevice) setAddr(desc *DeviceDesc) {
+	desc.Address = d.addr
+}
+
+func (d *webusbDevice) Open() (BackendHandle, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.opens == 0 {
+		if _, err := call(d.v, "open"); err != nil {
+			return nil, err
+		}
+	}
+	d.opens++
+	return &webusbHandle{d: d}, nil
+}
+
+func (d *webusbDevice) Release() {}
+
+// control performs a control transfer on the device.
+func (d *webusbDevice) control(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	requestType, recipient := webusbSetup(rType)
+	setup := js.Global().Get("Object").New()
+	setup.Set("requestType", requestType)
+	setup.Set("recipient", recipient)
+	setup.Set("request", int(request))
+	setup.Set("value", int(val))
+	setup.Set("index", int(idx))
+	var p js.Value
+	if rType&ControlIn != 0 {
+		p = d.v.Call("controlTransferIn", setup, len(data))
+	} else {
+		p = d.v.Call("controlTransferOut", setup, jsBytes(data))
+	}
+	res, err := await(p, timeout)
+	if err != nil {
+		return 0, err
+	}
+	switch webusbStatus(res.Get("status").String()) {
+	case TransferCompleted:
+	case TransferStall:
+		return 0, ErrorPipe
+	case TransferOverflow:
+		return 0, ErrorOverflow
+	default:
+		return 0, ErrorIO
+	}
+	if rType&ControlIn != 0 {
+		return copyDataView(data, res.Get("data")), nil
+	}
+	return res.Get("bytesWritten").Int(), nil
+}
+
+// webusbHandle is an open webusbDevice.
+type webusbHandle struct {
+	d *webusbDevice
+
+	mu   sync.Mutex
+	lang uint16
+}
+
+func (h *webusbHandle) Close() {
+	h.d.mu.Lock()
+	defer h.d.mu.Unlock()
+	if h.d.opens--; h.d.opens == 0 {
+		// Closing the device ends its pending transfers.
+		h.d.pendingIn = nil
+		call(h.d.v, "close")
+	}
+}
+
+func (h *webusbHandle) Reset() error {
+	_, err := call(h.d.v, "reset")
+	return err
+}
+
+func (h *webusbHandle) Control(timeout time.Duration, rType, request uint8, val, idx uint16, data []byte) (int, error) {
+	return h.d.control(timeout, rType, request, val, idx, data)
+}
+
+func (h *webusbHandle) ActiveConfig() (int, error) {
+	cfg := h.d.v.Get("configuration")
+	if cfg.IsNull() || cfg.IsUndefined() {
+		return 0, nil
+	}
+	return cfg.Get("configurationValue").Int(), nil
+}
+
+func (h *webusbHandle) SetConfig(cfg int) error {
+	_, err := call(h.d.v, "selectConfiguration", cfg)
+	return err
+}
+
+func (h *webusbHandle) StringDescriptor(index int) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buf := make([]byte, 255)
+	if h.lang == 0 {
+		n, err := h.d.control(time.Second, ControlIn|ControlDevice, requestGetDescriptor, uint16(DescriptorTypeString)<<8, 0, buf)
+		if err != nil {
+			return "", fmt.Errorf("failed to get string descriptor %d: %v", index, err)
+		}
+		if n < 4 {
+			return "", fmt.Errorf("failed to get string descriptor %d: device reports no languages", index)
+		}
+		h.lang = uint16(buf[2]) | uint16(buf[3])<<8
+	}
+	n, err := h.d.control(time.Second, ControlIn|ControlDevice, requestGetDescriptor, uint16(DescriptorTypeString)<<8|uint16(index), h.lang, buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to get string descriptor %d: %v", index, err)
+	}
+	return asciiStringDescriptor(buf[:n])
+}
+
+// SetAutoDetach is not supported, the browser decides which interfaces
+// can be claimed.
+func (h *webusbHandle) SetAutoDetach(bool) error {
+	return ErrorNotSupported
+}
+
+func (h *webusbHandle) DetachKernelDriver(int) error {
+	return ErrorNotSupported
+}
+
+func (h *webusbHandle) ClaimInterface(intf int) error {
+	_, err := call(h.d.v, "claimInterface", intf)
+	return err
+}
+
+func (h *webusbHandle) ReleaseInterface(intf int) {
+	call(h.d.v, "releaseInterface", intf)
+}
+
+func (h *webusbHandle) SetAlternate(intf, alt int) error {
+	_, err := call(h.d.v, "selectAlternateInterface", intf, alt)
+	return err
+}
+
+func (h *webusbHandle) NewTransfer(ep EndpointDesc, opts BackendTransferOptions, buf []byte, done chan<- struct{}) (BackendTransfer, error) {
+	return &webusbTransfer{d: h.d, ep: ep, opts: opts, buf: buf, done: done}, nil
+}
+
+// webusbTransfer is a transfer on a bulk, interrupt or isochronous
+// endpoint. WebUSB can't cancel transfers: Cancel completes the transfer
+// right away. A pending transfer on a bulk or interrupt IN endpoint is
+// taken over by the next transfer on the endpoint, which receives its
+// data, the outcome of other pending transfers is discarded. The received
+// data is only copied to the transfer buffer by a transfer that was not
+// cancelled, as the buffer is reused once the transfer completes.
+type webusbTransfer struct {
+	d    *webusbDevice
+	ep   EndpointDesc
+	opts BackendTransferOptions
+	buf  []byte
+	done chan<- struct{}
+
+	mu       sync.Mutex
+	gen      int
+	inFlight bool
+	// cancelled is closed by Cancel, pending is the WebUSB transfer
+	// awaited by the bulk or interrupt IN transfer in flight.
+	cancelled chan struct{}
+	pending   <-chan webusbInResult
+	n         int
+	status    TransferStatus
+	isoLen    int
+	pkts      []BackendIsoPacket
+}
+
+// webusbInResult is the outcome of a WebUSB transferIn.
+type webusbInResult struct {
+	data   []byte
+	status TransferStatus
+}
+
+func (t *webusbTransfer) Submit() error {
+	t.mu.Lock()
+	if t.inFlight {
+		t.mu.Unlock()
+		return ErrorBusy
+	}
+	t.inFlight = true
+	t.gen++
+	gen := t.gen
+	t.cancelled = make(chan struct{})
+	in := t.ep.Direction == EndpointDirectionIn && t.opts.IsoPackets == 0
+	if in {
+		t.pending = t.d.takePendingIn(t.ep.Number)
+		if t.pending == nil {
+			t.pending = t.startIn()
+		}
+	}
+	t.mu.Unlock()
+	if in {
+		go t.runIn(gen)
+	} else {
+		go t.run(gen)
+	}
+	return nil
+}
+
+// startIn starts a WebUSB transferIn and returns its outcome.
+func (t *webusbTransfer) startIn() <-chan webusbInResult {
+	ch := make(chan webusbInResult, 1)
+	p := t.d.v.Call("transferIn", t.ep.Number, len(t.buf))
+	go func() {
+		res, err := await(p, 0)
+		if err != nil {
+			ch <- webusbInResult{status: errorStatus(err)}
+			return
+		}
+		dv := res.Get("data")
+		var data []byte
+		if !dv.IsUndefined() && !dv.IsNull() {
+			data = make([]byte, dv.Get("byteLength").Int())
+			copyDataView(data, dv)
+		}
+		ch <- webusbInResult{data: data, status: webusbStatus(res.Get("status").String())}
+	}()
+	return ch
+}
+
+// runIn waits for the WebUSB transfer of a bulk or interrupt IN transfer
+// and reports the outcome, unless the transfer is cancelled first.
+func (t *webusbTransfer) runIn(gen int) {
+	t.mu.Lock()
+	pending, cancelled := t.pending, t.cancelled
+	t.mu.Unlock()
+	var r webusbInResult
+	select {
+	case r = <-pending:
+	case <-cancelled:
+		return
+	}
+	t.mu.Lock()
+	if gen != t.gen || !t.inFlight {
+		t.mu.Unlock()
+		return
+	}
+	t.inFlight, t.pending = false, nil
+	n := copy(t.buf, r.data)
+	status := r.status
+	switch {
+	case status == TransferCompleted && len(r.data) > len(t.buf):
+		// The data was requested by a cancelled transfer with a larger
+		// buffer.
+		status = TransferOverflow
+	case status == TransferCompleted && t.opts.ShortNotOK && n < len(t.buf):
+		status = TransferError
+	}
+	t.n, t.status, t.pkts = n, status, nil
+	t.mu.Unlock()
+	t.done <- struct{}{}
+}
+
+// run performs an OUT or isochronous transfer and reports the outcome,
+// unless the transfer was cancelled in the meantime.
+func (t *webusbTransfer) run(gen int) {
+	var n int
+	var status TransferStatus
+	var pkts []BackendIsoPacket
+	var data []byte
+	if t.opts.IsoPackets > 0 {
+		n, status, pkts, data = t.isoTransfer()
+	} else {
+		n, status = t.transferOut()
+	}
+	t.mu.Lock()
+	if gen != t.gen || !t.inFlight {
+		t.mu.Unlock()
+		return
+	}
+	t.inFlight = false
+	copy(t.buf, data)
+	t.n, t.status, t.pkts = n, status, pkts
+	t.mu.Unlock()
+	t.done <- struct{}{}
+}
+
+func (t *webusbTransfer) transferOut() (int, TransferStatus) {
+	num := t.ep.Number
+	res, err := await(t.d.v.Call("transferOut", num, jsBytes(t.buf)), 0)
+	if err != nil {
+		return 0, errorStatus(err)
+	}
+	status := webusbStatus(res.Get("status").String())
+	n := res.Get("bytesWritten").Int()
+	if status == TransferCompleted && t.opts.AddZeroPacket && n > 0 && n%t.ep.MaxPacketSize == 0 {
+		if res, err := call(t.d.v, "transferOut", num, jsBytes(nil)); err != nil {
+			status = errorStatus(err)
+		} else {
+			status = webusbStatus(res.Get("status").String())
+		}
+	}
+	return n, status
+}
+
+// isoTransfer performs an isochronous transfer. The data received by an IN
+// transfer is returned in a new buffer laid out like the transfer buffer.
+func (t *webusbTransfer) isoTransfer() (int, TransferStatus, []BackendIsoPacket, []byte) {
+	t.mu.Lock()
+	isoLen := t.isoLen
+	t.mu.Unlock()
+	pkts := make([]BackendIsoPacket, t.opts.IsoPackets)
+	lengths := make([]interface{}, len(pkts))
+	for i := range pkts {
+		pkts[i].Length = isoLen
+		lengths[i] = isoLen
+	}
+	var p js.Value
+	in := t.ep.Direction == EndpointDirectionIn
+	if in {
+		p = t.d.v.Call("isochronousTransferIn", t.ep.Number, lengths)
+	} else {
+		p = t.d.v.Call("isochronousTransferOut", t.ep.Number, jsBytes(t.buf[:len(pkts)*isoLen]), lengths)
+	}
+	res, err := await(p, 0)
+	if err != nil {
+		return 0, errorStatus(err), nil, nil
+	}
+	var data []byte
+	if in {
+		data = make([]byte, len(pkts)*isoLen)
+	}
+	rp := res.Get("packets")
+	total, off := 0, 0
+	for i := range pkts {
+		if i >= rp.Length() {
+			pkts[i].Status = TransferError
+			continue
+		}
+		r := rp.Index(i)
+		pkts[i].Status = webusbStatus(r.Get("status").String())
+		if in {
+			pkts[i].Actual = copyDataView(data[off:off+isoLen], r.Get("data"))
+		} else {
+			pkts[i].Actual = r.Get("bytesWritten").Int()
+		}
+		total += pkts[i].Actual
+		off += isoLen
+	}
+	return total, TransferCompleted, pkts, data
+}
+
+// errorStatus returns the status of a transfer that failed with err.
+func errorStatus(err error) TransferStatus {
+	if err == ErrorNoDevice {
+		return TransferNoDevice
+	}
+	return TransferError
+}
+
+func (t *webusbTransfer) Cancel() error {
+	t.mu.Lock()
+	if !t.inFlight {
+		t.mu.Unlock()
+		return ErrorNotFound
+	}
+	t.inFlight = false
+	close(t.cancelled)
+	if t.pending != nil {
+		t.d.putPendingIn(t.ep.Number, t.pending)
+		t.pending = nil
+	}
+	t.n, t.status, t.pkts = 0, TransferCancelled, nil
+	t.mu.Unlock()
+	t.done <- struct{}{}
+	return nil
+}
+
+func (t *webusbTransfer) Result() (int, TransferStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n, t.status
+}
+
+func (t *webusbTransfer) Free() {}
+
+func (t *webusbTransfer) SetIsoPacketLength(length int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.isoLen = length
+}
+
+func (t *webusbTransfer) IsoPackets() ([]BackendIsoPacket, TransferStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pkts, t.status
+}
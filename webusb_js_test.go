@@ -0,0 +1,173 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package gousb
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// fakeWebUSB is a navigator.usb with a single device, which echoes the
+// data written to its bulk OUT endpoint 0x01 on its bulk IN endpoint 0x82.
+// Reads with no data pending complete when data is written.
+const fakeWebUSB = `(function() {
+	const deviceDesc = [18, 1, 0x00, 0x02, 0, 0, 0, 64, 0x09, 0x12, 0x01, 0x00, 0x00, 0x01, 1, 2, 0, 1];
+	const configDesc = [
+		9, 2, 32, 0, 1, 1, 0, 0x80, 50,
+		9, 4, 0, 0, 2, 255, 0, 0, 0,
+		7, 5, 0x01, 2, 64, 0, 0,
+		7, 5, 0x82, 2, 64, 0, 0,
+	];
+	const strings = {1: "ACME", 2: "gousb"};
+	const stringDesc = (s) => {
+		const b = [2 + 2 * s.length, 3];
+		for (const c of s) b.push(c.charCodeAt(0), 0);
+		return b;
+	};
+	const result = (b, n) => ({status: "ok", data: new DataView(Uint8Array.from(b.slice(0, n)).buffer)});
+	const config = {
+		configurationValue: 1,
+		interfaces: [{interfaceNumber: 0, alternates: [{
+			alternateSetting: 0, interfaceClass: 255, interfaceSubclass: 0, interfaceProtocol: 0,
+			endpoints: [
+				{endpointNumber: 1, direction: "out", type: "bulk", packetSize: 64},
+				{endpointNumber: 2, direction: "in", type: "bulk", packetSize: 64},
+			],
+		}]}],
+	};
+	const queue = [];
+	const readers = [];
+	const dev = {
+		vendorId: 0x1209, productId: 0x0001, deviceClass: 0, deviceSubclass: 0, deviceProtocol: 0,
+		usbVersionMajor: 2, usbVersionMinor: 0, usbVersionSubminor: 0,
+		deviceVersionMajor: 1, deviceVersionMinor: 0, deviceVersionSubminor: 0,
+		configurations: [config], configuration: config, opened: false,
+		open() { this.opened = true; return Promise.resolve(); },
+		close() { this.opened = false; return Promise.resolve(); },
+		reset() { return Promise.resolve(); },
+		selectConfiguration() { return Promise.resolve(); },
+		claimInterface() { return Promise.resolve(); },
+		releaseInterface() { return Promise.resolve(); },
+		selectAlternateInterface() { return Promise.resolve(); },
+		controlTransferIn(setup, n) {
+			if (!this.opened) return Promise.reject(new DOMException("closed", "InvalidStateError"));
+			if (setup.requestType == "standard" && setup.request == 6) {
+				const idx = setup.value & 0xff;
+				switch (setup.value >> 8) {
+				case 1: return Promise.resolve(result(deviceDesc, n));
+				case 2: return Promise.resolve(result(configDesc, n));
+				case 3:
+					if (idx == 0) return Promise.resolve(result([4, 3, 0x09, 0x04], n));
+					if (strings[idx]) return Promise.resolve(result(stringDesc(strings[idx]), n));
+				}
+			}
+			if (setup.requestType == "vendor" && setup.request == 1) {
+				return Promise.resolve(result([setup.value, setup.index], n));
+			}
+			return Promise.resolve({status: "stall"});
+		},
+		controlTransferOut(setup, data) {
+			return Promise.resolve({status: "stall"});
+		},
+		transferOut(ep, data) {
+			if (readers.length > 0) readers.shift()(Array.from(data));
+			else queue.push(Array.from(data));
+			return Promise.resolve({status: "ok", bytesWritten: data.length});
+		},
+		transferIn(ep, n) {
+			if (queue.length == 0) return new Promise((resolve) => readers.push((b) => resolve(result(b, n))));
+			return Promise.resolve(result(queue.shift(), n));
+		},
+	};
+	return {
+		getDevices() { return Promise.resolve([dev]); },
+		addEventListener() {},
+		removeEventListener() {},
+	};
+})()`
+
+func TestWebUSB(t *testing.T) {
+	nav := js.Global().Get("navigator")
+	if nav.IsUndefined() {
+		nav = js.Global().Get("Object").New()
+		js.Global().Set("navigator", nav)
+	}
+	nav.Set("usb", js.Global().Call("eval", fakeWebUSB))
+	defer nav.Delete("usb")
+
+	ctx := NewContext()
+	defer ctx.Close()
+	dev, err := ctx.OpenDeviceWithVIDPID(0x1209, 0x0001)
+	if err != nil {
+		t.Fatalf("OpenDeviceWithVIDPID(1209, 0001): %v", err)
+	}
+	defer dev.Close()
+	if got, want := dev.Desc.Configs[1].MaxPower, Milliamperes(100); got != want {
+		t.Errorf("MaxPower: got %d, want %d", got, want)
+	}
+	if got, err := dev.Product(); err != nil || got != "gousb" {
+		t.Errorf("%s.Product(): got (%q, %v), want (\"gousb\", nil)", dev, got, err)
+	}
+	buf := make([]byte, 2)
+	if n, err := dev.Control(ControlIn|ControlVendor|ControlDevice, 0x01, 0x12, 0x34, buf); err != nil || n != 2 || buf[0] != 0x12 || buf[1] != 0x34 {
+		t.Errorf("%s.Control(): got (% x, %v), want (12 34, nil)", dev, buf[:n], err)
+	}
+	if _, err := dev.Control(ControlOut|ControlVendor|ControlDevice, 0x02, 0, 0, nil); err != ErrorPipe {
+		t.Errorf("%s.Control() OUT: got error %v, want %v", dev, err, ErrorPipe)
+	}
+	if err := dev.SetAutoDetach(true); !errors.Is(err, ErrorNotSupported) {
+		t.Errorf("%s.SetAutoDetach(true): got error %v, want %v", dev, err, ErrorNotSupported)
+	}
+
+	intf, done, err := dev.DefaultInterface()
+	if err != nil {
+		t.Fatalf("%s.DefaultInterface(): %v", dev, err)
+	}
+	defer done()
+	out, err := intf.OutEndpoint(1)
+	if err != nil {
+		t.Fatalf("%s.OutEndpoint(1): %v", intf, err)
+	}
+	in, err := intf.InEndpoint(2)
+	if err != nil {
+		t.Fatalf("%s.InEndpoint(2): %v", intf, err)
+	}
+	if n, err := out.Write([]byte("hello")); err != nil || n != 5 {
+		t.Errorf("%s.Write(): got (%d, %v), want (5, nil)", out, n, err)
+	}
+	rbuf := make([]byte, 64)
+	if n, err := in.Read(rbuf); err != nil || string(rbuf[:n]) != "hello" {
+		t.Errorf("%s.Read(): got (%q, %v), want (\"hello\", nil)", in, rbuf[:n], err)
+	}
+	rctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := in.ReadContext(rctx, rbuf); err == nil {
+		t.Errorf("%s.ReadContext() with no data: got nil error, want an error after the timeout", in)
+	}
+	// The data received by the WebUSB transfer of the cancelled read is
+	// returned by the next read.
+	if n, err := out.Write([]byte("world")); err != nil || n != 5 {
+		t.Errorf("%s.Write(): got (%d, %v), want (5, nil)", out, n, err)
+	}
+	if n, err := in.Read(rbuf); err != nil || string(rbuf[:n]) != "world" {
+		t.Errorf("%s.Read() after a cancelled read: got (%q, %v), want (\"world\", nil)", in, rbuf[:n], err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 the gousb Authors.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gousb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDeviceDescriptor(t *testing.T) {
+	raw := []byte{0x12, 0x01, 0x00, 0x03, 0xef, 0x02, 0x01, 0x09, 0x09, 0x12, 0x01, 0x00, 0x10, 0x02, 0x01, 0x02, 0x03, 0x01}
+	got, err := parseDeviceDescriptor(raw)
+	if err != nil {
+		t.Fatalf("parseDeviceDescriptor(): %v", err)
+	}
+	want := &DeviceDesc{
+		Speed:                SpeedSuper,
+		Spec:                 Version(3, 0),
+		Device:               Version(2, 10),
+		Vendor:               0x1209,
+		Product:              0x0001,
+		Class:                ClassMiscellaneous,
+		SubClass:             0x02,
+		Protocol:             0x01,
+		MaxControlPacketSize: 9,
+		Configs:              map[int]ConfigDesc{},
+		iManufacturer:        1,
+		iProduct:             2,
+		iSerialNumber:        3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDeviceDescriptor():\ngot  %+v\nwant %+v", got, want)
+	}
+	if _, err := parseDeviceDescriptor(raw[:8]); err == nil {
+		t.Error("parseDeviceDescriptor() with a truncated descriptor: got nil error, want non-nil")
+	}
+}
+
+func TestASCIIStringDescriptor(t *testing.T) {
+	for _, tc := range []struct {
+		raw     []byte
+		want    string
+		wantErr bool
+	}{
+		{raw: []byte{0x08, 0x03, 'a', 0, 'b', 0, 'c', 0}, want: "abc"},
+		{raw: []byte{0x06, 0x03, 'a', 0, 0xac, 0x20, 'x', 0}, want: "a?"},
+		{raw: []byte{0x02, 0x03}, want: ""},
+		{raw: []byte{0x08, 0x03, 'a', 0}, wantErr: true},
+		{raw: []byte{0x04, 0x02, 'a', 0}, wantErr: true},
+		{raw: []byte{0x03}, wantErr: true},
+	} {
+		got, err := asciiStringDescriptor(tc.raw)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("asciiStringDescriptor(% x): got error %v, want error: %v", tc.raw, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("asciiStringDescriptor(% x): got %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestWebUSBSetup(t *testing.T) {
+	for _, tc := range []struct {
+		rType                  uint8
+		requestType, recipient string
+	}{
+		{ControlIn | ControlDevice, "standard", "device"},
+		{ControlOut | ControlClass | ControlInterface, "class", "interface"},
+		{ControlIn | ControlVendor | ControlEndpoint, "vendor", "endpoint"},
+		{ControlOut | ControlVendor | ControlOther, "vendor", "other"},
+	} {
+		if rt, rcp := webusbSetup(tc.rType); rt != tc.requestType || rcp != tc.recipient {
+			t.Errorf("webusbSetup(%#02x): got (%q, %q), want (%q, %q)", tc.rType, rt, rcp, tc.requestType, tc.recipient)
+		}
+	}
+}
+
+func TestWebUSBStatus(t *testing.T) {
+	for status, want := range map[string]TransferStatus{
+		"ok":     TransferCompleted,
+		"stall":  TransferStall,
+		"babble": TransferOverflow,
+		"":       TransferError,
+	} {
+		if got := webusbStatus(status); got != want {
+			t.Errorf("webusbStatus(%q): got %s, want %s", status, got, want)
+		}
+	}
+	for name, want := range map[string]Error{
+		"NotFoundError":     ErrorNoDevice,
+		"SecurityError":     ErrorAccess,
+		"NetworkError":      ErrorIO,
+		"NotSupportedError": ErrorNotSupported,
+		"SomethingElse":     ErrorOther,
+	} {
+		if got := webusbError(name); got != want {
+			t.Errorf("webusbError(%q): got %s, want %s", name, got, want)
+		}
+	}
+}